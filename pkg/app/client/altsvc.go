@@ -0,0 +1,189 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+const (
+	// defaultAltSvcMaxAge is the freshness lifetime of the alternative service
+	// without "ma" parameter, see RFC 7838, section 3.1.
+	defaultAltSvcMaxAge = 24 * time.Hour
+
+	// altSvcBrokenDuration is how long the alternative service is not used
+	// after it fails.
+	altSvcBrokenDuration = 5 * time.Minute
+)
+
+// http3ALPNs are the protocol ids of HTTP/3 in Alt-Svc header.
+var http3ALPNs = [][]byte{[]byte("h3"), []byte("h3-29")}
+
+type altSvc struct {
+	addr    string
+	expires time.Time
+}
+
+// altSvcCache keeps the HTTP/3 alternative services learned from Alt-Svc headers by origin hosts.
+type altSvcCache struct {
+	lock     sync.Mutex
+	services map[string]altSvc
+	broken   map[string]time.Time
+}
+
+// get returns the address of the alternative service of host, which is neither expired nor broken.
+func (c *altSvcCache) get(host string) (string, bool) {
+	now := time.Now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	svc, ok := c.services[host]
+	if !ok {
+		return "", false
+	}
+	if now.After(svc.expires) {
+		delete(c.services, host)
+		return "", false
+	}
+	if until, ok := c.broken[host]; ok {
+		if now.Before(until) {
+			return "", false
+		}
+		delete(c.broken, host)
+	}
+	return svc.addr, true
+}
+
+// learn updates the alternative service of host with the value of Alt-Svc header,
+// hostname is used for the alternative authority without host.
+func (c *altSvcCache) learn(host, hostname string, value []byte) {
+	if len(value) == 0 {
+		return
+	}
+	addr, maxAge, clear, ok := parseAltSvc(value)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if clear || maxAge <= 0 {
+		delete(c.services, host)
+		return
+	}
+	if c.services == nil {
+		c.services = make(map[string]altSvc)
+	}
+	if h, port, err := net.SplitHostPort(addr); err == nil && h == "" {
+		addr = net.JoinHostPort(hostname, port)
+	}
+	c.services[host] = altSvc{addr: addr, expires: time.Now().Add(maxAge)}
+}
+
+// markBroken stops using the alternative service of host for a while.
+func (c *altSvcCache) markBroken(host string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.broken == nil {
+		c.broken = make(map[string]time.Time)
+	}
+	c.broken[host] = time.Now().Add(altSvcBrokenDuration)
+}
+
+// canRetryOverTCP reports whether the request failed over HTTP/3 may be sent again over TCP,
+// which is the case if it was never sent, i.e. connecting to the alternative service failed,
+// or if it's idempotent and its body can be sent again.
+func canRetryOverTCP(req *protocol.Request, err error) bool {
+	if isDialError(err) {
+		return true
+	}
+	if req.IsBodyStream() {
+		return false
+	}
+	h := &req.Header
+	return h.IsGet() || h.IsHead() || h.IsPut() || h.IsDelete() || h.IsOptions() || h.IsTrace()
+}
+
+// isDialError reports whether err is returned before the request is sent. HTTP/3 host clients
+// are expected to report the failures of QUIC handshakes as *net.OpError with Op "dial".
+func isDialError(err error) bool {
+	if errors.Is(err, errs.ErrDialTimeout) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseAltSvc returns the alternative authority and max age of the first HTTP/3 service in
+// the value of Alt-Svc header, or clear=true if the value is "clear", see RFC 7838, section 3.
+func parseAltSvc(value []byte) (authority string, maxAge time.Duration, clear, ok bool) {
+	value = bytes.TrimSpace(value)
+	if string(value) == "clear" {
+		return "", 0, true, true
+	}
+	for _, entry := range bytes.Split(value, []byte{','}) {
+		params := bytes.Split(entry, []byte{';'})
+		alt := bytes.TrimSpace(params[0])
+		eq := bytes.IndexByte(alt, '=')
+		if eq < 0 || !isHTTP3ALPN(alt[:eq]) {
+			continue
+		}
+		authority, ok = unquote(alt[eq+1:])
+		if !ok {
+			continue
+		}
+		maxAge = defaultAltSvcMaxAge
+		for _, p := range params[1:] {
+			p = bytes.TrimSpace(p)
+			if !bytes.HasPrefix(p, []byte("ma=")) {
+				continue
+			}
+			if v, ok := unquote(p[len("ma="):]); ok {
+				if seconds, err := strconv.ParseInt(v, 10, 64); err == nil && seconds >= 0 {
+					maxAge = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+		return authority, maxAge, false, true
+	}
+	return "", 0, false, false
+}
+
+func isHTTP3ALPN(id []byte) bool {
+	for _, alpn := range http3ALPNs {
+		if bytes.Equal(id, alpn) {
+			return true
+		}
+	}
+	return false
+}
+
+func unquote(b []byte) (string, bool) {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		return string(b[1 : len(b)-1]), true
+	}
+	if bytes.IndexByte(b, '"') >= 0 {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/client"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestParseAltSvc(t *testing.T) {
+	for value, expected := range map[string]struct {
+		authority string
+		maxAge    time.Duration
+		clear     bool
+		ok        bool
+	}{
+		`h3=":443"; ma=3600`:                    {":443", time.Hour, false, true},
+		`h2=":443", h3-29="alt.com:8443"`:       {"alt.com:8443", defaultAltSvcMaxAge, false, true},
+		`h3=":443"; persist=1; ma="60"`:         {":443", time.Minute, false, true},
+		`h3=":443"; ma=0`:                       {":443", 0, false, true},
+		`clear`:                                 {"", 0, true, true},
+		`h2=":443"`:                             {"", 0, false, false},
+		`h3=:443"`:                              {"", 0, false, false},
+		`h3=":443"; ma=x, quic=":443"; ma=3600`: {":443", defaultAltSvcMaxAge, false, true},
+	} {
+		authority, maxAge, clear, ok := parseAltSvc([]byte(value))
+		assert.DeepEqual(t, expected.authority, authority)
+		assert.DeepEqual(t, expected.maxAge, maxAge)
+		assert.DeepEqual(t, expected.clear, clear)
+		assert.DeepEqual(t, expected.ok, ok)
+	}
+}
+
+func TestAltSvcCache(t *testing.T) {
+	var c altSvcCache
+	c.learn("foo.com", "foo.com", nil)
+	_, ok := c.get("foo.com")
+	assert.False(t, ok)
+
+	c.learn("foo.com", "foo.com", []byte(`h3=":443"`))
+	addr, ok := c.get("foo.com")
+	assert.True(t, ok)
+	assert.DeepEqual(t, "foo.com:443", addr)
+
+	c.learn("[::1]:8443", "::1", []byte(`h3=":8443"`))
+	addr, _ = c.get("[::1]:8443")
+	assert.DeepEqual(t, "[::1]:8443", addr)
+
+	c.markBroken("foo.com")
+	_, ok = c.get("foo.com")
+	assert.False(t, ok)
+
+	c.learn("bar.com", "bar.com", []byte(`h3="alt.bar.com:443"`))
+	addr, _ = c.get("bar.com")
+	assert.DeepEqual(t, "alt.bar.com:443", addr)
+	c.learn("bar.com", "bar.com", []byte(`clear`))
+	_, ok = c.get("bar.com")
+	assert.False(t, ok)
+}
+
+type fakeHostClient struct {
+	addr       string
+	serverName string
+	do         func(req *protocol.Request, resp *protocol.Response) error
+	closed     int
+}
+
+func (f *fakeHostClient) Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	return f.do(req, resp)
+}
+
+func (f *fakeHostClient) SetDynamicConfig(dc *client.DynamicConfig) {
+	f.addr, f.serverName = dc.Addr, dc.ServerName
+}
+func (f *fakeHostClient) CloseIdleConnections() { f.closed++ }
+func (f *fakeHostClient) ShouldRemove() bool    { return false }
+func (f *fakeHostClient) ConnectionCount() int  { return 0 }
+
+type fakeClientFactory struct {
+	do      func(addr string, req *protocol.Request, resp *protocol.Response) error
+	clients []*fakeHostClient
+}
+
+func (f *fakeClientFactory) NewHostClient() (client.HostClient, error) {
+	hc := &fakeHostClient{}
+	hc.do = func(req *protocol.Request, resp *protocol.Response) error {
+		return f.do(hc.addr, req, resp)
+	}
+	f.clients = append(f.clients, hc)
+	return hc, nil
+}
+
+func TestClientHTTP3AltSvc(t *testing.T) {
+	var tcpAddrs, h3Addrs []string
+	h3Err := error(nil)
+	c, _ := NewClient()
+	c.SetClientFactory(&fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		tcpAddrs = append(tcpAddrs, addr)
+		resp.Header.Set(consts.HeaderAltSvc, `h3=":443"; ma=60`)
+		return nil
+	}})
+	c.SetHTTP3ClientFactory(&fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		h3Addrs = append(h3Addrs, addr)
+		return h3Err
+	}})
+
+	do := func(uri string) error {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		defer func() {
+			protocol.ReleaseRequest(req)
+			protocol.ReleaseResponse(resp)
+		}()
+		req.SetRequestURI(uri)
+		return c.Do(context.Background(), req, resp)
+	}
+
+	// the alternative service is learned from the first response over TCP
+	assert.Nil(t, do("https://foo.com/a"))
+	assert.Nil(t, do("https://foo.com/b"))
+	assert.DeepEqual(t, []string{"foo.com:443"}, tcpAddrs)
+	assert.DeepEqual(t, []string{"foo.com:443"}, h3Addrs)
+
+	// plain HTTP never learns
+	assert.Nil(t, do("http://bar.com/a"))
+	assert.Nil(t, do("http://bar.com/b"))
+	assert.DeepEqual(t, 3, len(tcpAddrs))
+	assert.DeepEqual(t, 1, len(h3Addrs))
+
+	// fall back to TCP if HTTP/3 fails, and stop using HTTP/3
+	h3Err = errors.New("quic handshake failed")
+	assert.Nil(t, do("https://foo.com/c"))
+	assert.Nil(t, do("https://foo.com/d"))
+	assert.DeepEqual(t, 5, len(tcpAddrs))
+	assert.DeepEqual(t, 2, len(h3Addrs))
+}
+
+func TestClientHTTP3Fallback(t *testing.T) {
+	var tcpMethods []string
+	h3Err := error(nil)
+	h3 := &fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		return h3Err
+	}}
+	c, _ := NewClient()
+	c.SetClientFactory(&fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		tcpMethods = append(tcpMethods, string(req.Header.Method()))
+		resp.Header.Set(consts.HeaderAltSvc, `h3=":443"; ma=60`)
+		return nil
+	}})
+	c.SetHTTP3ClientFactory(h3)
+
+	do := func(method string) error {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		defer func() {
+			protocol.ReleaseRequest(req)
+			protocol.ReleaseResponse(resp)
+		}()
+		req.SetRequestURI("https://foo.com/a")
+		req.Header.SetMethod(method)
+		return c.Do(context.Background(), req, resp)
+	}
+	learn := func() {
+		c.altSvcs = altSvcCache{}
+		assert.Nil(t, do(consts.MethodGet))
+	}
+
+	// the request which may have been processed over HTTP/3 is not sent again
+	learn()
+	h3Err = errors.New("stream reset")
+	assert.DeepEqual(t, h3Err, do(consts.MethodPost))
+	assert.DeepEqual(t, []string{consts.MethodGet}, tcpMethods)
+	_, ok := c.altSvcs.get("foo.com")
+	assert.False(t, ok)
+
+	// the request which was never sent falls back to TCP
+	learn()
+	h3Err = &net.OpError{Op: "dial", Net: "udp", Err: errors.New("quic handshake failed")}
+	assert.Nil(t, do(consts.MethodPost))
+	assert.DeepEqual(t, []string{consts.MethodGet, consts.MethodGet, consts.MethodPost}, tcpMethods)
+
+	// the HTTP/3 host clients are closed with the others
+	assert.DeepEqual(t, 1, len(h3.clients))
+	c.CloseIdleConnections()
+	assert.DeepEqual(t, 1, h3.clients[0].closed)
+}
+
+func TestClientHTTP3ServerName(t *testing.T) {
+	h3 := &fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		return nil
+	}}
+	c, _ := NewClient()
+	c.SetClientFactory(&fakeClientFactory{do: func(addr string, req *protocol.Request, resp *protocol.Response) error {
+		resp.Header.Set(consts.HeaderAltSvc, `h3="alt.com:443"; ma=60`)
+		return nil
+	}})
+	c.SetHTTP3ClientFactory(h3)
+
+	for _, uri := range []string{"https://foo.com/a", "https://foo.com/b", "https://bar.com:8443/a", "https://bar.com:8443/b"} {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		req.SetRequestURI(uri)
+		assert.Nil(t, c.Do(context.Background(), req, resp))
+		protocol.ReleaseRequest(req)
+		protocol.ReleaseResponse(resp)
+	}
+
+	// the origins sharing the alternative service have their own host clients verifying them
+	assert.DeepEqual(t, 2, len(h3.clients))
+	assert.DeepEqual(t, "alt.com:443", h3.clients[0].addr)
+	assert.DeepEqual(t, "foo.com", h3.clients[0].serverName)
+	assert.DeepEqual(t, "alt.com:443", h3.clients[1].addr)
+	assert.DeepEqual(t, "bar.com", h3.clients[1].serverName)
+}
+
+func TestClientStartCleanerOnce(t *testing.T) {
+	c, _ := NewClient()
+	c.mLock.Lock()
+	defer c.mLock.Unlock()
+	assert.True(t, c.startCleanerLocked())
+	assert.False(t, c.startCleanerLocked())
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package circuitbreak
+
+import (
+	"sync"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+// State is the state of a circuit breaker.
+type State int32
+
+const (
+	// StateClosed lets all requests pass and counts the failures.
+	StateClosed State = iota
+	// StateOpen rejects all requests until OpenTimeout elapses.
+	StateOpen
+	// StateHalfOpen lets a limited number of probing requests pass,
+	// the breaker is closed if all of them succeed, or opened again on any failure.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Config All configurations related to circuit breaker
+type Config struct {
+	// The number of consecutive failures which opens the breaker, 0 means disabled
+	ConsecutiveFailures uint32
+
+	// The failure rate within Window which opens the breaker, 0 means disabled
+	ErrorRate float64
+
+	// The minimum number of requests within Window before ErrorRate takes effect
+	MinRequests uint32
+
+	// The interval at which the counts of the closed breaker are cleared,
+	// 0 means the counts are only cleared on state changes
+	Window time.Duration
+
+	// The duration of the open state before the breaker becomes half-open
+	OpenTimeout time.Duration
+
+	// The number of probing requests allowed in the half-open state
+	HalfOpenRequests uint32
+
+	// IsFailure reports whether a request is failed by its response status code and error.
+	// The status code is 0 if the response is unavailable.
+	// By default, errors and status codes of 5xx are considered failures
+	IsFailure func(statusCode int, err error) bool
+
+	// OnStateChange is called when the state of the breaker named name changes.
+	// It is called with the breaker locked, so the methods of the breaker must not be called in it
+	OnStateChange func(name string, from, to State)
+}
+
+func (o *Config) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+// DefaultIsFailure considers errors and status codes of 5xx as failures
+func DefaultIsFailure(statusCode int, err error) bool {
+	return err != nil || statusCode >= 500
+}
+
+// Counts holds the numbers of requests of a circuit breaker in the current state or window.
+type Counts struct {
+	Requests             uint32
+	Successes            uint32
+	Failures             uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+func (c *Counts) onSuccess() {
+	c.Successes++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
+}
+
+func (c *Counts) onFailure() {
+	c.Failures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
+}
+
+// Breaker is a circuit breaker, which stops the requests to a failing upstream
+// for a while and probes it before letting all requests pass again.
+type Breaker struct {
+	name   string
+	config *Config
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	counts     Counts
+	expiry     time.Time
+}
+
+// NewBreaker creates a closed Breaker named name with config.
+func NewBreaker(name string, config *Config) *Breaker {
+	b := &Breaker{name: name, config: config}
+	b.toNewGeneration(time.Now())
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, _ := b.currentState(time.Now())
+	return state
+}
+
+// Counts returns the numbers of requests in the current state or window.
+func (b *Breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.currentState(time.Now())
+	return b.counts
+}
+
+// Allow checks whether a request can pass, and returns the generation which must be passed to Done
+// along with the result of the request.
+//
+// ErrCircuitOpen is returned if the breaker is open, or the probing requests of the half-open breaker
+// are exhausted.
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, generation := b.currentState(time.Now())
+	if state == StateOpen {
+		return generation, errs.ErrCircuitOpen
+	}
+	if state == StateHalfOpen && b.counts.Requests >= b.halfOpenRequests() {
+		return generation, errs.ErrCircuitOpen
+	}
+	b.counts.Requests++
+	return generation, nil
+}
+
+// Done records the result of the request allowed in generation.
+// The results of the previous generations are ignored.
+func (b *Breaker) Done(generation uint64, statusCode int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	state, current := b.currentState(now)
+	if generation != current {
+		return
+	}
+
+	isFailure := b.config.IsFailure
+	if isFailure == nil {
+		isFailure = DefaultIsFailure
+	}
+	if !isFailure(statusCode, err) {
+		b.counts.onSuccess()
+		if state == StateHalfOpen && b.counts.ConsecutiveSuccesses >= b.halfOpenRequests() {
+			b.setState(StateClosed, now)
+		}
+		return
+	}
+
+	b.counts.onFailure()
+	if state == StateHalfOpen || b.shouldOpen() {
+		b.setState(StateOpen, now)
+	}
+}
+
+func (b *Breaker) halfOpenRequests() uint32 {
+	if b.config.HalfOpenRequests == 0 {
+		return 1
+	}
+	return b.config.HalfOpenRequests
+}
+
+func (b *Breaker) shouldOpen() bool {
+	if b.config.ConsecutiveFailures > 0 && b.counts.ConsecutiveFailures >= b.config.ConsecutiveFailures {
+		return true
+	}
+	if b.config.ErrorRate > 0 && b.counts.Requests > 0 && b.counts.Requests >= b.config.MinRequests {
+		return float64(b.counts.Failures)/float64(b.counts.Requests) >= b.config.ErrorRate
+	}
+	return false
+}
+
+func (b *Breaker) currentState(now time.Time) (State, uint64) {
+	switch b.state {
+	case StateClosed:
+		if !b.expiry.IsZero() && b.expiry.Before(now) {
+			b.toNewGeneration(now)
+		}
+	case StateOpen:
+		if b.expiry.Before(now) {
+			b.setState(StateHalfOpen, now)
+		}
+	}
+	return b.state, b.generation
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	if b.state == state {
+		return
+	}
+	prev := b.state
+	b.state = state
+	b.toNewGeneration(now)
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(b.name, prev, state)
+	}
+}
+
+func (b *Breaker) toNewGeneration(now time.Time) {
+	b.generation++
+	b.counts = Counts{}
+
+	var zero time.Time
+	switch b.state {
+	case StateClosed:
+		if b.config.Window == 0 {
+			b.expiry = zero
+		} else {
+			b.expiry = now.Add(b.config.Window)
+		}
+	case StateOpen:
+		b.expiry = now.Add(b.config.OpenTimeout)
+	default:
+		b.expiry = zero
+	}
+}
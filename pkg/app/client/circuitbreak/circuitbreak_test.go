@@ -0,0 +1,151 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package circuitbreak
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+var errFoo = errors.New("foo")
+
+func TestApply(t *testing.T) {
+	isFailure := func(statusCode int, err error) bool { return err != nil }
+	config := Config{}
+	config.Apply([]Option{
+		WithConsecutiveFailures(3),
+		WithErrorRate(0.5, 10),
+		WithWindow(time.Second),
+		WithOpenTimeout(2 * time.Second),
+		WithHalfOpenRequests(2),
+		WithFailurePolicy(isFailure),
+		WithStateChangeFunc(func(name string, from, to State) {}),
+	})
+	assert.DeepEqual(t, uint32(3), config.ConsecutiveFailures)
+	assert.DeepEqual(t, 0.5, config.ErrorRate)
+	assert.DeepEqual(t, uint32(10), config.MinRequests)
+	assert.DeepEqual(t, time.Second, config.Window)
+	assert.DeepEqual(t, 2*time.Second, config.OpenTimeout)
+	assert.DeepEqual(t, uint32(2), config.HalfOpenRequests)
+	assert.False(t, config.IsFailure(500, nil))
+	assert.NotNil(t, config.OnStateChange)
+}
+
+func TestBreakerConsecutiveFailures(t *testing.T) {
+	var changes []string
+	b := NewBreaker("foobar", &Config{
+		ConsecutiveFailures: 2,
+		OpenTimeout:         50 * time.Millisecond,
+		HalfOpenRequests:    2,
+		OnStateChange: func(name string, from, to State) {
+			changes = append(changes, name+":"+from.String()+"->"+to.String())
+		},
+	})
+
+	g, err := b.Allow()
+	assert.Nil(t, err)
+	b.Done(g, 500, nil)
+	g, _ = b.Allow()
+	b.Done(g, 200, nil)
+	g, _ = b.Allow()
+	b.Done(g, 0, errFoo)
+	assert.DeepEqual(t, StateClosed, b.State())
+	g, _ = b.Allow()
+	b.Done(g, 0, errFoo)
+	assert.DeepEqual(t, StateOpen, b.State())
+
+	_, err = b.Allow()
+	assert.DeepEqual(t, errs.ErrCircuitOpen, err)
+	// the results of the previous state are ignored
+	b.Done(g, 200, nil)
+	assert.DeepEqual(t, StateOpen, b.State())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.DeepEqual(t, StateHalfOpen, b.State())
+	g1, err := b.Allow()
+	assert.Nil(t, err)
+	g2, err := b.Allow()
+	assert.Nil(t, err)
+	_, err = b.Allow()
+	assert.DeepEqual(t, errs.ErrCircuitOpen, err)
+	b.Done(g1, 200, nil)
+	assert.DeepEqual(t, StateHalfOpen, b.State())
+	b.Done(g2, 200, nil)
+	assert.DeepEqual(t, StateClosed, b.State())
+
+	// any failure in the half-open state opens the breaker again
+	for i := 0; i < 2; i++ {
+		g, _ = b.Allow()
+		b.Done(g, 0, errFoo)
+	}
+	time.Sleep(60 * time.Millisecond)
+	g, err = b.Allow()
+	assert.Nil(t, err)
+	b.Done(g, 503, nil)
+	assert.DeepEqual(t, StateOpen, b.State())
+
+	assert.DeepEqual(t, []string{
+		"foobar:closed->open",
+		"foobar:open->half-open",
+		"foobar:half-open->closed",
+		"foobar:closed->open",
+		"foobar:open->half-open",
+		"foobar:half-open->open",
+	}, changes)
+}
+
+func TestBreakerErrorRate(t *testing.T) {
+	b := NewBreaker("foobar", &Config{
+		ErrorRate:   0.5,
+		MinRequests: 4,
+		Window:      50 * time.Millisecond,
+		OpenTimeout: time.Second,
+	})
+	for _, failed := range []bool{true, false, true} {
+		g, err := b.Allow()
+		assert.Nil(t, err)
+		if failed {
+			b.Done(g, 0, errFoo)
+		} else {
+			b.Done(g, 200, nil)
+		}
+	}
+	assert.DeepEqual(t, StateClosed, b.State())
+	assert.DeepEqual(t, Counts{Requests: 3, Successes: 1, Failures: 2, ConsecutiveFailures: 1}, b.Counts())
+
+	// the counts are cleared in the next window
+	time.Sleep(60 * time.Millisecond)
+	assert.DeepEqual(t, Counts{}, b.Counts())
+	for i := 0; i < 3; i++ {
+		g, _ := b.Allow()
+		b.Done(g, 200, nil)
+	}
+	g, _ := b.Allow()
+	b.Done(g, 0, errFoo)
+	assert.DeepEqual(t, StateClosed, b.State())
+
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		g, _ := b.Allow()
+		b.Done(g, 0, errFoo)
+	}
+	assert.DeepEqual(t, StateOpen, b.State())
+}
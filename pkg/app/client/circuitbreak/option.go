@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package circuitbreak
+
+import "time"
+
+// Option is the only struct that can be used to set circuit breaker Config.
+type Option struct {
+	F func(o *Config)
+}
+
+// WithConsecutiveFailures set ConsecutiveFailures.
+func WithConsecutiveFailures(failures uint32) Option {
+	return Option{F: func(o *Config) {
+		o.ConsecutiveFailures = failures
+	}}
+}
+
+// WithErrorRate set ErrorRate and MinRequests.
+func WithErrorRate(rate float64, minRequests uint32) Option {
+	return Option{F: func(o *Config) {
+		o.ErrorRate = rate
+		o.MinRequests = minRequests
+	}}
+}
+
+// WithWindow set Window.
+func WithWindow(window time.Duration) Option {
+	return Option{F: func(o *Config) {
+		o.Window = window
+	}}
+}
+
+// WithOpenTimeout set OpenTimeout.
+func WithOpenTimeout(timeout time.Duration) Option {
+	return Option{F: func(o *Config) {
+		o.OpenTimeout = timeout
+	}}
+}
+
+// WithHalfOpenRequests set HalfOpenRequests.
+func WithHalfOpenRequests(requests uint32) Option {
+	return Option{F: func(o *Config) {
+		o.HalfOpenRequests = requests
+	}}
+}
+
+// WithFailurePolicy set IsFailure.
+func WithFailurePolicy(isFailure func(statusCode int, err error) bool) Option {
+	return Option{F: func(o *Config) {
+		o.IsFailure = isFailure
+	}}
+}
+
+// WithStateChangeFunc set OnStateChange.
+func WithStateChangeFunc(onStateChange func(name string, from, to State)) Option {
+	return Option{F: func(o *Config) {
+		o.OnStateChange = onStateChange
+	}}
+}
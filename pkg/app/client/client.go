@@ -46,6 +46,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"reflect"
 	"strings"
 	"sync"
@@ -53,6 +54,7 @@ import (
 
 	"github.com/cloudwego/hertz/internal/bytestr"
 	"github.com/cloudwego/hertz/internal/nocopy"
+	"github.com/cloudwego/hertz/pkg/app/client/ratelimit"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
@@ -267,12 +269,30 @@ type Client struct {
 	// RetryIfFunc sets the retry decision function. If nil, the client.DefaultRetryIf will be applied.
 	RetryIfFunc client.RetryIfFunc
 
+	// RedirectPolicy decides how the redirects are followed by Get, Post and DoRedirects.
+	// If nil, the request is resent to the new location as is.
+	RedirectPolicy *client.RedirectPolicy
+
 	clientFactory suite.ClientFactory
 
+	http3ClientFactory suite.ClientFactory
+	altSvcs            altSvcCache
+
+	limiter *ratelimit.Limiter
+
 	mLock sync.Mutex
 	m     map[string]client.HostClient
 	ms    map[string]client.HostClient
+	m3    map[string]client.HostClient
 	mws   Middleware
+
+	// cleanerRunning means mCleaner is running, it is guarded by mLock.
+	cleanerRunning bool
+
+	// parent owns the connection pools shared by the clone
+	parent       *Client
+	cloneOptions *config.RequestOptions
+	cloneHeaders []cloneHeader
 }
 
 func (c *Client) GetOptions() *config.ClientOptions {
@@ -291,6 +311,16 @@ func (c *Client) SetRetryIf(fn func(request *protocol.Request) bool) {
 	c.SetRetryIfFunc(f)
 }
 
+// SetRedirectPolicy sets the policy of following redirects.
+func (c *Client) SetRedirectPolicy(p *client.RedirectPolicy) {
+	c.RedirectPolicy = p
+}
+
+// GetRedirectPolicy returns the policy of following redirects.
+func (c *Client) GetRedirectPolicy() *client.RedirectPolicy {
+	return c.RedirectPolicy
+}
+
 // SetProxy is used to set client proxy.
 //
 // Don't SetProxy twice for a client.
@@ -451,20 +481,91 @@ func (c *Client) DoRedirects(ctx context.Context, req *protocol.Request, resp *p
 // It is recommended obtaining req and resp via AcquireRequest
 // and AcquireResponse in performance-critical code.
 func (c *Client) Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	do := c.do
+	if c.parent != nil {
+		do = c.doClone
+	}
 	if c.mws == nil {
-		return c.do(ctx, req, resp)
+		return do(ctx, req, resp)
 	}
-	return c.mws(c.do)(ctx, req, resp)
+	return c.mws(do)(ctx, req, resp)
+}
+
+// unixSocketFunc returns the function looking up the socket paths for host names,
+// or nil if paths is empty.
+func unixSocketFunc(paths map[string]string) func(host string) string {
+	if len(paths) == 0 {
+		return nil
+	}
+	return func(host string) string {
+		if path, ok := paths[strings.ToLower(host)]; ok {
+			return path
+		}
+		return paths[""]
+	}
+}
+
+// addrOverrideFunc returns the function looking up the overridden addresses for addrs,
+// or nil if overrides is empty.
+func addrOverrideFunc(overrides map[string][]string) func(addr string) []string {
+	if len(overrides) == 0 {
+		return nil
+	}
+	return func(addr string) []string {
+		addr = strings.ToLower(addr)
+		if addrs, ok := overrides[addr]; ok {
+			return addrs
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil
+		}
+		addrs, ok := overrides[host]
+		if !ok {
+			return nil
+		}
+		ret := make([]string, len(addrs))
+		for i, a := range addrs {
+			if _, _, err := net.SplitHostPort(a); err != nil {
+				a = net.JoinHostPort(a, port)
+			}
+			ret[i] = a
+		}
+		return ret
+	}
+}
+
+// dialAddr returns the address to dial for uri, in which the zone id of IPv6 literal is decoded.
+func dialAddr(uri *protocol.URI, isTLS bool) string {
+	host := string(uri.Host())
+	if !strings.HasPrefix(host, "[") {
+		return utils.AddMissingPort(host, isTLS)
+	}
+	port := string(uri.Port())
+	if port == "" {
+		port = "80"
+		if isTLS {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(string(uri.Hostname()), port)
 }
 
 func (c *Client) do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
 	if !c.options.KeepAlive {
 		req.Header.SetConnectionClose(true)
 	}
+	if c.options.AutoDecompress && len(req.Header.Peek(consts.HeaderAcceptEncoding)) == 0 {
+		req.Header.Set(consts.HeaderAcceptEncoding, protocol.SupportedContentEncodings)
+	}
 	uri := req.URI()
 	if uri == nil {
 		return errorInvalidURI
 	}
+	// internationalized host is sent in the punycode form
+	if err := uri.ToASCII(); err != nil {
+		return err
+	}
 
 	var proxyURI *protocol.URI
 	var err error
@@ -486,6 +587,32 @@ func (c *Client) do(ctx context.Context, req *protocol.Request, resp *protocol.R
 	host := uri.Host()
 	startCleaner := false
 
+	if c.limiter != nil {
+		key := req.Options().RateLimitKey()
+		if key == "" {
+			key = string(host)
+		}
+		if err = c.limiter.Take(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	if isTLS && proxyURI == nil && c.http3ClientFactory != nil {
+		if addr, ok := c.altSvcs.get(string(host)); ok {
+			err = c.doHTTP3(ctx, string(host), addr, req, resp)
+			if err == nil {
+				return c.decompress(req, resp)
+			}
+			// stop using the alternative service for a while, and fall back to TCP
+			// unless the request may have been processed by the server
+			c.altSvcs.markBroken(string(host))
+			if !canRetryOverTCP(req, err) {
+				return err
+			}
+			hlog.SystemLogger().Warnf("HTTP/3 request to %s failed, falling back to TCP: %s", addr, err.Error())
+		}
+	}
+
 	c.mLock.Lock()
 
 	m := c.m
@@ -502,16 +629,69 @@ func (c *Client) do(ctx context.Context, req *protocol.Request, resp *protocol.R
 		}
 		hc, _ = c.clientFactory.NewHostClient()
 		hc.SetDynamicConfig(&client.DynamicConfig{
-			Addr:     utils.AddMissingPort(h, isTLS),
+			Addr:     dialAddr(uri, isTLS),
 			ProxyURI: proxyURI,
 			IsTLS:    isTLS,
 		})
 		m[h] = hc
-		if len(m) == 1 {
-			startCleaner = true
+		startCleaner = c.startCleanerLocked()
+	}
+
+	c.mLock.Unlock()
+
+	if startCleaner {
+		go c.mCleaner()
+	}
+
+	err = hc.Do(ctx, req, resp)
+	if err != nil {
+		return err
+	}
+	if isTLS && proxyURI == nil && c.http3ClientFactory != nil && resp != nil {
+		c.altSvcs.learn(h, string(uri.Hostname()), resp.Header.Peek(consts.HeaderAltSvc))
+	}
+	return c.decompress(req, resp)
+}
+
+// decompress decodes the response body if AutoDecompress is enabled.
+func (c *Client) decompress(req *protocol.Request, resp *protocol.Response) error {
+	if !c.options.AutoDecompress || resp == nil {
+		return nil
+	}
+	maxBodySize := c.options.MaxDecompressedBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = c.options.MaxResponseBodySize
+		if n := req.Options().MaxResponseBodySize(); n > 0 {
+			maxBodySize = n
 		}
 	}
+	return resp.DecompressBody(maxBodySize)
+}
 
+// doHTTP3 performs the request to origin with the HTTP/3 host client of the alternative service addr,
+// which verifies the certificate of the origin.
+func (c *Client) doHTTP3(ctx context.Context, origin, addr string, req *protocol.Request, resp *protocol.Response) error {
+	startCleaner := false
+	key := origin + " " + addr
+	c.mLock.Lock()
+	hc := c.m3[key]
+	if hc == nil {
+		var err error
+		if hc, err = c.http3ClientFactory.NewHostClient(); err != nil {
+			c.mLock.Unlock()
+			return err
+		}
+		hc.SetDynamicConfig(&client.DynamicConfig{
+			Addr:       addr,
+			IsTLS:      true,
+			ServerName: string(req.URI().Hostname()),
+		})
+		if c.m3 == nil {
+			c.m3 = make(map[string]client.HostClient)
+		}
+		c.m3[key] = hc
+		startCleaner = c.startCleanerLocked()
+	}
 	c.mLock.Unlock()
 
 	if startCleaner {
@@ -526,34 +706,76 @@ func (c *Client) do(ctx context.Context, req *protocol.Request, resp *protocol.R
 // "keep-alive" state. It does not interrupt any connections currently
 // in use.
 func (c *Client) CloseIdleConnections() {
+	if c.parent != nil {
+		c.parent.CloseIdleConnections()
+		return
+	}
 	c.mLock.Lock()
 	for _, v := range c.m {
 		v.CloseIdleConnections()
 	}
+	for _, v := range c.m3 {
+		v.CloseIdleConnections()
+	}
 	c.mLock.Unlock()
 }
 
+// Stats returns the conn pool state of each host client created by the client.
+//
+// NOTE:
+//
+//	Use WithConnStateObserve to get the states periodically.
+func (c *Client) Stats() []config.ConnPoolState {
+	if c.parent != nil {
+		return c.parent.Stats()
+	}
+	c.mLock.Lock()
+	defer c.mLock.Unlock()
+	states := make([]config.ConnPoolState, 0, len(c.m)+len(c.ms)+len(c.m3))
+	for _, m := range []map[string]client.HostClient{c.m, c.ms, c.m3} {
+		for _, v := range m {
+			if hs, ok := v.(config.HostClientState); ok {
+				states = append(states, hs.ConnPoolState())
+			}
+		}
+	}
+	return states
+}
+
+// startCleanerLocked reports whether mCleaner should be started, which is the case if it is not running.
+// It must be called with mLock held.
+func (c *Client) startCleanerLocked() bool {
+	if c.cleanerRunning {
+		return false
+	}
+	c.cleanerRunning = true
+	return true
+}
+
 func (c *Client) mCleaner() {
 	mustStop := false
 
 	for {
 		time.Sleep(10 * time.Second)
 		c.mLock.Lock()
-		for k, v := range c.m {
-			shouldRemove := v.ShouldRemove()
-
-			if shouldRemove {
-				delete(c.m, k)
-				if f, ok := v.(io.Closer); ok {
-					err := f.Close()
-					if err != nil {
-						hlog.Warnf("clean hostclient error, addr: %s, err: %s", k, err.Error())
+		for _, m := range []map[string]client.HostClient{c.m, c.ms, c.m3} {
+			for k, v := range m {
+				shouldRemove := v.ShouldRemove()
+
+				if shouldRemove {
+					delete(m, k)
+					if f, ok := v.(io.Closer); ok {
+						err := f.Close()
+						if err != nil {
+							hlog.Warnf("clean hostclient error, addr: %s, err: %s", k, err.Error())
+						}
 					}
 				}
 			}
 		}
-		if len(c.m) == 0 {
+		if len(c.m) == 0 && len(c.ms) == 0 && len(c.m3) == 0 {
 			mustStop = true
+			c.cleanerRunning = false
 		}
 		c.mLock.Unlock()
 
@@ -567,6 +789,23 @@ func (c *Client) SetClientFactory(cf suite.ClientFactory) {
 	c.clientFactory = cf
 }
 
+// SetHTTP3ClientFactory sets the factory of HTTP/3 host clients, e.g. the one of hertz-contrib/http3,
+// which enables the client to learn the HTTP/3 alternative services from the Alt-Svc headers of
+// HTTPS responses, and to send the subsequent requests of the origins over QUIC.
+// Hertz does not implement QUIC itself, HTTP/3 is not used without the factory.
+//
+// NOTE:
+//
+//	A host client is created for each pair of the origin and its alternative service,
+//	with DynamicConfig.ServerName set to the origin host, which must be verified by TLS.
+//	The alternative service is not used for 5 minutes after a request fails over HTTP/3.
+//	The request falls back to TCP if connecting to the alternative service fails, or if it's
+//	idempotent and its body is not a stream, so that it's never processed twice by the server.
+//	The alternative services are not used through proxies.
+func (c *Client) SetHTTP3ClientFactory(cf suite.ClientFactory) {
+	c.http3ClientFactory = cf
+}
+
 // GetDialerName returns the name of the dialer
 func (c *Client) GetDialerName() (dName string, err error) {
 	defer func() {
@@ -602,6 +841,9 @@ func NewClient(opts ...config.ClientOption) (*Client, error) {
 		m:       make(map[string]client.HostClient),
 		ms:      make(map[string]client.HostClient),
 	}
+	if opt.RateLimitConfig != nil {
+		c.limiter = ratelimit.NewLimiter(opt.RateLimitConfig)
+	}
 
 	return c, nil
 }
@@ -624,17 +866,24 @@ func newHttp1OptionFromClient(c *Client) *http1.ClientOptions {
 		DialTimeout:                   c.options.DialTimeout,
 		DialDualStack:                 c.options.DialDualStack,
 		TLSConfig:                     c.options.TLSConfig,
+		HostTLSConfig:                 hostTLSConfigFunc(c.options.HostTLSConfigs),
+		UnixSocket:                    unixSocketFunc(c.options.UnixSockets),
+		AddrOverride:                  addrOverrideFunc(c.options.AddrOverrides),
 		MaxConns:                      c.options.MaxConnsPerHost,
 		MaxConnDuration:               c.options.MaxConnDuration,
 		MaxIdleConnDuration:           c.options.MaxIdleConnDuration,
 		ReadTimeout:                   c.options.ReadTimeout,
 		WriteTimeout:                  c.options.WriteTimeout,
+		TLSHandshakeTimeout:           c.options.TLSHandshakeTimeout,
+		ResponseHeaderTimeout:         c.options.ResponseHeaderTimeout,
 		MaxResponseBodySize:           c.options.MaxResponseBodySize,
 		DisableHeaderNamesNormalizing: c.options.DisableHeaderNamesNormalizing,
 		DisablePathNormalizing:        c.options.DisablePathNormalizing,
 		MaxConnWaitTimeout:            c.options.MaxConnWaitTimeout,
 		ResponseBodyStream:            c.options.ResponseBodyStream,
 		RetryConfig:                   c.options.RetryConfig,
+		CircuitBreakerConfig:          c.options.CircuitBreakerConfig,
+		Resolver:                      c.options.DNSResolver,
 		RetryIfFunc:                   c.RetryIfFunc,
 		StateObserve:                  c.options.HostClientStateObserve,
 		ObservationInterval:           c.options.ObservationInterval,
@@ -64,7 +64,9 @@ import (
 
 	"github.com/cloudwego/hertz/internal/bytestr"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client/ratelimit"
 	"github.com/cloudwego/hertz/pkg/app/client/retry"
+	"github.com/cloudwego/hertz/pkg/common/compress"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
@@ -2027,6 +2029,88 @@ func TestClientRetry(t *testing.T) {
 	}
 }
 
+func TestClientRetryStatusCodes(t *testing.T) {
+	opt := config.NewOptions([]config.Option{})
+	opt.Addr = "127.0.0.1:10040"
+	engine := route.NewEngine(opt)
+	var attempts int32
+	engine.GET("/retry", func(ctx context.Context, c *app.RequestContext) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			c.Response.Header.Set(consts.HeaderRetryAfter, "1")
+			c.String(consts.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		c.String(consts.StatusOK, "ok")
+	})
+	engine.POST("/retry", func(ctx context.Context, c *app.RequestContext) {
+		atomic.AddInt32(&attempts, 1)
+		c.String(consts.StatusServiceUnavailable, "unavailable")
+	})
+	go engine.Run()
+	defer func() {
+		engine.Close()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client, _ := NewClient(WithRetryConfig(
+		retry.WithMaxAttemptTimes(3),
+		retry.WithRetryStatusCodes(consts.StatusServiceUnavailable),
+		retry.WithRespectRetryAfter(true),
+		retry.WithMaxDelay(50*time.Millisecond),
+	))
+	startTime := time.Now()
+	status, body, err := client.Get(context.Background(), nil, "http://127.0.0.1:10040/retry")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, status)
+	assert.DeepEqual(t, "ok", string(body))
+	assert.DeepEqual(t, int32(3), atomic.LoadInt32(&attempts))
+	// Retry-After is limited by MaxDelay
+	assert.True(t, time.Since(startTime) >= 100*time.Millisecond)
+	assert.True(t, time.Since(startTime) < time.Second)
+
+	// the last response is returned if all attempts fail
+	atomic.StoreInt32(&attempts, -10)
+	status, body, err = client.Get(context.Background(), nil, "http://127.0.0.1:10040/retry")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, status)
+	assert.DeepEqual(t, "unavailable", string(body))
+	assert.DeepEqual(t, int32(-7), atomic.LoadInt32(&attempts))
+
+	// non-idempotent requests are not retried by default
+	atomic.StoreInt32(&attempts, 0)
+	status, _, err = client.Post(context.Background(), nil, "http://127.0.0.1:10040/retry", nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, status)
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestClientRetryErrors(t *testing.T) {
+	errDial := errors.New("dial error")
+	var dials int32
+	newClient := func(opts ...retry.Option) *Client {
+		c, _ := NewClient(
+			WithDialFunc(func(addr string) (network.Conn, error) {
+				atomic.AddInt32(&dials, 1)
+				return nil, errDial
+			}),
+			WithRetryConfig(append([]retry.Option{retry.WithMaxAttemptTimes(3)}, opts...)...),
+		)
+		c.SetRetryIfFunc(func(req *protocol.Request, resp *protocol.Response, err error) bool {
+			return err != nil
+		})
+		return c
+	}
+
+	_, _, err := newClient(retry.WithRetryErrors(errDial)).Get(context.Background(), nil, "http://127.0.0.1:1234/ping")
+	assert.True(t, errors.Is(err, errDial))
+	assert.DeepEqual(t, int32(3), atomic.LoadInt32(&dials))
+
+	atomic.StoreInt32(&dials, 0)
+	_, _, err = newClient(retry.WithRetryErrors(errs.ErrTimeout)).Get(context.Background(), nil, "http://127.0.0.1:1234/ping")
+	assert.True(t, errors.Is(err, errDial))
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&dials))
+}
+
 func TestClientDialerName(t *testing.T) {
 	client, _ := NewClient()
 	dName, err := client.GetDialerName()
@@ -2174,3 +2258,259 @@ func TestClientState(t *testing.T) {
 	client.Get(context.Background(), nil, "http://127.0.0.1:11000")
 	time.Sleep(time.Second * 22)
 }
+
+func TestClientStats(t *testing.T) {
+	client, err := NewClient(WithDialFunc(func(addr string) (network.Conn, error) {
+		return nil, errors.New("dial error")
+	}))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 0, len(client.Stats()))
+
+	_, _, err = client.Get(context.Background(), nil, "http://foobar.com/baz")
+	assert.True(t, err != nil)
+
+	stats := client.Stats()
+	assert.DeepEqual(t, 1, len(stats))
+	assert.DeepEqual(t, "foobar.com:80", stats[0].Addr)
+	assert.DeepEqual(t, 0, stats[0].TotalConnNum)
+	assert.True(t, stats[0].DialFailures > 0)
+}
+
+func TestClientAddrOverride(t *testing.T) {
+	var dialed []string
+	client, err := NewClient(
+		WithDialFunc(func(addr string) (network.Conn, error) {
+			dialed = append(dialed, addr)
+			return nil, errors.New("dial error")
+		}),
+		WithAddrOverride("Example.com", "127.0.0.1:8080", "127.0.0.2"),
+		WithAddrOverride("example.com:8443", "127.0.0.3:443"),
+	)
+	assert.Nil(t, err)
+
+	_, _, err = client.Get(context.Background(), nil, "http://example.com/foo")
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "127.0.0.1:8080", dialed[0])
+	assert.DeepEqual(t, "127.0.0.2:80", dialed[1])
+
+	dialed = nil
+	_, _, err = client.Get(context.Background(), nil, "http://example.com:8443/foo")
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "127.0.0.3:443", dialed[0])
+
+	dialed = nil
+	_, _, err = client.Get(context.Background(), nil, "http://foobar.com/foo")
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "foobar.com:80", dialed[0])
+}
+
+func TestClientRateLimit(t *testing.T) {
+	dials := 0
+	client, err := NewClient(
+		WithDialFunc(func(addr string) (network.Conn, error) {
+			dials++
+			return nil, errors.New("dial error")
+		}),
+		WithRateLimit(ratelimit.WithRate(1, 1)),
+	)
+	assert.Nil(t, err)
+
+	_, _, err = client.Get(context.Background(), nil, "http://example.com/foo")
+	assert.True(t, err != errs.ErrRateLimited)
+	_, _, err = client.Get(context.Background(), nil, "http://example.com/foo")
+	assert.DeepEqual(t, errs.ErrRateLimited, err)
+	assert.DeepEqual(t, 1, dials)
+
+	// the other hosts and keys are limited separately
+	_, _, err = client.Get(context.Background(), nil, "http://foobar.com/foo")
+	assert.True(t, err != errs.ErrRateLimited)
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	req.SetRequestURI("http://example.com/foo")
+	req.SetOptions(config.WithRateLimitKey("tenant"))
+	err = client.Do(context.Background(), req, resp)
+	assert.True(t, err != errs.ErrRateLimited)
+	assert.DeepEqual(t, 3, dials)
+}
+
+func TestClientIDNAHost(t *testing.T) {
+	var dialAddr string
+	client, err := NewClient(WithDialFunc(func(addr string) (network.Conn, error) {
+		dialAddr = addr
+		return nil, errors.New("dial error")
+	}))
+	assert.Nil(t, err)
+
+	req := protocol.AcquireRequest()
+	defer protocol.ReleaseRequest(req)
+	req.SetRequestURI("http://bücher.example/foo")
+	err = client.Do(context.Background(), req, nil)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "xn--bcher-kva.example:80", dialAddr)
+	assert.DeepEqual(t, "xn--bcher-kva.example", string(req.URI().Host()))
+}
+
+func TestClientDialAddr(t *testing.T) {
+	for uri, addr := range map[string]string{
+		"http://example.com/":           "example.com:80",
+		"https://example.com:8443/":     "example.com:8443",
+		"http://[::1]/":                 "[::1]:80",
+		"https://[fe80::1%25eth0]/":     "[fe80::1%eth0]:443",
+		"http://[fe80::1%25eth0]:8080/": "[fe80::1%eth0]:8080",
+	} {
+		assert.DeepEqual(t, addr, dialAddr(protocol.ParseURI(uri), strings.HasPrefix(uri, "https")))
+	}
+}
+
+func TestClientUnixSocket(t *testing.T) {
+	opt := config.NewOptions([]config.Option{})
+	opt.Addr = "unix-test-10103"
+	opt.Network = "unix"
+	engine := route.NewEngine(opt)
+	engine.GET("/info", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Write(ctx.Host()) //nolint:errcheck
+	})
+	go engine.Run()
+	defer func() {
+		engine.Close()
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	c, _ := NewClient(WithUnixSocket(opt.Addr, "Daemon"))
+	statusCode, body, err := c.Get(context.Background(), nil, "http://daemon/info")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, statusCode)
+	assert.DeepEqual(t, "daemon", string(body))
+
+	// the other hosts are dialed over TCP
+	_, _, err = c.Get(context.Background(), nil, "http://127.0.0.1:1/info")
+	assert.True(t, err != nil)
+
+	c, _ = NewClient(WithUnixSocket(opt.Addr))
+	_, body, err = c.Get(context.Background(), nil, "http://any.host:8080/info")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "any.host:8080", string(body))
+}
+
+func TestClientAutoDecompress(t *testing.T) {
+	opt := config.NewOptions([]config.Option{})
+	opt.Addr = "unix-test-10104"
+	opt.Network = "unix"
+	engine := route.NewEngine(opt)
+	engine.GET("/gzip", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Response.Header.Set("X-Accept-Encoding", string(ctx.Request.Header.Peek(consts.HeaderAcceptEncoding)))
+		ctx.Response.Header.SetContentEncoding("gzip")
+		ctx.Write(compress.AppendGzipBytes(nil, []byte("hello hertz"))) //nolint:errcheck
+	})
+	go engine.Run()
+	defer func() {
+		engine.Close()
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	c, _ := NewClient(WithUnixSocket(opt.Addr), WithAutoDecompress(true))
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	req.SetRequestURI("http://example.com/gzip")
+	err := c.Do(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello hertz", string(resp.Body()))
+	assert.DeepEqual(t, protocol.SupportedContentEncodings, string(resp.Header.Peek("X-Accept-Encoding")))
+	assert.DeepEqual(t, "gzip", string(resp.OriginalContentEncoding()))
+	assert.DeepEqual(t, 0, len(resp.Header.ContentEncoding()))
+
+	c, _ = NewClient(WithUnixSocket(opt.Addr),
+		WithAutoDecompress(true), WithMaxDecompressedBodySize(5))
+	req.Reset()
+	resp.Reset()
+	req.SetRequestURI("http://example.com/gzip")
+	err = c.Do(context.Background(), req, resp)
+	assert.DeepEqual(t, errs.ErrBodyTooLarge, err)
+}
+
+type dialTagKey struct{}
+
+func TestClientDialContext(t *testing.T) {
+	opt := config.NewOptions([]config.Option{})
+	opt.Addr = "127.0.0.1:10041"
+	engine := route.NewEngine(opt)
+	engine.GET("/", func(c context.Context, ctx *app.RequestContext) {
+		ctx.WriteString("ok") //nolint:errcheck
+	})
+	go engine.Run()
+	defer func() {
+		engine.Close()
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	var tag interface{}
+	var hasDeadline, upgraded bool
+	upgradeErr := errors.New("upgrade refused")
+	c, _ := NewClient(WithDialTimeout(time.Second), WithDialContext(
+		func(ctx context.Context, network, address string) (net.Conn, error) {
+			tag = ctx.Value(dialTagKey{})
+			_, hasDeadline = ctx.Deadline()
+			return (&net.Dialer{}).DialContext(ctx, network, opt.Addr)
+		},
+		func(ctx context.Context, conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
+			upgraded = true
+			return nil, upgradeErr
+		}))
+
+	ctx := context.WithValue(context.Background(), dialTagKey{}, "vpc-a")
+	status, body, err := c.Get(ctx, nil, "http://example.com/")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, status)
+	assert.DeepEqual(t, "ok", string(body))
+	assert.DeepEqual(t, "vpc-a", tag)
+	assert.True(t, hasDeadline)
+	assert.False(t, upgraded)
+
+	_, _, err = c.Get(ctx, nil, "https://example.com/")
+	assert.True(t, errors.Is(err, upgradeErr))
+	assert.True(t, upgraded)
+}
+
+func TestClientBodyPassthrough(t *testing.T) {
+	body := strings.Repeat("hertz", 200*1024)
+	upstreamOpt := config.NewOptions([]config.Option{})
+	upstreamOpt.Addr = "127.0.0.1:10042"
+	upstream := route.NewEngine(upstreamOpt)
+	upstream.POST("/echo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Write(ctx.Request.Body()) //nolint:errcheck
+	})
+	go upstream.Run()
+	defer func() {
+		upstream.Close()
+	}()
+
+	proxyOpt := config.NewOptions([]config.Option{})
+	proxyOpt.Addr = "127.0.0.1:10043"
+	proxyOpt.StreamRequestBody = true
+	proxy := route.NewEngine(proxyOpt)
+	pc, _ := NewClient(WithResponseBodyStream(true))
+	proxy.POST("/echo", func(c context.Context, ctx *app.RequestContext) {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		req.SetMethod(consts.MethodPost)
+		req.SetRequestURI("http://" + upstreamOpt.Addr + "/echo")
+		req.SetBodyStream(ctx.RequestBodyStream(), ctx.Request.Header.ContentLength())
+		if err := pc.Do(c, req, resp); err != nil {
+			ctx.AbortWithError(consts.StatusBadGateway, err) //nolint:errcheck
+			return
+		}
+		ctx.SetBodyStream(resp.BodyStream(), resp.Header.ContentLength())
+	})
+	go proxy.Run()
+	defer func() {
+		proxy.Close()
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	c, _ := NewClient()
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	req.SetMethod(consts.MethodPost)
+	req.SetRequestURI("http://" + proxyOpt.Addr + "/echo")
+	req.SetBodyString(body)
+	err := c.Do(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, body, string(resp.Body()))
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// CloneOption is the only struct that can be used to set the options of the client returned by Client.Clone.
+type CloneOption struct {
+	F func(c *Client)
+}
+
+type cloneHeader struct {
+	key   string
+	value string
+}
+
+// WithCloneRequestOptions sets the request options applied to the requests sent by the clone,
+// e.g. the timeouts, the options set on the requests take precedence over them.
+func WithCloneRequestOptions(opts ...config.RequestOption) CloneOption {
+	return CloneOption{F: func(c *Client) {
+		c.cloneOptions.Apply(opts)
+	}}
+}
+
+// WithCloneHeader sets the header added to the requests sent by the clone,
+// unless the header has been set on the request.
+func WithCloneHeader(key, value string) CloneOption {
+	return CloneOption{F: func(c *Client) {
+		for i := range c.cloneHeaders {
+			if strings.EqualFold(c.cloneHeaders[i].key, key) {
+				c.cloneHeaders[i].value = value
+				return
+			}
+		}
+		c.cloneHeaders = append(c.cloneHeaders, cloneHeader{key: key, value: value})
+	}}
+}
+
+// WithCloneMiddlewares replaces the middlewares inherited from the parent with mws.
+func WithCloneMiddlewares(mws ...Middleware) CloneOption {
+	return CloneOption{F: func(c *Client) {
+		c.mws = nil
+		c.Use(mws...)
+	}}
+}
+
+// Clone returns a child client sharing the connection pools with c,
+// which inherits the middlewares, proxy, retry and redirect policies of c,
+// and overrides them with opts, e.g.
+//
+//	tenantClient := c.Clone(
+//		client.WithCloneRequestOptions(config.WithReadTimeout(time.Second)),
+//		client.WithCloneHeader("X-Tenant", "foo"),
+//	)
+//
+// NOTE:
+//
+//	The connection pools are created with the options of the client which is not a clone,
+//	so only the options which can be set on the requests can be overridden.
+//	The middlewares added to the clone by Use do not affect c, and vice versa.
+//	CloseIdleConnections and Stats of the clone work on the shared pools.
+func (c *Client) Clone(opts ...CloneOption) *Client {
+	root := c
+	if c.parent != nil {
+		root = c.parent
+	}
+	clone := &Client{
+		options:        c.options,
+		Proxy:          c.Proxy,
+		RetryIfFunc:    c.RetryIfFunc,
+		RedirectPolicy: c.RedirectPolicy,
+		parent:         root,
+		mws:            c.mws,
+		cloneOptions:   config.NewRequestOptions(nil),
+		cloneHeaders:   append([]cloneHeader(nil), c.cloneHeaders...),
+	}
+	if c.cloneOptions != nil {
+		c.cloneOptions.CopyTo(clone.cloneOptions)
+	}
+	for _, opt := range opts {
+		opt.F(clone)
+	}
+	return clone
+}
+
+// doClone applies the options of the clone to req, and sends it with the pools of the parent.
+func (c *Client) doClone(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	req.Options().Inherit(c.cloneOptions)
+	for _, h := range c.cloneHeaders {
+		if len(req.Header.Peek(h.key)) == 0 {
+			req.Header.Set(h.key, h.value)
+		}
+	}
+	return c.parent.do(ctx, req, resp)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestClientClone(t *testing.T) {
+	dials := 0
+	c, err := NewClient(WithDialFunc(func(addr string) (network.Conn, error) {
+		dials++
+		return nil, errors.New("dial error")
+	}))
+	assert.Nil(t, err)
+	var parentCalls, cloneCalls int
+	c.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			parentCalls++
+			return next(ctx, req, resp)
+		}
+	})
+
+	clone := c.Clone(
+		WithCloneRequestOptions(config.WithReadTimeout(time.Second), config.WithWriteTimeout(time.Second)),
+		WithCloneHeader("X-Tenant", "foo"),
+	)
+	clone.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			cloneCalls++
+			return next(ctx, req, resp)
+		}
+	})
+
+	req := protocol.AcquireRequest()
+	req.SetRequestURI("http://example.com/foo")
+	req.SetOptions(config.WithReadTimeout(2 * time.Second))
+	req.Header.Set("X-Other", "bar")
+	err = clone.Do(context.Background(), req, nil)
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "foo", string(req.Header.Peek("X-Tenant")))
+	assert.DeepEqual(t, "bar", string(req.Header.Peek("X-Other")))
+	assert.DeepEqual(t, 2*time.Second, req.Options().ReadTimeout())
+	assert.DeepEqual(t, time.Second, req.Options().WriteTimeout())
+	assert.DeepEqual(t, 1, parentCalls)
+	assert.DeepEqual(t, 1, cloneCalls)
+
+	// the pools are shared
+	assert.DeepEqual(t, 1, dials)
+	assert.DeepEqual(t, 1, len(c.Stats()))
+	assert.DeepEqual(t, 1, len(clone.Stats()))
+
+	// the middlewares of the clone do not affect the parent
+	_, _, err = c.Get(context.Background(), nil, "http://example.com/foo")
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, 2, parentCalls)
+	assert.DeepEqual(t, 1, cloneCalls)
+
+	// the clone of the clone inherits its options
+	req.Reset()
+	req.SetRequestURI("http://example.com/foo")
+	grandchild := clone.Clone(WithCloneMiddlewares(), WithCloneHeader("X-Tenant", "bar"))
+	err = grandchild.Do(context.Background(), req, nil)
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "bar", string(req.Header.Peek("X-Tenant")))
+	assert.DeepEqual(t, time.Second, req.Options().ReadTimeout())
+	assert.DeepEqual(t, 2, parentCalls)
+	assert.DeepEqual(t, 1, len(c.Stats()))
+}
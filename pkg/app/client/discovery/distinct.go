@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"sync"
+)
+
+type distinctKey struct{}
+
+// DistinctInstances records the addresses of the instances picked for the requests
+// sent with the same context, so that they can be sent to different instances.
+type DistinctInstances struct {
+	mu    sync.Mutex
+	addrs map[string]bool
+}
+
+// WithDistinctInstances returns a new context, the requests sent with it are sent
+// to different instances as far as possible, e.g. the hedged requests.
+// ctx is returned as is if it has been returned by WithDistinctInstances.
+func WithDistinctInstances(ctx context.Context) context.Context {
+	if ContextDistinctInstances(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, distinctKey{}, &DistinctInstances{addrs: make(map[string]bool)})
+}
+
+// ContextDistinctInstances returns the DistinctInstances carried by ctx, or nil if there is none.
+func ContextDistinctInstances(ctx context.Context) *DistinctInstances {
+	if ctx == nil {
+		return nil
+	}
+	d, _ := ctx.Value(distinctKey{}).(*DistinctInstances)
+	return d
+}
+
+// Len returns the number of the picked addresses.
+func (d *DistinctInstances) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.addrs)
+}
+
+// Add records addr as picked, and reports whether it has not been picked before.
+func (d *DistinctInstances) Add(addr string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.addrs[addr] {
+		return false
+	}
+	d.addrs[addr] = true
+	return true
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/gopkg/lang/fastrand"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultTTL           = time.Minute
+	defaultLookupTimeout = 5 * time.Second
+)
+
+var errNoAddress = errors.NewPublic("no address found for host")
+
+// Resolver looks up the addresses of a host, which is implemented by *net.Resolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type cacheEntry struct {
+	addrs  []string
+	expire time.Time
+}
+
+// CachingResolver is a Resolver which caches the addresses of hosts for TTL,
+// so that the underlying resolver is not queried by every connection.
+//
+// The stale addresses are used if the lookup fails after they expire.
+type CachingResolver struct {
+	opts Options
+
+	lock  sync.RWMutex
+	cache map[string]*cacheEntry
+	sfg   singleflight.Group
+}
+
+// NewCachingResolver creates a CachingResolver, which caches the addresses for 1 minute
+// and times out the lookup after 5 seconds by default.
+func NewCachingResolver(opts ...Option) *CachingResolver {
+	options := Options{
+		Resolver:      net.DefaultResolver,
+		TTL:           defaultTTL,
+		LookupTimeout: defaultLookupTimeout,
+	}
+	options.Apply(opts)
+	if options.Resolver == nil {
+		options.Resolver = net.DefaultResolver
+	}
+	staticHosts := make(map[string][]string, len(options.StaticHosts))
+	for host, addrs := range options.StaticHosts {
+		staticHosts[strings.ToLower(host)] = addrs
+	}
+	options.StaticHosts = staticHosts
+	return &CachingResolver{
+		opts:  options,
+		cache: make(map[string]*cacheEntry),
+	}
+}
+
+// LookupHost implements the Resolver interface.
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	host = strings.ToLower(host)
+	if addrs, ok := r.opts.StaticHosts[host]; ok {
+		return addrs, nil
+	}
+
+	r.lock.RLock()
+	entry := r.cache[host]
+	r.lock.RUnlock()
+	if entry != nil && time.Now().Before(entry.expire) {
+		return entry.addrs, nil
+	}
+
+	v, err, _ := r.sfg.Do(host, func() (interface{}, error) {
+		return r.lookup(ctx, host)
+	})
+	if err != nil {
+		if entry != nil {
+			return entry.addrs, nil
+		}
+		return nil, err
+	}
+	return v.([]string), nil
+}
+
+func (r *CachingResolver) lookup(ctx context.Context, host string) ([]string, error) {
+	if r.opts.LookupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.LookupTimeout)
+		defer cancel()
+	}
+	addrs, err := r.opts.Resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errNoAddress
+	}
+	r.lock.Lock()
+	r.cache[host] = &cacheEntry{addrs: addrs, expire: time.Now().Add(r.opts.TTL)}
+	r.lock.Unlock()
+	return addrs, nil
+}
+
+// Flush removes the cached addresses of hosts, or all hosts if none is given.
+func (r *CachingResolver) Flush(hosts ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(hosts) == 0 {
+		r.cache = make(map[string]*cacheEntry)
+		return
+	}
+	for _, host := range hosts {
+		delete(r.cache, strings.ToLower(host))
+	}
+}
+
+// ResolveAddr replaces the host of addr in the form of "host:port" with one of its addresses
+// looked up by resolver, which is picked randomly to spread the connections.
+// addr is returned as is if the host is an IP address.
+func ResolveAddr(ctx context.Context, resolver Resolver, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", errNoAddress
+	}
+	ip := addrs[0]
+	if len(addrs) > 1 {
+		ip = addrs[fastrand.Intn(len(addrs))]
+	}
+	return net.JoinHostPort(ip, port), nil
+}
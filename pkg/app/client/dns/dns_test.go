@@ -0,0 +1,110 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+type mockResolver struct {
+	lookups int32
+	addrs   []string
+	err     error
+	delay   time.Duration
+}
+
+func (m *mockResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	atomic.AddInt32(&m.lookups, 1)
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return m.addrs, m.err
+}
+
+func TestCachingResolver(t *testing.T) {
+	mock := &mockResolver{addrs: []string{"10.0.0.1"}}
+	r := NewCachingResolver(
+		WithResolver(mock),
+		WithTTL(50*time.Millisecond),
+		WithStaticHosts(map[string][]string{"Static.Example": {"10.0.0.2"}}),
+	)
+
+	for i := 0; i < 3; i++ {
+		addrs, err := r.LookupHost(context.Background(), "example.com")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, []string{"10.0.0.1"}, addrs)
+	}
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&mock.lookups))
+
+	addrs, err := r.LookupHost(context.Background(), "static.example")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []string{"10.0.0.2"}, addrs)
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&mock.lookups))
+
+	// looked up again after TTL, and the stale addresses are used if the lookup fails
+	time.Sleep(60 * time.Millisecond)
+	mock.err = errors.New("lookup failed")
+	addrs, err = r.LookupHost(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []string{"10.0.0.1"}, addrs)
+	assert.DeepEqual(t, int32(2), atomic.LoadInt32(&mock.lookups))
+
+	r.Flush("example.com")
+	_, err = r.LookupHost(context.Background(), "example.com")
+	assert.DeepEqual(t, mock.err, err)
+
+	mock.err = nil
+	mock.addrs = nil
+	r.Flush()
+	_, err = r.LookupHost(context.Background(), "example.com")
+	assert.DeepEqual(t, errNoAddress, err)
+}
+
+func TestCachingResolverLookupTimeout(t *testing.T) {
+	mock := &mockResolver{addrs: []string{"10.0.0.1"}, delay: time.Second}
+	r := NewCachingResolver(WithResolver(mock), WithLookupTimeout(10*time.Millisecond))
+	_, err := r.LookupHost(context.Background(), "example.com")
+	assert.DeepEqual(t, context.DeadlineExceeded, err)
+}
+
+func TestResolveAddr(t *testing.T) {
+	mock := &mockResolver{addrs: []string{"10.0.0.1", "::1"}}
+
+	addr, err := ResolveAddr(context.Background(), mock, "127.0.0.1:80")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "127.0.0.1:80", addr)
+	assert.DeepEqual(t, int32(0), atomic.LoadInt32(&mock.lookups))
+
+	for i := 0; i < 10; i++ {
+		addr, err = ResolveAddr(context.Background(), mock, "example.com:443")
+		assert.Nil(t, err)
+		assert.True(t, addr == "10.0.0.1:443" || addr == "[::1]:443")
+	}
+
+	_, err = ResolveAddr(context.Background(), mock, "example.com")
+	assert.True(t, err != nil)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import "time"
+
+// Option is the only struct that can be used to set the Options of CachingResolver.
+type Option struct {
+	F func(o *Options)
+}
+
+// Options All configurations related to CachingResolver
+type Options struct {
+	// The resolver to look up the hosts which are not cached, net.DefaultResolver by default
+	Resolver Resolver
+
+	// The duration for which the addresses of a host are cached
+	TTL time.Duration
+
+	// The timeout of each lookup, 0 means no timeout
+	LookupTimeout time.Duration
+
+	// The addresses of hosts which are used without lookup, like /etc/hosts
+	StaticHosts map[string][]string
+}
+
+func (o *Options) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+// WithResolver set Resolver.
+func WithResolver(resolver Resolver) Option {
+	return Option{F: func(o *Options) {
+		o.Resolver = resolver
+	}}
+}
+
+// WithTTL set TTL.
+func WithTTL(ttl time.Duration) Option {
+	return Option{F: func(o *Options) {
+		o.TTL = ttl
+	}}
+}
+
+// WithLookupTimeout set LookupTimeout.
+func WithLookupTimeout(timeout time.Duration) Option {
+	return Option{F: func(o *Options) {
+		o.LookupTimeout = timeout
+	}}
+}
+
+// WithStaticHosts set StaticHosts.
+func WithStaticHosts(hosts map[string][]string) Option {
+	return Option{F: func(o *Options) {
+		o.StaticHosts = hosts
+	}}
+}
@@ -138,6 +138,14 @@ func (b *BalancerFactory) GetInstance(ctx context.Context, req *protocol.Request
 	return ins, nil
 }
 
+// Done notifies the balancer that the request sent to ins is finished,
+// if the balancer implements the DoneNotifier interface.
+func (b *BalancerFactory) Done(ins discovery.Instance) {
+	if dn, ok := b.balancer.(DoneNotifier); ok {
+		dn.Done(ins)
+	}
+}
+
 func (b *BalancerFactory) getCacheResult(ctx context.Context, req *protocol.Request) (*cacheResult, error) {
 	target := b.resolver.Target(ctx, &discovery.TargetInfo{Host: string(req.Host()), Tags: req.Options().Tags()})
 	cr, existed := b.cache.Load(target)
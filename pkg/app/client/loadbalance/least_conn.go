@@ -0,0 +1,121 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/bytedance/gopkg/lang/fastrand"
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"golang.org/x/sync/singleflight"
+)
+
+type leastConnBalancer struct {
+	cachedInfo sync.Map
+	active     sync.Map // address -> *int64
+	sfg        singleflight.Group
+}
+
+type leastConnInfo struct {
+	instances []discovery.Instance
+	active    []*int64
+}
+
+// NewLeastConnBalancer creates a loadbalancer which picks the instance
+// with the fewest in-flight requests, the ties are broken randomly.
+//
+// NOTE:
+//
+//	The in-flight requests are counted by Done, which is called by
+//	BalancerFactory.Done once the request sent to the picked instance is finished.
+func NewLeastConnBalancer() Loadbalancer {
+	lb := &leastConnBalancer{}
+	return lb
+}
+
+func (lb *leastConnBalancer) counter(ins discovery.Instance) *int64 {
+	addr := ins.Address().String()
+	if c, ok := lb.active.Load(addr); ok {
+		return c.(*int64)
+	}
+	c, _ := lb.active.LoadOrStore(addr, new(int64))
+	return c.(*int64)
+}
+
+func (lb *leastConnBalancer) calcInfo(e discovery.Result) *leastConnInfo {
+	l := &leastConnInfo{
+		instances: make([]discovery.Instance, len(e.Instances)),
+		active:    make([]*int64, len(e.Instances)),
+	}
+	for idx := range e.Instances {
+		l.instances[idx] = e.Instances[idx]
+		l.active[idx] = lb.counter(e.Instances[idx])
+	}
+	return l
+}
+
+// Pick implements the Loadbalancer interface.
+func (lb *leastConnBalancer) Pick(e discovery.Result) discovery.Instance {
+	li, ok := lb.cachedInfo.Load(e.CacheKey)
+	if !ok {
+		li, _, _ = lb.sfg.Do(e.CacheKey, func() (interface{}, error) {
+			return lb.calcInfo(e), nil
+		})
+		lb.cachedInfo.Store(e.CacheKey, li)
+	}
+
+	l := li.(*leastConnInfo)
+	n := len(l.instances)
+	if n == 0 {
+		return nil
+	}
+
+	offset := fastrand.Intn(n)
+	picked := offset
+	least := atomic.LoadInt64(l.active[offset])
+	for i := 1; i < n && least > 0; i++ {
+		idx := (offset + i) % n
+		if active := atomic.LoadInt64(l.active[idx]); active < least {
+			picked, least = idx, active
+		}
+	}
+	atomic.AddInt64(l.active[picked], 1)
+
+	return l.instances[picked]
+}
+
+// Done implements the DoneNotifier interface.
+func (lb *leastConnBalancer) Done(ins discovery.Instance) {
+	if c, ok := lb.active.Load(ins.Address().String()); ok {
+		atomic.AddInt64(c.(*int64), -1)
+	}
+}
+
+// Rebalance implements the Loadbalancer interface.
+func (lb *leastConnBalancer) Rebalance(e discovery.Result) {
+	lb.cachedInfo.Store(e.CacheKey, lb.calcInfo(e))
+}
+
+// Delete implements the Loadbalancer interface.
+func (lb *leastConnBalancer) Delete(cacheKey string) {
+	lb.cachedInfo.Delete(cacheKey)
+}
+
+func (lb *leastConnBalancer) Name() string {
+	return "least_conn"
+}
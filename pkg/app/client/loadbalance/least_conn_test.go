@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestLeastConnBalancer(t *testing.T) {
+	balancer := NewLeastConnBalancer()
+	// nil
+	ins := balancer.Pick(discovery.Result{})
+	assert.DeepEqual(t, ins, nil)
+
+	insList := []discovery.Instance{
+		discovery.NewInstance("tcp", "127.0.0.1:8881", 10, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8882", 10, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8883", 10, nil),
+	}
+	e := discovery.Result{
+		Instances: insList,
+		CacheKey:  "a",
+	}
+	balancer.Rebalance(e)
+
+	// every instance gets one request before any of them gets the second one
+	picked := map[discovery.Instance]int{}
+	for i := 0; i < len(insList); i++ {
+		picked[balancer.Pick(e)]++
+	}
+	assert.DeepEqual(t, len(insList), len(picked))
+
+	// the instance whose request is finished is picked next
+	balancer.(DoneNotifier).Done(insList[1])
+	for i := 0; i < 10; i++ {
+		ins = balancer.Pick(e)
+		assert.DeepEqual(t, insList[1], ins)
+		balancer.(DoneNotifier).Done(ins)
+	}
+
+	// the in-flight requests are kept across rebalancing
+	assert.DeepEqual(t, insList[1], balancer.Pick(e))
+	e.Instances = append(insList, discovery.NewInstance("tcp", "127.0.0.1:8884", 10, nil))
+	balancer.Rebalance(e)
+	ins = balancer.Pick(e)
+	assert.DeepEqual(t, "127.0.0.1:8884", ins.Address().String())
+}
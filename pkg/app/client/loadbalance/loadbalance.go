@@ -37,6 +37,13 @@ type Loadbalancer interface {
 	Name() string
 }
 
+// DoneNotifier is implemented by the Loadbalancer which keeps track of the requests
+// in flight, e.g. the least-conn one. Done is called with the picked instance
+// once the request sent to it is finished.
+type DoneNotifier interface {
+	Done(discovery.Instance)
+}
+
 const (
 	DefaultRefreshInterval = 5 * time.Second
 	DefaultExpireInterval  = 15 * time.Second
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/bytedance/gopkg/lang/fastrand"
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"golang.org/x/sync/singleflight"
+)
+
+type roundRobinBalancer struct {
+	cachedInfo sync.Map
+	sfg        singleflight.Group
+}
+
+type roundRobinInfo struct {
+	instances []discovery.Instance
+	next      uint64
+}
+
+// NewRoundRobinBalancer creates a loadbalancer using round-robin algorithm.
+func NewRoundRobinBalancer() Loadbalancer {
+	lb := &roundRobinBalancer{}
+	return lb
+}
+
+func (rb *roundRobinBalancer) calcInfo(e discovery.Result) *roundRobinInfo {
+	r := &roundRobinInfo{
+		instances: make([]discovery.Instance, len(e.Instances)),
+	}
+	copy(r.instances, e.Instances)
+	if len(r.instances) > 0 {
+		// start from a random instance so that clients do not hit the same one at first
+		r.next = uint64(fastrand.Intn(len(r.instances)))
+	}
+	return r
+}
+
+// Pick implements the Loadbalancer interface.
+func (rb *roundRobinBalancer) Pick(e discovery.Result) discovery.Instance {
+	ri, ok := rb.cachedInfo.Load(e.CacheKey)
+	if !ok {
+		ri, _, _ = rb.sfg.Do(e.CacheKey, func() (interface{}, error) {
+			return rb.calcInfo(e), nil
+		})
+		rb.cachedInfo.Store(e.CacheKey, ri)
+	}
+
+	r := ri.(*roundRobinInfo)
+	if len(r.instances) == 0 {
+		return nil
+	}
+
+	idx := (atomic.AddUint64(&r.next, 1) - 1) % uint64(len(r.instances))
+	return r.instances[idx]
+}
+
+// Rebalance implements the Loadbalancer interface.
+func (rb *roundRobinBalancer) Rebalance(e discovery.Result) {
+	rb.cachedInfo.Store(e.CacheKey, rb.calcInfo(e))
+}
+
+// Delete implements the Loadbalancer interface.
+func (rb *roundRobinBalancer) Delete(cacheKey string) {
+	rb.cachedInfo.Delete(cacheKey)
+}
+
+func (rb *roundRobinBalancer) Name() string {
+	return "round_robin"
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestRoundRobinBalancer(t *testing.T) {
+	balancer := NewRoundRobinBalancer()
+	// nil
+	ins := balancer.Pick(discovery.Result{})
+	assert.DeepEqual(t, ins, nil)
+
+	// empty instance
+	e := discovery.Result{
+		Instances: make([]discovery.Instance, 0),
+		CacheKey:  "a",
+	}
+	balancer.Rebalance(e)
+	ins = balancer.Pick(e)
+	assert.DeepEqual(t, ins, nil)
+
+	// multi instances
+	insList := []discovery.Instance{
+		discovery.NewInstance("tcp", "127.0.0.1:8881", 10, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8882", 20, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8883", 0, nil),
+	}
+	e = discovery.Result{
+		Instances: insList,
+		CacheKey:  "b",
+	}
+	balancer.Rebalance(e)
+	first := balancer.Pick(e)
+	idx := 0
+	for i, ins := range insList {
+		if ins == first {
+			idx = i
+		}
+	}
+	for i := 1; i < 30; i++ {
+		ins = balancer.Pick(e)
+		assert.DeepEqual(t, insList[(idx+i)%len(insList)], ins)
+	}
+
+	// instances are updated
+	e.Instances = insList[:1]
+	balancer.Rebalance(e)
+	for i := 0; i < 10; i++ {
+		assert.DeepEqual(t, insList[0], balancer.Pick(e))
+	}
+
+	balancer.Delete("b")
+	assert.DeepEqual(t, insList[0], balancer.Pick(e))
+}
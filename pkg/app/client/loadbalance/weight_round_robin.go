@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"golang.org/x/sync/singleflight"
+)
+
+type weightedRoundRobinBalancer struct {
+	cachedInfo sync.Map
+	sfg        singleflight.Group
+}
+
+type weightedRoundRobinInfo struct {
+	sync.Mutex
+	instances []discovery.Instance
+	weights   []int
+	current   []int
+	weightSum int
+}
+
+// NewWeightedRoundRobinBalancer creates a loadbalancer using smooth weighted round-robin algorithm,
+// which spreads the picks of an instance evenly instead of picking it in a row.
+func NewWeightedRoundRobinBalancer() Loadbalancer {
+	lb := &weightedRoundRobinBalancer{}
+	return lb
+}
+
+func (wb *weightedRoundRobinBalancer) calcInfo(e discovery.Result) *weightedRoundRobinInfo {
+	w := &weightedRoundRobinInfo{
+		instances: make([]discovery.Instance, 0, len(e.Instances)),
+		weights:   make([]int, 0, len(e.Instances)),
+	}
+
+	for idx := range e.Instances {
+		weight := e.Instances[idx].Weight()
+		if weight > 0 {
+			w.instances = append(w.instances, e.Instances[idx])
+			w.weights = append(w.weights, weight)
+			w.weightSum += weight
+		} else {
+			hlog.SystemLogger().Warnf("Invalid weight=%d on instance address=%s", weight, e.Instances[idx].Address())
+		}
+	}
+	w.current = make([]int, len(w.instances))
+
+	return w
+}
+
+// Pick implements the Loadbalancer interface.
+func (wb *weightedRoundRobinBalancer) Pick(e discovery.Result) discovery.Instance {
+	wi, ok := wb.cachedInfo.Load(e.CacheKey)
+	if !ok {
+		wi, _, _ = wb.sfg.Do(e.CacheKey, func() (interface{}, error) {
+			return wb.calcInfo(e), nil
+		})
+		wb.cachedInfo.Store(e.CacheKey, wi)
+	}
+
+	w := wi.(*weightedRoundRobinInfo)
+	if w.weightSum <= 0 {
+		return nil
+	}
+
+	w.Lock()
+	picked := 0
+	for i := range w.instances {
+		w.current[i] += w.weights[i]
+		if w.current[i] > w.current[picked] {
+			picked = i
+		}
+	}
+	w.current[picked] -= w.weightSum
+	w.Unlock()
+
+	return w.instances[picked]
+}
+
+// Rebalance implements the Loadbalancer interface.
+func (wb *weightedRoundRobinBalancer) Rebalance(e discovery.Result) {
+	wb.cachedInfo.Store(e.CacheKey, wb.calcInfo(e))
+}
+
+// Delete implements the Loadbalancer interface.
+func (wb *weightedRoundRobinBalancer) Delete(cacheKey string) {
+	wb.cachedInfo.Delete(cacheKey)
+}
+
+func (wb *weightedRoundRobinBalancer) Name() string {
+	return "weight_round_robin"
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestWeightedRoundRobinBalancer(t *testing.T) {
+	balancer := NewWeightedRoundRobinBalancer()
+	// nil
+	ins := balancer.Pick(discovery.Result{})
+	assert.DeepEqual(t, ins, nil)
+
+	// empty instance
+	e := discovery.Result{
+		Instances: make([]discovery.Instance, 0),
+		CacheKey:  "a",
+	}
+	balancer.Rebalance(e)
+	ins = balancer.Pick(e)
+	assert.DeepEqual(t, ins, nil)
+
+	// smooth weighted round-robin, {a:5, b:1, c:1} gives a a b a c a a
+	insList := []discovery.Instance{
+		discovery.NewInstance("tcp", "127.0.0.1:8881", 5, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8882", 1, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8883", 1, nil),
+	}
+	e = discovery.Result{
+		Instances: insList,
+		CacheKey:  "b",
+	}
+	balancer.Rebalance(e)
+	expected := []int{0, 0, 1, 0, 2, 0, 0}
+	for round := 0; round < 3; round++ {
+		for _, idx := range expected {
+			ins = balancer.Pick(e)
+			assert.DeepEqual(t, insList[idx], ins)
+		}
+	}
+}
@@ -18,8 +18,12 @@ package client
 
 import (
 	"crypto/tls"
+	"strings"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/app/client/circuitbreak"
+	"github.com/cloudwego/hertz/pkg/app/client/dns"
+	"github.com/cloudwego/hertz/pkg/app/client/ratelimit"
 	"github.com/cloudwego/hertz/pkg/app/client/retry"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/network"
@@ -35,6 +39,20 @@ func WithDialTimeout(dialTimeout time.Duration) config.ClientOption {
 	}}
 }
 
+// WithTLSHandshakeTimeout sets the timeout of the TLS handshake of new connections.
+func WithTLSHandshakeTimeout(t time.Duration) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.TLSHandshakeTimeout = t
+	}}
+}
+
+// WithResponseHeaderTimeout sets the timeout of waiting for the response after the request is written.
+func WithResponseHeaderTimeout(t time.Duration) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.ResponseHeaderTimeout = t
+	}}
+}
+
 // WithMaxConnsPerHost sets maximum number of connections per host which may be established.
 func WithMaxConnsPerHost(mc int) config.ClientOption {
 	return config.ClientOption{F: func(o *config.ClientOptions) {
@@ -85,6 +103,88 @@ func WithTLSConfig(cfg *tls.Config) config.ClientOption {
 	}}
 }
 
+// WithHostTLSConfig sets the tlsConfig used for the hosts matching pattern instead of the one
+// set by WithTLSConfig, so that the upstreams with different trust requirements can be
+// requested by one client.
+//
+// The pattern is either a host name like "api.example.com", or a wildcard like "*.example.com"
+// which matches all subdomains of example.com. The host name wins over the wildcards,
+// and the longer wildcard wins over the shorter one.
+func WithHostTLSConfig(pattern string, cfg *tls.Config) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		if o.HostTLSConfigs == nil {
+			o.HostTLSConfigs = make(map[string]*tls.Config)
+		}
+		o.HostTLSConfigs[strings.ToLower(pattern)] = cfg
+		o.Dialer = standard.NewDialer()
+	}}
+}
+
+// WithUnixSocket makes the client dial the unix domain socket of path for the hosts,
+// or for all hosts if hosts is empty, e.g.
+//
+//	c, _ := client.NewClient(client.WithUnixSocket("/var/run/docker.sock", "docker"))
+//	c.Get(ctx, nil, "http://docker/v1.41/info")
+//
+// The host of the request URI is still sent in the Host header.
+func WithUnixSocket(path string, hosts ...string) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		if o.UnixSockets == nil {
+			o.UnixSockets = make(map[string]string)
+		}
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+		for _, host := range hosts {
+			o.UnixSockets[strings.ToLower(host)] = path
+		}
+	}}
+}
+
+// WithAutoDecompress makes the client send Accept-Encoding header if the request does not
+// contain one, and decode the gzip, br and zstd response bodies transparently.
+// The Content-Encoding header is removed from the decoded response, and the original
+// value is available via Response.OriginalContentEncoding.
+func WithAutoDecompress(enable bool) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.AutoDecompress = enable
+	}}
+}
+
+// WithMaxDecompressedBodySize sets the maximum size of the decoded response body,
+// ErrBodyTooLarge is returned if the decoded body is larger than it.
+func WithMaxDecompressedBodySize(n int) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.MaxDecompressedBodySize = n
+	}}
+}
+
+// WithAddrOverride makes the client dial addrs in a round-robin manner instead of host,
+// so that the traffic can be redirected without changing the request URIs or DNS, e.g.
+//
+//	c, _ := client.NewClient(client.WithAddrOverride("api.example.com", "10.0.0.1:8080", "10.0.0.2"))
+//
+// The host is either a host name matching all ports, or an address with port like "api.example.com:443".
+// The port of the request URI is used for the addrs without port.
+// The host of the request URI is still sent in the Host header and used as the TLS server name.
+//
+// NOTE:
+//
+//	If addrs is empty, the override of host is removed.
+func WithAddrOverride(host string, addrs ...string) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		host = strings.ToLower(host)
+		if len(addrs) == 0 {
+			delete(o.AddrOverrides, host)
+			return
+		}
+		if o.AddrOverrides == nil {
+			o.AddrOverrides = make(map[string][]string)
+		}
+		o.AddrOverrides[host] = append([]string(nil), addrs...)
+	}}
+}
+
 // WithDialer sets the specific dialer.
 func WithDialer(d network.Dialer) config.ClientOption {
 	return config.ClientOption{F: func(o *config.ClientOptions) {
@@ -92,6 +192,19 @@ func WithDialer(d network.Dialer) config.ClientOption {
 	}}
 }
 
+// WithDialContext sets a dialer built from dial and upgrade, which receives the context
+// of the request, e.g. for happy-eyeballs, VPC-aware routing or connection tagging.
+//
+// NOTE:
+//
+//	If dial==nil, net.Dialer is used. If upgrade==nil, crypto/tls is used for tls connections.
+//	WithTLSConfig replaces the dialer, so WithDialContext should be applied after it.
+func WithDialContext(dial network.DialContextFunc, upgrade network.TLSUpgradeFunc) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.Dialer = standard.NewContextDialer(dial, upgrade)
+	}}
+}
+
 // WithResponseBodyStream is used to determine whether read body in stream or not.
 func WithResponseBodyStream(b bool) config.ClientOption {
 	return config.ClientOption{F: func(o *config.ClientOptions) {
@@ -142,6 +255,51 @@ func WithRetryConfig(opts ...retry.Option) config.ClientOption {
 	}}
 }
 
+// WithCircuitBreaker enables the circuit breaker of each host, which is opened by 5 consecutive failures
+// and probes the host after 5 seconds by default.
+// The requests are failed with ErrCircuitOpen without being sent while the breaker is open.
+func WithCircuitBreaker(opts ...circuitbreak.Option) config.ClientOption {
+	breakerCfg := &circuitbreak.Config{
+		ConsecutiveFailures: 5,
+		Window:              10 * time.Second,
+		OpenTimeout:         5 * time.Second,
+		HalfOpenRequests:    1,
+	}
+	breakerCfg.Apply(opts)
+
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.CircuitBreakerConfig = breakerCfg
+	}}
+}
+
+// WithRateLimit limits the rate of requests to each host with a token bucket, e.g.
+//
+//	client.WithRateLimit(ratelimit.WithRate(100, 10), ratelimit.WithWait(true))
+//
+// The requests beyond the limit are failed with ErrRateLimited without being sent by default.
+// Use config.WithRateLimitKey to limit the requests by other keys than hosts.
+func WithRateLimit(opts ...ratelimit.Option) config.ClientOption {
+	limitCfg := &ratelimit.Config{
+		Burst: 1,
+	}
+	limitCfg.Apply(opts)
+
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.RateLimitConfig = limitCfg
+	}}
+}
+
+// WithDNSResolver sets the resolver which looks up the addresses of hosts before dialing, e.g.
+//
+//	client.WithDNSResolver(dns.NewCachingResolver(dns.WithTTL(30 * time.Second)))
+//
+// The hosts are resolved by the dialer by default.
+func WithDNSResolver(resolver dns.Resolver) config.ClientOption {
+	return config.ClientOption{F: func(o *config.ClientOptions) {
+		o.DNSResolver = resolver
+	}}
+}
+
 // WithWriteTimeout sets write timeout.
 func WithWriteTimeout(t time.Duration) config.ClientOption {
 	return config.ClientOption{F: func(o *config.ClientOptions) {
@@ -18,9 +18,12 @@ package client
 
 import (
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/app/client/circuitbreak"
+	"github.com/cloudwego/hertz/pkg/app/client/ratelimit"
 	"github.com/cloudwego/hertz/pkg/app/client/retry"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
@@ -43,7 +46,21 @@ func TestClientOptions(t *testing.T) {
 			retry.WithMaxJitter(1*time.Second),
 			retry.WithDelayPolicy(retry.CombineDelay(retry.FixedDelayPolicy, retry.BackOffDelayPolicy, retry.RandomDelayPolicy)),
 		),
+		WithCircuitBreaker(
+			circuitbreak.WithConsecutiveFailures(3),
+			circuitbreak.WithOpenTimeout(time.Second),
+		),
+		WithDNSResolver(net.DefaultResolver),
+		WithRateLimit(ratelimit.WithRate(10, 5), ratelimit.WithWait(true)),
 		WithWriteTimeout(time.Second),
+		WithTLSHandshakeTimeout(2 * time.Second),
+		WithResponseHeaderTimeout(3 * time.Second),
+		WithUnixSocket("/tmp/a.sock", "A.com", "b.com"),
+		WithAutoDecompress(true),
+		WithAddrOverride("Foo.com", "127.0.0.1:80"),
+		WithAddrOverride("bar.com", "127.0.0.2:80"),
+		WithAddrOverride("bar.com"),
+		WithMaxDecompressedBodySize(1024),
 		WithConnStateObserve(nil, time.Second),
 	})
 	assert.DeepEqual(t, 100*time.Millisecond, opt.DialTimeout)
@@ -54,11 +71,23 @@ func TestClientOptions(t *testing.T) {
 	assert.DeepEqual(t, false, opt.KeepAlive)
 	assert.DeepEqual(t, 1*time.Second, opt.ReadTimeout)
 	assert.DeepEqual(t, 1*time.Second, opt.WriteTimeout)
+	assert.DeepEqual(t, 2*time.Second, opt.TLSHandshakeTimeout)
+	assert.DeepEqual(t, 3*time.Second, opt.ResponseHeaderTimeout)
+	assert.DeepEqual(t, map[string]string{"a.com": "/tmp/a.sock", "b.com": "/tmp/a.sock"}, opt.UnixSockets)
 	assert.DeepEqual(t, true, opt.ResponseBodyStream)
+	assert.DeepEqual(t, true, opt.AutoDecompress)
+	assert.DeepEqual(t, map[string][]string{"foo.com": {"127.0.0.1:80"}}, opt.AddrOverrides)
+	assert.DeepEqual(t, 1024, opt.MaxDecompressedBodySize)
 	assert.DeepEqual(t, uint(2), opt.RetryConfig.MaxAttemptTimes)
 	assert.DeepEqual(t, 100*time.Millisecond, opt.RetryConfig.Delay)
 	assert.DeepEqual(t, 5*time.Second, opt.RetryConfig.MaxDelay)
 	assert.DeepEqual(t, 1*time.Second, opt.RetryConfig.MaxJitter)
 	assert.DeepEqual(t, 1*time.Second, opt.ObservationInterval)
+	assert.DeepEqual(t, uint32(3), opt.CircuitBreakerConfig.ConsecutiveFailures)
+	assert.DeepEqual(t, time.Second, opt.CircuitBreakerConfig.OpenTimeout)
+	assert.DeepEqual(t, 10*time.Second, opt.CircuitBreakerConfig.Window)
+	assert.DeepEqual(t, uint32(1), opt.CircuitBreakerConfig.HalfOpenRequests)
+	assert.DeepEqual(t, net.DefaultResolver, opt.DNSResolver)
+	assert.DeepEqual(t, &ratelimit.Config{Rate: 10, Burst: 5, Wait: true}, opt.RateLimitConfig)
 	assert.DeepEqual(t, fmt.Sprint(retry.CombineDelay(retry.FixedDelayPolicy, retry.BackOffDelayPolicy, retry.RandomDelayPolicy)), fmt.Sprint(opt.RetryConfig.DelayPolicy))
 }
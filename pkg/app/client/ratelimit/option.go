@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+// Option is the only struct that can be used to set rate limit Config.
+type Option struct {
+	F func(o *Config)
+}
+
+// WithRate set Rate and Burst.
+func WithRate(rate float64, burst int) Option {
+	return Option{F: func(o *Config) {
+		o.Rate = rate
+		o.Burst = burst
+	}}
+}
+
+// WithWait set Wait.
+func WithWait(wait bool) Option {
+	return Option{F: func(o *Config) {
+		o.Wait = wait
+	}}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+// Config All configurations related to rate limiting
+type Config struct {
+	// The number of requests allowed per second for each key
+	Rate float64
+
+	// The maximum number of requests allowed at once for each key, which is at least 1
+	Burst int
+
+	// Wait makes the requests beyond the limit wait for their turns until the context is done,
+	// instead of failing with ErrRateLimited immediately
+	Wait bool
+}
+
+func (c *Config) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(c)
+	}
+}
+
+// Limiter limits the rate of requests with a token bucket for each key.
+//
+// NOTE:
+//
+//	The buckets are never removed, so the number of keys should be bounded.
+type Limiter struct {
+	cfg     *Config
+	buckets sync.Map
+}
+
+// NewLimiter creates the Limiter with cfg.
+func NewLimiter(cfg *Config) *Limiter {
+	return &Limiter{cfg: cfg}
+}
+
+type bucket struct {
+	sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (l *Limiter) bucket(key string) *bucket {
+	if b, ok := l.buckets.Load(key); ok {
+		return b.(*bucket)
+	}
+	b, _ := l.buckets.LoadOrStore(key, &bucket{tokens: float64(l.burst()), last: time.Now()})
+	return b.(*bucket)
+}
+
+func (l *Limiter) burst() int {
+	if l.cfg.Burst < 1 {
+		return 1
+	}
+	return l.cfg.Burst
+}
+
+// reserve takes a token from the bucket of key, and returns the duration to wait
+// before the token is available. The token is not taken if the wait is longer than maxWait.
+func (l *Limiter) reserve(key string, maxWait time.Duration) (time.Duration, bool) {
+	b := l.bucket(key)
+	b.Lock()
+	defer b.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * l.cfg.Rate
+	if burst := float64(l.burst()); b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if l.cfg.Rate <= 0 {
+		return 0, false
+	}
+	wait := time.Duration((1 - b.tokens) / l.cfg.Rate * float64(time.Second))
+	if wait > maxWait {
+		return 0, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel gives back the token taken by reserve.
+func (l *Limiter) cancel(key string) {
+	b := l.bucket(key)
+	b.Lock()
+	b.tokens++
+	b.Unlock()
+}
+
+// Allow reports whether a request of key is allowed now.
+func (l *Limiter) Allow(key string) bool {
+	_, ok := l.reserve(key, 0)
+	return ok
+}
+
+// Wait blocks until a request of key is allowed or ctx is done.
+// ErrRateLimited is returned if ctx would be done before the request is allowed.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	maxWait := time.Duration(1<<63 - 1)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = time.Until(deadline)
+	}
+	wait, ok := l.reserve(key, maxWait)
+	if !ok {
+		return errs.ErrRateLimited
+	}
+	if wait == 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(key)
+		return ctx.Err()
+	}
+}
+
+// Take lets a request of key pass according to the config, which waits for its turn
+// or fails with ErrRateLimited.
+func (l *Limiter) Take(ctx context.Context, key string) error {
+	if l.cfg.Wait {
+		return l.Wait(ctx, key)
+	}
+	if !l.Allow(key) {
+		return errs.ErrRateLimited
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	cfg := &Config{}
+	cfg.Apply([]Option{WithRate(20, 2)})
+	l := NewLimiter(cfg)
+
+	assert.True(t, l.Allow("a"))
+	assert.True(t, l.Allow("a"))
+	assert.False(t, l.Allow("a"))
+	// the buckets of keys are independent
+	assert.True(t, l.Allow("b"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, l.Allow("a"))
+	assert.False(t, l.Allow("a"))
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := NewLimiter(&Config{Rate: 20, Burst: 1, Wait: true})
+	assert.Nil(t, l.Take(context.Background(), "a"))
+
+	start := time.Now()
+	assert.Nil(t, l.Take(context.Background(), "a"))
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	// the deadline is earlier than the turn of the request
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.DeepEqual(t, errs.ErrRateLimited, l.Take(ctx, "a"))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	assert.DeepEqual(t, context.Canceled, l.Wait(ctx, "a"))
+
+	l = NewLimiter(&Config{Rate: 20, Burst: 1})
+	assert.Nil(t, l.Take(context.Background(), "a"))
+	assert.DeepEqual(t, errs.ErrRateLimited, l.Take(context.Background(), "a"))
+}
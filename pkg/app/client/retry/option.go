@@ -57,3 +57,31 @@ func WithMaxJitter(maxJitter time.Duration) Option {
 		o.MaxJitter = maxJitter
 	}}
 }
+
+// WithRetryStatusCodes set RetryStatusCodes.
+func WithRetryStatusCodes(statusCodes ...int) Option {
+	return Option{F: func(o *Config) {
+		o.RetryStatusCodes = statusCodes
+	}}
+}
+
+// WithRetryErrors set RetryErrors.
+func WithRetryErrors(errs ...error) Option {
+	return Option{F: func(o *Config) {
+		o.RetryErrors = errs
+	}}
+}
+
+// WithAttemptTimeout set AttemptTimeout.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return Option{F: func(o *Config) {
+		o.AttemptTimeout = timeout
+	}}
+}
+
+// WithRespectRetryAfter set RespectRetryAfter.
+func WithRespectRetryAfter(respect bool) Option {
+	return Option{F: func(o *Config) {
+		o.RespectRetryAfter = respect
+	}}
+}
@@ -17,10 +17,13 @@
 package retry
 
 import (
+	"errors"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/bytedance/gopkg/lang/fastrand"
+	"github.com/cloudwego/hertz/internal/bytesconv"
 )
 
 // Config All configurations related to retry
@@ -40,6 +43,21 @@ type Config struct {
 
 	// Delay strategy, which can combine multiple delay strategies. such as CombineDelay(BackOffDelayPolicy, RandomDelayPolicy) or BackOffDelayPolicy,etc
 	DelayPolicy DelayPolicyFunc
+
+	// The status codes of responses which are retried like errors, e.g. 502, 503 and 504.
+	// The responses are not retried by default
+	RetryStatusCodes []int
+
+	// The errors which are retried, an error is retried if it matches any of them by errors.Is.
+	// All errors are retried if it is empty
+	RetryErrors []error
+
+	// The timeout of each attempt, which bounds the dial, write and read timeouts of the attempt
+	AttemptTimeout time.Duration
+
+	// Whether to wait as long as the Retry-After header of the retried response asks for,
+	// if it is longer than the delay. The wait is still limited by MaxDelay
+	RespectRetryAfter bool
 }
 
 func (o *Config) Apply(opts []Option) {
@@ -48,6 +66,29 @@ func (o *Config) Apply(opts []Option) {
 	}
 }
 
+// IsRetryStatus returns whether the response of statusCode should be retried
+func (o *Config) IsRetryStatus(statusCode int) bool {
+	for _, code := range o.RetryStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryError returns whether err should be retried
+func (o *Config) IsRetryError(err error) bool {
+	if len(o.RetryErrors) == 0 {
+		return true
+	}
+	for _, target := range o.RetryErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // DelayPolicyFunc signature of delay policy function
 // is called to return the delay of retry
 type DelayPolicyFunc func(attempts uint, err error, retryConfig *Config) time.Duration
@@ -84,6 +125,16 @@ func BackOffDelayPolicy(attempts uint, _ error, retryConfig *Config) time.Durati
 	return retryConfig.Delay << attempts
 }
 
+// BackOffJitterDelayPolicy is a DelayPolicyFunc which picks a random delay up to the exponentially increased delay, known as full jitter,
+// if the retryConfig.Delay less than or equal to 0, the final delay is 0
+func BackOffJitterDelayPolicy(attempts uint, err error, retryConfig *Config) time.Duration {
+	backOff := BackOffDelayPolicy(attempts, err, retryConfig)
+	if backOff <= 0 {
+		return 0 * time.Millisecond
+	}
+	return time.Duration(fastrand.Int63n(int64(backOff)))
+}
+
 // CombineDelay return DelayPolicyFunc, which combines the optional DelayPolicyFunc into a new DelayPolicyFunc
 func CombineDelay(delays ...DelayPolicyFunc) DelayPolicyFunc {
 	const maxInt64 = uint64(math.MaxInt64)
@@ -113,3 +164,22 @@ func Delay(attempts uint, err error, retryConfig *Config) time.Duration {
 	}
 	return delayTime
 }
+
+// RetryAfter parses the value of Retry-After header, which is either delay-seconds or HTTP-date,
+// and returns the delay relative to now
+func RetryAfter(value []byte, now time.Time) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(string(value), 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := bytesconv.ParseHTTPDate(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
@@ -17,6 +17,8 @@
 package retry
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"testing"
 	"time"
@@ -85,3 +87,54 @@ func TestPolicy(t *testing.T) {
 	dur = delayFunc(0, nil, &config)
 	assert.DeepEqual(t, time.Duration(math.MaxInt64), dur)
 }
+
+func TestRetryConditions(t *testing.T) {
+	errFoo := errors.New("foo")
+	config := Config{}
+	config.Apply([]Option{
+		WithRetryStatusCodes(502, 503),
+		WithAttemptTimeout(time.Second),
+		WithRespectRetryAfter(true),
+	})
+	assert.True(t, config.IsRetryStatus(503))
+	assert.False(t, config.IsRetryStatus(500))
+	assert.True(t, config.IsRetryError(errFoo))
+	assert.DeepEqual(t, time.Second, config.AttemptTimeout)
+	assert.True(t, config.RespectRetryAfter)
+
+	config.Apply([]Option{WithRetryErrors(errFoo)})
+	assert.True(t, config.IsRetryError(fmt.Errorf("wrapped: %w", errFoo)))
+	assert.False(t, config.IsRetryError(errors.New("bar")))
+}
+
+func TestBackOffJitterDelayPolicy(t *testing.T) {
+	config := Config{}
+	assert.DeepEqual(t, 0*time.Millisecond, BackOffJitterDelayPolicy(1, nil, &config))
+
+	config.Delay = time.Millisecond
+	for i := 0; i < 10; i++ {
+		dur := BackOffJitterDelayPolicy(3, nil, &config)
+		assert.True(t, dur >= 0 && dur < 8*time.Millisecond)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dur, ok := RetryAfter([]byte("120"), now)
+	assert.True(t, ok)
+	assert.DeepEqual(t, 2*time.Minute, dur)
+
+	dur, ok = RetryAfter([]byte("Sat, 01 Jan 2022 00:00:30 GMT"), now)
+	assert.True(t, ok)
+	assert.DeepEqual(t, 30*time.Second, dur)
+
+	dur, ok = RetryAfter([]byte("Fri, 31 Dec 2021 00:00:00 GMT"), now)
+	assert.True(t, ok)
+	assert.DeepEqual(t, time.Duration(0), dur)
+
+	for _, v := range []string{"", "-1", "1.5", "tomorrow"} {
+		_, ok = RetryAfter([]byte(v), now)
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import (
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+)
+
+// Option is the only struct that can be used to set the Options of Client.
+type Option struct {
+	F func(o *Options)
+}
+
+// Options All configurations related to Client
+type Options struct {
+	// The options of the hertz client sending the requests,
+	// the response body is always streamed
+	ClientOptions []config.ClientOption
+
+	// The headers added to the requests
+	Headers map[string]string
+
+	// The delay before reconnecting, which is replaced by the retry field of the stream
+	ReconnectDelay time.Duration
+
+	// The maximum number of consecutive reconnections, 0 means unlimited
+	MaxReconnects int
+
+	// The stream is reconnected if nothing is received within HeartbeatTimeout,
+	// including the comments sent as heartbeats, 0 means disabled
+	HeartbeatTimeout time.Duration
+
+	// The maximum size of a line of the stream
+	MaxLineSize int
+
+	// The function called with the error once the stream is disconnected,
+	// before reconnecting
+	OnDisconnect func(err error)
+}
+
+func (o *Options) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+// WithClientOptions set ClientOptions.
+func WithClientOptions(opts ...config.ClientOption) Option {
+	return Option{F: func(o *Options) {
+		o.ClientOptions = append(o.ClientOptions, opts...)
+	}}
+}
+
+// WithHeader add a header to Headers.
+func WithHeader(key, value string) Option {
+	return Option{F: func(o *Options) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string)
+		}
+		o.Headers[key] = value
+	}}
+}
+
+// WithReconnectDelay set ReconnectDelay.
+func WithReconnectDelay(delay time.Duration) Option {
+	return Option{F: func(o *Options) {
+		o.ReconnectDelay = delay
+	}}
+}
+
+// WithMaxReconnects set MaxReconnects.
+func WithMaxReconnects(n int) Option {
+	return Option{F: func(o *Options) {
+		o.MaxReconnects = n
+	}}
+}
+
+// WithHeartbeatTimeout set HeartbeatTimeout.
+func WithHeartbeatTimeout(timeout time.Duration) Option {
+	return Option{F: func(o *Options) {
+		o.HeartbeatTimeout = timeout
+	}}
+}
+
+// WithMaxLineSize set MaxLineSize.
+func WithMaxLineSize(size int) Option {
+	return Option{F: func(o *Options) {
+		o.MaxLineSize = size
+	}}
+}
+
+// WithDisconnectHandler set OnDisconnect.
+func WithDisconnectHandler(f func(err error)) Option {
+	return Option{F: func(o *Options) {
+		o.OnDisconnect = f
+	}}
+}
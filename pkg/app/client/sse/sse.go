@@ -0,0 +1,438 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sse provides a client consuming the streams of server-sent events,
+// see https://html.spec.whatwg.org/multipage/server-sent-events.html.
+//
+// The stream is reconnected with the Last-Event-ID header once it is disconnected,
+// until the context is canceled or the server responds 204 No Content.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/dialer"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ContentType is the content type of the event streams.
+const ContentType = "text/event-stream"
+
+var (
+	// ErrHeartbeatTimeout is the error of the stream disconnected for nothing received within HeartbeatTimeout.
+	ErrHeartbeatTimeout = errors.New("sse: heartbeat timeout")
+
+	// ErrNoContent is returned by Subscribe once the server responds 204 No Content,
+	// which tells the client to stop reconnecting.
+	ErrNoContent = errors.New("sse: no content")
+
+	// ErrContentType is the error of the responses without text/event-stream content type.
+	ErrContentType = errors.New("sse: unexpected content type")
+
+	errLineTooLong = errors.New("sse: line too long")
+)
+
+// StatusError is the error of the responses which are neither 200 OK nor 204 No Content.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return "sse: unexpected status code " + strconv.Itoa(e.StatusCode)
+}
+
+// Event is an event dispatched from the stream.
+type Event struct {
+	// ID is the last event ID of the stream when the event is dispatched
+	ID string
+	// Event is the type of the event, which is "message" if not specified by the stream
+	Event string
+	Data  []byte
+}
+
+// Client subscribes the stream of url.
+//
+// NOTE:
+//
+//	A Client subscribes a single stream at a time, Subscribe must not be called concurrently.
+type Client struct {
+	url  string
+	opts *Options
+	hc   *client.Client
+
+	mu          sync.Mutex
+	conn        network.Conn
+	aborted     error
+	lastEventID string
+	retry       time.Duration
+}
+
+// NewClient returns a client of the stream of url.
+func NewClient(url string, opts ...Option) (*Client, error) {
+	o := &Options{
+		ReconnectDelay: 3 * time.Second,
+		MaxLineSize:    1 << 20,
+	}
+	o.Apply(opts)
+
+	c := &Client{
+		url:   url,
+		opts:  o,
+		retry: o.ReconnectDelay,
+	}
+	clientOpts := append(append([]config.ClientOption(nil), o.ClientOptions...),
+		client.WithResponseBodyStream(true),
+		// the connection of the stream is tracked, so that it can be closed to stop a blocked read
+		config.ClientOption{F: func(co *config.ClientOptions) {
+			d := co.Dialer
+			if d == nil {
+				d = dialer.DefaultDialer()
+			}
+			co.Dialer = &trackingDialer{Dialer: d, c: c}
+		}},
+	)
+	hc, err := client.NewClient(clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.hc = hc
+	return c, nil
+}
+
+// LastEventID returns the ID of the last event, which is sent by Last-Event-ID header when reconnecting.
+func (c *Client) LastEventID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastEventID
+}
+
+// SetLastEventID sets the ID of the last event, e.g. to resume the stream of a previous Client.
+func (c *Client) SetLastEventID(id string) {
+	c.mu.Lock()
+	c.lastEventID = id
+	c.mu.Unlock()
+}
+
+// Subscribe connects to the stream and calls handler with the events in order, the stream is
+// reconnected once it is disconnected. It returns the error of ctx once ctx is done, ErrNoContent
+// if the server responds 204 No Content, or the last error if MaxReconnects is exceeded.
+//
+// The responses with the status codes other than 200 OK, 204 No Content and 5xx, or without
+// text/event-stream content type, fail the subscription without reconnecting.
+func (c *Client) Subscribe(ctx context.Context, handler func(e *Event)) error {
+	reconnects := 0
+	for {
+		connected, err := c.stream(ctx, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) {
+			return err
+		}
+		if c.opts.OnDisconnect != nil {
+			c.opts.OnDisconnect(err)
+		}
+
+		if connected {
+			reconnects = 0
+		}
+		reconnects++
+		if c.opts.MaxReconnects > 0 && reconnects > c.opts.MaxReconnects {
+			return err
+		}
+
+		c.mu.Lock()
+		delay := c.retry
+		c.mu.Unlock()
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// retryable reports whether the stream should be reconnected after err.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= consts.StatusInternalServerError
+	}
+	return !errors.Is(err, ErrNoContent) && !errors.Is(err, ErrContentType) && !errors.Is(err, errLineTooLong)
+}
+
+// SubscribeChan is the same as Subscribe, except that the events are sent to ch.
+func (c *Client) SubscribeChan(ctx context.Context, ch chan<- *Event) error {
+	return c.Subscribe(ctx, func(e *Event) {
+		select {
+		case ch <- e:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// stream reads the events until the stream is disconnected, connected reports whether
+// the stream is established.
+func (c *Client) stream(ctx context.Context, handler func(e *Event)) (connected bool, err error) {
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	defer func() {
+		protocol.ReleaseRequest(req)
+		// the connection is released to the pool once the body stream is closed,
+		// which is closed here since the next stream dials a new one
+		protocol.ReleaseResponse(resp)
+		c.hc.CloseIdleConnections()
+	}()
+	req.SetRequestURI(c.url)
+	req.SetMethod(consts.MethodGet)
+	// a new connection is dialed for each stream, which is the one tracked
+	req.SetConnectionClose()
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(consts.HeaderAccept, ContentType)
+	req.Header.Set(consts.HeaderCacheControl, "no-cache")
+	if id := c.LastEventID(); id != "" {
+		req.Header.Set(consts.HeaderLastEventID, id)
+	}
+
+	c.mu.Lock()
+	c.conn, c.aborted = nil, nil
+	c.mu.Unlock()
+	// the stream is aborted by closing the connection once ctx is done or the heartbeat times out
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.abort(ctx.Err())
+		case <-done:
+		}
+	}()
+	var heartbeat *time.Timer
+	if c.opts.HeartbeatTimeout > 0 {
+		heartbeat = time.AfterFunc(c.opts.HeartbeatTimeout, func() { c.abort(ErrHeartbeatTimeout) })
+		defer heartbeat.Stop()
+	}
+
+	if err = c.hc.Do(ctx, req, resp); err != nil {
+		return false, c.abortedErr(err)
+	}
+	switch status := resp.StatusCode(); {
+	case status == consts.StatusNoContent:
+		return false, ErrNoContent
+	case status != consts.StatusOK:
+		return false, &StatusError{StatusCode: status}
+	}
+	if ct := string(resp.Header.ContentType()); !strings.HasPrefix(strings.ToLower(ct), ContentType) {
+		return false, fmt.Errorf("%w %q", ErrContentType, ct)
+	}
+
+	p := &parser{r: bufio.NewReader(resp.BodyStream()), maxLineSize: c.opts.MaxLineSize}
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return true, c.abortedErr(err)
+		}
+		if heartbeat != nil {
+			heartbeat.Reset(c.opts.HeartbeatTimeout)
+		}
+		if e := p.processLine(line); e != nil {
+			c.mu.Lock()
+			c.lastEventID = p.lastEventID
+			c.mu.Unlock()
+			if e.Data != nil {
+				handler(e)
+			}
+		}
+		if p.retry > 0 {
+			c.mu.Lock()
+			c.retry = p.retry
+			c.mu.Unlock()
+			p.retry = 0
+		}
+	}
+}
+
+// abort closes the connection of the stream, so that the blocked read returns.
+func (c *Client) abort(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.aborted != nil {
+		return
+	}
+	c.aborted = err
+	if c.conn != nil {
+		closeConn(c.conn)
+	}
+}
+
+func closeConn(conn network.Conn) {
+	// the buffers are still used by the goroutine reading the stream
+	if cc, ok := conn.(interface{ CloseNoResetBuffer() error }); ok {
+		cc.CloseNoResetBuffer() //nolint:errcheck
+		return
+	}
+	conn.Close() //nolint:errcheck
+}
+
+// abortedErr returns the error aborting the stream instead of err if it's aborted.
+func (c *Client) abortedErr(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.aborted != nil {
+		return c.aborted
+	}
+	return err
+}
+
+// trackingDialer records the last connection dialed, which is the one of the stream
+// since a Client has a single request at a time.
+type trackingDialer struct {
+	network.Dialer
+	c *Client
+}
+
+func (d *trackingDialer) DialConnection(n, address string, timeout time.Duration, tlsConfig *tls.Config) (network.Conn, error) {
+	conn, err := d.Dialer.DialConnection(n, address, timeout, tlsConfig)
+	if err == nil {
+		d.c.mu.Lock()
+		d.c.conn = conn
+		if d.c.aborted != nil {
+			closeConn(conn)
+		}
+		d.c.mu.Unlock()
+	}
+	return conn, err
+}
+
+// parser parses the lines of the stream into events.
+type parser struct {
+	r           *bufio.Reader
+	maxLineSize int
+	line        []byte
+	// pending is set if the last line ends with '\r', so that the following '\n' is skipped
+	pending bool
+	bomDone bool
+
+	eventType   string
+	data        []byte
+	hasData     bool
+	lastEventID string
+	retry       time.Duration
+}
+
+// readLine returns the next line without the end of line, which is "\r\n", "\n" or "\r".
+func (p *parser) readLine() ([]byte, error) {
+	p.line = p.line[:0]
+	for {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			// the incomplete line at the end of the stream is discarded
+			return nil, err
+		}
+		if p.pending {
+			p.pending = false
+			if b == '\n' {
+				continue
+			}
+		}
+		switch b {
+		case '\r':
+			p.pending = true
+			return p.stripBOM(p.line), nil
+		case '\n':
+			return p.stripBOM(p.line), nil
+		}
+		if len(p.line) >= p.maxLineSize {
+			return nil, errLineTooLong
+		}
+		p.line = append(p.line, b)
+	}
+}
+
+func (p *parser) stripBOM(line []byte) []byte {
+	if !p.bomDone {
+		p.bomDone = true
+		return bytes.TrimPrefix(line, []byte("\xEF\xBB\xBF"))
+	}
+	return line
+}
+
+// processLine processes a line, the event is returned once it is dispatched by an empty line.
+// The Data of the event is nil if there is no data, which should not be handled.
+func (p *parser) processLine(line []byte) *Event {
+	if len(line) == 0 {
+		e := &Event{ID: p.lastEventID, Event: p.eventType}
+		if p.hasData {
+			e.Data = bytes.TrimSuffix(p.data, []byte("\n"))
+			if e.Data == nil {
+				e.Data = []byte{}
+			}
+		}
+		if e.Event == "" {
+			e.Event = "message"
+		}
+		p.eventType, p.data, p.hasData = "", nil, false
+		return e
+	}
+	if line[0] == ':' {
+		// comment, e.g. heartbeat
+		return nil
+	}
+
+	field, value := line, []byte(nil)
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		field, value = line[:i], line[i+1:]
+		if len(value) > 0 && value[0] == ' ' {
+			value = value[1:]
+		}
+	}
+	switch string(field) {
+	case "event":
+		p.eventType = string(value)
+	case "data":
+		p.data = append(append(p.data, value...), '\n')
+		p.hasData = true
+	case "id":
+		if bytes.IndexByte(value, 0) < 0 {
+			p.lastEventID = string(value)
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+			p.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func parse(t *testing.T, s string) (events []*Event, p *parser) {
+	p = &parser{r: bufio.NewReader(strings.NewReader(s)), maxLineSize: 64}
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			assert.DeepEqual(t, io.EOF, err)
+			return
+		}
+		if e := p.processLine(line); e != nil && e.Data != nil {
+			events = append(events, e)
+		}
+	}
+}
+
+func TestParser(t *testing.T) {
+	events, p := parse(t, "\xEF\xBB\xBFdata: a\r\n"+
+		"data:b\r\n\r\n"+
+		": heartbeat\n"+
+		"event: update\rid: 1\rdata\r\r"+
+		"id: 2\n\n"+
+		"id: 3\x00\nretry: 1500\nretry: x\nunknown: v\ndata: {\"k\": \"v: 1\"}\n\n"+
+		"data: incomplete")
+	assert.DeepEqual(t, 3, len(events))
+	assert.DeepEqual(t, &Event{Event: "message", Data: []byte("a\nb")}, events[0])
+	assert.DeepEqual(t, &Event{ID: "1", Event: "update", Data: []byte{}}, events[1])
+	assert.DeepEqual(t, &Event{ID: "2", Event: "message", Data: []byte(`{"k": "v: 1"}`)}, events[2])
+	assert.DeepEqual(t, "2", p.lastEventID)
+	assert.DeepEqual(t, 1500*time.Millisecond, p.retry)
+
+	p = &parser{r: bufio.NewReader(strings.NewReader(strings.Repeat("a", 65) + "\n")), maxLineSize: 64}
+	_, err := p.readLine()
+	assert.DeepEqual(t, errLineTooLong, err)
+}
+
+func TestSubscribe(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		ids     []string
+		headers []string
+	)
+	stop := make(chan struct{})
+	defer close(stop)
+	stream := func(ctx *app.RequestContext, body string, stall bool) {
+		ctx.SetContentType(ContentType + "; charset=utf-8")
+		pr, pw := io.Pipe()
+		go func() {
+			pw.Write([]byte(body)) //nolint:errcheck
+			if stall {
+				<-stop
+			}
+			pw.Close()
+		}()
+		ctx.SetBodyStream(pr, -1)
+	}
+
+	h := server.New(server.WithHostPorts("127.0.0.1:9251"), server.WithTransport(standard.NewTransporter))
+	h.GET("/events", func(c context.Context, ctx *app.RequestContext) {
+		mu.Lock()
+		ids = append(ids, string(ctx.Request.Header.Peek(consts.HeaderLastEventID)))
+		headers = append(headers, string(ctx.Request.Header.Peek("X-Token")))
+		mu.Unlock()
+		switch string(ctx.Request.Header.Peek(consts.HeaderLastEventID)) {
+		case "":
+			stream(ctx, "retry: 10\n\nid: 1\ndata: a\n\n", false)
+		case "1":
+			stream(ctx, "id: 2\nevent: update\ndata: b\n\ndata: c\n\n", false)
+		default:
+			ctx.SetStatusCode(consts.StatusNoContent)
+		}
+	})
+	h.GET("/stall", func(c context.Context, ctx *app.RequestContext) {
+		stream(ctx, "data: a\n\n:\n", true)
+	})
+	h.GET("/fail", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetStatusCode(consts.StatusNotFound)
+	})
+	h.GET("/plain", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "data: a\n\n")
+	})
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	// reconnect with Last-Event-ID until 204 No Content
+	var disconnects []error
+	c, err := NewClient("http://127.0.0.1:9251/events", WithHeader("X-Token", "t"),
+		WithDisconnectHandler(func(err error) { disconnects = append(disconnects, err) }))
+	assert.Nil(t, err)
+	var events []*Event
+	err = c.Subscribe(context.Background(), func(e *Event) { events = append(events, e) })
+	assert.DeepEqual(t, ErrNoContent, err)
+	assert.DeepEqual(t, []*Event{
+		{ID: "1", Event: "message", Data: []byte("a")},
+		{ID: "2", Event: "update", Data: []byte("b")},
+		{ID: "2", Event: "message", Data: []byte("c")},
+	}, events)
+	assert.DeepEqual(t, "2", c.LastEventID())
+	assert.DeepEqual(t, 2, len(disconnects))
+	assert.DeepEqual(t, io.ErrUnexpectedEOF, disconnects[0])
+	mu.Lock()
+	assert.DeepEqual(t, []string{"", "1", "2"}, ids)
+	assert.DeepEqual(t, []string{"t", "t", "t"}, headers)
+	mu.Unlock()
+
+	// heartbeat timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	disconnects = nil
+	c, err = NewClient("http://127.0.0.1:9251/stall", WithHeartbeatTimeout(200*time.Millisecond),
+		WithReconnectDelay(10*time.Millisecond), WithDisconnectHandler(func(err error) {
+			if disconnects = append(disconnects, err); len(disconnects) == 2 {
+				cancel()
+			}
+		}))
+	assert.Nil(t, err)
+	ch := make(chan *Event, 2)
+	start := time.Now()
+	err = c.SubscribeChan(ctx, ch)
+	assert.DeepEqual(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 2*time.Second)
+	assert.DeepEqual(t, []error{ErrHeartbeatTimeout, ErrHeartbeatTimeout}, disconnects)
+	assert.DeepEqual(t, 2, len(ch))
+
+	// canceled
+	c, err = NewClient("http://127.0.0.1:9251/stall")
+	assert.Nil(t, err)
+	ctx, cancel = context.WithCancel(context.Background())
+	err = c.Subscribe(ctx, func(e *Event) { cancel() })
+	assert.DeepEqual(t, context.Canceled, err)
+
+	// not reconnected
+	c, err = NewClient("http://127.0.0.1:9251/fail", WithReconnectDelay(10*time.Millisecond), WithMaxReconnects(1))
+	assert.Nil(t, err)
+	err = c.Subscribe(context.Background(), func(e *Event) {})
+	var se *StatusError
+	assert.True(t, errors.As(err, &se))
+	assert.DeepEqual(t, consts.StatusNotFound, se.StatusCode)
+
+	c, err = NewClient("http://127.0.0.1:9251/plain")
+	assert.Nil(t, err)
+	err = c.Subscribe(context.Background(), func(e *Event) {})
+	assert.True(t, errors.Is(err, ErrContentType))
+}
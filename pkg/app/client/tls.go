@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/tls"
+	"strings"
+)
+
+// hostTLSConfigFunc returns the function looking up configs for host names,
+// or nil if configs is empty.
+func hostTLSConfigFunc(configs map[string]*tls.Config) func(host string) *tls.Config {
+	if len(configs) == 0 {
+		return nil
+	}
+	return func(host string) *tls.Config {
+		return matchHostTLSConfig(configs, host)
+	}
+}
+
+// matchHostTLSConfig returns the config of the host name, or the config of
+// the longest wildcard which matches host.
+func matchHostTLSConfig(configs map[string]*tls.Config, host string) *tls.Config {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if cfg, ok := configs[host]; ok {
+		return cfg
+	}
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if cfg, ok := configs["*."+host]; ok {
+			return cfg
+		}
+	}
+	return nil
+}
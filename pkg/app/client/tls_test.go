@@ -0,0 +1,48 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestMatchHostTLSConfig(t *testing.T) {
+	exact := &tls.Config{ServerName: "exact"}
+	wildcard := &tls.Config{ServerName: "wildcard"}
+	longer := &tls.Config{ServerName: "longer"}
+	opt := config.NewClientOptions([]config.ClientOption{
+		WithHostTLSConfig("API.example.com", exact),
+		WithHostTLSConfig("*.example.com", wildcard),
+		WithHostTLSConfig("*.internal.example.com", longer),
+	})
+	assert.DeepEqual(t, 3, len(opt.HostTLSConfigs))
+
+	f := hostTLSConfigFunc(opt.HostTLSConfigs)
+	assert.DeepEqual(t, exact, f("api.example.com"))
+	assert.DeepEqual(t, exact, f("Api.Example.com."))
+	assert.DeepEqual(t, wildcard, f("www.example.com"))
+	assert.DeepEqual(t, wildcard, f("a.b.example.com"))
+	assert.DeepEqual(t, longer, f("db.internal.example.com"))
+	assert.Nil(t, f("example.com"))
+	assert.Nil(t, f("example.org"))
+
+	assert.Nil(t, hostTLSConfigFunc(nil))
+}
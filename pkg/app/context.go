@@ -159,6 +159,21 @@ type RequestContext struct {
 
 	// clientIPFunc get form value by use custom function.
 	formValueFunc FormValueFunc
+
+	// bindConfig overrides the default binding.BindConfig for the current request.
+	bindConfig *binding.BindConfig
+
+	// translator translates the messages into the language of the current request.
+	translator Translator
+
+	// errorReported means the error of the current request has been reported by errreport.
+	errorReported bool
+}
+
+// Translator translates the message of key into the language of the current request,
+// which is usually set by the i18n middleware.
+type Translator interface {
+	Translate(key string, args ...interface{}) string
 }
 
 func (ctx *RequestContext) SetClientIPFunc(f ClientIP) {
@@ -169,6 +184,43 @@ func (ctx *RequestContext) SetFormValueFunc(f FormValueFunc) {
 	ctx.formValueFunc = f
 }
 
+// SetBindConfig sets the binding.BindConfig used by Bind and BindAndValidate of the current request.
+// It is usually called by a middleware to customize binding for a route or a group.
+func (ctx *RequestContext) SetBindConfig(config *binding.BindConfig) {
+	ctx.bindConfig = config
+}
+
+// SetTranslator sets the Translator used by T of the current request.
+func (ctx *RequestContext) SetTranslator(t Translator) {
+	ctx.translator = t
+}
+
+// Translator returns the Translator of the current request, or nil if it is not set.
+func (ctx *RequestContext) Translator() Translator {
+	return ctx.translator
+}
+
+// MarkErrorReported marks the error of the current request as reported, so that it is reported once,
+// e.g. a panic turned into a 500 response. It returns false if it has been marked already.
+//
+// NOTE: It is an internal function used by errreport. You should not use it.
+func (ctx *RequestContext) MarkErrorReported() bool {
+	if ctx.errorReported {
+		return false
+	}
+	ctx.errorReported = true
+	return true
+}
+
+// T translates the message of key with args by the Translator of the current request.
+// The key is returned as is if no Translator is set.
+func (ctx *RequestContext) T(key string, args ...interface{}) string {
+	if ctx.translator == nil {
+		return key
+	}
+	return ctx.translator.Translate(key, args...)
+}
+
 func (ctx *RequestContext) GetTraceInfo() traceinfo.TraceInfo {
 	return ctx.traceInfo
 }
@@ -386,6 +438,28 @@ func (ctx *RequestContext) RemoteAddr() net.Addr {
 	return addr
 }
 
+// InterceptBody calls fn with the response body written by the handlers,
+// and replaces the body with the one returned by fn.
+// It is usually called by a middleware after ctx.Next(), e.g. to compute
+// ETag for dynamic content, sign or transform the body.
+//
+// NOTE:
+//
+//	A body stream is read completely before fn is called,
+//	use ctx.Response.WrapBodyStream to process the stream on the fly.
+//	The body passed to fn is only valid until fn returns.
+func (ctx *RequestContext) InterceptBody(fn func(body []byte) ([]byte, error)) error {
+	body, err := ctx.Response.BodyE()
+	if err != nil {
+		return err
+	}
+	if body, err = fn(body); err != nil {
+		return err
+	}
+	ctx.Response.SetBody(body)
+	return nil
+}
+
 // WriteString appends s to response body.
 func (ctx *RequestContext) WriteString(s string) (int, error) {
 	ctx.Response.AppendBodyString(s)
@@ -541,6 +615,13 @@ func (ctx *RequestContext) RequestBodyStream() io.Reader {
 	return ctx.Request.BodyStream()
 }
 
+// RequestBodyReader returns an io.ReadCloser of the request body.
+//
+// See protocol.Request.BodyReader for the semantics of streamed bodies and Close.
+func (ctx *RequestContext) RequestBodyReader() io.ReadCloser {
+	return ctx.Request.BodyReader()
+}
+
 // MultipartForm returns request's multipart form.
 //
 // Returns errNoMultipartForm if request's content-type
@@ -559,6 +640,17 @@ func (ctx *RequestContext) MultipartForm() (*multipart.Form, error) {
 	return ctx.Request.MultipartForm()
 }
 
+// SetMultipartFormConfig sets the policy of parsing multipart form for the request,
+// such as max in-memory bytes, temporary directory and file permission.
+// It is usually called by a middleware to customize the policy for a route or a group.
+// NOTE:
+//
+//	It must be called before the multipart form is parsed,
+//	and the pre-parsing should be disabled by server.WithDisablePreParseMultipartForm.
+func (ctx *RequestContext) SetMultipartFormConfig(config *protocol.MultipartFormConfig) {
+	ctx.Request.SetMultipartFormConfig(config)
+}
+
 // SaveUploadedFile uploads the form file to specific dst.
 func (ctx *RequestContext) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
 	src, err := file.Open()
@@ -633,8 +725,10 @@ func getRedirectStatusCode(statusCode int) int {
 // to get a copy of requestContext.
 func (ctx *RequestContext) Copy() *RequestContext {
 	cp := &RequestContext{
-		conn:   ctx.conn,
-		Params: ctx.Params,
+		conn:       ctx.conn,
+		Params:     ctx.Params,
+		bindConfig: ctx.bindConfig,
+		translator: ctx.translator,
 	}
 	ctx.Request.CopyTo(&cp.Request)
 	ctx.Response.CopyTo(&cp.Response)
@@ -690,6 +784,9 @@ func (ctx *RequestContext) ResetWithoutConn() {
 	ctx.index = -1
 	ctx.fullPath = ""
 	ctx.Keys = nil
+	ctx.bindConfig = nil
+	ctx.translator = nil
+	ctx.errorReported = false
 
 	if ctx.finished != nil {
 		close(ctx.finished)
@@ -1046,6 +1143,11 @@ func (ctx *RequestContext) Cookie(key string) []byte {
 	return ctx.Request.Header.Cookie(key)
 }
 
+// CookieValues returns all the values of the request cookie key in the order they were received.
+func (ctx *RequestContext) CookieValues(key string) [][]byte {
+	return ctx.Request.Header.CookieValues(key)
+}
+
 // SetCookie adds a Set-Cookie header to the Response's headers.
 //
 //	Parameter introduce:
@@ -1171,6 +1273,45 @@ func (ctx *RequestContext) GetQuery(key string) (string, bool) {
 	return ctx.QueryArgs().PeekExists(key)
 }
 
+// QueryArray returns all the values of the keyed url query, including the values in
+// array syntax, otherwise it returns an empty slice.
+//
+// For example:
+//
+//	GET /path?ids=1&ids=2&names[]=a&names[]=b
+//	c.QueryArray("ids") == []string{"1", "2"}
+//	c.QueryArray("names") == []string{"a", "b"}
+func (ctx *RequestContext) QueryArray(key string) []string {
+	values, _ := ctx.GetQueryArray(key)
+	return values
+}
+
+// GetQueryArray is like QueryArray, and it also returns whether the key exists.
+func (ctx *RequestContext) GetQueryArray(key string) ([]string, bool) {
+	return bytesToStrings(ctx.QueryArgs().PeekAll(key))
+}
+
+// QueryMap returns the values of the keyed url query in map syntax,
+// otherwise it returns an empty map.
+//
+// For example:
+//
+//	GET /path?ids[a]=1&ids[b]=2
+//	c.QueryMap("ids") == map[string]string{"a": "1", "b": "2"}
+func (ctx *RequestContext) QueryMap(key string) map[string]string {
+	m, _ := ctx.GetQueryMap(key)
+	return m
+}
+
+// GetQueryMap is like QueryMap, and it also returns whether the key exists.
+func (ctx *RequestContext) GetQueryMap(key string) (map[string]string, bool) {
+	m := ctx.QueryArgs().PeekMap(key)
+	if m == nil {
+		return make(map[string]string), false
+	}
+	return m, true
+}
+
 // PostForm returns the specified key from a POST urlencoded form or multipart form
 // when it exists, otherwise it returns an empty string `("")`.
 func (ctx *RequestContext) PostForm(key string) string {
@@ -1205,6 +1346,69 @@ func (ctx *RequestContext) GetPostForm(key string) (string, bool) {
 	return ctx.multipartFormValue(key)
 }
 
+// PostFormArray returns all the values of the specified key from a POST urlencoded form
+// or multipart form, including the values in array syntax, e.g. "names[]=a&names[]=b",
+// otherwise it returns an empty slice.
+func (ctx *RequestContext) PostFormArray(key string) []string {
+	values, _ := ctx.GetPostFormArray(key)
+	return values
+}
+
+// GetPostFormArray is like PostFormArray, and it also returns whether the key exists.
+func (ctx *RequestContext) GetPostFormArray(key string) ([]string, bool) {
+	if values, ok := bytesToStrings(ctx.PostArgs().PeekAll(key)); ok {
+		return values, ok
+	}
+	mf, err := ctx.MultipartForm()
+	if err != nil || mf.Value == nil {
+		return []string{}, false
+	}
+	values := append(append([]string{}, mf.Value[key]...), mf.Value[key+"[]"]...)
+	if len(values) == 0 {
+		return []string{}, false
+	}
+	return values, true
+}
+
+// PostFormMap returns the values of the specified key in map syntax from a POST urlencoded
+// form or multipart form, e.g. "ids[a]=1&ids[b]=2", otherwise it returns an empty map.
+func (ctx *RequestContext) PostFormMap(key string) map[string]string {
+	m, _ := ctx.GetPostFormMap(key)
+	return m
+}
+
+// GetPostFormMap is like PostFormMap, and it also returns whether the key exists.
+func (ctx *RequestContext) GetPostFormMap(key string) (map[string]string, bool) {
+	if m := ctx.PostArgs().PeekMap(key); m != nil {
+		return m, true
+	}
+	mf, err := ctx.MultipartForm()
+	if err != nil || mf.Value == nil {
+		return make(map[string]string), false
+	}
+	args := protocol.Args{}
+	for k, vs := range mf.Value {
+		for _, v := range vs {
+			args.Add(k, v)
+		}
+	}
+	if m := args.PeekMap(key); m != nil {
+		return m, true
+	}
+	return make(map[string]string), false
+}
+
+func bytesToStrings(values [][]byte) ([]string, bool) {
+	if len(values) == 0 {
+		return []string{}, false
+	}
+	ret := make([]string, len(values))
+	for i, v := range values {
+		ret[i] = string(v)
+	}
+	return ret, true
+}
+
 // bodyAllowedForStatus is a copy of http.bodyAllowedForStatus non-exported function.
 func bodyAllowedForStatus(status int) bool {
 	switch {
@@ -1221,12 +1425,18 @@ func bodyAllowedForStatus(status int) bool {
 // BindAndValidate binds data from *RequestContext to obj and validates them if needed.
 // NOTE: obj should be a pointer.
 func (ctx *RequestContext) BindAndValidate(obj interface{}) error {
+	if ctx.bindConfig != nil {
+		return binding.BindAndValidateWithConfig(&ctx.Request, obj, ctx.Params, ctx.bindConfig)
+	}
 	return binding.BindAndValidate(&ctx.Request, obj, ctx.Params)
 }
 
 // Bind binds data from *RequestContext to obj.
 // NOTE: obj should be a pointer.
 func (ctx *RequestContext) Bind(obj interface{}) error {
+	if ctx.bindConfig != nil {
+		return binding.BindWithConfig(&ctx.Request, obj, ctx.Params, ctx.bindConfig)
+	}
 	return binding.Bind(&ctx.Request, obj, ctx.Params)
 }
 
@@ -1235,3 +1445,49 @@ func (ctx *RequestContext) Bind(obj interface{}) error {
 func (ctx *RequestContext) Validate(obj interface{}) error {
 	return binding.Validate(obj)
 }
+
+// ShouldBind binds data from *RequestContext to obj and validates them,
+// it is the same as BindAndValidate.
+// The ShouldBind family only returns errors, the response is never touched,
+// so handlers keep full control of the error response.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBind(obj interface{}) error {
+	return ctx.BindAndValidate(obj)
+}
+
+// ShouldBindJSON binds the json body to obj regardless of Content-Type and validates them.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBindJSON(obj interface{}) error {
+	return ctx.shouldBindSources(obj, binding.SourceJSON)
+}
+
+// ShouldBindQuery binds the url query to obj and validates them.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBindQuery(obj interface{}) error {
+	return ctx.shouldBindSources(obj, binding.SourceQuery)
+}
+
+// ShouldBindForm binds the urlencoded form or multipart form to obj and validates them.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBindForm(obj interface{}) error {
+	return ctx.shouldBindSources(obj, binding.SourceForm)
+}
+
+// ShouldBindHeader binds the request headers to obj and validates them.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBindHeader(obj interface{}) error {
+	return ctx.shouldBindSources(obj, binding.SourceHeader)
+}
+
+// ShouldBindPath binds the path parameters to obj and validates them.
+// NOTE: obj should be a pointer.
+func (ctx *RequestContext) ShouldBindPath(obj interface{}) error {
+	return ctx.shouldBindSources(obj, binding.SourcePath)
+}
+
+func (ctx *RequestContext) shouldBindSources(obj interface{}, sources ...binding.Source) error {
+	if err := binding.BindSources(&ctx.Request, obj, ctx.Params, sources...); err != nil {
+		return err
+	}
+	return binding.Validate(obj)
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/internal/bytestr"
+	"github.com/cloudwego/hertz/pkg/app/server/binding"
 	"github.com/cloudwego/hertz/pkg/app/server/render"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
@@ -155,6 +157,14 @@ func TestGetRedirectStatusCode(t *testing.T) {
 	assert.DeepEqual(t, consts.StatusFound, val)
 }
 
+func TestCookieValues(t *testing.T) {
+	ctx := NewContext(0)
+	ctx.Request.Header.Set("Cookie", "a=1; b=2; a=3")
+	assert.DeepEqual(t, "1", string(ctx.Cookie("a")))
+	assert.DeepEqual(t, [][]byte{[]byte("1"), []byte("3")}, ctx.CookieValues("a"))
+	assert.DeepEqual(t, 0, len(ctx.CookieValues("c")))
+}
+
 func TestCookie(t *testing.T) {
 	ctx := NewContext(0)
 	ctx.Request.Header.SetCookie("cookie", "test cookie")
@@ -200,6 +210,34 @@ func TestGet(t *testing.T) {
 	assert.True(t, ctx.IsGet())
 }
 
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(key string, args ...interface{}) string {
+	return strings.ToUpper(fmt.Sprintf(key, args...))
+}
+
+func TestTranslator(t *testing.T) {
+	ctx := NewContext(0)
+	assert.Nil(t, ctx.Translator())
+	assert.DeepEqual(t, "hello", ctx.T("hello", 1))
+
+	ctx.SetTranslator(upperTranslator{})
+	assert.DeepEqual(t, "HELLO 1", ctx.T("hello %d", 1))
+	assert.DeepEqual(t, "HELLO", ctx.Copy().T("hello"))
+
+	ctx.Reset()
+	assert.Nil(t, ctx.Translator())
+}
+
+func TestMarkErrorReported(t *testing.T) {
+	ctx := NewContext(0)
+	assert.True(t, ctx.MarkErrorReported())
+	assert.False(t, ctx.MarkErrorReported())
+
+	ctx.Reset()
+	assert.True(t, ctx.MarkErrorReported())
+}
+
 func TestCopy(t *testing.T) {
 	t.Parallel()
 	ctx := NewContext(0)
@@ -278,6 +316,46 @@ func TestQuery(t *testing.T) {
 	}
 }
 
+func TestQueryArrayAndMap(t *testing.T) {
+	ctx := NewContext(0)
+	ctx.Request.SetRequestURI("/foo?ids=1&ids=2&names[]=a&names[]=b&m[a]=1&m[b]=2")
+
+	assert.DeepEqual(t, []string{"1", "2"}, ctx.QueryArray("ids"))
+	assert.DeepEqual(t, []string{"a", "b"}, ctx.QueryArray("names"))
+	values, ok := ctx.GetQueryArray("none")
+	assert.False(t, ok)
+	assert.DeepEqual(t, []string{}, values)
+
+	assert.DeepEqual(t, map[string]string{"a": "1", "b": "2"}, ctx.QueryMap("m"))
+	m, ok := ctx.GetQueryMap("none")
+	assert.False(t, ok)
+	assert.DeepEqual(t, map[string]string{}, m)
+}
+
+func TestPostFormArrayAndMap(t *testing.T) {
+	ctx := NewContext(0)
+	ctx.Request.SetFormDataFromValues(url.Values{"ids[]": {"1", "2"}, "m[a]": {"1"}})
+	assert.DeepEqual(t, []string{"1", "2"}, ctx.PostFormArray("ids"))
+	assert.DeepEqual(t, map[string]string{"a": "1"}, ctx.PostFormMap("m"))
+
+	body := "--foo\r\n" +
+		"Content-Disposition: form-data; name=\"ids[]\"\r\n\r\n1\r\n" +
+		"--foo\r\n" +
+		"Content-Disposition: form-data; name=\"ids[]\"\r\n\r\n2\r\n" +
+		"--foo\r\n" +
+		"Content-Disposition: form-data; name=\"m[b]\"\r\n\r\n2\r\n" +
+		"--foo--\r\n"
+	ctx = NewContext(0)
+	ctx.Request.SetBodyString(body)
+	ctx.Request.Header.SetContentTypeBytes([]byte("multipart/form-data; boundary=foo"))
+	assert.DeepEqual(t, []string{"1", "2"}, ctx.PostFormArray("ids"))
+	assert.DeepEqual(t, map[string]string{"b": "2"}, ctx.PostFormMap("m"))
+	_, ok := ctx.GetPostFormArray("none")
+	assert.False(t, ok)
+	_, ok = ctx.GetPostFormMap("none")
+	assert.False(t, ok)
+}
+
 func TestMethod(t *testing.T) {
 	ctx := NewContext(0)
 	ctx.Status(consts.StatusOK)
@@ -1207,6 +1285,115 @@ func TestBindAndValidate(t *testing.T) {
 	}
 }
 
+func TestBindWithBindConfig(t *testing.T) {
+	type Test struct {
+		A string `query:"a" header:"a"`
+	}
+
+	c := &RequestContext{}
+	c.Request.SetRequestURI("/foo/bar?a=query")
+	c.Request.Header.Set("a", "header")
+
+	var req Test
+	err := c.Bind(&req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "query", req.A)
+
+	c.SetBindConfig(&binding.BindConfig{Precedence: []binding.Source{binding.SourceHeader}})
+	req = Test{}
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "header", req.A)
+
+	// the copy binds with the config of the original
+	req = Test{}
+	err = c.Copy().Bind(&req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "header", req.A)
+
+	c.ResetWithoutConn()
+	assert.Nil(t, c.bindConfig)
+}
+
+func TestShouldBind(t *testing.T) {
+	type Test struct {
+		ID   string `path:"id" query:"id" header:"id"`
+		Name string `json:"name" form:"name" vd:"len($)<10"`
+	}
+
+	c := NewContext(0)
+	c.Params = param.Params{{Key: "id", Value: "path"}}
+	c.Request.SetRequestURI("/foo?id=query")
+	c.Request.Header.Set("id", "header")
+	c.Request.SetBodyString(`{"name":"hertz"}`)
+
+	var req Test
+	assert.Nil(t, c.ShouldBindQuery(&req))
+	assert.DeepEqual(t, Test{ID: "query"}, req)
+
+	req = Test{}
+	assert.Nil(t, c.ShouldBindHeader(&req))
+	assert.DeepEqual(t, Test{ID: "header"}, req)
+
+	req = Test{}
+	assert.Nil(t, c.ShouldBindPath(&req))
+	assert.DeepEqual(t, Test{ID: "path"}, req)
+
+	// the json body is bound without Content-Type
+	req = Test{}
+	assert.Nil(t, c.ShouldBindJSON(&req))
+	assert.DeepEqual(t, Test{Name: "hertz"}, req)
+
+	c.Request.SetBodyString(`{"name":"hertz-hertz"}`)
+	req = Test{}
+	assert.NotNil(t, c.ShouldBindJSON(&req))
+
+	c.Request.SetBodyString(`{"name":`)
+	assert.NotNil(t, c.ShouldBindJSON(&req))
+
+	c.Request.SetFormDataFromValues(url.Values{"name": {"form"}})
+	req = Test{}
+	assert.Nil(t, c.ShouldBindForm(&req))
+	assert.DeepEqual(t, Test{Name: "form"}, req)
+
+	// the response is never touched
+	assert.DeepEqual(t, consts.StatusOK, c.Response.StatusCode())
+	assert.DeepEqual(t, 0, len(c.Response.Body()))
+}
+
+func TestInterceptBody(t *testing.T) {
+	c := NewContext(0)
+	c.String(consts.StatusOK, "hello")
+	err := c.InterceptBody(func(body []byte) ([]byte, error) {
+		return append(body, " world"...), nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello world", string(c.Response.Body()))
+
+	c.SetBodyStream(strings.NewReader("stream"), -1)
+	var captured string
+	err = c.InterceptBody(func(body []byte) ([]byte, error) {
+		captured = string(body)
+		return []byte("replaced"), nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "stream", captured)
+	assert.False(t, c.Response.IsBodyStream())
+	assert.DeepEqual(t, "replaced", string(c.Response.Body()))
+
+	err = c.InterceptBody(func(body []byte) ([]byte, error) {
+		return nil, errors.New("intercept error")
+	})
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "replaced", string(c.Response.Body()))
+}
+
 func TestRequestContext_SetCookie(t *testing.T) {
 	c := NewContext(0)
 	c.SetCookie("user", "hertz", 1, "/", "localhost", protocol.CookieSameSiteLaxMode, true, true)
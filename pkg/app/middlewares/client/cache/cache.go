@@ -0,0 +1,394 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cache provides the client middleware caching responses
+// as a private cache described in RFC 7234.
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/json"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// heuristicStatus are the status codes which can be cached without explicit freshness,
+// see RFC 7231, section 6.1.
+var heuristicStatus = map[int]bool{
+	consts.StatusOK:                   true,
+	consts.StatusNonAuthoritativeInfo: true,
+	consts.StatusNoContent:            true,
+	consts.StatusMultipleChoices:      true,
+	consts.StatusMovedPermanently:     true,
+	consts.StatusNotFound:             true,
+	consts.StatusMethodNotAllowed:     true,
+	consts.StatusGone:                 true,
+	consts.StatusRequestURITooLong:    true,
+	consts.StatusNotImplemented:       true,
+}
+
+// Cache creates the middleware caching the responses of GET requests, e.g.
+//
+//	c, _ := client.NewClient()
+//	c.Use(cache.Cache(cache.WithStorage(cache.NewMemoryStorage(100))))
+//
+// The fresh responses are served from the cache according to Cache-Control and Expires,
+// the stale ones are revalidated by the conditional requests with If-None-Match
+// and If-Modified-Since if they have ETag or Last-Modified.
+// The cached responses are invalidated by the successful unsafe requests, e.g. POST, to the same key.
+//
+// NOTE:
+//
+//	The responses with body stream are not cached.
+func Cache(opts ...Option) client.Middleware {
+	c := &cache{options: newOptions(opts)}
+	return func(next client.Endpoint) client.Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			return c.do(ctx, req, resp, next)
+		}
+	}
+}
+
+type cache struct {
+	options *Options
+}
+
+func (c *cache) do(ctx context.Context, req *protocol.Request, resp *protocol.Response, next client.Endpoint) error {
+	method := string(req.Header.Method())
+	if method != consts.MethodGet {
+		err := next(ctx, req, resp)
+		if err == nil && isUnsafe(method) && resp.StatusCode() < consts.StatusBadRequest {
+			c.delete(c.options.KeyFunc(req))
+		}
+		return err
+	}
+
+	reqCC := parseCacheControl(req.Header.Peek(consts.HeaderCacheControl))
+	if _, ok := reqCC["no-store"]; ok {
+		return next(ctx, req, resp)
+	}
+
+	key := c.options.KeyFunc(req)
+	e := c.load(key, req)
+	var restore func()
+	if e != nil {
+		if e.fresh(reqCC, string(req.Header.Peek(consts.HeaderPragma)), time.Now()) {
+			e.writeTo(resp, time.Now())
+			return nil
+		}
+		if restore = e.setConditions(req); restore == nil {
+			e = nil
+		}
+	}
+
+	err := next(ctx, req, resp)
+	if restore != nil {
+		restore()
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if e != nil && resp.StatusCode() == consts.StatusNotModified {
+		e.update(&resp.Header, now)
+		c.store(key, e)
+		e.writeTo(resp, now)
+		return nil
+	}
+	if ne := c.newEntry(req, resp, now); ne != nil {
+		c.store(key, ne)
+	}
+	return nil
+}
+
+func isUnsafe(method string) bool {
+	switch method {
+	case consts.MethodGet, consts.MethodHead, consts.MethodOptions, consts.MethodTrace:
+		return false
+	}
+	return true
+}
+
+func (c *cache) load(key string, req *protocol.Request) *entry {
+	b, ok := c.options.Storage.Get(key)
+	if !ok {
+		return nil
+	}
+	e := &entry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		hlog.SystemLogger().Warnf("Decode cached response of %s failed: %s", key, err.Error())
+		return nil
+	}
+	for name, value := range e.Vary {
+		if string(req.Header.Peek(name)) != value {
+			return nil
+		}
+	}
+	return e
+}
+
+func (c *cache) store(key string, e *entry) {
+	b, err := json.Marshal(e)
+	if err == nil {
+		err = c.options.Storage.Set(key, b)
+	}
+	if err != nil {
+		hlog.SystemLogger().Warnf("Store cached response of %s failed: %s", key, err.Error())
+	}
+}
+
+func (c *cache) delete(key string) {
+	if err := c.options.Storage.Delete(key); err != nil {
+		hlog.SystemLogger().Warnf("Delete cached response of %s failed: %s", key, err.Error())
+	}
+}
+
+// newEntry returns the entry of resp, or nil if resp can not be cached.
+func (c *cache) newEntry(req *protocol.Request, resp *protocol.Response, now time.Time) *entry {
+	if resp.IsBodyStream() {
+		return nil
+	}
+	body := resp.Body()
+	if c.options.MaxBodySize > 0 && len(body) > c.options.MaxBodySize {
+		return nil
+	}
+	e := &entry{
+		StatusCode:   resp.StatusCode(),
+		Body:         append([]byte(nil), body...),
+		ResponseTime: now,
+	}
+	resp.Header.VisitAll(func(key, value []byte) {
+		e.Header = append(e.Header, [2]string{string(key), string(value)})
+	})
+
+	cc := parseCacheControl([]byte(e.header(consts.HeaderCacheControl)))
+	if _, ok := cc["no-store"]; ok {
+		return nil
+	}
+	_, hasMaxAge := cc["max-age"]
+	explicit := hasMaxAge || e.header(consts.HeaderExpires) != ""
+	validators := e.header(consts.HeaderETag) != "" || e.header(consts.HeaderLastModified) != ""
+	if !explicit && !(validators && heuristicStatus[e.StatusCode]) {
+		return nil
+	}
+
+	for _, v := range e.values(consts.HeaderVary) {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name == "" {
+				continue
+			}
+			if e.Vary == nil {
+				e.Vary = make(map[string]string)
+			}
+			e.Vary[name] = string(req.Header.Peek(name))
+		}
+	}
+	return e
+}
+
+// entry is the cached response.
+type entry struct {
+	StatusCode   int               `json:"status_code"`
+	Header       [][2]string       `json:"header"`
+	Body         []byte            `json:"body"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	ResponseTime time.Time         `json:"response_time"`
+}
+
+func (e *entry) values(name string) []string {
+	var values []string
+	for _, kv := range e.Header {
+		if strings.EqualFold(kv[0], name) {
+			values = append(values, kv[1])
+		}
+	}
+	return values
+}
+
+func (e *entry) header(name string) string {
+	return strings.Join(e.values(name), ", ")
+}
+
+// age returns the current age of the response, see RFC 7234, section 4.2.3.
+func (e *entry) age(now time.Time) time.Duration {
+	age := now.Sub(e.ResponseTime)
+	if n, err := strconv.Atoi(e.header(consts.HeaderAge)); err == nil && n > 0 {
+		age += time.Duration(n) * time.Second
+	}
+	return age
+}
+
+// lifetime returns the freshness lifetime of the response, see RFC 7234, section 4.2.1.
+func (e *entry) lifetime(cc map[string]string) time.Duration {
+	if v, ok := cc["max-age"]; ok {
+		n, _ := strconv.Atoi(v)
+		return time.Duration(n) * time.Second
+	}
+	date := e.ResponseTime
+	if t, err := bytesconv.ParseHTTPDate([]byte(e.header(consts.HeaderDate))); err == nil {
+		date = t
+	}
+	if v := e.header(consts.HeaderExpires); v != "" {
+		t, err := bytesconv.ParseHTTPDate([]byte(v))
+		if err != nil {
+			return 0
+		}
+		return t.Sub(date)
+	}
+	// heuristic freshness, see RFC 7234, section 4.2.2
+	if t, err := bytesconv.ParseHTTPDate([]byte(e.header(consts.HeaderLastModified))); err == nil && heuristicStatus[e.StatusCode] {
+		return date.Sub(t) / 10
+	}
+	return 0
+}
+
+// fresh reports whether the response can be served without revalidation.
+func (e *entry) fresh(reqCC map[string]string, pragma string, now time.Time) bool {
+	cc := parseCacheControl([]byte(e.header(consts.HeaderCacheControl)))
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	if len(reqCC) == 0 && strings.Contains(strings.ToLower(pragma), "no-cache") {
+		return false
+	}
+
+	lifetime, age := e.lifetime(cc), e.age(now)
+	if v, ok := reqCC["max-age"]; ok {
+		if n, err := strconv.Atoi(v); err == nil && time.Duration(n)*time.Second < lifetime {
+			lifetime = time.Duration(n) * time.Second
+		}
+	}
+	if v, ok := reqCC["min-fresh"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			age += time.Duration(n) * time.Second
+		}
+	}
+	if age < lifetime {
+		return true
+	}
+	_, mustRevalidate := cc["must-revalidate"]
+	if v, ok := reqCC["max-stale"]; ok && !mustRevalidate {
+		if v == "" {
+			return true
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			return age < lifetime+time.Duration(n)*time.Second
+		}
+	}
+	return false
+}
+
+// setConditions makes req a conditional request with the validators of the response,
+// and returns the function restoring the headers of req, or nil if there are no validators.
+func (e *entry) setConditions(req *protocol.Request) func() {
+	etag, lastModified := e.header(consts.HeaderETag), e.header(consts.HeaderLastModified)
+	if etag == "" && lastModified == "" {
+		return nil
+	}
+	oldETag := string(req.Header.Peek(consts.HeaderIfNoneMatch))
+	oldLastModified := string(req.Header.Peek(consts.HeaderIfModifiedSince))
+	if etag != "" {
+		req.Header.Set(consts.HeaderIfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		req.Header.Set(consts.HeaderIfModifiedSince, lastModified)
+	}
+	return func() {
+		restoreHeader(req, consts.HeaderIfNoneMatch, oldETag)
+		restoreHeader(req, consts.HeaderIfModifiedSince, oldLastModified)
+	}
+}
+
+func restoreHeader(req *protocol.Request, key, value string) {
+	if value == "" {
+		req.Header.DelBytes([]byte(key))
+		return
+	}
+	req.Header.Set(key, value)
+}
+
+// update replaces the headers of the response with the ones of the 304 response,
+// see RFC 7234, section 4.3.4.
+func (e *entry) update(h *protocol.ResponseHeader, now time.Time) {
+	var updated [][2]string
+	names := make(map[string]bool)
+	h.VisitAll(func(key, value []byte) {
+		if k := string(key); !skipHeader(k) {
+			updated = append(updated, [2]string{k, string(value)})
+			names[strings.ToLower(k)] = true
+		}
+	})
+	header := make([][2]string, 0, len(e.Header)+len(updated))
+	for _, kv := range e.Header {
+		if !names[strings.ToLower(kv[0])] {
+			header = append(header, kv)
+		}
+	}
+	e.Header = append(header, updated...)
+	e.ResponseTime = now
+}
+
+// writeTo sets resp to the cached response with the Age header.
+func (e *entry) writeTo(resp *protocol.Response, now time.Time) {
+	resp.Reset()
+	resp.SetStatusCode(e.StatusCode)
+	for _, kv := range e.Header {
+		if !skipHeader(kv[0]) && !strings.EqualFold(kv[0], consts.HeaderAge) {
+			resp.Header.Add(kv[0], kv[1])
+		}
+	}
+	resp.Header.Set(consts.HeaderAge, strconv.Itoa(int(e.age(now)/time.Second)))
+	resp.SetBody(e.Body)
+}
+
+func skipHeader(key string) bool {
+	return strings.EqualFold(key, consts.HeaderContentLength) ||
+		strings.EqualFold(key, consts.HeaderConnection) ||
+		strings.EqualFold(key, consts.HeaderTransferEncoding)
+}
+
+// parseCacheControl parses the directives of Cache-Control header into a map,
+// the directives without value are mapped to "".
+func parseCacheControl(v []byte) map[string]string {
+	cc := make(map[string]string)
+	for _, directive := range strings.Split(string(v), ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+		name, value := directive, ""
+		if i := strings.IndexByte(directive, '='); i >= 0 {
+			name, value = directive[:i], strings.Trim(strings.TrimSpace(directive[i+1:]), `"`)
+		}
+		cc[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return cc
+}
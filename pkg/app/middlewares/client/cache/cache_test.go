@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type upstream struct {
+	calls   int
+	handler func(req *protocol.Request, resp *protocol.Response)
+}
+
+func (u *upstream) endpoint(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	u.calls++
+	resp.Reset()
+	u.handler(req, resp)
+	return nil
+}
+
+func doGet(t *testing.T, ep client.Endpoint, uri string, headers ...string) *protocol.Response {
+	req, resp := protocol.AcquireRequest(), &protocol.Response{}
+	defer protocol.ReleaseRequest(req)
+	req.SetRequestURI(uri)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	assert.Nil(t, ep(context.Background(), req, resp))
+	return resp
+}
+
+func TestCacheFresh(t *testing.T) {
+	u := &upstream{handler: func(req *protocol.Request, resp *protocol.Response) {
+		resp.Header.Set(consts.HeaderCacheControl, "max-age=60")
+		resp.SetBodyString("hello")
+	}}
+	ep := Cache()(u.endpoint)
+
+	resp := doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, "hello", string(resp.Body()))
+	resp = doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, "hello", string(resp.Body()))
+	assert.DeepEqual(t, "0", string(resp.Header.Peek(consts.HeaderAge)))
+	assert.DeepEqual(t, 1, u.calls)
+
+	// the other URI is not cached
+	doGet(t, ep, "http://example.com/bar")
+	assert.DeepEqual(t, 2, u.calls)
+
+	// the request asks for revalidation, but there are no validators
+	doGet(t, ep, "http://example.com/foo", consts.HeaderCacheControl, "no-cache")
+	assert.DeepEqual(t, 3, u.calls)
+
+	// the unsafe request invalidates the cached response
+	req, resp := protocol.AcquireRequest(), &protocol.Response{}
+	req.SetRequestURI("http://example.com/foo")
+	req.Header.SetMethod(consts.MethodPost)
+	assert.Nil(t, ep(context.Background(), req, resp))
+	assert.DeepEqual(t, 4, u.calls)
+	doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, 5, u.calls)
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	var ifNoneMatch string
+	u := &upstream{handler: func(req *protocol.Request, resp *protocol.Response) {
+		ifNoneMatch = string(req.Header.Peek(consts.HeaderIfNoneMatch))
+		resp.Header.Set(consts.HeaderCacheControl, "no-cache")
+		resp.Header.Set(consts.HeaderETag, `"v1"`)
+		if ifNoneMatch == `"v1"` {
+			resp.Header.Set("X-Revalidated", "true")
+			resp.SetStatusCode(consts.StatusNotModified)
+			return
+		}
+		resp.SetBodyString("hello")
+	}}
+	ep := Cache()(u.endpoint)
+
+	resp := doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, "hello", string(resp.Body()))
+	assert.DeepEqual(t, "", ifNoneMatch)
+
+	req, resp := protocol.AcquireRequest(), &protocol.Response{}
+	req.SetRequestURI("http://example.com/foo")
+	assert.Nil(t, ep(context.Background(), req, resp))
+	assert.DeepEqual(t, `"v1"`, ifNoneMatch)
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "hello", string(resp.Body()))
+	assert.DeepEqual(t, "true", string(resp.Header.Peek("X-Revalidated")))
+	assert.DeepEqual(t, 0, len(req.Header.Peek(consts.HeaderIfNoneMatch)))
+	assert.DeepEqual(t, 2, u.calls)
+}
+
+func TestCacheNotStored(t *testing.T) {
+	cacheControl := "no-store"
+	u := &upstream{handler: func(req *protocol.Request, resp *protocol.Response) {
+		resp.Header.Set(consts.HeaderCacheControl, cacheControl)
+		resp.Header.Set(consts.HeaderVary, "Accept")
+		resp.SetBodyString("hello")
+	}}
+	ep := Cache(WithMaxBodySize(3))(u.endpoint)
+	doGet(t, ep, "http://example.com/foo")
+	doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, 2, u.calls)
+
+	// the body is too large
+	cacheControl = "max-age=60"
+	doGet(t, ep, "http://example.com/foo")
+	doGet(t, ep, "http://example.com/foo")
+	assert.DeepEqual(t, 4, u.calls)
+
+	ep = Cache()(u.endpoint)
+	doGet(t, ep, "http://example.com/foo", "Accept", "text/plain")
+	doGet(t, ep, "http://example.com/foo", "Accept", "text/plain")
+	assert.DeepEqual(t, 5, u.calls)
+	// the response varies by Accept
+	doGet(t, ep, "http://example.com/foo", "Accept", "text/html")
+	assert.DeepEqual(t, 6, u.calls)
+}
+
+func TestParseCacheControl(t *testing.T) {
+	cc := parseCacheControl([]byte(`Max-Age=60, no-cache, private="Set-Cookie"`))
+	assert.DeepEqual(t, map[string]string{"max-age": "60", "no-cache": "", "private": "Set-Cookie"}, cc)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+const defaultMaxEntries = 1024
+
+// Options are the options of the cache middleware.
+type Options struct {
+	// Storage stores the cached responses, the memory storage
+	// holding up to 1024 responses is used by default.
+	Storage Storage
+
+	// KeyFunc returns the key of the cached response for the request,
+	// the URI of the request is used by default.
+	// NOTE:
+	//
+	//	The method of the request should not be a part of the key,
+	//	since the responses are invalidated by the unsafe requests to the same key.
+	KeyFunc func(req *protocol.Request) string
+
+	// MaxBodySize is the max body size of the responses to be cached.
+	// If MaxBodySize<=0, the size is unlimited.
+	MaxBodySize int
+}
+
+// Option is the only struct that can be used to set Options.
+type Option struct {
+	F func(o *Options)
+}
+
+func (o *Options) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	options := &Options{
+		Storage: NewMemoryStorage(defaultMaxEntries),
+		KeyFunc: defaultKey,
+	}
+	options.Apply(opts)
+	return options
+}
+
+func defaultKey(req *protocol.Request) string {
+	return req.URI().String()
+}
+
+// WithStorage sets the storage of the cached responses.
+func WithStorage(s Storage) Option {
+	return Option{F: func(o *Options) {
+		o.Storage = s
+	}}
+}
+
+// WithKeyFunc sets the function returning the key of the cached response for the request.
+func WithKeyFunc(f func(req *protocol.Request) string) Option {
+	return Option{F: func(o *Options) {
+		o.KeyFunc = f
+	}}
+}
+
+// WithMaxBodySize sets the max body size of the responses to be cached.
+func WithMaxBodySize(n int) Option {
+	return Option{F: func(o *Options) {
+		o.MaxBodySize = n
+	}}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Storage stores the encoded cache entries by keys.
+type Storage interface {
+	// Get returns the value of key, or false if it is not found.
+	Get(key string) ([]byte, bool)
+	// Set stores value by key.
+	Set(key string, value []byte) error
+	// Delete removes the value of key.
+	Delete(key string) error
+}
+
+type memoryItem struct {
+	key   string
+	value []byte
+}
+
+type memoryStorage struct {
+	sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// NewMemoryStorage creates the Storage keeping the entries in memory,
+// the least recently used entry is evicted if there are more than maxEntries entries.
+//
+// NOTE:
+//
+//	If maxEntries<=0, the number of entries is unlimited.
+func NewMemoryStorage(maxEntries int) Storage {
+	return &memoryStorage{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryStorage) Get(key string) ([]byte, bool) {
+	s.Lock()
+	defer s.Unlock()
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	s.ll.MoveToFront(e)
+	return e.Value.(*memoryItem).value, true
+}
+
+func (s *memoryStorage) Set(key string, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.ll.MoveToFront(e)
+		e.Value.(*memoryItem).value = value
+		return nil
+	}
+	s.items[key] = s.ll.PushFront(&memoryItem{key: key, value: value})
+	if s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		e := s.ll.Back()
+		s.ll.Remove(e)
+		delete(s.items, e.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.ll.Remove(e)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+type diskStorage struct {
+	dir string
+}
+
+// NewDiskStorage creates the Storage keeping the entries in the files under dir,
+// which is created if it does not exist.
+func NewDiskStorage(dir string) (Storage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &diskStorage{dir: dir}, nil
+}
+
+func (s *diskStorage) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+func (s *diskStorage) Get(key string) ([]byte, bool) {
+	value, err := ioutil.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (s *diskStorage) Set(key string, value []byte) error {
+	f, err := ioutil.TempFile(s.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	// rename the complete file, so that the readers never see a partial one
+	return os.Rename(f.Name(), s.path(key))
+}
+
+func (s *diskStorage) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage(2)
+	assert.Nil(t, s.Set("a", []byte("1")))
+	assert.Nil(t, s.Set("b", []byte("2")))
+	_, ok := s.Get("a")
+	assert.True(t, ok)
+	// b is the least recently used one
+	assert.Nil(t, s.Set("c", []byte("3")))
+	_, ok = s.Get("b")
+	assert.False(t, ok)
+	v, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.DeepEqual(t, "1", string(v))
+
+	assert.Nil(t, s.Delete("a"))
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+}
+
+func TestDiskStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewDiskStorage(dir)
+	assert.Nil(t, err)
+	_, ok := s.Get("http://example.com/foo")
+	assert.False(t, ok)
+	assert.Nil(t, s.Set("http://example.com/foo", []byte("hello")))
+	v, ok := s.Get("http://example.com/foo")
+	assert.True(t, ok)
+	assert.DeepEqual(t, "hello", string(v))
+
+	assert.Nil(t, s.Delete("http://example.com/foo"))
+	assert.Nil(t, s.Delete("http://example.com/foo"))
+	_, ok = s.Get("http://example.com/foo")
+	assert.False(t, ok)
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package hedge provides the client middleware sending hedged requests to tame the tail latency.
+package hedge
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Hedge returns a middleware which sends a hedged attempt of the request
+// if no response is received within delay, or once an attempt fails.
+// The first successful response of the attempts is taken, and the others are canceled.
+//
+// NOTE:
+//
+//	Put it before the Discovery middleware, so that the attempts are sent to different instances.
+//	The canceled attempts are canceled by their context and discarded,
+//	the endpoints ignoring the context finish them in the background.
+//	If delay<=0, the requests are not hedged.
+func Hedge(delay time.Duration, opts ...Option) client.Middleware {
+	options := newOptions(opts)
+	return func(next client.Endpoint) client.Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			if delay <= 0 || options.MaxHedges <= 0 || !options.HedgeIf(req) {
+				return next(ctx, req, resp)
+			}
+			return hedge(ctx, req, resp, next, delay, options.MaxHedges)
+		}
+	}
+}
+
+type attempt struct {
+	req  *protocol.Request
+	resp *protocol.Response
+	err  error
+}
+
+func (a *attempt) release() {
+	protocol.ReleaseRequest(a.req)
+	protocol.ReleaseResponse(a.resp)
+}
+
+func hedge(ctx context.Context, req *protocol.Request, resp *protocol.Response, next client.Endpoint, delay time.Duration, maxHedges int) error {
+	ctx, cancel := context.WithCancel(discovery.WithDistinctInstances(ctx))
+	defer cancel()
+
+	// the attempts work on the copies, since the losers may still be running after return
+	results := make(chan *attempt, maxHedges+1)
+	send := func() {
+		a := &attempt{req: protocol.AcquireRequest(), resp: protocol.AcquireResponse()}
+		req.CopyTo(a.req)
+		if resp != nil {
+			a.resp.SkipBody = resp.SkipBody
+		}
+		go func() {
+			a.err = next(ctx, a.req, a.resp)
+			results <- a
+		}()
+	}
+	// the losers release their copies once they are finished
+	drain := func(pending int) {
+		go func() {
+			for ; pending > 0; pending-- {
+				(<-results).release()
+			}
+		}()
+	}
+
+	send()
+	sent, pending := 1, 1
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	hedgeNow := func() {
+		send()
+		sent++
+		pending++
+		if !t.Stop() {
+			select {
+			case <-t.C:
+			default:
+			}
+		}
+		t.Reset(delay)
+	}
+
+	var err error
+	for pending > 0 {
+		var timeout <-chan time.Time
+		if sent <= maxHedges {
+			timeout = t.C
+		}
+		select {
+		case a := <-results:
+			pending--
+			if a.err == nil {
+				if resp != nil {
+					a.resp.CopyToSkipBody(resp)
+					protocol.SwapResponseBody(resp, a.resp)
+				}
+				a.release()
+				drain(pending)
+				return nil
+			}
+			err = a.err
+			a.release()
+			if sent <= maxHedges {
+				hedgeNow()
+			}
+		case <-timeout:
+			hedgeNow()
+		case <-ctx.Done():
+			drain(pending)
+			return ctx.Err()
+		}
+	}
+	return err
+}
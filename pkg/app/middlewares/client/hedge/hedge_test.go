@@ -0,0 +1,108 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// newEndpoint returns the endpoint responding the n-th attempt with the body "n" after delays[n],
+// or failing if the delay is negative.
+func newEndpoint(calls *int32, delays ...time.Duration) func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+		n := atomic.AddInt32(calls, 1) - 1
+		delay := delays[n]
+		if delay < 0 {
+			return errors.New("failed")
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		resp.SetBodyString(string(rune('0' + n)))
+		return nil
+	}
+}
+
+func TestHedge(t *testing.T) {
+	var calls int32
+	next := Hedge(50 * time.Millisecond)(newEndpoint(&calls, time.Second, 0))
+
+	req := protocol.NewRequest("GET", "http://example.com/", nil)
+	resp := protocol.AcquireResponse()
+	start := time.Now()
+	err := next(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "1", string(resp.Body()))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.DeepEqual(t, int32(2), atomic.LoadInt32(&calls))
+
+	// the first attempt responding in time is not hedged
+	calls = 0
+	next = Hedge(time.Second)(newEndpoint(&calls, 0))
+	err = next(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "0", string(resp.Body()))
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHedgeFailure(t *testing.T) {
+	var calls int32
+	next := Hedge(time.Second, WithMaxHedges(2))(newEndpoint(&calls, -1, -1, 0))
+
+	req := protocol.NewRequest("GET", "http://example.com/", nil)
+	resp := protocol.AcquireResponse()
+	start := time.Now()
+	err := next(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "2", string(resp.Body()))
+	assert.True(t, time.Since(start) < time.Second)
+
+	calls = 0
+	next = Hedge(time.Second)(newEndpoint(&calls, -1, -1))
+	err = next(context.Background(), req, resp)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestHedgeSkipped(t *testing.T) {
+	var calls int32
+	next := Hedge(10 * time.Millisecond)(newEndpoint(&calls, 100*time.Millisecond))
+
+	// the non-idempotent requests are not hedged
+	req := protocol.NewRequest("POST", "http://example.com/", nil)
+	resp := protocol.AcquireResponse()
+	err := next(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&calls))
+
+	calls = 0
+	next = Hedge(10*time.Millisecond, WithHedgeIf(func(req *protocol.Request) bool {
+		return true
+	}))(newEndpoint(&calls, 100*time.Millisecond, 0))
+	err = next(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "1", string(resp.Body()))
+}
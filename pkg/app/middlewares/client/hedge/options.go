@@ -0,0 +1,81 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hedge
+
+import (
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Options are the options of the hedge middleware.
+type Options struct {
+	// MaxHedges is the max number of the hedged attempts sent besides the first one,
+	// 1 by default.
+	MaxHedges int
+
+	// HedgeIf reports whether the request can be hedged, the idempotent requests
+	// without body stream are hedged by default.
+	HedgeIf func(req *protocol.Request) bool
+}
+
+// Option is the only struct that can be used to set Options.
+type Option struct {
+	F func(o *Options)
+}
+
+func (o *Options) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	options := &Options{
+		MaxHedges: 1,
+		HedgeIf:   defaultHedgeIf,
+	}
+	options.Apply(opts)
+	return options
+}
+
+func defaultHedgeIf(req *protocol.Request) bool {
+	if req.IsBodyStream() {
+		return false
+	}
+	return req.Header.IsGet() ||
+		req.Header.IsHead() ||
+		req.Header.IsPut() ||
+		req.Header.IsDelete() ||
+		req.Header.IsOptions() ||
+		req.Header.IsTrace()
+}
+
+// WithMaxHedges sets the max number of the hedged attempts sent besides the first one.
+func WithMaxHedges(n int) Option {
+	return Option{F: func(o *Options) {
+		o.MaxHedges = n
+	}}
+}
+
+// WithHedgeIf sets the function reporting whether the request can be hedged.
+// NOTE:
+//
+//	The request hedged must be idempotent, since it may be handled by more than one backend.
+func WithHedgeIf(f func(req *protocol.Request) bool) Option {
+	return Option{F: func(o *Options) {
+		o.HedgeIf = f
+	}}
+}
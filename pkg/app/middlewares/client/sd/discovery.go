@@ -44,13 +44,38 @@ func Discovery(resolver discovery.Resolver, opts ...ServiceDiscoveryOption) clie
 	return func(next client.Endpoint) client.Endpoint {
 		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) (err error) {
 			if req.Options() != nil && req.Options().IsSD() {
-				ins, err := f.GetInstance(ctx, req)
+				ins, err := pickInstance(ctx, f, req)
 				if err != nil {
 					return err
 				}
+				defer f.Done(ins)
 				req.SetHost(ins.Address().String())
 			}
 			return next(ctx, req, resp)
 		}
 	}
 }
+
+// maxDistinctPicks is the max times of picking an instance for a request,
+// which are spent on avoiding the picked instances.
+const maxDistinctPicks = 8
+
+// pickInstance picks an instance for req, the instances which have been picked
+// for the requests sent with the same context are avoided if the context
+// is returned by discovery.WithDistinctInstances.
+func pickInstance(ctx context.Context, f *loadbalance.BalancerFactory, req *protocol.Request) (discovery.Instance, error) {
+	distinct := discovery.ContextDistinctInstances(ctx)
+	if distinct == nil {
+		return f.GetInstance(ctx, req)
+	}
+	for i := 1; ; i++ {
+		ins, err := f.GetInstance(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if distinct.Add(ins.Address().String()) || i >= maxDistinctPicks {
+			return ins, nil
+		}
+		f.Done(ins)
+	}
+}
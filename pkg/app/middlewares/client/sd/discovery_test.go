@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/app/client/discovery"
+	"github.com/cloudwego/hertz/pkg/app/client/loadbalance"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/protocol"
@@ -55,3 +56,100 @@ func TestDiscovery(t *testing.T) {
 		_ = mw(checkMdw)(context.Background(), req, resp)
 	}
 }
+
+func TestDiscoveryLeastConn(t *testing.T) {
+	inss := []discovery.Instance{
+		discovery.NewInstance("tcp", "127.0.0.1:8888", 10, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8889", 10, nil),
+	}
+	r := &discovery.SynthesizedResolver{
+		TargetFunc: func(ctx context.Context, target *discovery.TargetInfo) string {
+			return target.Host
+		},
+		ResolveFunc: func(ctx context.Context, key string) (discovery.Result, error) {
+			return discovery.Result{CacheKey: "svc1", Instances: inss}, nil
+		},
+		NameFunc: func() string { return t.Name() },
+	}
+
+	mw := Discovery(r, WithLoadBalanceOptions(loadbalance.NewLeastConnBalancer(), loadbalance.DefaultLbOpts))
+	newRequest := func() *protocol.Request {
+		req := &protocol.Request{}
+		req.Options().Apply([]config.RequestOption{config.WithSD(true)})
+		req.SetRequestURI("http://service_name")
+		return req
+	}
+	// the request to the other instance is in flight when the inner one is sent
+	var hosts []string
+	inner := func(ctx context.Context, req *protocol.Request, resp *protocol.Response) (err error) {
+		hosts = append(hosts, string(req.Host()))
+		return nil
+	}
+	outer := func(ctx context.Context, req *protocol.Request, resp *protocol.Response) (err error) {
+		hosts = append(hosts, string(req.Host()))
+		return mw(inner)(ctx, newRequest(), resp)
+	}
+	for i := 0; i < 10; i++ {
+		hosts = hosts[:0]
+		_ = mw(outer)(context.Background(), newRequest(), &protocol.Response{})
+		assert.DeepEqual(t, 2, len(hosts))
+		assert.Assert(t, hosts[0] != hosts[1])
+	}
+}
+
+type pairBalancer struct {
+	n int
+}
+
+// Pick picks every instance twice in turn.
+func (b *pairBalancer) Pick(res discovery.Result) discovery.Instance {
+	ins := res.Instances[(b.n/2)%len(res.Instances)]
+	b.n++
+	return ins
+}
+
+func (b *pairBalancer) Rebalance(discovery.Result) {}
+
+func (b *pairBalancer) Delete(string) {}
+
+func (b *pairBalancer) Name() string { return "pair" }
+
+func TestDiscoveryDistinctInstances(t *testing.T) {
+	inss := []discovery.Instance{
+		discovery.NewInstance("tcp", "127.0.0.1:8888", 10, nil),
+		discovery.NewInstance("tcp", "127.0.0.1:8889", 10, nil),
+	}
+	r := &discovery.SynthesizedResolver{
+		TargetFunc: func(ctx context.Context, target *discovery.TargetInfo) string {
+			return target.Host
+		},
+		ResolveFunc: func(ctx context.Context, key string) (discovery.Result, error) {
+			return discovery.Result{CacheKey: "svc1", Instances: inss}, nil
+		},
+		NameFunc: func() string { return t.Name() },
+	}
+
+	var hosts []string
+	mw := Discovery(r, WithLoadBalanceOptions(&pairBalancer{}, loadbalance.DefaultLbOpts))
+	next := mw(func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+		hosts = append(hosts, string(req.Host()))
+		return nil
+	})
+	send := func(ctx context.Context) {
+		req := &protocol.Request{}
+		req.Options().Apply([]config.RequestOption{config.WithSD(true)})
+		req.SetRequestURI("http://service_name")
+		assert.Nil(t, next(ctx, req, &protocol.Response{}))
+	}
+
+	// the picked instances are not avoided without the distinct context
+	send(context.Background())
+	send(context.Background())
+	assert.DeepEqual(t, []string{"127.0.0.1:8888", "127.0.0.1:8888"}, hosts)
+
+	hosts = nil
+	ctx := discovery.WithDistinctInstances(context.Background())
+	send(ctx)
+	send(ctx)
+	assert.DeepEqual(t, []string{"127.0.0.1:8889", "127.0.0.1:8888"}, hosts)
+}
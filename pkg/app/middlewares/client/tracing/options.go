@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Options are the options of the tracing middleware.
+type Options struct {
+	// SpanNameFormatter names the client spans, which returns the method by default.
+	SpanNameFormatter func(req *protocol.Request) string
+}
+
+// Option is the only struct that can be used to set Options.
+type Option struct {
+	F func(o *Options)
+}
+
+func (o *Options) Apply(opts []Option) {
+	for _, op := range opts {
+		op.F(o)
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	options := &Options{
+		SpanNameFormatter: defaultSpanNameFormatter,
+	}
+	options.Apply(opts)
+	return options
+}
+
+func defaultSpanNameFormatter(req *protocol.Request) string {
+	return string(req.Method())
+}
+
+// WithSpanNameFormatter sets the function naming the client spans.
+func WithSpanNameFormatter(f func(req *protocol.Request) string) Option {
+	return Option{F: func(o *Options) {
+		o.SpanNameFormatter = f
+	}}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tracing provides the client middleware starting the client spans and propagating them
+// by W3C Trace Context, which works with the tracing middleware of the server.
+package tracing
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/tracer/trace"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Tracing returns a middleware starting a client span for each request as the child of the span in ctx,
+// and injecting it into the traceparent and tracestate headers of the request, e.g.
+//
+//	c.Use(tracing.Tracing(tracer))
+//	c.Do(c, req, resp) // c is the context of the server handler
+//
+// The status of the span is set to error for the failed requests and the 4xx and 5xx responses.
+//
+// NOTE:
+//
+//	Put it before the retry and hedge middlewares to get a span for each request,
+//	or after them to get a span for each attempt.
+func Tracing(tracer *trace.Tracer, opts ...Option) client.Middleware {
+	options := newOptions(opts)
+	return func(next client.Endpoint) client.Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			ctx, span := tracer.Start(ctx, options.SpanNameFormatter(req), trace.SpanKindClient,
+				trace.String("http.request.method", string(req.Method())),
+				trace.String("url.full", req.URI().String()),
+				trace.String("server.address", string(req.Host())),
+			)
+			trace.Inject(span.Context, &req.Header)
+
+			err := next(ctx, req, resp)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(trace.StatusError, err.Error())
+				span.End()
+				return err
+			}
+			status := resp.StatusCode()
+			span.SetAttributes(trace.Int("http.response.status_code", status))
+			if status >= 400 {
+				span.SetStatus(trace.StatusError, "")
+			}
+			span.End()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/trace"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestTracing(t *testing.T) {
+	var exported []*trace.Span
+	tracer := trace.NewTracer(trace.ExporterFunc(func(span *trace.Span) {
+		exported = append(exported, span)
+	}))
+
+	var traceparent string
+	status, failure := consts.StatusOK, error(nil)
+	endpoint := Tracing(tracer)(func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+		traceparent = string(req.Header.Peek(trace.HeaderTraceparent))
+		resp.SetStatusCode(status)
+		return failure
+	})
+
+	// the client span is the child of the span in the context
+	ctx, parent := tracer.Start(context.Background(), "/handler", trace.SpanKindServer)
+	req, resp := protocol.NewRequest(consts.MethodGet, "http://example.com/foo?a=b", nil), &protocol.Response{}
+	assert.Nil(t, endpoint(ctx, req, resp))
+	span := exported[0]
+	assert.DeepEqual(t, "GET", span.Name)
+	assert.DeepEqual(t, trace.SpanKindClient, span.Kind)
+	assert.DeepEqual(t, parent.Context.SpanID, span.Parent.SpanID)
+	assert.DeepEqual(t, parent.Context.TraceID, span.Context.TraceID)
+	assert.DeepEqual(t, trace.FormatTraceparent(span.Context), traceparent)
+	assert.DeepEqual(t, trace.StatusUnset, span.Status)
+
+	status = consts.StatusNotFound
+	assert.Nil(t, endpoint(ctx, req, resp))
+	assert.DeepEqual(t, trace.StatusError, exported[1].Status)
+
+	failure = errors.New("dial failed")
+	endpoint = Tracing(tracer, WithSpanNameFormatter(func(req *protocol.Request) string {
+		return "GET " + string(req.Host())
+	}))(func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+		return failure
+	})
+	assert.DeepEqual(t, failure, endpoint(context.Background(), req, resp))
+	span = exported[2]
+	assert.DeepEqual(t, "GET example.com", span.Name)
+	assert.DeepEqual(t, "dial failed", span.StatusMessage)
+	assert.False(t, span.Parent.IsValid())
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// New returns a middleware which writes the access log of requests after they are handled.
+//
+// If the response body is streamed, the access log is written once the body stream is
+// sent, with the latency and the bytes sent of the whole response.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	var format formatter
+	if len(cfg.jsonFields) > 0 {
+		format = compileJSON(cfg.jsonFields)
+	} else {
+		format = compileTemplate(cfg.format)
+	}
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		start := time.Now()
+		var received *countingReader
+		if ctx.Request.IsBodyStream() {
+			received = &countingReader{r: ctx.Request.BodyStream()}
+			ctx.Request.ConstructBodyStream(ctx.Request.BodyBuffer(), received)
+			defer received.restore(ctx)
+		}
+		ctx.Next(c)
+
+		if cfg.excluded(ctx) {
+			return
+		}
+		e := &entry{ctx: ctx, start: start, latency: time.Since(start)}
+		if received != nil {
+			e.bytesReceived = received.n
+		} else {
+			e.bytesReceived = int64(len(ctx.Request.BodyBytes()))
+		}
+		if !ctx.Response.IsBodyStream() {
+			e.bytesSent = int64(len(ctx.Response.BodyBytes()))
+			write(c, cfg, format, e)
+			return
+		}
+		// the body stream is not sent until the handlers return, so the log is
+		// written once the protocol is done with it
+		ctx.Response.OnBodyStreamDone(func(written int64, _ error) {
+			e.latency = time.Since(start)
+			e.bytesSent = written
+			write(c, cfg, format, e)
+		})
+	}
+}
+
+func write(c context.Context, cfg *options, format formatter, e *entry) {
+	buf := bytebufferpool.Get()
+	buf.B = format(buf.B, cfg, e)
+	if _, err := cfg.output.Write(buf.B); err != nil {
+		hlog.SystemLogger().CtxErrorf(c, "Write access log error=%v", err)
+	}
+	bytebufferpool.Put(buf)
+}
+
+// countingReader counts the bytes of the request body stream read by the handlers.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// Close closes the underlying body stream, which happens if the handlers
+// read the whole body by Body().
+func (cr *countingReader) Close() error {
+	if c, ok := cr.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// restore puts the underlying body stream back to the request, so that the
+// protocol is able to release it.
+func (cr *countingReader) restore(ctx *app.RequestContext) {
+	if ctx.Request.BodyStream() != io.Reader(cr) {
+		return
+	}
+	ctx.Request.ConstructBodyStream(ctx.Request.BodyBuffer(), cr.r)
+}
+
+func (o *options) excluded(ctx *app.RequestContext) bool {
+	if len(o.excludeRoutes) == 0 {
+		return false
+	}
+	if _, ok := o.excludeRoutes[ctx.FullPath()]; ok {
+		return true
+	}
+	_, ok := o.excludeRoutes[string(ctx.Path())]
+	return ok
+}
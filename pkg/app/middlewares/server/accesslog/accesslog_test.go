@@ -0,0 +1,200 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestAccessLogTemplate(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithOutput(out),
+		WithFormat("${status} ${method} ${path} ${route} ${query} ${bytesSent} ${requestID} ${value:upstream} ${header:X-Foo} ${respHeader:Content-Type}\n"),
+	))
+	engine.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set("upstream", "10.0.0.1:8888")
+		ctx.Header("X-Request-ID", "resp-id")
+		ctx.String(consts.StatusOK, "hello")
+	})
+
+	ut.PerformRequest(engine, consts.MethodGet, "/user/1?a=b", nil, ut.Header{Key: "X-Foo", Value: "foo"})
+	assert.DeepEqual(t, "200 GET /user/1 /user/:id a=b 5 resp-id 10.0.0.1:8888 foo text/plain; charset=utf-8\n", out.String())
+
+	out.Reset()
+	ut.PerformRequest(engine, consts.MethodGet, "/user/2", nil, ut.Header{Key: "X-Request-ID", Value: "req-id"})
+	assert.True(t, strings.Contains(out.String(), " req-id "))
+
+	out.Reset()
+	ut.PerformRequest(engine, consts.MethodGet, "/not-found", nil)
+	// the route is empty if it is not found
+	assert.True(t, strings.HasPrefix(out.String(), "404 GET /not-found  "))
+}
+
+func TestAccessLogDefaultFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithOutput(out)))
+	engine.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "pong")
+	})
+	ut.PerformRequest(engine, consts.MethodGet, "/ping", nil)
+	assert.True(t, strings.HasPrefix(out.String(), "["))
+	assert.True(t, strings.HasSuffix(out.String(), " GET /ping\n"))
+	assert.True(t, strings.Contains(out.String(), "] 200 - "))
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithOutput(out),
+		WithJSONFields(TagStatus, TagMethod, TagRoute, TagBytesSent, TagLatency, "value:upstream", "header:X-Foo"),
+	))
+	engine.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set("upstream", "10.0.0.1:8888")
+		ctx.Header("X-Request-ID", "resp-id")
+		ctx.String(consts.StatusOK, "hello")
+	})
+	ut.PerformRequest(engine, consts.MethodGet, "/user/1", nil, ut.Header{Key: "X-Foo", Value: "a\"b\n"})
+	assert.True(t, strings.HasSuffix(out.String(), "}\n"))
+
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &m))
+	assert.DeepEqual(t, float64(200), m["status"])
+	assert.DeepEqual(t, "GET", m["method"])
+	assert.DeepEqual(t, "/user/:id", m["route"])
+	assert.DeepEqual(t, float64(5), m["bytesSent"])
+	assert.DeepEqual(t, "10.0.0.1:8888", m["upstream"])
+	assert.DeepEqual(t, "a\"b\n", m["X-Foo"])
+	_, ok := m["latency"].(string)
+	assert.True(t, ok)
+}
+
+func TestAccessLogBodyStream(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithOutput(out), WithFormat("${status} ${bytesSent}\n")))
+	engine.GET("/stream", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetBodyStream(strings.NewReader("hello world"), -1)
+	})
+
+	ctx := engine.NewContext()
+	ctx.Request.SetMethod(consts.MethodGet)
+	ctx.Request.SetRequestURI("/stream")
+	engine.ServeHTTP(context.Background(), ctx)
+	// the log is not written until the body stream is sent
+	assert.DeepEqual(t, "", out.String())
+
+	w := &bytes.Buffer{}
+	zw := network.NewWriter(w)
+	assert.Nil(t, resp.Write(&ctx.Response, zw))
+	assert.Nil(t, zw.Flush())
+	assert.True(t, strings.HasSuffix(w.String(), "hello world\r\n0\r\n\r\n"))
+	assert.DeepEqual(t, "200 11\n", out.String())
+}
+
+func TestAccessLogBytesReceived(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithOutput(out), WithFormat("${bytesReceived}\n")))
+	engine.POST("/body", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Request.Body()
+	})
+	engine.POST("/partial", func(c context.Context, ctx *app.RequestContext) {
+		buf := make([]byte, 5)
+		io.ReadFull(ctx.RequestBodyStream(), buf) //nolint:errcheck
+	})
+
+	ut.PerformRequest(engine, consts.MethodPost, "/body", &ut.Body{Body: strings.NewReader("hello world"), Len: 11})
+	assert.DeepEqual(t, "11\n", out.String())
+
+	// only the bytes read by the handler are counted, regardless of the Content-Length
+	out.Reset()
+	ut.PerformRequest(engine, consts.MethodPost, "/body", &ut.Body{Body: strings.NewReader("hello world"), Len: -1})
+	assert.DeepEqual(t, "11\n", out.String())
+
+	out.Reset()
+	ctx := engine.NewContext()
+	ctx.Request.SetMethod(consts.MethodPost)
+	ctx.Request.SetRequestURI("/partial")
+	stream := strings.NewReader("hello world")
+	ctx.Request.SetBodyStream(stream, 11)
+	engine.ServeHTTP(context.Background(), ctx)
+	assert.DeepEqual(t, "5\n", out.String())
+	// the body stream is put back for the server to release it
+	assert.True(t, ctx.Request.BodyStream() == io.Reader(stream))
+}
+
+func TestAccessLogExcludeRoutes(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithOutput(out), WithFormat("${path}\n"), WithExcludeRoutes("/user/:id", "/not-found")))
+	engine.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set("upstream", "10.0.0.1:8888")
+		ctx.Header("X-Request-ID", "resp-id")
+		ctx.String(consts.StatusOK, "hello")
+	})
+	engine.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "pong")
+	})
+	ut.PerformRequest(engine, consts.MethodGet, "/user/1", nil)
+	ut.PerformRequest(engine, consts.MethodGet, "/not-found", nil)
+	ut.PerformRequest(engine, consts.MethodGet, "/ping", nil)
+	assert.DeepEqual(t, "/ping\n", out.String())
+}
+
+func TestAccessLogInvalidFormat(t *testing.T) {
+	for _, opt := range []Option{
+		WithFormat("${status"),
+		WithFormat("${unknown}"),
+		WithJSONFields(TagStatus, "unknown"),
+	} {
+		func() {
+			defer func() {
+				assert.True(t, recover() != nil)
+			}()
+			New(opt)
+		}()
+	}
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{"", "abc", "a\"b\\c", "\x00\x1f\t\r\n", "中文", "\xff"} {
+		var got string
+		assert.Nil(t, json.Unmarshal(appendJSONString(nil, s), &got))
+		if s == "\xff" {
+			s = "\ufffd"
+		}
+		assert.DeepEqual(t, s, got)
+	}
+}
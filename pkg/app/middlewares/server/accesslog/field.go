@@ -0,0 +1,171 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// The tags of the fields of the access log, which are referred as ${tag} in the format,
+// or listed by WithJSONFields.
+const (
+	TagTime          = "time"
+	TagStatus        = "status"
+	TagLatency       = "latency"
+	TagMethod        = "method"
+	TagPath          = "path"
+	TagRoute         = "route"
+	TagQuery         = "query"
+	TagIP            = "ip"
+	TagHost          = "host"
+	TagProtocol      = "protocol"
+	TagUserAgent     = "userAgent"
+	TagReferer       = "referer"
+	TagBytesReceived = "bytesReceived"
+	TagBytesSent     = "bytesSent"
+	TagRequestID     = "requestID"
+	TagError         = "error"
+
+	// TagHeaderPrefix is the prefix of the tags of the request headers, e.g. ${header:X-Forwarded-For}.
+	TagHeaderPrefix = "header:"
+	// TagRespHeaderPrefix is the prefix of the tags of the response headers, e.g. ${respHeader:Content-Type}.
+	TagRespHeaderPrefix = "respHeader:"
+	// TagValuePrefix is the prefix of the tags of the values set on the RequestContext by Set,
+	// e.g. ${value:upstream} for the upstream set by the handler.
+	TagValuePrefix = "value:"
+)
+
+// entry is the access log of a request.
+type entry struct {
+	ctx     *app.RequestContext
+	start   time.Time
+	latency time.Duration
+	// bytesReceived is the size of the request body read by the handlers.
+	bytesReceived int64
+	// bytesSent is the size of the response body, which is the bytes written
+	// by the protocol if the body is streamed.
+	bytesSent int64
+}
+
+type field struct {
+	name    string
+	numeric bool
+	value   func(cfg *options, e *entry) string
+}
+
+var fields = map[string]field{
+	TagTime: {value: func(cfg *options, e *entry) string {
+		return e.start.Format(cfg.timeFormat)
+	}},
+	TagStatus: {numeric: true, value: func(cfg *options, e *entry) string {
+		return strconv.Itoa(e.ctx.Response.StatusCode())
+	}},
+	TagLatency: {value: func(cfg *options, e *entry) string {
+		return e.latency.String()
+	}},
+	TagMethod: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.Method())
+	}},
+	TagPath: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.Path())
+	}},
+	TagRoute: {value: func(cfg *options, e *entry) string {
+		return e.ctx.FullPath()
+	}},
+	TagQuery: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.URI().QueryString())
+	}},
+	TagIP: {value: func(cfg *options, e *entry) string {
+		return e.ctx.ClientIP()
+	}},
+	TagHost: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.Host())
+	}},
+	TagProtocol: {value: func(cfg *options, e *entry) string {
+		return e.ctx.Request.Header.GetProtocol()
+	}},
+	TagUserAgent: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.Request.Header.UserAgent())
+	}},
+	TagReferer: {value: func(cfg *options, e *entry) string {
+		return string(e.ctx.Request.Header.Peek(consts.HeaderReferer))
+	}},
+	TagBytesReceived: {numeric: true, value: func(cfg *options, e *entry) string {
+		return strconv.FormatInt(e.bytesReceived, 10)
+	}},
+	TagBytesSent: {numeric: true, value: func(cfg *options, e *entry) string {
+		return strconv.FormatInt(e.bytesSent, 10)
+	}},
+	TagRequestID: {value: func(cfg *options, e *entry) string {
+		if id := e.ctx.Request.Header.Peek(cfg.requestIDHeader); len(id) > 0 {
+			return string(id)
+		}
+		return string(e.ctx.Response.Header.Peek(cfg.requestIDHeader))
+	}},
+	TagError: {value: func(cfg *options, e *entry) string {
+		return strings.Join(e.ctx.Errors.Errors(), "; ")
+	}},
+}
+
+// lookupField returns the field of tag, which is false if tag is unknown.
+func lookupField(tag string) (field, bool) {
+	if f, ok := fields[tag]; ok {
+		f.name = tag
+		return f, true
+	}
+	switch {
+	case strings.HasPrefix(tag, TagHeaderPrefix):
+		key := tag[len(TagHeaderPrefix):]
+		return field{name: key, value: func(cfg *options, e *entry) string {
+			return string(e.ctx.Request.Header.Peek(key))
+		}}, true
+	case strings.HasPrefix(tag, TagRespHeaderPrefix):
+		key := tag[len(TagRespHeaderPrefix):]
+		return field{name: key, value: func(cfg *options, e *entry) string {
+			return string(e.ctx.Response.Header.Peek(key))
+		}}, true
+	case strings.HasPrefix(tag, TagValuePrefix):
+		key := tag[len(TagValuePrefix):]
+		return field{name: key, value: func(cfg *options, e *entry) string {
+			if v, ok := e.ctx.Get(key); ok {
+				return toString(v)
+			}
+			return ""
+		}}, true
+	}
+	return field{}, false
+}
+
+func toString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case fmt.Stringer:
+		return x.String()
+	case error:
+		return x.Error()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+// formatter appends the access log of an entry to dst.
+type formatter func(dst []byte, cfg *options, e *entry) []byte
+
+// compileTemplate compiles format with ${tag} placeholders into a formatter.
+// It panics if format refers to an unknown tag or a placeholder is not closed.
+func compileTemplate(format string) formatter {
+	var (
+		literals []string
+		values   []field
+	)
+	for {
+		start := strings.Index(format, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			panic("accesslog: unclosed placeholder in format: " + format[start:])
+		}
+		tag := format[start+2 : start+end]
+		f, ok := lookupField(tag)
+		if !ok {
+			panic("accesslog: unknown tag: " + tag)
+		}
+		literals = append(literals, format[:start])
+		values = append(values, f)
+		format = format[start+end+1:]
+	}
+	literals = append(literals, format)
+
+	return func(dst []byte, cfg *options, e *entry) []byte {
+		for i, f := range values {
+			dst = append(dst, literals[i]...)
+			dst = append(dst, f.value(cfg, e)...)
+		}
+		return append(dst, literals[len(values)]...)
+	}
+}
+
+// compileJSON compiles the tags into a formatter which writes a JSON object per line.
+// It panics if any tag is unknown.
+func compileJSON(tags []string) formatter {
+	values := make([]field, 0, len(tags))
+	for _, tag := range tags {
+		f, ok := lookupField(tag)
+		if !ok {
+			panic("accesslog: unknown tag: " + tag)
+		}
+		values = append(values, f)
+	}
+
+	return func(dst []byte, cfg *options, e *entry) []byte {
+		dst = append(dst, '{')
+		for i, f := range values {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJSONString(dst, f.name)
+			dst = append(dst, ':')
+			if f.numeric {
+				dst = append(dst, f.value(cfg, e)...)
+			} else {
+				dst = appendJSONString(dst, f.value(cfg, e))
+			}
+		}
+		return append(dst, '}', '\n')
+	}
+}
+
+// appendJSONString appends s to dst as a quoted JSON string.
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+			case c == '\t':
+				dst = append(dst, '\\', 't')
+			case c < 0x20:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				dst = append(dst, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, "\ufffd"...)
+		} else {
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultFormat is the format of the access log if neither WithFormat nor WithJSONFields is used.
+const DefaultFormat = "[${time}] ${status} - ${latency} ${method} ${path}\n"
+
+type (
+	options struct {
+		format          string
+		jsonFields      []string
+		output          io.Writer
+		timeFormat      string
+		requestIDHeader string
+		excludeRoutes   map[string]struct{}
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		format:          DefaultFormat,
+		output:          os.Stdout,
+		timeFormat:      time.RFC3339,
+		requestIDHeader: "X-Request-ID",
+		excludeRoutes:   make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithFormat sets the template of the access log, in which ${tag} is replaced by the field of tag.
+// e.g. "${ip} ${method} ${route} ${status} ${latency} ${header:X-Forwarded-For}\n".
+//
+// NOTE:
+//
+//	The newline is not appended automatically.
+//	New panics if the template refers to an unknown tag.
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = format
+		o.jsonFields = nil
+	}
+}
+
+// WithJSONFields makes the access log a JSON object per line, which contains the fields of tags.
+// The keys of the header and value tags are the names without the prefix.
+func WithJSONFields(tags ...string) Option {
+	return func(o *options) {
+		o.jsonFields = tags
+	}
+}
+
+// WithOutput sets the writer of the access log, which is os.Stdout by default.
+// Wrap the writer by NewAsyncWriter to write the access log in the background.
+//
+// NOTE:
+//
+//	Write is called once per request and may be called concurrently.
+func WithOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.output = w
+	}
+}
+
+// WithTimeFormat sets the layout of ${time}, which is time.RFC3339 by default.
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// WithRequestIDHeader sets the header of ${requestID}, which is "X-Request-ID" by default.
+// The request header is preferred, and the response header is used if it is absent.
+func WithRequestIDHeader(header string) Option {
+	return func(o *options) {
+		o.requestIDHeader = header
+	}
+}
+
+// WithExcludeRoutes skips the access log of routes, which are matched against
+// the route template (e.g. "/user/:id") and then the path of the request.
+func WithExcludeRoutes(routes ...string) Option {
+	return func(o *options) {
+		for _, r := range routes {
+			o.excludeRoutes[r] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// AsyncWriter writes to the underlying writer in a background goroutine,
+// so that slow sinks do not add latency to requests.
+type AsyncWriter struct {
+	w       io.Writer
+	ch      chan []byte
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	dropped uint64
+}
+
+// NewAsyncWriter returns an AsyncWriter buffering up to size writes for w.
+//
+// NOTE:
+//
+//	Writes are dropped instead of blocking when the buffer is full, see Dropped.
+//	Close must be called to flush the buffered writes before exiting.
+func NewAsyncWriter(w io.Writer, size int) *AsyncWriter {
+	if size <= 0 {
+		size = 1
+	}
+	aw := &AsyncWriter{
+		w:    w,
+		ch:   make(chan []byte, size),
+		done: make(chan struct{}),
+	}
+	go aw.loop()
+	return aw
+}
+
+func (aw *AsyncWriter) loop() {
+	defer close(aw.done)
+	for p := range aw.ch {
+		if _, err := aw.w.Write(p); err != nil {
+			hlog.SystemLogger().Errorf("Async write access log error=%v", err)
+		}
+	}
+}
+
+// Write copies p into the buffer, it never blocks and always reports len(p) written.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+	if aw.closed {
+		atomic.AddUint64(&aw.dropped, 1)
+		return len(p), nil
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+	select {
+	case aw.ch <- b:
+	default:
+		atomic.AddUint64(&aw.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of writes dropped due to the full buffer or after Close.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
+
+// Close flushes the buffered writes to the underlying writer, and closes it if it is an io.Closer.
+func (aw *AsyncWriter) Close() error {
+	var err error
+	aw.once.Do(func() {
+		aw.mu.Lock()
+		aw.closed = true
+		close(aw.ch)
+		aw.mu.Unlock()
+		<-aw.done
+		if c, ok := aw.w.(io.Closer); ok {
+			err = c.Close()
+		}
+	})
+	return err
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	block   chan struct{}
+	closed  bool
+	started chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.block
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestAsyncWriter(t *testing.T) {
+	w := &blockingWriter{block: make(chan struct{}), started: make(chan struct{}, 1)}
+	aw := NewAsyncWriter(w, 2)
+
+	p := []byte("a\n")
+	n, err := aw.Write(p)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 2, n)
+	// the buffer is copied
+	p[0] = 'x'
+	// the first write is taken by the background goroutine
+	<-w.started
+	aw.Write([]byte("b\n"))
+	aw.Write([]byte("c\n"))
+	// the buffer is full
+	aw.Write([]byte("d\n"))
+	assert.DeepEqual(t, uint64(1), aw.Dropped())
+
+	close(w.block)
+	assert.Nil(t, aw.Close())
+	assert.True(t, w.closed)
+	assert.DeepEqual(t, "a\nb\nc\n", w.buf.String())
+
+	// writes after Close are dropped
+	aw.Write([]byte("e\n"))
+	assert.DeepEqual(t, uint64(2), aw.Dropped())
+	assert.Nil(t, aw.Close())
+}
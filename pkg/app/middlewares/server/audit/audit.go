@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/requestid"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// decisionKey is the key of the decision set by SetDecision in the RequestContext.
+const decisionKey = "__hertz_audit_decision"
+
+// New returns a middleware which writes an audit record of each request to the sink
+// after it is handled, e.g.
+//
+//	h.Use(audit.New(
+//		audit.WithSink(sink),
+//		audit.WithSkipper(func(ctx *app.RequestContext) bool { return string(ctx.Method()) == "GET" }),
+//		audit.WithBodyFields("user.name", "user.password"),
+//	))
+//
+// NOTE:
+//
+//	The audit records are separate from the access logs, and are only written for the
+//	requests which are not skipped. Failures of the sink are logged and do not affect the response.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	return func(c context.Context, ctx *app.RequestContext) {
+		if cfg.skipper != nil && cfg.skipper(ctx) {
+			ctx.Next(c)
+			return
+		}
+		start := time.Now()
+		// the request fields are taken before the handlers are able to change them
+		request := cfg.requestFields(ctx)
+		ctx.Next(c)
+
+		r := &Record{
+			Time:      start,
+			Principal: cfg.principal(c, ctx),
+			Method:    string(ctx.Method()),
+			Route:     ctx.FullPath(),
+			Path:      string(ctx.Path()),
+			ClientIP:  ctx.ClientIP(),
+			RequestID: requestid.FromContext(c),
+			Decision:  cfg.decision(ctx),
+			Status:    ctx.Response.StatusCode(),
+			Latency:   time.Since(start),
+			Request:   request,
+			Response:  cfg.responseFields(ctx),
+		}
+		if d, ok := ctx.Get(decisionKey); ok {
+			r.Decision = d.(Decision)
+		}
+		if err := cfg.sink.Write(c, r); err != nil {
+			hlog.SystemLogger().CtxErrorf(c, "Write audit record error=%v", err)
+		}
+	}
+}
+
+// SetDecision sets the decision of the request explicitly, e.g. by an authorization middleware,
+// which takes the place of the decision derived from the response.
+func SetDecision(ctx *app.RequestContext, d Decision) {
+	ctx.Set(decisionKey, d)
+}
+
+func (o *options) requestFields(ctx *app.RequestContext) map[string]string {
+	if len(o.requestHeaders)+len(o.queryParams)+len(o.bodyFields) == 0 {
+		return nil
+	}
+	fields := make(map[string]string)
+	for _, name := range o.requestHeaders {
+		if v := ctx.Request.Header.Peek(name); v != nil {
+			fields["header."+name] = o.redact(name, string(v))
+		}
+	}
+	for _, name := range o.queryParams {
+		if v, ok := ctx.GetQuery(name); ok {
+			fields["query."+name] = o.redact(name, v)
+		}
+	}
+	if len(o.bodyFields) > 0 && bytes.HasPrefix(ctx.Request.Header.ContentType(), []byte("application/json")) {
+		var body interface{}
+		if json.Unmarshal(ctx.Request.Body(), &body) == nil {
+			for _, path := range o.bodyFields {
+				if v, ok := lookup(body, path); ok {
+					fields["body."+path] = o.redact(path, v)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+func (o *options) responseFields(ctx *app.RequestContext) map[string]string {
+	if len(o.responseHeaders) == 0 {
+		return nil
+	}
+	fields := make(map[string]string)
+	for _, name := range o.responseHeaders {
+		if v := ctx.Response.Header.Peek(name); v != nil {
+			fields["header."+name] = o.redact(name, string(v))
+		}
+	}
+	return fields
+}
+
+// redact returns Redacted if the name or the last segment of the path is a redacted key.
+func (o *options) redact(path, value string) string {
+	name := path[strings.LastIndexByte(path, '.')+1:]
+	if _, ok := o.redactKeys[strings.ToLower(name)]; ok {
+		return Redacted
+	}
+	return value
+}
+
+// lookup returns the value of the dot-separated path in the decoded JSON data.
+func lookup(data interface{}, path string) (string, bool) {
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if data, ok = m[seg]; !ok {
+			return "", false
+		}
+	}
+	if s, ok := data.(string); ok {
+		return s, true
+	}
+	b, _ := json.Marshal(data)
+	return string(b), true
+}
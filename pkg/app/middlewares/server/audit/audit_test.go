@@ -0,0 +1,162 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/basic_auth"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestAudit(t *testing.T) {
+	var records []*Record
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set(basic_auth.AuthUserKey, "alice")
+		ctx.Next(c)
+	})
+	engine.Use(New(
+		WithSink(SinkFunc(func(c context.Context, r *Record) error {
+			records = append(records, r)
+			return nil
+		})),
+		WithRequestHeaders("Authorization", "X-Client"),
+		WithResponseHeaders("X-Token", "X-Version"),
+		WithQueryParams("reason", "api_key"),
+		WithBodyFields("user.name", "user.password", "user.roles", "missing"),
+		WithRedactKeys("X-Token", "API_KEY"),
+	))
+	engine.POST("/users/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Header("X-Token", "t")
+		ctx.Header("X-Version", "2")
+		ctx.String(consts.StatusOK, "ok")
+	})
+	body := `{"user":{"name":"bob","password":"p","roles":["admin"]}}`
+	ut.PerformRequest(engine, consts.MethodPost, "/users/1?reason=onboard&api_key=k", &ut.Body{Body: bytes.NewBufferString(body), Len: len(body)},
+		ut.Header{Key: "Content-Type", Value: "application/json"},
+		ut.Header{Key: "Authorization", Value: "Bearer x"},
+		ut.Header{Key: "X-Client", Value: "cli"},
+	)
+
+	assert.DeepEqual(t, 1, len(records))
+	r := records[0]
+	assert.DeepEqual(t, "alice", r.Principal)
+	assert.DeepEqual(t, "POST", r.Method)
+	assert.DeepEqual(t, "/users/:id", r.Route)
+	assert.DeepEqual(t, "/users/1", r.Path)
+	assert.DeepEqual(t, DecisionAllow, r.Decision)
+	assert.DeepEqual(t, consts.StatusOK, r.Status)
+	assert.DeepEqual(t, map[string]string{
+		"header.Authorization": Redacted,
+		"header.X-Client":      "cli",
+		"query.reason":         "onboard",
+		"query.api_key":        Redacted,
+		"body.user.name":       "bob",
+		"body.user.password":   Redacted,
+		"body.user.roles":      `["admin"]`,
+	}, r.Request)
+	assert.DeepEqual(t, map[string]string{
+		"header.X-Token":   Redacted,
+		"header.X-Version": "2",
+	}, r.Response)
+}
+
+func TestAuditDecision(t *testing.T) {
+	var records []*Record
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithSink(SinkFunc(func(c context.Context, r *Record) error {
+		records = append(records, r)
+		return nil
+	}))))
+	engine.DELETE("/users/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.AbortWithStatus(consts.StatusForbidden)
+	})
+	engine.GET("/users/:id", func(c context.Context, ctx *app.RequestContext) {
+		SetDecision(ctx, DecisionDeny)
+		ctx.String(consts.StatusOK, "masked")
+	})
+	ut.PerformRequest(engine, consts.MethodDelete, "/users/1", nil)
+	ut.PerformRequest(engine, consts.MethodGet, "/users/1", nil)
+
+	assert.DeepEqual(t, 2, len(records))
+	assert.DeepEqual(t, DecisionDeny, records[0].Decision)
+	assert.DeepEqual(t, consts.StatusForbidden, records[0].Status)
+	// the decision set by the handler takes precedence
+	assert.DeepEqual(t, DecisionDeny, records[1].Decision)
+	assert.DeepEqual(t, consts.StatusOK, records[1].Status)
+	assert.Nil(t, records[1].Request)
+}
+
+func TestAuditSkipper(t *testing.T) {
+	var records []*Record
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithSink(SinkFunc(func(c context.Context, r *Record) error {
+			records = append(records, r)
+			return nil
+		})),
+		WithSkipper(func(ctx *app.RequestContext) bool {
+			return string(ctx.Method()) == consts.MethodGet
+		}),
+	))
+	engine.GET("/users/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "masked")
+	})
+	w := ut.PerformRequest(engine, consts.MethodGet, "/users/1", nil)
+	assert.DeepEqual(t, "masked", string(w.Result().Body()))
+	assert.DeepEqual(t, 0, len(records))
+}
+
+func TestWriterSink(t *testing.T) {
+	out := &bytes.Buffer{}
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithSink(NewWriterSink(out)), WithPrincipal(func(c context.Context, ctx *app.RequestContext) string {
+		return "svc"
+	})))
+	engine.GET("/ping", func(c context.Context, ctx *app.RequestContext) {})
+	ut.PerformRequest(engine, consts.MethodGet, "/ping", nil)
+
+	assert.DeepEqual(t, byte('\n'), out.Bytes()[out.Len()-1])
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &m))
+	assert.DeepEqual(t, "svc", m["principal"])
+	assert.DeepEqual(t, "/ping", m["route"])
+	assert.DeepEqual(t, "allow", m["decision"])
+	assert.DeepEqual(t, float64(200), m["status"])
+}
+
+func TestSinkError(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithSink(SinkFunc(func(c context.Context, r *Record) error {
+		return errors.New("sink is down")
+	}))))
+	engine.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "pong")
+	})
+	w := ut.PerformRequest(engine, consts.MethodGet, "/ping", nil)
+	assert.DeepEqual(t, "pong", string(w.Result().Body()))
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/basic_auth"
+)
+
+// Redacted replaces the values of the redacted fields.
+const Redacted = "[REDACTED]"
+
+// defaultRedactKeys are always redacted, in lower case.
+var defaultRedactKeys = []string{"authorization", "proxy-authorization", "cookie", "set-cookie", "password", "token", "secret"}
+
+type (
+	options struct {
+		sink            Sink
+		principal       func(c context.Context, ctx *app.RequestContext) string
+		decision        func(ctx *app.RequestContext) Decision
+		skipper         func(ctx *app.RequestContext) bool
+		requestHeaders  []string
+		responseHeaders []string
+		queryParams     []string
+		bodyFields      []string
+		redactKeys      map[string]struct{}
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		sink:       NewWriterSink(os.Stdout),
+		principal:  defaultPrincipal,
+		decision:   defaultDecision,
+		redactKeys: make(map[string]struct{}),
+	}
+	for _, k := range defaultRedactKeys {
+		cfg.redactKeys[k] = struct{}{}
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// defaultPrincipal takes the user set by the basic_auth middleware.
+func defaultPrincipal(c context.Context, ctx *app.RequestContext) string {
+	return ctx.GetString(basic_auth.AuthUserKey)
+}
+
+// defaultDecision derives the decision from the status code,
+// 401 and 403 are denied, 5xx are errors and the others are allowed.
+func defaultDecision(ctx *app.RequestContext) Decision {
+	switch status := ctx.Response.StatusCode(); {
+	case status == 401 || status == 403:
+		return DecisionDeny
+	case status >= 500:
+		return DecisionError
+	}
+	return DecisionAllow
+}
+
+// WithSink sets the sink of the records, which writes JSON lines to os.Stdout by default.
+func WithSink(s Sink) Option {
+	return func(o *options) {
+		o.sink = s
+	}
+}
+
+// WithPrincipal sets the function returning who performs the request,
+// which is the user set by the basic_auth middleware by default.
+func WithPrincipal(f func(c context.Context, ctx *app.RequestContext) string) Option {
+	return func(o *options) {
+		o.principal = f
+	}
+}
+
+// WithDecision sets the function deciding the outcome of the request, which is used
+// if the handlers do not call SetDecision. By default, 401 and 403 are denied,
+// 5xx are errors and the others are allowed.
+func WithDecision(f func(ctx *app.RequestContext) Decision) Option {
+	return func(o *options) {
+		o.decision = f
+	}
+}
+
+// WithSkipper skips the requests for which f returns true, e.g. the read-only ones.
+func WithSkipper(f func(ctx *app.RequestContext) bool) Option {
+	return func(o *options) {
+		o.skipper = f
+	}
+}
+
+// WithRequestHeaders records the request headers of names as "header.<name>".
+func WithRequestHeaders(names ...string) Option {
+	return func(o *options) {
+		o.requestHeaders = append(o.requestHeaders, names...)
+	}
+}
+
+// WithResponseHeaders records the response headers of names as "header.<name>".
+func WithResponseHeaders(names ...string) Option {
+	return func(o *options) {
+		o.responseHeaders = append(o.responseHeaders, names...)
+	}
+}
+
+// WithQueryParams records the query parameters of names as "query.<name>".
+func WithQueryParams(names ...string) Option {
+	return func(o *options) {
+		o.queryParams = append(o.queryParams, names...)
+	}
+}
+
+// WithBodyFields records the fields of the JSON request body as "body.<path>",
+// the path of a nested field is separated by dots, e.g. "user.name".
+//
+// NOTE:
+//
+//	The body is only parsed if its content type is application/json.
+//	The values which are not strings are recorded in JSON.
+func WithBodyFields(paths ...string) Option {
+	return func(o *options) {
+		o.bodyFields = append(o.bodyFields, paths...)
+	}
+}
+
+// WithRedactKeys redacts the recorded fields whose names, or the last segment of the paths,
+// match keys case-insensitively. Authorization, Proxy-Authorization, Cookie, Set-Cookie,
+// password, token and secret are always redacted.
+func WithRedactKeys(keys ...string) Option {
+	return func(o *options) {
+		for _, k := range keys {
+			o.redactKeys[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
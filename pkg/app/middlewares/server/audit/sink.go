@@ -0,0 +1,92 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of the audited action.
+type Decision string
+
+const (
+	DecisionAllow Decision = "allow"
+	DecisionDeny  Decision = "deny"
+	DecisionError Decision = "error"
+)
+
+// Record is an audit record of who did what.
+type Record struct {
+	Time      time.Time     `json:"time"`
+	Principal string        `json:"principal,omitempty"`
+	Method    string        `json:"method"`
+	Route     string        `json:"route"`
+	Path      string        `json:"path"`
+	ClientIP  string        `json:"client_ip"`
+	RequestID string        `json:"request_id,omitempty"`
+	Decision  Decision      `json:"decision"`
+	Status    int           `json:"status"`
+	Latency   time.Duration `json:"latency"`
+	// Request holds the selected request fields, the keys are prefixed by
+	// "header.", "query." or "body." according to where the values come from.
+	Request map[string]string `json:"request,omitempty"`
+	// Response holds the selected response fields, the keys are prefixed by "header.".
+	Response map[string]string `json:"response,omitempty"`
+}
+
+// Sink stores the audit records.
+//
+// NOTE:
+//
+//	Write is called once per audited request and may be called concurrently.
+type Sink interface {
+	Write(c context.Context, r *Record) error
+}
+
+// SinkFunc is an adapter to allow the use of ordinary functions as Sink.
+type SinkFunc func(c context.Context, r *Record) error
+
+// Write implements Sink.
+func (f SinkFunc) Write(c context.Context, r *Record) error {
+	return f(c, r)
+}
+
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a Sink writing the records to w as JSON objects, one per line.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Write(c context.Context, r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(b)
+	return err
+}
@@ -42,22 +42,35 @@ package basic_auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/pkg/app"
 )
 
+// AuthUserKey is the key of the authenticated user in the context used by BasicAuth.
+const AuthUserKey = "user"
+
+const defaultRealm = "Authorization Required"
+
 // Accounts is an alias to map[string]string, construct with {"username":"password"}
 type Accounts map[string]string
 
 // pairs is an alias to map[string]string, which mean {"header":"username"}
 type pairs map[string]string
 
+// findValue searches needle in constant time, so that the credentials can not be guessed by timing.
 func (p pairs) findValue(needle string) (v string, ok bool) {
-	v, ok = p[needle]
+	for header, user := range p {
+		if subtle.ConstantTimeCompare(bytesconv.S2b(header), bytesconv.S2b(needle)) == 1 {
+			v, ok = user, true
+		}
+	}
 	return
 }
 
@@ -76,6 +89,9 @@ func constructPairs(accounts Accounts) pairs {
 // If the realm is empty, "Authorization Required" will be used by default.
 // (see http://tools.ietf.org/html/rfc2617#section-1.2)
 func BasicAuthForRealm(accounts Accounts, realm, userKey string) app.HandlerFunc {
+	if realm == "" {
+		realm = defaultRealm
+	}
 	realm = "Basic realm=" + strconv.Quote(realm)
 	p := constructPairs(accounts)
 	return func(ctx context.Context, c *app.RequestContext) {
@@ -97,5 +113,73 @@ func BasicAuthForRealm(accounts Accounts, realm, userKey string) app.HandlerFunc
 // It returns a Basic HTTP Authorization middleware. It takes as argument a map[string]string where
 // the key is the username and the value is the password.
 func BasicAuth(accounts Accounts) app.HandlerFunc {
-	return BasicAuthForRealm(accounts, "Authorization Required", "user")
+	return BasicAuthForRealm(accounts, defaultRealm, AuthUserKey)
+}
+
+// Verifier reports whether the password of user is valid, e.g. by looking up a database or LDAP.
+type Verifier func(ctx context.Context, user, password string) bool
+
+// BasicAuthWithVerifier returns a Basic HTTP Authorization middleware which verifies the credentials by verifier,
+// and sets the user to userKey in the context if they are valid.
+// If the realm is empty, "Authorization Required" will be used by default.
+//
+// NOTE:
+//
+//	The verifier should compare the password in constant time, see StaticVerifier.
+func BasicAuthWithVerifier(verifier Verifier, realm, userKey string) app.HandlerFunc {
+	if realm == "" {
+		realm = defaultRealm
+	}
+	realm = "Basic realm=" + strconv.Quote(realm)
+	return func(ctx context.Context, c *app.RequestContext) {
+		user, password, ok := parseBasicAuth(c.Request.Header.Get("Authorization"))
+		if !ok || !verifier(ctx, user, password) {
+			c.Header("WWW-Authenticate", realm)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(userKey, user)
+	}
+}
+
+// StaticVerifier returns a Verifier of accounts, which compares the passwords in constant time.
+func StaticVerifier(accounts Accounts) Verifier {
+	hashes := make(map[string][sha256.Size]byte, len(accounts))
+	for user, password := range accounts {
+		hashes[user] = sha256.Sum256(bytesconv.S2b(password))
+	}
+	return func(ctx context.Context, user, password string) bool {
+		expected, ok := hashes[user]
+		// the hash of the password is compared even if the user does not exist to keep the timing
+		actual := sha256.Sum256(bytesconv.S2b(password))
+		return subtle.ConstantTimeCompare(expected[:], actual[:]) == 1 && ok
+	}
+}
+
+// GetUser returns the authenticated user set by BasicAuth.
+func GetUser(c *app.RequestContext) (string, bool) {
+	user, ok := c.Get(AuthUserKey)
+	if !ok {
+		return "", false
+	}
+	s, ok := user.(string)
+	return s, ok
+}
+
+// parseBasicAuth parses the credentials of the Authorization header in Basic scheme.
+func parseBasicAuth(auth string) (user, password string, ok bool) {
+	const prefix = "Basic "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	cs := string(decoded)
+	idx := strings.IndexByte(cs, ':')
+	if idx < 0 {
+		return "", "", false
+	}
+	return cs[:idx], cs[idx+1:], true
 }
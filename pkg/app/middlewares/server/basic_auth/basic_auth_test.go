@@ -43,6 +43,7 @@ package basic_auth
 import (
 	"context"
 	"encoding/base64"
+	"net/http"
 	"testing"
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
@@ -96,3 +97,44 @@ func TestBasicAuth(t *testing.T) {
 	assert.Nil(t, user)
 	assert.False(t, ok)
 }
+
+func TestBasicAuthWithVerifier(t *testing.T) {
+	handler := BasicAuthWithVerifier(StaticVerifier(Accounts{"user1": "value1"}), "", "principal")
+
+	for auth, valid := range map[string]bool{
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("user1:value1")): true,
+		"basic " + base64.StdEncoding.EncodeToString([]byte("user1:value1")): true,
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("user1:value2")): false,
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("user2:value1")): false,
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("user1")):        false,
+		"Basic !!!":  false,
+		"Bearer abc": false,
+		"":           false,
+	} {
+		c := app.RequestContext{}
+		c.Request.Header.Add("Authorization", auth)
+		handler(context.TODO(), &c)
+
+		user, ok := c.Get("principal")
+		assert.DeepEqual(t, valid, ok)
+		assert.DeepEqual(t, valid, !c.IsAborted())
+		if valid {
+			assert.DeepEqual(t, "user1", user)
+			continue
+		}
+		assert.DeepEqual(t, http.StatusUnauthorized, c.Response.StatusCode())
+		assert.DeepEqual(t, `Basic realm="Authorization Required"`, string(c.Response.Header.Peek("WWW-Authenticate")))
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	c := app.RequestContext{}
+	c.Request.Header.Add("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("user1:value1")))
+	BasicAuth(Accounts{"user1": "value1"})(context.TODO(), &c)
+	user, ok := GetUser(&c)
+	assert.True(t, ok)
+	assert.DeepEqual(t, "user1", user)
+
+	_, ok = GetUser(&app.RequestContext{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// keySep separates the parts of the cache key, which are the path, method, query string and vary headers.
+const keySep = "\x00"
+
+// entry is the cached response.
+type entry struct {
+	Status     int         `json:"status"`
+	Header     [][2]string `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+	FreshUntil time.Time   `json:"fresh_until"`
+	StaleUntil time.Time   `json:"stale_until"`
+}
+
+// Cache caches the responses of the requests in a Store, e.g.
+//
+//	c := cache.New(cache.NewMemoryStore(10000), time.Minute)
+//	h.GET("/articles/:id", c.Middleware(), getArticle)
+//	h.PUT("/articles/:id", updateArticle) // calls c.InvalidatePath(ctx, "/articles/1")
+type Cache struct {
+	store      Store
+	ttl        time.Duration
+	opts       *options
+	now        func() time.Time
+	refreshing sync.Map
+}
+
+// New returns a Cache of store, the responses are cached for ttl unless Cache-Control of the responses
+// sets "s-maxage" or "max-age".
+func New(store Store, ttl time.Duration, opts ...Option) *Cache {
+	return &Cache{
+		store: store,
+		ttl:   ttl,
+		opts:  newOptions(opts...),
+		now:   time.Now,
+	}
+}
+
+// Middleware returns a middleware serving the requests from the cache, and caching the responses of the handlers.
+//
+// NOTE:
+//
+//	The requests with "Cache-Control: no-store" bypass the cache, and the ones with "no-cache" are revalidated.
+//	The stale responses within WithStaleWhileRevalidate are served at once, and revalidated in the background.
+//	The responses with "Cache-Control: no-store", "no-cache" or "private", Set-Cookie or body stream are not cached.
+func (c *Cache) Middleware() app.HandlerFunc {
+	return func(ctx context.Context, rc *app.RequestContext) {
+		if !c.opts.methods[string(rc.Method())] {
+			rc.Next(ctx)
+			return
+		}
+		reqCC := parseCacheControl(string(rc.Request.Header.Peek(consts.HeaderCacheControl)))
+		if _, ok := reqCC["no-store"]; ok {
+			rc.Next(ctx)
+			return
+		}
+
+		key := c.key(rc)
+		if _, ok := reqCC["no-cache"]; !ok {
+			if e := c.load(ctx, key); e != nil {
+				now := c.now()
+				if now.Before(e.FreshUntil) {
+					c.serve(rc, e, now, "HIT")
+					return
+				}
+				if _, refreshing := c.refreshing.LoadOrStore(key, struct{}{}); !refreshing {
+					c.revalidate(ctx, rc, key)
+				}
+				c.serve(rc, e, now, "STALE")
+				return
+			}
+		}
+
+		rc.Next(ctx)
+		if c.opts.statusHeader != "" {
+			rc.Response.Header.Set(c.opts.statusHeader, "MISS")
+		}
+		c.save(ctx, rc, key)
+	}
+}
+
+// revalidate runs the rest of the handlers on a copy of the request in the background, and caches
+// the response of it. The stale response is served to the request meanwhile.
+//
+// NOTE:
+//
+//	The copy has no connection, so RemoteAddr of it is not available, which the shared responses
+//	should not depend on anyway.
+func (c *Cache) revalidate(ctx context.Context, rc *app.RequestContext, key string) {
+	cp := app.NewContext(0)
+	rc.Request.CopyTo(&cp.Request)
+	cp.Params = append(cp.Params, rc.Params...)
+	rc.ForEachKey(cp.Set)
+	cp.SetTranslator(rc.Translator())
+	cp.SetHandlers(rc.Handlers()[rc.GetIndex()+1:])
+	go func() {
+		defer c.refreshing.Delete(key)
+		defer func() {
+			if r := recover(); r != nil {
+				hlog.SystemLogger().CtxErrorf(ctx, "Revalidate cached response panic=%v", r)
+			}
+		}()
+		cp.Next(ctx)
+		c.save(ctx, cp, key)
+	}()
+}
+
+// Invalidate deletes the cached responses of the request, the responses with all the vary headers are deleted.
+func (c *Cache) Invalidate(ctx context.Context, method, path, query string) error {
+	return c.store.DeletePrefix(ctx, path+keySep+method+keySep+query+keySep)
+}
+
+// InvalidatePath deletes the cached responses of path with all methods and query strings.
+func (c *Cache) InvalidatePath(ctx context.Context, path string) error {
+	return c.store.DeletePrefix(ctx, path+keySep)
+}
+
+// Purge deletes all the cached responses.
+func (c *Cache) Purge(ctx context.Context) error {
+	return c.store.DeletePrefix(ctx, "")
+}
+
+func (c *Cache) key(rc *app.RequestContext) string {
+	var b strings.Builder
+	b.Write(rc.Path())
+	b.WriteString(keySep)
+	b.Write(rc.Method())
+	b.WriteString(keySep)
+	b.Write(rc.URI().QueryString())
+	b.WriteString(keySep)
+	for _, h := range c.opts.varyHeaders {
+		b.Write(rc.Request.Header.Peek(h))
+		b.WriteString(keySep)
+	}
+	return b.String()
+}
+
+func (c *Cache) load(ctx context.Context, key string) *entry {
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		hlog.SystemLogger().CtxWarnf(ctx, "Get cached response error=%v", err)
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	e := &entry{}
+	if err = json.Unmarshal(data, e); err != nil {
+		hlog.SystemLogger().CtxWarnf(ctx, "Decode cached response error=%v", err)
+		return nil
+	}
+	return e
+}
+
+func (c *Cache) serve(rc *app.RequestContext, e *entry, now time.Time, status string) {
+	rc.Response.Reset()
+	rc.Response.SetStatusCode(e.Status)
+	for _, kv := range e.Header {
+		rc.Response.Header.Add(kv[0], kv[1])
+	}
+	rc.Response.Header.Set(consts.HeaderAge, strconv.FormatInt(int64(now.Sub(e.StoredAt)/time.Second), 10))
+	if c.opts.statusHeader != "" {
+		rc.Response.Header.Set(c.opts.statusHeader, status)
+	}
+	rc.Response.SetBody(e.Body)
+	rc.Abort()
+}
+
+func (c *Cache) save(ctx context.Context, rc *app.RequestContext, key string) {
+	resp := &rc.Response
+	if !c.opts.statusCodes[resp.StatusCode()] || resp.IsBodyStream() ||
+		len(resp.Header.Peek(consts.HeaderSetCookie)) > 0 || string(resp.Header.Peek(consts.HeaderVary)) == "*" {
+		return
+	}
+	ttl := c.ttl
+	cc := parseCacheControl(string(resp.Header.Peek(consts.HeaderCacheControl)))
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[d]; ok {
+			return
+		}
+	}
+	for _, d := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[d]; ok {
+			if sec, err := strconv.Atoi(v); err == nil {
+				ttl = time.Duration(sec) * time.Second
+			}
+			break
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	now := c.now()
+	e := &entry{
+		Status:     resp.StatusCode(),
+		Body:       resp.Body(),
+		StoredAt:   now,
+		FreshUntil: now.Add(ttl),
+		StaleUntil: now.Add(ttl + c.opts.staleWhileRevalidate),
+	}
+	resp.Header.VisitAll(func(k, v []byte) {
+		name := bytesconv.B2s(k)
+		switch {
+		case strings.EqualFold(name, consts.HeaderContentLength), strings.EqualFold(name, consts.HeaderConnection),
+			strings.EqualFold(name, consts.HeaderDate), strings.EqualFold(name, consts.HeaderTransferEncoding),
+			strings.EqualFold(name, consts.HeaderServer), strings.EqualFold(name, c.opts.statusHeader):
+			return
+		}
+		e.Header = append(e.Header, [2]string{string(k), string(v)})
+	})
+	data, err := json.Marshal(e)
+	if err != nil {
+		hlog.SystemLogger().CtxWarnf(ctx, "Encode cached response error=%v", err)
+		return
+	}
+	if err = c.store.Set(ctx, key, data, ttl+c.opts.staleWhileRevalidate); err != nil {
+		hlog.SystemLogger().CtxWarnf(ctx, "Set cached response error=%v", err)
+	}
+}
+
+// parseCacheControl parses the directives of Cache-Control, the names are lower-cased.
+func parseCacheControl(v string) map[string]string {
+	if v == "" {
+		return nil
+	}
+	directives := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], strings.Trim(strings.TrimSpace(part[i+1:]), `"`)
+		}
+		directives[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return directives
+}
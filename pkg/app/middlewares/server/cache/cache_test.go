@@ -0,0 +1,219 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+type testServer struct {
+	engine *route.Engine
+	cache  *Cache
+	calls  int32
+	now    time.Time
+}
+
+func newTestServer(opts ...Option) *testServer {
+	s := &testServer{now: time.Unix(1000, 0)}
+	store := NewMemoryStore(0)
+	store.(*memoryStore).now = func() time.Time { return s.now }
+	s.cache = New(store, time.Minute, opts...)
+	s.cache.now = func() time.Time { return s.now }
+
+	s.engine = route.NewEngine(config.NewOptions(nil))
+	s.engine.Use(s.cache.Middleware())
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		n := atomic.AddInt32(&s.calls, 1)
+		if cc := ctx.Query("cc"); cc != "" {
+			ctx.Header(consts.HeaderCacheControl, cc)
+		}
+		if ctx.Query("cookie") != "" {
+			ctx.SetCookie("a", "b", 0, "/", "", 0, false, false)
+		}
+		ctx.Header("X-Foo", "foo")
+		ctx.String(consts.StatusOK, strconv.Itoa(int(n))+" "+string(ctx.GetHeader("Accept-Language")))
+	}
+	s.engine.GET("/foo", handler)
+	s.engine.POST("/foo", handler)
+	s.engine.GET("/bar", handler)
+	s.engine.GET("/error", func(c context.Context, ctx *app.RequestContext) {
+		atomic.AddInt32(&s.calls, 1)
+		ctx.String(consts.StatusInternalServerError, "error")
+	})
+	return s
+}
+
+func (s *testServer) get(url string, headers ...ut.Header) (body, status string) {
+	resp := ut.PerformRequest(s.engine, consts.MethodGet, url, nil, headers...).Result()
+	return string(resp.Body()), resp.Header.Get("X-Cache")
+}
+
+func TestCache(t *testing.T) {
+	s := newTestServer()
+
+	body, status := s.get("/foo")
+	assert.DeepEqual(t, "1 ", body)
+	assert.DeepEqual(t, "MISS", status)
+
+	s.now = s.now.Add(10 * time.Second)
+	resp := ut.PerformRequest(s.engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, "1 ", string(resp.Body()))
+	assert.DeepEqual(t, "HIT", resp.Header.Get("X-Cache"))
+	assert.DeepEqual(t, "10", resp.Header.Get(consts.HeaderAge))
+	assert.DeepEqual(t, "foo", resp.Header.Get("X-Foo"))
+	assert.DeepEqual(t, "text/plain; charset=utf-8", string(resp.Header.ContentType()))
+
+	// the query string is part of the key
+	body, _ = s.get("/foo?a=b")
+	assert.DeepEqual(t, "2 ", body)
+	// POST is not cached
+	resp = ut.PerformRequest(s.engine, consts.MethodPost, "/foo", nil).Result()
+	assert.DeepEqual(t, "3 ", string(resp.Body()))
+	assert.DeepEqual(t, "", resp.Header.Get("X-Cache"))
+
+	// expired
+	s.now = s.now.Add(time.Minute)
+	body, status = s.get("/foo")
+	assert.DeepEqual(t, "4 ", body)
+	assert.DeepEqual(t, "MISS", status)
+
+	// the failed responses are not cached
+	s.get("/error")
+	s.get("/error")
+	assert.DeepEqual(t, int32(6), s.calls)
+}
+
+func TestCacheControl(t *testing.T) {
+	s := newTestServer()
+
+	// request directives
+	s.get("/foo")
+	body, _ := s.get("/foo", ut.Header{Key: consts.HeaderCacheControl, Value: "no-store"})
+	assert.DeepEqual(t, "2 ", body)
+	body, _ = s.get("/foo")
+	assert.DeepEqual(t, "1 ", body)
+	body, _ = s.get("/foo", ut.Header{Key: consts.HeaderCacheControl, Value: "no-cache"})
+	assert.DeepEqual(t, "3 ", body)
+	body, _ = s.get("/foo")
+	assert.DeepEqual(t, "3 ", body)
+
+	// response directives
+	for _, url := range []string{"/bar?cc=no-store", "/bar?cc=private,max-age=10", "/bar?cc=max-age%3D0", "/bar?cookie=1"} {
+		first, _ := s.get(url)
+		second, _ := s.get(url)
+		assert.NotEqual(t, first, second)
+	}
+	s.get("/bar?cc=s-maxage%3D5,max-age%3D100")
+	s.now = s.now.Add(4 * time.Second)
+	_, status := s.get("/bar?cc=s-maxage%3D5,max-age%3D100")
+	assert.DeepEqual(t, "HIT", status)
+	s.now = s.now.Add(time.Second)
+	_, status = s.get("/bar?cc=s-maxage%3D5,max-age%3D100")
+	assert.DeepEqual(t, "MISS", status)
+}
+
+func TestCacheVaryHeaders(t *testing.T) {
+	s := newTestServer(WithVaryHeaders("accept-language"), WithStatusHeader(""))
+	en := ut.Header{Key: "Accept-Language", Value: "en"}
+	zh := ut.Header{Key: "Accept-Language", Value: "zh"}
+
+	body, status := s.get("/foo", en)
+	assert.DeepEqual(t, "1 en", body)
+	assert.DeepEqual(t, "", status)
+	body, _ = s.get("/foo", zh)
+	assert.DeepEqual(t, "2 zh", body)
+	body, _ = s.get("/foo", en)
+	assert.DeepEqual(t, "1 en", body)
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	s := newTestServer(WithStaleWhileRevalidate(time.Minute))
+	s.get("/foo")
+	s.now = s.now.Add(90 * time.Second)
+
+	// the response is being revalidated by another request
+	key := "/foo" + keySep + "GET" + keySep + keySep
+	s.cache.refreshing.Store(key, struct{}{})
+	body, status := s.get("/foo")
+	assert.DeepEqual(t, "1 ", body)
+	assert.DeepEqual(t, "STALE", status)
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&s.calls))
+	s.cache.refreshing.Delete(key)
+
+	// the stale response is served at once and revalidated in the background
+	body, status = s.get("/foo")
+	assert.DeepEqual(t, "1 ", body)
+	assert.DeepEqual(t, "STALE", status)
+	for i := 0; i < 100; i++ {
+		if _, ok := s.cache.refreshing.Load(key); !ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, ok := s.cache.refreshing.Load(key)
+	assert.False(t, ok)
+	assert.DeepEqual(t, int32(2), atomic.LoadInt32(&s.calls))
+	body, status = s.get("/foo")
+	assert.DeepEqual(t, "2 ", body)
+	assert.DeepEqual(t, "HIT", status)
+
+	// beyond the stale window
+	s.now = s.now.Add(2 * time.Minute)
+	body, _ = s.get("/foo")
+	assert.DeepEqual(t, "3 ", body)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	s.get("/foo")
+	s.get("/foo?a=b")
+	s.get("/bar")
+
+	assert.Nil(t, s.cache.Invalidate(ctx, consts.MethodGet, "/foo", "a=b"))
+	body, _ := s.get("/foo")
+	assert.DeepEqual(t, "1 ", body)
+	body, _ = s.get("/foo?a=b")
+	assert.DeepEqual(t, "4 ", body)
+
+	assert.Nil(t, s.cache.InvalidatePath(ctx, "/foo"))
+	body, _ = s.get("/foo")
+	assert.DeepEqual(t, "5 ", body)
+	body, _ = s.get("/bar")
+	assert.DeepEqual(t, "3 ", body)
+
+	assert.Nil(t, s.cache.Purge(ctx))
+	body, _ = s.get("/bar")
+	assert.DeepEqual(t, "6 ", body)
+}
+
+func TestParseCacheControl(t *testing.T) {
+	assert.DeepEqual(t, map[string]string(nil), parseCacheControl(""))
+	assert.DeepEqual(t, map[string]string{"no-cache": "", "max-age": "10", "private": "x"},
+		parseCacheControl(`No-Cache, max-age=10,, private="x"`))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		varyHeaders          []string
+		staleWhileRevalidate time.Duration
+		statusCodes          map[int]bool
+		methods              map[string]bool
+		statusHeader         string
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		statusCodes:  map[int]bool{consts.StatusOK: true},
+		methods:      map[string]bool{consts.MethodGet: true, consts.MethodHead: true},
+		statusHeader: "X-Cache",
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithVaryHeaders sets the request headers which the cached responses vary on,
+// e.g. "Accept-Encoding" or "Accept-Language", they are part of the cache key.
+//
+// NOTE:
+//
+//	The Vary header of the response is not taken into account,
+//	the responses with "Vary: *" are not cached.
+func WithVaryHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.varyHeaders = make([]string, 0, len(headers))
+		for _, h := range headers {
+			o.varyHeaders = append(o.varyHeaders, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// WithStaleWhileRevalidate serves the stale response for d after it expires while it is being revalidated.
+//
+// NOTE:
+//
+//	The revalidation is done by the first request after the response expires,
+//	the concurrent requests are served with the stale response meanwhile.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(o *options) {
+		o.staleWhileRevalidate = d
+	}
+}
+
+// WithStatusCodes sets the status codes of the responses which can be cached, which is 200 only by default.
+func WithStatusCodes(codes ...int) Option {
+	return func(o *options) {
+		o.statusCodes = make(map[int]bool, len(codes))
+		for _, c := range codes {
+			o.statusCodes[c] = true
+		}
+	}
+}
+
+// WithMethods sets the methods of the requests which can be cached, which are GET and HEAD by default.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		o.methods = make(map[string]bool, len(methods))
+		for _, m := range methods {
+			o.methods[m] = true
+		}
+	}
+}
+
+// WithStatusHeader sets the header indicating whether the response is served from the cache,
+// whose value is "HIT", "STALE" or "MISS". It is "X-Cache" by default, and empty name disables it.
+func WithStatusHeader(name string) Option {
+	return func(o *options) {
+		o.statusHeader = name
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RedisClient is the Redis client used by the Store returned by NewRedisStore,
+// e.g. the adapter of github.com/redis/go-redis:
+//
+//	type redisClient struct {
+//		*redis.Client
+//	}
+//
+//	func (c redisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		v, err := c.Client.Eval(ctx, script, keys, args...).Result()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return v, err
+//	}
+//
+//	func (c redisClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+//		return c.Client.Scan(ctx, cursor, match, count).Result()
+//	}
+//
+//	func (c redisClient) Unlink(ctx context.Context, keys ...string) error {
+//		return c.Client.Unlink(ctx, keys...).Err()
+//	}
+type RedisClient interface {
+	// Eval evaluates the Lua script and returns the reply, which is nil for the nil reply.
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+	// Scan returns a batch of the keys matching the pattern from cursor and the cursor of
+	// the next batch, which is 0 once the scan is done.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) (keys []string, next uint64, err error)
+
+	// Unlink deletes the keys. The keys of a batch may belong to different slots of Redis Cluster,
+	// in which case the adapter unlinks them by slots, or scans and unlinks on each master.
+	Unlink(ctx context.Context, keys ...string) error
+}
+
+const (
+	redisGetScript    = `return redis.call('GET', KEYS[1])`
+	redisSetScript    = `return redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])`
+	redisDeleteScript = `return redis.call('DEL', KEYS[1])`
+
+	// redisScanCount is the COUNT hint of each SCAN of DeletePrefix
+	redisScanCount = 1000
+)
+
+type redisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore returns a Store keeping the responses in Redis with client,
+// the keys of the responses are prefixed with prefix.
+//
+// NOTE:
+//
+//	DeletePrefix scans the whole keyspace of Redis in batches, each of which is unlinked before
+//	scanning the next one, so Redis is not blocked as by KEYS. It is meant for the manual invalidation only.
+func NewRedisStore(client RedisClient, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := s.client.Eval(ctx, redisGetScript, []string{s.prefix + key})
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	return nil, fmt.Errorf("cache: unexpected redis reply %T", reply)
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.client.Eval(ctx, redisSetScript, []string{s.prefix + key}, value, ttl.Milliseconds())
+	return err
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Eval(ctx, redisDeleteScript, []string{s.prefix + key})
+	return err
+}
+
+func (s *redisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	pattern := escapeGlob(s.prefix+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := s.client.Scan(ctx, cursor, pattern, redisScanCount)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err = s.client.Unlink(ctx, keys...); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// escapeGlob escapes the special characters of the pattern of SCAN.
+func escapeGlob(s string) string {
+	return globEscaper.Replace(s)
+}
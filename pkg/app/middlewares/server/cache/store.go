@@ -0,0 +1,122 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// Store stores the cached responses, which can be shared by the replicas of a service.
+type Store interface {
+	// Get returns the value of key, or nil if it does not exist or has expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set sets the value of key, which expires after ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete deletes the value of key.
+	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix deletes the values whose keys start with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type item struct {
+	value    []byte
+	expireAt time.Time
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	items      map[string]*item
+	maxEntries int
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// NewMemoryStore returns a Store keeping the responses in memory, which is not shared by the replicas.
+// If there are maxEntries responses, an arbitrary one is evicted to store a new one. maxEntries<=0 means no limit.
+func NewMemoryStore(maxEntries int) Store {
+	return &memoryStore{
+		items:      make(map[string]*item),
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if it := s.items[key]; it != nil && s.now().Before(it.expireAt) {
+		return it.value, nil
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	if _, ok := s.items[key]; !ok && s.maxEntries > 0 && len(s.items) >= s.maxEntries {
+		for k := range s.items {
+			delete(s.items, k)
+			break
+		}
+	}
+	s.items[key] = &item{value: value, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.items, key)
+		}
+	}
+	return nil
+}
+
+// sweep removes the expired responses.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, it := range s.items {
+		if !now.Before(it.expireAt) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore(2).(*memoryStore)
+	s.now = func() time.Time { return now }
+
+	assert.Nil(t, s.Set(ctx, "a", []byte("1"), time.Second))
+	assert.Nil(t, s.Set(ctx, "b", []byte("2"), time.Minute))
+	v, err := s.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []byte("1"), v)
+
+	now = now.Add(time.Second)
+	v, _ = s.Get(ctx, "a")
+	assert.Nil(t, v)
+
+	// an arbitrary one is evicted
+	assert.Nil(t, s.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.DeepEqual(t, 2, len(s.items))
+
+	// the expired ones are swept
+	now = now.Add(sweepInterval)
+	assert.Nil(t, s.Set(ctx, "d", []byte("4"), time.Minute))
+	assert.DeepEqual(t, 1, len(s.items))
+
+	assert.Nil(t, s.Set(ctx, "d2", []byte("5"), time.Minute))
+	assert.Nil(t, s.DeletePrefix(ctx, "d"))
+	assert.DeepEqual(t, 0, len(s.items))
+}
+
+// fakeRedis interprets the scripts of redisStore with a memory store,
+// and scans a key in each batch.
+type fakeRedis struct {
+	store *memoryStore
+	scans int
+}
+
+func (r *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	switch script {
+	case redisGetScript:
+		v, err := r.store.Get(ctx, keys[0])
+		if v == nil {
+			return nil, err
+		}
+		return string(v), err
+	case redisSetScript:
+		return "OK", r.store.Set(ctx, keys[0], args[0].([]byte), time.Duration(args[1].(int64))*time.Millisecond)
+	case redisDeleteScript:
+		return int64(1), r.store.Delete(ctx, keys[0])
+	}
+	panic("unknown script")
+}
+
+func (r *fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	r.scans++
+	prefix := strings.TrimSuffix(unescapeGlob(match), "*")
+	var all []string
+	for k := range r.store.items {
+		if strings.HasPrefix(k, prefix) {
+			all = append(all, k)
+		}
+	}
+	sort.Strings(all)
+	if len(all) == 0 {
+		return nil, 0, nil
+	}
+	return all[:1], cursor + 1, nil
+}
+
+func (r *fakeRedis) Unlink(ctx context.Context, keys ...string) error {
+	for _, k := range keys {
+		r.store.Delete(ctx, k) //nolint:errcheck
+	}
+	return nil
+}
+
+func unescapeGlob(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\*`, `*`, `\?`, `?`, `\[`, `[`, `\]`, `]`).Replace(s)
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryStore(0).(*memoryStore)
+	r := &fakeRedis{store: mem}
+	s := NewRedisStore(r, "cache:")
+
+	assert.Nil(t, s.Set(ctx, "/a*", []byte("1"), time.Minute))
+	assert.Nil(t, s.Set(ctx, "/a*b", []byte("1"), time.Minute))
+	assert.Nil(t, s.Set(ctx, "/ab", []byte("2"), time.Minute))
+	v, err := s.Get(ctx, "/a*")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []byte("1"), v)
+	_, ok := mem.items["cache:/a*"]
+	assert.True(t, ok)
+
+	v, err = s.Get(ctx, "/c")
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+
+	// the keys are scanned and unlinked in batches
+	assert.Nil(t, s.DeletePrefix(ctx, "/a*"))
+	assert.DeepEqual(t, 3, r.scans)
+	v, _ = s.Get(ctx, "/a*")
+	assert.Nil(t, v)
+	v, _ = s.Get(ctx, "/a*b")
+	assert.Nil(t, v)
+	v, _ = s.Get(ctx, "/ab")
+	assert.DeepEqual(t, []byte("2"), v)
+
+	assert.Nil(t, s.Delete(ctx, "/ab"))
+	v, _ = s.Get(ctx, "/ab")
+	assert.Nil(t, v)
+
+	assert.DeepEqual(t, `cache:/a\*\?\[x\]\\`, escapeGlob(`cache:/a*?[x]\`))
+}
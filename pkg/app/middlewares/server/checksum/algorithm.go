@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+)
+
+// Algorithm is a hash algorithm computing the digest of the body.
+type Algorithm struct {
+	Name string
+	New  func() hash.Hash
+}
+
+var (
+	MD5    = Algorithm{Name: "md5", New: md5.New}
+	SHA1   = Algorithm{Name: "sha1", New: sha1.New}
+	SHA256 = Algorithm{Name: "sha256", New: sha256.New}
+	SHA512 = Algorithm{Name: "sha512", New: sha512.New}
+	CRC32  = Algorithm{Name: "crc32", New: func() hash.Hash { return crc32.NewIEEE() }}
+	CRC32C = Algorithm{Name: "crc32c", New: func() hash.Hash { return crc32.New(castagnoliTable) }}
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Encoding is the text encoding of the digest carried by a header.
+type Encoding int
+
+const (
+	// Base64 is the standard base64 encoding, used by Content-MD5 and most object storage APIs.
+	Base64 Encoding = iota
+	// Hex is the lowercase hexadecimal encoding, decoded case-insensitively.
+	Hex
+)
+
+func (e Encoding) encode(sum []byte) string {
+	if e == Hex {
+		return hex.EncodeToString(sum)
+	}
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+func (e Encoding) decode(s string) ([]byte, error) {
+	if e == Hex {
+		return hex.DecodeString(s)
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// digestHeader is a header carrying the digest of the body.
+type digestHeader struct {
+	header    string
+	algorithm Algorithm
+	encoding  Encoding
+}
+
+func (d *digestHeader) sum(body []byte) []byte {
+	h := d.algorithm.New()
+	h.Write(body) //nolint:errcheck
+	return h.Sum(nil)
+}
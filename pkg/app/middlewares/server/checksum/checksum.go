@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+var (
+	ErrChecksumMissing   = errors.NewPublic("checksum: digest header is missing")
+	ErrChecksumMalformed = errors.NewPublic("checksum: digest header is malformed")
+	ErrChecksumMismatch  = errors.NewPublic("checksum: body does not match the digest")
+	ErrBodyUnreadable    = errors.NewPublic("checksum: failed to read the body")
+)
+
+// New returns a middleware verifying the integrity of the request body against Content-MD5
+// and the configured digest headers, and optionally setting the digests of the response body, e.g.
+//
+//	h.Use(checksum.New(
+//		checksum.WithDigestHeader("X-Amz-Checksum-Sha256", checksum.SHA256, checksum.Base64),
+//		checksum.WithResponseDigest(consts.HeaderContentMD5, checksum.MD5, checksum.Base64),
+//	))
+//
+// NOTE:
+//
+//	The body stream is read into memory before the verification.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		if err := verify(ctx, cfg); err != nil {
+			cfg.errorHandler(c, ctx, err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next(c)
+
+		if len(cfg.responseHeaders) == 0 || ctx.Response.IsBodyStream() {
+			return
+		}
+		body := ctx.Response.Body()
+		for i := range cfg.responseHeaders {
+			d := &cfg.responseHeaders[i]
+			if len(ctx.Response.Header.Peek(d.header)) == 0 {
+				ctx.Response.Header.Set(d.header, d.encoding.encode(d.sum(body)))
+			}
+		}
+	}
+}
+
+func verify(ctx *app.RequestContext, cfg *options) error {
+	var body []byte
+	read, found := false, false
+	for i := range cfg.headers {
+		d := &cfg.headers[i]
+		value := strings.TrimSpace(string(ctx.Request.Header.Peek(d.header)))
+		if value == "" {
+			continue
+		}
+		found = true
+		expected, err := d.encoding.decode(value)
+		if err != nil || len(expected) != d.algorithm.New().Size() {
+			return ErrChecksumMalformed
+		}
+		if !read {
+			if body, err = ctx.Request.BodyE(); err != nil {
+				return ErrBodyUnreadable
+			}
+			read = true
+		}
+		if subtle.ConstantTimeCompare(expected, d.sum(body)) != 1 {
+			return ErrChecksumMismatch
+		}
+	}
+	if found || !cfg.required {
+		return nil
+	}
+	if ctx.Request.IsBodyStream() || len(ctx.Request.Body()) > 0 {
+		return ErrChecksumMissing
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const testBody = "hello world"
+
+func put(e *route.Engine, body string, headers ...ut.Header) *ut.ResponseRecorder {
+	return ut.PerformRequest(e, consts.MethodPut, "/object", &ut.Body{Body: strings.NewReader(body), Len: len(body)}, headers...)
+}
+
+func TestContentMD5(t *testing.T) {
+	sum := md5.Sum([]byte(testBody))
+	contentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New())
+	e.PUT("/object", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, string(ctx.Request.Body()))
+	})
+
+	resp := put(e, testBody, ut.Header{Key: consts.HeaderContentMD5, Value: contentMD5}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, testBody, string(resp.Body()))
+
+	resp = put(e, "hello world!", ut.Header{Key: consts.HeaderContentMD5, Value: contentMD5}).Result()
+	assert.DeepEqual(t, consts.StatusBadRequest, resp.StatusCode())
+	assert.DeepEqual(t, ErrChecksumMismatch.Error(), string(resp.Body()))
+
+	resp = put(e, testBody, ut.Header{Key: consts.HeaderContentMD5, Value: "not-base64"}).Result()
+	assert.DeepEqual(t, consts.StatusBadRequest, resp.StatusCode())
+	assert.DeepEqual(t, ErrChecksumMalformed.Error(), string(resp.Body()))
+
+	// the requests without digest pass by default
+	resp = put(e, testBody).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(WithoutContentMD5()))
+	e.PUT("/object", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, string(ctx.Request.Body()))
+	})
+	resp = put(e, "hello world!", ut.Header{Key: consts.HeaderContentMD5, Value: contentMD5}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+}
+
+func TestDigestHeader(t *testing.T) {
+	sum := sha256.Sum256([]byte(testBody))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(
+		WithDigestHeader("X-Checksum-Sha256", SHA256, Base64),
+		WithDigestHeader("X-Content-Sha256", SHA256, Hex),
+		WithRequired(),
+	))
+	e.PUT("/object", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, string(ctx.Request.Body()))
+	})
+
+	resp := put(e, testBody, ut.Header{Key: "X-Checksum-Sha256", Value: base64.StdEncoding.EncodeToString(sum[:])}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	resp = put(e, testBody, ut.Header{Key: "X-Content-Sha256", Value: strings.ToUpper(hex.EncodeToString(sum[:]))}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+
+	// all the digests present are verified
+	resp = put(e, testBody,
+		ut.Header{Key: "X-Checksum-Sha256", Value: base64.StdEncoding.EncodeToString(sum[:])},
+		ut.Header{Key: "X-Content-Sha256", Value: hex.EncodeToString(sum[:4])},
+	).Result()
+	assert.DeepEqual(t, consts.StatusBadRequest, resp.StatusCode())
+	assert.DeepEqual(t, ErrChecksumMalformed.Error(), string(resp.Body()))
+
+	resp = put(e, testBody).Result()
+	assert.DeepEqual(t, consts.StatusBadRequest, resp.StatusCode())
+	assert.DeepEqual(t, ErrChecksumMissing.Error(), string(resp.Body()))
+
+	// the empty body requires no digest
+	resp = put(e, "").Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+
+	var got error
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(WithRequired(), WithErrorHandler(func(c context.Context, ctx *app.RequestContext, err error) {
+		got = err
+		ctx.AbortWithStatus(consts.StatusPreconditionFailed)
+	})))
+	e.PUT("/object", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, string(ctx.Request.Body()))
+	})
+	resp = put(e, testBody).Result()
+	assert.DeepEqual(t, consts.StatusPreconditionFailed, resp.StatusCode())
+	assert.DeepEqual(t, ErrChecksumMissing, got)
+}
+
+func TestResponseDigest(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(
+		WithResponseDigest(consts.HeaderContentMD5, MD5, Base64),
+		WithResponseDigest("X-Checksum-Crc32c", CRC32C, Hex),
+	))
+	e.PUT("/object", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, string(ctx.Request.Body()))
+	})
+	e.GET("/preset", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Header(consts.HeaderContentMD5, "preset")
+		ctx.String(consts.StatusOK, testBody)
+	})
+
+	resp := put(e, testBody).Result()
+	sum := md5.Sum([]byte(testBody))
+	assert.DeepEqual(t, base64.StdEncoding.EncodeToString(sum[:]), resp.Header.Get(consts.HeaderContentMD5))
+	assert.DeepEqual(t, "c99465aa", resp.Header.Get("X-Checksum-Crc32c"))
+
+	resp = ut.PerformRequest(e, consts.MethodGet, "/preset", nil).Result()
+	assert.DeepEqual(t, "preset", resp.Header.Get(consts.HeaderContentMD5))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		headers         []digestHeader
+		responseHeaders []digestHeader
+		required        bool
+		errorHandler    func(c context.Context, ctx *app.RequestContext, err error)
+	}
+
+	Option func(o *options)
+)
+
+func defaultErrorHandler(c context.Context, ctx *app.RequestContext, err error) {
+	ctx.AbortWithMsg(err.Error(), consts.StatusBadRequest)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		headers:      []digestHeader{{header: consts.HeaderContentMD5, algorithm: MD5, encoding: Base64}},
+		errorHandler: defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithDigestHeader verifies the request body against the digest carried by header,
+// which is computed by alg and encoded by enc, e.g.
+//
+//	checksum.WithDigestHeader("X-Amz-Checksum-Sha256", checksum.SHA256, checksum.Base64)
+//
+// NOTE:
+//
+//	Content-MD5 is verified by default, and all the digest headers present in the request are verified.
+func WithDigestHeader(header string, alg Algorithm, enc Encoding) Option {
+	return func(o *options) {
+		o.headers = append(o.headers, digestHeader{header: header, algorithm: alg, encoding: enc})
+	}
+}
+
+// WithoutContentMD5 skips the verification of Content-MD5.
+func WithoutContentMD5() Option {
+	return func(o *options) {
+		headers := o.headers[:0]
+		for _, h := range o.headers {
+			if h.header != consts.HeaderContentMD5 {
+				headers = append(headers, h)
+			}
+		}
+		o.headers = headers
+	}
+}
+
+// WithRequired rejects the requests with a non-empty body carrying none of the digest headers.
+func WithRequired() Option {
+	return func(o *options) {
+		o.required = true
+	}
+}
+
+// WithResponseDigest sets the digest of the response body computed by alg and encoded by enc
+// into header, unless the handler has set it.
+//
+// NOTE:
+//
+//	The digest is not set for the body stream, since it can not be read without consuming it.
+func WithResponseDigest(header string, alg Algorithm, enc Encoding) Option {
+	return func(o *options) {
+		o.responseHeaders = append(o.responseHeaders, digestHeader{header: header, algorithm: alg, encoding: enc})
+	}
+}
+
+// WithErrorHandler sets the handler of the requests failing the verification,
+// which responds 400 with the error message by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cors provides the middleware handling the cross-origin requests,
+// see https://fetch.spec.whatwg.org/#http-cors-protocol for details.
+package cors
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// New returns a middleware handling the cross-origin requests.
+// The preflight requests are answered by the middleware without calling the next handlers,
+// and the requests from the origins not allowed are aborted with 403.
+//
+// NOTE:
+//
+//	Register it by Use of the engine, so that the preflight requests are answered even if
+//	no OPTIONS route is registered for the path, since the middlewares of the engine
+//	also handle the requests not found or not allowed by the router.
+//	New panics if the options are unsafe, e.g. the credentials are allowed for all origins.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	if err := cfg.validate(); err != nil {
+		panic(err)
+	}
+	allowMethods := strings.Join(cfg.allowMethods, ", ")
+	allowHeaders := strings.Join(cfg.allowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.exposeHeaders, ", ")
+	maxAge := ""
+	if cfg.maxAge > 0 {
+		maxAge = strconv.FormatInt(int64(cfg.maxAge/time.Second), 10)
+	}
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		origin := string(ctx.Request.Header.Peek(consts.HeaderOrigin))
+		if origin == "" {
+			ctx.Next(c)
+			return
+		}
+		if !cfg.isOriginAllowed(origin) {
+			ctx.AbortWithStatus(consts.StatusForbidden)
+			return
+		}
+
+		h := &ctx.Response.Header
+		if cfg.allowAllOrigins {
+			h.Set(consts.HeaderAccessControlAllowOrigin, "*")
+		} else {
+			h.Set(consts.HeaderAccessControlAllowOrigin, origin)
+			h.Add(consts.HeaderVary, consts.HeaderOrigin)
+		}
+		if cfg.allowCredentials {
+			h.Set(consts.HeaderAccessControlAllowCredentials, "true")
+		}
+
+		requestMethod := ctx.Request.Header.Peek(consts.HeaderAccessControlRequestMethod)
+		if !ctx.Request.Header.IsOptions() || len(requestMethod) == 0 {
+			if exposeHeaders != "" {
+				h.Set(consts.HeaderAccessControlExposeHeaders, exposeHeaders)
+			}
+			ctx.Next(c)
+			return
+		}
+
+		// preflight request
+		h.Add(consts.HeaderVary, consts.HeaderAccessControlRequestMethod)
+		h.Add(consts.HeaderVary, consts.HeaderAccessControlRequestHeaders)
+		if !cfg.isMethodAllowed(strings.ToUpper(string(requestMethod))) {
+			ctx.AbortWithStatus(consts.StatusForbidden)
+			return
+		}
+		h.Set(consts.HeaderAccessControlAllowMethods, allowMethods)
+		if cfg.allowAllHeaders {
+			if requestHeaders := ctx.Request.Header.Peek(consts.HeaderAccessControlRequestHeaders); len(requestHeaders) > 0 {
+				h.SetBytesV(consts.HeaderAccessControlAllowHeaders, requestHeaders)
+			}
+		} else if allowHeaders != "" {
+			h.Set(consts.HeaderAccessControlAllowHeaders, allowHeaders)
+		}
+		if maxAge != "" {
+			h.Set(consts.HeaderAccessControlMaxAge, maxAge)
+		}
+		if cfg.allowPrivateNetwork && string(ctx.Request.Header.Peek(consts.HeaderAccessControlRequestPrivateNetwork)) == "true" {
+			h.Set(consts.HeaderAccessControlAllowPrivateNetwork, "true")
+		}
+		ctx.AbortWithStatus(cfg.preflightStatusCode)
+	}
+}
+
+func (o *options) isOriginAllowed(origin string) bool {
+	if o.allowAllOrigins {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	for _, allowed := range o.allowOrigins {
+		if lower == allowed {
+			return true
+		}
+	}
+	for _, p := range o.allowOriginPatterns {
+		if len(lower) >= len(p[0])+len(p[1]) && strings.HasPrefix(lower, p[0]) && strings.HasSuffix(lower, p[1]) {
+			return true
+		}
+	}
+	return o.allowOriginFunc != nil && o.allowOriginFunc(origin)
+}
+
+func (o *options) isMethodAllowed(method string) bool {
+	for _, m := range o.allowMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
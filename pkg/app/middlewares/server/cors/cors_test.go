@@ -0,0 +1,129 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestCORSSimpleRequest(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithExposeHeaders("X-Foo", "X-Bar")))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+
+	// not a cross-origin request
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderAccessControlAllowOrigin))
+
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: consts.HeaderOrigin, Value: "https://example.com"}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "foo", string(resp.Body()))
+	assert.DeepEqual(t, "*", resp.Header.Get(consts.HeaderAccessControlAllowOrigin))
+	assert.DeepEqual(t, "X-Foo, X-Bar", resp.Header.Get(consts.HeaderAccessControlExposeHeaders))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderAccessControlAllowCredentials))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithAllowHeaders("*"),
+		WithMaxAge(time.Hour),
+		WithAllowPrivateNetwork(true),
+	))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+
+	// the preflight request is answered without the OPTIONS route
+	resp := ut.PerformRequest(engine, consts.MethodOptions, "/foo", nil,
+		ut.Header{Key: consts.HeaderOrigin, Value: "https://example.com"},
+		ut.Header{Key: consts.HeaderAccessControlRequestMethod, Value: "put"},
+		ut.Header{Key: consts.HeaderAccessControlRequestHeaders, Value: "X-Foo"},
+		ut.Header{Key: consts.HeaderAccessControlRequestPrivateNetwork, Value: "true"},
+	).Result()
+	assert.DeepEqual(t, consts.StatusNoContent, resp.StatusCode())
+	assert.DeepEqual(t, 0, len(resp.Body()))
+	assert.DeepEqual(t, "*", resp.Header.Get(consts.HeaderAccessControlAllowOrigin))
+	assert.DeepEqual(t, "GET, POST, PUT, PATCH, DELETE, HEAD", resp.Header.Get(consts.HeaderAccessControlAllowMethods))
+	assert.DeepEqual(t, "X-Foo", resp.Header.Get(consts.HeaderAccessControlAllowHeaders))
+	assert.DeepEqual(t, "3600", resp.Header.Get(consts.HeaderAccessControlMaxAge))
+	assert.DeepEqual(t, "true", resp.Header.Get(consts.HeaderAccessControlAllowPrivateNetwork))
+
+	// the method not allowed
+	resp = ut.PerformRequest(engine, consts.MethodOptions, "/foo", nil,
+		ut.Header{Key: consts.HeaderOrigin, Value: "https://example.com"},
+		ut.Header{Key: consts.HeaderAccessControlRequestMethod, Value: "CONNECT"},
+	).Result()
+	assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderAccessControlAllowMethods))
+
+	// the OPTIONS request which is not a preflight one goes to the router
+	resp = ut.PerformRequest(engine, consts.MethodOptions, "/foo", nil,
+		ut.Header{Key: consts.HeaderOrigin, Value: "https://example.com"},
+	).Result()
+	assert.DeepEqual(t, consts.StatusNotFound, resp.StatusCode())
+}
+
+func TestCORSAllowOrigins(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithAllowOrigins("https://example.com", "https://*.foo.com"),
+		WithAllowOriginFunc(func(origin string) bool {
+			return origin == "https://bar.com"
+		}),
+		WithAllowCredentials(true),
+	))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+
+	for origin, allowed := range map[string]bool{
+		"https://example.com":    true,
+		"https://EXAMPLE.com":    true,
+		"https://a.b.foo.com":    true,
+		"https://bar.com":        true,
+		"https://foo.com":        false,
+		"http://a.foo.com":       false,
+		"https://example.com.cn": false,
+		"https://a.foo.com.evil": false,
+		"https://evil.com":       false,
+	} {
+		resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+			ut.Header{Key: consts.HeaderOrigin, Value: origin}).Result()
+		if !allowed {
+			assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+			continue
+		}
+		assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+		assert.DeepEqual(t, origin, resp.Header.Get(consts.HeaderAccessControlAllowOrigin))
+		assert.DeepEqual(t, "true", resp.Header.Get(consts.HeaderAccessControlAllowCredentials))
+		assert.DeepEqual(t, consts.HeaderOrigin, resp.Header.Get(consts.HeaderVary))
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cors
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		allowAllOrigins     bool
+		allowOrigins        []string
+		allowOriginPatterns [][2]string
+		allowOriginFunc     func(origin string) bool
+		allowMethods        []string
+		allowHeaders        []string
+		allowAllHeaders     bool
+		exposeHeaders       []string
+		allowCredentials    bool
+		allowPrivateNetwork bool
+		maxAge              time.Duration
+		preflightStatusCode int
+	}
+
+	Option func(o *options)
+)
+
+var (
+	defaultAllowMethods = []string{
+		consts.MethodGet, consts.MethodPost, consts.MethodPut, consts.MethodPatch,
+		consts.MethodDelete, consts.MethodHead,
+	}
+	defaultAllowHeaders = []string{consts.HeaderOrigin, consts.HeaderContentLength, consts.HeaderContentType}
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		allowAllOrigins:     true,
+		allowMethods:        defaultAllowMethods,
+		allowHeaders:        defaultAllowHeaders,
+		preflightStatusCode: consts.StatusNoContent,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+var errCredentialsAllOrigins = errors.New("cors: credentials can not be allowed for all origins, " +
+	"set the allowed origins by WithAllowOrigins or WithAllowOriginFunc")
+
+// validate reports the combinations of the options which are unsafe.
+func (o *options) validate() error {
+	// any website could make the credentialed requests if all origins were echoed back with credentials
+	if o.allowCredentials && o.allowAllOrigins {
+		return errCredentialsAllOrigins
+	}
+	return nil
+}
+
+// WithAllowOrigins sets the origins allowed to access the resources, "*" by default.
+// An origin may contain a wildcard, e.g. "https://*.example.com", and "*" allows all origins.
+func WithAllowOrigins(origins ...string) Option {
+	return func(o *options) {
+		o.allowAllOrigins = false
+		o.allowOrigins = nil
+		o.allowOriginPatterns = nil
+		for _, origin := range origins {
+			if origin == "*" {
+				o.allowAllOrigins = true
+				continue
+			}
+			origin = strings.ToLower(origin)
+			if i := strings.IndexByte(origin, '*'); i >= 0 {
+				o.allowOriginPatterns = append(o.allowOriginPatterns, [2]string{origin[:i], origin[i+1:]})
+				continue
+			}
+			o.allowOrigins = append(o.allowOrigins, origin)
+		}
+	}
+}
+
+// WithAllowOriginFunc sets the function reporting whether the origin is allowed,
+// which is called if the origin does not match the ones set by WithAllowOrigins.
+//
+// NOTE:
+//
+//	Call WithAllowOrigins without origins to allow only the ones accepted by f.
+func WithAllowOriginFunc(f func(origin string) bool) Option {
+	return func(o *options) {
+		o.allowOriginFunc = f
+	}
+}
+
+// WithAllowMethods sets the methods allowed for the cross-origin requests,
+// GET, POST, PUT, PATCH, DELETE and HEAD by default.
+func WithAllowMethods(methods ...string) Option {
+	return func(o *options) {
+		o.allowMethods = nil
+		for _, m := range methods {
+			o.allowMethods = append(o.allowMethods, strings.ToUpper(m))
+		}
+	}
+}
+
+// WithAllowHeaders sets the headers allowed for the cross-origin requests,
+// Origin, Content-Length and Content-Type by default, and "*" allows the headers requested.
+func WithAllowHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.allowAllHeaders = false
+		o.allowHeaders = nil
+		for _, h := range headers {
+			if h == "*" {
+				o.allowAllHeaders = true
+				continue
+			}
+			o.allowHeaders = append(o.allowHeaders, h)
+		}
+	}
+}
+
+// WithExposeHeaders sets the headers of the responses exposed to the cross-origin requests.
+func WithExposeHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.exposeHeaders = headers
+	}
+}
+
+// WithAllowCredentials sets whether the cross-origin requests can include the credentials,
+// e.g. cookies. The allowed origins must be set explicitly by WithAllowOrigins or WithAllowOriginFunc,
+// otherwise New panics, since any website could make the credentialed requests.
+func WithAllowCredentials(allow bool) Option {
+	return func(o *options) {
+		o.allowCredentials = allow
+	}
+}
+
+// WithAllowPrivateNetwork sets whether the preflight requests from the public network
+// can access the resources in the private network.
+// See https://wicg.github.io/private-network-access/ for details.
+func WithAllowPrivateNetwork(allow bool) Option {
+	return func(o *options) {
+		o.allowPrivateNetwork = allow
+	}
+}
+
+// WithMaxAge sets how long the results of the preflight requests can be cached,
+// which is rounded down to seconds. If maxAge<=0, the header is not sent.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(o *options) {
+		o.maxAge = maxAge
+	}
+}
+
+// WithPreflightStatusCode sets the status code of the responses to the preflight requests,
+// 204 by default.
+func WithPreflightStatusCode(code int) Option {
+	return func(o *options) {
+		o.preflightStatusCode = code
+	}
+}
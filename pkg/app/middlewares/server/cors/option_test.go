@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestDefaultOptions(t *testing.T) {
+	cfg := newOptions()
+	assert.True(t, cfg.allowAllOrigins)
+	assert.DeepEqual(t, defaultAllowMethods, cfg.allowMethods)
+	assert.DeepEqual(t, defaultAllowHeaders, cfg.allowHeaders)
+	assert.DeepEqual(t, consts.StatusNoContent, cfg.preflightStatusCode)
+	assert.False(t, cfg.allowCredentials)
+}
+
+func TestOptions(t *testing.T) {
+	cfg := newOptions(
+		WithAllowOrigins("https://Example.com", "https://*.foo.com"),
+		WithAllowMethods("get", "post"),
+		WithAllowHeaders("X-Foo"),
+		WithMaxAge(time.Minute),
+		WithPreflightStatusCode(consts.StatusOK),
+	)
+	assert.False(t, cfg.allowAllOrigins)
+	assert.DeepEqual(t, []string{"https://example.com"}, cfg.allowOrigins)
+	assert.DeepEqual(t, [][2]string{{"https://", ".foo.com"}}, cfg.allowOriginPatterns)
+	assert.DeepEqual(t, []string{"GET", "POST"}, cfg.allowMethods)
+	assert.DeepEqual(t, []string{"X-Foo"}, cfg.allowHeaders)
+	assert.DeepEqual(t, time.Minute, cfg.maxAge)
+	assert.DeepEqual(t, consts.StatusOK, cfg.preflightStatusCode)
+
+	cfg = newOptions(WithAllowOrigins("*"), WithAllowHeaders("*"))
+	assert.True(t, cfg.allowAllOrigins)
+	assert.True(t, cfg.allowAllHeaders)
+}
+
+func TestValidateOptions(t *testing.T) {
+	assert.DeepEqual(t, errCredentialsAllOrigins, newOptions(WithAllowCredentials(true)).validate())
+	assert.DeepEqual(t, errCredentialsAllOrigins, newOptions(WithAllowOrigins("https://example.com", "*"), WithAllowCredentials(true)).validate())
+	assert.Nil(t, newOptions(WithAllowOrigins("https://example.com"), WithAllowCredentials(true)).validate())
+	assert.Nil(t, newOptions(WithAllowOrigins(), WithAllowOriginFunc(func(string) bool { return true }), WithAllowCredentials(true)).validate())
+	assert.Panic(t, func() { New(WithAllowCredentials(true)) })
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package featureflag
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Gate returns the middleware letting the requests through only if flag is enabled, e.g.
+//
+//	h.GET("/beta", featureflag.Gate(provider, "beta"), betaHandler)
+//
+// NOTE:
+//
+//	flag is disabled if it is unknown to the provider, unless WithDefaultEnabled(true) is given.
+func Gate(provider Provider, flag string, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	return func(c context.Context, ctx *app.RequestContext) {
+		if !enabled(c, provider, flag, cfg.defaultEnabled) {
+			cfg.handler(c, ctx)
+			ctx.Abort()
+			return
+		}
+		ctx.Next(c)
+	}
+}
+
+// Routes returns the middleware disabling the routes whose flags are disabled by provider,
+// which is meant to be used globally, e.g.
+//
+//	provider.Set("DELETE /users/:id", false)
+//	h.Use(featureflag.Routes(provider))
+//
+// NOTE:
+//
+//	The routes whose flags are unknown to the provider are enabled, unless WithDefaultEnabled(false) is given.
+//	The requests not matching any route are let through.
+func Routes(provider Provider, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(append([]Option{WithDefaultEnabled(true)}, opts...)...)
+	return func(c context.Context, ctx *app.RequestContext) {
+		if ctx.FullPath() != "" && !enabled(c, provider, cfg.flagName(ctx), cfg.defaultEnabled) {
+			cfg.handler(c, ctx)
+			ctx.Abort()
+			return
+		}
+		ctx.Next(c)
+	}
+}
+
+func enabled(c context.Context, provider Provider, flag string, defaultEnabled bool) bool {
+	if on, ok := provider.Lookup(c, flag); ok {
+		return on
+	}
+	return defaultEnabled
+}
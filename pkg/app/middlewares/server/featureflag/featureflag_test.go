@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package featureflag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func ok(c context.Context, ctx *app.RequestContext) {
+	ctx.String(consts.StatusOK, "ok")
+}
+
+func perform(e *route.Engine, method, path string) int {
+	return ut.PerformRequest(e, method, path, nil).Result().StatusCode()
+}
+
+func TestGate(t *testing.T) {
+	p := NewMemoryProvider(map[string]bool{"beta": true})
+	e := route.NewEngine(config.NewOptions(nil))
+	e.GET("/beta", Gate(p, "beta"), ok)
+	e.GET("/new", Gate(p, "new"), ok)
+	e.GET("/legacy", Gate(p, "legacy", WithDefaultEnabled(true), WithHandler(func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusForbidden, "disabled")
+	})), ok)
+
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodGet, "/beta"))
+	assert.DeepEqual(t, consts.StatusNotFound, perform(e, consts.MethodGet, "/new"))
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodGet, "/legacy"))
+
+	p.Set("beta", false)
+	p.Set("new", true)
+	p.Set("legacy", false)
+	assert.DeepEqual(t, consts.StatusNotFound, perform(e, consts.MethodGet, "/beta"))
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodGet, "/new"))
+	assert.DeepEqual(t, consts.StatusForbidden, perform(e, consts.MethodGet, "/legacy"))
+
+	p.Delete("new")
+	assert.DeepEqual(t, consts.StatusNotFound, perform(e, consts.MethodGet, "/new"))
+}
+
+func TestRoutes(t *testing.T) {
+	p := NewMemoryProvider(map[string]bool{"DELETE /users/:id": false})
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(Routes(p))
+	e.GET("/users/:id", ok)
+	e.DELETE("/users/:id", ok)
+
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodGet, "/users/1"))
+	assert.DeepEqual(t, consts.StatusNotFound, perform(e, consts.MethodDelete, "/users/1"))
+	p.Set("DELETE /users/:id", true)
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodDelete, "/users/1"))
+
+	provider := ProviderFunc(func(c context.Context, flag string) (bool, bool) {
+		return flag == "users", flag == "users"
+	})
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(Routes(provider, WithDefaultEnabled(false), WithFlagName(func(ctx *app.RequestContext) string {
+		return ctx.Param("name")
+	})))
+	e.GET("/:name", ok)
+	assert.DeepEqual(t, consts.StatusOK, perform(e, consts.MethodGet, "/users"))
+	assert.DeepEqual(t, consts.StatusNotFound, perform(e, consts.MethodGet, "/orders"))
+}
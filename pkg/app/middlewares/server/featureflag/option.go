@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package featureflag
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		defaultEnabled bool
+		flagName       func(ctx *app.RequestContext) string
+		handler        func(c context.Context, ctx *app.RequestContext)
+	}
+
+	Option func(o *options)
+)
+
+func defaultHandler(c context.Context, ctx *app.RequestContext) {
+	ctx.AbortWithStatus(consts.StatusNotFound)
+}
+
+// defaultFlagName names the flag of a route by its method and template, e.g. "GET /users/:id".
+func defaultFlagName(ctx *app.RequestContext) string {
+	return string(ctx.Method()) + " " + ctx.FullPath()
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		flagName: defaultFlagName,
+		handler:  defaultHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithDefaultEnabled sets the state of the flags unknown to the provider.
+// The unknown flags are disabled by Gate and enabled by Routes by default.
+func WithDefaultEnabled(enabled bool) Option {
+	return func(o *options) {
+		o.defaultEnabled = enabled
+	}
+}
+
+// WithFlagName sets the function naming the flag of the request for Routes,
+// which is "<method> <route template>" by default, e.g. "GET /users/:id".
+func WithFlagName(f func(ctx *app.RequestContext) string) Option {
+	return func(o *options) {
+		o.flagName = f
+	}
+}
+
+// WithHandler sets the handler of the requests to the disabled features,
+// which responds 404 by default as if the route does not exist.
+func WithHandler(f func(c context.Context, ctx *app.RequestContext)) Option {
+	return func(o *options) {
+		o.handler = f
+	}
+}
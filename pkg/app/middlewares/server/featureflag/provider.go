@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package featureflag
+
+import (
+	"context"
+	"sync"
+)
+
+// Provider provides the states of the feature flags, which can be backed by
+// a configuration center to switch the features without redeploying.
+type Provider interface {
+	// Lookup returns whether flag is enabled, ok is false if flag is unknown to the provider.
+	Lookup(c context.Context, flag string) (enabled, ok bool)
+}
+
+// ProviderFunc is an adapter to allow the use of ordinary functions as Provider.
+type ProviderFunc func(c context.Context, flag string) (enabled, ok bool)
+
+// Lookup calls f(c, flag).
+func (f ProviderFunc) Lookup(c context.Context, flag string) (enabled, ok bool) {
+	return f(c, flag)
+}
+
+// MemoryProvider is a Provider holding the flags in memory, which is safe for concurrent use.
+type MemoryProvider struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// NewMemoryProvider creates a MemoryProvider with the initial flags.
+func NewMemoryProvider(flags map[string]bool) *MemoryProvider {
+	p := &MemoryProvider{flags: make(map[string]bool, len(flags))}
+	for k, v := range flags {
+		p.flags[k] = v
+	}
+	return p
+}
+
+// Set sets the state of flag.
+func (p *MemoryProvider) Set(flag string, enabled bool) {
+	p.mu.Lock()
+	p.flags[flag] = enabled
+	p.mu.Unlock()
+}
+
+// Delete removes flag, which becomes unknown to the provider.
+func (p *MemoryProvider) Delete(flag string) {
+	p.mu.Lock()
+	delete(p.flags, flag)
+	p.mu.Unlock()
+}
+
+// Lookup implements Provider.
+func (p *MemoryProvider) Lookup(_ context.Context, flag string) (enabled, ok bool) {
+	p.mu.RLock()
+	enabled, ok = p.flags[flag]
+	p.mu.RUnlock()
+	return
+}
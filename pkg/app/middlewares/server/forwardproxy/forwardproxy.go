@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package forwardproxy provides a middleware handling the CONNECT requests, so that the server
+// can act as a forward proxy tunneling the connections of the clients to the targets, e.g.
+//
+//	h := server.Default()
+//	h.Use(forwardproxy.New(
+//		forwardproxy.WithProxyAuth(basic_auth.StaticVerifier(accounts), ""),
+//		forwardproxy.WithPolicy(func(c context.Context, ctx *app.RequestContext, target string) bool {
+//			return strings.HasSuffix(target, ":443")
+//		}),
+//	))
+//
+// The other requests are passed to the next handlers. The middleware must be registered by Use,
+// since CONNECT requests carry the target instead of a path, which match no route.
+package forwardproxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// UserKey is the key of the user authenticated by WithProxyAuth in the context.
+const UserKey = "forwardproxy_user"
+
+// New returns a middleware tunneling the connections of the CONNECT requests to the targets.
+//
+// Once the target is connected, 200 OK is responded, then the connection
+// of the client is hijacked and the data is copied in both directions until either side closes.
+func New(opts ...Option) app.HandlerFunc {
+	o := newOptions(opts...)
+	realm := "Basic realm=" + strconv.Quote(o.realm)
+	return func(c context.Context, ctx *app.RequestContext) {
+		if !ctx.Request.Header.IsConnect() {
+			ctx.Next(c)
+			return
+		}
+
+		// CONNECT requests are in authority-form, see https://www.rfc-editor.org/rfc/rfc7230#section-5.3.3
+		target := string(ctx.Request.Header.RequestURI())
+		if host, port, err := net.SplitHostPort(target); err != nil || host == "" || port == "" {
+			ctx.AbortWithStatus(consts.StatusBadRequest)
+			return
+		}
+
+		if o.verifier != nil {
+			user, password, ok := ctx.Request.ProxyBasicAuth()
+			if !ok || !o.verifier(c, user, password) {
+				ctx.Header(consts.HeaderProxyAuthenticate, realm)
+				ctx.AbortWithStatus(consts.StatusProxyAuthRequired)
+				return
+			}
+			ctx.Set(UserKey, user)
+		}
+
+		if o.policy != nil && !o.policy(c, ctx, target) {
+			o.denyHandler(c, ctx, target)
+			ctx.Abort()
+			return
+		}
+
+		conn, err := o.dialer.DialConnection("tcp", target, o.dialTimeout, nil)
+		if err != nil {
+			hlog.SystemLogger().CtxWarnf(c, "Forward proxy dial error=%v, target=%s", err, target)
+			if isTimeout(err) {
+				ctx.AbortWithStatus(consts.StatusGatewayTimeout)
+				return
+			}
+			ctx.AbortWithStatus(consts.StatusBadGateway)
+			return
+		}
+		// the tunnel is closed by either side instead of timeouts
+		if err = conn.SetReadTimeout(0); err != nil {
+			conn.Close() //nolint:errcheck
+			ctx.AbortWithStatus(consts.StatusBadGateway)
+			return
+		}
+
+		// neither Content-Length nor Transfer-Encoding is allowed in the 2xx response to CONNECT
+		ctx.Response.SkipBody = true
+		ctx.SetStatusCode(consts.StatusOK)
+		ctx.Hijack(func(c network.Conn) {
+			network.Tunnel(c, conn) //nolint:errcheck
+			conn.Close()            //nolint:errcheck
+		})
+		ctx.Abort()
+	}
+}
+
+// GetUser returns the user authenticated by WithProxyAuth.
+func GetUser(ctx *app.RequestContext) (string, bool) {
+	user, ok := ctx.Get(UserKey)
+	if !ok {
+		return "", false
+	}
+	s, ok := user.(string)
+	return s, ok
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, errs.ErrDialTimeout) || errors.Is(err, errs.ErrTimeout) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
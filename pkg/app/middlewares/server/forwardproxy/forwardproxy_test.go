@@ -0,0 +1,137 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package forwardproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/basic_auth"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func readHeader(t *testing.T, conn network.Conn) string {
+	var header []byte
+	for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+		b, err := conn.ReadByte()
+		assert.Nil(t, err)
+		header = append(header, b)
+	}
+	return string(header)
+}
+
+func connect(t *testing.T, target, auth string) (network.Conn, string) {
+	conn, err := standard.NewDialer().DialConnection("tcp", "127.0.0.1:9248", time.Second, nil)
+	assert.Nil(t, err)
+	req := "CONNECT " + target + " HTTP/1.1\r\nHost: " + target + "\r\n"
+	if auth != "" {
+		req += "Proxy-Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(auth)) + "\r\n"
+	}
+	_, err = conn.Write([]byte(req + "\r\n"))
+	assert.Nil(t, err)
+	return conn, readHeader(t, conn)
+}
+
+func TestForwardProxy(t *testing.T) {
+	target := server.New(server.WithHostPorts("127.0.0.1:9249"), server.WithTransport(standard.NewTransporter))
+	target.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "pong")
+	})
+	go target.Spin()
+
+	var (
+		mu    sync.Mutex
+		users []string
+	)
+	h := server.New(server.WithHostPorts("127.0.0.1:9248"), server.WithTransport(standard.NewTransporter))
+	h.Use(New(
+		WithProxyAuth(basic_auth.StaticVerifier(basic_auth.Accounts{"foo": "bar"}), ""),
+		WithPolicy(func(c context.Context, ctx *app.RequestContext, target string) bool {
+			user, _ := GetUser(ctx)
+			mu.Lock()
+			users = append(users, user)
+			mu.Unlock()
+			return strings.HasPrefix(target, "127.0.0.1:")
+		}),
+		WithDialTimeout(time.Second),
+	))
+	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "local")
+	})
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	conn, header := connect(t, "127.0.0.1:9249", "")
+	conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 407 "))
+	assert.True(t, strings.Contains(header, "\r\nProxy-Authenticate: Basic realm=\"Proxy\"\r\n"))
+
+	conn, header = connect(t, "127.0.0.1:9249", "foo:baz")
+	conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 407 "))
+
+	conn, header = connect(t, "localhost:9249", "foo:bar")
+	conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 403 "))
+
+	conn, header = connect(t, "127.0.0.1", "foo:bar")
+	conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 400 "))
+
+	conn, header = connect(t, "127.0.0.1:9250", "foo:bar")
+	conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 502 "))
+
+	conn, header = connect(t, "127.0.0.1:9249", "foo:bar")
+	defer conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 200 OK\r\n"))
+	assert.False(t, strings.Contains(header, "Content-Length"))
+	mu.Lock()
+	assert.DeepEqual(t, []string{"foo", "foo", "foo"}, users)
+	mu.Unlock()
+
+	for i := 0; i < 2; i++ {
+		_, err := conn.Write([]byte("GET /ping HTTP/1.1\r\nHost: 127.0.0.1:9249\r\n\r\n"))
+		assert.Nil(t, err)
+		header = readHeader(t, conn)
+		assert.True(t, strings.HasPrefix(header, "HTTP/1.1 200 OK\r\n"))
+		body, err := conn.ReadBinary(4)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "pong", string(body))
+	}
+
+	// the other requests are passed to the next handlers
+	conn2, err := standard.NewDialer().DialConnection("tcp", "127.0.0.1:9248", time.Second, nil)
+	assert.Nil(t, err)
+	defer conn2.Close()
+	_, err = conn2.Write([]byte("GET /ping HTTP/1.1\r\nHost: 127.0.0.1:9248\r\n\r\n"))
+	assert.Nil(t, err)
+	readHeader(t, conn2)
+	body, err := conn2.ReadBinary(5)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "local", string(body))
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package forwardproxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/basic_auth"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/dialer"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const defaultRealm = "Proxy"
+
+type (
+	options struct {
+		verifier    basic_auth.Verifier
+		realm       string
+		policy      Policy
+		denyHandler func(c context.Context, ctx *app.RequestContext, target string)
+		dialer      network.Dialer
+		dialTimeout time.Duration
+	}
+
+	Option func(o *options)
+)
+
+// Policy reports whether the client is allowed to connect to target, which is host:port.
+// The user authenticated by WithProxyAuth can be got by GetUser.
+type Policy func(c context.Context, ctx *app.RequestContext, target string) bool
+
+func defaultDenyHandler(c context.Context, ctx *app.RequestContext, target string) {
+	ctx.AbortWithStatus(consts.StatusForbidden)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		realm:       defaultRealm,
+		denyHandler: defaultDenyHandler,
+		dialTimeout: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.dialer == nil {
+		cfg.dialer = dialer.DefaultDialer()
+	}
+
+	return cfg
+}
+
+// WithProxyAuth requires the clients to authenticate by the Proxy-Authorization header in Basic scheme,
+// which is verified by verifier. The clients failing to authenticate are responded with
+// 407 Proxy Authentication Required. If the realm is empty, "Proxy" will be used by default.
+func WithProxyAuth(verifier basic_auth.Verifier, realm string) Option {
+	return func(o *options) {
+		o.verifier = verifier
+		if realm != "" {
+			o.realm = realm
+		}
+	}
+}
+
+// WithPolicy sets the policy deciding which targets the clients can connect to, which is checked
+// after the authentication. All the targets are allowed by default.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithDenyHandler sets the handler of the requests denied by the policy,
+// which responds 403 Forbidden by default.
+func WithDenyHandler(f func(c context.Context, ctx *app.RequestContext, target string)) Option {
+	return func(o *options) {
+		o.denyHandler = f
+	}
+}
+
+// WithDialer sets the dialer connecting to the targets, the default dialer is used if not set.
+func WithDialer(d network.Dialer) Option {
+	return func(o *options) {
+		o.dialer = d
+	}
+}
+
+// WithDialTimeout sets the timeout of connecting to the targets, which is 10s by default.
+func WithDialTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = t
+	}
+}
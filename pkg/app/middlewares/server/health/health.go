@@ -0,0 +1,227 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+var errCheckTimeout = errors.NewPublic("check timed out")
+
+// Check reports the health of a component, it should return in time once ctx is done.
+type Check func(ctx context.Context) error
+
+// CheckResult is the result of a check.
+type CheckResult struct {
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// Report is the response of the health endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name    string
+	timeout time.Duration
+	check   Check
+}
+
+// Checker aggregates the checks registered by the components into the liveness and readiness endpoints, e.g.
+//
+//	checker := health.NewChecker()
+//	checker.AddReadinessCheck("db", 500*time.Millisecond, func(ctx context.Context) error {
+//		return db.PingContext(ctx)
+//	})
+//	h.GET("/healthz", checker.LivenessHandler())
+//	h.GET("/readyz", checker.ReadinessHandler())
+//	h.OnShutdown = append(h.OnShutdown, checker.Drain)
+//
+// The endpoints respond 200 if all the checks pass, or 503 otherwise, with a Report in JSON.
+type Checker struct {
+	opts *options
+
+	mu        sync.RWMutex
+	liveness  []check
+	readiness []check
+
+	draining int32
+}
+
+// NewChecker creates a checker without any check.
+func NewChecker(opts ...Option) *Checker {
+	return &Checker{opts: newOptions(opts...)}
+}
+
+// AddLivenessCheck registers the check reporting whether the process should be restarted,
+// which is also run by the readiness endpoint. The default timeout is used if timeout<=0.
+func (c *Checker) AddLivenessCheck(name string, timeout time.Duration, fn Check) {
+	c.mu.Lock()
+	c.liveness = appendCheck(c.liveness, check{name: name, timeout: timeout, check: fn})
+	c.mu.Unlock()
+}
+
+// AddReadinessCheck registers the check reporting whether the process can serve the requests.
+// The default timeout is used if timeout<=0.
+func (c *Checker) AddReadinessCheck(name string, timeout time.Duration, fn Check) {
+	c.mu.Lock()
+	c.readiness = appendCheck(c.readiness, check{name: name, timeout: timeout, check: fn})
+	c.mu.Unlock()
+}
+
+// appendCheck replaces the check of the same name, or appends ch.
+func appendCheck(checks []check, ch check) []check {
+	for i := range checks {
+		if checks[i].name == ch.name {
+			ret := append([]check{}, checks...)
+			ret[i] = ch
+			return ret
+		}
+	}
+	return append(checks[:len(checks):len(checks)], ch)
+}
+
+// Drain makes the readiness endpoint report StatusDraining, so that the load balancers
+// stop routing new requests to the process while the in-flight ones are finishing.
+// Its signature allows it to be used as an OnShutdown hook of the server.
+func (c *Checker) Drain(ctx context.Context) {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+// IsDraining reports whether Drain has been called.
+func (c *Checker) IsDraining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Liveness runs the liveness checks concurrently.
+func (c *Checker) Liveness(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.liveness
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// Readiness runs the liveness and readiness checks concurrently,
+// the checks are not run while draining.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	if c.IsDraining() {
+		return Report{Status: StatusDraining}
+	}
+	c.mu.RLock()
+	checks := append(append(make([]check, 0, len(c.liveness)+len(c.readiness)), c.liveness...), c.readiness...)
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// LivenessHandler returns the handler of the liveness endpoint, e.g. /healthz or /livez.
+func (c *Checker) LivenessHandler() app.HandlerFunc {
+	return func(ctx context.Context, rc *app.RequestContext) {
+		c.respond(rc, c.Liveness(ctx))
+	}
+}
+
+// ReadinessHandler returns the handler of the readiness endpoint, e.g. /readyz.
+func (c *Checker) ReadinessHandler() app.HandlerFunc {
+	return func(ctx context.Context, rc *app.RequestContext) {
+		c.respond(rc, c.Readiness(ctx))
+	}
+}
+
+func (c *Checker) respond(ctx *app.RequestContext, report Report) {
+	code := consts.StatusOK
+	if report.Status != StatusOK {
+		code = consts.StatusServiceUnavailable
+	}
+	if c.opts.hideDetails {
+		report.Checks = nil
+	}
+	ctx.Header(consts.HeaderCacheControl, "no-store")
+	ctx.JSON(code, report)
+}
+
+func (c *Checker) run(ctx context.Context, checks []check) Report {
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.runCheck(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, ch := range checks {
+		report.Checks[ch.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// runCheck returns once the check finishes or times out, the check ignoring the context
+// finishes in the background.
+func (c *Checker) runCheck(ctx context.Context, ch check) CheckResult {
+	timeout := ch.timeout
+	if timeout <= 0 {
+		timeout = c.opts.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.NewPublicf("check panicked: %v", r)
+			}
+		}()
+		done <- ch.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errCheckTimeout
+	}
+	result := CheckResult{Status: StatusOK, Duration: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
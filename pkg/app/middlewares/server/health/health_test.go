@@ -0,0 +1,113 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func ok(ctx context.Context) error {
+	return nil
+}
+
+func perform(t *testing.T, e *route.Engine, path string) (int, Report) {
+	resp := ut.PerformRequest(e, consts.MethodGet, path, nil).Result()
+	var report Report
+	assert.Nil(t, json.Unmarshal(resp.Body(), &report))
+	assert.DeepEqual(t, "no-store", resp.Header.Get(consts.HeaderCacheControl))
+	return resp.StatusCode(), report
+}
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker(WithTimeout(50 * time.Millisecond))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.GET("/healthz", checker.LivenessHandler())
+	e.GET("/readyz", checker.ReadinessHandler())
+
+	code, report := perform(t, e, "/readyz")
+	assert.DeepEqual(t, consts.StatusOK, code)
+	assert.DeepEqual(t, StatusOK, report.Status)
+
+	var dbErr error
+	checker.AddLivenessCheck("goroutines", 0, ok)
+	checker.AddReadinessCheck("db", time.Second, func(ctx context.Context) error { return dbErr })
+	checker.AddReadinessCheck("cache", 0, ok)
+
+	code, report = perform(t, e, "/readyz")
+	assert.DeepEqual(t, consts.StatusOK, code)
+	assert.DeepEqual(t, 3, len(report.Checks))
+
+	dbErr = errors.New("connection refused")
+	code, report = perform(t, e, "/readyz")
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, code)
+	assert.DeepEqual(t, StatusFail, report.Status)
+	assert.DeepEqual(t, StatusFail, report.Checks["db"].Status)
+	assert.DeepEqual(t, "connection refused", report.Checks["db"].Error)
+	assert.DeepEqual(t, StatusOK, report.Checks["cache"].Status)
+
+	// the readiness checks are not run by the liveness endpoint
+	code, report = perform(t, e, "/healthz")
+	assert.DeepEqual(t, consts.StatusOK, code)
+	assert.DeepEqual(t, 1, len(report.Checks))
+
+	// the check of the same name is replaced
+	checker.AddReadinessCheck("db", 0, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	checker.AddReadinessCheck("cache", 0, func(ctx context.Context) error { panic("boom") })
+	start := time.Now()
+	code, report = perform(t, e, "/readyz")
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, code)
+	assert.DeepEqual(t, errCheckTimeout.Error(), report.Checks["db"].Error)
+	assert.DeepEqual(t, "check panicked: boom", report.Checks["cache"].Error)
+}
+
+func TestDrain(t *testing.T) {
+	checker := NewChecker(WithHideDetails())
+	checker.AddLivenessCheck("ok", 0, ok)
+	e := route.NewEngine(config.NewOptions(nil))
+	e.GET("/healthz", checker.LivenessHandler())
+	e.GET("/readyz", checker.ReadinessHandler())
+
+	code, report := perform(t, e, "/readyz")
+	assert.DeepEqual(t, consts.StatusOK, code)
+	assert.Nil(t, report.Checks)
+
+	e.OnShutdown = append(e.OnShutdown, checker.Drain)
+	e.OnShutdown[0](context.Background())
+	assert.True(t, checker.IsDraining())
+
+	code, report = perform(t, e, "/readyz")
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, code)
+	assert.DeepEqual(t, StatusDraining, report.Status)
+
+	// the liveness is not affected by draining
+	code, _ = perform(t, e, "/healthz")
+	assert.DeepEqual(t, consts.StatusOK, code)
+}
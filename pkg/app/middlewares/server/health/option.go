@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import "time"
+
+const defaultTimeout = time.Second
+
+type (
+	options struct {
+		timeout     time.Duration
+		hideDetails bool
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		timeout: defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithTimeout sets the default timeout of the checks, which is 1s by default.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithHideDetails hides the results of the checks from the responses, which only carry the overall status.
+// It prevents the errors of the checks from leaking through the public endpoints.
+func WithHideDetails() Option {
+	return func(o *options) {
+		o.hideDetails = true
+	}
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type message struct {
+	text   string
+	plural map[PluralCategory]string
+}
+
+// Bundle holds the messages of the supported languages.
+type Bundle struct {
+	defaultLang string
+
+	mu       sync.RWMutex
+	langs    map[string]string
+	messages map[string]map[string]*message
+}
+
+// NewBundle creates a bundle falling back to defaultLang for the requests
+// asking for no supported language, and for the messages missing in a language.
+func NewBundle(defaultLang string) *Bundle {
+	b := &Bundle{
+		defaultLang: normalize(defaultLang),
+		langs:       make(map[string]string),
+		messages:    make(map[string]map[string]*message),
+	}
+	b.langs[b.defaultLang] = defaultLang
+	return b
+}
+
+func (b *Bundle) add(lang, key string, m *message) {
+	tag := normalize(lang)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.langs[tag]; !ok {
+		b.langs[tag] = lang
+	}
+	if b.messages[tag] == nil {
+		b.messages[tag] = make(map[string]*message)
+	}
+	b.messages[tag][key] = m
+}
+
+// AddMessages adds the messages of lang by keys.
+func (b *Bundle) AddMessages(lang string, messages map[string]string) {
+	for key, text := range messages {
+		b.add(lang, key, &message{text: text})
+	}
+}
+
+// AddPluralMessage adds the message of lang by key in plural forms,
+// the form of PluralOther is used if the form of a category is missing.
+func (b *Bundle) AddPluralMessage(lang, key string, forms map[PluralCategory]string) {
+	plural := make(map[PluralCategory]string, len(forms))
+	for c, text := range forms {
+		plural[c] = text
+	}
+	b.add(lang, key, &message{plural: plural})
+}
+
+// LoadMessages loads the messages of lang from the JSON data, e.g.
+//
+//	{
+//		"hello": "Hello, %s!",
+//		"user": {"not_found": "User %d is not found."},
+//		"apples": {"one": "%d apple", "other": "%d apples"}
+//	}
+//
+// The keys of the nested objects are joined by ".", e.g. "user.not_found",
+// and the objects whose keys are all plural categories are loaded as plural messages.
+func (b *Bundle) LoadMessages(lang string, data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("i18n: failed to load messages of %s: %w", lang, err)
+	}
+	return b.loadObject(lang, "", raw)
+}
+
+func (b *Bundle) loadObject(lang, prefix string, obj map[string]interface{}) error {
+	for k, v := range obj {
+		key := prefix + k
+		switch x := v.(type) {
+		case string:
+			b.add(lang, key, &message{text: x})
+		case map[string]interface{}:
+			if forms, ok := pluralForms(x); ok {
+				b.AddPluralMessage(lang, key, forms)
+				continue
+			}
+			if err := b.loadObject(lang, key+".", x); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("i18n: message %s of %s must be a string or an object", key, lang)
+		}
+	}
+	return nil
+}
+
+func pluralForms(obj map[string]interface{}) (map[PluralCategory]string, bool) {
+	if _, ok := obj[string(PluralOther)]; !ok {
+		return nil, false
+	}
+	forms := make(map[PluralCategory]string, len(obj))
+	for k, v := range obj {
+		text, ok := v.(string)
+		if !ok || !isPluralCategory(k) {
+			return nil, false
+		}
+		forms[PluralCategory(k)] = text
+	}
+	return forms, true
+}
+
+// LoadFS loads the messages from the JSON files in dir of fsys, which are named by
+// their languages, e.g. "locales/en.json" and "locales/zh-CN.json", e.g.
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	bundle := i18n.NewBundle("en")
+//	err := bundle.LoadFS(locales, "locales")
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: failed to read %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: failed to read %s: %w", e.Name(), err)
+		}
+		if err = b.LoadMessages(strings.TrimSuffix(e.Name(), ".json"), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Languages returns the sorted languages which have messages, including the default one.
+func (b *Bundle) Languages() []string {
+	b.mu.RLock()
+	langs := make([]string, 0, len(b.langs))
+	for _, lang := range b.langs {
+		langs = append(langs, lang)
+	}
+	b.mu.RUnlock()
+	sort.Strings(langs)
+	return langs
+}
+
+// Match returns the first supported language of langs in order of preference, or the default language.
+// A language matches the supported one which is equal to it, its parent, or shares its base language,
+// e.g. "en-GB" matches "en-GB", "en" and "en-US" in turn.
+func (b *Bundle) Match(langs ...string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, lang := range langs {
+		tag := normalize(lang)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		for t := tag; t != ""; t = parent(t) {
+			if lang, ok := b.langs[t]; ok {
+				return lang
+			}
+		}
+		base := tag
+		if i := strings.IndexByte(tag, '-'); i > 0 {
+			base = tag[:i]
+		}
+		var matched []string
+		for t, lang := range b.langs {
+			if strings.HasPrefix(t, base+"-") {
+				matched = append(matched, lang)
+			}
+		}
+		if len(matched) > 0 {
+			sort.Strings(matched)
+			return matched[0]
+		}
+	}
+	return b.langs[b.defaultLang]
+}
+
+// Localizer returns the localizer translating the messages into lang, which falls back to
+// the parents of lang and then the default language for the missing messages.
+func (b *Bundle) Localizer(lang string) *Localizer {
+	l := &Localizer{bundle: b, lang: lang}
+	for t := normalize(lang); t != ""; t = parent(t) {
+		l.fallbacks = append(l.fallbacks, t)
+	}
+	if l.fallbacks == nil || l.fallbacks[len(l.fallbacks)-1] != b.defaultLang {
+		l.fallbacks = append(l.fallbacks, b.defaultLang)
+	}
+	return l
+}
+
+func (b *Bundle) lookup(tags []string, key string) (string, *message) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, t := range tags {
+		if m, ok := b.messages[t][key]; ok {
+			return t, m
+		}
+	}
+	return "", nil
+}
+
+// Localizer translates the messages into a language, it implements app.Translator.
+type Localizer struct {
+	bundle    *Bundle
+	lang      string
+	fallbacks []string
+}
+
+// Lang returns the language of l.
+func (l *Localizer) Lang() string {
+	return l.lang
+}
+
+// Translate returns the message of key formatted by fmt.Sprintf with args,
+// or key if the message is not found in any language.
+// The plural form of the message is selected by the first integer of args,
+// or PluralOther if there is none.
+//
+// NOTE:
+//
+//	The message containing no verb is returned as is, so that the forms like "an apple"
+//	do not have to consume the args.
+func (l *Localizer) Translate(key string, args ...interface{}) string {
+	tag, m := l.bundle.lookup(l.fallbacks, key)
+	if m == nil {
+		return key
+	}
+	text := m.text
+	if m.plural != nil {
+		category := PluralOther
+		if n, ok := firstInt(args); ok {
+			category = getPluralRule(tag)(n)
+		}
+		var ok bool
+		if text, ok = m.plural[category]; !ok {
+			text = m.plural[PluralOther]
+		}
+	}
+	if len(args) == 0 || !strings.Contains(text, "%") {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+func firstInt(args []interface{}) (int, bool) {
+	for _, arg := range args {
+		switch n := arg.(type) {
+		case int:
+			return n, true
+		case int8:
+			return int(n), true
+		case int16:
+			return int(n), true
+		case int32:
+			return int(n), true
+		case int64:
+			return int(n), true
+		case uint:
+			return int(n), true
+		case uint8:
+			return int(n), true
+		case uint16:
+			return int(n), true
+		case uint32:
+			return int(n), true
+		case uint64:
+			return int(n), true
+		}
+	}
+	return 0, false
+}
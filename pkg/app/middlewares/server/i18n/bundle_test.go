@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+var testLocales = fstest.MapFS{
+	"locales/en.json": {Data: []byte(`{
+		"hello": "Hello, %s!",
+		"bye": "Bye!",
+		"user": {"not_found": "User %d is not found."},
+		"apples": {"one": "an apple", "other": "%d apples"}
+	}`)},
+	"locales/zh-CN.json": {Data: []byte(`{"hello": "你好，%s！", "apples": {"other": "%d 个苹果"}}`)},
+	"locales/ru.json":    {Data: []byte(`{"apples": {"one": "%d яблоко", "few": "%d яблока", "many": "%d яблок", "other": "%d яблока"}}`)},
+	"locales/README.md":  {Data: []byte("ignored")},
+}
+
+func newTestBundle(t *testing.T) *Bundle {
+	b := NewBundle("en")
+	assert.Nil(t, b.LoadFS(testLocales, "locales"))
+	return b
+}
+
+func TestBundle(t *testing.T) {
+	b := newTestBundle(t)
+	assert.DeepEqual(t, []string{"en", "ru", "zh-CN"}, b.Languages())
+
+	en := b.Localizer("en")
+	assert.DeepEqual(t, "Hello, hertz!", en.Translate("hello", "hertz"))
+	assert.DeepEqual(t, "Bye!", en.Translate("bye"))
+	assert.DeepEqual(t, "User 1 is not found.", en.Translate("user.not_found", 1))
+	assert.DeepEqual(t, "an apple", en.Translate("apples", 1))
+	assert.DeepEqual(t, "2 apples", en.Translate("apples", 2))
+	assert.DeepEqual(t, "missing", en.Translate("missing", 1))
+
+	// the missing messages fall back to the default language
+	zh := b.Localizer("zh-CN")
+	assert.DeepEqual(t, "zh-CN", zh.Lang())
+	assert.DeepEqual(t, "你好，hertz！", zh.Translate("hello", "hertz"))
+	assert.DeepEqual(t, "1 个苹果", zh.Translate("apples", 1))
+	assert.DeepEqual(t, "Bye!", zh.Translate("bye"))
+
+	ru := b.Localizer("ru")
+	for n, text := range map[int]string{1: "1 яблоко", 3: "3 яблока", 5: "5 яблок", 11: "11 яблок", 21: "21 яблоко", 22: "22 яблока"} {
+		assert.DeepEqual(t, text, ru.Translate("apples", n))
+	}
+
+	b.AddMessages("en-GB", map[string]string{"bye": "Cheerio!"})
+	gb := b.Localizer("en-GB")
+	assert.DeepEqual(t, "Cheerio!", gb.Translate("bye"))
+	assert.DeepEqual(t, "Hello, hertz!", gb.Translate("hello", "hertz"))
+
+	assert.NotNil(t, b.LoadMessages("en", []byte(`{"bad": 1}`)))
+	assert.NotNil(t, b.LoadMessages("en", []byte(`not json`)))
+	assert.NotNil(t, b.LoadFS(testLocales, "missing"))
+}
+
+func TestMatch(t *testing.T) {
+	b := newTestBundle(t)
+	for _, tc := range []struct {
+		langs []string
+		want  string
+	}{
+		{nil, "en"},
+		{[]string{"fr", "*"}, "en"},
+		{[]string{"zh_cn"}, "zh-CN"},
+		{[]string{"zh"}, "zh-CN"},
+		{[]string{"zh-Hans-CN"}, "zh-CN"},
+		{[]string{"en-US"}, "en"},
+		{[]string{"de", "ru-RU", "en"}, "ru"},
+	} {
+		assert.DeepEqual(t, tc.want, b.Match(tc.langs...))
+	}
+}
+
+func TestPluralRules(t *testing.T) {
+	for _, tc := range []struct {
+		lang string
+		n    int
+		want PluralCategory
+	}{
+		{"en", 0, PluralOther},
+		{"en-US", 1, PluralOne},
+		{"fr", 0, PluralOne},
+		{"zh", 1, PluralOther},
+		{"pl", 1, PluralOne},
+		{"pl", 22, PluralFew},
+		{"pl", 12, PluralMany},
+		{"cs", 3, PluralFew},
+		{"cs", 5, PluralOther},
+		{"ar", 0, PluralZero},
+		{"ar", 2, PluralTwo},
+		{"ar", 103, PluralFew},
+		{"ar", 111, PluralMany},
+		{"ar", 100, PluralOther},
+	} {
+		assert.DeepEqual(t, tc.want, getPluralRule(normalize(tc.lang))(tc.n))
+	}
+
+	RegisterPluralRule("pt-PT", pluralZeroOne)
+	assert.DeepEqual(t, PluralOne, getPluralRule("pt-pt")(0))
+	assert.DeepEqual(t, PluralOther, getPluralRule("pt-br")(0))
+}
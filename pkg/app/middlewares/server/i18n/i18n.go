@@ -0,0 +1,113 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// New returns a middleware detecting the language of the request from the query, the cookie
+// and Accept-Language in turn, and setting the localizer of the language into the context, e.g.
+//
+//	h.Use(i18n.New(bundle))
+//	h.GET("/apples", func(c context.Context, ctx *app.RequestContext) {
+//		ctx.String(consts.StatusOK, ctx.T("apples", 3))
+//	})
+//
+// The templates can translate the messages by the T passed in the data:
+//
+//	ctx.HTML(consts.StatusOK, "index.tmpl", utils.H{"T": ctx.T})
+//	{{ call .T "hello" .Name }}
+func New(bundle *Bundle, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		var langs []string
+		if cfg.queryKey != "" {
+			if lang := ctx.Query(cfg.queryKey); lang != "" {
+				langs = append(langs, lang)
+			}
+		}
+		if cfg.cookieKey != "" {
+			if lang := ctx.Cookie(cfg.cookieKey); len(lang) > 0 {
+				langs = append(langs, string(lang))
+			}
+		}
+		langs = append(langs, ParseAcceptLanguage(string(ctx.GetHeader(consts.HeaderAcceptLanguage)))...)
+
+		lang := bundle.Match(langs...)
+		ctx.SetTranslator(bundle.Localizer(lang))
+		if cfg.contentLanguage {
+			ctx.Header(consts.HeaderContentLanguage, lang)
+		}
+		ctx.Next(c)
+	}
+}
+
+// Lang returns the language detected by the middleware, or "" if the middleware is not used.
+func Lang(ctx *app.RequestContext) string {
+	if l, ok := ctx.Translator().(*Localizer); ok {
+		return l.Lang()
+	}
+	return ""
+}
+
+// ParseAcceptLanguage returns the languages of the Accept-Language header in order of preference,
+// the languages with q=0 are excluded.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var ws []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params := part, ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			lang, params = part[:i], part[i+1:]
+		}
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			ws = append(ws, weighted{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(ws, func(i, j int) bool {
+		return ws[i].q > ws[j].q
+	})
+	langs := make([]string, len(ws))
+	for i, w := range ws {
+		langs[i] = w.lang
+	}
+	return langs
+}
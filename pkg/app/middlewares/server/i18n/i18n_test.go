@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestI18n(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(newTestBundle(t), WithContentLanguage()))
+	e.GET("/hello", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, Lang(ctx)+":"+ctx.T("hello", "hertz"))
+	})
+
+	for _, tc := range []struct {
+		url     string
+		headers []ut.Header
+		want    string
+	}{
+		{"/hello", nil, "en:Hello, hertz!"},
+		{"/hello", []ut.Header{{Key: consts.HeaderAcceptLanguage, Value: "fr;q=0.9, zh-CN;q=0.8, en;q=0.5"}}, "zh-CN:你好，hertz！"},
+		{"/hello", []ut.Header{{Key: consts.HeaderCookie, Value: "lang=zh"}, {Key: consts.HeaderAcceptLanguage, Value: "en"}}, "zh-CN:你好，hertz！"},
+		{"/hello?lang=en", []ut.Header{{Key: consts.HeaderCookie, Value: "lang=zh"}}, "en:Hello, hertz!"},
+		{"/hello?lang=fr", []ut.Header{{Key: consts.HeaderCookie, Value: "lang=zh"}}, "zh-CN:你好，hertz！"},
+	} {
+		resp := ut.PerformRequest(e, consts.MethodGet, tc.url, nil, tc.headers...).Result()
+		assert.DeepEqual(t, tc.want, string(resp.Body()))
+		assert.DeepEqual(t, strings.SplitN(tc.want, ":", 2)[0], resp.Header.Get(consts.HeaderContentLanguage))
+	}
+
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(newTestBundle(t), WithQueryKey(""), WithCookieKey("")))
+	e.GET("/hello", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, ctx.T("hello", "hertz"))
+	})
+	resp := ut.PerformRequest(e, consts.MethodGet, "/hello?lang=zh", nil, ut.Header{Key: consts.HeaderCookie, Value: "lang=zh"}).Result()
+	assert.DeepEqual(t, "Hello, hertz!", string(resp.Body()))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderContentLanguage))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.DeepEqual(t, []string{}, ParseAcceptLanguage(""))
+	assert.DeepEqual(t, []string{"da", "en-gb", "en"}, ParseAcceptLanguage("da, en-gb;q=0.8, en;q=0.7"))
+	assert.DeepEqual(t, []string{"en", "de", "*"}, ParseAcceptLanguage("*;q=0.1, de;q=0.5, fr;q=0, en, ;q=1"))
+}
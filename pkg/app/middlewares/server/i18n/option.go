@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+type (
+	options struct {
+		queryKey        string
+		cookieKey       string
+		contentLanguage bool
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		queryKey:  "lang",
+		cookieKey: "lang",
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithQueryKey sets the query parameter carrying the language, which is "lang" by default.
+// The query is not checked if key is empty.
+func WithQueryKey(key string) Option {
+	return func(o *options) {
+		o.queryKey = key
+	}
+}
+
+// WithCookieKey sets the cookie carrying the language, which is "lang" by default.
+// The cookie is not checked if key is empty.
+func WithCookieKey(key string) Option {
+	return func(o *options) {
+		o.cookieKey = key
+	}
+}
+
+// WithContentLanguage sets the Content-Language of the responses to the detected language.
+func WithContentLanguage() Option {
+	return func(o *options) {
+		o.contentLanguage = true
+	}
+}
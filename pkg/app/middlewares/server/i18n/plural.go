@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"strings"
+	"sync"
+)
+
+// PluralCategory is a plural category defined by CLDR.
+type PluralCategory string
+
+const (
+	PluralZero  PluralCategory = "zero"
+	PluralOne   PluralCategory = "one"
+	PluralTwo   PluralCategory = "two"
+	PluralFew   PluralCategory = "few"
+	PluralMany  PluralCategory = "many"
+	PluralOther PluralCategory = "other"
+)
+
+func isPluralCategory(s string) bool {
+	switch PluralCategory(s) {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+// PluralRule returns the plural category of the integer n.
+type PluralRule func(n int) PluralCategory
+
+var (
+	pluralRulesLock sync.RWMutex
+	pluralRules     = map[string]PluralRule{}
+)
+
+func init() {
+	for _, lang := range []string{"ja", "ko", "th", "vi", "id", "ms", "zh"} {
+		pluralRules[lang] = pluralOther
+	}
+	for _, lang := range []string{"fr", "hi", "fa"} {
+		pluralRules[lang] = pluralZeroOne
+	}
+	for _, lang := range []string{"ru", "uk", "be"} {
+		pluralRules[lang] = pluralRussian
+	}
+	pluralRules["pl"] = pluralPolish
+	pluralRules["cs"] = pluralCzech
+	pluralRules["sk"] = pluralCzech
+	pluralRules["ar"] = pluralArabic
+}
+
+// RegisterPluralRule registers the plural rule of lang, which can be a base language like "pt"
+// or a tag with region like "pt-PT". The languages without rules use the English one,
+// which is "one" for 1 and "other" for the others.
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralRulesLock.Lock()
+	pluralRules[normalize(lang)] = rule
+	pluralRulesLock.Unlock()
+}
+
+func getPluralRule(lang string) PluralRule {
+	pluralRulesLock.RLock()
+	defer pluralRulesLock.RUnlock()
+	for ; lang != ""; lang = parent(lang) {
+		if rule, ok := pluralRules[lang]; ok {
+			return rule
+		}
+	}
+	return pluralOne
+}
+
+func pluralOther(n int) PluralCategory {
+	return PluralOther
+}
+
+func pluralOne(n int) PluralCategory {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralZeroOne(n int) PluralCategory {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralRussian(n int) PluralCategory {
+	n = abs(n)
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(n int) PluralCategory {
+	n = abs(n)
+	switch mod10, mod100 := n%10, n%100; {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralCzech(n int) PluralCategory {
+	switch n = abs(n); {
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func pluralArabic(n int) PluralCategory {
+	n = abs(n)
+	switch mod100 := n % 100; {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// normalize lowercases lang and replaces "_" with "-", e.g. "zh_CN" becomes "zh-cn".
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// parent returns lang without the last subtag, e.g. "zh-hant-tw" becomes "zh-hant".
+func parent(lang string) string {
+	if i := strings.LastIndexByte(lang, '-'); i > 0 {
+		return lang[:i]
+	}
+	return ""
+}
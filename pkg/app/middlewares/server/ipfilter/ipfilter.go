@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipfilter
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Decision is the result of filtering a request.
+type Decision struct {
+	// ClientIP is the client IP of the request, which is nil if it can not be parsed.
+	ClientIP net.IP
+	Allowed  bool
+	// Rule is the matched range in CIDR notation, which is empty if no range is matched.
+	Rule string
+}
+
+// New returns a middleware filtering the requests by the client IPs, which can be used
+// for a route group, e.g.
+//
+//	admin := h.Group("/admin", ipfilter.New(
+//		ipfilter.WithAllow("10.0.0.0/8", "192.168.0.0/16"),
+//		ipfilter.WithTrustedProxies("172.16.0.1"),
+//	))
+//
+// The denylist is checked first, and then the allowlist if it is set.
+//
+// NOTE:
+//
+//	The client IP is the remote address unless it is a trusted proxy, in which case the forwarded
+//	headers are walked from right to left, skipping the trusted proxies, so that the spoofed
+//	entries prepended by the client are not taken.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		var ip net.IP
+		if cfg.clientIPFunc != nil {
+			ip = cfg.clientIPFunc(ctx)
+		} else {
+			ip = clientIP(ctx, cfg)
+		}
+
+		d := decide(ip, cfg)
+		if cfg.auditHandler != nil {
+			cfg.auditHandler(c, ctx, d)
+		}
+		if !d.Allowed {
+			cfg.denyHandler(c, ctx, d)
+			ctx.Abort()
+			return
+		}
+		ctx.Next(c)
+	}
+}
+
+func decide(ip net.IP, cfg *options) Decision {
+	d := Decision{ClientIP: ip}
+	if rule, ok := cfg.deny.Match(ip); ok {
+		d.Rule = rule
+		return d
+	}
+	if cfg.allow.Len() == 0 {
+		d.Allowed = true
+		return d
+	}
+	d.Rule, d.Allowed = cfg.allow.Match(ip)
+	return d
+}
+
+func clientIP(ctx *app.RequestContext, cfg *options) net.IP {
+	remote := parseIP(ctx.RemoteAddr().String())
+	if !cfg.trustedProxies.Contains(remote) {
+		return remote
+	}
+	for _, h := range cfg.headers {
+		value := string(ctx.Request.Header.Peek(h))
+		if value == "" {
+			continue
+		}
+		hops := strings.Split(value, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseIP(hops[i])
+			if ip == nil || !cfg.trustedProxies.Contains(ip) || i == 0 {
+				return ip
+			}
+		}
+	}
+	return remote
+}
+
+// parseIP parses s as an IP, with or without port.
+func parseIP(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return net.ParseIP(s)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipfilter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func get(e *route.Engine, path, forwardedFor string) int {
+	var headers []ut.Header
+	if forwardedFor != "" {
+		headers = append(headers, ut.Header{Key: consts.HeaderXForwardedFor, Value: forwardedFor})
+	}
+	return ut.PerformRequest(e, consts.MethodGet, path, nil, headers...).Result().StatusCode()
+}
+
+func TestFilter(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	}
+	e.GET("/public", handler)
+	// the remote address of the test requests is 0.0.0.0
+	e.GET("/local", New(WithAllow("0.0.0.0")), handler)
+	e.GET("/private", New(WithAllow("10.0.0.0/8")), handler)
+	e.GET("/proxied", New(WithAllow("10.0.0.0/8"), WithDeny("10.0.0.13"), WithTrustedProxies("0.0.0.0", "172.16.0.0/12")), handler)
+	e.GET("/none", New(WithDeny("0.0.0.0/0")), handler)
+
+	assert.DeepEqual(t, consts.StatusOK, get(e, "/local", ""))
+	// the forwarded headers of the untrusted proxies are ignored
+	assert.DeepEqual(t, consts.StatusOK, get(e, "/local", "1.2.3.4"))
+
+	assert.DeepEqual(t, consts.StatusForbidden, get(e, "/private", "10.0.0.1"))
+	assert.DeepEqual(t, consts.StatusOK, get(e, "/public", ""))
+
+	for forwardedFor, code := range map[string]int{
+		"":                              consts.StatusForbidden,
+		"10.0.0.1":                      consts.StatusOK,
+		"10.0.0.13":                     consts.StatusForbidden,
+		"10.0.0.1, 172.16.0.2":          consts.StatusOK,
+		"10.0.0.1, 8.8.8.8, 172.16.0.2": consts.StatusForbidden,
+		"8.8.8.8, 10.0.0.1":             consts.StatusOK,
+		"not-an-ip":                     consts.StatusForbidden,
+		"172.16.0.3":                    consts.StatusForbidden,
+	} {
+		assert.DeepEqual(t, code, get(e, "/proxied", forwardedFor))
+	}
+
+	assert.DeepEqual(t, consts.StatusForbidden, get(e, "/none", ""))
+}
+
+func TestFilterOptions(t *testing.T) {
+	var audits []Decision
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(
+		WithAllow("10.0.0.0/8"),
+		WithTrustedProxies("0.0.0.0"),
+		WithForwardedHeaders("X-Real-IP", consts.HeaderXForwardedFor),
+		WithAuditHandler(func(c context.Context, ctx *app.RequestContext, d Decision) {
+			audits = append(audits, d)
+		}),
+		WithDenyHandler(func(c context.Context, ctx *app.RequestContext, d Decision) {
+			ctx.String(consts.StatusNotFound, "not found")
+		}),
+	))
+	e.GET("/admin/", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "admin")
+	})
+	resp := ut.PerformRequest(e, consts.MethodGet, "/admin/", nil, ut.Header{Key: "X-Real-IP", Value: "10.0.0.1"}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	resp = ut.PerformRequest(e, consts.MethodGet, "/admin/", nil, ut.Header{Key: consts.HeaderXForwardedFor, Value: "8.8.8.8"}).Result()
+	assert.DeepEqual(t, consts.StatusNotFound, resp.StatusCode())
+	assert.DeepEqual(t, "not found", string(resp.Body()))
+
+	assert.DeepEqual(t, 2, len(audits))
+	assert.DeepEqual(t, "10.0.0.1", audits[0].ClientIP.String())
+	assert.True(t, audits[0].Allowed)
+	assert.DeepEqual(t, "10.0.0.0/8", audits[0].Rule)
+	assert.DeepEqual(t, "8.8.8.8", audits[1].ClientIP.String())
+	assert.False(t, audits[1].Allowed)
+	assert.DeepEqual(t, "", audits[1].Rule)
+
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(WithAllow("192.168.0.0/16"), WithClientIPFunc(func(ctx *app.RequestContext) net.IP {
+		return net.ParseIP(ctx.ClientIP())
+	})))
+	e.GET("/admin/", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "admin")
+	})
+	assert.DeepEqual(t, consts.StatusOK, get(e, "/admin/", "192.168.1.1"))
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPSet is a set of IP ranges in CIDR notation.
+type IPSet struct {
+	nets []*net.IPNet
+}
+
+// NewIPSet parses the CIDRs like "10.0.0.0/8" and "2001:db8::/32" into a set,
+// the plain IPs like "192.168.1.1" are taken as single addresses.
+func NewIPSet(cidrs ...string) (*IPSet, error) {
+	s := &IPSet{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("ipfilter: invalid IP %q", cidr)
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+			s.nets = append(s.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("ipfilter: invalid CIDR %q", cidr)
+		}
+		s.nets = append(s.nets, ipNet)
+	}
+	return s, nil
+}
+
+// MustIPSet is like NewIPSet, but panics on failure.
+func MustIPSet(cidrs ...string) *IPSet {
+	s, err := NewIPSet(cidrs...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Len returns the number of the ranges in s.
+func (s *IPSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.nets)
+}
+
+// Match returns the first range of s containing ip in CIDR notation, and reports whether it exists.
+func (s *IPSet) Match(ip net.IP) (string, bool) {
+	if s == nil || ip == nil {
+		return "", false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, n := range s.nets {
+		if n.Contains(ip) {
+			return n.String(), true
+		}
+	}
+	return "", false
+}
+
+// Contains reports whether ip is in s.
+func (s *IPSet) Contains(ip net.IP) bool {
+	_, ok := s.Match(ip)
+	return ok
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipfilter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestIPSet(t *testing.T) {
+	s, err := NewIPSet("10.0.0.0/8", " 192.168.1.1 ", "2001:db8::/32", "::1")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 4, s.Len())
+
+	for ip, rule := range map[string]string{
+		"10.1.2.3":         "10.0.0.0/8",
+		"::ffff:10.1.2.3":  "10.0.0.0/8",
+		"192.168.1.1":      "192.168.1.1/32",
+		"2001:db8::1":      "2001:db8::/32",
+		"::1":              "::1/128",
+		"192.168.1.2":      "",
+		"11.0.0.1":         "",
+		"2001:db9::1":      "",
+		"::ffff:192.0.2.1": "",
+	} {
+		got, ok := s.Match(net.ParseIP(ip))
+		assert.DeepEqual(t, rule, got)
+		assert.DeepEqual(t, rule != "", ok)
+	}
+	assert.False(t, s.Contains(nil))
+
+	var empty *IPSet
+	assert.DeepEqual(t, 0, empty.Len())
+	assert.False(t, empty.Contains(net.ParseIP("10.0.0.1")))
+
+	_, err = NewIPSet("10.0.0.0/33")
+	assert.NotNil(t, err)
+	_, err = NewIPSet("example.com")
+	assert.NotNil(t, err)
+	assert.Panic(t, func() { MustIPSet("invalid") })
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipfilter
+
+import (
+	"context"
+	"net"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		allow          *IPSet
+		deny           *IPSet
+		trustedProxies *IPSet
+		headers        []string
+		clientIPFunc   func(ctx *app.RequestContext) net.IP
+		denyHandler    func(c context.Context, ctx *app.RequestContext, d Decision)
+		auditHandler   func(c context.Context, ctx *app.RequestContext, d Decision)
+	}
+
+	Option func(o *options)
+)
+
+func defaultDenyHandler(c context.Context, ctx *app.RequestContext, d Decision) {
+	ctx.AbortWithStatus(consts.StatusForbidden)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		allow:          &IPSet{},
+		deny:           &IPSet{},
+		trustedProxies: &IPSet{},
+		headers:        []string{consts.HeaderXForwardedFor},
+		denyHandler:    defaultDenyHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithAllow allows only the client IPs in cidrs, it panics if any CIDR is invalid.
+// All the client IPs not denied are allowed if no allowlist is set.
+func WithAllow(cidrs ...string) Option {
+	set := MustIPSet(cidrs...)
+	return func(o *options) {
+		o.allow.nets = append(o.allow.nets, set.nets...)
+	}
+}
+
+// WithDeny denies the client IPs in cidrs, which takes precedence over the allowlist.
+// It panics if any CIDR is invalid.
+func WithDeny(cidrs ...string) Option {
+	set := MustIPSet(cidrs...)
+	return func(o *options) {
+		o.deny.nets = append(o.deny.nets, set.nets...)
+	}
+}
+
+// WithTrustedProxies sets the proxies whose forwarded headers are trusted, it panics if any CIDR is invalid.
+// The forwarded headers are ignored by default, so that the client IP is the remote address.
+func WithTrustedProxies(cidrs ...string) Option {
+	set := MustIPSet(cidrs...)
+	return func(o *options) {
+		o.trustedProxies.nets = append(o.trustedProxies.nets, set.nets...)
+	}
+}
+
+// WithForwardedHeaders sets the headers carrying the client IP set by the trusted proxies,
+// which are checked in turn. It is X-Forwarded-For by default.
+func WithForwardedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithClientIPFunc sets the function returning the client IP, which takes the place of
+// the trusted-proxy-aware extraction, e.g. to use the ClientIP of the engine.
+// The nil IP never matches any range.
+func WithClientIPFunc(f func(ctx *app.RequestContext) net.IP) Option {
+	return func(o *options) {
+		o.clientIPFunc = f
+	}
+}
+
+// WithDenyHandler sets the handler of the denied requests, which responds 403 by default.
+func WithDenyHandler(f func(c context.Context, ctx *app.RequestContext, d Decision)) Option {
+	return func(o *options) {
+		o.denyHandler = f
+	}
+}
+
+// WithAuditHandler sets the function called with the decision of every request before it is
+// allowed or denied, e.g. to log the access to the admin endpoints.
+func WithAuditHandler(f func(c context.Context, ctx *app.RequestContext, d Decision)) Option {
+	return func(o *options) {
+		o.auditHandler = f
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"math/big"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+)
+
+// The signing algorithms supported by the middleware.
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+)
+
+type algorithm struct {
+	hash crypto.Hash
+	// keySize is the size of r and s of ECDSA signatures.
+	keySize int
+	verify  func(alg *algorithm, signingInput, sig []byte, key interface{}) error
+}
+
+var algorithms = map[string]*algorithm{
+	HS256: {hash: crypto.SHA256, verify: verifyHMAC},
+	HS384: {hash: crypto.SHA384, verify: verifyHMAC},
+	HS512: {hash: crypto.SHA512, verify: verifyHMAC},
+	RS256: {hash: crypto.SHA256, verify: verifyRSA},
+	RS384: {hash: crypto.SHA384, verify: verifyRSA},
+	RS512: {hash: crypto.SHA512, verify: verifyRSA},
+	ES256: {hash: crypto.SHA256, keySize: 32, verify: verifyECDSA},
+	ES384: {hash: crypto.SHA384, keySize: 48, verify: verifyECDSA},
+	ES512: {hash: crypto.SHA512, keySize: 66, verify: verifyECDSA},
+}
+
+// verifySignature verifies sig of signingInput with key by alg.
+// The type of key must match alg, which is []byte for HS*, *rsa.PublicKey for RS* and *ecdsa.PublicKey for ES*.
+func verifySignature(alg string, signingInput, sig []byte, key interface{}) error {
+	a, ok := algorithms[alg]
+	if !ok {
+		return ErrAlgorithmNotAllowed
+	}
+	return a.verify(a, signingInput, sig, key)
+}
+
+func verifyHMAC(alg *algorithm, signingInput, sig []byte, key interface{}) error {
+	var secret []byte
+	switch k := key.(type) {
+	case []byte:
+		secret = k
+	case string:
+		secret = bytesconv.S2b(k)
+	default:
+		return ErrInvalidKeyType
+	}
+	mac := hmac.New(alg.hash.New, secret)
+	mac.Write(signingInput)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+func verifyRSA(alg *algorithm, signingInput, sig []byte, key interface{}) error {
+	k, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidKeyType
+	}
+	h := alg.hash.New()
+	h.Write(signingInput)
+	if rsa.VerifyPKCS1v15(k, alg.hash, h.Sum(nil), sig) != nil {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+func verifyECDSA(alg *algorithm, signingInput, sig []byte, key interface{}) error {
+	k, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return ErrInvalidKeyType
+	}
+	if (k.Curve.Params().BitSize+7)/8 != alg.keySize || len(sig) != 2*alg.keySize {
+		return ErrSignatureInvalid
+	}
+	r := new(big.Int).SetBytes(sig[:alg.keySize])
+	s := new(big.Int).SetBytes(sig[alg.keySize:])
+	h := alg.hash.New()
+	h.Write(signingInput)
+	if !ecdsa.Verify(k, h.Sum(nil), r, s) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
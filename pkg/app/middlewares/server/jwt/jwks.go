@@ -0,0 +1,290 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	defaultJWKSRefreshInterval = 10 * time.Minute
+	// minJWKSRefreshInterval limits refreshing the keys on unknown key ids.
+	minJWKSRefreshInterval = 10 * time.Second
+)
+
+type (
+	jwksOptions struct {
+		refreshInterval time.Duration
+		fetcher         func(ctx context.Context, url string) ([]byte, error)
+	}
+
+	JWKSOption func(o *jwksOptions)
+)
+
+// WithJWKSRefreshInterval sets the interval of refreshing the cached keys, which is 10 minutes by default.
+func WithJWKSRefreshInterval(d time.Duration) JWKSOption {
+	return func(o *jwksOptions) {
+		o.refreshInterval = d
+	}
+}
+
+// WithJWKSFetcher sets the function to fetch the JWKS document from url,
+// which is a GET request by a hertz client with 5s timeout by default.
+func WithJWKSFetcher(f func(ctx context.Context, url string) ([]byte, error)) JWKSOption {
+	return func(o *jwksOptions) {
+		o.fetcher = f
+	}
+}
+
+// JWKS is a JSON Web Key Set fetched from an endpoint, the keys are cached and refreshed periodically,
+// or refreshed on unknown key ids at most once per 10 seconds.
+type JWKS struct {
+	url  string
+	opts *jwksOptions
+	now  func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]*jwk
+	fetchedAt time.Time
+	refreshMu sync.Mutex
+}
+
+type jwk struct {
+	alg string
+	key interface{}
+}
+
+// NewJWKS returns the JWKS of url, which is fetched on the first use.
+func NewJWKS(url string, opts ...JWKSOption) *JWKS {
+	o := &jwksOptions{
+		refreshInterval: defaultJWKSRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.fetcher == nil {
+		o.fetcher = defaultJWKSFetcher()
+	}
+	return &JWKS{url: url, opts: o, now: time.Now}
+}
+
+func defaultJWKSFetcher() func(ctx context.Context, url string) ([]byte, error) {
+	var (
+		once sync.Once
+		c    *client.Client
+		err  error
+	)
+	return func(ctx context.Context, url string) ([]byte, error) {
+		once.Do(func() {
+			c, err = client.NewClient()
+		})
+		if err != nil {
+			return nil, err
+		}
+		status, body, err := c.GetTimeout(ctx, nil, url, 5*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		if status != consts.StatusOK {
+			return nil, fmt.Errorf("jwt: unexpected status code %d fetching JWKS", status)
+		}
+		return body, nil
+	}
+}
+
+// KeyFunc returns the key of the token by the key id, which can be used by WithKeyFunc.
+//
+// NOTE:
+//
+//	If the token has no key id, the only key in the set is used.
+func (s *JWKS) KeyFunc(ctx context.Context, header *Header) (interface{}, error) {
+	k, err := s.lookup(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if k.alg != "" && k.alg != header.Alg {
+		return nil, ErrAlgorithmNotAllowed
+	}
+	return k.key, nil
+}
+
+func (s *JWKS) lookup(ctx context.Context, kid string) (*jwk, error) {
+	s.mu.RLock()
+	keys, fetchedAt := s.keys, s.fetchedAt
+	s.mu.RUnlock()
+
+	now := s.now()
+	stale := keys == nil || now.Sub(fetchedAt) >= s.opts.refreshInterval
+	if !stale {
+		if k, ok := find(keys, kid); ok {
+			return k, nil
+		}
+		if now.Sub(fetchedAt) < minJWKSRefreshInterval {
+			return nil, ErrKeyNotFound
+		}
+	}
+
+	if err := s.refresh(ctx, fetchedAt); err != nil {
+		// the stale keys are used if the refreshing fails
+		if k, ok := find(keys, kid); ok {
+			return k, nil
+		}
+		return nil, err
+	}
+	s.mu.RLock()
+	keys = s.keys
+	s.mu.RUnlock()
+	if k, ok := find(keys, kid); ok {
+		return k, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func find(keys map[string]*jwk, kid string) (*jwk, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+// refresh fetches the keys unless they have been refreshed by others since fetchedAt.
+func (s *JWKS) refresh(ctx context.Context, fetchedAt time.Time) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	s.mu.RLock()
+	refreshed := s.fetchedAt.After(fetchedAt)
+	s.mu.RUnlock()
+	if refreshed {
+		return nil
+	}
+
+	body, err := s.opts.fetcher(ctx, s.url)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys, s.fetchedAt = keys, s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+type rawJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS parses the keys for signature of the JWKS document, the unsupported keys are skipped.
+func parseJWKS(body []byte) (map[string]*jwk, error) {
+	var set struct {
+		Keys []rawJWK `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWKS: %w", err)
+	}
+	keys := make(map[string]*jwk, len(set.Keys))
+	for _, raw := range set.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+		key, err := raw.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[raw.Kid] = &jwk{alg: raw.Alg, key: key}
+	}
+	return keys, nil
+}
+
+func (k *rawJWK) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, ErrTokenMalformed
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, ErrInvalidKeyType
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, ErrInvalidKeyType
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, ErrInvalidKeyType
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
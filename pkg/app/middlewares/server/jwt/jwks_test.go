@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func rsaJWK(kid string) string {
+	return fmt.Sprintf(`{"kty":"RSA","kid":%q,"alg":"RS256","use":"sig","n":%q,"e":%q}`, kid,
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()))
+}
+
+func ecJWK(kid string) string {
+	return fmt.Sprintf(`{"kty":"EC","kid":%q,"crv":"P-256","x":%q,"y":%q}`, kid,
+		base64.RawURLEncoding.EncodeToString(ecdsaKey.X.Bytes()),
+		base64.RawURLEncoding.EncodeToString(ecdsaKey.Y.Bytes()))
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys, err := parseJWKS([]byte(`{"keys":[` + rsaJWK("rsa") + `,` + ecJWK("ec") +
+		`,{"kty":"oct","kid":"oct","k":"c2VjcmV0"},{"kty":"RSA","kid":"enc","use":"enc"},{"kty":"OKP","kid":"okp"}]}`))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 3, len(keys))
+	assert.DeepEqual(t, RS256, keys["rsa"].alg)
+	assert.DeepEqual(t, rsaKey.PublicKey, *keys["rsa"].key.(*rsa.PublicKey))
+	assert.DeepEqual(t, ecdsaKey.X, keys["ec"].key.(*ecdsa.PublicKey).X)
+	assert.DeepEqual(t, []byte("secret"), keys["oct"].key)
+
+	_, err = parseJWKS([]byte("not json"))
+	assert.NotNil(t, err)
+}
+
+func TestJWKS(t *testing.T) {
+	fetched := 0
+	doc := `{"keys":[` + rsaJWK("rsa") + `]}`
+	var fetchErr error
+	jwks := NewJWKS("https://example.com/jwks", WithJWKSRefreshInterval(time.Minute),
+		WithJWKSFetcher(func(ctx context.Context, url string) ([]byte, error) {
+			assert.DeepEqual(t, "https://example.com/jwks", url)
+			fetched++
+			return []byte(doc), fetchErr
+		}))
+	now := time.Unix(1000, 0)
+	jwks.now = func() time.Time { return now }
+
+	token := sign(t, &Header{Alg: RS256, Kid: "rsa"}, Claims{"sub": "foo"}, rsaKey)
+	for i := 0; i < 2; i++ {
+		_, claims, err := parse(context.Background(), token, jwks.KeyFunc, nil)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "foo", claims.Subject())
+	}
+	assert.DeepEqual(t, 1, fetched)
+
+	// the token without kid uses the only key
+	_, _, err := parse(context.Background(), sign(t, &Header{Alg: RS256}, Claims{}, rsaKey), jwks.KeyFunc, nil)
+	assert.Nil(t, err)
+
+	// the algorithm of the key is enforced
+	_, _, err = parse(context.Background(), sign(t, &Header{Alg: RS512, Kid: "rsa"}, Claims{}, rsaKey), jwks.KeyFunc, nil)
+	assert.DeepEqual(t, ErrAlgorithmNotAllowed, err)
+
+	// unknown kid does not refresh too often
+	ecToken := sign(t, &Header{Alg: ES256, Kid: "ec"}, Claims{}, ecdsaKey)
+	doc = `{"keys":[` + rsaJWK("rsa") + `,` + ecJWK("ec") + `]}`
+	_, _, err = parse(context.Background(), ecToken, jwks.KeyFunc, nil)
+	assert.DeepEqual(t, ErrKeyNotFound, err)
+	assert.DeepEqual(t, 1, fetched)
+
+	now = now.Add(minJWKSRefreshInterval)
+	_, _, err = parse(context.Background(), ecToken, jwks.KeyFunc, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 2, fetched)
+
+	// the keys are refreshed periodically, and the stale keys are used if refreshing fails
+	now = now.Add(time.Minute)
+	fetchErr = errors.New("unavailable")
+	_, _, err = parse(context.Background(), token, jwks.KeyFunc, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 3, fetched)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	// ClaimsKey is the key of the claims of the token in the RequestContext.
+	ClaimsKey = "jwt_claims"
+	// HeaderKey is the key of the header of the token in the RequestContext.
+	HeaderKey = "jwt_header"
+)
+
+// New returns a middleware which authenticates the requests by JWT,
+// and sets the claims to ClaimsKey in the RequestContext if the token is valid.
+//
+// NOTE:
+//
+//	One of WithKey, WithKeyFunc and WithJWKS must be used, otherwise New panics.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	if cfg.keyFunc == nil {
+		panic("jwt: key is not set")
+	}
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		token := cfg.extract(ctx)
+		if token == "" {
+			cfg.errorHandler(c, ctx, ErrTokenMissing)
+			return
+		}
+		header, claims, err := parse(c, token, cfg.keyFunc, cfg.algorithms)
+		if err == nil {
+			err = cfg.validate(claims, time.Now())
+		}
+		if err != nil {
+			cfg.errorHandler(c, ctx, err)
+			return
+		}
+		ctx.Set(HeaderKey, header)
+		ctx.Set(ClaimsKey, claims)
+		ctx.Next(c)
+	}
+}
+
+// GetClaims returns the claims of the token set by the middleware.
+func GetClaims(ctx *app.RequestContext) (Claims, bool) {
+	v, ok := ctx.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(Claims)
+	return claims, ok
+}
+
+func (o *options) extract(ctx *app.RequestContext) string {
+	for _, l := range o.lookups {
+		var token string
+		switch l.source {
+		case "header":
+			token = string(ctx.Request.Header.Peek(l.name))
+			if token != "" && strings.EqualFold(l.name, consts.HeaderAuthorization) && o.authScheme != "" {
+				n := len(o.authScheme)
+				if len(token) <= n || !strings.EqualFold(token[:n], o.authScheme) || token[n] != ' ' {
+					token = ""
+				} else {
+					token = strings.TrimSpace(token[n+1:])
+				}
+			}
+		case "cookie":
+			token = string(ctx.Cookie(l.name))
+		case "query":
+			token = ctx.Query(l.name)
+		case "form":
+			token = string(ctx.FormValue(l.name))
+		}
+		if token != "" {
+			return token
+		}
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+var (
+	rsaKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	ecdsaKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	hmacKey     = []byte("secret")
+)
+
+func sign(t *testing.T, header *Header, claims Claims, key interface{}) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	alg := algorithms[header.Alg]
+	digest := alg.hash.New()
+	digest.Write([]byte(input))
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(alg.hash.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.Hash(alg.hash), digest.Sum(nil))
+		assert.Nil(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest.Sum(nil))
+		assert.Nil(t, err)
+		sig = make([]byte, 2*alg.keySize)
+		r.FillBytes(sig[:alg.keySize])
+		s.FillBytes(sig[alg.keySize:])
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestParseAlgorithms(t *testing.T) {
+	claims := Claims{"sub": "foo"}
+	for _, tc := range []struct {
+		alg       string
+		signKey   interface{}
+		verifyKey interface{}
+	}{
+		{HS256, hmacKey, hmacKey},
+		{HS384, hmacKey, hmacKey},
+		{HS512, hmacKey, hmacKey},
+		{RS256, rsaKey, &rsaKey.PublicKey},
+		{RS512, rsaKey, &rsaKey.PublicKey},
+		{ES256, ecdsaKey, &ecdsaKey.PublicKey},
+	} {
+		token := sign(t, &Header{Alg: tc.alg}, claims, tc.signKey)
+		keyFunc := func(ctx context.Context, header *Header) (interface{}, error) {
+			return tc.verifyKey, nil
+		}
+		_, got, err := parse(context.Background(), token, keyFunc, nil)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "foo", got.Subject())
+
+		// tampered
+		// replace a char at the beginning of the signature, as the last one may carry padding bits only
+		i := strings.LastIndexByte(token, '.') + 1
+		c := byte('A')
+		if token[i] == c {
+			c = 'B'
+		}
+		_, _, err = parse(context.Background(), token[:i]+string(c)+token[i+1:], keyFunc, nil)
+		assert.True(t, err == ErrSignatureInvalid || err == ErrTokenMalformed)
+
+		// not allowed
+		_, _, err = Parse(context.Background(), token, keyFunc, "none")
+		assert.DeepEqual(t, ErrAlgorithmNotAllowed, err)
+		_, _, err = Parse(context.Background(), token, keyFunc, tc.alg)
+		assert.Nil(t, err)
+	}
+
+	// the public key of RSA can not be used as the secret of HMAC
+	token := sign(t, &Header{Alg: HS256}, claims, hmacKey)
+	_, _, err := parse(context.Background(), token, func(ctx context.Context, header *Header) (interface{}, error) {
+		return &rsaKey.PublicKey, nil
+	}, nil)
+	assert.DeepEqual(t, ErrInvalidKeyType, err)
+
+	// the size of the signature of ES256 is checked
+	token = sign(t, &Header{Alg: ES256}, claims, ecdsaKey)
+	_, _, err = parse(context.Background(), token[:len(token)-1], func(ctx context.Context, header *Header) (interface{}, error) {
+		return &ecdsaKey.PublicKey, nil
+	}, nil)
+	assert.NotNil(t, err)
+
+	for _, token := range []string{"", "a", "a.b", "!.b.c"} {
+		_, _, err = parse(context.Background(), token, nil, nil)
+		assert.DeepEqual(t, ErrTokenMalformed, err)
+	}
+	_, _, err = parse(context.Background(), sign(t, &Header{Alg: HS256}, claims, hmacKey)[1:], nil, nil)
+	assert.DeepEqual(t, ErrTokenMalformed, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cfg := newOptions(WithLeeway(10*time.Second), WithIssuer("iss"), WithAudience("aud1", "aud2"))
+	for _, tc := range []struct {
+		claims Claims
+		err    error
+	}{
+		{Claims{"iss": "iss", "aud": "aud1"}, nil},
+		{Claims{"iss": "iss", "aud": []interface{}{"aud0", "aud2"}}, nil},
+		{Claims{"iss": "iss", "aud": "aud0"}, ErrInvalidAudience},
+		{Claims{"iss": "other", "aud": "aud1"}, ErrInvalidIssuer},
+		{Claims{"iss": "iss", "aud": "aud1", "exp": json.Number("995")}, nil},
+		{Claims{"iss": "iss", "aud": "aud1", "exp": json.Number("990")}, ErrTokenExpired},
+		{Claims{"iss": "iss", "aud": "aud1", "nbf": json.Number("1005")}, nil},
+		{Claims{"iss": "iss", "aud": "aud1", "nbf": json.Number("1011")}, ErrTokenNotValidYet},
+		{Claims{"iss": "iss", "aud": "aud1", "iat": json.Number("1011")}, ErrTokenUsedBeforeIssued},
+		{Claims{"iss": "iss", "aud": "aud1", "exp": "2000"}, ErrTokenMalformed},
+		{Claims{"iss": "iss", "aud": "aud1", "nbf": true}, ErrTokenMalformed},
+		{Claims{"iss": "iss", "aud": "aud1", "iat": nil}, ErrTokenMalformed},
+		{Claims{"iss": "iss", "aud": "aud1", "exp": json.Number("1e")}, ErrTokenMalformed},
+	} {
+		assert.DeepEqual(t, tc.err, cfg.validate(tc.claims, now))
+	}
+}
+
+func TestJWT(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithKey(&rsaKey.PublicKey), WithAudience("api")))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		claims, _ := GetClaims(ctx)
+		ctx.String(consts.StatusOK, claims.Subject())
+	})
+	valid := sign(t, &Header{Alg: RS256}, Claims{"sub": "user1", "aud": "api", "exp": time.Now().Add(time.Hour).Unix()}, rsaKey)
+	expired := sign(t, &Header{Alg: RS256}, Claims{"sub": "user1", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()}, rsaKey)
+
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: consts.HeaderAuthorization, Value: "Bearer " + valid}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "user1", string(resp.Body()))
+
+	for _, auth := range []string{"", valid, "Basic " + valid, "Bearer " + expired} {
+		resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+			ut.Header{Key: consts.HeaderAuthorization, Value: auth}).Result()
+		assert.DeepEqual(t, consts.StatusUnauthorized, resp.StatusCode())
+		assert.DeepEqual(t, "Bearer", resp.Header.Get(consts.HeaderWWWAuthenticate))
+	}
+}
+
+func TestJWTTokenLookup(t *testing.T) {
+	var gotErr error
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithKey(hmacKey),
+		WithAlgorithms(HS256),
+		WithTokenLookup("header:X-Token, cookie:jwt,query:token"),
+		WithErrorHandler(func(c context.Context, ctx *app.RequestContext, err error) {
+			gotErr = err
+			ctx.AbortWithStatus(consts.StatusForbidden)
+		}),
+	))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		claims, _ := GetClaims(ctx)
+		ctx.String(consts.StatusOK, claims.Subject())
+	})
+	token := sign(t, &Header{Alg: HS256}, Claims{"sub": "user1"}, hmacKey)
+
+	for _, tc := range []struct {
+		url    string
+		header ut.Header
+	}{
+		{"/foo", ut.Header{Key: "X-Token", Value: token}},
+		{"/foo", ut.Header{Key: "Cookie", Value: "jwt=" + token}},
+		{"/foo?token=" + token, ut.Header{}},
+	} {
+		resp := ut.PerformRequest(engine, consts.MethodGet, tc.url, nil, tc.header).Result()
+		assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+		assert.DeepEqual(t, "user1", string(resp.Body()))
+	}
+
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: consts.HeaderAuthorization, Value: "Bearer " + token}).Result()
+	assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+	assert.True(t, errors.Is(gotErr, ErrTokenMissing))
+
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: "X-Token", Value: sign(t, &Header{Alg: HS512}, Claims{"sub": "user1"}, hmacKey)}).Result()
+	assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+	assert.DeepEqual(t, ErrAlgorithmNotAllowed, gotErr)
+}
+
+func TestNewPanics(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithKey(hmacKey), WithTokenLookup("header")},
+		{WithKey(hmacKey), WithTokenLookup("body:token")},
+	} {
+		func() {
+			defer func() {
+				assert.True(t, recover() != nil)
+			}()
+			New(opts...)
+		}()
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		keyFunc      KeyFunc
+		algorithms   map[string]bool
+		audiences    map[string]bool
+		issuers      map[string]bool
+		leeway       time.Duration
+		lookups      []lookup
+		authScheme   string
+		errorHandler func(c context.Context, ctx *app.RequestContext, err error)
+	}
+
+	Option func(o *options)
+)
+
+type lookup struct {
+	source string
+	name   string
+}
+
+func defaultErrorHandler(c context.Context, ctx *app.RequestContext, err error) {
+	ctx.Header(consts.HeaderWWWAuthenticate, "Bearer")
+	ctx.AbortWithStatus(consts.StatusUnauthorized)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		lookups:      []lookup{{source: "header", name: consts.HeaderAuthorization}},
+		authScheme:   "Bearer",
+		errorHandler: defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithKey sets the key to verify all tokens, which is []byte for HS*,
+// *rsa.PublicKey for RS* and *ecdsa.PublicKey for ES*.
+//
+// NOTE:
+//
+//	The tokens signed by the algorithms which do not match the type of the key are rejected,
+//	use WithAlgorithms to restrict the algorithms further.
+func WithKey(key interface{}) Option {
+	return func(o *options) {
+		o.keyFunc = func(ctx context.Context, header *Header) (interface{}, error) {
+			return key, nil
+		}
+	}
+}
+
+// WithKeyFunc sets the function returning the key to verify the token, e.g. by the key id.
+func WithKeyFunc(f KeyFunc) Option {
+	return func(o *options) {
+		o.keyFunc = f
+	}
+}
+
+// WithJWKS verifies the tokens by the keys of the JWKS endpoint.
+func WithJWKS(jwks *JWKS) Option {
+	return func(o *options) {
+		o.keyFunc = jwks.KeyFunc
+	}
+}
+
+// WithAlgorithms restricts the signing algorithms of the tokens, all supported algorithms are allowed by default.
+func WithAlgorithms(algs ...string) Option {
+	return func(o *options) {
+		o.algorithms = toSet(algs)
+	}
+}
+
+// WithAudience requires the "aud" claim to contain any of audiences.
+func WithAudience(audiences ...string) Option {
+	return func(o *options) {
+		o.audiences = toSet(audiences)
+	}
+}
+
+// WithIssuer requires the "iss" claim to be any of issuers.
+func WithIssuer(issuers ...string) Option {
+	return func(o *options) {
+		o.issuers = toSet(issuers)
+	}
+}
+
+// WithLeeway sets the leeway of validating the "exp", "nbf" and "iat" claims for the clock skew.
+func WithLeeway(d time.Duration) Option {
+	return func(o *options) {
+		o.leeway = d
+	}
+}
+
+// WithTokenLookup sets where the token is extracted from, in the form of "<source>:<name>"
+// separated by commas, the sources are tried in order, e.g.
+//
+//	"header:Authorization,cookie:jwt,query:token"
+//
+// The source can be "header", "cookie", "query" or "form", and it is "header:Authorization" by default.
+//
+// NOTE:
+//
+//	The auth scheme is stripped from the Authorization header, see WithAuthScheme.
+//	It panics if lookup is invalid.
+func WithTokenLookup(lookups string) Option {
+	return func(o *options) {
+		o.lookups = o.lookups[:0:0]
+		for _, l := range strings.Split(lookups, ",") {
+			parts := strings.SplitN(strings.TrimSpace(l), ":", 2)
+			if len(parts) != 2 || parts[1] == "" {
+				panic("jwt: invalid token lookup: " + l)
+			}
+			switch parts[0] {
+			case "header", "cookie", "query", "form":
+			default:
+				panic("jwt: invalid token lookup: " + l)
+			}
+			o.lookups = append(o.lookups, lookup{source: parts[0], name: strings.TrimSpace(parts[1])})
+		}
+	}
+}
+
+// WithAuthScheme sets the scheme of the token in the Authorization header, which is "Bearer" by default.
+func WithAuthScheme(scheme string) Option {
+	return func(o *options) {
+		o.authScheme = scheme
+	}
+}
+
+// WithErrorHandler sets the handler of the requests whose tokens are missing or invalid,
+// which responds 401 with "WWW-Authenticate: Bearer" by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
+
+func toSet(ss []string) map[string]bool {
+	m := make(map[string]bool, len(ss))
+	for _, s := range ss {
+		m[s] = true
+	}
+	return m
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+var (
+	ErrTokenMissing          = errors.NewPublic("jwt: token is missing")
+	ErrTokenMalformed        = errors.NewPublic("jwt: token is malformed")
+	ErrAlgorithmNotAllowed   = errors.NewPublic("jwt: signing algorithm is not allowed")
+	ErrKeyNotFound           = errors.NewPublic("jwt: key is not found")
+	ErrInvalidKeyType        = errors.NewPublic("jwt: key type does not match the algorithm")
+	ErrSignatureInvalid      = errors.NewPublic("jwt: signature is invalid")
+	ErrTokenExpired          = errors.NewPublic("jwt: token is expired")
+	ErrTokenNotValidYet      = errors.NewPublic("jwt: token is not valid yet")
+	ErrTokenUsedBeforeIssued = errors.NewPublic("jwt: token is used before issued")
+	ErrInvalidAudience       = errors.NewPublic("jwt: audience is invalid")
+	ErrInvalidIssuer         = errors.NewPublic("jwt: issuer is invalid")
+)
+
+// Header is the JOSE header of a token.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Claims is the claims set of a token.
+type Claims map[string]interface{}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the "iss" claim.
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which may be a string or an array of strings in the token.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		ret := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+// ExpiresAt returns the "exp" claim, which is false if it is absent or not a number.
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	t, ok, _ := c.time("exp")
+	return t, ok
+}
+
+// NotBefore returns the "nbf" claim, which is false if it is absent or not a number.
+func (c Claims) NotBefore() (time.Time, bool) {
+	t, ok, _ := c.time("nbf")
+	return t, ok
+}
+
+// IssuedAt returns the "iat" claim, which is false if it is absent or not a number.
+func (c Claims) IssuedAt() (time.Time, bool) {
+	t, ok, _ := c.time("iat")
+	return t, ok
+}
+
+// time returns the NumericDate claim of name, ok is false if it is absent,
+// and ErrTokenMalformed is returned if it is present but not a number.
+func (c Claims) time(name string) (t time.Time, ok bool, err error) {
+	var f float64
+	switch v := c[name].(type) {
+	case nil:
+		if _, present := c[name]; !present {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, ErrTokenMalformed
+	case float64:
+		f = v
+	case json.Number:
+		if f, err = v.Float64(); err != nil {
+			return time.Time{}, false, ErrTokenMalformed
+		}
+	default:
+		return time.Time{}, false, ErrTokenMalformed
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true, nil
+}
+
+// KeyFunc returns the key to verify the token with header.
+type KeyFunc func(ctx context.Context, header *Header) (interface{}, error)
+
+// Parse verifies the signature of token with the key returned by keyFunc, and returns its header and claims.
+// The algorithms of the token are restricted to algs if any.
+//
+// NOTE:
+//
+//	The claims are not validated, it is the duty of the caller.
+func Parse(ctx context.Context, token string, keyFunc KeyFunc, algs ...string) (*Header, Claims, error) {
+	return parse(ctx, token, keyFunc, toSet(algs))
+}
+
+// parse verifies the signature of token and returns its claims, the claims are not validated.
+func parse(ctx context.Context, token string, keyFunc KeyFunc, allowed map[string]bool) (*Header, Claims, error) {
+	dot1 := strings.IndexByte(token, '.')
+	dot2 := strings.LastIndexByte(token, '.')
+	if dot1 < 0 || dot1 == dot2 {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	header := &Header{}
+	if err := decodeSegment(token[:dot1], header); err != nil {
+		return nil, nil, err
+	}
+	if len(allowed) > 0 && !allowed[header.Alg] {
+		return nil, nil, ErrAlgorithmNotAllowed
+	}
+	if _, ok := algorithms[header.Alg]; !ok {
+		return nil, nil, ErrAlgorithmNotAllowed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(token[dot2+1:])
+	if err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+	key, err := keyFunc(ctx, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = verifySignature(header.Alg, bytesconv.S2b(token[:dot2]), sig, key); err != nil {
+		return nil, nil, err
+	}
+
+	claims := Claims{}
+	if err = decodeSegment(token[dot1+1:dot2], &claims); err != nil {
+		return nil, nil, err
+	}
+	return header, claims, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if dec.Decode(v) != nil {
+		return ErrTokenMalformed
+	}
+	return nil
+}
+
+// validate validates the registered claims of claims at now.
+func (o *options) validate(claims Claims, now time.Time) error {
+	exp, ok, err := claims.time("exp")
+	if err != nil {
+		return err
+	}
+	if ok && !now.Before(exp.Add(o.leeway)) {
+		return ErrTokenExpired
+	}
+	nbf, ok, err := claims.time("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(o.leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	iat, ok, err := claims.time("iat")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(o.leeway).Before(iat) {
+		return ErrTokenUsedBeforeIssued
+	}
+	if len(o.issuers) > 0 && !o.issuers[claims.Issuer()] {
+		return ErrInvalidIssuer
+	}
+	if len(o.audiences) > 0 {
+		for _, aud := range claims.Audience() {
+			if o.audiences[aud] {
+				return nil
+			}
+		}
+		return ErrInvalidAudience
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// Maintenance rejects the requests with 503 while it is enabled, which can be toggled at runtime, e.g.
+//
+//	m := maintenance.New(maintenance.WithAllowPaths("/healthz", "/admin/*"))
+//	h.Use(m.Middleware())
+//	...
+//	m.Enable(10 * time.Minute)
+//	m.Disable()
+type Maintenance struct {
+	opts *options
+
+	mu      sync.RWMutex
+	enabled bool
+	until   time.Time
+}
+
+// New creates a disabled Maintenance.
+func New(opts ...Option) *Maintenance {
+	return &Maintenance{opts: newOptions(opts...)}
+}
+
+// Enable starts the maintenance, which is expected to last for d.
+// The rejected responses carry Retry-After with the remaining seconds if d>0.
+//
+// NOTE:
+//
+//	The maintenance is not ended automatically after d, Disable must be called to end it.
+func (m *Maintenance) Enable(d time.Duration) {
+	m.mu.Lock()
+	m.enabled = true
+	m.until = time.Time{}
+	if d > 0 {
+		m.until = time.Now().Add(d)
+	}
+	m.mu.Unlock()
+}
+
+// Disable ends the maintenance.
+func (m *Maintenance) Disable() {
+	m.mu.Lock()
+	m.enabled = false
+	m.mu.Unlock()
+}
+
+// Enabled reports whether the maintenance is in progress.
+func (m *Maintenance) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// Middleware returns the middleware rejecting the requests during maintenance
+// except the ones of the allowed paths and client IPs.
+func (m *Maintenance) Middleware() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		m.mu.RLock()
+		enabled, until := m.enabled, m.until
+		m.mu.RUnlock()
+		if !enabled || m.allowed(ctx) {
+			ctx.Next(c)
+			return
+		}
+
+		if !until.IsZero() {
+			seconds := int64((time.Until(until) + time.Second - 1) / time.Second)
+			if seconds < 1 {
+				seconds = 1
+			}
+			ctx.Header(consts.HeaderRetryAfter, strconv.FormatInt(seconds, 10))
+		}
+		m.opts.handler(c, ctx)
+		ctx.Abort()
+	}
+}
+
+func (m *Maintenance) allowed(ctx *app.RequestContext) bool {
+	path := string(ctx.Path())
+	for _, p := range m.opts.allowPaths {
+		if path == p {
+			return true
+		}
+	}
+	for _, p := range m.opts.allowPrefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return m.opts.allowIPs.Len() > 0 && m.opts.allowIPs.Contains(net.ParseIP(ctx.ClientIP()))
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestMaintenance(t *testing.T) {
+	m := New(WithAllowPaths("/healthz", "/admin/*"))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(m.Middleware())
+	for _, path := range []string{"/ping", "/healthz", "/admin/users"} {
+		e.GET(path, func(c context.Context, ctx *app.RequestContext) {
+			ctx.String(consts.StatusOK, "ok")
+		})
+	}
+	assert.False(t, m.Enabled())
+	assert.DeepEqual(t, consts.StatusOK, ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result().StatusCode())
+
+	m.Enable(10 * time.Minute)
+	assert.True(t, m.Enabled())
+	resp := ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result()
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, resp.StatusCode())
+	retryAfter, err := strconv.Atoi(resp.Header.Get(consts.HeaderRetryAfter))
+	assert.Nil(t, err)
+	assert.True(t, retryAfter > 590 && retryAfter <= 600)
+	assert.DeepEqual(t, consts.StatusOK, ut.PerformRequest(e, consts.MethodGet, "/healthz", nil).Result().StatusCode())
+	assert.DeepEqual(t, consts.StatusOK, ut.PerformRequest(e, consts.MethodGet, "/admin/users", nil).Result().StatusCode())
+
+	m.Enable(0)
+	resp = ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result()
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, resp.StatusCode())
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderRetryAfter))
+
+	m.Disable()
+	assert.DeepEqual(t, consts.StatusOK, ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result().StatusCode())
+}
+
+func TestMaintenanceAllowIPs(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	}
+	// the client IP of the test requests is 0.0.0.0
+	local := New(WithAllowIPs("0.0.0.0"))
+	local.Enable(time.Minute)
+	e.GET("/local", local.Middleware(), handler)
+	private := New(WithAllowIPs("10.0.0.0/8"), WithHandler(func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(consts.StatusServiceUnavailable, map[string]string{"message": "maintenance"})
+	}))
+	private.Enable(time.Minute)
+	e.GET("/private", private.Middleware(), handler)
+
+	assert.DeepEqual(t, consts.StatusOK, ut.PerformRequest(e, consts.MethodGet, "/local", nil).Result().StatusCode())
+	resp := ut.PerformRequest(e, consts.MethodGet, "/private", nil).Result()
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, resp.StatusCode())
+	assert.DeepEqual(t, `{"message":"maintenance"}`, string(resp.Body()))
+	assert.DeepEqual(t, "60", resp.Header.Get(consts.HeaderRetryAfter))
+
+	assert.Panic(t, func() { New(WithAllowIPs("invalid")) })
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/ipfilter"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		allowPaths    []string
+		allowPrefixes []string
+		allowIPs      *ipfilter.IPSet
+		handler       func(c context.Context, ctx *app.RequestContext)
+	}
+
+	Option func(o *options)
+)
+
+func defaultHandler(c context.Context, ctx *app.RequestContext) {
+	ctx.String(consts.StatusServiceUnavailable, "service is under maintenance")
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		allowIPs: &ipfilter.IPSet{},
+		handler:  defaultHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithAllowPaths lets the requests of paths through during maintenance, e.g. the health checks.
+// The path ending with "*" matches the paths with the prefix before it, e.g. "/admin/*".
+func WithAllowPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, p := range paths {
+			if strings.HasSuffix(p, "*") {
+				o.allowPrefixes = append(o.allowPrefixes, strings.TrimSuffix(p, "*"))
+			} else {
+				o.allowPaths = append(o.allowPaths, p)
+			}
+		}
+	}
+}
+
+// WithAllowIPs lets the requests from the client IPs in cidrs through during maintenance,
+// e.g. to verify the service before ending the maintenance. It panics if any CIDR is invalid.
+//
+// NOTE:
+//
+//	The client IP is got by ClientIP of the context, which should be configured by the engine
+//	to trust only the forwarded headers set by the trusted proxies.
+func WithAllowIPs(cidrs ...string) Option {
+	set := ipfilter.MustIPSet(cidrs...)
+	return func(o *options) {
+		o.allowIPs = set
+	}
+}
+
+// WithHandler sets the handler of the requests rejected during maintenance,
+// which responds 503 with a message by default. The Retry-After header is set before it is called.
+func WithHandler(f func(c context.Context, ctx *app.RequestContext)) Option {
+	return func(o *options) {
+		o.handler = f
+	}
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/jwt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// SessionKey is the key of the session in the RequestContext.
+const SessionKey = "oidc_session"
+
+var errStateMismatch = errors.New("oidc: state does not match")
+
+// RelyingParty delegates the login of browser-facing apps to an OpenID Connect provider,
+// by the authorization code flow with state, nonce and PKCE, e.g.
+//
+//	rp := oidc.New("https://accounts.example.com", "client-id", "https://app.example.com/callback",
+//		oidc.WithClientSecret("secret"), oidc.WithCookieKey(key))
+//	h.GET("/callback", rp.CallbackHandler())
+//	h.GET("/logout", rp.LogoutHandler())
+//	g := h.Group("/", rp.Middleware())
+type RelyingParty struct {
+	issuer      string
+	clientID    string
+	redirectURL string
+	opts        *options
+	codec       *codec
+	now         func() time.Time
+
+	mu       sync.RWMutex
+	provider *Provider
+	jwks     *jwt.JWKS
+}
+
+// New returns a RelyingParty of the client registered at the provider of issuer,
+// redirectURL is where the provider redirects back to, which is served by CallbackHandler.
+//
+// NOTE:
+//
+//	New panics if the cookie key is invalid.
+func New(issuer, clientID, redirectURL string, opts ...Option) *RelyingParty {
+	cfg := newOptions(opts...)
+	c, err := newCodec(cfg.cookieKey)
+	if err != nil {
+		panic("oidc: invalid cookie key: " + err.Error())
+	}
+	if cfg.client == nil {
+		cfg.client = &lazyClient{}
+	}
+	if cfg.sessionStore == nil {
+		cfg.sessionStore = &cookieStore{codec: c, maxAge: int(cfg.sessionMaxAge / time.Second), secure: cfg.secureCookie}
+	}
+	rp := &RelyingParty{
+		issuer:      issuer,
+		clientID:    clientID,
+		redirectURL: redirectURL,
+		opts:        cfg,
+		codec:       c,
+		now:         time.Now,
+	}
+	if cfg.provider != nil {
+		rp.provider = cfg.provider
+		rp.jwks = jwt.NewJWKS(cfg.provider.JWKSURI, jwt.WithJWKSFetcher(rp.get))
+	}
+	return rp
+}
+
+// lazyClient creates the hertz client on the first use.
+type lazyClient struct {
+	once sync.Once
+	c    *client.Client
+	err  error
+}
+
+func (l *lazyClient) Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	l.once.Do(func() {
+		l.c, l.err = client.NewClient(client.WithDialTimeout(5 * time.Second))
+	})
+	if l.err != nil {
+		return l.err
+	}
+	return l.c.DoTimeout(ctx, req, resp, 10*time.Second)
+}
+
+// GetSession returns the session set by the middleware.
+func GetSession(ctx *app.RequestContext) (*Session, bool) {
+	v, ok := ctx.Get(SessionKey)
+	if !ok {
+		return nil, false
+	}
+	s, ok := v.(*Session)
+	return s, ok
+}
+
+// Middleware returns a middleware which requires the requests to be authenticated,
+// and sets the session to SessionKey in the RequestContext.
+// The expired access token is refreshed if there is a refresh token,
+// otherwise the unauthenticated handler is invoked, which starts the login by default.
+func (rp *RelyingParty) Middleware() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		s, err := rp.opts.sessionStore.Get(c, ctx)
+		if err == nil && s != nil && s.expired(rp.now(), rp.opts.leeway) {
+			if err = rp.refresh(c, s); err == nil {
+				err = rp.opts.sessionStore.Save(c, ctx, s)
+			}
+			if err != nil {
+				hlog.SystemLogger().CtxWarnf(c, "Refresh OIDC session error=%v", err)
+				s = nil
+			}
+		}
+		if err != nil || s == nil {
+			rp.unauthenticated(c, ctx)
+			return
+		}
+		ctx.Set(SessionKey, s)
+		ctx.Next(c)
+	}
+}
+
+func (rp *RelyingParty) unauthenticated(c context.Context, ctx *app.RequestContext) {
+	if rp.opts.unauthenticated != nil {
+		rp.opts.unauthenticated(c, ctx, rp)
+		ctx.Abort()
+		return
+	}
+	rp.Login(c, ctx, string(ctx.URI().RequestURI()))
+}
+
+// LoginHandler returns a handler which starts the login,
+// the user agent is redirected to the "return_to" query parameter after login, which is "/" by default.
+func (rp *RelyingParty) LoginHandler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		rp.Login(c, ctx, ctx.Query("return_to"))
+	}
+}
+
+// Login redirects the user agent to the authorization endpoint of the provider,
+// and returns to returnTo after login, which must be a local path, otherwise "/" is used.
+func (rp *RelyingParty) Login(c context.Context, ctx *app.RequestContext, returnTo string) {
+	p, _, err := rp.getProvider(c)
+	if err != nil {
+		rp.fail(c, ctx, err)
+		return
+	}
+	st := &loginState{ReturnTo: safeReturnTo(returnTo), Expiry: rp.now().Add(stateMaxAge)}
+	for _, s := range []*string{&st.State, &st.Nonce, &st.Verifier} {
+		if *s, err = randomString(32); err != nil {
+			rp.fail(c, ctx, err)
+			return
+		}
+	}
+	value, err := rp.codec.encode(stateCookieName, st)
+	if err != nil {
+		rp.fail(c, ctx, err)
+		return
+	}
+	ctx.SetCookie(stateCookieName, value, int(stateMaxAge/time.Second), "/", "",
+		protocol.CookieSameSiteLaxMode, rp.opts.secureCookie, true)
+
+	challenge := sha256.Sum256([]byte(st.Verifier))
+	q := url.Values{}
+	for k, v := range rp.opts.authParams {
+		q.Set(k, v)
+	}
+	q.Set("response_type", "code")
+	q.Set("client_id", rp.clientID)
+	q.Set("redirect_uri", rp.redirectURL)
+	q.Set("scope", rp.scope())
+	q.Set("state", st.State)
+	q.Set("nonce", st.Nonce)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	ctx.Redirect(consts.StatusFound, []byte(appendQuery(p.AuthorizationEndpoint, q)))
+	ctx.Abort()
+}
+
+// CallbackHandler returns the handler of the redirect URL, which exchanges the authorization code for tokens,
+// establishes the session and redirects the user agent back to where the login started.
+func (rp *RelyingParty) CallbackHandler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		value := ctx.Cookie(stateCookieName)
+		deleteCookie(ctx, stateCookieName, rp.opts.secureCookie)
+		if e := ctx.Query("error"); e != "" {
+			rp.fail(c, ctx, fmt.Errorf("oidc: authorization failed: %s %s", e, ctx.Query("error_description")))
+			return
+		}
+
+		st := &loginState{}
+		if len(value) == 0 || rp.codec.decode(stateCookieName, string(value), st) != nil ||
+			!rp.now().Before(st.Expiry) ||
+			subtle.ConstantTimeCompare([]byte(st.State), []byte(ctx.Query("state"))) != 1 {
+			rp.fail(c, ctx, errStateMismatch)
+			return
+		}
+		code := ctx.Query("code")
+		if code == "" {
+			rp.fail(c, ctx, fmt.Errorf("oidc: authorization code is missing"))
+			return
+		}
+
+		p, jwks, err := rp.getProvider(c)
+		if err != nil {
+			rp.fail(c, ctx, err)
+			return
+		}
+		tr, err := rp.token(c, p, map[string]string{
+			"grant_type":    "authorization_code",
+			"code":          code,
+			"redirect_uri":  rp.redirectURL,
+			"code_verifier": st.Verifier,
+		})
+		if err != nil {
+			rp.fail(c, ctx, err)
+			return
+		}
+		if tr.IDToken == "" {
+			rp.fail(c, ctx, fmt.Errorf("oidc: ID token is missing in the token response"))
+			return
+		}
+		claims, err := rp.verifyIDToken(c, p, jwks, tr.IDToken, st.Nonce)
+		if err != nil {
+			rp.fail(c, ctx, err)
+			return
+		}
+
+		s := &Session{
+			Subject:      claims.Subject(),
+			IDToken:      tr.IDToken,
+			AccessToken:  tr.AccessToken,
+			TokenType:    tr.TokenType,
+			RefreshToken: tr.RefreshToken,
+			Expiry:       rp.expiry(tr),
+			Claims:       claims,
+		}
+		if err = rp.opts.sessionStore.Save(c, ctx, s); err != nil {
+			rp.fail(c, ctx, err)
+			return
+		}
+		ctx.Redirect(consts.StatusFound, []byte(st.ReturnTo))
+	}
+}
+
+// LogoutHandler returns a handler which deletes the session, and redirects the user agent to
+// the end session endpoint of the provider if it is supported, or the post logout redirect URL.
+func (rp *RelyingParty) LogoutHandler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		s, _ := rp.opts.sessionStore.Get(c, ctx)
+		if err := rp.opts.sessionStore.Delete(c, ctx); err != nil {
+			hlog.SystemLogger().CtxWarnf(c, "Delete OIDC session error=%v", err)
+		}
+
+		target := rp.opts.postLogoutRedirectURL
+		if p, _, err := rp.getProvider(c); err == nil && p.EndSessionEndpoint != "" {
+			q := url.Values{}
+			q.Set("client_id", rp.clientID)
+			q.Set("post_logout_redirect_uri", rp.opts.postLogoutRedirectURL)
+			if s != nil && s.IDToken != "" {
+				q.Set("id_token_hint", s.IDToken)
+			}
+			target = appendQuery(p.EndSessionEndpoint, q)
+		}
+		ctx.Redirect(consts.StatusFound, []byte(target))
+	}
+}
+
+// refresh refreshes the tokens of s by the refresh token.
+func (rp *RelyingParty) refresh(c context.Context, s *Session) error {
+	if s.RefreshToken == "" {
+		return fmt.Errorf("oidc: session is expired")
+	}
+	p, jwks, err := rp.getProvider(c)
+	if err != nil {
+		return err
+	}
+	tr, err := rp.token(c, p, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": s.RefreshToken,
+	})
+	if err != nil {
+		return err
+	}
+	if tr.IDToken != "" {
+		claims, err := rp.verifyIDToken(c, p, jwks, tr.IDToken, "")
+		if err != nil {
+			return err
+		}
+		if claims.Subject() != s.Subject {
+			return fmt.Errorf("oidc: subject of the refreshed ID token does not match")
+		}
+		s.IDToken, s.Claims = tr.IDToken, claims
+	}
+	s.AccessToken, s.TokenType, s.Expiry = tr.AccessToken, tr.TokenType, rp.expiry(tr)
+	if tr.RefreshToken != "" {
+		s.RefreshToken = tr.RefreshToken
+	}
+	return nil
+}
+
+func (rp *RelyingParty) fail(c context.Context, ctx *app.RequestContext, err error) {
+	hlog.SystemLogger().CtxWarnf(c, "OIDC login error=%v", err)
+	rp.opts.errorHandler(c, ctx, err)
+	ctx.Abort()
+}
+
+func (rp *RelyingParty) scope() string {
+	scopes := []string{"openid"}
+	for _, s := range rp.opts.scopes {
+		if s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+// safeReturnTo only allows local paths to prevent open redirects.
+func safeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		return "/"
+	}
+	return returnTo
+}
+
+func appendQuery(endpoint string, q url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + q.Encode()
+	}
+	return endpoint + "?" + q.Encode()
+}
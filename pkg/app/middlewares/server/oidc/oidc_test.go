@@ -0,0 +1,224 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const (
+	testIssuer      = "https://idp.example.com"
+	testClientID    = "client"
+	testRedirectURL = "https://app.example.com/callback"
+)
+
+var idpKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+// fakeIdP serves the discovery, JWKS and token endpoints of the provider.
+type fakeIdP struct {
+	t         *testing.T
+	now       time.Time
+	challenge string
+	nonce     string
+	refreshed int
+}
+
+func (p *fakeIdP) Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	switch string(req.URI().Path()) {
+	case "/.well-known/openid-configuration":
+		resp.SetBodyString(`{"issuer":"` + testIssuer + `","authorization_endpoint":"` + testIssuer + `/authorize",` +
+			`"token_endpoint":"` + testIssuer + `/token","jwks_uri":"` + testIssuer + `/jwks",` +
+			`"end_session_endpoint":"` + testIssuer + `/logout"}`)
+	case "/jwks":
+		resp.SetBodyString(fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","alg":"RS256","n":%q,"e":%q}]}`,
+			base64.RawURLEncoding.EncodeToString(idpKey.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idpKey.E)).Bytes())))
+	case "/token":
+		assert.DeepEqual(p.t, "Basic "+base64.StdEncoding.EncodeToString([]byte("client:secret")),
+			string(req.Header.Peek(consts.HeaderAuthorization)))
+		args := req.PostArgs()
+		switch string(args.Peek("grant_type")) {
+		case "authorization_code":
+			verifier := sha256.Sum256(args.Peek("code_verifier"))
+			if string(args.Peek("code")) != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+				resp.SetStatusCode(consts.StatusBadRequest)
+				resp.SetBodyString(`{"error":"invalid_grant"}`)
+				return nil
+			}
+			resp.SetBodyString(`{"access_token":"at1","token_type":"Bearer","refresh_token":"rt1","expires_in":60,"id_token":"` +
+				p.idToken(p.nonce) + `"}`)
+		case "refresh_token":
+			assert.DeepEqual(p.t, "rt1", string(args.Peek("refresh_token")))
+			p.refreshed++
+			resp.SetBodyString(`{"access_token":"at2","token_type":"Bearer","expires_in":60}`)
+		}
+	default:
+		resp.SetStatusCode(consts.StatusNotFound)
+	}
+	return nil
+}
+
+func (p *fakeIdP) idToken(nonce string) string {
+	h, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	c, _ := json.Marshal(map[string]interface{}{
+		"iss": testIssuer, "aud": testClientID, "sub": "user1", "nonce": nonce,
+		"exp": p.now.Add(time.Hour).Unix(), "iat": p.now.Unix(),
+	})
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(input))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, idpKey, crypto.SHA256, digest[:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func responseCookie(resp *protocol.Response, name string) *protocol.Cookie {
+	c := &protocol.Cookie{}
+	c.SetKey(name)
+	if !resp.Header.Cookie(c) {
+		return nil
+	}
+	return c
+}
+
+func TestRelyingParty(t *testing.T) {
+	now := time.Now()
+	idp := &fakeIdP{t: t, now: now}
+	rp := New(testIssuer, testClientID, testRedirectURL, WithClient(idp), WithClientSecret("secret"), WithScopes("email"))
+	rp.now = func() time.Time { return now }
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.GET("/callback", rp.CallbackHandler())
+	engine.GET("/logout", rp.LogoutHandler())
+	g := engine.Group("/", rp.Middleware())
+	g.GET("/profile", func(c context.Context, ctx *app.RequestContext) {
+		s, _ := GetSession(ctx)
+		ctx.String(consts.StatusOK, s.Subject+" "+s.AccessToken+" "+s.Claims["nonce"].(string))
+	})
+
+	// the unauthenticated request is redirected to the provider
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/profile?a=b", nil).Result()
+	assert.DeepEqual(t, consts.StatusFound, resp.StatusCode())
+	location, err := url.Parse(string(resp.Header.Peek(consts.HeaderLocation)))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "/authorize", location.Path)
+	q := location.Query()
+	assert.DeepEqual(t, "code", q.Get("response_type"))
+	assert.DeepEqual(t, testClientID, q.Get("client_id"))
+	assert.DeepEqual(t, testRedirectURL, q.Get("redirect_uri"))
+	assert.DeepEqual(t, "openid email", q.Get("scope"))
+	assert.DeepEqual(t, "S256", q.Get("code_challenge_method"))
+	idp.challenge, idp.nonce = q.Get("code_challenge"), q.Get("nonce")
+	state := responseCookie(resp, stateCookieName)
+	assert.NotNil(t, state)
+	assert.True(t, state.HTTPOnly())
+	assert.True(t, state.Secure())
+	stateCookie := ut.Header{Key: "Cookie", Value: stateCookieName + "=" + string(state.Value())}
+
+	// mismatched state
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/callback?code=code&state=other", nil, stateCookie).Result()
+	assert.DeepEqual(t, consts.StatusUnauthorized, resp.StatusCode())
+	// invalid code
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/callback?code=other&state="+q.Get("state"), nil, stateCookie).Result()
+	assert.DeepEqual(t, consts.StatusUnauthorized, resp.StatusCode())
+
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/callback?code=code&state="+q.Get("state"), nil, stateCookie).Result()
+	assert.DeepEqual(t, consts.StatusFound, resp.StatusCode())
+	assert.DeepEqual(t, "/profile?a=b", string(resp.Header.Peek(consts.HeaderLocation)))
+	session := responseCookie(resp, sessionCookieName)
+	assert.NotNil(t, session)
+	sessionCookie := ut.Header{Key: "Cookie", Value: sessionCookieName + "=" + string(session.Value())}
+
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/profile", nil, sessionCookie).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "user1 at1 "+idp.nonce, string(resp.Body()))
+
+	// the expired access token is refreshed
+	now = now.Add(2 * time.Minute)
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/profile", nil, sessionCookie).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "user1 at2 "+idp.nonce, string(resp.Body()))
+	assert.DeepEqual(t, 1, idp.refreshed)
+	assert.NotNil(t, responseCookie(resp, sessionCookieName))
+
+	// tampered session
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/profile", nil,
+		ut.Header{Key: "Cookie", Value: sessionCookieName + "=" + string(session.Value()[1:])}).Result()
+	assert.DeepEqual(t, consts.StatusFound, resp.StatusCode())
+
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/logout", nil, sessionCookie).Result()
+	assert.DeepEqual(t, consts.StatusFound, resp.StatusCode())
+	location, err = url.Parse(string(resp.Header.Peek(consts.HeaderLocation)))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "/logout", location.Path)
+	assert.DeepEqual(t, "/", location.Query().Get("post_logout_redirect_uri"))
+	assert.True(t, location.Query().Get("id_token_hint") != "")
+	assert.DeepEqual(t, protocol.CookieExpireDelete.Unix(), responseCookie(resp, sessionCookieName).Expire().Unix())
+}
+
+func TestRelyingPartyUnauthenticatedHandler(t *testing.T) {
+	rp := New(testIssuer, testClientID, testRedirectURL, WithClient(&fakeIdP{t: t}), WithCookieKey(make([]byte, 16)),
+		WithUnauthenticatedHandler(func(c context.Context, ctx *app.RequestContext, rp *RelyingParty) {
+			ctx.SetStatusCode(consts.StatusUnauthorized)
+		}))
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.GET("/profile", rp.Middleware(), func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "profile")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/profile", nil).Result()
+	assert.DeepEqual(t, consts.StatusUnauthorized, resp.StatusCode())
+
+	assert.Panic(t, func() {
+		New(testIssuer, testClientID, testRedirectURL, WithCookieKey([]byte("short")))
+	})
+}
+
+func TestSafeReturnTo(t *testing.T) {
+	for in, out := range map[string]string{
+		"":                    "/",
+		"/foo?a=b":            "/foo?a=b",
+		"//evil.com":          "/",
+		"/\\evil.com":         "/",
+		"https://evil.com/":   "/",
+		"javascript:alert(1)": "/",
+	} {
+		assert.DeepEqual(t, out, safeReturnTo(in))
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	rp := New(strings.Replace(testIssuer, "idp", "other", 1), testClientID, testRedirectURL, WithClient(&fakeIdP{t: t}))
+	_, _, err := rp.getProvider(context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// Doer sends the requests to the identity provider, *client.Client of hertz is a Doer.
+type Doer interface {
+	Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error
+}
+
+type (
+	options struct {
+		clientSecret          string
+		scopes                []string
+		authParams            map[string]string
+		provider              *Provider
+		client                Doer
+		cookieKey             []byte
+		sessionStore          SessionStore
+		sessionMaxAge         time.Duration
+		secureCookie          bool
+		leeway                time.Duration
+		postLogoutRedirectURL string
+		unauthenticated       func(c context.Context, ctx *app.RequestContext, rp *RelyingParty)
+		errorHandler          func(c context.Context, ctx *app.RequestContext, err error)
+	}
+
+	Option func(o *options)
+)
+
+func defaultErrorHandler(c context.Context, ctx *app.RequestContext, err error) {
+	ctx.AbortWithStatus(consts.StatusUnauthorized)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		scopes:                []string{"openid", "profile", "email"},
+		secureCookie:          true,
+		postLogoutRedirectURL: "/",
+		errorHandler:          defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithClientSecret sets the secret of confidential clients, which is sent by HTTP basic authentication.
+// Public clients rely on PKCE only.
+func WithClientSecret(secret string) Option {
+	return func(o *options) {
+		o.clientSecret = secret
+	}
+}
+
+// WithScopes sets the scopes requested, which are "openid", "profile" and "email" by default.
+// "openid" is always requested.
+func WithScopes(scopes ...string) Option {
+	return func(o *options) {
+		o.scopes = scopes
+	}
+}
+
+// WithAuthParams adds the parameters of the authorization request, e.g. "prompt" or "audience".
+func WithAuthParams(params map[string]string) Option {
+	return func(o *options) {
+		o.authParams = params
+	}
+}
+
+// WithProvider sets the metadata of the identity provider, instead of discovering it from
+// "<issuer>/.well-known/openid-configuration" on the first use.
+func WithProvider(p *Provider) Option {
+	return func(o *options) {
+		o.provider = p
+	}
+}
+
+// WithClient sets the client sending the requests to the identity provider,
+// which is a hertz client by default.
+func WithClient(client Doer) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithCookieKey sets the AES key encrypting the cookies of the login state and the default session store,
+// which must be 16, 24 or 32 bytes.
+//
+// NOTE:
+//
+//	A random key is generated by default, so the sessions do not survive restarts
+//	and are not shared by multiple instances.
+func WithCookieKey(key []byte) Option {
+	return func(o *options) {
+		o.cookieKey = key
+	}
+}
+
+// WithSessionStore sets the store of the sessions, which keeps the sessions in encrypted cookies by default.
+//
+// NOTE:
+//
+//	The tokens may exceed the size limit of cookies (4KB), use a server-side store in that case.
+func WithSessionStore(store SessionStore) Option {
+	return func(o *options) {
+		o.sessionStore = store
+	}
+}
+
+// WithSessionMaxAge sets the max age of the session cookie of the default store,
+// the cookie lasts until the browser is closed by default.
+func WithSessionMaxAge(d time.Duration) Option {
+	return func(o *options) {
+		o.sessionMaxAge = d
+	}
+}
+
+// WithInsecureCookie allows the cookies to be sent over plain HTTP, e.g. for local development.
+func WithInsecureCookie() Option {
+	return func(o *options) {
+		o.secureCookie = false
+	}
+}
+
+// WithLeeway sets the leeway of validating the expiry of the tokens for the clock skew.
+func WithLeeway(d time.Duration) Option {
+	return func(o *options) {
+		o.leeway = d
+	}
+}
+
+// WithPostLogoutRedirectURL sets where the user agent is redirected after logout, which is "/" by default.
+// It is passed to the end session endpoint of the provider if the provider supports it.
+func WithPostLogoutRedirectURL(url string) Option {
+	return func(o *options) {
+		o.postLogoutRedirectURL = url
+	}
+}
+
+// WithUnauthenticatedHandler sets the handler of the unauthenticated requests to the protected routes,
+// which starts the login by default. e.g. APIs may respond 401 instead.
+func WithUnauthenticatedHandler(f func(c context.Context, ctx *app.RequestContext, rp *RelyingParty)) Option {
+	return func(o *options) {
+		o.unauthenticated = f
+	}
+}
+
+// WithErrorHandler sets the handler of the failures of the login,
+// e.g. the mismatched state or the invalid ID token, which responds 401 by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/jwt"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// Provider is the metadata of the identity provider.
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint,omitempty"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// discover fetches the metadata of the provider from the well-known endpoint of issuer.
+func (rp *RelyingParty) discover(ctx context.Context) (*Provider, error) {
+	body, err := rp.get(ctx, strings.TrimSuffix(rp.issuer, "/")+"/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{}
+	if err = json.Unmarshal(body, p); err != nil {
+		return nil, fmt.Errorf("oidc: invalid provider metadata: %w", err)
+	}
+	if strings.TrimSuffix(p.Issuer, "/") != strings.TrimSuffix(rp.issuer, "/") {
+		return nil, fmt.Errorf("oidc: issuer %q does not match the provider %q", rp.issuer, p.Issuer)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: incomplete provider metadata")
+	}
+	return p, nil
+}
+
+// getProvider returns the metadata of the provider, which is discovered on the first use.
+func (rp *RelyingParty) getProvider(ctx context.Context) (*Provider, *jwt.JWKS, error) {
+	rp.mu.RLock()
+	p, jwks := rp.provider, rp.jwks
+	rp.mu.RUnlock()
+	if p != nil {
+		return p, jwks, nil
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.provider != nil {
+		return rp.provider, rp.jwks, nil
+	}
+	p, err := rp.discover(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rp.provider = p
+	rp.jwks = jwt.NewJWKS(p.JWKSURI, jwt.WithJWKSFetcher(rp.get))
+	return rp.provider, rp.jwks, nil
+}
+
+func (rp *RelyingParty) get(ctx context.Context, uri string) ([]byte, error) {
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	defer func() {
+		protocol.ReleaseRequest(req)
+		protocol.ReleaseResponse(resp)
+	}()
+	req.SetRequestURI(uri)
+	req.SetMethod(consts.MethodGet)
+	if err := rp.opts.client.Do(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != consts.StatusOK {
+		return nil, fmt.Errorf("oidc: unexpected status code %d from %s", resp.StatusCode(), uri)
+	}
+	return append([]byte(nil), resp.Body()...), nil
+}
+
+// token sends the token request with form to the token endpoint.
+func (rp *RelyingParty) token(ctx context.Context, p *Provider, form map[string]string) (*tokenResponse, error) {
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	defer func() {
+		protocol.ReleaseRequest(req)
+		protocol.ReleaseResponse(resp)
+	}()
+	req.SetRequestURI(p.TokenEndpoint)
+	req.SetMethod(consts.MethodPost)
+	req.Header.Set(consts.HeaderAccept, "application/json")
+	form["client_id"] = rp.clientID
+	if rp.opts.clientSecret != "" {
+		credentials := url.QueryEscape(rp.clientID) + ":" + url.QueryEscape(rp.opts.clientSecret)
+		req.Header.Set(consts.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	req.SetFormData(form)
+	if err := rp.opts.client.Do(ctx, req, resp); err != nil {
+		return nil, err
+	}
+
+	tr := &tokenResponse{}
+	if err := json.Unmarshal(resp.Body(), tr); err != nil {
+		return nil, fmt.Errorf("oidc: invalid token response with status code %d", resp.StatusCode())
+	}
+	if resp.StatusCode() != consts.StatusOK || tr.Error != "" {
+		return nil, fmt.Errorf("oidc: token request failed with status code %d: %s %s",
+			resp.StatusCode(), tr.Error, tr.ErrorDescription)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oidc: access token is missing in the token response")
+	}
+	return tr, nil
+}
+
+// verifyIDToken verifies the signature and the claims of the ID token.
+// The nonce is not checked if it is empty, e.g. the ID token of the refresh response.
+func (rp *RelyingParty) verifyIDToken(ctx context.Context, p *Provider, jwks *jwt.JWKS, raw, nonce string) (jwt.Claims, error) {
+	_, claims, err := jwt.Parse(ctx, raw, jwks.KeyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer() != p.Issuer {
+		return nil, jwt.ErrInvalidIssuer
+	}
+	aud := claims.Audience()
+	found := false
+	for _, a := range aud {
+		if a == rp.clientID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, jwt.ErrInvalidAudience
+	}
+	if azp, ok := claims["azp"].(string); ok && len(aud) > 1 && azp != rp.clientID {
+		return nil, jwt.ErrInvalidAudience
+	}
+	exp, ok := claims.ExpiresAt()
+	if !ok || !rp.now().Before(exp.Add(rp.opts.leeway)) {
+		return nil, jwt.ErrTokenExpired
+	}
+	if claims.Subject() == "" {
+		return nil, fmt.Errorf("oidc: subject is missing in the ID token")
+	}
+	if nonce != "" {
+		if got, _ := claims["nonce"].(string); got != nonce {
+			return nil, fmt.Errorf("oidc: nonce of the ID token does not match")
+		}
+	}
+	return claims, nil
+}
+
+func (rp *RelyingParty) expiry(tr *tokenResponse) time.Time {
+	if tr.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return rp.now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/middlewares/server/jwt"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+const (
+	sessionCookieName = "oidc_session"
+	stateCookieName   = "oidc_state"
+	stateMaxAge       = 10 * time.Minute
+)
+
+var errInvalidCookie = errors.New("oidc: invalid cookie")
+
+// Session is the authenticated session of a user.
+type Session struct {
+	Subject      string     `json:"sub"`
+	IDToken      string     `json:"id_token"`
+	AccessToken  string     `json:"access_token"`
+	TokenType    string     `json:"token_type,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	Expiry       time.Time  `json:"expiry,omitempty"`
+	Claims       jwt.Claims `json:"claims"`
+}
+
+// expired reports whether the access token is expired at now.
+func (s *Session) expired(now time.Time, leeway time.Duration) bool {
+	return !s.Expiry.IsZero() && !now.Before(s.Expiry.Add(leeway))
+}
+
+// SessionStore keeps the sessions of the users.
+type SessionStore interface {
+	// Get returns the session of the request, which is nil if there is none.
+	Get(c context.Context, ctx *app.RequestContext) (*Session, error)
+	Save(c context.Context, ctx *app.RequestContext, s *Session) error
+	Delete(c context.Context, ctx *app.RequestContext) error
+}
+
+// codec encrypts the values of the cookies by AES-GCM, the name of the cookie is authenticated as well.
+type codec struct {
+	aead cipher.AEAD
+}
+
+func newCodec(key []byte) (*codec, error) {
+	if key == nil {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &codec{aead: aead}, nil
+}
+
+func (c *codec) encode(name string, v interface{}) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, bytesconv.S2b(name))), nil
+}
+
+func (c *codec) decode(name, value string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) < c.aead.NonceSize() {
+		return errInvalidCookie
+	}
+	plaintext, err := c.aead.Open(nil, b[:c.aead.NonceSize()], b[c.aead.NonceSize():], bytesconv.S2b(name))
+	if err != nil {
+		return errInvalidCookie
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// cookieStore keeps the sessions in encrypted cookies.
+type cookieStore struct {
+	codec  *codec
+	maxAge int
+	secure bool
+}
+
+func (s *cookieStore) Get(c context.Context, ctx *app.RequestContext) (*Session, error) {
+	value := ctx.Cookie(sessionCookieName)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	sess := &Session{}
+	if err := s.codec.decode(sessionCookieName, string(value), sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+func (s *cookieStore) Save(c context.Context, ctx *app.RequestContext, sess *Session) error {
+	value, err := s.codec.encode(sessionCookieName, sess)
+	if err != nil {
+		return err
+	}
+	ctx.SetCookie(sessionCookieName, value, s.maxAge, "/", "", protocol.CookieSameSiteLaxMode, s.secure, true)
+	return nil
+}
+
+func (s *cookieStore) Delete(c context.Context, ctx *app.RequestContext) error {
+	deleteCookie(ctx, sessionCookieName, s.secure)
+	return nil
+}
+
+// deleteCookie expires the cookie of name, which is not supported by RequestContext.SetCookie.
+func deleteCookie(ctx *app.RequestContext, name string, secure bool) {
+	cookie := protocol.AcquireCookie()
+	defer protocol.ReleaseCookie(cookie)
+	cookie.SetKey(name)
+	cookie.SetPath("/")
+	cookie.SetExpire(protocol.CookieExpireDelete)
+	cookie.SetSecure(secure)
+	cookie.SetHTTPOnly(true)
+	cookie.SetSameSite(protocol.CookieSameSiteLaxMode)
+	ctx.Response.Header.SetCookie(cookie)
+}
+
+// loginState is kept in the state cookie between the authorization request and the callback.
+type loginState struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	ReturnTo string    `json:"return_to"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Document is the part of an OpenAPI 3 document used to validate the requests.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	routes []*pathRoute
+}
+
+// Components holds the reusable objects referenced by "$ref".
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+}
+
+// PathItem describes the operations of a path.
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Options    *Operation   `json:"options"`
+	Head       *Operation   `json:"head"`
+	Patch      *Operation   `json:"patch"`
+	Trace      *Operation   `json:"trace"`
+}
+
+func (p *PathItem) operations() map[string]*Operation {
+	return map[string]*Operation{
+		"GET": p.Get, "PUT": p.Put, "POST": p.Post, "DELETE": p.Delete,
+		"OPTIONS": p.Options, "HEAD": p.Head, "PATCH": p.Patch, "TRACE": p.Trace,
+	}
+}
+
+// Operation describes an API operation on a path.
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Parameters  []*Parameter `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+
+	// params are the parameters of the operation merged with the ones of the path item.
+	params []*Parameter
+}
+
+// Parameter describes a path, query, header or cookie parameter.
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Explode  *bool   `json:"explode"`
+	Schema   *Schema `json:"schema"`
+}
+
+// explode reports whether the array values are passed as separate parameters,
+// which is the default of the query and cookie parameters.
+func (p *Parameter) explode() bool {
+	if p.Explode != nil {
+		return *p.Explode
+	}
+	return p.In == "query" || p.In == "cookie"
+}
+
+// RequestBody describes the request body of an operation.
+type RequestBody struct {
+	Ref      string                `json:"$ref"`
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// MediaType describes the request body of a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Load parses the OpenAPI 3 document in JSON and resolves the local references,
+// e.g. "#/components/schemas/User".
+//
+// NOTE:
+//
+//	The documents in YAML must be converted into JSON before being loaded.
+//	The references to the other documents are not supported.
+func Load(data []byte) (*Document, error) {
+	doc := &Document{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(doc); err != nil {
+		return nil, fmt.Errorf("openapi: failed to parse document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version %q", doc.OpenAPI)
+	}
+	if err := doc.resolve(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadFile is like Load, but reads the document from the file of name.
+func LoadFile(name string) (*Document, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to read document: %w", err)
+	}
+	return Load(data)
+}
+
+func (d *Document) resolve() error {
+	r := &resolver{doc: d, visited: make(map[*Schema]bool)}
+	for _, s := range d.Components.Schemas {
+		if err := r.schema(s); err != nil {
+			return err
+		}
+	}
+	for path, item := range d.Paths {
+		pathParams, err := r.parameters(item.Parameters)
+		if err != nil {
+			return err
+		}
+		for _, op := range item.operations() {
+			if op == nil {
+				continue
+			}
+			params, err := r.parameters(op.Parameters)
+			if err != nil {
+				return err
+			}
+			op.params = mergeParameters(pathParams, params)
+			if op.RequestBody, err = r.requestBody(op.RequestBody); err != nil {
+				return err
+			}
+		}
+		rt, err := newRoute(path, item)
+		if err != nil {
+			return err
+		}
+		d.routes = append(d.routes, rt)
+	}
+	// the more literal segments, the higher priority, e.g. "/users/me" takes precedence over "/users/{id}"
+	sort.SliceStable(d.routes, func(i, j int) bool {
+		if d.routes[i].literals != d.routes[j].literals {
+			return d.routes[i].literals > d.routes[j].literals
+		}
+		return d.routes[i].path < d.routes[j].path
+	})
+	return nil
+}
+
+// mergeParameters overrides the parameters of the path item by the ones of the operation with the same name and location.
+func mergeParameters(pathParams, opParams []*Parameter) []*Parameter {
+	ret := append([]*Parameter{}, opParams...)
+	for _, p := range pathParams {
+		overridden := false
+		for _, o := range opParams {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+type resolver struct {
+	doc     *Document
+	visited map[*Schema]bool
+}
+
+func refName(ref, prefix string) (string, error) {
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("openapi: unsupported reference %q", ref)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(ref[len(prefix):], "~1", "/"), "~0", "~"), nil
+}
+
+func (r *resolver) parameters(params []*Parameter) ([]*Parameter, error) {
+	ret := make([]*Parameter, 0, len(params))
+	for _, p := range params {
+		if p.Ref != "" {
+			name, err := refName(p.Ref, "#/components/parameters/")
+			if err != nil {
+				return nil, err
+			}
+			target, ok := r.doc.Components.Parameters[name]
+			if !ok || target.Ref != "" {
+				return nil, fmt.Errorf("openapi: unresolved reference %q", p.Ref)
+			}
+			p = target
+		}
+		if p.In == "path" {
+			p.Required = true
+		}
+		if err := r.schema(p.Schema); err != nil {
+			return nil, err
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+func (r *resolver) requestBody(body *RequestBody) (*RequestBody, error) {
+	if body == nil {
+		return nil, nil
+	}
+	if body.Ref != "" {
+		name, err := refName(body.Ref, "#/components/requestBodies/")
+		if err != nil {
+			return nil, err
+		}
+		target, ok := r.doc.Components.RequestBodies[name]
+		if !ok || target.Ref != "" {
+			return nil, fmt.Errorf("openapi: unresolved reference %q", body.Ref)
+		}
+		body = target
+	}
+	for _, mt := range body.Content {
+		if err := r.schema(mt.Schema); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// schema resolves the references in s recursively, the recursive schemas are supported.
+func (r *resolver) schema(s *Schema) error {
+	if s == nil || r.visited[s] {
+		return nil
+	}
+	r.visited[s] = true
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "#/components/schemas/")
+		if err != nil {
+			return err
+		}
+		target, ok := r.doc.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("openapi: unresolved reference %q", s.Ref)
+		}
+		s.ref = target
+		return r.schema(target)
+	}
+	children := []*Schema{s.Items, s.Not}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.Schema)
+	}
+	for _, c := range children {
+		if err := r.schema(c); err != nil {
+			return err
+		}
+	}
+	if s.Pattern != "" {
+		return s.compilePattern()
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// New returns a middleware validating the path, query, header and cookie parameters
+// and the body of the requests against doc before the handlers, e.g.
+//
+//	doc, err := openapi.LoadFile("openapi.json")
+//	h.Use(openapi.New(doc, openapi.WithBasePath("/api/v1")))
+//
+// NOTE:
+//
+//	The bodies in JSON and application/x-www-form-urlencoded are validated,
+//	the other bodies are only checked against the required flag.
+//	The parameters in the styles other than the default ones are not validated.
+func New(doc *Document, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		path := string(ctx.URI().PathOriginal())
+		if cfg.basePath != "" {
+			if !strings.HasPrefix(path, cfg.basePath) {
+				reject(c, ctx, cfg, consts.StatusNotFound)
+				return
+			}
+			path = "/" + strings.TrimLeft(path[len(cfg.basePath):], "/")
+		}
+		item, pathParams, ok := doc.find(path)
+		if !ok {
+			reject(c, ctx, cfg, consts.StatusNotFound)
+			return
+		}
+		op := item.operations()[string(ctx.Method())]
+		if op == nil {
+			reject(c, ctx, cfg, consts.StatusMethodNotAllowed)
+			return
+		}
+
+		if errs := validateRequest(ctx, op, pathParams); len(errs) > 0 {
+			cfg.errorHandler(c, ctx, errs)
+			ctx.Abort()
+			return
+		}
+		ctx.Next(c)
+	}
+}
+
+func reject(c context.Context, ctx *app.RequestContext, cfg *options, status int) {
+	if cfg.strictRoutes {
+		ctx.AbortWithStatus(status)
+		return
+	}
+	ctx.Next(c)
+}
+
+// validateRequest validates the parameters and the body of ctx against op, and returns the violations.
+func validateRequest(ctx *app.RequestContext, op *Operation, pathParams map[string]string) []ValidationError {
+	var errs []ValidationError
+	for _, p := range op.params {
+		raw, ok := paramValues(ctx, p, pathParams)
+		if !ok {
+			if p.Required {
+				errs = append(errs, ValidationError{In: p.In, Name: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		if s := p.Schema.resolved(); s != nil && s.Type.has("object") {
+			continue
+		}
+		v := &validator{in: p.In}
+		if value, bad := coerce(p.Schema, raw, p.explode()); bad != nil {
+			v.checkType(bad, value, p.Name)
+		} else {
+			v.validate(p.Schema, value, p.Name)
+		}
+		errs = append(errs, v.errs...)
+	}
+	if op.RequestBody != nil {
+		errs = append(errs, validateBody(ctx, op.RequestBody)...)
+	}
+	return errs
+}
+
+func paramValues(ctx *app.RequestContext, p *Parameter, pathParams map[string]string) ([]string, bool) {
+	var values []string
+	switch p.In {
+	case "path":
+		if v, ok := pathParams[p.Name]; ok {
+			values = append(values, v)
+		}
+	case "query":
+		for _, v := range ctx.QueryArgs().PeekAll(p.Name) {
+			values = append(values, string(v))
+		}
+	case "header":
+		if v := ctx.Request.Header.Peek(p.Name); v != nil {
+			values = append(values, string(v))
+		}
+	case "cookie":
+		if v := ctx.Request.Header.Cookie(p.Name); v != nil {
+			values = append(values, string(v))
+		}
+	}
+	return values, len(values) > 0
+}
+
+// coerce converts the raw values of a parameter into the value of schema.
+// If a raw value can not be converted, it is returned with the schema it fails to be converted into.
+func coerce(schema *Schema, raw []string, explode bool) (interface{}, *Schema) {
+	s := schema.resolved()
+	if s == nil || !s.Type.has("array") {
+		return coerceScalar(s, raw[0])
+	}
+	if !explode || len(raw) == 1 {
+		raw = strings.Split(strings.Join(raw, ","), ",")
+	}
+	arr := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		item, bad := coerceScalar(s.Items.resolved(), r)
+		if bad != nil {
+			return item, bad
+		}
+		arr = append(arr, item)
+	}
+	return arr, nil
+}
+
+func coerceScalar(s *Schema, raw string) (interface{}, *Schema) {
+	if s == nil || len(s.Type) == 0 || s.Type.has("string") {
+		return raw, nil
+	}
+	if s.Type.has("integer") || s.Type.has("number") {
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw), nil
+		}
+	}
+	if s.Type.has("boolean") && (raw == "true" || raw == "false") {
+		return raw == "true", nil
+	}
+	if s.Type.has("null") && raw == "" {
+		return nil, nil
+	}
+	return raw, s
+}
+
+func validateBody(ctx *app.RequestContext, rb *RequestBody) []ValidationError {
+	body := ctx.Request.Body()
+	if len(body) == 0 {
+		if rb.Required {
+			return []ValidationError{{In: "body", Message: "is required"}}
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(string(ctx.Request.Header.ContentType()))
+	if err != nil {
+		mediaType = ""
+	}
+	mt, ok := findMediaType(rb.Content, mediaType)
+	if !ok {
+		return []ValidationError{{In: "body", Message: "unsupported content type " + strconv.Quote(mediaType)}}
+	}
+	if mt == nil || mt.Schema == nil {
+		return nil
+	}
+
+	var value interface{}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return []ValidationError{{In: "body", Message: "invalid JSON: " + err.Error()}}
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		value = formValue(ctx, mt.Schema.resolved())
+	default:
+		return nil
+	}
+	v := &validator{in: "body"}
+	v.validate(mt.Schema, value, "")
+	return v.errs
+}
+
+// findMediaType returns the media type of the content matching mediaType, e.g. "application/json",
+// "application/*" and "*/*" in turn.
+func findMediaType(content map[string]*MediaType, mediaType string) (*MediaType, bool) {
+	if len(content) == 0 {
+		return nil, true
+	}
+	if mt, ok := content[mediaType]; ok {
+		return mt, true
+	}
+	if i := strings.IndexByte(mediaType, '/'); i > 0 {
+		if mt, ok := content[mediaType[:i]+"/*"]; ok {
+			return mt, true
+		}
+	}
+	mt, ok := content["*/*"]
+	return mt, ok
+}
+
+// formValue converts the form into an object, whose fields are converted by the properties of schema.
+func formValue(ctx *app.RequestContext, schema *Schema) map[string]interface{} {
+	obj := make(map[string]interface{})
+	ctx.PostArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		if _, ok := obj[k]; ok {
+			return
+		}
+		var raw []string
+		for _, v := range ctx.PostArgs().PeekAll(k) {
+			raw = append(raw, string(v))
+		}
+		var ps *Schema
+		if schema != nil {
+			ps = schema.Properties[k]
+		}
+		if s := ps.resolved(); s != nil && s.Type.has("object") {
+			obj[k] = raw[0]
+			return
+		}
+		// the value failing to be converted is kept as string to be reported by the validation
+		obj[k], _ = coerce(ps, raw, true)
+	})
+	return obj
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
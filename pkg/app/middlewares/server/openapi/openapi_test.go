@@ -0,0 +1,233 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const testDocument = `{
+	"openapi": "3.0.3",
+	"paths": {
+		"/users/{id}": {
+			"parameters": [{"name": "id", "in": "path", "schema": {"type": "integer", "minimum": 1}}],
+			"get": {
+				"parameters": [
+					{"$ref": "#/components/parameters/Fields"},
+					{"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+				]
+			},
+			"put": {
+				"requestBody": {"$ref": "#/components/requestBodies/User"}
+			}
+		},
+		"/users/me": {
+			"get": {}
+		},
+		"/users": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "maximum": 100}},
+					{"name": "active", "in": "query", "schema": {"type": "boolean"}},
+					{"name": "tags", "in": "query", "explode": false, "schema": {"type": "array", "maxItems": 2, "items": {"type": "string"}}},
+					{"name": "session", "in": "cookie", "schema": {"type": "string", "minLength": 4}}
+				]
+			},
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {"schema": {"$ref": "#/components/schemas/User"}},
+						"application/x-www-form-urlencoded": {"schema": {"$ref": "#/components/schemas/User"}},
+						"text/*": {}
+					}
+				}
+			}
+		}
+	},
+	"components": {
+		"parameters": {
+			"Fields": {"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string", "enum": ["name", "email"]}}}
+		},
+		"requestBodies": {
+			"User": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}
+		},
+		"schemas": {
+			"User": {
+				"type": "object",
+				"required": ["name"],
+				"additionalProperties": false,
+				"properties": {
+					"name": {"type": "string", "minLength": 1, "maxLength": 8},
+					"email": {"type": "string", "format": "email"},
+					"age": {"type": "integer", "minimum": 0, "exclusiveMinimum": true},
+					"friends": {"type": "array", "items": {"$ref": "#/components/schemas/User"}}
+				}
+			}
+		}
+	}
+}`
+
+type testResponse struct {
+	Message string            `json:"message"`
+	Errors  []ValidationError `json:"errors"`
+}
+
+func perform(t *testing.T, e *route.Engine, method, url, contentType, body string, headers ...ut.Header) (int, []ValidationError) {
+	var b *ut.Body
+	if body != "" {
+		b = &ut.Body{Body: strings.NewReader(body), Len: len(body)}
+		headers = append(headers, ut.Header{Key: consts.HeaderContentType, Value: contentType})
+	}
+	resp := ut.PerformRequest(e, method, url, b, headers...).Result()
+	if resp.StatusCode() != consts.StatusBadRequest {
+		return resp.StatusCode(), nil
+	}
+	var r testResponse
+	assert.Nil(t, json.Unmarshal(resp.Body(), &r))
+	assert.DeepEqual(t, "request validation failed", r.Message)
+	return resp.StatusCode(), r.Errors
+}
+
+func TestParameters(t *testing.T) {
+	doc, err := Load([]byte(testDocument))
+	assert.Nil(t, err)
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	}
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(doc, WithBasePath("/api")))
+	e.GET("/api/users", handler)
+	e.GET("/api/users/:id", handler)
+	tenant := ut.Header{Key: "X-Tenant", Value: "123e4567-e89b-12d3-a456-426614174000"}
+
+	code, _ := perform(t, e, consts.MethodGet, "/api/users/1?fields=name&fields=email", "", "", tenant)
+	assert.DeepEqual(t, consts.StatusOK, code)
+
+	code, errs := perform(t, e, consts.MethodGet, "/api/users/0?fields=name&fields=phone", "", "")
+	assert.DeepEqual(t, consts.StatusBadRequest, code)
+	assert.DeepEqual(t, []ValidationError{
+		{In: "query", Name: "fields/1", Message: `must be one of ["name","email"]`},
+		{In: "header", Name: "X-Tenant", Message: "is required"},
+		{In: "path", Name: "id", Message: "must be >= 1"},
+	}, errs)
+
+	_, errs = perform(t, e, consts.MethodGet, "/api/users/abc", "", "", ut.Header{Key: "X-Tenant", Value: "not-uuid"})
+	assert.DeepEqual(t, []ValidationError{
+		{In: "header", Name: "X-Tenant", Message: "must be a valid uuid"},
+		{In: "path", Name: "id", Message: "must be integer"},
+	}, errs)
+
+	// the literal path takes precedence over the template
+	code, _ = perform(t, e, consts.MethodGet, "/api/users/me", "", "")
+	assert.DeepEqual(t, consts.StatusOK, code)
+
+	code, _ = perform(t, e, consts.MethodGet, "/api/users?limit=10&active=true&tags=a,b", "", "", ut.Header{Key: consts.HeaderCookie, Value: "session=abcd"})
+	assert.DeepEqual(t, consts.StatusOK, code)
+	_, errs = perform(t, e, consts.MethodGet, "/api/users?limit=1000&active=yes&tags=a,b,c", "", "", ut.Header{Key: consts.HeaderCookie, Value: "session=abc"})
+	assert.DeepEqual(t, []ValidationError{
+		{In: "query", Name: "limit", Message: "must be <= 100"},
+		{In: "query", Name: "active", Message: "must be boolean"},
+		{In: "query", Name: "tags", Message: "must have <= 2 items"},
+		{In: "cookie", Name: "session", Message: "length must be >= 4"},
+	}, errs)
+}
+
+func TestBody(t *testing.T) {
+	doc, err := Load([]byte(testDocument))
+	assert.Nil(t, err)
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	}
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(doc, WithBasePath("/api/")))
+	e.POST("/api/users", handler)
+	e.PUT("/api/users/:id", handler)
+
+	code, _ := perform(t, e, consts.MethodPost, "/api/users", "application/json", `{"name":"hertz","age":1,"friends":[{"name":"kitex"}]}`)
+	assert.DeepEqual(t, consts.StatusOK, code)
+
+	_, errs := perform(t, e, consts.MethodPost, "/api/users", "application/json; charset=utf-8",
+		`{"name":"", "email":"invalid", "age":0, "role":"admin", "friends":[{"age":1.5}]}`)
+	assert.DeepEqual(t, []ValidationError{
+		{In: "body", Name: "/age", Message: "must be > 0"},
+		{In: "body", Name: "/email", Message: "must be a valid email"},
+		{In: "body", Name: "/friends/0/name", Message: "is required"},
+		{In: "body", Name: "/friends/0/age", Message: "must be integer"},
+		{In: "body", Name: "/name", Message: "length must be >= 1"},
+		{In: "body", Name: "/role", Message: "is not allowed"},
+	}, errs)
+
+	_, errs = perform(t, e, consts.MethodPost, "/api/users", "", "")
+	assert.DeepEqual(t, []ValidationError{{In: "body", Message: "is required"}}, errs)
+	_, errs = perform(t, e, consts.MethodPost, "/api/users", "application/xml", "<user/>")
+	assert.DeepEqual(t, []ValidationError{{In: "body", Message: `unsupported content type "application/xml"`}}, errs)
+	_, errs = perform(t, e, consts.MethodPost, "/api/users", "application/json", "{")
+	assert.DeepEqual(t, 1, len(errs))
+
+	// the bodies without schema are not validated
+	code, _ = perform(t, e, consts.MethodPost, "/api/users", "text/plain", "hello")
+	assert.DeepEqual(t, consts.StatusOK, code)
+
+	code, _ = perform(t, e, consts.MethodPost, "/api/users", "application/x-www-form-urlencoded", "name=hertz&age=2")
+	assert.DeepEqual(t, consts.StatusOK, code)
+	_, errs = perform(t, e, consts.MethodPost, "/api/users", "application/x-www-form-urlencoded", "name=hertz&age=old")
+	assert.DeepEqual(t, []ValidationError{{In: "body", Name: "/age", Message: "must be integer"}}, errs)
+
+	_, errs = perform(t, e, consts.MethodPut, "/api/users/1", "application/json", `{"name":"hertz-framework"}`)
+	assert.DeepEqual(t, []ValidationError{{In: "body", Name: "/name", Message: "length must be <= 8"}}, errs)
+}
+
+func TestRoutes(t *testing.T) {
+	doc, err := Load([]byte(testDocument))
+	assert.Nil(t, err)
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	}
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(doc, WithBasePath("/api")))
+	e.GET("/api/health", handler)
+	code, _ := perform(t, e, consts.MethodGet, "/api/health", "", "")
+	assert.DeepEqual(t, consts.StatusOK, code)
+
+	var got []ValidationError
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(doc, WithBasePath("/api"), WithStrictRoutes(), WithErrorHandler(func(c context.Context, ctx *app.RequestContext, errs []ValidationError) {
+		got = errs
+		ctx.AbortWithStatus(consts.StatusUnprocessableEntity)
+	})))
+	e.GET("/api/health", handler)
+	e.GET("/api/users", handler)
+	e.GET("/api/users/:id", handler)
+	code, _ = perform(t, e, consts.MethodGet, "/api/health", "", "")
+	assert.DeepEqual(t, consts.StatusNotFound, code)
+	code, _ = perform(t, e, consts.MethodPost, "/api/users/me", "", "")
+	assert.DeepEqual(t, consts.StatusMethodNotAllowed, code)
+	code, _ = perform(t, e, consts.MethodGet, "/api/users?limit=x", "", "")
+	assert.DeepEqual(t, consts.StatusUnprocessableEntity, code)
+	assert.DeepEqual(t, "limit", got[0].Name)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		basePath     string
+		strictRoutes bool
+		errorHandler func(c context.Context, ctx *app.RequestContext, errs []ValidationError)
+	}
+
+	Option func(o *options)
+)
+
+func defaultErrorHandler(c context.Context, ctx *app.RequestContext, errs []ValidationError) {
+	ctx.AbortWithStatusJSON(consts.StatusBadRequest, utils.H{
+		"message": "request validation failed",
+		"errors":  errs,
+	})
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		errorHandler: defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithBasePath sets the prefix of the request paths which is not in the paths of the document,
+// e.g. "/api/v1" of the server url "https://example.com/api/v1".
+func WithBasePath(path string) Option {
+	return func(o *options) {
+		o.basePath = path
+	}
+}
+
+// WithStrictRoutes rejects the requests not described by the document with 404 or 405,
+// which are passed to the handlers by default.
+func WithStrictRoutes() Option {
+	return func(o *options) {
+		o.strictRoutes = true
+	}
+}
+
+// WithErrorHandler sets the handler of the invalid requests,
+// which responds 400 with the errors in JSON by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, errs []ValidationError)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var paramRE = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// pathRoute matches the request paths against a path template like "/users/{id}".
+type pathRoute struct {
+	path     string
+	re       *regexp.Regexp
+	names    []string
+	literals int
+	item     *PathItem
+}
+
+func newRoute(path string, item *PathItem) (*pathRoute, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("openapi: path %q must start with /", path)
+	}
+	rt := &pathRoute{path: path, item: item}
+	var sb strings.Builder
+	sb.WriteByte('^')
+	last := 0
+	for _, m := range paramRE.FindAllStringSubmatchIndex(path, -1) {
+		sb.WriteString(regexp.QuoteMeta(path[last:m[0]]))
+		sb.WriteString("([^/]+)")
+		rt.names = append(rt.names, path[m[2]:m[3]])
+		last = m[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(path[last:]))
+	sb.WriteByte('$')
+	rt.re = regexp.MustCompile(sb.String())
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && !strings.Contains(seg, "{") {
+			rt.literals++
+		}
+	}
+	return rt, nil
+}
+
+// match returns the unescaped path parameters if path matches the template.
+func (rt *pathRoute) match(path string) (map[string]string, bool) {
+	m := rt.re.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(rt.names))
+	for i, name := range rt.names {
+		v, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			v = m[i+1]
+		}
+		params[name] = v
+	}
+	return params, true
+}
+
+// find returns the path item matching path and its path parameters.
+func (d *Document) find(path string) (*PathItem, map[string]string, bool) {
+	for _, rt := range d.routes {
+		if params, ok := rt.match(path); ok {
+			return rt.item, params, true
+		}
+	}
+	return nil, nil, false
+}
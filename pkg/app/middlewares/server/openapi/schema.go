@@ -0,0 +1,445 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is the subset of the JSON Schema used by OpenAPI 3.0 and 3.1 to validate the requests.
+//
+// NOTE:
+//
+//	Only the formats date-time, date, email, uuid, ipv4, ipv6, uri, byte, int32 and int64
+//	are checked, the others are ignored.
+type Schema struct {
+	Ref                  string                `json:"$ref"`
+	Type                 schemaType            `json:"type"`
+	Format               string                `json:"format"`
+	Enum                 []interface{}         `json:"enum"`
+	Nullable             bool                  `json:"nullable"`
+	Required             []string              `json:"required"`
+	Properties           map[string]*Schema    `json:"properties"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties"`
+	MinProperties        *int                  `json:"minProperties"`
+	MaxProperties        *int                  `json:"maxProperties"`
+	Items                *Schema               `json:"items"`
+	MinItems             *int                  `json:"minItems"`
+	MaxItems             *int                  `json:"maxItems"`
+	UniqueItems          bool                  `json:"uniqueItems"`
+	MinLength            *int                  `json:"minLength"`
+	MaxLength            *int                  `json:"maxLength"`
+	Pattern              string                `json:"pattern"`
+	Minimum              *float64              `json:"minimum"`
+	Maximum              *float64              `json:"maximum"`
+	ExclusiveMinimum     exclusiveBound        `json:"exclusiveMinimum"`
+	ExclusiveMaximum     exclusiveBound        `json:"exclusiveMaximum"`
+	MultipleOf           *float64              `json:"multipleOf"`
+	AllOf                []*Schema             `json:"allOf"`
+	AnyOf                []*Schema             `json:"anyOf"`
+	OneOf                []*Schema             `json:"oneOf"`
+	Not                  *Schema               `json:"not"`
+
+	ref     *Schema
+	pattern *regexp.Regexp
+}
+
+// schemaType is the type of a schema, which is a string in OpenAPI 3.0 and can be an array in 3.1.
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = schemaType{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(data, &ss); err != nil {
+		return err
+	}
+	*t = ss
+	return nil
+}
+
+func (t schemaType) has(typ string) bool {
+	for _, s := range t {
+		if s == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusiveBound is a bool in OpenAPI 3.0 modifying minimum or maximum, and a number in 3.1.
+type exclusiveBound struct {
+	exclusive bool
+	value     *float64
+}
+
+func (b *exclusiveBound) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.exclusive); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &b.value)
+}
+
+// AdditionalProperties is either a bool or a schema.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+func (s *Schema) compilePattern() (err error) {
+	if s.pattern, err = regexp.Compile(s.Pattern); err != nil {
+		return fmt.Errorf("openapi: invalid pattern %q: %w", s.Pattern, err)
+	}
+	return nil
+}
+
+func (s *Schema) resolved() *Schema {
+	for s != nil && s.ref != nil {
+		s = s.ref
+	}
+	return s
+}
+
+// ValidationError describes a value of the request violating the document.
+type ValidationError struct {
+	// In is the location of the value, which is path, query, header, cookie or body.
+	In string `json:"in"`
+	// Name is the name of the parameter, or the JSON pointer of the value in the body.
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Name == "" {
+		return e.In + ": " + e.Message
+	}
+	return e.In + " " + e.Name + ": " + e.Message
+}
+
+type validator struct {
+	in   string
+	errs []ValidationError
+}
+
+func (v *validator) fail(name, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{In: v.in, Name: name, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid reports whether value is valid against s without recording the errors.
+func (v *validator) valid(s *Schema, value interface{}, name string) bool {
+	sub := &validator{in: v.in}
+	sub.validate(s, value, name)
+	return len(sub.errs) == 0
+}
+
+func (v *validator) validate(s *Schema, value interface{}, name string) {
+	s = s.resolved()
+	if s == nil {
+		return
+	}
+	if value == nil {
+		if !s.Nullable && len(s.Type) > 0 && !s.Type.has("null") {
+			v.fail(name, "must not be null")
+		}
+		return
+	}
+	if len(s.Type) > 0 && !v.checkType(s, value, name) {
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if equal(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(name, "must be one of %s", formatEnum(s.Enum))
+		}
+	}
+
+	switch x := value.(type) {
+	case string:
+		v.validateString(s, x, name)
+	case json.Number:
+		v.validateNumber(s, x, name)
+	case []interface{}:
+		v.validateArray(s, x, name)
+	case map[string]interface{}:
+		v.validateObject(s, x, name)
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(sub, value, name)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if v.valid(sub, value, name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(name, "must match at least one schema of anyOf")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if v.valid(sub, value, name) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(name, "must match exactly one schema of oneOf, but matches %d", matched)
+		}
+	}
+	if s.Not != nil && v.valid(s.Not, value, name) {
+		v.fail(name, "must not match the schema of not")
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch x := value.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := x.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+func (v *validator) checkType(s *Schema, value interface{}, name string) bool {
+	typ := typeOf(value)
+	if s.Type.has(typ) || (typ == "integer" && s.Type.has("number")) {
+		return true
+	}
+	types := make([]string, 0, len(s.Type))
+	for _, t := range s.Type {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	v.fail(name, "must be %s", strings.Join(types, " or "))
+	return false
+}
+
+func (v *validator) validateString(s *Schema, str, name string) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		v.fail(name, "length must be >= %d", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		v.fail(name, "length must be <= %d", *s.MaxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		v.fail(name, "must match pattern %s", s.Pattern)
+	}
+	if s.Format != "" && !validFormat(s.Format, str) {
+		v.fail(name, "must be a valid %s", s.Format)
+	}
+}
+
+var uuidRE = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uuid":
+		return uuidRE.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "byte":
+		_, err := base64.StdEncoding.DecodeString(s)
+		return err == nil
+	}
+	return true
+}
+
+func (v *validator) validateNumber(s *Schema, num json.Number, name string) {
+	f, err := num.Float64()
+	if err != nil {
+		v.fail(name, "must be a number")
+		return
+	}
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum.exclusive && f <= *s.Minimum {
+			v.fail(name, "must be > %s", formatFloat(*s.Minimum))
+		} else if f < *s.Minimum {
+			v.fail(name, "must be >= %s", formatFloat(*s.Minimum))
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum.exclusive && f >= *s.Maximum {
+			v.fail(name, "must be < %s", formatFloat(*s.Maximum))
+		} else if f > *s.Maximum {
+			v.fail(name, "must be <= %s", formatFloat(*s.Maximum))
+		}
+	}
+	if b := s.ExclusiveMinimum.value; b != nil && f <= *b {
+		v.fail(name, "must be > %s", formatFloat(*b))
+	}
+	if b := s.ExclusiveMaximum.value; b != nil && f >= *b {
+		v.fail(name, "must be < %s", formatFloat(*b))
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := f / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(name, "must be a multiple of %s", formatFloat(*s.MultipleOf))
+		}
+	}
+	switch s.Format {
+	case "int32":
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			v.fail(name, "must be a valid int32")
+		}
+	case "int64":
+		if f < math.MinInt64 || f >= -math.MinInt64 {
+			v.fail(name, "must be a valid int64")
+		}
+	}
+}
+
+func (v *validator) validateArray(s *Schema, arr []interface{}, name string) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		v.fail(name, "must have >= %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		v.fail(name, "must have <= %d items", *s.MaxItems)
+	}
+	if s.UniqueItems {
+	loop:
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if equal(arr[i], arr[j]) {
+					v.fail(name, "must have unique items")
+					break loop
+				}
+			}
+		}
+	}
+	if s.Items != nil {
+		for i, item := range arr {
+			v.validate(s.Items, item, name+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, obj map[string]interface{}, name string) {
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok {
+			v.fail(joinPointer(name, key), "is required")
+		}
+	}
+	if s.MinProperties != nil && len(obj) < *s.MinProperties {
+		v.fail(name, "must have >= %d properties", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(obj) > *s.MaxProperties {
+		v.fail(name, "must have <= %d properties", *s.MaxProperties)
+	}
+	for _, key := range sortedKeys(obj) {
+		value := obj[key]
+		if ps, ok := s.Properties[key]; ok {
+			v.validate(ps, value, joinPointer(name, key))
+			continue
+		}
+		if ap := s.AdditionalProperties; ap != nil {
+			if !ap.Allowed {
+				v.fail(joinPointer(name, key), "is not allowed")
+			} else if ap.Schema != nil {
+				v.validate(ap.Schema, value, joinPointer(name, key))
+			}
+		}
+	}
+}
+
+// joinPointer appends key to the JSON pointer name.
+func joinPointer(name, key string) string {
+	return name + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// equal compares the decoded JSON values, the numbers are compared by value.
+func equal(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, err1 := na.Float64()
+		fb, err2 := nb.Float64()
+		return err1 == nil && err2 == nil && fa == fb
+	}
+	ba, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ba, bb)
+}
+
+func formatEnum(enum []interface{}) string {
+	b, _ := json.Marshal(enum)
+	return string(b)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func validate(t *testing.T, schema, value string) []string {
+	var s Schema
+	dec := json.NewDecoder(bytes.NewReader([]byte(schema)))
+	dec.UseNumber()
+	assert.Nil(t, dec.Decode(&s))
+	assert.Nil(t, (&resolver{doc: &Document{}, visited: map[*Schema]bool{}}).schema(&s))
+
+	var v interface{}
+	dec = json.NewDecoder(bytes.NewReader([]byte(value)))
+	dec.UseNumber()
+	assert.Nil(t, dec.Decode(&v))
+
+	vd := &validator{in: "body"}
+	vd.validate(&s, v, "")
+	var msgs []string
+	for _, e := range vd.errs {
+		msgs = append(msgs, e.Name+" "+e.Message)
+	}
+	return msgs
+}
+
+func TestSchema(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		value  string
+		errs   []string
+	}{
+		{`{"type": "string"}`, `null`, []string{" must not be null"}},
+		{`{"type": "string", "nullable": true}`, `null`, nil},
+		{`{"type": ["string", "null"]}`, `null`, nil},
+		{`{"type": ["string", "null"]}`, `1`, []string{" must be string"}},
+		{`{"type": "number"}`, `1`, nil},
+		{`{"type": "integer"}`, `1.0`, nil},
+		{`{"type": "integer", "format": "int32"}`, `3000000000`, []string{" must be a valid int32"}},
+		{`{"type": "number", "exclusiveMaximum": 10, "multipleOf": 0.5}`, `10`, []string{" must be < 10"}},
+		{`{"type": "number", "multipleOf": 0.5}`, `1.25`, []string{" must be a multiple of 0.5"}},
+		{`{"type": "string", "pattern": "^[a-z]+$"}`, `"abc1"`, []string{" must match pattern ^[a-z]+$"}},
+		{`{"type": "string", "maxLength": 2}`, `"你好"`, nil},
+		{`{"type": "string", "format": "date-time"}`, `"2022-01-02T15:04:05Z"`, nil},
+		{`{"type": "string", "format": "date"}`, `"2022-13-01"`, []string{" must be a valid date"}},
+		{`{"type": "string", "format": "ipv4"}`, `"::1"`, []string{" must be a valid ipv4"}},
+		{`{"type": "string", "format": "uri"}`, `"/relative"`, []string{" must be a valid uri"}},
+		{`{"type": "string", "format": "unknown"}`, `"anything"`, nil},
+		{`{"enum": [1, "a", null]}`, `1.0`, nil},
+		{`{"enum": [1, "a"]}`, `"b"`, []string{` must be one of [1,"a"]`}},
+		{`{"type": "array", "uniqueItems": true, "minItems": 4}`, `[1, {"a": 1}, {"a": 1}]`, []string{" must have >= 4 items", " must have unique items"}},
+		{`{"type": "object", "minProperties": 1, "additionalProperties": {"type": "integer"}}`, `{"a/b": "x"}`, []string{"/a~1b must be integer"}},
+		{`{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`, `{"a": 1}`, []string{"/b is required"}},
+		{`{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []string{" must match at least one schema of anyOf"}},
+		{`{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []string{" must match exactly one schema of oneOf, but matches 2"}},
+		{`{"not": {"type": "string"}}`, `"a"`, []string{" must not match the schema of not"}},
+	} {
+		assert.DeepEqual(t, tc.errs, validate(t, tc.schema, tc.value))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, doc := range []string{
+		`not json`,
+		`{"openapi": "2.0"}`,
+		`{"openapi": "3.0.0", "paths": {"users": {}}}`,
+		`{"openapi": "3.0.0", "paths": {"/users": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`,
+		`{"openapi": "3.0.0", "paths": {"/users": {"post": {"requestBody": {"$ref": "other.json#/Body"}}}}}`,
+		`{"openapi": "3.0.0", "components": {"schemas": {"A": {"$ref": "#/components/schemas/B"}}}}`,
+		`{"openapi": "3.0.0", "components": {"schemas": {"A": {"type": "string", "pattern": "("}}}}`,
+	} {
+		_, err := Load([]byte(doc))
+		assert.NotNil(t, err)
+	}
+
+	_, err := LoadFile("missing.json")
+	assert.NotNil(t, err)
+}
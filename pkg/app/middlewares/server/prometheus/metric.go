@@ -0,0 +1,288 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// DefBuckets are the default buckets of the request duration in seconds.
+	DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// DefSizeBuckets are the default buckets of the response size in bytes.
+	DefSizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+)
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// metricVec is a metric partitioned by the values of its labels.
+type metricVec struct {
+	name       string
+	help       string
+	typ        string
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newMetricVec(name, help, typ string, labelNames []string) *metricVec {
+	if !metricNameRE.MatchString(name) {
+		panic(fmt.Sprintf("prometheus: invalid metric name %q", name))
+	}
+	for _, l := range labelNames {
+		if !labelNameRE.MatchString(l) || strings.HasPrefix(l, "__") || (typ == "histogram" && l == "le") {
+			panic(fmt.Sprintf("prometheus: invalid label name %q of metric %q", l, name))
+		}
+	}
+	return &metricVec{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+	}
+}
+
+// Name implements Collector.
+func (v *metricVec) Name() string {
+	return v.name
+}
+
+// get returns the series of labelValues, v.mu must be held.
+func (v *metricVec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("prometheus: metric %q expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if v.buckets != nil {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// Collect implements Collector.
+func (v *metricVec) Collect(buf *bytes.Buffer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.help != "" {
+		buf.WriteString("# HELP ")
+		buf.WriteString(v.name)
+		buf.WriteByte(' ')
+		buf.WriteString(escapeHelp(v.help))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("# TYPE ")
+	buf.WriteString(v.name)
+	buf.WriteByte(' ')
+	buf.WriteString(v.typ)
+	buf.WriteByte('\n')
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		if v.typ != "histogram" {
+			writeSample(buf, v.name, v.labelNames, s.labelValues, "", "", s.value)
+			continue
+		}
+		var cumulative uint64
+		for i, b := range v.buckets {
+			cumulative += s.counts[i]
+			writeSample(buf, v.name+"_bucket", v.labelNames, s.labelValues, "le", formatFloat(b), float64(cumulative))
+		}
+		writeSample(buf, v.name+"_bucket", v.labelNames, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(buf, v.name+"_sum", v.labelNames, s.labelValues, "", "", s.sum)
+		writeSample(buf, v.name+"_count", v.labelNames, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+// CounterVec is a counter partitioned by the values of its labels.
+type CounterVec struct {
+	*metricVec
+}
+
+// NewCounterVec creates a counter, it panics if the name or any label name is invalid.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newMetricVec(name, help, "counter", labelNames)}
+}
+
+// Inc increments the counter of labelValues by 1.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta to the counter of labelValues, it panics if delta<0.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("prometheus: counter cannot decrease")
+	}
+	c.mu.Lock()
+	c.get(labelValues).value += delta
+	c.mu.Unlock()
+}
+
+// GaugeVec is a gauge partitioned by the values of its labels.
+type GaugeVec struct {
+	*metricVec
+}
+
+// NewGaugeVec creates a gauge, it panics if the name or any label name is invalid.
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{newMetricVec(name, help, "gauge", labelNames)}
+}
+
+// Set sets the gauge of labelValues to value.
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value = value
+	g.mu.Unlock()
+}
+
+// Add adds delta to the gauge of labelValues.
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value += delta
+	g.mu.Unlock()
+}
+
+// Inc increments the gauge of labelValues by 1.
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec decrements the gauge of labelValues by 1.
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// HistogramVec is a histogram partitioned by the values of its labels.
+type HistogramVec struct {
+	*metricVec
+}
+
+// NewHistogramVec creates a histogram with the upper bounds of the buckets, which must be
+// sorted in increasing order. It panics if the name, any label name or the buckets are invalid.
+//
+// NOTE:
+//
+//	The +Inf bucket is always added, it is dropped from buckets if present.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	v := newMetricVec(name, help, "histogram", labelNames)
+	if n := len(buckets); n > 0 && math.IsInf(buckets[n-1], 1) {
+		buckets = buckets[:n-1]
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("prometheus: buckets of histogram %q must be in increasing order", name))
+		}
+	}
+	v.buckets = append(make([]float64, 0, len(buckets)), buckets...)
+	return &HistogramVec{v}
+}
+
+// Observe adds value to the histogram of labelValues.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	i := sort.SearchFloat64s(h.buckets, value)
+	h.mu.Lock()
+	s := h.get(labelValues)
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+	h.mu.Unlock()
+}
+
+func writeSample(buf *bytes.Buffer, name string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	buf.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		buf.WriteByte('{')
+		for i, l := range labelNames {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, l, labelValues[i])
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, extraName, extraValue)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+func writeLabel(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	buf.WriteString(labelValueReplacer.Replace(value))
+	buf.WriteByte('"')
+}
+
+var (
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestMetricVec(t *testing.T) {
+	r := NewRegistry()
+	counter := NewCounterVec("requests_total", "Total requests.\nWith \\ newline.", "path")
+	gauge := NewGaugeVec("temperature", "")
+	histogram := NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1, math.Inf(1)}, "code")
+	r.MustRegister(counter, gauge, histogram)
+
+	counter.Inc(`/a"b`)
+	counter.Add(2, "/c\n")
+	counter.Inc(`/a"b`)
+	gauge.Set(10)
+	gauge.Dec()
+	histogram.Observe(0.05, "200")
+	histogram.Observe(0.1, "200")
+	histogram.Observe(0.5, "200")
+	histogram.Observe(5, "200")
+
+	var buf bytes.Buffer
+	r.Gather(&buf)
+	assert.DeepEqual(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{code="200",le="0.1"} 2
+latency_seconds_bucket{code="200",le="1"} 3
+latency_seconds_bucket{code="200",le="+Inf"} 4
+latency_seconds_sum{code="200"} 5.65
+latency_seconds_count{code="200"} 4
+# HELP requests_total Total requests.\nWith \\ newline.
+# TYPE requests_total counter
+requests_total{path="/a\"b"} 2
+requests_total{path="/c\n"} 2
+# TYPE temperature gauge
+temperature 9
+`, buf.String())
+
+	assert.NotNil(t, r.Register(NewCounterVec("temperature", "")))
+	assert.True(t, r.Unregister("temperature"))
+	assert.False(t, r.Unregister("temperature"))
+	assert.Nil(t, r.Register(NewCounterVec("temperature", "")))
+
+	assert.Panic(t, func() { counter.Add(-1, "/") })
+	assert.Panic(t, func() { counter.Inc() })
+	assert.Panic(t, func() { NewCounterVec("invalid-name", "") })
+	assert.Panic(t, func() { NewCounterVec("name", "", "__reserved") })
+	assert.Panic(t, func() { NewHistogramVec("name", "", nil, "le") })
+	assert.Panic(t, func() { NewHistogramVec("name", "", []float64{1, 1}) })
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+type (
+	options struct {
+		registry        *Registry
+		namespace       string
+		durationBuckets []float64
+		sizeBuckets     []float64
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		namespace:       "hertz",
+		durationBuckets: DefBuckets,
+		sizeBuckets:     DefSizeBuckets,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.registry == nil {
+		cfg.registry = NewRegistry()
+	}
+	return cfg
+}
+
+// WithRegistry sets the registry where the metrics are registered,
+// which is a new registry by default.
+func WithRegistry(r *Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithNamespace sets the prefix of the metric names, which is "hertz" by default.
+// The metric names are not prefixed if namespace is empty.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithDurationBuckets sets the buckets of the request duration in seconds, which is DefBuckets by default.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.durationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the buckets of the response size in bytes, which is DefSizeBuckets by default.
+func WithSizeBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.sizeBuckets = buckets
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/tracer"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+)
+
+var _ tracer.Tracer = (*Metrics)(nil)
+
+// Metrics collects the metrics of the HTTP requests, labeled by method, route template and status:
+//
+//	hertz_http_requests_total{method,route,status}
+//	hertz_http_request_duration_seconds{method,route,status}
+//	hertz_http_response_size_bytes{method,route,status}
+//	hertz_http_requests_in_flight
+//
+// The route of the requests which match no route is empty, so that the raw paths never end up in labels.
+type Metrics struct {
+	registry *Registry
+	requests *CounterVec
+	duration *HistogramVec
+	size     *HistogramVec
+	inFlight *GaugeVec
+}
+
+// New creates the metrics and registers them into the registry, it panics if they have been registered.
+//
+// The metrics are collected either by the middleware:
+//
+//	m := prometheus.New()
+//	h.Use(m.Middleware())
+//	h.GET("/metrics", m.Handler())
+//
+// or by the tracer, which also covers the requests failing before the handlers, e.g. on timeout:
+//
+//	h := server.Default(server.WithTracer(m))
+//
+// NOTE:
+//
+//	Use only one of them, otherwise the requests are counted twice.
+func New(opts ...Option) *Metrics {
+	cfg := newOptions(opts...)
+	prefix := ""
+	if cfg.namespace != "" {
+		prefix = cfg.namespace + "_"
+	}
+
+	m := &Metrics{
+		registry: cfg.registry,
+		requests: NewCounterVec(prefix+"http_requests_total",
+			"Total number of HTTP requests.", "method", "route", "status"),
+		duration: NewHistogramVec(prefix+"http_request_duration_seconds",
+			"Duration of HTTP requests in seconds.", cfg.durationBuckets, "method", "route", "status"),
+		size: NewHistogramVec(prefix+"http_response_size_bytes",
+			"Size of HTTP responses in bytes.", cfg.sizeBuckets, "method", "route", "status"),
+		inFlight: NewGaugeVec(prefix+"http_requests_in_flight",
+			"Number of HTTP requests being served."),
+	}
+	m.registry.MustRegister(m.requests, m.duration, m.size, m.inFlight)
+	return m
+}
+
+// Registry returns the registry of m, where the custom metrics can be registered.
+func (m *Metrics) Registry() *Registry {
+	return m.registry
+}
+
+// Handler returns a handler serving the metrics of the registry of m.
+func (m *Metrics) Handler() app.HandlerFunc {
+	return Handler(m.registry)
+}
+
+// Middleware returns a middleware collecting the metrics of the requests passing through it.
+//
+// NOTE:
+//
+//	The response size is the length of the body, or the Content-Length of the body stream.
+func (m *Metrics) Middleware() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+		start := time.Now()
+
+		ctx.Next(c)
+
+		size := ctx.Response.Header.ContentLength()
+		if !ctx.Response.IsBodyStream() {
+			size = len(ctx.Response.BodyBytes())
+		}
+		m.observe(string(ctx.Method()), ctx.FullPath(), ctx.Response.StatusCode(), time.Since(start), size)
+	}
+}
+
+// Start implements tracer.Tracer.
+func (m *Metrics) Start(c context.Context, ctx *app.RequestContext) context.Context {
+	m.inFlight.Inc()
+	return c
+}
+
+// Finish implements tracer.Tracer, the duration is measured between the HTTPStart and HTTPFinish events,
+// and the response size includes the header.
+func (m *Metrics) Finish(c context.Context, ctx *app.RequestContext) {
+	m.inFlight.Dec()
+
+	st := ctx.GetTraceInfo().Stats()
+	var duration time.Duration
+	start, finish := st.GetEvent(stats.HTTPStart), st.GetEvent(stats.HTTPFinish)
+	if start != nil && !start.IsNil() && finish != nil && !finish.IsNil() {
+		duration = finish.Time().Sub(start.Time())
+	}
+	m.observe(string(ctx.Method()), ctx.FullPath(), ctx.Response.StatusCode(), duration, st.SendSize())
+}
+
+func (m *Metrics) observe(method, route string, status int, duration time.Duration, size int) {
+	code := strconv.Itoa(status)
+	m.requests.Inc(method, route, code)
+	m.duration.Observe(duration.Seconds(), method, route, code)
+	if size >= 0 {
+		m.size.Observe(float64(size), method, route, code)
+	}
+}
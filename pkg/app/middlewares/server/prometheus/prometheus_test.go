@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func gather(m *Metrics) string {
+	var buf bytes.Buffer
+	m.Registry().Gather(&buf)
+	return buf.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New(WithNamespace("test"), WithDurationBuckets([]float64{10}), WithSizeBuckets([]float64{2, 10}))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(m.Middleware())
+	e.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "hello")
+	})
+	e.GET("/metrics", m.Handler())
+
+	ut.PerformRequest(e, consts.MethodGet, "/user/1", nil)
+	ut.PerformRequest(e, consts.MethodGet, "/user/2", nil)
+	resp := ut.PerformRequest(e, consts.MethodGet, "/metrics", nil).Result()
+	assert.DeepEqual(t, ContentType, string(resp.Header.ContentType()))
+
+	body := string(resp.Body())
+	for _, line := range []string{
+		`test_http_requests_total{method="GET",route="/user/:id",status="200"} 2`,
+		`test_http_request_duration_seconds_count{method="GET",route="/user/:id",status="200"} 2`,
+		`test_http_response_size_bytes_bucket{method="GET",route="/user/:id",status="200",le="2"} 0`,
+		`test_http_response_size_bytes_bucket{method="GET",route="/user/:id",status="200",le="10"} 2`,
+		`test_http_response_size_bytes_sum{method="GET",route="/user/:id",status="200"} 10`,
+		"test_http_requests_in_flight 1",
+	} {
+		assert.True(t, strings.Contains(body, line+"\n"))
+	}
+	// the request being served is not counted yet
+	assert.False(t, strings.Contains(body, `route="/metrics"`))
+	assert.True(t, strings.Contains(gather(m), `test_http_requests_total{method="GET",route="/metrics",status="200"} 1`))
+	assert.True(t, strings.Contains(gather(m), "test_http_requests_in_flight 0\n"))
+
+	// the metrics can not be registered twice
+	assert.Panic(t, func() { New(WithRegistry(m.Registry()), WithNamespace("test")) })
+	New(WithRegistry(m.Registry()), WithNamespace(""))
+	assert.True(t, strings.Contains(gather(m), "# TYPE http_requests_total counter\n"))
+}
+
+func TestTracer(t *testing.T) {
+	m := New()
+	ctx := app.NewContext(0)
+	ti := traceinfo.NewTraceInfo()
+	ti.Stats().SetLevel(stats.LevelBase)
+	ctx.SetTraceInfo(ti)
+
+	c := m.Start(context.Background(), ctx)
+	assert.True(t, strings.Contains(gather(m), "hertz_http_requests_in_flight 1\n"))
+
+	ti.Stats().Record(stats.HTTPStart, stats.StatusInfo, "")
+	ctx.Request.Header.SetMethod(consts.MethodPost)
+	ctx.SetStatusCode(consts.StatusNotFound)
+	ti.Stats().SetSendSize(120)
+	ti.Stats().Record(stats.HTTPFinish, stats.StatusInfo, "")
+	m.Finish(c, ctx)
+
+	body := gather(m)
+	for _, line := range []string{
+		`hertz_http_requests_total{method="POST",route="",status="404"} 1`,
+		`hertz_http_response_size_bytes_bucket{method="POST",route="",status="404",le="1000"} 1`,
+		`hertz_http_response_size_bytes_sum{method="POST",route="",status="404"} 120`,
+		"hertz_http_requests_in_flight 0",
+	} {
+		assert.True(t, strings.Contains(body, line+"\n"))
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Collector writes the samples of a metric family in the text exposition format.
+type Collector interface {
+	// Name returns the name of the metric family, which must be unique in a registry.
+	Name() string
+	// Collect writes the HELP, TYPE and sample lines of the metric family into buf.
+	Collect(buf *bytes.Buffer)
+}
+
+// Registry holds the collectors exposed by the same endpoint.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Register adds c into the registry, it fails if a collector of the same name has been registered.
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.collectors[c.Name()]; ok {
+		return fmt.Errorf("prometheus: duplicate metric %q", c.Name())
+	}
+	r.collectors[c.Name()] = c
+	return nil
+}
+
+// MustRegister is like Register, but panics on failure.
+func (r *Registry) MustRegister(cs ...Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Unregister removes the collector of name, and reports whether it has been registered.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.collectors[name]
+	delete(r.collectors, name)
+	return ok
+}
+
+// Gather writes all the metric families sorted by name in the text exposition format into buf.
+func (r *Registry) Gather(buf *bytes.Buffer) {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+	for _, c := range collectors {
+		c.Collect(buf)
+	}
+}
+
+// Handler returns a handler serving the metrics of r, e.g.
+//
+//	h.GET("/metrics", prometheus.Handler(registry))
+func Handler(r *Registry) app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		var buf bytes.Buffer
+		r.Gather(&buf)
+		ctx.Data(consts.StatusOK, ContentType, buf.Bytes())
+	}
+}
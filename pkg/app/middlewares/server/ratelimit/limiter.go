@@ -0,0 +1,222 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval of removing the state of the idle keys.
+const sweepInterval = time.Minute
+
+// Result is the result of taking a request of a key from the Limiter.
+type Result struct {
+	// Allowed reports whether the request is allowed.
+	Allowed bool
+	// Limit is the max number of the requests allowed in a burst or a window.
+	Limit int
+	// Remaining is the number of the requests which are still allowed.
+	Remaining int
+	// Reset is the duration until the quota is fully restored.
+	Reset time.Duration
+	// RetryAfter is the duration until the next request is allowed, 0 if the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter limits the rate of the requests of each key.
+type Limiter interface {
+	// Take takes a request of key, and reports whether it is allowed in the Result.
+	Take(ctx context.Context, key string) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type tokenBucket struct {
+	rate  float64
+	burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewTokenBucket returns a Limiter allowing rate requests per second for each key,
+// with bursts of at most burst requests.
+func NewTokenBucket(rate float64, burst int) Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *tokenBucket) Take(_ context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	res := Result{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = l.duration(1 - b.tokens)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = l.duration(float64(l.burst) - b.tokens)
+	return res, nil
+}
+
+// duration returns the duration of restoring n tokens.
+func (l *tokenBucket) duration(n float64) time.Duration {
+	if l.rate <= 0 {
+		return math.MaxInt64
+	}
+	return time.Duration(n / l.rate * float64(time.Second))
+}
+
+// sweep removes the buckets which have been restored fully.
+func (l *tokenBucket) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type windowState struct {
+	start time.Time
+	curr  int
+	prev  int
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*windowState
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewSlidingWindow returns a Limiter allowing limit requests within any window of each key.
+// The requests in the previous window are weighted by its overlap with the sliding window.
+// If window<=0, it is one second.
+func NewSlidingWindow(limit int, window time.Duration) Limiter {
+	if limit < 1 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &slidingWindow{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*windowState),
+		now:     time.Now,
+	}
+}
+
+func (l *slidingWindow) Take(_ context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	start := now.Truncate(l.window)
+	w := l.windows[key]
+	if w == nil {
+		w = &windowState{start: start}
+		l.windows[key] = w
+	}
+	switch {
+	case w.start.Equal(start):
+	case w.start.Add(l.window).Equal(start):
+		w.start, w.prev, w.curr = start, w.curr, 0
+	default:
+		w.start, w.prev, w.curr = start, 0, 0
+	}
+
+	elapsed := now.Sub(start)
+	return slidingWindowResult(l.limit, l.window, elapsed, w.prev, &w.curr), nil
+}
+
+// slidingWindowResult counts the request in curr if it is allowed, and returns the result.
+func slidingWindowResult(limit int, window, elapsed time.Duration, prev int, curr *int) Result {
+	weight := float64(window-elapsed) / float64(window)
+	count := float64(prev)*weight + float64(*curr)
+	res := Result{Limit: limit}
+	switch {
+	case count+1 <= float64(limit):
+		*curr++
+		count++
+		res.Allowed = true
+	case *curr+1 > limit:
+		// the requests of the current window are weighted in the next window
+		res.RetryAfter = window - elapsed + time.Duration((1-float64(limit-1)/float64(*curr))*float64(window))
+	default:
+		// the weight of the previous window decreases until the request fits
+		res.RetryAfter = time.Duration((count + 1 - float64(limit)) / float64(prev) * float64(window))
+	}
+	res.Remaining = limit - int(math.Ceil(count))
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	res.Reset = window - elapsed
+	if *curr > 0 {
+		res.Reset += window
+	}
+	return res
+}
+
+// sweep removes the windows which have expired.
+func (l *slidingWindow) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= 2*l.window {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewTokenBucket(2, 3).(*tokenBucket)
+	l.now = func() time.Time { return now }
+
+	for i := 2; i >= 0; i-- {
+		res, err := l.Take(context.Background(), "a")
+		assert.Nil(t, err)
+		assert.True(t, res.Allowed)
+		assert.DeepEqual(t, 3, res.Limit)
+		assert.DeepEqual(t, i, res.Remaining)
+	}
+	res, _ := l.Take(context.Background(), "a")
+	assert.False(t, res.Allowed)
+	assert.DeepEqual(t, 500*time.Millisecond, res.RetryAfter)
+	assert.DeepEqual(t, 1500*time.Millisecond, res.Reset)
+
+	// the other keys are limited separately
+	res, _ = l.Take(context.Background(), "b")
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = l.Take(context.Background(), "a")
+	assert.True(t, res.Allowed)
+	assert.DeepEqual(t, 0, res.Remaining)
+
+	// the idle buckets are removed
+	now = now.Add(sweepInterval)
+	_, _ = l.Take(context.Background(), "c")
+	assert.DeepEqual(t, 1, len(l.buckets))
+}
+
+func TestSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewSlidingWindow(4, 10*time.Second).(*slidingWindow)
+	l.now = func() time.Time { return now }
+
+	for i := 3; i >= 0; i-- {
+		res, err := l.Take(context.Background(), "a")
+		assert.Nil(t, err)
+		assert.True(t, res.Allowed)
+		assert.DeepEqual(t, 4, res.Limit)
+		assert.DeepEqual(t, i, res.Remaining)
+	}
+	res, _ := l.Take(context.Background(), "a")
+	assert.False(t, res.Allowed)
+	// 4 requests weighted 3/4 in the next window fit the limit
+	assert.DeepEqual(t, 10*time.Second+2500*time.Millisecond, res.RetryAfter)
+	assert.DeepEqual(t, 20*time.Second, res.Reset)
+
+	// half of the previous window overlaps with the sliding window
+	now = now.Add(15 * time.Second)
+	for i := 1; i >= 0; i-- {
+		res, _ = l.Take(context.Background(), "a")
+		assert.True(t, res.Allowed)
+		assert.DeepEqual(t, i, res.Remaining)
+	}
+	res, _ = l.Take(context.Background(), "a")
+	assert.False(t, res.Allowed)
+	assert.DeepEqual(t, 2500*time.Millisecond, res.RetryAfter)
+
+	// the windows long ago are forgotten
+	now = now.Add(20 * time.Second)
+	res, _ = l.Take(context.Background(), "a")
+	assert.True(t, res.Allowed)
+	assert.DeepEqual(t, 3, res.Remaining)
+
+	now = now.Add(sweepInterval)
+	_, _ = l.Take(context.Background(), "b")
+	assert.DeepEqual(t, 1, len(l.windows))
+}
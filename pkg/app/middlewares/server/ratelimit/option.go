@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type (
+	options struct {
+		keyFunc       func(c context.Context, ctx *app.RequestContext) string
+		rejectHandler func(c context.Context, ctx *app.RequestContext, res Result)
+		errorHandler  func(c context.Context, ctx *app.RequestContext, err error)
+		disableHeader bool
+	}
+
+	Option func(o *options)
+)
+
+// KeyByIP returns the client IP of the request as the key.
+func KeyByIP(c context.Context, ctx *app.RequestContext) string {
+	return ctx.ClientIP()
+}
+
+// KeyByHeader returns the function which returns the value of the header as the key.
+func KeyByHeader(header string) func(c context.Context, ctx *app.RequestContext) string {
+	return func(c context.Context, ctx *app.RequestContext) string {
+		return string(ctx.Request.Header.Peek(header))
+	}
+}
+
+func defaultRejectHandler(c context.Context, ctx *app.RequestContext, res Result) {
+	if res.RetryAfter > 0 {
+		ctx.Response.Header.Set(consts.HeaderRetryAfter, strconv.FormatInt(ceilSeconds(res.RetryAfter), 10))
+	}
+	ctx.AbortWithStatus(consts.StatusTooManyRequests)
+}
+
+// defaultErrorHandler lets the request pass, so the requests are not rejected
+// if the storage of the limiter is unavailable.
+func defaultErrorHandler(c context.Context, ctx *app.RequestContext, err error) {
+	ctx.Next(c)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		keyFunc:       KeyByIP,
+		rejectHandler: defaultRejectHandler,
+		errorHandler:  defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithKeyFunc sets the function returning the key whose requests are limited,
+// KeyByIP by default.
+func WithKeyFunc(f func(c context.Context, ctx *app.RequestContext) string) Option {
+	return func(o *options) {
+		o.keyFunc = f
+	}
+}
+
+// WithRejectHandler sets the handler of the rejected requests, which responds
+// 429 with Retry-After by default.
+func WithRejectHandler(f func(c context.Context, ctx *app.RequestContext, res Result)) Option {
+	return func(o *options) {
+		o.rejectHandler = f
+	}
+}
+
+// WithErrorHandler sets the handler of the requests failing to be limited,
+// which lets them pass by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
+
+// WithDisableHeader disables the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// of the responses.
+func WithDisableHeader(disable bool) Option {
+	return func(o *options) {
+		o.disableHeader = disable
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	if d > math.MaxInt64-time.Second {
+		return int64(d / time.Second)
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
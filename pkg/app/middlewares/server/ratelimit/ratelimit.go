@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ratelimit provides the middleware limiting the rate of the requests by keys,
+// e.g. the client IP.
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// The headers of the rate limit, see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// New returns a middleware limiting the rate of the requests of each key with limiter,
+// e.g.
+//
+//	h.Use(ratelimit.New(ratelimit.NewTokenBucket(10, 20)))
+//
+// NOTE:
+//
+//	The requests with the empty key are not limited.
+func New(limiter Limiter, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		key := cfg.keyFunc(c, ctx)
+		if key == "" {
+			ctx.Next(c)
+			return
+		}
+		res, err := limiter.Take(c, key)
+		if err != nil {
+			cfg.errorHandler(c, ctx, err)
+			return
+		}
+		if !cfg.disableHeader {
+			h := &ctx.Response.Header
+			h.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			h.Set(HeaderRateLimitReset, strconv.FormatInt(ceilSeconds(res.Reset), 10))
+		}
+		if !res.Allowed {
+			cfg.rejectHandler(c, ctx, res)
+			return
+		}
+		ctx.Next(c)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestRateLimit(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(NewTokenBucket(1, 2), WithKeyFunc(KeyByHeader("X-User"))))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+
+	for i := 1; i >= 0; i-- {
+		resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil, ut.Header{Key: "X-User", Value: "a"}).Result()
+		assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+		assert.DeepEqual(t, "2", resp.Header.Get(HeaderRateLimitLimit))
+		assert.DeepEqual(t, string(rune('0'+i)), resp.Header.Get(HeaderRateLimitRemaining))
+	}
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil, ut.Header{Key: "X-User", Value: "a"}).Result()
+	assert.DeepEqual(t, consts.StatusTooManyRequests, resp.StatusCode())
+	assert.DeepEqual(t, "1", resp.Header.Get(consts.HeaderRetryAfter))
+	assert.DeepEqual(t, "2", resp.Header.Get(HeaderRateLimitReset))
+
+	// the requests without key are not limited
+	for i := 0; i < 3; i++ {
+		resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+		assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+		assert.DeepEqual(t, "", resp.Header.Get(HeaderRateLimitLimit))
+	}
+}
+
+func TestRateLimitOptions(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(NewSlidingWindow(1, time.Minute),
+		WithDisableHeader(true),
+		WithRejectHandler(func(c context.Context, ctx *app.RequestContext, res Result) {
+			ctx.AbortWithMsg("slow down", consts.StatusServiceUnavailable)
+		}),
+	))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, "", resp.Header.Get(HeaderRateLimitLimit))
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusServiceUnavailable, resp.StatusCode())
+	assert.DeepEqual(t, "slow down", string(resp.Body()))
+}
+
+type errLimiter struct{}
+
+func (errLimiter) Take(ctx context.Context, key string) (Result, error) {
+	return Result{}, errors.New("unavailable")
+}
+
+func TestRateLimitError(t *testing.T) {
+	// the requests pass if the limiter fails by default
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(errLimiter{}))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+
+	engine = route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(errLimiter{}, WithErrorHandler(func(c context.Context, ctx *app.RequestContext, err error) {
+		ctx.AbortWithStatus(consts.StatusInternalServerError)
+	})))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusInternalServerError, resp.StatusCode())
+}
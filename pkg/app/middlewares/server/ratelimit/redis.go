@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient is the Redis client used by the Store returned by NewRedisStore,
+// which evaluates the Lua script and returns the integer reply as int64,
+// e.g. the adapter of github.com/redis/go-redis:
+//
+//	type redisClient struct {
+//		*redis.Client
+//	}
+//
+//	func (c redisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		return c.Client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+const (
+	// redisIncrByScript sets the ttl of the counter if it is created by INCRBY.
+	redisIncrByScript = `local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n`
+
+	// redisGetScript returns 0 instead of nil if the counter does not exist.
+	redisGetScript = `return tonumber(redis.call('GET', KEYS[1]) or '0')`
+)
+
+type redisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore returns a Store keeping the counters in Redis with client,
+// the keys of the counters are prefixed with prefix.
+func NewRedisStore(client RedisClient, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) IncrBy(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	reply, err := s.client.Eval(ctx, redisIncrByScript, []string{s.prefix + key}, n, ms)
+	if err != nil {
+		return 0, err
+	}
+	return redisInt(reply)
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (int64, error) {
+	reply, err := s.client.Eval(ctx, redisGetScript, []string{s.prefix + key})
+	if err != nil {
+		return 0, err
+	}
+	return redisInt(reply)
+}
+
+func redisInt(reply interface{}) (int64, error) {
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	}
+	return 0, fmt.Errorf("unexpected reply of redis: %v", reply)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store stores the counters of the requests, which is shared by the replicas of a service
+// to enforce the limits consistently across them.
+type Store interface {
+	// IncrBy adds n to the counter of key and returns the result.
+	// The counter is created with ttl if it does not exist or has expired.
+	IncrBy(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+
+	// Get returns the counter of key, or 0 if it does not exist or has expired.
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+type counter struct {
+	n        int64
+	expireAt time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore returns a Store keeping the counters in memory,
+// which is not shared by the replicas.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		counters: make(map[string]*counter),
+		now:      time.Now,
+	}
+}
+
+func (s *memoryStore) IncrBy(_ context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	c := s.counters[key]
+	if c == nil || !now.Before(c.expireAt) {
+		c = &counter{expireAt: now.Add(ttl)}
+		s.counters[key] = c
+	}
+	c.n += n
+	return c.n, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.counters[key]; c != nil && s.now().Before(c.expireAt) {
+		return c.n, nil
+	}
+	return 0, nil
+}
+
+// sweep removes the expired counters.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counters {
+		if !now.Before(c.expireAt) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+type storeSlidingWindow struct {
+	store  Store
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewStoreSlidingWindow returns a Limiter like NewSlidingWindow,
+// which keeps the counters of the windows in store, e.g. the one returned by NewRedisStore.
+//
+// NOTE:
+//
+//	The counters of the windows are keyed by the key of the request with the suffix ":<index of the window>".
+func NewStoreSlidingWindow(store Store, limit int, window time.Duration) Limiter {
+	if limit < 1 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &storeSlidingWindow{
+		store:  store,
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+func (l *storeSlidingWindow) Take(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	start := now.Truncate(l.window)
+	idx := start.UnixNano() / int64(l.window)
+	currKey := key + ":" + strconv.FormatInt(idx, 10)
+	prevKey := key + ":" + strconv.FormatInt(idx-1, 10)
+
+	prev, err := l.store.Get(ctx, prevKey)
+	if err != nil {
+		return Result{}, err
+	}
+	// the counters expire once they are out of the sliding window
+	curr, err := l.store.IncrBy(ctx, currKey, 1, 2*l.window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	n := int(curr - 1)
+	res := slidingWindowResult(l.limit, l.window, now.Sub(start), int(prev), &n)
+	if !res.Allowed {
+		// the rejected requests are not counted
+		if _, err = l.store.IncrBy(ctx, currKey, -1, 2*l.window); err != nil {
+			return Result{}, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore().(*memoryStore)
+	s.now = func() time.Time { return now }
+
+	n, err := s.IncrBy(context.Background(), "a", 2, time.Second)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(2), n)
+	n, _ = s.IncrBy(context.Background(), "a", -1, time.Minute)
+	assert.DeepEqual(t, int64(1), n)
+	n, _ = s.Get(context.Background(), "a")
+	assert.DeepEqual(t, int64(1), n)
+
+	// the ttl is set by the first increment
+	now = now.Add(time.Second)
+	n, _ = s.Get(context.Background(), "a")
+	assert.DeepEqual(t, int64(0), n)
+	n, _ = s.IncrBy(context.Background(), "a", 1, time.Second)
+	assert.DeepEqual(t, int64(1), n)
+}
+
+func TestStoreSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryStore().(*memoryStore)
+	store.now = func() time.Time { return now }
+	// the replicas share the store
+	replicas := []*storeSlidingWindow{
+		NewStoreSlidingWindow(store, 4, 10*time.Second).(*storeSlidingWindow),
+		NewStoreSlidingWindow(store, 4, 10*time.Second).(*storeSlidingWindow),
+	}
+	for _, l := range replicas {
+		l.now = store.now
+	}
+
+	for i := 3; i >= 0; i-- {
+		res, err := replicas[i%2].Take(context.Background(), "a")
+		assert.Nil(t, err)
+		assert.True(t, res.Allowed)
+		assert.DeepEqual(t, i, res.Remaining)
+	}
+	res, _ := replicas[0].Take(context.Background(), "a")
+	assert.False(t, res.Allowed)
+	assert.DeepEqual(t, 10*time.Second+2500*time.Millisecond, res.RetryAfter)
+
+	now = now.Add(15 * time.Second)
+	for i := 1; i >= 0; i-- {
+		res, _ = replicas[i%2].Take(context.Background(), "a")
+		assert.True(t, res.Allowed)
+		assert.DeepEqual(t, i, res.Remaining)
+	}
+	res, _ = replicas[1].Take(context.Background(), "a")
+	assert.False(t, res.Allowed)
+	assert.DeepEqual(t, 2500*time.Millisecond, res.RetryAfter)
+}
+
+// fakeRedis evaluates the scripts of the redis store with the memory store.
+type fakeRedis struct {
+	store   Store
+	scripts []string
+	err     error
+}
+
+func (r *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.scripts = append(r.scripts, script)
+	switch script {
+	case redisIncrByScript:
+		return r.store.IncrBy(ctx, keys[0], args[0].(int64), time.Duration(args[1].(int64))*time.Millisecond)
+	case redisGetScript:
+		return r.store.Get(ctx, keys[0])
+	}
+	return nil, errors.New("unknown script")
+}
+
+func TestRedisStore(t *testing.T) {
+	client := &fakeRedis{store: NewMemoryStore()}
+	s := NewRedisStore(client, "ratelimit:")
+
+	n, err := s.IncrBy(context.Background(), "a", 1, time.Minute)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(1), n)
+	n, err = s.Get(context.Background(), "a")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(1), n)
+	n, _ = client.store.Get(context.Background(), "ratelimit:a")
+	assert.DeepEqual(t, int64(1), n)
+	assert.DeepEqual(t, []string{redisIncrByScript, redisGetScript}, client.scripts)
+
+	client.err = errors.New("unavailable")
+	_, err = NewStoreSlidingWindow(s, 1, time.Second).Take(context.Background(), "a")
+	assert.DeepEqual(t, client.err, err)
+
+	for reply, expected := range map[interface{}]int64{int64(1): 1, 2: 2, "3": 3} {
+		n, err = redisInt(reply)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, expected, n)
+	}
+	_, err = redisInt(nil)
+	assert.NotNil(t, err)
+}
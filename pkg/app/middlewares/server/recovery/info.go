@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package recovery
+
+import (
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Redacted replaces the values of the redacted headers and query parameters in PanicInfo.
+const Redacted = "REDACTED"
+
+// PanicInfo is the details of a recovered panic passed to the alert handler.
+type PanicInfo struct {
+	// Err is the value recovered from the panic.
+	Err interface{}
+	// Stack is the captured stack, which is nil if it is disabled.
+	Stack []byte
+
+	Method   string
+	Route    string
+	ClientIP string
+	// URI is the path and the redacted query string of the request.
+	URI string
+	// Header is the redacted headers of the request.
+	Header map[string]string
+}
+
+func newPanicInfo(ctx *app.RequestContext, err interface{}, stack []byte, cfg *options) *PanicInfo {
+	info := &PanicInfo{
+		Err:      err,
+		Stack:    stack,
+		Method:   string(ctx.Method()),
+		Route:    ctx.FullPath(),
+		ClientIP: ctx.ClientIP(),
+		URI:      string(ctx.Path()),
+		Header:   make(map[string]string),
+	}
+
+	ctx.Request.Header.VisitAll(func(key, value []byte) {
+		k := string(key)
+		if _, ok := cfg.redactHeaders[strings.ToLower(k)]; ok {
+			info.Header[k] = Redacted
+			return
+		}
+		info.Header[k] = string(value)
+	})
+
+	query := ctx.URI().QueryArgs()
+	if query.Len() == 0 {
+		return info
+	}
+	if len(cfg.redactQueries) == 0 {
+		info.URI += "?" + query.String()
+		return info
+	}
+	var args protocol.Args
+	query.VisitAll(func(key, value []byte) {
+		k := string(key)
+		if _, ok := cfg.redactQueries[k]; ok {
+			args.Add(k, Redacted)
+			return
+		}
+		args.Add(k, string(value))
+	})
+	info.URI += "?" + args.String()
+	return info
+}
@@ -18,6 +18,7 @@ package recovery
 
 import (
 	"context"
+	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
@@ -27,6 +28,12 @@ import (
 type (
 	options struct {
 		recoveryHandler func(c context.Context, ctx *app.RequestContext, err interface{}, stack []byte)
+		alertHandler    func(c context.Context, info *PanicInfo)
+		disableStack    bool
+		allGoroutines   bool
+		frameFilter     func(function, file string) bool
+		redactHeaders   map[string]struct{}
+		redactQueries   map[string]struct{}
 	}
 
 	Option func(o *options)
@@ -40,6 +47,12 @@ func defaultRecoveryHandler(c context.Context, ctx *app.RequestContext, err inte
 func newOptions(opts ...Option) *options {
 	cfg := &options{
 		recoveryHandler: defaultRecoveryHandler,
+		redactHeaders: map[string]struct{}{
+			strings.ToLower(consts.HeaderAuthorization):      {},
+			strings.ToLower(consts.HeaderProxyAuthorization): {},
+			strings.ToLower(consts.HeaderCookie):             {},
+		},
+		redactQueries: make(map[string]struct{}),
 	}
 
 	for _, opt := range opts {
@@ -54,3 +67,64 @@ func WithRecoveryHandler(f func(c context.Context, ctx *app.RequestContext, err
 		o.recoveryHandler = f
 	}
 }
+
+// WithAlertHandler sets the callback invoked with the details of the panic before the recovery handler,
+// e.g. to report the panic to an alerting system.
+//
+// NOTE:
+//
+//	The headers and query parameters of the request in PanicInfo are redacted, see WithRedactHeaders.
+func WithAlertHandler(f func(c context.Context, info *PanicInfo)) Option {
+	return func(o *options) {
+		o.alertHandler = f
+	}
+}
+
+// WithDisableStack disables capturing the stack, which is nil in the recovery handler.
+func WithDisableStack() Option {
+	return func(o *options) {
+		o.disableStack = true
+	}
+}
+
+// WithAllGoroutines captures the stacks of all goroutines in the format of runtime.Stack,
+// instead of the stack of the panicking goroutine only.
+//
+// NOTE:
+//
+//	Capturing all goroutines stops the world, and the frame filter is not applied.
+func WithAllGoroutines() Option {
+	return func(o *options) {
+		o.allGoroutines = true
+	}
+}
+
+// WithStackFrameFilter sets the filter of the frames of the stack,
+// only the frames for which f returns true are kept.
+// function is the full name of the function with the package path, e.g.
+// "github.com/cloudwego/hertz/pkg/app.(*RequestContext).Next".
+func WithStackFrameFilter(f func(function, file string) bool) Option {
+	return func(o *options) {
+		o.frameFilter = f
+	}
+}
+
+// WithRedactHeaders sets the request headers whose values are redacted in PanicInfo.
+// Authorization, Proxy-Authorization and Cookie are redacted by default.
+func WithRedactHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.redactHeaders = make(map[string]struct{}, len(headers))
+		for _, h := range headers {
+			o.redactHeaders[strings.ToLower(h)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactQueries sets the query parameters whose values are redacted in PanicInfo.
+func WithRedactQueries(keys ...string) Option {
+	return func(o *options) {
+		for _, k := range keys {
+			o.redactQueries[k] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package recovery
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/json"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ProblemContentType is the content type of the problem details defined by RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the problem details of RFC 7807.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// ProblemDetailsHandler is a recovery handler which logs the panic as the default one,
+// and responds with the problem details of RFC 7807 instead of a bare 500, e.g.
+//
+//	{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/foo"}
+//
+// NOTE:
+//
+//	The panic value is not exposed to the client.
+//	Use NewProblemDetailsHandler to customize the problem details.
+func ProblemDetailsHandler(c context.Context, ctx *app.RequestContext, err interface{}, stack []byte) {
+	hlog.SystemLogger().CtxErrorf(c, "[Recovery] err=%v\nstack=%s", err, stack)
+	RenderProblem(ctx, &Problem{
+		Type:     "about:blank",
+		Title:    consts.StatusMessage(consts.StatusInternalServerError),
+		Status:   consts.StatusInternalServerError,
+		Instance: string(ctx.Path()),
+	})
+}
+
+// NewProblemDetailsHandler returns a recovery handler which logs the panic as the default one,
+// and responds with the problem details built by f.
+func NewProblemDetailsHandler(f func(ctx *app.RequestContext, err interface{}) *Problem) func(c context.Context, ctx *app.RequestContext, err interface{}, stack []byte) {
+	return func(c context.Context, ctx *app.RequestContext, err interface{}, stack []byte) {
+		hlog.SystemLogger().CtxErrorf(c, "[Recovery] err=%v\nstack=%s", err, stack)
+		RenderProblem(ctx, f(ctx, err))
+	}
+}
+
+// RenderProblem aborts the request and writes p as the response with ProblemContentType.
+// The status code is 500 if p.Status is not set.
+func RenderProblem(ctx *app.RequestContext, p *Problem) {
+	if p.Status == 0 {
+		p.Status = consts.StatusInternalServerError
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		ctx.AbortWithStatus(p.Status)
+		return
+	}
+	ctx.Data(p.Status, ProblemContentType, body)
+	ctx.Abort()
+}
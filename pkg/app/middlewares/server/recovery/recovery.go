@@ -24,6 +24,7 @@ import (
 	"runtime"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/errreport"
 )
 
 var (
@@ -42,9 +43,20 @@ func Recovery(opts ...Option) app.HandlerFunc {
 	return func(c context.Context, ctx *app.RequestContext) {
 		defer func() {
 			if err := recover(); err != nil {
-				stack := stack(3)
+				var trace []byte
+				switch {
+				case cfg.disableStack:
+				case cfg.allGoroutines:
+					trace = allStacks()
+				default:
+					trace = stack(3, cfg.frameFilter)
+				}
 
-				cfg.recoveryHandler(c, ctx, err, stack)
+				errreport.ReportPanic(c, ctx, err, trace)
+				if cfg.alertHandler != nil {
+					cfg.alertHandler(c, newPanicInfo(ctx, err, trace, cfg))
+				}
+				cfg.recoveryHandler(c, ctx, err, trace)
 			}
 		}()
 		ctx.Next(c)
@@ -52,7 +64,8 @@ func Recovery(opts ...Option) app.HandlerFunc {
 }
 
 // stack returns a nicely formatted stack frame, skipping skip frames.
-func stack(skip int) []byte {
+// Only the frames for which filter returns true are kept if filter != nil.
+func stack(skip int, filter func(function, file string) bool) []byte {
 	buf := new(bytes.Buffer) // the returned data
 	// As we loop, we open files and read them. These variables record the currently
 	// loaded file.
@@ -63,6 +76,15 @@ func stack(skip int) []byte {
 		if !ok {
 			break
 		}
+		if filter != nil {
+			name := ""
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				name = fn.Name()
+			}
+			if !filter(name, file) {
+				continue
+			}
+		}
 		// Print this much at least.  If we can't find the source, it won't show.
 		fmt.Fprintf(buf, "%s:%d (0x%x)\n", file, line, pc)
 		if file != lastFile {
@@ -78,6 +100,18 @@ func stack(skip int) []byte {
 	return buf.Bytes()
 }
 
+// allStacks returns the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
 // source returns a space-trimmed slice of the n'th line.
 func source(lines [][]byte, n int) []byte {
 	n-- // in stack trace, lines are 1-indexed but our array is 0-indexed
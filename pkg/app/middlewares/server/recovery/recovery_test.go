@@ -17,8 +17,10 @@
 package recovery
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -58,3 +60,77 @@ func TestWithRecoveryHandler(t *testing.T) {
 	}
 	assert.DeepEqual(t, "{\"msg\":\"test\"}", string(ctx.Response.Body()))
 }
+
+func newPanicContext() *app.RequestContext {
+	ctx := app.NewContext(0)
+	ctx.Request.SetRequestURI("/foo?token=abc&a=b")
+	ctx.Request.Header.SetMethod(consts.MethodPost)
+	ctx.Request.Header.Set(consts.HeaderAuthorization, "Bearer secret")
+	ctx.Request.Header.Set("X-Foo", "foo")
+	ctx.SetHandlers(app.HandlersChain{func(c context.Context, ctx *app.RequestContext) {
+		panic("test")
+	}})
+	return ctx
+}
+
+func TestRecoveryStack(t *testing.T) {
+	var got []byte
+	handler := func(c context.Context, ctx *app.RequestContext, err interface{}, stack []byte) {
+		got = stack
+	}
+
+	Recovery(WithRecoveryHandler(handler))(context.Background(), newPanicContext())
+	assert.True(t, bytes.Contains(got, []byte("TestRecoveryStack")))
+	assert.True(t, bytes.Contains(got, []byte("recovery_test.go")))
+
+	Recovery(WithRecoveryHandler(handler), WithDisableStack())(context.Background(), newPanicContext())
+	assert.Nil(t, got)
+
+	Recovery(WithRecoveryHandler(handler), WithStackFrameFilter(func(function, file string) bool {
+		return !strings.HasPrefix(function, "testing.") && !strings.HasSuffix(file, "recovery_test.go")
+	}))(context.Background(), newPanicContext())
+	assert.False(t, bytes.Contains(got, []byte("recovery_test.go")))
+	assert.False(t, bytes.Contains(got, []byte("testing.go")))
+	assert.True(t, bytes.Contains(got, []byte("context.go")))
+
+	Recovery(WithRecoveryHandler(handler), WithAllGoroutines())(context.Background(), newPanicContext())
+	assert.True(t, bytes.HasPrefix(got, []byte("goroutine ")))
+}
+
+func TestRecoveryAlertHandler(t *testing.T) {
+	var info *PanicInfo
+	alert := func(c context.Context, i *PanicInfo) {
+		info = i
+	}
+
+	ctx := newPanicContext()
+	Recovery(WithAlertHandler(alert), WithRedactQueries("token"))(context.Background(), ctx)
+	assert.DeepEqual(t, consts.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.DeepEqual(t, "test", info.Err)
+	assert.DeepEqual(t, consts.MethodPost, info.Method)
+	assert.DeepEqual(t, "/foo?token=REDACTED&a=b", info.URI)
+	assert.DeepEqual(t, Redacted, info.Header[consts.HeaderAuthorization])
+	assert.DeepEqual(t, "foo", info.Header["X-Foo"])
+	assert.NotNil(t, info.Stack)
+
+	Recovery(WithAlertHandler(alert), WithRedactHeaders("X-Foo"))(context.Background(), newPanicContext())
+	assert.DeepEqual(t, "/foo?token=abc&a=b", info.URI)
+	assert.DeepEqual(t, "Bearer secret", info.Header[consts.HeaderAuthorization])
+	assert.DeepEqual(t, Redacted, info.Header["X-Foo"])
+}
+
+func TestProblemDetailsHandler(t *testing.T) {
+	ctx := newPanicContext()
+	Recovery(WithRecoveryHandler(ProblemDetailsHandler))(context.Background(), ctx)
+	assert.DeepEqual(t, consts.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.DeepEqual(t, ProblemContentType, string(ctx.Response.Header.ContentType()))
+	assert.DeepEqual(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/foo"}`, string(ctx.Response.Body()))
+	assert.True(t, ctx.IsAborted())
+
+	ctx = newPanicContext()
+	Recovery(WithRecoveryHandler(NewProblemDetailsHandler(func(ctx *app.RequestContext, err interface{}) *Problem {
+		return &Problem{Type: "https://example.com/panic", Title: "Panic", Detail: fmt.Sprint(err)}
+	})))(context.Background(), ctx)
+	assert.DeepEqual(t, consts.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.DeepEqual(t, `{"type":"https://example.com/panic","title":"Panic","status":500,"detail":"test"}`, string(ctx.Response.Body()))
+}
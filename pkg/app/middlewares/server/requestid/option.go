@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const defaultHeader = "X-Request-ID"
+
+type (
+	options struct {
+		header    string
+		generator func() string
+	}
+
+	Option func(o *options)
+)
+
+// defaultGenerator generates 16 random bytes in hex.
+func defaultGenerator() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		header:    defaultHeader,
+		generator: defaultGenerator,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithHeader sets the header carrying the request ID, which is "X-Request-ID" by default.
+func WithHeader(header string) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+// WithGenerator sets the generator of the request IDs for the requests without a valid one,
+// which generates 32 random hex characters by default.
+func WithGenerator(f func() string) Option {
+	return func(o *options) {
+		o.generator = f
+	}
+}
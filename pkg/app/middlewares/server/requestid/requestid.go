@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package requestid
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// LogKey is the key of the field returned by LogFields.
+const LogKey = "request_id"
+
+// maxLength is the max length of the incoming request IDs, the longer ones are replaced.
+const maxLength = 128
+
+type requestIDKey struct{}
+
+// New returns the middleware assigning an ID to each request, which is taken from the request header
+// if it is valid, or generated otherwise. The ID is set to the request and response headers,
+// and carried by the context passed to the following handlers, e.g.
+//
+//	hlog.RegisterContextExtractor(requestid.LogFields)
+//	h.Use(requestid.New())
+//	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+//		hlog.CtxInfof(c, "ping") // [Info] ping request_id=...
+//	})
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+	return func(c context.Context, ctx *app.RequestContext) {
+		id := ctx.Request.Header.Get(cfg.header)
+		if !valid(id) {
+			id = cfg.generator()
+			ctx.Request.Header.Set(cfg.header, id)
+		}
+		ctx.Response.Header.Set(cfg.header, id)
+		ctx.Next(context.WithValue(c, requestIDKey{}, id))
+	}
+}
+
+// FromContext returns the request ID carried by c, or "" if there is none.
+func FromContext(c context.Context) string {
+	id, _ := c.Value(requestIDKey{}).(string)
+	return id
+}
+
+// LogFields returns the request ID carried by c as the field "request_id",
+// which can be registered with hlog.RegisterContextExtractor.
+func LogFields(c context.Context) map[string]interface{} {
+	if id := FromContext(c); id != "" {
+		return map[string]interface{}{LogKey: id}
+	}
+	return nil
+}
+
+// valid reports whether id is non-empty, not too long and only contains printable ASCII characters,
+// so that the IDs from the clients can not inject into the logs.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestRequestID(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New())
+	e.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, FromContext(c)+","+string(ctx.Request.Header.Peek(defaultHeader)))
+	})
+
+	resp := ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result()
+	id := resp.Header.Get(defaultHeader)
+	assert.DeepEqual(t, 32, len(id))
+	assert.DeepEqual(t, id+","+id, string(resp.Body()))
+
+	resp = ut.PerformRequest(e, consts.MethodGet, "/ping", nil, ut.Header{Key: defaultHeader, Value: "abc-123"}).Result()
+	assert.DeepEqual(t, "abc-123", resp.Header.Get(defaultHeader))
+	assert.DeepEqual(t, "abc-123,abc-123", string(resp.Body()))
+
+	for _, invalid := range []string{"a b", strings.Repeat("a", maxLength+1), "a\x01"} {
+		resp = ut.PerformRequest(e, consts.MethodGet, "/ping", nil, ut.Header{Key: defaultHeader, Value: invalid}).Result()
+		assert.DeepEqual(t, 32, len(resp.Header.Get(defaultHeader)))
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(WithHeader("X-Trace"), WithGenerator(func() string { return "generated" })))
+	e.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, FromContext(c))
+	})
+
+	resp := ut.PerformRequest(e, consts.MethodGet, "/ping", nil).Result()
+	assert.DeepEqual(t, "generated", resp.Header.Get("X-Trace"))
+	assert.DeepEqual(t, "generated", string(resp.Body()))
+	assert.DeepEqual(t, "", FromContext(context.Background()))
+}
+
+func TestLogFields(t *testing.T) {
+	assert.Nil(t, LogFields(context.Background()))
+
+	var buf bytes.Buffer
+	hlog.SetOutput(&buf)
+	defer hlog.SetOutput(os.Stderr)
+	hlog.RegisterContextExtractor(LogFields)
+
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New())
+	e.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		hlog.CtxInfof(c, "ping")
+	})
+	ut.PerformRequest(e, consts.MethodGet, "/ping", nil, ut.Header{Key: defaultHeader, Value: "abc"})
+	assert.True(t, strings.HasSuffix(buf.String(), "[Info] ping request_id=abc\n"))
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secure
+
+import (
+	"strings"
+)
+
+// NoncePlaceholder is replaced by 'nonce-<nonce>' in the policy with a random nonce per request,
+// which can be read by GetNonce, e.g.
+//
+//	secure.NewCSP().ScriptSrc(secure.Self, secure.NoncePlaceholder)
+const NoncePlaceholder = "'nonce'"
+
+// The keywords of the source lists.
+const (
+	Self          = "'self'"
+	None          = "'none'"
+	UnsafeInline  = "'unsafe-inline'"
+	UnsafeEval    = "'unsafe-eval'"
+	StrictDynamic = "'strict-dynamic'"
+)
+
+// CSP builds the Content-Security-Policy from directives, the methods can be chained, e.g.
+//
+//	csp := secure.NewCSP().DefaultSrc(secure.Self).ImgSrc(secure.Self, "data:")
+//
+// Use Clone to derive the policy of a route group from a base policy.
+type CSP struct {
+	names  []string
+	values map[string][]string
+}
+
+// NewCSP returns an empty policy.
+func NewCSP() *CSP {
+	return &CSP{values: make(map[string][]string)}
+}
+
+// Add appends sources to directive, the duplicated sources are ignored.
+// The directive without sources (e.g. "upgrade-insecure-requests") is added if sources is empty.
+func (c *CSP) Add(directive string, sources ...string) *CSP {
+	directive = strings.ToLower(strings.TrimSpace(directive))
+	values, ok := c.values[directive]
+	if !ok {
+		c.names = append(c.names, directive)
+	}
+	for _, s := range sources {
+		if !contains(values, s) {
+			values = append(values, s)
+		}
+	}
+	c.values[directive] = values
+	return c
+}
+
+// Set replaces the sources of directive.
+func (c *CSP) Set(directive string, sources ...string) *CSP {
+	c.Remove(directive)
+	return c.Add(directive, sources...)
+}
+
+// Remove removes directive from the policy.
+func (c *CSP) Remove(directive string) *CSP {
+	directive = strings.ToLower(strings.TrimSpace(directive))
+	if _, ok := c.values[directive]; !ok {
+		return c
+	}
+	delete(c.values, directive)
+	for i, name := range c.names {
+		if name == directive {
+			c.names = append(c.names[:i:i], c.names[i+1:]...)
+			break
+		}
+	}
+	return c
+}
+
+// Clone returns a copy of the policy, which can be changed without affecting c.
+func (c *CSP) Clone() *CSP {
+	n := &CSP{names: append([]string(nil), c.names...), values: make(map[string][]string, len(c.values))}
+	for k, v := range c.values {
+		n.values[k] = append([]string(nil), v...)
+	}
+	return n
+}
+
+// Merge adds the directives and sources of other to c.
+func (c *CSP) Merge(other *CSP) *CSP {
+	for _, name := range other.names {
+		c.Add(name, other.values[name]...)
+	}
+	return c
+}
+
+// DefaultSrc adds sources to "default-src", the methods below add sources to the directives of their names.
+func (c *CSP) DefaultSrc(sources ...string) *CSP { return c.Add("default-src", sources...) }
+
+func (c *CSP) ScriptSrc(sources ...string) *CSP { return c.Add("script-src", sources...) }
+
+func (c *CSP) StyleSrc(sources ...string) *CSP { return c.Add("style-src", sources...) }
+
+func (c *CSP) ImgSrc(sources ...string) *CSP { return c.Add("img-src", sources...) }
+
+func (c *CSP) ConnectSrc(sources ...string) *CSP { return c.Add("connect-src", sources...) }
+
+func (c *CSP) FontSrc(sources ...string) *CSP { return c.Add("font-src", sources...) }
+
+func (c *CSP) ObjectSrc(sources ...string) *CSP { return c.Add("object-src", sources...) }
+
+func (c *CSP) FrameSrc(sources ...string) *CSP { return c.Add("frame-src", sources...) }
+
+func (c *CSP) FrameAncestors(sources ...string) *CSP { return c.Add("frame-ancestors", sources...) }
+
+func (c *CSP) BaseURI(sources ...string) *CSP { return c.Add("base-uri", sources...) }
+
+func (c *CSP) FormAction(sources ...string) *CSP { return c.Add("form-action", sources...) }
+
+func (c *CSP) ReportURI(uri string) *CSP { return c.Set("report-uri", uri) }
+
+func (c *CSP) UpgradeInsecureRequests() *CSP { return c.Add("upgrade-insecure-requests") }
+
+// String returns the policy in the form of the header value.
+func (c *CSP) String() string {
+	var b strings.Builder
+	for i, name := range c.names {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(name)
+		for _, v := range c.values[name] {
+			b.WriteByte(' ')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
+
+func (c *CSP) hasNonce() bool {
+	for _, values := range c.values {
+		if contains(values, NoncePlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
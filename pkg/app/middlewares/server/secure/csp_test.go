@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secure
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestCSP(t *testing.T) {
+	base := NewCSP().DefaultSrc(Self).ScriptSrc(Self, Self).ObjectSrc(None).UpgradeInsecureRequests()
+	assert.DeepEqual(t, "default-src 'self'; script-src 'self'; object-src 'none'; upgrade-insecure-requests", base.String())
+
+	derived := base.Clone().ScriptSrc("https://cdn.example.com").Remove("object-src").ReportURI("/csp")
+	assert.DeepEqual(t, "default-src 'self'; script-src 'self' https://cdn.example.com; upgrade-insecure-requests; report-uri /csp", derived.String())
+	// the base is not changed
+	assert.DeepEqual(t, "default-src 'self'; script-src 'self'; object-src 'none'; upgrade-insecure-requests", base.String())
+
+	merged := NewCSP().Set("Script-Src", UnsafeInline).Merge(derived)
+	assert.DeepEqual(t, "script-src 'unsafe-inline' 'self' https://cdn.example.com; default-src 'self'; upgrade-insecure-requests; report-uri /csp", merged.String())
+	assert.DeepEqual(t, "script-src 'self'", merged.Set("script-src", Self).Remove("default-src").Remove("upgrade-insecure-requests").Remove("report-uri").String())
+
+	assert.False(t, base.hasNonce())
+	assert.True(t, NewCSP().StyleSrc(NoncePlaceholder).hasNonce())
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secure
+
+import (
+	"strconv"
+	"time"
+)
+
+type (
+	options struct {
+		hstsMaxAge            time.Duration
+		hstsIncludeSubdomains bool
+		hstsPreload           bool
+		trustForwardedProto   bool
+		contentTypeNosniff    bool
+		frameOptions          string
+		referrerPolicy        string
+		coop                  string
+		coep                  string
+		corp                  string
+		csp                   *CSP
+		cspReportOnly         bool
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		hstsMaxAge:            180 * 24 * time.Hour,
+		hstsIncludeSubdomains: true,
+		contentTypeNosniff:    true,
+		frameOptions:          "DENY",
+		referrerPolicy:        "no-referrer",
+		coop:                  "same-origin",
+		corp:                  "same-origin",
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithHSTS sets the Strict-Transport-Security header, which is
+// "max-age=15552000; includeSubDomains" by default. maxAge<=0 disables it.
+//
+// NOTE:
+//
+//	The header is only sent over HTTPS, see WithTrustForwardedProto.
+func WithHSTS(maxAge time.Duration, includeSubdomains, preload bool) Option {
+	return func(o *options) {
+		o.hstsMaxAge = maxAge
+		o.hstsIncludeSubdomains = includeSubdomains
+		o.hstsPreload = preload
+	}
+}
+
+// WithTrustForwardedProto treats the request as HTTPS if the X-Forwarded-Proto header is "https",
+// which should only be used behind a trusted proxy terminating TLS.
+func WithTrustForwardedProto() Option {
+	return func(o *options) {
+		o.trustForwardedProto = true
+	}
+}
+
+// WithContentTypeNosniff sets whether "X-Content-Type-Options: nosniff" is sent, which is true by default.
+func WithContentTypeNosniff(b bool) Option {
+	return func(o *options) {
+		o.contentTypeNosniff = b
+	}
+}
+
+// WithFrameOptions sets the X-Frame-Options header, which is "DENY" by default. Empty value disables it.
+func WithFrameOptions(v string) Option {
+	return func(o *options) {
+		o.frameOptions = v
+	}
+}
+
+// WithReferrerPolicy sets the Referrer-Policy header, which is "no-referrer" by default. Empty value disables it.
+func WithReferrerPolicy(v string) Option {
+	return func(o *options) {
+		o.referrerPolicy = v
+	}
+}
+
+// WithCrossOriginOpenerPolicy sets the Cross-Origin-Opener-Policy header,
+// which is "same-origin" by default. Empty value disables it.
+func WithCrossOriginOpenerPolicy(v string) Option {
+	return func(o *options) {
+		o.coop = v
+	}
+}
+
+// WithCrossOriginEmbedderPolicy sets the Cross-Origin-Embedder-Policy header, e.g. "require-corp",
+// which is not sent by default since it blocks the cross-origin resources without CORP or CORS.
+func WithCrossOriginEmbedderPolicy(v string) Option {
+	return func(o *options) {
+		o.coep = v
+	}
+}
+
+// WithCrossOriginResourcePolicy sets the Cross-Origin-Resource-Policy header,
+// which is "same-origin" by default. Empty value disables it.
+func WithCrossOriginResourcePolicy(v string) Option {
+	return func(o *options) {
+		o.corp = v
+	}
+}
+
+// WithCSP sets the Content-Security-Policy header, which is not sent by default.
+// The policy is rendered once, it must not be changed after New.
+func WithCSP(csp *CSP) Option {
+	return func(o *options) {
+		o.csp = csp
+	}
+}
+
+// WithCSPReportOnly sends the policy by Content-Security-Policy-Report-Only instead,
+// so the violations are reported without being blocked.
+func WithCSPReportOnly() Option {
+	return func(o *options) {
+		o.cspReportOnly = true
+	}
+}
+
+func (o *options) hsts() string {
+	if o.hstsMaxAge <= 0 {
+		return ""
+	}
+	v := "max-age=" + strconv.FormatInt(int64(o.hstsMaxAge/time.Second), 10)
+	if o.hstsIncludeSubdomains {
+		v += "; includeSubDomains"
+	}
+	if o.hstsPreload {
+		v += "; preload"
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// NonceKey is the key of the nonce of the policy in the RequestContext.
+const NonceKey = "csp_nonce"
+
+type header struct {
+	key   string
+	value string
+}
+
+// New returns a middleware which sets the security headers of the responses.
+// Use it on a route group to apply the headers to the group only, e.g.
+//
+//	api := h.Group("/api", secure.New(secure.WithFrameOptions("")))
+//
+// NOTE:
+//
+//	The headers are set before the handlers, the handlers can override them.
+func New(opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	var headers []header
+	add := func(key, value string) {
+		if value != "" {
+			headers = append(headers, header{key, value})
+		}
+	}
+	if cfg.contentTypeNosniff {
+		add(consts.HeaderXContentTypeOptions, "nosniff")
+	}
+	add(consts.HeaderXFrameOptions, cfg.frameOptions)
+	add(consts.HeaderReferrerPolicy, cfg.referrerPolicy)
+	add(consts.HeaderCrossOriginOpenerPolicy, cfg.coop)
+	add(consts.HeaderCrossOriginEmbedderPolicy, cfg.coep)
+	add(consts.HeaderCrossOriginResourcePolicy, cfg.corp)
+	hsts := cfg.hsts()
+
+	cspHeader := consts.HeaderContentSecurityPolicy
+	if cfg.cspReportOnly {
+		cspHeader = consts.HeaderContentSecurityPolicyReportOnly
+	}
+	var csp string
+	var nonce bool
+	if cfg.csp != nil {
+		csp, nonce = cfg.csp.String(), cfg.csp.hasNonce()
+		if !nonce {
+			add(cspHeader, csp)
+		}
+	}
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		for _, h := range headers {
+			ctx.Response.Header.Set(h.key, h.value)
+		}
+		if hsts != "" && cfg.isHTTPS(ctx) {
+			ctx.Response.Header.Set(consts.HeaderStrictTransportSecurity, hsts)
+		}
+		if nonce {
+			n, err := newNonce()
+			if err != nil {
+				hlog.SystemLogger().CtxErrorf(c, "Generate CSP nonce error=%v", err)
+				ctx.AbortWithStatus(consts.StatusInternalServerError)
+				return
+			}
+			ctx.Set(NonceKey, n)
+			ctx.Response.Header.Set(cspHeader, strings.Replace(csp, NoncePlaceholder, "'nonce-"+n+"'", -1))
+		}
+		ctx.Next(c)
+	}
+}
+
+// GetNonce returns the nonce of the policy of the request,
+// which is empty if the policy does not contain NoncePlaceholder.
+func GetNonce(ctx *app.RequestContext) string {
+	return ctx.GetString(NonceKey)
+}
+
+func (o *options) isHTTPS(ctx *app.RequestContext) bool {
+	if string(ctx.URI().Scheme()) == "https" {
+		return true
+	}
+	return o.trustForwardedProto &&
+		strings.EqualFold(string(ctx.Request.Header.Peek(consts.HeaderXForwardedProto)), "https")
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secure
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestSecureDefault(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New())
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, "nosniff", resp.Header.Get(consts.HeaderXContentTypeOptions))
+	assert.DeepEqual(t, "DENY", resp.Header.Get(consts.HeaderXFrameOptions))
+	assert.DeepEqual(t, "no-referrer", resp.Header.Get(consts.HeaderReferrerPolicy))
+	assert.DeepEqual(t, "same-origin", resp.Header.Get(consts.HeaderCrossOriginOpenerPolicy))
+	assert.DeepEqual(t, "same-origin", resp.Header.Get(consts.HeaderCrossOriginResourcePolicy))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderCrossOriginEmbedderPolicy))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderContentSecurityPolicy))
+	// not https
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderStrictTransportSecurity))
+}
+
+func TestSecureHSTS(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithHSTS(time.Hour, false, true), WithTrustForwardedProto()))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: consts.HeaderXForwardedProto, Value: "HTTPS"}).Result()
+	assert.DeepEqual(t, "max-age=3600; preload", resp.Header.Get(consts.HeaderStrictTransportSecurity))
+
+	engine = route.NewEngine(config.NewOptions(nil))
+	engine.Use(New())
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp = ut.PerformRequest(engine, consts.MethodGet, "/foo", nil,
+		ut.Header{Key: consts.HeaderXForwardedProto, Value: "https"}).Result()
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderStrictTransportSecurity))
+	resp = ut.PerformRequest(engine, consts.MethodGet, "https://example.com/foo", nil).Result()
+	assert.DeepEqual(t, "max-age=15552000; includeSubDomains", resp.Header.Get(consts.HeaderStrictTransportSecurity))
+}
+
+func TestSecureOptions(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(
+		WithContentTypeNosniff(false),
+		WithFrameOptions("SAMEORIGIN"),
+		WithReferrerPolicy(""),
+		WithCrossOriginOpenerPolicy(""),
+		WithCrossOriginEmbedderPolicy("require-corp"),
+		WithCrossOriginResourcePolicy("cross-origin"),
+		WithCSP(NewCSP().DefaultSrc(Self)),
+		WithCSPReportOnly(),
+	))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderXContentTypeOptions))
+	assert.DeepEqual(t, "SAMEORIGIN", resp.Header.Get(consts.HeaderXFrameOptions))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderReferrerPolicy))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderCrossOriginOpenerPolicy))
+	assert.DeepEqual(t, "require-corp", resp.Header.Get(consts.HeaderCrossOriginEmbedderPolicy))
+	assert.DeepEqual(t, "cross-origin", resp.Header.Get(consts.HeaderCrossOriginResourcePolicy))
+	assert.DeepEqual(t, "", resp.Header.Get(consts.HeaderContentSecurityPolicy))
+	assert.DeepEqual(t, "default-src 'self'", resp.Header.Get(consts.HeaderContentSecurityPolicyReportOnly))
+}
+
+func TestSecureCSPNonce(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(New(WithCSP(NewCSP().DefaultSrc(Self).ScriptSrc(Self, NoncePlaceholder))))
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, GetNonce(ctx))
+	})
+	re := regexp.MustCompile(`^default-src 'self'; script-src 'self' 'nonce-([A-Za-z0-9+/=]+)'$`)
+
+	var nonces []string
+	for i := 0; i < 2; i++ {
+		resp := ut.PerformRequest(engine, consts.MethodGet, "/foo", nil).Result()
+		m := re.FindStringSubmatch(resp.Header.Get(consts.HeaderContentSecurityPolicy))
+		assert.DeepEqual(t, 2, len(m))
+		assert.DeepEqual(t, m[1], string(resp.Body()))
+		nonces = append(nonces, m[1])
+	}
+	assert.NotEqual(t, nonces[0], nonces[1])
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type (
+	options struct {
+		spanNameFormatter func(ctx *app.RequestContext) string
+		filter            func(c context.Context, ctx *app.RequestContext) bool
+	}
+
+	Option func(o *options)
+)
+
+// defaultSpanNameFormatter names the span by the route template, or by the method if no route is matched,
+// so that the raw paths never end up in the span names.
+func defaultSpanNameFormatter(ctx *app.RequestContext) string {
+	if route := ctx.FullPath(); route != "" {
+		return route
+	}
+	return string(ctx.Method())
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		spanNameFormatter: defaultSpanNameFormatter,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithSpanNameFormatter sets the function naming the server spans, which returns the route template by default.
+func WithSpanNameFormatter(f func(ctx *app.RequestContext) string) Option {
+	return func(o *options) {
+		o.spanNameFormatter = f
+	}
+}
+
+// WithFilter sets the function reporting whether the request is traced, e.g. to skip the health checks.
+func WithFilter(f func(c context.Context, ctx *app.RequestContext) bool) Option {
+	return func(o *options) {
+		o.filter = f
+	}
+}
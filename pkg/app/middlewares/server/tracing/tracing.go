@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/tracer/trace"
+)
+
+// New returns a middleware starting a server span for each request, which is the child of
+// the span context extracted from the traceparent and tracestate headers, e.g.
+//
+//	tracer := trace.NewTracer(exporter)
+//	h.Use(tracing.New(tracer))
+//
+// The span can be got by trace.SpanFromContext in the handlers, and the context passed to
+// the handlers carries it to the client middleware, so that the trace crosses the hops.
+// The status of the span is set to error for the 5xx responses and the panics,
+// and the errors of the context are recorded as events.
+func New(tracer *trace.Tracer, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		if cfg.filter != nil && !cfg.filter(c, ctx) {
+			ctx.Next(c)
+			return
+		}
+
+		if sc, ok := trace.Extract(&ctx.Request.Header); ok {
+			c = trace.ContextWithRemoteSpanContext(c, sc)
+		}
+		method := string(ctx.Method())
+		c, span := tracer.Start(c, cfg.spanNameFormatter(ctx), trace.SpanKindServer,
+			trace.String("http.request.method", method),
+			trace.String("url.path", string(ctx.Path())),
+			trace.String("http.route", ctx.FullPath()),
+			trace.String("server.address", string(ctx.Host())),
+			trace.String("client.address", ctx.ClientIP()),
+			trace.String("user_agent.original", string(ctx.UserAgent())),
+		)
+		defer func() {
+			if r := recover(); r != nil {
+				span.RecordError(fmt.Errorf("%v", r))
+				span.SetStatus(trace.StatusError, fmt.Sprint(r))
+				span.End()
+				panic(r)
+			}
+		}()
+
+		ctx.Next(c)
+
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(trace.Int("http.response.status_code", status))
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= 500 {
+			span.SetStatus(trace.StatusError, "")
+		}
+		span.End()
+	}
+}
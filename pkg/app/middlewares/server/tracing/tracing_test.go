@@ -0,0 +1,130 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/trace"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func attribute(span *trace.Span, key string) interface{} {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	var exported []*trace.Span
+	tracer := trace.NewTracer(trace.ExporterFunc(func(span *trace.Span) {
+		exported = append(exported, span)
+	}))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(func(c context.Context, ctx *app.RequestContext) {
+		defer func() {
+			if recover() != nil {
+				ctx.AbortWithStatus(consts.StatusInternalServerError)
+			}
+		}()
+		ctx.Next(c)
+	}, New(tracer))
+	e.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		span := trace.SpanFromContext(c)
+		span.SetAttributes(trace.String("user.id", ctx.Param("id")))
+		ctx.String(consts.StatusOK, span.Context.TraceID.String())
+	})
+	e.GET("/error", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Error(errors.New("failed")) //nolint:errcheck
+		ctx.AbortWithStatus(consts.StatusServiceUnavailable)
+	})
+	e.GET("/panic", func(c context.Context, ctx *app.RequestContext) {
+		panic("boom")
+	})
+
+	resp := ut.PerformRequest(e, consts.MethodGet, "/user/1", nil,
+		ut.Header{Key: trace.HeaderTraceparent, Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		ut.Header{Key: trace.HeaderTracestate, Value: "vendor=value"},
+	).Result()
+	assert.DeepEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", string(resp.Body()))
+	assert.DeepEqual(t, 1, len(exported))
+	span := exported[0]
+	assert.DeepEqual(t, "/user/:id", span.Name)
+	assert.DeepEqual(t, trace.SpanKindServer, span.Kind)
+	assert.DeepEqual(t, "00f067aa0ba902b7", span.Parent.SpanID.String())
+	assert.True(t, span.Parent.Remote)
+	assert.DeepEqual(t, "vendor=value", span.Context.TraceState)
+	assert.DeepEqual(t, "GET", attribute(span, "http.request.method"))
+	assert.DeepEqual(t, "/user/1", attribute(span, "url.path"))
+	assert.DeepEqual(t, "/user/:id", attribute(span, "http.route"))
+	assert.DeepEqual(t, "1", attribute(span, "user.id"))
+	assert.DeepEqual(t, 200, attribute(span, "http.response.status_code"))
+	assert.DeepEqual(t, trace.StatusUnset, span.Status)
+
+	// a new trace is started without traceparent
+	resp = ut.PerformRequest(e, consts.MethodGet, "/user/1", nil).Result()
+	assert.DeepEqual(t, exported[1].Context.TraceID.String(), string(resp.Body()))
+	assert.False(t, exported[1].Parent.IsValid())
+
+	ut.PerformRequest(e, consts.MethodGet, "/error", nil)
+	span = exported[2]
+	assert.DeepEqual(t, trace.StatusError, span.Status)
+	assert.DeepEqual(t, "failed", span.Events[0].Attributes[0].Value)
+
+	resp = ut.PerformRequest(e, consts.MethodGet, "/panic", nil).Result()
+	assert.DeepEqual(t, consts.StatusInternalServerError, resp.StatusCode())
+	span = exported[3]
+	assert.DeepEqual(t, trace.StatusError, span.Status)
+	assert.DeepEqual(t, "boom", span.StatusMessage)
+}
+
+func TestTracingOptions(t *testing.T) {
+	var exported []*trace.Span
+	tracer := trace.NewTracer(trace.ExporterFunc(func(span *trace.Span) {
+		exported = append(exported, span)
+	}))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(tracer,
+		WithSpanNameFormatter(func(ctx *app.RequestContext) string {
+			return string(ctx.Method()) + " " + ctx.FullPath()
+		}),
+		WithFilter(func(c context.Context, ctx *app.RequestContext) bool {
+			return string(ctx.Path()) != "/error"
+		}),
+	))
+	e.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "user")
+	})
+	e.GET("/error", func(c context.Context, ctx *app.RequestContext) {
+		ctx.AbortWithStatus(consts.StatusServiceUnavailable)
+	})
+
+	ut.PerformRequest(e, consts.MethodGet, "/error", nil)
+	assert.DeepEqual(t, 0, len(exported))
+	ut.PerformRequest(e, consts.MethodGet, "/user/1", nil)
+	assert.DeepEqual(t, "GET /user/:id", exported[0].Name)
+}
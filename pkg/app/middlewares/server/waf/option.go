@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package waf
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const defaultMaxBodyPrefix = 8 * 1024
+
+type (
+	options struct {
+		maxBodyPrefix int
+		detectionOnly bool
+		denyHandler   func(c context.Context, ctx *app.RequestContext, res Result)
+	}
+
+	Option func(o *options)
+)
+
+func defaultDenyHandler(c context.Context, ctx *app.RequestContext, res Result) {
+	ctx.AbortWithStatus(consts.StatusForbidden)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		maxBodyPrefix: defaultMaxBodyPrefix,
+		denyHandler:   defaultDenyHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithMaxBodyPrefix sets the max size of the body inspected by the rules, which is 8KB by default.
+// n<=0 disables the inspection of the body.
+//
+// NOTE:
+//
+//	The body stream is not inspected, since it can not be read without consuming it.
+func WithMaxBodyPrefix(n int) Option {
+	return func(o *options) {
+		o.maxBodyPrefix = n
+	}
+}
+
+// WithDetectionOnly logs the denied requests without rejecting them,
+// which helps tuning the rules before enforcing them.
+func WithDetectionOnly() Option {
+	return func(o *options) {
+		o.detectionOnly = true
+	}
+}
+
+// WithDenyHandler sets the handler of the denied requests, which responds 403 by default.
+func WithDenyHandler(f func(c context.Context, ctx *app.RequestContext, res Result)) Option {
+	return func(o *options) {
+		o.denyHandler = f
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package waf
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// Action is the decision of a rule on a request.
+type Action int
+
+const (
+	// Pass means the rule does not match, the other rules go on.
+	Pass Action = iota
+	// Allow accepts the request without inspecting it further, e.g. a whitelist.
+	Allow
+	// Deny rejects the request immediately.
+	Deny
+	// Score adds the score of the verdict to the anomaly score of the request,
+	// which is rejected if the total score reaches the threshold.
+	Score
+)
+
+func (a Action) String() string {
+	switch a {
+	case Pass:
+		return "pass"
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	case Score:
+		return "score"
+	}
+	return "unknown"
+}
+
+// Verdict is the result of a rule.
+type Verdict struct {
+	Action  Action
+	Score   int
+	RuleID  string
+	Message string
+}
+
+// Request is the part of the request inspected by the rules.
+type Request struct {
+	Method   string
+	Path     string
+	Query    string
+	ClientIP string
+	Header   *protocol.RequestHeader
+	// BodyPrefix is at most the first MaxBodyPrefix bytes of the body, see WithMaxBodyPrefix.
+	BodyPrefix []byte
+}
+
+// Engine inspects the requests, it can be a third-party rule engine.
+type Engine interface {
+	// Inspect returns the result of req, whose Action is either Allow or Deny.
+	Inspect(ctx context.Context, req *Request) Result
+}
+
+// Result is the result of the inspection by an Engine.
+type Result struct {
+	Action Action
+	// Score is the total anomaly score of the request.
+	Score int
+	// Matched is the verdicts of the matched rules.
+	Matched []Verdict
+}
+
+// Rule inspects a request.
+type Rule interface {
+	Inspect(ctx context.Context, req *Request) Verdict
+}
+
+// RuleFunc is an adapter to use ordinary functions as Rule.
+type RuleFunc func(ctx context.Context, req *Request) Verdict
+
+func (f RuleFunc) Inspect(ctx context.Context, req *Request) Verdict {
+	return f(ctx, req)
+}
+
+// RuleSet is an Engine evaluating the rules in order by anomaly scoring as OWASP CRS does.
+type RuleSet struct {
+	rules     []Rule
+	threshold int
+}
+
+// NewRuleSet returns a RuleSet of rules, the requests whose total score reaches threshold are denied.
+// threshold<=0 means the scores never deny the requests.
+func NewRuleSet(threshold int, rules ...Rule) *RuleSet {
+	return &RuleSet{rules: rules, threshold: threshold}
+}
+
+// Inspect evaluates the rules until one allows or denies the request.
+func (s *RuleSet) Inspect(ctx context.Context, req *Request) Result {
+	var res Result
+	for _, r := range s.rules {
+		v := r.Inspect(ctx, req)
+		switch v.Action {
+		case Pass:
+			continue
+		case Allow, Deny:
+			res.Matched = append(res.Matched, v)
+			res.Action = v.Action
+			return res
+		case Score:
+			res.Matched = append(res.Matched, v)
+			res.Score += v.Score
+		}
+	}
+	if s.threshold > 0 && res.Score >= s.threshold {
+		res.Action = Deny
+	} else {
+		res.Action = Allow
+	}
+	return res
+}
+
+// Target is the parts of the request matched by RegexRule.
+type Target int
+
+const (
+	TargetPath Target = 1 << iota
+	TargetQuery
+	TargetHeaders
+	TargetBody
+
+	TargetAll = TargetPath | TargetQuery | TargetHeaders | TargetBody
+)
+
+// NewRegexRule returns a Rule scoring the request with score if pattern matches any of targets.
+// The path and query string are matched after being unescaped.
+// It panics if pattern can not be compiled.
+func NewRegexRule(id string, targets Target, pattern string, score int) Rule {
+	re := regexp.MustCompile(pattern)
+	return RuleFunc(func(ctx context.Context, req *Request) Verdict {
+		if matchRegex(re, targets, req) {
+			return Verdict{Action: Score, Score: score, RuleID: id, Message: "matched " + pattern}
+		}
+		return Verdict{}
+	})
+}
+
+func matchRegex(re *regexp.Regexp, targets Target, req *Request) bool {
+	if targets&TargetPath != 0 && re.MatchString(unescape(req.Path)) {
+		return true
+	}
+	if targets&TargetQuery != 0 && req.Query != "" && re.MatchString(unescape(req.Query)) {
+		return true
+	}
+	if targets&TargetHeaders != 0 && req.Header != nil {
+		matched := false
+		req.Header.VisitAll(func(k, v []byte) {
+			if !matched && re.Match(v) {
+				matched = true
+			}
+		})
+		if matched {
+			return true
+		}
+	}
+	return targets&TargetBody != 0 && re.Match(req.BodyPrefix)
+}
+
+func unescape(s string) string {
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
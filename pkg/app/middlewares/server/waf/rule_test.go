@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package waf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestRuleSet(t *testing.T) {
+	allowAdmin := RuleFunc(func(ctx context.Context, req *Request) Verdict {
+		if req.ClientIP == "10.0.0.1" {
+			return Verdict{Action: Allow, RuleID: "allow"}
+		}
+		return Verdict{}
+	})
+	denyTrace := RuleFunc(func(ctx context.Context, req *Request) Verdict {
+		if req.Method == "TRACE" {
+			return Verdict{Action: Deny, RuleID: "trace"}
+		}
+		return Verdict{}
+	})
+	rules := NewRuleSet(5,
+		allowAdmin,
+		denyTrace,
+		NewRegexRule("sqli", TargetQuery|TargetBody, `(?i)union\s+select`, 3),
+		NewRegexRule("traversal", TargetPath|TargetQuery, `\.\./`, 3),
+		NewRegexRule("scanner", TargetHeaders, `(?i)sqlmap`, 5),
+	)
+
+	header := &protocol.RequestHeader{}
+	for _, tc := range []struct {
+		req    *Request
+		action Action
+		score  int
+		rules  []string
+	}{
+		{&Request{Method: "GET", Path: "/foo", Query: "a=b"}, Allow, 0, nil},
+		{&Request{Method: "TRACE", Path: "/foo"}, Deny, 0, []string{"trace"}},
+		{&Request{Method: "GET", Path: "/foo", Query: "q=1%20UNION%20SELECT%201"}, Allow, 3, []string{"sqli"}},
+		{&Request{Method: "GET", Path: "/%2e%2e/", Query: "q=1%20UNION%20SELECT%201"}, Deny, 3 + 3, []string{"sqli", "traversal"}},
+		{&Request{Method: "POST", Path: "/../", BodyPrefix: []byte("union select")}, Deny, 6, []string{"sqli", "traversal"}},
+		{&Request{Method: "TRACE", Path: "/../", ClientIP: "10.0.0.1"}, Allow, 0, []string{"allow"}},
+		{&Request{Method: "GET", Path: "/", Header: header}, Deny, 5, []string{"scanner"}},
+	} {
+		header.Set("User-Agent", "sqlmap/1.0")
+		res := rules.Inspect(context.Background(), tc.req)
+		assert.DeepEqual(t, tc.action, res.Action)
+		assert.DeepEqual(t, tc.score, res.Score)
+		var ids []string
+		for _, v := range res.Matched {
+			ids = append(ids, v.RuleID)
+		}
+		assert.DeepEqual(t, tc.rules, ids)
+	}
+
+	// the scores never deny the requests without threshold
+	res := NewRuleSet(0, NewRegexRule("all", TargetAll, `.`, 100)).Inspect(context.Background(), &Request{Path: "/"})
+	assert.DeepEqual(t, Allow, res.Action)
+	assert.DeepEqual(t, 100, res.Score)
+}
+
+func TestAction(t *testing.T) {
+	assert.DeepEqual(t, "pass", Pass.String())
+	assert.DeepEqual(t, "allow", Allow.String())
+	assert.DeepEqual(t, "deny", Deny.String())
+	assert.DeepEqual(t, "score", Score.String())
+	assert.DeepEqual(t, "unknown", Action(10).String())
+}
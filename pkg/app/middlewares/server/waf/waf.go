@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package waf
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// New returns a middleware inspecting the requests by engine before the handlers, e.g.
+//
+//	rules := waf.NewRuleSet(5,
+//		waf.NewRegexRule("942100", waf.TargetQuery|waf.TargetBody, `(?i)union\s+select`, 5),
+//		waf.NewRegexRule("930100", waf.TargetPath|waf.TargetQuery, `\.\./`, 5),
+//	)
+//	h.Use(waf.New(rules))
+func New(engine Engine, opts ...Option) app.HandlerFunc {
+	cfg := newOptions(opts...)
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		req := &Request{
+			Method:   string(ctx.Method()),
+			Path:     string(ctx.Path()),
+			Query:    string(ctx.URI().QueryString()),
+			ClientIP: ctx.ClientIP(),
+			Header:   &ctx.Request.Header,
+		}
+		if cfg.maxBodyPrefix > 0 && !ctx.Request.IsBodyStream() {
+			body := ctx.Request.Body()
+			if len(body) > cfg.maxBodyPrefix {
+				body = body[:cfg.maxBodyPrefix]
+			}
+			req.BodyPrefix = body
+		}
+
+		res := engine.Inspect(c, req)
+		if res.Action != Deny {
+			ctx.Next(c)
+			return
+		}
+		hlog.SystemLogger().CtxWarnf(c, "WAF denied request: method=%s, path=%s, clientIP=%s, score=%d, matched=%v, detectionOnly=%t",
+			req.Method, req.Path, req.ClientIP, res.Score, res.Matched, cfg.detectionOnly)
+		if cfg.detectionOnly {
+			ctx.Next(c)
+			return
+		}
+		cfg.denyHandler(c, ctx, res)
+		ctx.Abort()
+	}
+}
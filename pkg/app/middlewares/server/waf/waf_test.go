@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package waf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestWAF(t *testing.T) {
+	rules := NewRuleSet(5, NewRegexRule("sqli", TargetQuery|TargetBody, `(?i)union\s+select`, 5))
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(rules))
+	e.POST("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+
+	resp := ut.PerformRequest(e, consts.MethodPost, "/foo", &ut.Body{Body: strings.NewReader("a=b"), Len: 3}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	resp = ut.PerformRequest(e, consts.MethodPost, "/foo?q=union+select", nil).Result()
+	assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+	resp = ut.PerformRequest(e, consts.MethodPost, "/foo", &ut.Body{Body: strings.NewReader("union select"), Len: 12}).Result()
+	assert.DeepEqual(t, consts.StatusForbidden, resp.StatusCode())
+
+	// the body beyond the prefix is not inspected
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(rules, WithMaxBodyPrefix(4)))
+	e.POST("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp = ut.PerformRequest(e, consts.MethodPost, "/foo", &ut.Body{Body: strings.NewReader("union select"), Len: 12}).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+}
+
+func TestWAFOptions(t *testing.T) {
+	deny := NewRuleSet(1, NewRegexRule("all", TargetPath, `.`, 1))
+
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(New(deny, WithDetectionOnly()))
+	e.POST("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp := ut.PerformRequest(e, consts.MethodPost, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+
+	var got Result
+	e = route.NewEngine(config.NewOptions(nil))
+	e.Use(New(deny, WithDenyHandler(func(c context.Context, ctx *app.RequestContext, res Result) {
+		got = res
+		ctx.String(consts.StatusNotAcceptable, "denied")
+	})))
+	e.POST("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "foo")
+	})
+	resp = ut.PerformRequest(e, consts.MethodPost, "/foo", nil).Result()
+	assert.DeepEqual(t, consts.StatusNotAcceptable, resp.StatusCode())
+	assert.DeepEqual(t, "denied", string(resp.Body()))
+	assert.DeepEqual(t, 1, got.Score)
+	assert.DeepEqual(t, "all", got.Matched[0].RuleID)
+}
@@ -0,0 +1,500 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reverseproxy provides a handler forwarding the requests to an upstream server
+// by the hertz client, whose connections are pooled and reused across the requests.
+//
+// The request and response bodies are streamed through the proxy if the server is created
+// with WithStreamBody(true) and the client with WithResponseBodyStream(true), which is the
+// default of NewSingleHostReverseProxy. Upgraded connections, e.g. WebSocket, are tunneled
+// to the upstream server on dedicated connections.
+package reverseproxy
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/ext"
+	reqI "github.com/cloudwego/hertz/pkg/protocol/http1/req"
+	respI "github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+	"github.com/cloudwego/hertz/pkg/protocol/websocket"
+)
+
+// hopHeaders are the hop-by-hop headers which are removed when forwarding,
+// see https://www.rfc-editor.org/rfc/rfc7230#section-6.1.
+var hopHeaders = []string{
+	consts.HeaderConnection,
+	consts.HeaderProxyConnection,
+	consts.HeaderKeepAlive,
+	consts.HeaderProxyAuthenticate,
+	consts.HeaderProxyAuthorization,
+	consts.HeaderTE,
+	consts.HeaderTrailer,
+	consts.HeaderTransferEncoding,
+	consts.HeaderUpgrade,
+}
+
+// ReverseProxy is a handler forwarding the requests to the upstream server and
+// the responses back to the clients.
+type ReverseProxy struct {
+	client *client.Client
+
+	// target is the upstream server set by NewSingleHostReverseProxy
+	target *protocol.URI
+
+	// director rewrites the request before it is sent to the upstream server
+	director func(req *protocol.Request)
+
+	// modifyResponse modifies the response from the upstream server, the error
+	// returned is handled by errorHandler
+	modifyResponse func(resp *protocol.Response) error
+
+	// errorHandler handles the error of forwarding
+	errorHandler func(ctx *app.RequestContext, err error)
+
+	// tunneler tunnels the upgraded connections
+	tunneler network.Tunneler
+
+	// onUpgradeClose is called after an upgraded connection is closed
+	onUpgradeClose func(stats *network.ConnStats, err error)
+}
+
+// NewSingleHostReverseProxy returns a ReverseProxy forwarding the requests to target,
+// e.g. http://127.0.0.1:8080/base, the path of which is joined with the path of the requests.
+// The query of target is combined with the query of the requests as well.
+//
+// The client forwarding the requests is created with opts, the response body is streamed
+// by default. Use SetClient to share a client among several proxies.
+func NewSingleHostReverseProxy(target string, opts ...config.ClientOption) (*ReverseProxy, error) {
+	u := protocol.ParseURI(target)
+	if scheme := string(u.Scheme()); scheme != "http" && scheme != "https" || len(u.Host()) == 0 {
+		return nil, fmt.Errorf("reverseproxy: invalid target %q", target)
+	}
+	c, err := client.NewClient(append([]config.ClientOption{client.WithResponseBodyStream(true)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReverseProxy{
+		client: c,
+		target: u,
+	}
+	r.director = r.defaultDirector
+	return r, nil
+}
+
+// SetClient sets the client forwarding the requests.
+func (r *ReverseProxy) SetClient(c *client.Client) {
+	r.client = c
+}
+
+// GetClient returns the client forwarding the requests.
+func (r *ReverseProxy) GetClient() *client.Client {
+	return r.client
+}
+
+// SetDirector replaces the default director, which points the request to the target
+// and sets the Host header to the host of it.
+//
+// The request passed to director is a copy of the incoming one, the hop-by-hop headers
+// are removed after director returns.
+func (r *ReverseProxy) SetDirector(director func(req *protocol.Request)) {
+	r.director = director
+}
+
+// SetModifyResponse sets the function modifying the response from the upstream server
+// before it is copied to the client. If it returns an error, the error handler is called
+// instead. It is not called for the responses of the upgraded connections except that of
+// a failed upgrade.
+func (r *ReverseProxy) SetModifyResponse(mr func(resp *protocol.Response) error) {
+	r.modifyResponse = mr
+}
+
+// SetErrorHandler sets the handler of the errors of forwarding, e.g. failing to connect to
+// the upstream server. By default, the error is logged and 502 Bad Gateway is responded,
+// or 504 Gateway Timeout if it's a timeout.
+func (r *ReverseProxy) SetErrorHandler(eh func(ctx *app.RequestContext, err error)) {
+	r.errorHandler = eh
+}
+
+// SetUpgradeIdleTimeout sets the idle timeout of the upgraded connections, e.g. WebSocket,
+// which are closed once no data is transferred in either direction for timeout.
+// By default, they are kept until either side closes the connection.
+func (r *ReverseProxy) SetUpgradeIdleTimeout(timeout time.Duration) {
+	r.tunneler.IdleTimeout = timeout
+}
+
+// SetUpgradeMaxDuration sets the maximum duration of the upgraded connections, which are
+// closed once they have been open for it. By default, there is no limit.
+func (r *ReverseProxy) SetUpgradeMaxDuration(d time.Duration) {
+	r.tunneler.MaxDuration = d
+}
+
+// SetUpgradeCloseHandler sets the function called after an upgraded connection is closed with
+// the bytes copied, see TunnelUpgrade, and the error closing it, e.g. ErrIdleTimeout of pkg/common/errors.
+func (r *ReverseProxy) SetUpgradeCloseHandler(h func(stats *network.ConnStats, err error)) {
+	r.onUpgradeClose = h
+}
+
+// ServeHTTP forwards the request to the upstream server and copies the response to ctx.
+func (r *ReverseProxy) ServeHTTP(c context.Context, ctx *app.RequestContext) {
+	req := protocol.AcquireRequest()
+	defer protocol.ReleaseRequest(req)
+	r.prepareRequest(ctx, req)
+
+	if upType := upgradeType(&ctx.Request.Header); upType != "" {
+		req.Header.Set(consts.HeaderConnection, "Upgrade")
+		req.Header.Set(consts.HeaderUpgrade, upType)
+		r.serveUpgrade(ctx, req, upType)
+		return
+	}
+
+	resp := protocol.AcquireResponse()
+	if err := r.client.Do(c, req, resp); err != nil {
+		protocol.ReleaseResponse(resp)
+		r.handleError(ctx, err)
+		return
+	}
+	if err := r.copyResponse(ctx, resp); err != nil {
+		protocol.ReleaseResponse(resp)
+		r.handleError(ctx, err)
+	}
+}
+
+// prepareRequest copies the incoming request to req, which shares the body of it.
+func (r *ReverseProxy) prepareRequest(ctx *app.RequestContext, req *protocol.Request) {
+	ctx.Request.Header.CopyTo(&req.Header)
+	ctx.Request.URI().CopyTo(req.URI())
+	if ctx.Request.IsBodyStream() {
+		// see ext.NoCopyReader, the stream is released by the server
+		req.SetBodyStream(ctx.RequestBodyStream(), ctx.Request.Header.ContentLength())
+	} else {
+		req.SetBodyRaw(ctx.Request.Body())
+	}
+
+	if r.director != nil {
+		r.director(req)
+	}
+
+	// keep "TE: trailers" which is required by some protocols, e.g. gRPC
+	keepTrailers := tokenListContains(req.Header.PeekAll(consts.HeaderTE), "trailers")
+	removeHopHeaders(&req.Header)
+	if keepTrailers {
+		req.Header.Set(consts.HeaderTE, "trailers")
+	}
+
+	if ip, _, err := net.SplitHostPort(ctx.RemoteAddr().String()); err == nil {
+		if prior := req.Header.Peek(consts.HeaderXForwardedFor); len(prior) > 0 {
+			ip = string(prior) + ", " + ip
+		}
+		req.Header.Set(consts.HeaderXForwardedFor, ip)
+	}
+}
+
+func (r *ReverseProxy) defaultDirector(req *protocol.Request) {
+	uri := req.URI()
+	path := joinURLPath(r.target.PathOriginal(), uri.PathOriginal())
+	query := r.target.QueryString()
+	if len(query) == 0 {
+		query = uri.QueryString()
+	} else if q := uri.QueryString(); len(q) > 0 {
+		query = append(append(append([]byte(nil), query...), '&'), q...)
+	}
+
+	requestURI := append(append([]byte(nil), r.target.Scheme()...), "://"...)
+	requestURI = append(requestURI, r.target.Host()...)
+	requestURI = append(requestURI, path...)
+	if len(query) > 0 {
+		requestURI = append(append(requestURI, '?'), query...)
+	}
+	req.SetRequestURI(string(requestURI))
+	req.Header.SetHostBytes(r.target.Host())
+}
+
+// copyResponse copies resp to the response of ctx, resp is released by the response
+// once the body of it is written.
+func (r *ReverseProxy) copyResponse(ctx *app.RequestContext, resp *protocol.Response) error {
+	if r.modifyResponse != nil {
+		if err := r.modifyResponse(resp); err != nil {
+			return err
+		}
+	}
+
+	trailers := append([]byte(nil), resp.Header.Trailer().GetBytes()...)
+	removeHopHeaders(&resp.Header)
+	ctx.Response.Header.SetStatusCode(resp.StatusCode())
+	resp.Header.VisitAll(func(k, v []byte) {
+		if !strings.EqualFold(string(k), consts.HeaderContentLength) {
+			ctx.Response.Header.Add(string(k), string(v))
+		}
+	})
+
+	if !resp.IsBodyStream() {
+		ctx.Response.SetBody(resp.Body())
+		protocol.ReleaseResponse(resp)
+		return nil
+	}
+
+	// the trailers are copied at the end of the body, which must be declared before writing the header
+	size := resp.Header.ContentLength()
+	if size < 0 && len(trailers) > 0 {
+		// only the declared trailers are read from the upstream body
+		resp.Header.Trailer().SetTrailers(trailers)         //nolint:errcheck
+		ctx.Response.Header.Trailer().SetTrailers(trailers) //nolint:errcheck
+	}
+	body := &responseBody{resp: resp, trailer: ctx.Response.Header.Trailer()}
+	if _, ok := resp.BodyStream().(ext.NoCopyReader); ok {
+		ctx.Response.SetBodyStream(&noCopyResponseBody{body}, size)
+	} else {
+		ctx.Response.SetBodyStream(body, size)
+	}
+	return nil
+}
+
+// serveUpgrade sends req on a new connection to the upstream server, the connection is
+// tunneled to the client one after the upstream server switches protocols.
+//
+// The WebSocket handshake is validated before it is forwarded, and the Sec-WebSocket-Accept
+// of the upstream server is verified, so that the frames are only tunneled between the
+// endpoints of a valid handshake.
+func (r *ReverseProxy) serveUpgrade(ctx *app.RequestContext, req *protocol.Request, upType string) {
+	isWebSocket := strings.EqualFold(upType, "websocket")
+	if isWebSocket && !isWebSocketHandshake(req) {
+		ctx.AbortWithMsg("reverseproxy: invalid websocket handshake", consts.StatusBadRequest)
+		return
+	}
+
+	conn, err := r.dial(req)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+	resp := protocol.AcquireResponse()
+	defer protocol.ReleaseResponse(resp)
+
+	if err = reqI.Write(req, conn); err == nil {
+		err = conn.Flush()
+	}
+	if err == nil {
+		err = respI.ReadHeaderAndLimitBody(resp, conn, 0)
+	}
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		r.handleError(ctx, err)
+		return
+	}
+
+	if resp.StatusCode() != consts.StatusSwitchingProtocols {
+		conn.Close() //nolint:errcheck
+		if r.modifyResponse != nil {
+			if err = r.modifyResponse(resp); err != nil {
+				r.handleError(ctx, err)
+				return
+			}
+		}
+		removeHopHeaders(&resp.Header)
+		resp.CopyTo(&ctx.Response)
+		return
+	}
+
+	if resUpType := upgradeType(&resp.Header); !strings.EqualFold(resUpType, upType) {
+		conn.Close() //nolint:errcheck
+		r.handleError(ctx, fmt.Errorf("reverseproxy: upstream server switched protocol %q when %q was requested", resUpType, upType))
+		return
+	}
+	if isWebSocket {
+		accept := websocket.ComputeAcceptKey(req.Header.Peek(consts.HeaderSecWebSocketKey))
+		if string(resp.Header.Peek(consts.HeaderSecWebSocketAccept)) != accept {
+			conn.Close() //nolint:errcheck
+			r.handleError(ctx, errors.New("reverseproxy: upstream server responded invalid Sec-WebSocket-Accept"))
+			return
+		}
+	}
+	TunnelUpgrade(ctx, resp, conn, &r.tunneler, r.onUpgradeClose) //nolint:errcheck
+}
+
+// isWebSocketHandshake reports whether req is a valid WebSocket opening handshake of version 13,
+// see https://www.rfc-editor.org/rfc/rfc6455#section-4.1.
+func isWebSocketHandshake(req *protocol.Request) bool {
+	return string(req.Header.Method()) == consts.MethodGet &&
+		string(req.Header.Peek(consts.HeaderSecWebSocketVersion)) == "13" &&
+		websocket.IsValidChallengeKey(req.Header.Peek(consts.HeaderSecWebSocketKey))
+}
+
+func (r *ReverseProxy) dial(req *protocol.Request) (network.Conn, error) {
+	opt := r.client.GetOptions()
+	isTLS := string(req.URI().Scheme()) == "https"
+	host := string(req.URI().Host())
+	var tlsConfig *tls.Config
+	if isTLS {
+		tlsConfig = &tls.Config{}
+		if opt.TLSConfig != nil {
+			tlsConfig = opt.TLSConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				tlsConfig.ServerName = h
+			} else {
+				tlsConfig.ServerName = host
+			}
+		}
+	}
+	conn, err := opt.Dialer.DialConnection("tcp", utils.AddMissingPort(host, isTLS), opt.DialTimeout, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	// the tunnel is closed by either side instead of timeouts
+	if err = conn.SetReadTimeout(0); err != nil {
+		conn.Close() //nolint:errcheck
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (r *ReverseProxy) handleError(ctx *app.RequestContext, err error) {
+	if r.errorHandler != nil {
+		r.errorHandler(ctx, err)
+		return
+	}
+	hlog.SystemLogger().Errorf("Reverse proxy error=%v, uri=%s", err, ctx.Request.URI().String())
+	ctx.Response.Reset()
+	if isTimeout(err) {
+		ctx.AbortWithStatus(consts.StatusGatewayTimeout)
+		return
+	}
+	ctx.AbortWithStatus(consts.StatusBadGateway)
+}
+
+// responseBody is the body of the response to the client, which reads the streamed body
+// of the upstream response and copies the trailers of it at the end.
+type responseBody struct {
+	resp    *protocol.Response
+	trailer *protocol.Trailer
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.resp.BodyStream().Read(p)
+	if err == io.EOF {
+		b.copyTrailer()
+	}
+	return n, err
+}
+
+func (b *responseBody) copyTrailer() {
+	b.resp.Header.Trailer().VisitAll(func(k, v []byte) {
+		b.trailer.Set(string(k), string(v)) //nolint:errcheck
+	})
+}
+
+// Close releases the upstream response, along with the connection of it.
+func (b *responseBody) Close() error {
+	err := b.resp.CloseBodyStream()
+	protocol.ReleaseResponse(b.resp)
+	return err
+}
+
+// noCopyResponseBody implements ext.NoCopyReader so that the upstream body is piped
+// to the client without intermediate copies.
+type noCopyResponseBody struct {
+	*responseBody
+}
+
+func (b *noCopyResponseBody) NextNoCopy(n int) ([]byte, error) {
+	p, err := b.resp.BodyStream().(ext.NoCopyReader).NextNoCopy(n)
+	if err == io.EOF {
+		b.copyTrailer()
+	}
+	return p, err
+}
+
+func (b *noCopyResponseBody) Release() error {
+	return b.resp.BodyStream().(ext.NoCopyReader).Release()
+}
+
+// header is implemented by both protocol.RequestHeader and protocol.ResponseHeader.
+type header interface {
+	Peek(key string) []byte
+	PeekAll(key string) [][]byte
+	DelBytes(key []byte)
+}
+
+// removeHopHeaders removes the hop-by-hop headers, including the ones listed in Connection.
+func removeHopHeaders(h header) {
+	for _, v := range h.PeekAll(consts.HeaderConnection) {
+		for _, f := range strings.Split(string(v), ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				h.DelBytes([]byte(f))
+			}
+		}
+	}
+	for _, k := range hopHeaders {
+		h.DelBytes([]byte(k))
+	}
+}
+
+func upgradeType(h header) string {
+	if !tokenListContains(h.PeekAll(consts.HeaderConnection), "upgrade") {
+		return ""
+	}
+	return string(h.Peek(consts.HeaderUpgrade))
+}
+
+func tokenListContains(values [][]byte, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(string(v), ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinURLPath(a, b []byte) []byte {
+	aslash := len(a) > 0 && a[len(a)-1] == '/'
+	bslash := len(b) > 0 && b[0] == '/'
+	path := append([]byte(nil), a...)
+	switch {
+	case aslash && bslash:
+		return append(path, b[1:]...)
+	case !aslash && !bslash && len(b) > 0:
+		path = append(path, '/')
+	}
+	return append(path, b...)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, errs.ErrTimeout) || errors.Is(err, errs.ErrDialTimeout) || errors.Is(err, errs.ErrReadTimeout) ||
+		errors.Is(err, errs.ErrWriteTimeout) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
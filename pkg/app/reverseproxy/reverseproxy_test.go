@@ -0,0 +1,327 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reverseproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/websocket"
+)
+
+func TestJoinURLPath(t *testing.T) {
+	for _, tt := range []struct{ a, b, want string }{
+		{"", "", ""},
+		{"", "/foo", "/foo"},
+		{"/base", "/foo", "/base/foo"},
+		{"/base/", "/foo", "/base/foo"},
+		{"/base", "foo", "/base/foo"},
+		{"/base/", "", "/base/"},
+	} {
+		assert.DeepEqual(t, tt.want, string(joinURLPath([]byte(tt.a), []byte(tt.b))))
+	}
+}
+
+func TestRemoveHopHeaders(t *testing.T) {
+	var h protocol.RequestHeader
+	h.Set(consts.HeaderConnection, "keep-alive, X-Hop")
+	h.Set("X-Hop", "1")
+	h.Set(consts.HeaderKeepAlive, "timeout=5")
+	h.Set(consts.HeaderProxyAuthorization, "Basic Zm9vOmJhcg==")
+	h.Set("X-End", "1")
+	removeHopHeaders(&h)
+	assert.DeepEqual(t, "", h.Get(consts.HeaderConnection))
+	assert.DeepEqual(t, "", h.Get("X-Hop"))
+	assert.DeepEqual(t, "", h.Get(consts.HeaderKeepAlive))
+	assert.DeepEqual(t, "", h.Get(consts.HeaderProxyAuthorization))
+	assert.DeepEqual(t, "1", h.Get("X-End"))
+
+	h.Set(consts.HeaderConnection, "Upgrade")
+	h.Set(consts.HeaderUpgrade, "websocket")
+	assert.DeepEqual(t, "websocket", upgradeType(&h))
+	h.Set(consts.HeaderConnection, "keep-alive")
+	assert.DeepEqual(t, "", upgradeType(&h))
+}
+
+func TestNewSingleHostReverseProxy(t *testing.T) {
+	_, err := NewSingleHostReverseProxy("127.0.0.1:8080")
+	assert.NotNil(t, err)
+	_, err = NewSingleHostReverseProxy("ftp://127.0.0.1:8080")
+	assert.NotNil(t, err)
+	r, err := NewSingleHostReverseProxy("http://127.0.0.1:8080/base?a=1")
+	assert.Nil(t, err)
+
+	req := protocol.AcquireRequest()
+	defer protocol.ReleaseRequest(req)
+	req.SetRequestURI("http://example.com/foo?b=2")
+	r.director(req)
+	assert.DeepEqual(t, "http://127.0.0.1:8080/base/foo?a=1&b=2", req.URI().String())
+	assert.DeepEqual(t, "127.0.0.1:8080", string(req.Header.Host()))
+}
+
+func TestServeHTTP(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		remoteAddrs = make(map[string]bool)
+	)
+	upstream := server.New(server.WithHostPorts("127.0.0.1:9245"), server.WithTransport(standard.NewTransporter))
+	upstream.Use(func(c context.Context, ctx *app.RequestContext) {
+		mu.Lock()
+		remoteAddrs[ctx.RemoteAddr().String()] = true
+		mu.Unlock()
+	})
+	upstream.GET("/base/echo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Response.Header.Set("X-Query", string(ctx.Request.URI().QueryString()))
+		ctx.Response.Header.Set("X-Forwarded-For", ctx.Request.Header.Get(consts.HeaderXForwardedFor))
+		ctx.Response.Header.Set("X-Hop", ctx.Request.Header.Get("X-Hop"))
+		ctx.Response.Header.Set("X-Host", string(ctx.Request.Host()))
+		ctx.Response.Header.Set(consts.HeaderConnection, "X-Upstream-Hop")
+		ctx.Response.Header.Set("X-Upstream-Hop", "1")
+		ctx.String(consts.StatusCreated, "echo")
+	})
+	upstream.POST("/base/stream", func(c context.Context, ctx *app.RequestContext) {
+		body := append([]byte(nil), ctx.Request.Body()...)
+		ctx.Response.Header.Trailer().SetTrailers([]byte("X-Size"))                      //nolint:errcheck
+		ctx.Response.Header.Trailer().Set("X-Size", strings.Repeat("9", len(body)%10+1)) //nolint:errcheck
+		ctx.SetBodyStream(bytes.NewReader(body), -1)
+	})
+	upstream.GET("/base/upgrade", func(c context.Context, ctx *app.RequestContext) {
+		if upgradeType(&ctx.Request.Header) != "echo" {
+			ctx.AbortWithStatus(consts.StatusBadRequest)
+			return
+		}
+		ctx.SetStatusCode(consts.StatusSwitchingProtocols)
+		ctx.Response.Header.Set(consts.HeaderConnection, "Upgrade")
+		ctx.Response.Header.Set(consts.HeaderUpgrade, "echo")
+		ctx.Hijack(func(c network.Conn) {
+			io.Copy(c, c) //nolint:errcheck
+		})
+	})
+	go upstream.Spin()
+
+	r, err := NewSingleHostReverseProxy("http://127.0.0.1:9245/base")
+	assert.Nil(t, err)
+	upgradeClosed := make(chan *network.ConnStats, 1)
+	r.SetUpgradeCloseHandler(func(stats *network.ConnStats, err error) {
+		assert.Nil(t, err)
+		upgradeClosed <- stats
+	})
+	r.SetModifyResponse(func(resp *protocol.Response) error {
+		if resp.StatusCode() == consts.StatusNotFound {
+			return errors.New("not found")
+		}
+		resp.Header.Set("X-Modified", "1")
+		return nil
+	})
+	h := server.New(server.WithHostPorts("127.0.0.1:9246"), server.WithTransport(standard.NewTransporter), server.WithStreamBody(true))
+	h.Any("/*path", r.ServeHTTP)
+	down, err := NewSingleHostReverseProxy("http://127.0.0.1:9247")
+	assert.Nil(t, err)
+	h.GET("/down", down.ServeHTTP)
+	handled, err := NewSingleHostReverseProxy("http://127.0.0.1:9247")
+	assert.Nil(t, err)
+	handled.SetErrorHandler(func(ctx *app.RequestContext, err error) {
+		ctx.String(consts.StatusServiceUnavailable, "unavailable")
+	})
+	h.GET("/handled", handled.ServeHTTP)
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	c, err := client.NewClient()
+	assert.Nil(t, err)
+
+	t.Run("headers", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+			req.SetRequestURI("http://127.0.0.1:9246/echo?a=1")
+			req.Header.Set(consts.HeaderXForwardedFor, "10.0.0.1")
+			req.Header.Set(consts.HeaderConnection, "X-Hop")
+			req.Header.Set("X-Hop", "1")
+			assert.Nil(t, c.Do(context.Background(), req, resp))
+			assert.DeepEqual(t, consts.StatusCreated, resp.StatusCode())
+			assert.DeepEqual(t, "echo", string(resp.Body()))
+			assert.DeepEqual(t, "a=1", resp.Header.Get("X-Query"))
+			assert.DeepEqual(t, "10.0.0.1, 127.0.0.1", resp.Header.Get("X-Forwarded-For"))
+			assert.DeepEqual(t, "", resp.Header.Get("X-Hop"))
+			assert.DeepEqual(t, "127.0.0.1:9245", resp.Header.Get("X-Host"))
+			assert.DeepEqual(t, "", resp.Header.Get("X-Upstream-Hop"))
+			assert.DeepEqual(t, "1", resp.Header.Get("X-Modified"))
+		}
+		// the connections to the upstream server are reused
+		mu.Lock()
+		assert.DeepEqual(t, 1, len(remoteAddrs))
+		mu.Unlock()
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		body := strings.Repeat("hertz", 200000)
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		req.SetMethod(consts.MethodPost)
+		req.SetRequestURI("http://127.0.0.1:9246/stream")
+		req.SetBodyString(body)
+		assert.Nil(t, c.Do(context.Background(), req, resp))
+		assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+		assert.True(t, body == string(resp.Body()))
+		assert.DeepEqual(t, "9", resp.Header.Trailer().Get("X-Size"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		status, _, err := c.Get(context.Background(), nil, "http://127.0.0.1:9246/missing")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, consts.StatusBadGateway, status)
+		status, _, err = c.Get(context.Background(), nil, "http://127.0.0.1:9246/down")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, consts.StatusBadGateway, status)
+		status, body, err := c.Get(context.Background(), nil, "http://127.0.0.1:9246/handled")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, consts.StatusServiceUnavailable, status)
+		assert.DeepEqual(t, "unavailable", string(body))
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		conn, err := standard.NewDialer().DialConnection("tcp", "127.0.0.1:9246", time.Second, nil)
+		assert.Nil(t, err)
+		_, err = conn.Write([]byte("GET /upgrade HTTP/1.1\r\nHost: 127.0.0.1:9246\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n"))
+		assert.Nil(t, err)
+		var header []byte
+		for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+			b, err := conn.ReadByte()
+			assert.Nil(t, err)
+			header = append(header, b)
+		}
+		assert.True(t, strings.HasPrefix(string(header), "HTTP/1.1 101 Switching Protocols\r\n"))
+		assert.True(t, strings.Contains(string(header), "\r\nUpgrade: echo\r\n"))
+
+		for _, msg := range []string{"hello", "hertz"} {
+			_, err = conn.Write([]byte(msg))
+			assert.Nil(t, err)
+			p, err := conn.ReadBinary(len(msg))
+			assert.Nil(t, err)
+			assert.DeepEqual(t, msg, string(p))
+		}
+		conn.Close()
+		stats := <-upgradeClosed
+		assert.DeepEqual(t, int64(10), stats.BytesRead())
+		assert.DeepEqual(t, int64(10), stats.BytesWritten())
+	})
+}
+
+// handshake writes the request of an upgrade on a new connection and reads the response header.
+func handshake(t *testing.T, addr, request string) (network.Conn, string) {
+	conn, err := standard.NewDialer().DialConnection("tcp", addr, time.Second, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.SetReadTimeout(2*time.Second))
+	_, err = conn.Write([]byte(request))
+	assert.Nil(t, err)
+	var header []byte
+	for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+		b, err := conn.ReadByte()
+		assert.Nil(t, err)
+		header = append(header, b)
+	}
+	return conn, string(header)
+}
+
+func TestServeWebSocket(t *testing.T) {
+	upstream := server.New(server.WithHostPorts("127.0.0.1:9252"), server.WithTransport(standard.NewTransporter))
+	upstream.GET("/ws", func(c context.Context, ctx *app.RequestContext) {
+		u := websocket.Upgrader{}
+		u.Upgrade(ctx, func(conn *websocket.Conn) { //nolint:errcheck
+			for {
+				mt, p, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if err = conn.WriteMessage(mt, p); err != nil {
+					return
+				}
+			}
+		})
+	})
+	upstream.GET("/bad", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetStatusCode(consts.StatusSwitchingProtocols)
+		ctx.Response.Header.Set(consts.HeaderConnection, "Upgrade")
+		ctx.Response.Header.Set(consts.HeaderUpgrade, "websocket")
+		ctx.Response.Header.Set(consts.HeaderSecWebSocketAccept, "invalid")
+		ctx.Hijack(func(c network.Conn) {})
+	})
+	go upstream.Spin()
+
+	r, err := NewSingleHostReverseProxy("http://127.0.0.1:9252")
+	assert.Nil(t, err)
+	r.SetUpgradeIdleTimeout(300 * time.Millisecond)
+	h := server.New(server.WithHostPorts("127.0.0.1:9253"), server.WithTransport(standard.NewTransporter))
+	h.Any("/*path", r.ServeHTTP)
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	const request = "GET %s HTTP/1.1\r\nHost: 127.0.0.1:9253\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: %s\r\n\r\n"
+
+	t.Run("echo", func(t *testing.T) {
+		conn, header := handshake(t, "127.0.0.1:9253", fmt.Sprintf(request, "/ws", "dGhlIHNhbXBsZSBub25jZQ=="))
+		defer conn.Close()
+		assert.True(t, strings.HasPrefix(header, "HTTP/1.1 101 Switching Protocols\r\n"))
+		assert.True(t, strings.Contains(header, ": s3pPLMBiTxaQ9kYGzzhZRbK+xOo=\r\n"))
+
+		for _, msg := range []string{"hello", "hertz"} {
+			// a masked text frame from the client
+			frame := []byte{0x81, 0x80 | byte(len(msg)), 1, 2, 3, 4}
+			for i := 0; i < len(msg); i++ {
+				frame = append(frame, msg[i]^frame[2+i%4])
+			}
+			_, err = conn.Write(frame)
+			assert.Nil(t, err)
+			p, err := conn.ReadBinary(2 + len(msg))
+			assert.Nil(t, err)
+			assert.DeepEqual(t, append([]byte{0x81, byte(len(msg))}, msg...), p)
+		}
+
+		// closed by the idle timeout instead of the read timeout of the client
+		start := time.Now()
+		_, err = conn.ReadByte()
+		assert.NotNil(t, err)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("invalid handshake", func(t *testing.T) {
+		conn, header := handshake(t, "127.0.0.1:9253", fmt.Sprintf(request, "/ws", "invalid"))
+		defer conn.Close()
+		assert.True(t, strings.HasPrefix(header, "HTTP/1.1 400 Bad Request\r\n"))
+	})
+
+	t.Run("invalid accept", func(t *testing.T) {
+		conn, header := handshake(t, "127.0.0.1:9253", fmt.Sprintf(request, "/bad", "dGhlIHNhbXBsZSBub25jZQ=="))
+		defer conn.Close()
+		assert.True(t, strings.HasPrefix(header, "HTTP/1.1 502 Bad Gateway\r\n"))
+	})
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reverseproxy
+
+import (
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+var errNotSwitchingProtocols = errors.New("reverseproxy: not a 101 Switching Protocols response with Upgrade")
+
+// TunnelUpgrade copies resp, the 101 Switching Protocols response read from upstream, to the
+// response of ctx, and tunnels the connection of ctx to upstream by t once the response is
+// written, so that any upgraded protocol is passed through, e.g. by a handler forwarding the
+// upgrades on its own connections.
+//
+// onClose is called with the bytes copied and the error of the tunnel after it is closed, the
+// bytes read from the client are counted as read and the bytes from upstream as written.
+// upstream is closed after the tunnel is closed.
+//
+// An error is returned if resp doesn't switch protocols, in which case nothing is copied
+// and upstream is left to the caller.
+//
+// NOTE:
+//
+//	The data following the response in the buffer of upstream are tunneled as well,
+//	so resp must be read from upstream without reading ahead.
+func TunnelUpgrade(ctx *app.RequestContext, resp *protocol.Response, upstream network.Conn, t *network.Tunneler, onClose func(stats *network.ConnStats, err error)) error {
+	upType := string(resp.Header.Peek(consts.HeaderUpgrade))
+	if resp.StatusCode() != consts.StatusSwitchingProtocols || upType == "" {
+		return errNotSwitchingProtocols
+	}
+	if t == nil {
+		t = &network.Tunneler{}
+	}
+
+	removeHopHeaders(&resp.Header)
+	resp.Header.CopyTo(&ctx.Response.Header)
+	ctx.Response.Header.Set(consts.HeaderConnection, "Upgrade")
+	ctx.Response.Header.Set(consts.HeaderUpgrade, upType)
+	ctx.Hijack(func(c network.Conn) {
+		stats := &network.ConnStats{}
+		err := t.Tunnel(c, upstream, stats)
+		upstream.Close() //nolint:errcheck
+		if onClose != nil {
+			onClose(stats, err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reverseproxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	respI "github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+func TestTunnelUpgrade(t *testing.T) {
+	ctx := app.NewContext(0)
+	resp := protocol.AcquireResponse()
+	defer protocol.ReleaseResponse(resp)
+	resp.SetStatusCode(consts.StatusOK)
+	assert.DeepEqual(t, errNotSwitchingProtocols, TunnelUpgrade(ctx, resp, nil, nil, nil))
+	resp.SetStatusCode(consts.StatusSwitchingProtocols)
+	assert.DeepEqual(t, errNotSwitchingProtocols, TunnelUpgrade(ctx, resp, nil, nil, nil))
+	assert.False(t, ctx.Hijacked())
+
+	// an upstream server of a custom protocol, which greets right after switching protocols
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				br := bufio.NewReader(c)
+				for {
+					line, err := br.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == "\r\n" {
+						break
+					}
+				}
+				c.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: custom/1\r\n\r\nhello")) //nolint:errcheck
+				io.Copy(c, br)                                                                                               //nolint:errcheck
+			}()
+		}
+	}()
+
+	type result struct {
+		read, written int64
+		err           error
+	}
+	closed := make(chan result, 1)
+	h := server.New(server.WithHostPorts("127.0.0.1:9254"), server.WithTransport(standard.NewTransporter))
+	h.GET("/custom", func(c context.Context, ctx *app.RequestContext) {
+		conn, err := standard.NewDialer().DialConnection("tcp", ln.Addr().String(), time.Second, nil)
+		assert.Nil(t, err)
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: upstream\r\nConnection: Upgrade\r\nUpgrade: custom/1\r\n\r\n"))
+		assert.Nil(t, err)
+		resp := protocol.AcquireResponse()
+		defer protocol.ReleaseResponse(resp)
+		assert.Nil(t, respI.ReadHeaderAndLimitBody(resp, conn, 0))
+		err = TunnelUpgrade(ctx, resp, conn, &network.Tunneler{MaxDuration: 500 * time.Millisecond}, func(stats *network.ConnStats, err error) {
+			closed <- result{stats.BytesRead(), stats.BytesWritten(), err}
+		})
+		assert.Nil(t, err)
+	})
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	conn, header := handshake(t, "127.0.0.1:9254", "GET /custom HTTP/1.1\r\nHost: 127.0.0.1:9254\r\nConnection: Upgrade\r\nUpgrade: custom/1\r\n\r\n")
+	defer conn.Close()
+	assert.True(t, strings.HasPrefix(header, "HTTP/1.1 101 Switching Protocols\r\n"))
+	assert.True(t, strings.Contains(header, "\r\nUpgrade: custom/1\r\n"))
+	p, err := conn.ReadBinary(5)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello", string(p))
+
+	// the tunnel is closed after MaxDuration even if it's active
+	start := time.Now()
+	for {
+		if _, err = conn.Write([]byte("ping")); err != nil {
+			break
+		}
+		if _, err = conn.ReadBinary(4); err != nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, time.Since(start) < time.Second)
+	res := <-closed
+	assert.DeepEqual(t, errs.ErrTimeout, res.err)
+	assert.True(t, res.read > 0 && res.read%4 == 0)
+	assert.DeepEqual(t, res.read+5, res.written)
+}
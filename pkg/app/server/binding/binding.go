@@ -17,7 +17,9 @@
 package binding
 
 import (
+	"encoding"
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/bytedance/go-tagexpr/v2/binding"
@@ -34,12 +36,22 @@ func init() {
 
 var defaultBinder = binding.Default()
 
+var bindErrFactory = defaultBindErrFactory
+
+func defaultBindErrFactory(failField, msg string) error {
+	return &Error{
+		ErrType:   ErrTypeBinding,
+		FailField: failField,
+		Msg:       msg,
+	}
+}
+
 // BindAndValidate binds data from *protocol.Request to obj and validates them if needed.
 // NOTE:
 //
 //	obj should be a pointer.
 func BindAndValidate(req *protocol.Request, obj interface{}, pathParams param.Params) error {
-	return defaultBinder.IBindAndValidate(obj, wrapRequest(req), pathParams)
+	return BindAndValidateWithConfig(req, obj, pathParams, defaultBindConfig)
 }
 
 // Bind binds data from *protocol.Request to obj.
@@ -47,15 +59,79 @@ func BindAndValidate(req *protocol.Request, obj interface{}, pathParams param.Pa
 //
 //	obj should be a pointer.
 func Bind(req *protocol.Request, obj interface{}, pathParams param.Params) error {
-	return defaultBinder.IBind(obj, wrapRequest(req), pathParams)
+	return BindWithConfig(req, obj, pathParams, defaultBindConfig)
+}
+
+// BindAndValidateWithConfig is like BindAndValidate, but resolves the sources of fields with config.
+// NOTE:
+//
+//	obj should be a pointer.
+//	If config==nil, the built-in order is used.
+func BindAndValidateWithConfig(req *protocol.Request, obj interface{}, pathParams param.Params, config *BindConfig) error {
+	r, params, err := prepareRequest(req, obj, pathParams, config, false)
+	if err != nil {
+		return err
+	}
+	if customValidator == nil {
+		return defaultBinder.IBindAndValidate(obj, r, params)
+	}
+	if err = defaultBinder.IBind(obj, r, params); err != nil {
+		return err
+	}
+	return customValidator.ValidateStruct(obj)
 }
 
-// Validate validates obj with "vd" tag
+// BindWithConfig is like Bind, but resolves the sources of fields with config.
+// NOTE:
+//
+//	obj should be a pointer.
+//	If config==nil, the built-in order is used.
+func BindWithConfig(req *protocol.Request, obj interface{}, pathParams param.Params, config *BindConfig) error {
+	r, params, err := prepareRequest(req, obj, pathParams, config, false)
+	if err != nil {
+		return err
+	}
+	return defaultBinder.IBind(obj, r, params)
+}
+
+// BindSources binds data from the given sources of *protocol.Request to obj,
+// the data of the other sources is ignored.
+// NOTE:
+//
+//	obj should be a pointer.
+//	With SourceJSON, the body is decoded as json regardless of Content-Type.
+func BindSources(req *protocol.Request, obj interface{}, pathParams param.Params, sources ...Source) error {
+	keep := make(map[Source]bool, len(sources))
+	for _, s := range sources {
+		keep[s] = true
+	}
+	if keep[SourceJSON] {
+		if body := req.Body(); len(body) > 0 {
+			if err := hjson.Unmarshal(body, obj); err != nil {
+				return bindErrFactory("", err.Error())
+			}
+		}
+	}
+	r, params, err := prepareRequest(req, obj, pathParams, nil, true)
+	if err != nil {
+		return err
+	}
+	r.keepSources(keep)
+	if !keep[SourcePath] {
+		params = nil
+	}
+	return defaultBinder.IBind(obj, r, params)
+}
+
+// Validate validates obj with "vd" tag, or with the validator set by SetValidator.
 // NOTE:
 //
 //	obj should be a pointer.
 //	Validate should be called after Bind.
 func Validate(obj interface{}) error {
+	if customValidator != nil {
+		return customValidator.ValidateStruct(obj)
+	}
 	return defaultBinder.Validate(obj)
 }
 
@@ -75,9 +151,17 @@ func SetLooseZeroMode(enable bool) {
 //	If errFactory==nil, the default is used.
 //	SetErrorFactory will remain in effect once it has been called.
 func SetErrorFactory(bindErrFactory, validatingErrFactory func(failField, msg string) error) {
+	setBindErrFactory(bindErrFactory)
 	defaultBinder.SetErrorFactory(bindErrFactory, validatingErrFactory)
 }
 
+func setBindErrFactory(factory func(failField, msg string) error) {
+	if factory == nil {
+		factory = defaultBindErrFactory
+	}
+	bindErrFactory = factory
+}
+
 // MustRegTypeUnmarshal registers unmarshal function of type.
 // NOTE:
 //
@@ -85,6 +169,50 @@ func SetErrorFactory(bindErrFactory, validatingErrFactory func(failField, msg st
 //	MustRegTypeUnmarshal will remain in effect once it has been called.
 func MustRegTypeUnmarshal(t reflect.Type, fn func(v string, emptyAsZero bool) (reflect.Value, error)) {
 	binding.MustRegTypeUnmarshal(t, fn)
+	customTypes.Store(t, struct{}{})
+}
+
+// RegTypeUnmarshal registers unmarshal function of type.
+// NOTE:
+//
+//	It returns an error if t is a basic type or a pointer type, or fn fails the self-check with an empty value.
+//	RegTypeUnmarshal will remain in effect once it has been called.
+func RegTypeUnmarshal(t reflect.Type, fn func(v string, emptyAsZero bool) (reflect.Value, error)) error {
+	if err := binding.RegTypeUnmarshal(t, fn); err != nil {
+		return err
+	}
+	customTypes.Store(t, struct{}{})
+	return nil
+}
+
+// MustRegTextUnmarshaler registers the type pointed to by v as a bindable type,
+// whose values are decoded by its UnmarshalText method.
+// It is a shortcut for types such as uuid.UUID or decimal.Decimal, e.g.
+//
+//	binding.MustRegTextUnmarshaler((*uuid.UUID)(nil))
+//
+// NOTE:
+//
+//	v must be a pointer and the type it points to must not be a basic type.
+//	The empty string is bound to the zero value when LooseZeroMode is enabled.
+//	It will panic if exist error.
+//	MustRegTextUnmarshaler will remain in effect once it has been called.
+func MustRegTextUnmarshaler(v encoding.TextUnmarshaler) {
+	pt := reflect.TypeOf(v)
+	if pt == nil || pt.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("binding: MustRegTextUnmarshaler expects a pointer, but got %v", pt))
+	}
+	t := pt.Elem()
+	MustRegTypeUnmarshal(t, func(s string, emptyAsZero bool) (reflect.Value, error) {
+		if s == "" && emptyAsZero {
+			return reflect.Zero(t), nil
+		}
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr.Elem(), nil
+	})
 }
 
 // MustRegValidateFunc registers validator function expression.
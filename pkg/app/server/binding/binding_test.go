@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"mime/multipart"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
@@ -263,6 +264,63 @@ tailfoobar`
 	assert.DeepEqual(t, "TODO3", req.F2.Filename)
 }
 
+func TestBindingFileWithFields(t *testing.T) {
+	type Upload struct {
+		Name   string                  `form:"name,required"`
+		Avatar *multipart.FileHeader   `form:"avatar,required"`
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+
+	s := "--foo\r\n" +
+		"Content-Disposition: form-data; name=\"name\"\r\n\r\n" +
+		"hertz\r\n" +
+		"--foo\r\n" +
+		"Content-Disposition: form-data; name=\"avatar\"; filename=\"a.png\"\r\n" +
+		"Content-Type: image/png\r\n\r\n" +
+		"png\r\n" +
+		"--foo\r\n" +
+		"Content-Disposition: form-data; name=\"photos\"; filename=\"b.png\"\r\n\r\n" +
+		"b\r\n" +
+		"--foo\r\n" +
+		"Content-Disposition: form-data; name=\"photos\"; filename=\"c.png\"\r\n\r\n" +
+		"c\r\n" +
+		"--foo--\r\n"
+	newRequest := func(body string) *protocol.Request {
+		r := protocol.NewRequest("POST", "/upload", bytes.NewBufferString(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte("multipart/form-data; boundary=foo"))
+		return r
+	}
+
+	var req Upload
+	err := BindAndValidate(newRequest(s), &req, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hertz", req.Name)
+	assert.DeepEqual(t, "a.png", req.Avatar.Filename)
+	assert.DeepEqual(t, "image/png", req.Avatar.Header.Get("Content-Type"))
+	assert.DeepEqual(t, 2, len(req.Photos))
+	assert.DeepEqual(t, "c.png", req.Photos[1].Filename)
+
+	// missing required file
+	s = "--foo\r\n" +
+		"Content-Disposition: form-data; name=\"name\"\r\n\r\n" +
+		"hertz\r\n" +
+		"--foo--\r\n"
+	req = Upload{}
+	err = BindAndValidate(newRequest(s), &req, nil)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "Avatar", err.(*Error).FailField)
+
+	// malformed body
+	s = "--foo\r\n" +
+		"Content-Disposition: form-data; name=\"avatar\"; filename=\"a.png\"\r\n\r\n" +
+		"png"
+	req = Upload{}
+	err = BindAndValidate(newRequest(s), &req, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "invalid multipart form"))
+}
+
 type BindError struct {
 	ErrType, FailField, Msg string
 }
@@ -435,3 +493,59 @@ func TestQueryAlias(t *testing.T) {
 	assert.DeepEqual(t, "string2", req.D[0])
 	assert.DeepEqual(t, "string3", req.D[1])
 }
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p *testPoint) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+func TestMustRegTextUnmarshaler(t *testing.T) {
+	type TestBind struct {
+		A testPoint    `query:"a"`
+		B *testPoint   `header:"b"`
+		C []testPoint  `query:"c"`
+		D []*testPoint `query:"d"`
+	}
+
+	MustRegTextUnmarshaler((*testPoint)(nil))
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?a=1,2&c=3,4&c=5,6&d=7,8")
+	r.SetHeader("b", "9,10")
+
+	var req TestBind
+	err := Bind(r, &req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, testPoint{1, 2}, req.A)
+	assert.DeepEqual(t, testPoint{9, 10}, *req.B)
+	assert.DeepEqual(t, []testPoint{{3, 4}, {5, 6}}, req.C)
+	assert.DeepEqual(t, 1, len(req.D))
+	assert.DeepEqual(t, testPoint{7, 8}, *req.D[0])
+
+	r.SetRequestURI("/foo/bar?a=invalid")
+	req = TestBind{}
+	err = Bind(r, &req, nil)
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+
+	assert.Panic(t, func() {
+		MustRegTextUnmarshaler(nil)
+	})
+}
+
+func TestRegTypeUnmarshal(t *testing.T) {
+	type MyInt int
+	err := RegTypeUnmarshal(reflect.TypeOf(MyInt(0)), func(v string, emptyAsZero bool) (reflect.Value, error) {
+		return reflect.ValueOf(MyInt(0)), nil
+	})
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/internal/bytesconv"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// BodyDecoder decodes the request body into obj.
+type BodyDecoder func(body []byte, obj interface{}) error
+
+// errNotDecoded is returned by the built-in decoders if obj is not of the type they decode,
+// the body is left to the binder then as if there were no decoder.
+var errNotDecoded = errors.New("body is not decoded")
+
+var (
+	bodyDecodersLock sync.RWMutex
+	bodyDecoders     = map[string]BodyDecoder{
+		"application/protobuf":    decodeProtobuf,
+		"application/msgpack":     decodeMsgpack,
+		"application/x-msgpack":   decodeMsgpack,
+		"application/vnd.msgpack": decodeMsgpack,
+	}
+)
+
+// RegBodyDecoder registers the decoder of request bodies with contentType,
+// which is used by Bind and BindAndValidate before binding the other parameters, e.g.
+//
+//	binding.RegBodyDecoder("application/msgpack", func(body []byte, obj interface{}) error {
+//		return msgpack.Unmarshal(body, obj)
+//	})
+//
+// NOTE:
+//
+//	"application/json", "application/x-protobuf" and form bodies are decoded by default,
+//	the decoder registered for them takes the place of the default one.
+//	"application/protobuf" bodies are decoded into proto.Message, and msgpack bodies
+//	("application/msgpack", "application/x-msgpack" and "application/vnd.msgpack") are decoded
+//	by the json tags of the fields, by the built-in decoders.
+//	The required option of json tag can not be checked against the decoded body, use vd tag instead.
+//	If decoder==nil, the decoder of contentType is removed.
+func RegBodyDecoder(contentType string, decoder BodyDecoder) {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	bodyDecodersLock.Lock()
+	defer bodyDecodersLock.Unlock()
+	if decoder == nil {
+		delete(bodyDecoders, contentType)
+		return
+	}
+	bodyDecoders[contentType] = decoder
+}
+
+func getBodyDecoder(contentType string) (BodyDecoder, bool) {
+	bodyDecodersLock.RLock()
+	decoder, ok := bodyDecoders[strings.ToLower(contentType)]
+	bodyDecodersLock.RUnlock()
+	return decoder, ok
+}
+
+// contentType returns the media type of the request without parameters.
+func contentType(req *protocol.Request) string {
+	ct := bytesconv.B2s(req.Header.ContentType())
+	if idx := strings.IndexByte(ct, ';'); idx >= 0 {
+		ct = ct[:idx]
+	}
+	return strings.TrimSpace(ct)
+}
+
+// decodeBody decodes the body of r into obj with the registered decoder,
+// and hides the body from the binder if it has been decoded.
+func decodeBody(r *bindRequest, obj interface{}) error {
+	ct := contentType(r.req)
+	decoder, ok := getBodyDecoder(ct)
+	if !ok {
+		return nil
+	}
+	if body := r.req.Body(); len(body) > 0 {
+		if err := decoder(body, obj); err != nil {
+			if err == errNotDecoded {
+				return nil
+			}
+			return bindErrFactory("", "failed to decode "+ct+" body: "+err.Error())
+		}
+	}
+	r.skipBody = true
+	return nil
+}
+
+func decodeProtobuf(body []byte, obj interface{}) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return errNotDecoded
+	}
+	return proto.Unmarshal(body, msg)
+}
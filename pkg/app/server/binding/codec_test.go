@@ -0,0 +1,174 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/testdata/proto"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	protov2 "google.golang.org/protobuf/proto"
+)
+
+func TestBindProtobuf(t *testing.T) {
+	label := "hertz"
+	typ := int32(1)
+	body, err := protov2.Marshal(&proto.Test{Label: &label, Type: &typ, Reps: []int64{1, 2}})
+	assert.Nil(t, err)
+
+	for _, ct := range []string{"application/x-protobuf", "application/protobuf"} {
+		r := protocol.NewRequest("POST", "/foo", bytes.NewReader(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte(ct))
+
+		var req proto.Test
+		err = BindAndValidate(r, &req, nil)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, label, req.GetLabel())
+		assert.DeepEqual(t, typ, req.GetType())
+		assert.DeepEqual(t, []int64{1, 2}, req.GetReps())
+	}
+}
+
+func TestRegBodyDecoder(t *testing.T) {
+	type TestBind struct {
+		A string `json:"a"`
+		B int    `query:"b"`
+		C string `json:"c" vd:"len($)>0"`
+	}
+	const ct = "application/x-test"
+	RegBodyDecoder(ct, func(body []byte, obj interface{}) error {
+		if !bytes.HasPrefix(body, []byte("test:")) {
+			return errors.New("bad prefix")
+		}
+		return json.Unmarshal(body[len("test:"):], obj)
+	})
+	defer RegBodyDecoder(ct, nil)
+
+	newRequest := func(body string) *protocol.Request {
+		r := protocol.NewRequest("POST", "/foo?b=2", strings.NewReader(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte(ct + "; charset=utf-8"))
+		return r
+	}
+
+	var req TestBind
+	err := BindAndValidate(newRequest(`test:{"a":"aaa","c":"ccc"}`), &req, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "aaa", req.A)
+	assert.DeepEqual(t, 2, req.B)
+	assert.DeepEqual(t, "ccc", req.C)
+
+	req = TestBind{}
+	err = BindAndValidate(newRequest(`test:{"a":"aaa"}`), &req, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "C"))
+
+	req = TestBind{}
+	err = Bind(newRequest(`{"a":"aaa"}`), &req, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "failed to decode "+ct+" body: bad prefix"))
+
+	RegBodyDecoder(ct, nil)
+	req = TestBind{}
+	err = Bind(newRequest(`test:{"a":"aaa"}`), &req, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "", req.A)
+	assert.DeepEqual(t, 2, req.B)
+}
+
+func TestBindProtobufFallback(t *testing.T) {
+	type TestBind struct {
+		A string `json:"a"`
+		B int    `query:"b"`
+	}
+	body := []byte(`{"a":"aaa"}`)
+	r := protocol.NewRequest("POST", "/foo?b=2", bytes.NewReader(body))
+	r.Header.SetContentLength(len(body))
+	r.Header.SetContentTypeBytes([]byte("application/protobuf"))
+
+	// the body is left to the binder if obj is not a proto.Message
+	var req TestBind
+	err := Bind(r, &req, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 2, req.B)
+}
+
+func TestBindMsgpack(t *testing.T) {
+	type TestBind struct {
+		A string            `json:"a"`
+		N int               `json:"n"`
+		U uint              `json:"u"`
+		F float64           `json:"f"`
+		B []byte            `json:"b"`
+		L []interface{}     `json:"l"`
+		T time.Time         `json:"t"`
+		M map[string]string `json:"m"`
+		Q int               `query:"q"`
+	}
+	body := []byte{
+		0x88,
+		0xa1, 'a', 0xd9, 0x03, 'a', 'a', 'a',
+		0xa1, 'n', 0xfb,
+		0xa1, 'u', 0xcd, 0x01, 0x2c,
+		0xa1, 'f', 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+		0xa1, 'b', 0xc4, 0x02, 0x01, 0x02,
+		0xa1, 'l', 0x93, 0x01, 0xc3, 0xc0,
+		0xa1, 't', 0xd6, 0xff, 0x3b, 0x9a, 0xca, 0x00,
+		0xa1, 'm', 0x81, 0x01, 0xa1, 'x',
+	}
+	newRequest := func(body []byte, ct string) *protocol.Request {
+		r := protocol.NewRequest("POST", "/foo?q=3", bytes.NewReader(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte(ct))
+		return r
+	}
+
+	for _, ct := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"} {
+		var req TestBind
+		err := Bind(newRequest(body, ct), &req, nil)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "aaa", req.A)
+		assert.DeepEqual(t, -5, req.N)
+		assert.DeepEqual(t, uint(300), req.U)
+		assert.DeepEqual(t, 1.5, req.F)
+		assert.DeepEqual(t, []byte{1, 2}, req.B)
+		assert.DeepEqual(t, []interface{}{float64(1), true, nil}, req.L)
+		assert.True(t, req.T.Equal(time.Unix(1000000000, 0)))
+		assert.DeepEqual(t, map[string]string{"1": "x"}, req.M)
+		assert.DeepEqual(t, 3, req.Q)
+	}
+
+	for _, body := range [][]byte{
+		body[:len(body)-1],
+		append(body, 0xc0),
+		{0xc1},
+		{0xdd, 0xff, 0xff, 0xff, 0xff},
+		{0xd4, 0x01, 0x00},
+	} {
+		var req TestBind
+		err := Bind(newRequest(body, "application/msgpack"), &req, nil)
+		assert.NotNil(t, err)
+		assert.True(t, strings.Contains(err.Error(), "msgpack"))
+	}
+}
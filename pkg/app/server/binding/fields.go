@@ -0,0 +1,317 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/route/param"
+)
+
+const (
+	tagTimeFormat   = "time_format"
+	tagTimeLocation = "time_location"
+	tagTimeUTC      = "time_utc"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+	// customTypes are the types registered by RegTypeUnmarshal,
+	// which are decoded as a whole rather than field by field.
+	customTypes sync.Map
+)
+
+// fieldSources describes the sources a field can be bound from.
+type fieldSources struct {
+	name    string
+	typ     reflect.Type
+	isSlice bool
+	keys    map[Source]string
+
+	// nested means the field can be bound from keys like "a.b" or "a[b]".
+	nested bool
+
+	timeFormat   string
+	timeLocation *time.Location
+}
+
+// typeInfo is the result of analysing a receiver type, it is cached by type.
+type typeInfo struct {
+	multiSource []*fieldSources
+	nested      []*fieldSources
+	timed       []*fieldSources
+	files       []*fieldSources
+
+	// formKeys are the form keys of all fields, which are used to detect unknown fields.
+	formKeys map[string]bool
+}
+
+// needsRewrite reports whether the request data must be adjusted
+// even if there is no BindConfig.
+func (info *typeInfo) needsRewrite() bool {
+	return len(info.nested) > 0 || len(info.timed) > 0
+}
+
+var (
+	typeInfoCache sync.Map
+	sourceTags    = []Source{SourcePath, SourceForm, SourceQuery, SourceCookie, SourceHeader}
+)
+
+func getTypeInfo(t reflect.Type) *typeInfo {
+	if v, ok := typeInfoCache.Load(t); ok {
+		return v.(*typeInfo)
+	}
+	info := &typeInfo{formKeys: make(map[string]bool)}
+	collectFieldSources(t, "", false, 0, info)
+	typeInfoCache.Store(t, info)
+	return info
+}
+
+const maxFieldSourcesDepth = 10
+
+func collectFieldSources(t reflect.Type, jsonPrefix string, parentTagged bool, depth int, info *typeInfo) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || depth > maxFieldSourcesDepth || isOpaqueType(t) {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		fs := &fieldSources{
+			name: sf.Name,
+			typ:  sf.Type,
+			keys: make(map[Source]string),
+		}
+		omit := make(map[Source]bool)
+		tagged := false
+		for _, s := range sourceTags {
+			value, ok := sf.Tag.Lookup(string(s))
+			if !ok {
+				continue
+			}
+			name := tagName(value, sf.Name)
+			if name == "-" {
+				omit[s] = true
+				continue
+			}
+			if s == SourceHeader {
+				name = textproto.CanonicalMIMEHeaderKey(name)
+			}
+			fs.keys[s] = name
+			tagged = true
+		}
+
+		jsonName := sf.Name
+		if sf.Anonymous {
+			jsonName = ""
+		}
+		if value, ok := sf.Tag.Lookup(string(SourceJSON)); ok {
+			if name := tagName(value, sf.Name); name == "-" {
+				omit[SourceJSON] = true
+			} else {
+				jsonName = name
+				tagged = true
+			}
+		}
+		jsonPath := joinJSONPath(jsonPrefix, jsonName)
+		if !omit[SourceJSON] && (tagged || !parentTagged) {
+			fs.keys[SourceJSON] = jsonPath
+		}
+
+		if !tagged && !parentTagged && !sf.Anonymous {
+			for _, s := range sourceTags {
+				if !omit[s] {
+					fs.keys[s] = sf.Name
+				}
+			}
+		}
+
+		ft := derefType(sf.Type)
+		fs.isSlice = ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array
+		if !sf.Anonymous {
+			fs.nested = isNestedType(ft)
+			if ft == timeType || (fs.isSlice && derefType(ft.Elem()) == timeType) {
+				fs.timeFormat = sf.Tag.Get(tagTimeFormat)
+				fs.timeLocation = timeLocationOf(sf.Tag)
+			}
+			if len(fs.keys) > 1 {
+				info.multiSource = append(info.multiSource, fs)
+			}
+			if fs.nested && (fs.keys[SourceQuery] != "" || fs.keys[SourceForm] != "") {
+				info.nested = append(info.nested, fs)
+			}
+			if fs.timeFormat != "" {
+				info.timed = append(info.timed, fs)
+			}
+			if key := fs.keys[SourceForm]; key != "" {
+				info.formKeys[key] = true
+			}
+			if fs.keys[SourceForm] != "" && (ft == fileHeaderType || (fs.isSlice && derefType(ft.Elem()) == fileHeaderType)) {
+				info.files = append(info.files, fs)
+			}
+		}
+		collectFieldSources(ft, jsonPath, parentTagged || tagged, depth+1, info)
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isOpaqueType reports whether t is decoded as a whole by the binder.
+func isOpaqueType(t reflect.Type) bool {
+	if t == timeType || t == fileHeaderType {
+		return true
+	}
+	_, ok := customTypes.Load(t)
+	return ok
+}
+
+// isNestedType reports whether t can be built from keys like "a.b", "a[b]" or "a[0][b]".
+func isNestedType(t reflect.Type) bool {
+	t = derefType(t)
+	if isOpaqueType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		elem := derefType(t.Elem())
+		return !isOpaqueType(elem) && (elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map)
+	default:
+		return false
+	}
+}
+
+func tagName(value, fieldName string) string {
+	value = strings.TrimSpace(value)
+	if idx := strings.IndexByte(value, ','); idx >= 0 {
+		value = strings.TrimSpace(value[:idx])
+	}
+	if value == "" {
+		return fieldName
+	}
+	return value
+}
+
+func joinJSONPath(prefix, name string) string {
+	if name == "" {
+		return prefix
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// prepareRequest adjusts the request data for the fields of obj,
+// and returns the request and path parameters which should be bound.
+// If skipBody is true, the body is left to the caller.
+func prepareRequest(req *protocol.Request, obj interface{}, pathParams param.Params, config *BindConfig, skipBody bool) (*bindRequest, param.Params, error) {
+	r := &bindRequest{req: req, skipBody: skipBody}
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return r, pathParams, nil
+	}
+	if !skipBody {
+		if err := decodeBody(r, obj); err != nil {
+			return nil, nil, err
+		}
+	}
+	info := getTypeInfo(t)
+	if err := checkMultipartForm(req, info); err != nil {
+		return nil, nil, err
+	}
+	if !info.needsRewrite() && (config == nil || (len(info.multiSource) == 0 && !config.DisallowUnknownFields)) {
+		return r, pathParams, nil
+	}
+
+	values := &sourceValues{
+		path:    pathParams,
+		query:   r.GetQuery(),
+		header:  r.GetHeader(),
+		cookies: r.GetCookies(),
+	}
+	switch contentType(req) {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		values.form, _ = r.GetPostForm()
+	case "application/json":
+		if config != nil && !r.skipBody {
+			dec := json.NewDecoder(bytes.NewReader(req.Body()))
+			dec.UseNumber()
+			_ = dec.Decode(&values.json)
+		}
+	}
+	if values.form == nil {
+		values.form = make(url.Values)
+	}
+	if config != nil && config.DisallowUnknownFields {
+		if err := checkUnknownFields(r, t, info, values); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := buildNested(info, values); err != nil {
+		return nil, nil, err
+	}
+	if err := formatTimes(info, values); err != nil {
+		return nil, nil, err
+	}
+	if config != nil {
+		if err := applyPrecedence(info, values, config); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r.query = values.query
+	r.form = values.form
+	r.header = values.header
+	r.cookies = values.cookies
+	r.files, _ = r.GetFileHeaders()
+	r.resolved = true
+	return r, values.path, nil
+}
+
+// checkMultipartForm reports the error of parsing a multipart/form-data body
+// if obj has file fields, otherwise the files would be treated as absent.
+func checkMultipartForm(req *protocol.Request, info *typeInfo) error {
+	if len(info.files) == 0 || len(req.Header.MultipartFormBoundary()) == 0 {
+		return nil
+	}
+	if _, err := req.MultipartForm(); err != nil {
+		return bindErrFactory(info.files[0].name, "invalid multipart form: "+err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// msgpackMaxDepth is the max nesting depth of the msgpack arrays and maps.
+	msgpackMaxDepth = 1000
+
+	// msgpackTimestampType is the type of the timestamp extension, see
+	// https://github.com/msgpack/msgpack/blob/master/spec.md#timestamp-extension-type
+	msgpackTimestampType = -1
+)
+
+var (
+	errMsgpackShortData = errors.New("msgpack: unexpected end of data")
+	errMsgpackTooDeep   = errors.New("msgpack: exceeded max depth")
+)
+
+// decodeMsgpack decodes the msgpack body into obj. The body is decoded into the generic values
+// which are unmarshalled by encoding/json, so that the fields are matched by their json tags,
+// the binaries are bound to []byte and the timestamps to time.Time.
+func decodeMsgpack(body []byte, obj interface{}) error {
+	d := msgpackDecoder{data: body}
+	v, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return errors.New("msgpack: extra data after the top-level value")
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, obj)
+}
+
+// msgpackDecoder decodes the msgpack data into nil, bool, int64, uint64, float64, string, []byte,
+// time.Time, []interface{} and map[string]interface{}, whose non-string keys are formatted by fmt.
+type msgpackDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.off < n {
+		return nil, errMsgpackShortData
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+// length reads the length of size bytes.
+func (d *msgpackDecoder) length(size int) (int, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch size {
+	case 1:
+		n = uint64(b[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(b))
+	default:
+		n = uint64(binary.BigEndian.Uint32(b))
+	}
+	// every element takes one byte at least
+	if n > uint64(len(d.data)-d.off) {
+		return 0, errMsgpackShortData
+	}
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errMsgpackTooDeep
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.decodeMap(int(c&0x0f), depth)
+	case c >= 0x90 && c <= 0x9f:
+		return d.decodeArray(int(c&0x0f), depth)
+	case c >= 0xa0 && c <= 0xbf:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.next(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return uintOf(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := d.next(1 << (c - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		return intOf(b), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: invalid code 0x%x", c)
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n, depth int) (interface{}, error) {
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n, depth int) (interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := k.(string); ok {
+			m[s] = v
+		} else {
+			m[fmt.Sprint(k)] = v
+		}
+	}
+	return m, nil
+}
+
+func (d *msgpackDecoder) decodeExt(n int) (interface{}, error) {
+	b, err := d.next(1 + n)
+	if err != nil {
+		return nil, err
+	}
+	typ, b := int8(b[0]), b[1:]
+	if typ != msgpackTimestampType {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", typ)
+	}
+	switch len(b) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)).UTC(), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))).UTC(), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp of %d bytes", len(b))
+}
+
+func uintOf(b []byte) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b))
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func intOf(b []byte) int64 {
+	switch len(b) {
+	case 1:
+		return int64(int8(b[0]))
+	case 2:
+		return int64(int16(binary.BigEndian.Uint16(b)))
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(b)))
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
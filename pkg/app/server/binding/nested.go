@@ -0,0 +1,351 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// paramNode is the tree built from keys like "a.b.c", "a[b][c]" and "a[0][b]".
+type paramNode struct {
+	values   []string
+	children map[string]*paramNode
+}
+
+func (n *paramNode) insert(segs []string, values []string) {
+	for _, seg := range segs {
+		if n.children == nil {
+			n.children = make(map[string]*paramNode)
+		}
+		child, ok := n.children[seg]
+		if !ok {
+			child = &paramNode{}
+			n.children[seg] = child
+		}
+		n = child
+	}
+	n.values = append(n.values, values...)
+}
+
+// splitKey splits the suffix of a key, such as ".b[c][0]", into segments.
+// It returns false if the suffix is malformed.
+func splitKey(suffix string) ([]string, bool) {
+	var segs []string
+	for len(suffix) > 0 {
+		switch suffix[0] {
+		case '.':
+			suffix = suffix[1:]
+			end := strings.IndexAny(suffix, ".[")
+			if end < 0 {
+				end = len(suffix)
+			}
+			if end == 0 {
+				return nil, false
+			}
+			segs = append(segs, suffix[:end])
+			suffix = suffix[end:]
+		case '[':
+			end := strings.IndexByte(suffix, ']')
+			if end <= 1 {
+				return nil, false
+			}
+			segs = append(segs, suffix[1:end])
+			suffix = suffix[end+1:]
+		default:
+			return nil, false
+		}
+	}
+	return segs, len(segs) > 0
+}
+
+// buildNested builds the values of nested fields from keys like "a.b" or "a[b]"
+// when the key of the field itself is absent.
+func buildNested(info *typeInfo, values *sourceValues) error {
+	for _, f := range info.nested {
+		for _, s := range []Source{SourceQuery, SourceForm} {
+			key, ok := f.keys[s]
+			if !ok {
+				continue
+			}
+			m := values.query
+			if s == SourceForm {
+				m = values.form
+			}
+			if len(m[key]) > 0 {
+				continue
+			}
+			root := &paramNode{}
+			for k, vs := range m {
+				if len(k) <= len(key) || !strings.HasPrefix(k, key) {
+					continue
+				}
+				if segs, ok := splitKey(k[len(key):]); ok {
+					root.insert(segs, vs)
+				}
+			}
+			if root.children == nil {
+				continue
+			}
+
+			v, err := buildValue(root, f.typ, s)
+			if err != nil {
+				return bindErrFactory(f.name, err.Error())
+			}
+			var encoded []string
+			if arr, ok := v.([]interface{}); ok {
+				for _, elem := range arr {
+					b, _ := json.Marshal(elem)
+					encoded = append(encoded, string(b))
+				}
+			} else {
+				b, _ := json.Marshal(v)
+				encoded = []string{string(b)}
+			}
+			m[key] = encoded
+		}
+	}
+	return nil
+}
+
+// buildValue converts node into a value which can be unmarshalled into t by encoding/json.
+func buildValue(node *paramNode, t reflect.Type, s Source) (interface{}, error) {
+	t = derefType(t)
+	if node.children == nil || isOpaqueType(t) {
+		return leafValue(node.values, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj := make(map[string]interface{}, len(node.children))
+		if err := buildStruct(node, t, s, obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case reflect.Map:
+		obj := make(map[string]interface{}, len(node.children))
+		for k, child := range node.children {
+			v, err := buildValue(child, t.Elem(), s)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = v
+		}
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		indexes := make([]int, 0, len(node.children))
+		for k := range node.children {
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 {
+				return nil, errors.New("invalid index: " + k)
+			}
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		arr := make([]interface{}, 0, len(indexes))
+		for _, i := range indexes {
+			v, err := buildValue(node.children[strconv.Itoa(i)], t.Elem(), s)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	default:
+		return leafValue(node.values, t)
+	}
+}
+
+func buildStruct(node *paramNode, t reflect.Type, s Source, obj map[string]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		jsonName := sf.Name
+		if value, ok := sf.Tag.Lookup(string(SourceJSON)); ok {
+			if jsonName = tagName(value, sf.Name); jsonName == "-" {
+				continue
+			}
+		} else if sf.Anonymous {
+			if ft := derefType(sf.Type); ft.Kind() == reflect.Struct {
+				if err := buildStruct(node, ft, s, obj); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var child *paramNode
+		for _, name := range []string{sf.Tag.Get(string(s)), sf.Tag.Get(string(SourceJSON)), sf.Name} {
+			if name = tagName(name, ""); name == "" || name == "-" {
+				continue
+			}
+			if c, ok := node.children[name]; ok {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			continue
+		}
+
+		if layout := sf.Tag.Get(tagTimeFormat); layout != "" && child.children == nil {
+			formatted := make([]string, len(child.values))
+			for i, v := range child.values {
+				tm, err := parseTime(v, layout, timeLocationOf(sf.Tag))
+				if err != nil {
+					return err
+				}
+				formatted[i] = tm
+			}
+			child = &paramNode{values: formatted}
+		}
+		v, err := buildValue(child, sf.Type, s)
+		if err != nil {
+			return err
+		}
+		obj[jsonName] = v
+	}
+	return nil
+}
+
+// leafValue converts the raw values into a value matching the kind of t, so that
+// encoding/json can unmarshal it. Values which can not be converted are kept as
+// strings and reported by the binder as type mismatches.
+func leafValue(values []string, t reflect.Type) (interface{}, error) {
+	t = derefType(t)
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		arr := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			elem, err := leafValue([]string{v}, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, elem)
+		}
+		return arr, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	v := values[len(values)-1]
+	if isOpaqueType(t) {
+		return v, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return json.Number(v), nil
+		}
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		if json.Valid([]byte(v)) {
+			return json.RawMessage(v), nil
+		}
+	}
+	return v, nil
+}
+
+// formatTimes converts the values of time fields with time_format tag into RFC3339,
+// which is the format the binder decodes time.Time from.
+func formatTimes(info *typeInfo, values *sourceValues) error {
+	for _, f := range info.timed {
+		for _, s := range sourceTags {
+			key, ok := f.keys[s]
+			if !ok {
+				continue
+			}
+			vals, ok := values.get(s, key)
+			if !ok {
+				continue
+			}
+			formatted := make([]string, len(vals))
+			for i, v := range vals {
+				tm, err := parseTime(v, f.timeFormat, f.timeLocation)
+				if err != nil {
+					return bindErrFactory(f.name, err.Error())
+				}
+				formatted[i] = tm
+			}
+			values.set(s, key, formatted)
+		}
+	}
+	return nil
+}
+
+func timeLocationOf(tag reflect.StructTag) *time.Location {
+	if tag.Get(tagTimeUTC) != "" {
+		return time.UTC
+	}
+	if name := tag.Get(tagTimeLocation); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return nil
+}
+
+// parseTime parses v with layout and formats it in RFC3339.
+// Besides the layouts supported by time.Parse, layout can be one of
+// "unix", "unixmilli", "unixmicro" and "unixnano".
+func parseTime(v, layout string, loc *time.Location) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	var t time.Time
+	switch layout {
+	case "unix", "unixmilli", "unixmicro", "unixnano":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", errors.New("parameter type does not match binding data: " + err.Error())
+		}
+		switch layout {
+		case "unix":
+			t = time.Unix(n, 0)
+		case "unixmilli":
+			t = time.Unix(0, n*int64(time.Millisecond))
+		case "unixmicro":
+			t = time.Unix(0, n*int64(time.Microsecond))
+		default:
+			t = time.Unix(0, n)
+		}
+		if loc != nil {
+			t = t.In(loc)
+		}
+	default:
+		if loc == nil {
+			loc = time.Local
+		}
+		var err error
+		t, err = time.ParseInLocation(layout, v, loc)
+		if err != nil {
+			return "", errors.New("parameter type does not match binding data: " + err.Error())
+		}
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
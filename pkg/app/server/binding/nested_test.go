@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/route/param"
+)
+
+func TestBindNested(t *testing.T) {
+	type Inner struct {
+		C int `query:"c"`
+	}
+	type Outer struct {
+		B     Inner  `query:"b"`
+		Label string `json:"label"`
+	}
+	type Item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	type Test struct {
+		A      Outer             `query:"a"`
+		Filter map[string]string `query:"filter"`
+		Ranges map[string][]int  `query:"range"`
+		Items  []Item            `query:"items"`
+		Raw    map[string]int    `query:"raw"`
+	}
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?a.b.c=1&a[label]=x&filter[name]=hertz&filter.lang=go" +
+		"&range[age]=1&range[age]=2&items[1][name]=b&items[0][name]=a&items[0][count]=3" +
+		"&raw=%7B%22k%22%3A1%7D")
+
+	var req Test
+	err := Bind(r, &req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, 1, req.A.B.C)
+	assert.DeepEqual(t, "x", req.A.Label)
+	assert.DeepEqual(t, map[string]string{"name": "hertz", "lang": "go"}, req.Filter)
+	assert.DeepEqual(t, map[string][]int{"age": {1, 2}}, req.Ranges)
+	assert.DeepEqual(t, []Item{{Name: "a", Count: 3}, {Name: "b"}}, req.Items)
+	assert.DeepEqual(t, map[string]int{"k": 1}, req.Raw)
+
+	r.SetRequestURI("/foo/bar?a.b.c=x")
+	req = Test{}
+	err = Bind(r, &req, nil)
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+
+	r.SetRequestURI("/foo/bar?items[x][name]=a")
+	req = Test{}
+	err = Bind(r, &req, nil)
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+}
+
+func TestBindNestedForm(t *testing.T) {
+	type Test struct {
+		User struct {
+			Name string `form:"name"`
+			Age  int    `form:"age"`
+		} `form:"user"`
+	}
+
+	body := "user[name]=hertz&user[age]=2"
+	r := protocol.NewRequest("POST", "/foo", bytes.NewBufferString(body))
+	r.Header.SetContentTypeBytes([]byte("application/x-www-form-urlencoded"))
+	r.Header.SetContentLength(len(body))
+
+	var req Test
+	err := Bind(r, &req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "hertz", req.User.Name)
+	assert.DeepEqual(t, 2, req.User.Age)
+}
+
+func TestBindTimeFormat(t *testing.T) {
+	type Test struct {
+		Day     time.Time   `query:"day" time_format:"2006-01-02" time_utc:"1"`
+		Days    []time.Time `query:"days" time_format:"2006-01-02" time_location:"Asia/Shanghai"`
+		Created *time.Time  `path:"created" time_format:"unix"`
+		Updated time.Time   `query:"updated"`
+		Period  struct {
+			Start time.Time `query:"start" time_format:"2006-01-02" time_utc:"1"`
+		} `query:"period"`
+	}
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?day=2022-08-01&days=2022-08-02&days=2022-08-03" +
+		"&updated=2022-08-04T01:02:03Z&period[start]=2022-08-05")
+	params := param.Params{{Key: "created", Value: "1659312000"}}
+
+	var req Test
+	err := Bind(r, &req, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), req.Day.UTC())
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	assert.DeepEqual(t, 2, len(req.Days))
+	assert.True(t, req.Days[0].Equal(time.Date(2022, 8, 2, 0, 0, 0, 0, shanghai)))
+	assert.True(t, req.Days[1].Equal(time.Date(2022, 8, 3, 0, 0, 0, 0, shanghai)))
+	assert.True(t, req.Created.Equal(time.Unix(1659312000, 0)))
+	assert.True(t, req.Updated.Equal(time.Date(2022, 8, 4, 1, 2, 3, 0, time.UTC)))
+	assert.True(t, req.Period.Start.Equal(time.Date(2022, 8, 5, 0, 0, 0, 0, time.UTC)))
+	assert.DeepEqual(t, "1659312000", params[0].Value)
+
+	r.SetRequestURI("/foo/bar?day=2022/08/01")
+	req = Test{}
+	err = Bind(r, &req, nil)
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+}
+
+func TestBindArraySyntax(t *testing.T) {
+	type Test struct {
+		Tags   []string         `query:"tags"`
+		IDs    []int            `form:"ids"`
+		Ranges map[string][]int `query:"range"`
+	}
+
+	body := "ids[]=1&ids[]=2"
+	r := protocol.NewRequest("POST", "/foo", strings.NewReader(body))
+	r.SetRequestURI("/foo?tags[]=a&tags[]=b&range[age][]=1&range[age][]=2")
+	r.Header.SetContentLength(len(body))
+	r.Header.SetContentTypeBytes([]byte("application/x-www-form-urlencoded"))
+
+	var req Test
+	err := Bind(r, &req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, []string{"a", "b"}, req.Tags)
+	assert.DeepEqual(t, []int{1, 2}, req.IDs)
+	assert.DeepEqual(t, map[string][]int{"age": {1, 2}}, req.Ranges)
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/route/param"
+)
+
+// Source is a source of request data used for binding.
+type Source string
+
+const (
+	SourcePath   Source = "path"
+	SourceForm   Source = "form"
+	SourceQuery  Source = "query"
+	SourceCookie Source = "cookie"
+	SourceHeader Source = "header"
+	SourceJSON   Source = "json"
+)
+
+// defaultPrecedence is the built-in order in which the sources of a field are tried.
+var defaultPrecedence = []Source{SourcePath, SourceForm, SourceQuery, SourceCookie, SourceHeader, SourceJSON}
+
+// BindConfig customizes binding, mostly how the values of a field are resolved
+// when the field can be bound from more than one source, e.g.
+//
+//	ID string `path:"id" query:"id" header:"X-Id"`
+//
+// or a field without any source tag, which is bound from all sources by its name.
+type BindConfig struct {
+	// Precedence lists the sources from the highest priority to the lowest.
+	// The sources which are not listed keep the built-in order after the listed ones.
+	// The built-in order is path, form, query, cookie, header and json.
+	Precedence []Source
+
+	// MergeSlices combines the values from all sources in order of precedence
+	// for slice fields, instead of taking the values of the first source.
+	// NOTE:
+	//
+	//	Path parameters and json body can not be the destination of merged values,
+	//	so the field must have at least one query, form, cookie or header source.
+	MergeSlices bool
+
+	// FailOnConflict makes binding fail if the sources of a field carry different values.
+	FailOnConflict bool
+
+	// DisallowUnknownFields makes binding fail if the json or form body contains
+	// fields which do not exist in the receiver.
+	DisallowUnknownFields bool
+}
+
+var defaultBindConfig *BindConfig
+
+// SetBindConfig sets the BindConfig used by Bind and BindAndValidate.
+// NOTE:
+//
+//	If config==nil, the built-in order is used and conflicts are ignored.
+//	SetBindConfig will remain in effect once it has been called.
+func SetBindConfig(config *BindConfig) {
+	defaultBindConfig = config
+}
+
+func (c *BindConfig) precedence() []Source {
+	if len(c.Precedence) == 0 {
+		return defaultPrecedence
+	}
+	seen := make(map[Source]bool, len(defaultPrecedence))
+	ret := make([]Source, 0, len(defaultPrecedence))
+	for _, s := range append(append([]Source{}, c.Precedence...), defaultPrecedence...) {
+		if !seen[s] {
+			seen[s] = true
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// sourceValues holds a snapshot of the request data, which can be
+// adjusted before being handed to the binder.
+type sourceValues struct {
+	path    param.Params
+	query   url.Values
+	form    url.Values
+	header  http.Header
+	cookies []*http.Cookie
+	json    interface{}
+}
+
+func (v *sourceValues) get(s Source, key string) ([]string, bool) {
+	switch s {
+	case SourcePath:
+		if val, ok := v.path.Get(key); ok {
+			return []string{val}, true
+		}
+	case SourceQuery:
+		vals, ok := v.query[key]
+		return vals, ok && len(vals) > 0
+	case SourceForm:
+		vals, ok := v.form[key]
+		return vals, ok && len(vals) > 0
+	case SourceHeader:
+		vals, ok := v.header[key]
+		return vals, ok && len(vals) > 0
+	case SourceCookie:
+		var vals []string
+		for _, c := range v.cookies {
+			if c.Name == key {
+				vals = append(vals, c.Value)
+			}
+		}
+		return vals, len(vals) > 0
+	case SourceJSON:
+		return lookupJSON(v.json, key)
+	}
+	return nil, false
+}
+
+func (v *sourceValues) set(s Source, key string, vals []string) {
+	switch s {
+	case SourcePath:
+		if len(vals) == 0 {
+			v.del(s, key)
+			return
+		}
+		ps := make(param.Params, len(v.path))
+		copy(ps, v.path)
+		for i := range ps {
+			if ps[i].Key == key {
+				ps[i].Value = vals[0]
+			}
+		}
+		v.path = ps
+	case SourceQuery:
+		v.query[key] = vals
+	case SourceForm:
+		v.form[key] = vals
+	case SourceHeader:
+		v.header[key] = vals
+	case SourceCookie:
+		v.del(s, key)
+		for _, val := range vals {
+			v.cookies = append(v.cookies, &http.Cookie{Name: key, Value: val})
+		}
+	}
+}
+
+func (v *sourceValues) del(s Source, key string) {
+	switch s {
+	case SourcePath:
+		ps := v.path[:0:0]
+		for _, p := range v.path {
+			if p.Key != key {
+				ps = append(ps, p)
+			}
+		}
+		v.path = ps
+	case SourceQuery:
+		delete(v.query, key)
+	case SourceForm:
+		delete(v.form, key)
+	case SourceHeader:
+		delete(v.header, key)
+	case SourceCookie:
+		cs := v.cookies[:0:0]
+		for _, c := range v.cookies {
+			if c.Name != key {
+				cs = append(cs, c)
+			}
+		}
+		v.cookies = cs
+	}
+}
+
+func lookupJSON(data interface{}, path string) ([]string, bool) {
+	if data == nil {
+		return nil, false
+	}
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if data, ok = m[seg]; !ok {
+			return nil, false
+		}
+	}
+	if arr, ok := data.([]interface{}); ok {
+		vals := make([]string, 0, len(arr))
+		for _, elem := range arr {
+			vals = append(vals, jsonString(elem))
+		}
+		return vals, true
+	}
+	return []string{jsonString(data)}, true
+}
+
+func jsonString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// applyPrecedence resolves the sources of the fields which can be bound from more than one source.
+func applyPrecedence(info *typeInfo, values *sourceValues, config *BindConfig) error {
+	precedence := config.precedence()
+	for _, f := range info.multiSource {
+		var present []Source
+		var presentValues [][]string
+		for _, s := range precedence {
+			key, ok := f.keys[s]
+			if !ok {
+				continue
+			}
+			if vals, ok := values.get(s, key); ok {
+				present = append(present, s)
+				presentValues = append(presentValues, vals)
+			}
+		}
+		if len(present) < 2 {
+			continue
+		}
+
+		if config.FailOnConflict {
+			for i := 1; i < len(present); i++ {
+				if !equalStrings(presentValues[0], presentValues[i]) {
+					return bindErrFactory(f.name, "conflicting values between "+
+						string(present[0])+" and "+string(present[i]))
+				}
+			}
+		}
+
+		winner := present[0]
+		if config.MergeSlices && f.isSlice {
+			for _, s := range present {
+				if s != SourcePath && s != SourceJSON {
+					winner = s
+					break
+				}
+			}
+			if winner != SourcePath && winner != SourceJSON {
+				var merged []string
+				for _, vals := range presentValues {
+					merged = append(merged, vals...)
+				}
+				values.set(winner, f.keys[winner], merged)
+			}
+		}
+		for _, s := range present {
+			if s != winner {
+				values.del(s, f.keys[s])
+			}
+		}
+	}
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
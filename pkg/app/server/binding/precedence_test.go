@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/route/param"
+)
+
+func TestBindConfigPrecedence(t *testing.T) {
+	type Test struct {
+		ID   string `path:"id" query:"id" header:"X-Id"`
+		Name string
+		Tags []string `query:"tag" header:"tag"`
+	}
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?id=query&Name=query&tag=a&tag=b")
+	r.SetHeader("X-Id", "header")
+	r.SetHeader("Name", "header")
+	r.SetHeader("tag", "c")
+	params := param.Params{{Key: "id", Value: "path"}}
+
+	var req Test
+	err := Bind(r, &req, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "path", req.ID)
+	assert.DeepEqual(t, "query", req.Name)
+	assert.DeepEqual(t, []string{"a", "b"}, req.Tags)
+
+	req = Test{}
+	err = BindWithConfig(r, &req, params, &BindConfig{Precedence: []Source{SourceHeader, SourceQuery}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "header", req.ID)
+	assert.DeepEqual(t, "header", req.Name)
+	assert.DeepEqual(t, []string{"c"}, req.Tags)
+	// the path parameters of the caller are left untouched
+	assert.DeepEqual(t, param.Params{{Key: "id", Value: "path"}}, params)
+
+	req = Test{}
+	err = BindWithConfig(r, &req, params, &BindConfig{Precedence: []Source{SourceHeader}, MergeSlices: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, []string{"c", "a", "b"}, req.Tags)
+
+	SetBindConfig(&BindConfig{Precedence: []Source{SourceQuery}})
+	defer SetBindConfig(nil)
+	req = Test{}
+	err = Bind(r, &req, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "query", req.ID)
+}
+
+func TestBindConfigFailOnConflict(t *testing.T) {
+	SetErrorFactory(nil, nil)
+
+	type Test struct {
+		ID string `query:"id" json:"id"`
+	}
+
+	body := `{"id":"json"}`
+	r := protocol.NewRequest("POST", "/foo", bytes.NewBufferString(body))
+	r.SetRequestURI("/foo/bar?id=json")
+	r.Header.SetContentTypeBytes([]byte("application/json"))
+	r.Header.SetContentLength(len(body))
+	config := &BindConfig{Precedence: []Source{SourceJSON}, FailOnConflict: true}
+
+	var req Test
+	err := BindAndValidateWithConfig(r, &req, nil, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "json", req.ID)
+
+	r.SetRequestURI("/foo/bar?id=query")
+	req = Test{}
+	err = BindAndValidateWithConfig(r, &req, nil, config)
+	if err == nil {
+		t.Fatalf("unexpected nil, expected an error")
+	}
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.DeepEqual(t, ErrTypeBinding, e.ErrType)
+	assert.DeepEqual(t, "ID", e.FailField)
+	assert.DeepEqual(t, "conflicting values between json and query", e.Msg)
+
+	config.FailOnConflict = false
+	req = Test{}
+	err = BindAndValidateWithConfig(r, &req, nil, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, "json", req.ID)
+}
@@ -20,6 +20,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/bytedance/go-tagexpr/v2/binding"
 	"github.com/cloudwego/hertz/internal/bytesconv"
@@ -35,9 +36,25 @@ func wrapRequest(req *protocol.Request) binding.Request {
 
 type bindRequest struct {
 	req *protocol.Request
+
+	// resolved means the values below are adjusted by BindConfig,
+	// and should be used instead of the ones in req.
+	resolved bool
+	query    url.Values
+	form     url.Values
+	header   http.Header
+	cookies  []*http.Cookie
+	files    map[string][]*multipart.FileHeader
+
+	// skipBody means the body must not be bound by the binder,
+	// e.g. it has been decoded by a registered BodyDecoder.
+	skipBody bool
 }
 
 func (r *bindRequest) GetQuery() url.Values {
+	if r.resolved {
+		return r.query
+	}
 	queryMap := make(url.Values)
 	r.req.URI().QueryArgs().VisitAll(func(key, value []byte) {
 		keyStr := string(key)
@@ -46,10 +63,13 @@ func (r *bindRequest) GetQuery() url.Values {
 		queryMap[keyStr] = values
 	})
 
-	return queryMap
+	return foldArrayKeys(queryMap)
 }
 
 func (r *bindRequest) GetPostForm() (url.Values, error) {
+	if r.resolved {
+		return r.form, nil
+	}
 	postMap := make(url.Values)
 	r.req.PostArgs().VisitAll(func(key, value []byte) {
 		keyStr := string(key)
@@ -66,7 +86,7 @@ func (r *bindRequest) GetPostForm() (url.Values, error) {
 		}
 	}
 
-	return postMap, nil
+	return foldArrayKeys(postMap), nil
 }
 
 func (r *bindRequest) GetForm() (url.Values, error) {
@@ -88,6 +108,9 @@ func (r *bindRequest) GetForm() (url.Values, error) {
 }
 
 func (r *bindRequest) GetCookies() []*http.Cookie {
+	if r.resolved {
+		return r.cookies
+	}
 	var cookies []*http.Cookie
 	r.req.Header.VisitAllCookie(func(key, value []byte) {
 		cookies = append(cookies, &http.Cookie{
@@ -100,6 +123,9 @@ func (r *bindRequest) GetCookies() []*http.Cookie {
 }
 
 func (r *bindRequest) GetHeader() http.Header {
+	if r.resolved {
+		return r.header
+	}
 	header := make(http.Header)
 	r.req.Header.VisitAll(func(key, value []byte) {
 		keyStr := string(key)
@@ -116,14 +142,28 @@ func (r *bindRequest) GetMethod() string {
 }
 
 func (r *bindRequest) GetContentType() string {
+	if r.skipBody {
+		if ct := contentType(r.req); ct == "application/x-www-form-urlencoded" || ct == "multipart/form-data" {
+			return bytesconv.B2s(r.req.Header.ContentType())
+		}
+		// the binder treats the fields of protobuf bodies as bound,
+		// which is the case of a body decoded in advance or ignored.
+		return "application/x-protobuf"
+	}
 	return bytesconv.B2s(r.req.Header.ContentType())
 }
 
 func (r *bindRequest) GetBody() ([]byte, error) {
+	if r.skipBody {
+		return nil, nil
+	}
 	return r.req.Body(), nil
 }
 
 func (r *bindRequest) GetFileHeaders() (map[string][]*multipart.FileHeader, error) {
+	if r.resolved {
+		return r.files, nil
+	}
 	files := make(map[string][]*multipart.FileHeader)
 	mf, err := r.req.MultipartForm()
 	if err == nil {
@@ -136,3 +176,41 @@ func (r *bindRequest) GetFileHeaders() (map[string][]*multipart.FileHeader, erro
 
 	return files, nil
 }
+
+// foldArrayKeys appends the values of keys in array syntax like "key[]" to "key",
+// so that they can be bound to the fields of "key".
+func foldArrayKeys(values url.Values) url.Values {
+	var keys []string
+	for k := range values {
+		if len(k) > 2 && strings.HasSuffix(k, "[]") {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		name := k[:len(k)-2]
+		values[name] = append(values[name], values[k]...)
+	}
+	return values
+}
+
+// keepSources resolves the request data, and drops the data of the sources not in sources.
+func (r *bindRequest) keepSources(sources map[Source]bool) {
+	query, header, cookies := r.GetQuery(), r.GetHeader(), r.GetCookies()
+	form, _ := r.GetPostForm()
+	files, _ := r.GetFileHeaders()
+
+	r.query, r.form, r.header, r.cookies, r.files = make(url.Values), make(url.Values), make(http.Header), nil, nil
+	if sources[SourceQuery] {
+		r.query = query
+	}
+	if sources[SourceForm] {
+		r.form, r.files = form, files
+	}
+	if sources[SourceHeader] {
+		r.header = header
+	}
+	if sources[SourceCookie] {
+		r.cookies = cookies
+	}
+	r.resolved = true
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkUnknownFields reports the first field of the json or form body
+// which can not be bound to t.
+func checkUnknownFields(r *bindRequest, t reflect.Type, info *typeInfo, values *sourceValues) error {
+	if values.json != nil {
+		if field, ok := unknownJSONField(values.json, t, ""); ok {
+			return bindErrFactory(field, "unknown field")
+		}
+	}
+	for key := range values.form {
+		if !isKnownFormKey(key, info) {
+			return bindErrFactory(key, "unknown field")
+		}
+	}
+	files, _ := r.GetFileHeaders()
+	for key := range files {
+		if !isKnownFormKey(key, info) {
+			return bindErrFactory(key, "unknown field")
+		}
+	}
+	return nil
+}
+
+// isKnownFormKey reports whether key is the form key of a field in plain or array syntax,
+// or a key like "a.b" or "a[b]" of a nested field.
+func isKnownFormKey(key string, info *typeInfo) bool {
+	key = strings.TrimSuffix(key, "[]")
+	if info.formKeys[key] {
+		return true
+	}
+	idx := strings.IndexAny(key, ".[")
+	if idx <= 0 {
+		return false
+	}
+	for _, f := range info.nested {
+		if f.keys[SourceForm] == key[:idx] {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownJSONField walks data as encoding/json does when unmarshalling it into t,
+// and returns the path of the first key which does not match any field.
+func unknownJSONField(data interface{}, t reflect.Type, path string) (string, bool) {
+	t = derefType(t)
+	if isOpaqueType(t) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return "", false
+	}
+	switch x := data.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for k, v := range x {
+				ft, ok := fields[k]
+				if !ok {
+					ft, ok = fields[strings.ToLower(k)]
+				}
+				if !ok {
+					return joinJSONPath(path, k), true
+				}
+				if field, ok := unknownJSONField(v, ft, joinJSONPath(path, k)); ok {
+					return field, true
+				}
+			}
+		case reflect.Map:
+			for k, v := range x {
+				if field, ok := unknownJSONField(v, t.Elem(), joinJSONPath(path, k)); ok {
+					return field, true
+				}
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, v := range x {
+				if field, ok := unknownJSONField(v, t.Elem(), path); ok {
+					return field, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonFields returns the types of the fields of struct t by json names,
+// the lower-cased names are included for case-insensitive matching.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup(string(SourceJSON))
+		if sf.Anonymous && !tagged {
+			if ft := derefType(sf.Type); ft.Kind() == reflect.Struct {
+				for name, typ := range jsonFields(ft) {
+					if _, ok := fields[name]; !ok {
+						fields[name] = typ
+					}
+				}
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := tagName(tag, sf.Name)
+		if name == "-" && !strings.HasPrefix(tag, "-,") {
+			continue
+		}
+		fields[name] = sf.Type
+		if lower := strings.ToLower(name); lower != name {
+			if _, ok := fields[lower]; !ok {
+				fields[lower] = sf.Type
+			}
+		}
+	}
+	return fields
+}
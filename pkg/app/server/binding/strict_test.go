@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestDisallowUnknownFieldsJSON(t *testing.T) {
+	SetErrorFactory(nil, nil)
+	type Inner struct {
+		C int `json:"c"`
+	}
+	type Embedded struct {
+		E string `json:"e"`
+	}
+	type Test struct {
+		Embedded
+		A     string            `json:"a"`
+		Inner Inner             `json:"inner"`
+		List  []Inner           `json:"list"`
+		Extra map[string]string `json:"extra"`
+		Any   interface{}       `json:"any"`
+	}
+	newRequest := func(body string) *protocol.Request {
+		r := protocol.NewRequest("POST", "/foo", strings.NewReader(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte("application/json"))
+		return r
+	}
+	config := &BindConfig{DisallowUnknownFields: true}
+
+	body := `{"e":"e","A":"a","inner":{"c":1},"list":[{"c":2}],"extra":{"x":"y"},"any":{"z":1}}`
+	var req Test
+	err := BindWithConfig(newRequest(body), &req, nil, config)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "e", req.E)
+	assert.DeepEqual(t, "a", req.A)
+	assert.DeepEqual(t, 2, req.List[0].C)
+
+	// unknown fields are ignored by default
+	err = Bind(newRequest(`{"a":"a","b":"b"}`), &req, nil)
+	assert.Nil(t, err)
+
+	for body, field := range map[string]string{
+		`{"a":"a","b":"b"}`:          "b",
+		`{"inner":{"c":1,"d":2}}`:    "inner.d",
+		`{"list":[{"c":1},{"d":2}]}`: "list.d",
+	} {
+		req = Test{}
+		err = BindWithConfig(newRequest(body), &req, nil, config)
+		assert.NotNil(t, err)
+		assert.DeepEqual(t, field, err.(*Error).FailField)
+		assert.DeepEqual(t, "unknown field", err.(*Error).Msg)
+	}
+}
+
+func TestDisallowUnknownFieldsForm(t *testing.T) {
+	SetErrorFactory(nil, nil)
+	type Inner struct {
+		C int `form:"c"`
+	}
+	type Test struct {
+		A     string `form:"a"`
+		Inner Inner  `form:"inner"`
+		Q     string `query:"q"`
+	}
+	newRequest := func(body string) *protocol.Request {
+		r := protocol.NewRequest("POST", "/foo?q=q&x=x", strings.NewReader(body))
+		r.Header.SetContentLength(len(body))
+		r.Header.SetContentTypeBytes([]byte("application/x-www-form-urlencoded"))
+		return r
+	}
+	config := &BindConfig{DisallowUnknownFields: true}
+
+	var req Test
+	err := BindWithConfig(newRequest("a=a&inner.c=1&c=2"), &req, nil, config)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "a", req.A)
+	assert.DeepEqual(t, "q", req.Q)
+
+	req = Test{}
+	err = BindWithConfig(newRequest("a=a&b=b"), &req, nil, config)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "b", err.(*Error).FailField)
+
+	req = Test{}
+	err = BindWithConfig(newRequest("a=a&q=q"), &req, nil, config)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "q", err.(*Error).FailField)
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/go-tagexpr/v2/binding"
+)
+
+const (
+	// ErrTypeBinding is the ErrType of the errors returned when binding fails.
+	ErrTypeBinding = "binding"
+	// ErrTypeValidating is the ErrType of the errors returned when validating fails.
+	ErrTypeValidating = "validating"
+)
+
+// Error is the default error type returned by Bind, BindAndValidate and Validate,
+// unless the error factory is customized by SetErrorFactory.
+type Error = binding.Error
+
+// LocalizedError is the error produced by Translator with the default formatter.
+type LocalizedError struct {
+	// Locale is the locale which the message is rendered in, empty if no translation is found.
+	Locale string
+	// ErrType is ErrTypeBinding or ErrTypeValidating.
+	ErrType string
+	// Field is the display name of the failed field.
+	Field string
+	// Message is the user-facing message.
+	Message string
+	// Cause is the original error.
+	Cause *Error
+}
+
+// Error implements error interface.
+func (e *LocalizedError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the original error.
+func (e *LocalizedError) Unwrap() error {
+	return e.Cause
+}
+
+// FormatFunc builds the final error from the translated message,
+// it is used to customize the error payload returned to users.
+type FormatFunc func(locale, field, message string, cause *Error) error
+
+// Translator translates the errors of binding and validating into user-facing messages
+// in multiple languages.
+//
+// Templates are looked up by the original message first (e.g. "missing required parameter"
+// or the message set by the msg expression of vd tag), then by the ErrType.
+// The placeholders {field} and {msg} in templates are replaced with the display name
+// of the failed field and the original message respectively.
+type Translator struct {
+	mu             sync.RWMutex
+	fallbackLocale string
+	fieldNames     map[string]map[string]string
+	templates      map[string]map[string]string
+	format         FormatFunc
+}
+
+// NewTranslator creates a Translator, fallbackLocale is used when none of the
+// requested locales is registered.
+func NewTranslator(fallbackLocale string) *Translator {
+	return &Translator{
+		fallbackLocale: fallbackLocale,
+		fieldNames:     make(map[string]map[string]string),
+		templates:      make(map[string]map[string]string),
+		format:         defaultFormat,
+	}
+}
+
+// RegisterFieldName maps the field path reported by binding (e.g. "user.name") to
+// a display name in the given locale.
+func (t *Translator) RegisterFieldName(locale, field, name string) *Translator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	register(t.fieldNames, locale, field, name)
+	return t
+}
+
+// RegisterTemplate registers a message template in the given locale, key is either
+// an original message or one of ErrTypeBinding and ErrTypeValidating.
+func (t *Translator) RegisterTemplate(locale, key, template string) *Translator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	register(t.templates, locale, key, template)
+	return t
+}
+
+// SetFormatter customizes how the final error is built.
+// NOTE:
+//
+//	If fn==nil, the default is used, which returns *LocalizedError.
+func (t *Translator) SetFormatter(fn FormatFunc) *Translator {
+	if fn == nil {
+		fn = defaultFormat
+	}
+	t.mu.Lock()
+	t.format = fn
+	t.mu.Unlock()
+	return t
+}
+
+// Translate translates err in the first matched locale of locales, which are in order of preference.
+// A locale such as "zh-CN" also matches templates registered with its base language "zh".
+// NOTE:
+//
+//	err is returned as is if it is nil or not *Error, e.g. it is built by a customized error factory.
+func (t *Translator) Translate(err error, locales ...string) error {
+	var e *Error
+	if err == nil || !errors.As(err, &e) {
+		return err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	locale, template := t.lookupTemplate(e, locales)
+	field := e.FailField
+	if locale != "" {
+		if name, ok := t.fieldNames[locale][e.FailField]; ok {
+			field = name
+		}
+	}
+	msg := e.Msg
+	if template != "" {
+		msg = strings.NewReplacer("{field}", field, "{msg}", e.Msg).Replace(template)
+	} else if msg == "" {
+		msg = e.Error()
+	}
+	return t.format(locale, field, msg, e)
+}
+
+func (t *Translator) lookupTemplate(e *Error, locales []string) (string, string) {
+	candidates := make([]string, 0, 2*len(locales)+1)
+	for _, l := range locales {
+		candidates = append(candidates, l)
+		if i := strings.IndexAny(l, "-_"); i > 0 {
+			candidates = append(candidates, l[:i])
+		}
+	}
+	candidates = append(candidates, t.fallbackLocale)
+
+	for _, l := range candidates {
+		templates, ok := t.templates[l]
+		if !ok {
+			continue
+		}
+		if template, ok := templates[e.Msg]; ok && e.Msg != "" {
+			return l, template
+		}
+		if template, ok := templates[e.ErrType]; ok {
+			return l, template
+		}
+	}
+	return "", ""
+}
+
+func register(m map[string]map[string]string, locale, key, value string) {
+	kv, ok := m[locale]
+	if !ok {
+		kv = make(map[string]string)
+		m[locale] = kv
+	}
+	kv[key] = value
+}
+
+func defaultFormat(locale, field, message string, cause *Error) error {
+	return &LocalizedError{
+		Locale:  locale,
+		ErrType: cause.ErrType,
+		Field:   field,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// ParseAcceptLanguage parses the value of Accept-Language header into locales
+// in order of preference, which can be passed to Translator.Translate.
+func ParseAcceptLanguage(value string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var list []weighted
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		q := 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			param := strings.TrimSpace(part[i+1:])
+			part = strings.TrimSpace(part[:i])
+			if strings.HasPrefix(param, "q=") {
+				if f, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if part == "*" || q <= 0 {
+			continue
+		}
+		list = append(list, weighted{locale: part, q: q})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].q > list[j].q
+	})
+	locales := make([]string, len(list))
+	for i, w := range list {
+		locales[i] = w.locale
+	}
+	return locales
+}
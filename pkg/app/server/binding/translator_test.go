@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestTranslator(t *testing.T) {
+	SetErrorFactory(nil, nil)
+
+	type TestTranslate struct {
+		Name string `query:"name,required"`
+		Age  int    `query:"age" vd:"$>0"`
+	}
+
+	tr := NewTranslator("en").
+		RegisterTemplate("en", "missing required parameter", "{field} is required").
+		RegisterTemplate("en", ErrTypeValidating, "{field} is invalid").
+		RegisterTemplate("zh", "missing required parameter", "缺少参数{field}").
+		RegisterFieldName("zh", "Name", "名称")
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?age=0")
+
+	var req TestTranslate
+	err := BindAndValidate(r, &req, nil)
+	assert.NotNil(t, err)
+
+	zhErr := tr.Translate(err, "zh-CN")
+	assert.DeepEqual(t, "缺少参数名称", zhErr.Error())
+	var le *LocalizedError
+	assert.True(t, errors.As(zhErr, &le))
+	assert.DeepEqual(t, "zh", le.Locale)
+	assert.DeepEqual(t, ErrTypeBinding, le.ErrType)
+	var e *Error
+	assert.True(t, errors.As(zhErr, &e))
+
+	assert.DeepEqual(t, "Name is required", tr.Translate(err, "fr").Error())
+
+	r.SetRequestURI("/foo/bar?name=hertz&age=0")
+	req = TestTranslate{}
+	err = BindAndValidate(r, &req, nil)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "Age is invalid", tr.Translate(err, "zh").Error())
+
+	tr.SetFormatter(func(locale, field, message string, cause *Error) error {
+		return fmt.Errorf("%s|%s|%s", locale, field, message)
+	})
+	assert.DeepEqual(t, "en|Age|Age is invalid", tr.Translate(err).Error())
+
+	assert.Nil(t, tr.Translate(nil, "en"))
+	plain := errors.New("plain")
+	assert.DeepEqual(t, plain, tr.Translate(plain, "en"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.DeepEqual(t, []string{"zh-CN", "zh", "en"}, ParseAcceptLanguage("en;q=0.5, zh-CN,zh;q=0.9, *;q=0.1"))
+	assert.DeepEqual(t, []string{}, ParseAcceptLanguage(""))
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+// StructValidator validates the value bound by BindAndValidate.
+//
+// For example, go-playground/validator can be plugged in by:
+//
+//	type playgroundValidator struct{ v *validator.Validate }
+//
+//	func (p *playgroundValidator) ValidateStruct(obj interface{}) error {
+//		return p.v.Struct(obj)
+//	}
+//
+//	binding.SetValidator(binding.ChainValidators(
+//		binding.DefaultValidator(),
+//		&playgroundValidator{v: validator.New()},
+//	))
+type StructValidator interface {
+	// ValidateStruct validates obj, which is the pointer passed to BindAndValidate or Validate.
+	ValidateStruct(obj interface{}) error
+}
+
+// StructValidatorFunc is an adapter to allow the use of ordinary functions as StructValidator.
+type StructValidatorFunc func(obj interface{}) error
+
+// ValidateStruct calls f(obj).
+func (f StructValidatorFunc) ValidateStruct(obj interface{}) error {
+	return f(obj)
+}
+
+type vdValidator struct{}
+
+func (vdValidator) ValidateStruct(obj interface{}) error {
+	return defaultBinder.Validate(obj)
+}
+
+// DefaultValidator returns the built-in validator, which validates fields with "vd" tag.
+// It is useful to keep "vd" tag working when wrapping or chaining validators.
+func DefaultValidator() StructValidator {
+	return vdValidator{}
+}
+
+type validatorChain []StructValidator
+
+func (c validatorChain) ValidateStruct(obj interface{}) error {
+	for _, v := range c {
+		if err := v.ValidateStruct(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChainValidators returns a StructValidator which runs validators in order,
+// and returns the first error.
+func ChainValidators(validators ...StructValidator) StructValidator {
+	return validatorChain(validators)
+}
+
+var customValidator StructValidator
+
+// SetValidator replaces the validator used by BindAndValidate and Validate.
+// NOTE:
+//
+//	If v==nil, the built-in validator is used.
+//	SetValidator will remain in effect once it has been called.
+func SetValidator(v StructValidator) {
+	if _, ok := v.(vdValidator); ok {
+		v = nil
+	}
+	customValidator = v
+}
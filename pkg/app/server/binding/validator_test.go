@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package binding
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestSetValidator(t *testing.T) {
+	type Test struct {
+		A int    `query:"a" vd:"$>0"`
+		B string `query:"b"`
+	}
+
+	errEmptyB := errors.New("b is empty")
+	custom := StructValidatorFunc(func(obj interface{}) error {
+		if obj.(*Test).B == "" {
+			return errEmptyB
+		}
+		return nil
+	})
+
+	r := protocol.NewRequest("GET", "/foo", nil)
+	r.SetRequestURI("/foo/bar?a=1")
+
+	SetValidator(custom)
+	defer SetValidator(nil)
+	var req Test
+	err := BindAndValidate(r, &req, nil)
+	assert.DeepEqual(t, errEmptyB, err)
+	assert.DeepEqual(t, errEmptyB, Validate(&req))
+
+	r.SetRequestURI("/foo/bar?a=0&b=1")
+	req = Test{}
+	err = BindAndValidate(r, &req, nil)
+	assert.Nil(t, err)
+
+	SetValidator(ChainValidators(DefaultValidator(), custom))
+	req = Test{}
+	err = BindAndValidate(r, &req, nil)
+	assert.NotNil(t, err)
+	assert.NotEqual(t, errEmptyB, err)
+
+	r.SetRequestURI("/foo/bar?a=1")
+	req = Test{}
+	err = BindAndValidate(r, &req, nil)
+	assert.DeepEqual(t, errEmptyB, err)
+
+	SetValidator(DefaultValidator())
+	assert.Nil(t, customValidator)
+	req = Test{}
+	err = BindAndValidate(r, &req, nil)
+	assert.Nil(t, err)
+}
@@ -17,6 +17,7 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -39,6 +40,7 @@ import (
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/common/tracer/slowlog"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/network/standard"
@@ -143,6 +145,69 @@ func TestHertz_GracefulShutdown(t *testing.T) {
 	cancel()
 }
 
+func TestHertz_ShutdownTimeouts(t *testing.T) {
+	engine := New(WithHostPorts("127.0.0.1:9241"), WithTransport(standard.NewTransporter),
+		WithExitWaitTime(3*time.Second), WithShutdownTimeouts(300*time.Millisecond, 0, 600*time.Millisecond))
+	engine.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetBodyString("pong")
+	})
+	engine.GET("/slow", func(c context.Context, ctx *app.RequestContext) {
+		time.Sleep(3 * time.Second)
+	})
+	go engine.Spin()
+	time.Sleep(100 * time.Millisecond)
+
+	get := func(conn net.Conn, path string) (*http.Response, error) {
+		if _, err := conn.Write([]byte("GET " + path + " HTTP/1.1\r\nHost: foo.com\r\n\r\n")); err != nil {
+			return nil, err
+		}
+		return http.ReadResponse(bufio.NewReader(conn), nil)
+	}
+
+	idleConn, err := net.Dial("tcp", "127.0.0.1:9241")
+	assert.Nil(t, err)
+	defer idleConn.Close()
+	resp, err := get(idleConn, "/ping")
+	assert.Nil(t, err)
+	assert.False(t, resp.Close)
+
+	slowConn, err := net.Dial("tcp", "127.0.0.1:9241")
+	assert.Nil(t, err)
+	defer slowConn.Close()
+	_, err = slowConn.Write([]byte("GET /slow HTTP/1.1\r\nHost: foo.com\r\n\r\n"))
+	assert.Nil(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	go engine.Shutdown(ctx) //nolint:errcheck
+
+	// the idle connection is closed as soon as shutdown begins
+	idleConn.SetReadDeadline(time.Now().Add(time.Second)) //nolint:errcheck
+	_, err = idleConn.Read(make([]byte, 1))
+	assert.DeepEqual(t, io.EOF, err)
+
+	// new connections are still accepted, and closed after the response
+	newConn, err := net.Dial("tcp", "127.0.0.1:9241")
+	assert.Nil(t, err)
+	defer newConn.Close()
+	resp, err = get(newConn, "/ping")
+	assert.Nil(t, err)
+	assert.True(t, resp.Close)
+
+	// the connection with the request in flight is closed by the request deadline
+	slowConn.SetReadDeadline(time.Now().Add(2 * time.Second)) //nolint:errcheck
+	_, err = slowConn.Read(make([]byte, 1))
+	assert.DeepEqual(t, io.EOF, err)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 600*time.Millisecond && elapsed < 2*time.Second)
+
+	// new connections are refused after the accept deadline
+	_, err = net.Dial("tcp", "127.0.0.1:9241")
+	assert.NotNil(t, err)
+}
+
 func TestLoadHTMLGlob(t *testing.T) {
 	engine := New(WithMaxRequestBodySize(15), WithHostPorts("127.0.0.1:8890"))
 	engine.Delims("{[{", "}]}")
@@ -683,6 +748,34 @@ func TestReuseCtx(t *testing.T) {
 	}
 }
 
+func TestSlowRequestThreshold(t *testing.T) {
+	infos := make(chan *slowlog.Info, 2)
+	h := New(WithHostPorts("localhost:9232"), WithSlowRequestThreshold(50*time.Millisecond, func(ctx context.Context, info *slowlog.Info) {
+		infos <- info
+	}))
+	h.GET("/fast", func(ctx context.Context, c *app.RequestContext) {})
+	h.GET("/slow/:id", func(ctx context.Context, c *app.RequestContext) {
+		time.Sleep(100 * time.Millisecond)
+		c.String(consts.StatusAccepted, "slow")
+	})
+
+	go h.Spin()
+	time.Sleep(time.Second)
+	_, _, err := c.Get(context.Background(), nil, "http://127.0.0.1:9232/fast")
+	assert.Nil(t, err)
+	_, _, err = c.Get(context.Background(), nil, "http://127.0.0.1:9232/slow/1")
+	assert.Nil(t, err)
+
+	info := <-infos
+	assert.DeepEqual(t, "/slow/1", info.Path)
+	assert.DeepEqual(t, "/slow/:id", info.Route)
+	assert.DeepEqual(t, consts.StatusAccepted, info.StatusCode)
+	assert.True(t, info.Latency >= 100*time.Millisecond)
+	assert.True(t, info.Handle >= 100*time.Millisecond)
+	assert.True(t, info.Handle <= info.Latency)
+	assert.DeepEqual(t, 0, len(infos))
+}
+
 type CloseWithoutResetBuffer interface {
 	CloseNoResetBuffer() error
 }
@@ -20,6 +20,7 @@ package server
 
 import (
 	"context"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
@@ -32,8 +33,11 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/app"
 	c "github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/netpoll"
 	"github.com/cloudwego/hertz/pkg/network/standard"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"golang.org/x/sys/unix"
@@ -134,3 +138,68 @@ func TestHertz_Spin(t *testing.T) {
 
 	<-ch2
 }
+
+func TestConnStats(t *testing.T) {
+	transporters := []func(*config.Options) network.Transporter{standard.NewTransporter, netpoll.NewTransporter}
+	for i, transporter := range transporters {
+		addr := "127.0.0.1:" + strconv.Itoa(9239+i)
+		closed := make(chan [2]int64, 1)
+		h := New(WithHostPorts(addr), WithTransport(transporter),
+			WithOnClose(func(ctx context.Context, conn network.Conn) {
+				s := network.ConnStatsFromContext(ctx)
+				closed <- [2]int64{s.BytesRead(), s.BytesWritten()}
+			}))
+		var readInHandler int64
+		h.POST("/echo", func(ctx context.Context, c *app.RequestContext) {
+			atomic.StoreInt64(&readInHandler, network.ConnStatsFromContext(ctx).BytesRead())
+			c.Write(c.Request.Body()) //nolint:errcheck
+		})
+		go h.Spin()
+		time.Sleep(500 * time.Millisecond)
+
+		conn, err := net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		req := "POST /echo HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\nConnection: close\r\n\r\nhello"
+		_, err = conn.Write([]byte(req))
+		assert.Nil(t, err)
+		resp, err := ioutil.ReadAll(conn)
+		assert.Nil(t, err)
+		conn.Close()
+
+		select {
+		case stats := <-closed:
+			assert.DeepEqual(t, int64(len(req)), atomic.LoadInt64(&readInHandler))
+			assert.DeepEqual(t, int64(len(req)), stats[0])
+			assert.DeepEqual(t, int64(len(resp)), stats[1])
+		case <-time.After(time.Second):
+			t.Fatalf("OnClose is not called by transporter %d", i)
+		}
+		h.Close()
+	}
+}
+
+func TestWithListener(t *testing.T) {
+	// the standard transporter is used by default, and netpoll serves the tcp listeners
+	for _, transporter := range []string{"", TransporterNetpoll} {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		opts := []config.Option{WithListener(ln)}
+		if transporter != "" {
+			opts = append(opts, WithTransporter(transporter))
+		}
+		h := New(opts...)
+		assert.DeepEqual(t, ln.Addr().String(), h.GetOptions().Addr)
+		h.GET("/ping", func(ctx context.Context, c *app.RequestContext) {
+			c.String(consts.StatusOK, "pong")
+		})
+		go h.Spin()
+		time.Sleep(500 * time.Millisecond)
+
+		cli, _ := c.NewClient()
+		status, body, err := cli.Get(context.Background(), nil, "http://"+ln.Addr().String()+"/ping")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, consts.StatusOK, status)
+		assert.DeepEqual(t, "pong", string(body))
+		h.Close()
+	}
+}
@@ -19,6 +19,7 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
 	"strings"
 	"time"
@@ -26,9 +27,11 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/server/registry"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/tracer"
+	"github.com/cloudwego/hertz/pkg/common/tracer/slowlog"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
 )
 
 // WithKeepAliveTimeout sets keep-alive timeout.
@@ -184,6 +187,90 @@ func WithMaxRequestBodySize(bs int) config.Option {
 	}}
 }
 
+// WithMaxHeaderBytes sets the limitation of request line and headers size. Unit: byte
+//
+// 431 Request Header Fields Too Large is returned if the limitation is exceeded,
+// 0 means no limitation. The default is 1MB.
+func WithMaxHeaderBytes(size int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxHeaderBytes = size
+	}}
+}
+
+// WithMaxHeaderCount sets the limitation of request headers count.
+//
+// 431 Request Header Fields Too Large is returned if the limitation is exceeded,
+// 0 means no limitation, which is the default.
+func WithMaxHeaderCount(count int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxHeaderCount = count
+	}}
+}
+
+// WithMaxURLLength sets the limitation of request uri length. Unit: byte
+//
+// 414 Request URI Too Long is returned if the limitation is exceeded,
+// 0 means no limitation, which is the default.
+func WithMaxURLLength(length int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxURLLength = length
+	}}
+}
+
+// WithMaxRequestsPerConn sets the max number of requests served by a keep-alive connection.
+//
+// The response of the last request carries 'Connection: close', and the connection is closed
+// after it is written, so that the clients reconnect and can be rebalanced by the load balancer.
+// 0 means no limitation, which is the default.
+func WithMaxRequestsPerConn(n int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxRequestsPerConn = n
+	}}
+}
+
+// WithMaxConnAge sets the max duration for which a keep-alive connection is reused.
+//
+// The response of the first request finished after the duration carries 'Connection: close',
+// and the connection is closed after it is written.
+// 0 means no limitation, which is the default.
+func WithMaxConnAge(d time.Duration) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxConnAge = d
+	}}
+}
+
+// WithMaxFormFields sets the limitation of the number of urlencoded or multipart form fields,
+// including the files of multipart forms.
+//
+// 413 Request Entity Too Large is returned if the limitation is exceeded,
+// 0 means no limitation, which is the default.
+func WithMaxFormFields(n int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxFormFields = n
+	}}
+}
+
+// WithMaxFormKeyLength sets the limitation of the key length of urlencoded or multipart form fields. Unit: byte
+//
+// 400 Bad Request is returned if the limitation is exceeded,
+// 0 means no limitation, which is the default.
+func WithMaxFormKeyLength(length int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxFormKeyLength = length
+	}}
+}
+
+// WithMaxFormValueLength sets the limitation of the value length of urlencoded or multipart form fields,
+// the files of multipart forms are not limited by it. Unit: byte
+//
+// 413 Request Entity Too Large is returned if the limitation is exceeded,
+// 0 means no limitation, which is the default.
+func WithMaxFormValueLength(length int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.MaxFormValueLength = length
+	}}
+}
+
 // WithMaxKeepBodySize sets max size of request/response body to keep when recycled. Unit: byte
 //
 // Body buffer which larger than this size will be put back into buffer poll.
@@ -235,12 +322,37 @@ func WithExitWaitTime(timeout time.Duration) config.Option {
 	}}
 }
 
+// WithShutdownTimeouts sets the deadlines of the phases of graceful shutdown,
+// see config.ShutdownTimeouts. They are all relative to the beginning of shutdown
+// and bounded by WithExitWaitTime.
+//
+// NOTE:
+//
+//	The responses written during shutdown are always added 'Connection: close' header,
+//	so that a keep-alive connection is closed after the request in flight is finished.
+//	The idle connections of netpoll are closed once it stops accepting.
+func WithShutdownTimeouts(accept, idle, request time.Duration) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.ShutdownTimeouts = &config.ShutdownTimeouts{
+			Accept:  accept,
+			Idle:    idle,
+			Request: request,
+		}
+	}}
+}
+
 // WithTLS sets TLS config to start a tls server.
 //
-// NOTE: If a tls server is started, it won't accept non-tls request.
+// NOTE:
+//
+//	If a tls server is started, it won't accept non-tls request.
+//	The standard transporter is used if there is no explicit transporter,
+//	use WithTransporter(TransporterNetpoll) to serve tls by netpoll.
+//	The context returned by OnConnect must be derived from the given one with netpoll,
+//	which carries the tls connection.
 func WithTLS(cfg *tls.Config) config.Option {
 	return config.Option{F: func(o *config.Options) {
-		// If there is no explicit transporter, change it to standard one. Netpoll do not support tls yet.
+		// If there is no explicit transporter, change it to standard one.
 		if o.TransporterNewer == nil {
 			o.TransporterNewer = standard.NewTransporter
 		}
@@ -248,6 +360,27 @@ func WithTLS(cfg *tls.Config) config.Option {
 	}}
 }
 
+// WithListener sets the listener to be served instead of listening on the address set by
+// WithHostPorts, e.g. the listeners created by tls termination libraries, tests or tsnet.
+//
+// NOTE:
+//
+//	The address and network of the listener take the place of the ones set before.
+//	The standard transporter is used if there is no explicit transporter, since netpoll
+//	and io_uring transporter only serve the listeners with file descriptors, e.g. *net.TCPListener.
+//	The listener is closed when the server shuts down.
+func WithListener(ln net.Listener) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		// If there is no explicit transporter, change it to standard one.
+		if o.TransporterNewer == nil {
+			o.TransporterNewer = standard.NewTransporter
+		}
+		o.Listener = ln
+		o.Network = ln.Addr().Network()
+		o.Addr = ln.Addr().String()
+	}}
+}
+
 // WithListenConfig sets listener config.
 func WithListenConfig(l *net.ListenConfig) config.Option {
 	return config.Option{F: func(o *config.Options) {
@@ -262,6 +395,20 @@ func WithTransport(transporter func(options *config.Options) network.Transporter
 	}}
 }
 
+// WithTransporter sets the network library to use by the name registered by RegisterTransporter,
+// e.g. TransporterStandard.
+//
+// NOTE:
+//
+//	WithTransporter panics if name is not registered.
+func WithTransporter(name string) config.Option {
+	newer, ok := lookupTransporter(name)
+	if !ok {
+		panic(fmt.Sprintf("server: transporter %q is not registered, registered: %v", name, Transporters()))
+	}
+	return WithTransport(newer)
+}
+
 // WithAltTransport sets which network library to use as an alternative transporter(need to be implemented by specific transporter).
 func WithAltTransport(transporter func(options *config.Options) network.Transporter) config.Option {
 	return config.Option{F: func(o *config.Options) {
@@ -283,6 +430,57 @@ func WithReadBufferSize(size int) config.Option {
 	}}
 }
 
+// WithWriteBufferSize sets the min size of the buffers allocated for writing the responses.
+//
+// NOTE:
+//
+//	It takes effect with the standard and io_uring transporters,
+//	the buffers of netpoll are managed by netpoll itself.
+func WithWriteBufferSize(size int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.WriteBufferSize = size
+	}}
+}
+
+// WithBufferPool sets the pool which the connection buffers are allocated from,
+// e.g. network.NewBufferPool(1024, 64*1024, 1024) retains at most 1024 free buffers
+// of each size from 1KB to 64KB.
+//
+// NOTE:
+//
+//	It takes effect with the standard and io_uring transporters,
+//	the buffers of netpoll are managed by netpoll itself.
+//	If pool==nil, the buffers are allocated from mcache, which is the default.
+func WithBufferPool(pool network.BufferPool) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.BufferPool = pool
+	}}
+}
+
+// WithConnBandwidth limits the read and write rates of each connection in bytes per second,
+// 0 means unlimited.
+//
+// NOTE:
+//
+//	It takes effect with the standard and io_uring transporters, but not netpoll.
+func WithConnBandwidth(read, write int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.ConnBandwidth = network.Bandwidth{Read: read, Write: write}
+	}}
+}
+
+// WithTotalBandwidth limits the total read and write rates of all the connections
+// in bytes per second, 0 means unlimited.
+//
+// NOTE:
+//
+//	It takes effect with the standard and io_uring transporters, but not netpoll.
+func WithTotalBandwidth(read, write int) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.TotalBandwidth = network.Bandwidth{Read: read, Write: write}
+	}}
+}
+
 // WithALPN sets whether enable ALPN.
 func WithALPN(enable bool) config.Option {
 	return config.Option{F: func(o *config.Options) {
@@ -304,6 +502,20 @@ func WithTraceLevel(level stats.Level) config.Option {
 	}}
 }
 
+// WithSlowRequestThreshold logs the requests whose latency exceeds threshold by the system logger,
+// with the route, the durations of the phases and the metadata of the request.
+// callback is called with the slow requests after logging if it is not nil, e.g. to report metrics.
+//
+// NOTE:
+//
+//	It works as a tracer, so the durations of the phases are recorded only if the trace level is stats.LevelDetailed,
+//	which is the default.
+func WithSlowRequestThreshold(threshold time.Duration, callback slowlog.Callback) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.Tracers = append(o.Tracers, slowlog.NewTracer(threshold, callback))
+	}}
+}
+
 // WithRegistry sets the registry and registry's info
 func WithRegistry(r registry.Registry, info *registry.Info) config.Option {
 	return config.Option{F: func(o *config.Options) {
@@ -331,6 +543,42 @@ func WithDisablePrintRoute(b bool) config.Option {
 	}}
 }
 
+// WithDisableHeaderNamesNormalizing is used to set whether disable header names normalizing.
+// If disabled, the header names of requests and responses are kept in their original casing.
+func WithDisableHeaderNamesNormalizing(disable bool) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.DisableHeaderNamesNormalizing = disable
+	}}
+}
+
+// WithStrictCookieParsing sets whether the request cookies are parsed according to RFC 6265 strictly.
+// If enabled, the cookie-pairs without '=', with invalid names or values, or larger than 4096 bytes are ignored.
+func WithStrictCookieParsing(strict bool) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.StrictCookieParsing = strict
+	}}
+}
+
+// WithStrictRequestParsing sets whether the request header is parsed in the strict mode,
+// which is recommended when hertz serves the clients directly without a proxy in front.
+// If enabled, the request with both Content-Length and Transfer-Encoding, conflicting Content-Length,
+// obs-fold header lines, or bare CR/LF is rejected with 400 and the connection is closed.
+func WithStrictRequestParsing(strict bool) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.StrictRequestParsing = strict
+	}}
+}
+
+// WithQueryDialect sets the dialect used to parse the query args of requests,
+// e.g. ';' as separator, '+' kept as it is, or the policy of duplicate keys.
+func WithQueryDialect(d protocol.QueryDialect) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.QuerySemicolonSeparator = d.SemicolonSeparator
+		o.QueryDisablePlusAsSpace = d.DisablePlusAsSpace
+		o.QueryDuplicateKeyPolicy = int(d.DuplicateKeys)
+	}}
+}
+
 // WithOnAccept sets the callback function when a new connection is accepted but cannot
 // receive data in netpoll. In go net, it will be called before converting tls connection
 func WithOnAccept(fn func(conn net.Conn) context.Context) config.Option {
@@ -346,3 +594,15 @@ func WithOnConnect(fn func(ctx context.Context, conn network.Conn) context.Conte
 		o.OnConnect = fn
 	}}
 }
+
+// WithOnClose sets the callback function when a connection is closed, e.g. to report
+// the bytes transferred on it by network.ConnStatsFromContext(ctx).
+//
+// NOTE:
+//
+//	In go net, the hijacked connections are reported once their serving is finished.
+func WithOnClose(fn func(ctx context.Context, conn network.Conn)) config.Option {
+	return config.Option{F: func(o *config.Options) {
+		o.OnClose = fn
+	}}
+}
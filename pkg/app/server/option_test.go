@@ -25,6 +25,8 @@ import (
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
 )
 
 func TestOptions(t *testing.T) {
@@ -33,6 +35,7 @@ func TestOptions(t *testing.T) {
 		Weight:      10,
 		Addr:        utils.NewNetAddr("tcp", ":8888"),
 	}
+	pool := network.NewBufferPool(1024, 4096, 16)
 	opt := config.NewOptions([]config.Option{
 		WithReadTimeout(time.Second),
 		WithWriteTimeout(time.Second),
@@ -49,7 +52,19 @@ func TestOptions(t *testing.T) {
 		WithHostPorts(":8888"),
 		WithBasePath("/"),
 		WithMaxRequestBodySize(2),
+		WithMaxHeaderBytes(3),
+		WithMaxHeaderCount(4),
+		WithMaxURLLength(5),
+		WithMaxRequestsPerConn(100),
+		WithMaxConnAge(time.Minute),
+		WithMaxFormFields(6),
+		WithMaxFormKeyLength(7),
+		WithMaxFormValueLength(8),
 		WithDisablePrintRoute(true),
+		WithDisableHeaderNamesNormalizing(true),
+		WithStrictCookieParsing(true),
+		WithStrictRequestParsing(true),
+		WithQueryDialect(protocol.QueryDialect{SemicolonSeparator: true, DuplicateKeys: protocol.DuplicateKeysKeepLast}),
 		WithNetwork("unix"),
 		WithExitWaitTime(time.Second),
 		WithMaxKeepBodySize(500),
@@ -58,6 +73,10 @@ func TestOptions(t *testing.T) {
 		WithTLS(nil),
 		WithH2C(true),
 		WithReadBufferSize(100),
+		WithWriteBufferSize(200),
+		WithBufferPool(pool),
+		WithConnBandwidth(1, 2),
+		WithTotalBandwidth(3, 0),
 		WithALPN(true),
 		WithTraceLevel(stats.LevelDisabled),
 		WithRegistry(nil, info),
@@ -78,7 +97,21 @@ func TestOptions(t *testing.T) {
 	assert.DeepEqual(t, opt.Addr, ":8888")
 	assert.DeepEqual(t, opt.BasePath, "/")
 	assert.DeepEqual(t, opt.MaxRequestBodySize, 2)
+	assert.DeepEqual(t, opt.MaxHeaderBytes, 3)
+	assert.DeepEqual(t, opt.MaxHeaderCount, 4)
+	assert.DeepEqual(t, opt.MaxURLLength, 5)
+	assert.DeepEqual(t, opt.MaxRequestsPerConn, 100)
+	assert.DeepEqual(t, opt.MaxConnAge, time.Minute)
+	assert.DeepEqual(t, opt.MaxFormFields, 6)
+	assert.DeepEqual(t, opt.MaxFormKeyLength, 7)
+	assert.DeepEqual(t, opt.MaxFormValueLength, 8)
 	assert.DeepEqual(t, opt.DisablePrintRoute, true)
+	assert.DeepEqual(t, opt.DisableHeaderNamesNormalizing, true)
+	assert.DeepEqual(t, opt.StrictCookieParsing, true)
+	assert.DeepEqual(t, opt.StrictRequestParsing, true)
+	assert.DeepEqual(t, opt.QuerySemicolonSeparator, true)
+	assert.DeepEqual(t, opt.QueryDisablePlusAsSpace, false)
+	assert.DeepEqual(t, opt.QueryDuplicateKeyPolicy, int(protocol.DuplicateKeysKeepLast))
 	assert.DeepEqual(t, opt.Network, "unix")
 	assert.DeepEqual(t, opt.ExitWaitTimeout, time.Second)
 	assert.DeepEqual(t, opt.MaxKeepBodySize, 500)
@@ -86,6 +119,10 @@ func TestOptions(t *testing.T) {
 	assert.DeepEqual(t, opt.DisableKeepalive, true)
 	assert.DeepEqual(t, opt.H2C, true)
 	assert.DeepEqual(t, opt.ReadBufferSize, 100)
+	assert.DeepEqual(t, opt.WriteBufferSize, 200)
+	assert.DeepEqual(t, opt.BufferPool, pool)
+	assert.DeepEqual(t, opt.ConnBandwidth, network.Bandwidth{Read: 1, Write: 2})
+	assert.DeepEqual(t, opt.TotalBandwidth, network.Bandwidth{Read: 3})
 	assert.DeepEqual(t, opt.ALPN, true)
 	assert.DeepEqual(t, opt.TraceLevel, stats.LevelDisabled)
 	assert.DeepEqual(t, opt.RegistryInfo, info)
@@ -110,14 +147,32 @@ func TestDefaultOptions(t *testing.T) {
 	assert.DeepEqual(t, opt.Addr, ":8888")
 	assert.DeepEqual(t, opt.BasePath, "/")
 	assert.DeepEqual(t, opt.MaxRequestBodySize, 4*1024*1024)
+	assert.DeepEqual(t, opt.MaxHeaderBytes, 1024*1024)
+	assert.DeepEqual(t, opt.MaxHeaderCount, 0)
+	assert.DeepEqual(t, opt.MaxURLLength, 0)
+	assert.DeepEqual(t, opt.MaxRequestsPerConn, 0)
+	assert.DeepEqual(t, opt.MaxConnAge, time.Duration(0))
+	assert.DeepEqual(t, opt.MaxFormFields, 0)
+	assert.DeepEqual(t, opt.MaxFormKeyLength, 0)
+	assert.DeepEqual(t, opt.MaxFormValueLength, 0)
 	assert.DeepEqual(t, opt.GetOnly, false)
 	assert.DeepEqual(t, opt.DisableKeepalive, false)
 	assert.DeepEqual(t, opt.DisablePrintRoute, false)
+	assert.DeepEqual(t, opt.DisableHeaderNamesNormalizing, false)
+	assert.DeepEqual(t, opt.StrictCookieParsing, false)
+	assert.DeepEqual(t, opt.StrictRequestParsing, false)
+	assert.DeepEqual(t, opt.QuerySemicolonSeparator, false)
+	assert.DeepEqual(t, opt.QueryDisablePlusAsSpace, false)
+	assert.DeepEqual(t, opt.QueryDuplicateKeyPolicy, 0)
 	assert.DeepEqual(t, opt.Network, "tcp")
 	assert.DeepEqual(t, opt.ExitWaitTimeout, time.Second*5)
 	assert.DeepEqual(t, opt.MaxKeepBodySize, 4*1024*1024)
 	assert.DeepEqual(t, opt.H2C, false)
 	assert.DeepEqual(t, opt.ReadBufferSize, 4096)
+	assert.DeepEqual(t, opt.WriteBufferSize, 4096)
+	assert.Nil(t, opt.BufferPool)
+	assert.DeepEqual(t, opt.ConnBandwidth, network.Bandwidth{})
+	assert.DeepEqual(t, opt.TotalBandwidth, network.Bandwidth{})
 	assert.DeepEqual(t, opt.ALPN, false)
 	assert.DeepEqual(t, opt.Registry, registry.NoopRegistry)
 	assert.DeepEqual(t, opt.AutoReloadRender, false)
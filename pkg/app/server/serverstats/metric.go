@@ -0,0 +1,141 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverstats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram is the snapshot of a latency histogram.
+//
+// NOTE:
+//
+//	The durations are encoded in nanoseconds in JSON.
+type Histogram struct {
+	Count uint64        `json:"count"`
+	Sum   time.Duration `json:"sum"`
+	// Buckets are cumulative, the count of a bucket includes the ones of the lower buckets,
+	// the observations above the highest bound are only counted by Count.
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Bucket is a bucket of Histogram.
+type Bucket struct {
+	UpperBound time.Duration `json:"le"`
+	Count      uint64        `json:"count"`
+}
+
+// Mean returns the mean latency, or 0 if there is no observation.
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+type histogram struct {
+	count  uint64
+	sum    int64
+	bounds []time.Duration
+	counts []uint64
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] }); i < len(h.bounds) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	atomic.AddInt64(&h.sum, int64(d))
+	atomic.AddUint64(&h.count, 1)
+}
+
+func (h *histogram) snapshot() Histogram {
+	ret := Histogram{
+		Count:   atomic.LoadUint64(&h.count),
+		Sum:     time.Duration(atomic.LoadInt64(&h.sum)),
+		Buckets: make([]Bucket, len(h.bounds)),
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		ret.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	return ret
+}
+
+// rateCounter counts the events in the buckets of seconds to calculate the rate over a window.
+type rateCounter struct {
+	mu      sync.Mutex
+	buckets []uint64
+	last    int64
+}
+
+func newRateCounter(window time.Duration) *rateCounter {
+	n := int(window / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return &rateCounter{buckets: make([]uint64, n+1)}
+}
+
+// advance clears the buckets of the seconds passed since the last event.
+func (r *rateCounter) advance(sec int64) {
+	if sec <= r.last {
+		return
+	}
+	n := int64(len(r.buckets))
+	if sec-r.last >= n {
+		for i := range r.buckets {
+			r.buckets[i] = 0
+		}
+	} else {
+		for s := r.last + 1; s <= sec; s++ {
+			r.buckets[s%n] = 0
+		}
+	}
+	r.last = sec
+}
+
+func (r *rateCounter) inc(now time.Time) {
+	sec := now.Unix()
+	r.mu.Lock()
+	r.advance(sec)
+	r.buckets[sec%int64(len(r.buckets))]++
+	r.mu.Unlock()
+}
+
+// rate returns the events per second over the window excluding the current second, which is incomplete.
+func (r *rateCounter) rate(now time.Time) float64 {
+	sec := now.Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.advance(sec)
+	n := int64(len(r.buckets))
+	var sum uint64
+	for i, c := range r.buckets {
+		if int64(i) != sec%n {
+			sum += c
+		}
+	}
+	return float64(sum) / float64(n-1)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]time.Duration{10 * time.Millisecond, 100 * time.Millisecond})
+	assert.DeepEqual(t, time.Duration(0), (&Histogram{}).Mean())
+	for _, d := range []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, time.Second} {
+		h.observe(d)
+	}
+	s := h.snapshot()
+	assert.DeepEqual(t, uint64(4), s.Count)
+	assert.DeepEqual(t, 1065*time.Millisecond, s.Sum)
+	assert.DeepEqual(t, []Bucket{
+		{UpperBound: 10 * time.Millisecond, Count: 2},
+		{UpperBound: 100 * time.Millisecond, Count: 3},
+	}, s.Buckets)
+	assert.DeepEqual(t, 266250*time.Microsecond, s.Mean())
+}
+
+func TestRateCounter(t *testing.T) {
+	r := newRateCounter(4 * time.Second)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 8; i++ {
+		r.inc(now)
+	}
+	// the current second is not counted
+	assert.DeepEqual(t, float64(0), r.rate(now))
+	assert.DeepEqual(t, float64(2), r.rate(now.Add(time.Second)))
+	r.inc(now.Add(2 * time.Second))
+	assert.DeepEqual(t, 2.25, r.rate(now.Add(3*time.Second)))
+	assert.DeepEqual(t, 0.25, r.rate(now.Add(5*time.Second)))
+	assert.DeepEqual(t, float64(0), r.rate(now.Add(time.Minute)))
+
+	assert.DeepEqual(t, 2, len(newRateCounter(0).buckets))
+}
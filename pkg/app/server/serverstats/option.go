@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverstats
+
+import "time"
+
+// DefLatencyBuckets are the default upper bounds of the latency histograms.
+var DefLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+const defaultRateWindow = 10 * time.Second
+
+type (
+	options struct {
+		buckets    []time.Duration
+		rateWindow time.Duration
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		buckets:    DefLatencyBuckets,
+		rateWindow: defaultRateWindow,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithLatencyBuckets sets the upper bounds of the latency histograms in increasing order,
+// which are DefLatencyBuckets by default.
+func WithLatencyBuckets(buckets ...time.Duration) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// WithRateWindow sets the window the rate of the accepted connections is averaged over,
+// which is 10s by default. It is rounded down to seconds and at least 1s.
+func WithRateWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.rateWindow = d
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package serverstats provides the statistics of the server, e.g.
+//
+//	stats := serverstats.NewRegistry()
+//	h := server.Default(server.WithTracer(stats))
+//	h.GET("/admin/stats", stats.Handler())
+package serverstats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/tracer"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// UnmatchedRoute is the route of the requests which do not match any route in Snapshot.Routes.
+const UnmatchedRoute = "<unmatched>"
+
+var (
+	_ tracer.Tracer     = (*Registry)(nil)
+	_ tracer.ConnTracer = (*Registry)(nil)
+)
+
+// Snapshot is the statistics of the server at a moment.
+type Snapshot struct {
+	Time        time.Time    `json:"time"`
+	Connections ConnStats    `json:"connections"`
+	Requests    ReqStats     `json:"requests"`
+	Routes      []RouteStats `json:"routes"`
+}
+
+// ConnStats is the statistics of the connections.
+type ConnStats struct {
+	// Active is the number of the connections being served.
+	Active int64 `json:"active"`
+	// Accepted is the number of the connections served since the start.
+	Accepted uint64 `json:"accepted"`
+	// AcceptedPerSec is the average rate of the accepted connections over the rate window.
+	AcceptedPerSec float64 `json:"accepted_per_sec"`
+}
+
+// ReqStats is the statistics of the requests.
+type ReqStats struct {
+	// Active is the number of the requests being handled, from reading the request to writing the response.
+	Active int64 `json:"active"`
+	// Total is the number of the finished requests.
+	Total uint64 `json:"total"`
+	// RecvBytes and SendBytes are the sizes of the requests and responses,
+	// the sizes are got from the trace stats and may be inaccurate for the streaming bodies.
+	RecvBytes uint64 `json:"recv_bytes"`
+	SendBytes uint64 `json:"send_bytes"`
+}
+
+// RouteStats is the statistics of the requests of a route template.
+type RouteStats struct {
+	Route string `json:"route"`
+	// Errors is the number of the requests responded with 5xx.
+	Errors  uint64    `json:"errors"`
+	Latency Histogram `json:"latency"`
+}
+
+type routeStats struct {
+	errors  uint64
+	latency *histogram
+}
+
+// Registry collects the statistics of the server as a tracer, which should be added by server.WithTracer.
+// It is safe for concurrent use.
+//
+// NOTE:
+//
+//	The latency is measured from reading the request to writing the response,
+//	so the trace level must not be stats.LevelDisabled.
+type Registry struct {
+	// the counters are placed first to be 64-bit aligned for the atomic operations
+	activeConns    int64
+	acceptedConns  uint64
+	activeRequests int64
+	requests       uint64
+	recvBytes      uint64
+	sendBytes      uint64
+
+	opts         *options
+	now          func() time.Time
+	acceptedRate *rateCounter
+
+	mu     sync.RWMutex
+	routes map[string]*routeStats
+}
+
+// NewRegistry creates a Registry.
+func NewRegistry(opts ...Option) *Registry {
+	cfg := newOptions(opts...)
+	return &Registry{
+		opts:         cfg,
+		now:          time.Now,
+		acceptedRate: newRateCounter(cfg.rateWindow),
+		routes:       make(map[string]*routeStats),
+	}
+}
+
+// ConnStart implements tracer.ConnTracer.
+func (r *Registry) ConnStart(_ context.Context) {
+	atomic.AddInt64(&r.activeConns, 1)
+	atomic.AddUint64(&r.acceptedConns, 1)
+	r.acceptedRate.inc(r.now())
+}
+
+// ConnFinish implements tracer.ConnTracer.
+func (r *Registry) ConnFinish(_ context.Context) {
+	atomic.AddInt64(&r.activeConns, -1)
+}
+
+// Start implements tracer.Tracer.
+func (r *Registry) Start(ctx context.Context, _ *app.RequestContext) context.Context {
+	atomic.AddInt64(&r.activeRequests, 1)
+	return ctx
+}
+
+// Finish implements tracer.Tracer.
+func (r *Registry) Finish(_ context.Context, c *app.RequestContext) {
+	atomic.AddInt64(&r.activeRequests, -1)
+	atomic.AddUint64(&r.requests, 1)
+
+	ti := c.GetTraceInfo()
+	if ti == nil {
+		return
+	}
+	st := ti.Stats()
+	if n := st.RecvSize(); n > 0 {
+		atomic.AddUint64(&r.recvBytes, uint64(n))
+	}
+	if n := st.SendSize(); n > 0 {
+		atomic.AddUint64(&r.sendBytes, uint64(n))
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	rs := r.route(route)
+	if c.Response.StatusCode() >= consts.StatusInternalServerError {
+		atomic.AddUint64(&rs.errors, 1)
+	}
+	start, finish := st.GetEvent(stats.HTTPStart), st.GetEvent(stats.HTTPFinish)
+	if start != nil && finish != nil {
+		rs.latency.observe(finish.Time().Sub(start.Time()))
+	}
+}
+
+func (r *Registry) route(route string) *routeStats {
+	r.mu.RLock()
+	rs := r.routes[route]
+	r.mu.RUnlock()
+	if rs != nil {
+		return rs
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rs = r.routes[route]; rs == nil {
+		rs = &routeStats{latency: newHistogram(r.opts.buckets)}
+		r.routes[route] = rs
+	}
+	return rs
+}
+
+// Snapshot returns the current statistics, the routes are sorted by the route templates.
+func (r *Registry) Snapshot() *Snapshot {
+	now := r.now()
+	s := &Snapshot{
+		Time: now,
+		Connections: ConnStats{
+			Active:         atomic.LoadInt64(&r.activeConns),
+			Accepted:       atomic.LoadUint64(&r.acceptedConns),
+			AcceptedPerSec: r.acceptedRate.rate(now),
+		},
+		Requests: ReqStats{
+			Active:    atomic.LoadInt64(&r.activeRequests),
+			Total:     atomic.LoadUint64(&r.requests),
+			RecvBytes: atomic.LoadUint64(&r.recvBytes),
+			SendBytes: atomic.LoadUint64(&r.sendBytes),
+		},
+	}
+
+	r.mu.RLock()
+	s.Routes = make([]RouteStats, 0, len(r.routes))
+	for route, rs := range r.routes {
+		s.Routes = append(s.Routes, RouteStats{
+			Route:   route,
+			Errors:  atomic.LoadUint64(&rs.errors),
+			Latency: rs.latency.snapshot(),
+		})
+	}
+	r.mu.RUnlock()
+	sort.Slice(s.Routes, func(i, j int) bool {
+		return s.Routes[i].Route < s.Routes[j].Route
+	})
+	return s
+}
+
+// Handler returns the handler responding the snapshot in JSON, which is meant to be
+// registered as an admin endpoint and protected from the public.
+func (r *Registry) Handler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		ctx.Header(consts.HeaderCacheControl, "no-store")
+		ctx.JSON(consts.StatusOK, r.Snapshot())
+	}
+}
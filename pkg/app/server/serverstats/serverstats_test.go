@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serverstats
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(WithLatencyBuckets(50 * time.Millisecond))
+	h := server.New(server.WithHostPorts("localhost:9233"), server.WithTracer(r))
+	h.GET("/users/:id", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "user")
+	})
+	h.GET("/fail", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusInternalServerError, "fail")
+	})
+	h.GET("/admin/stats", r.Handler())
+	go h.Spin()
+	time.Sleep(time.Second)
+
+	cli, err := client.NewClient()
+	assert.Nil(t, err)
+	for _, path := range []string{"/users/1", "/users/2", "/fail", "/missing"} {
+		_, _, err = cli.Get(context.Background(), nil, "http://127.0.0.1:9233"+path)
+		assert.Nil(t, err)
+	}
+
+	status, body, err := cli.Get(context.Background(), nil, "http://127.0.0.1:9233/admin/stats")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, status)
+	var s Snapshot
+	assert.Nil(t, json.Unmarshal(body, &s))
+	// the connection is kept alive by the client
+	assert.DeepEqual(t, int64(1), s.Connections.Active)
+	assert.DeepEqual(t, uint64(1), s.Connections.Accepted)
+	// the request of the snapshot is being handled
+	assert.DeepEqual(t, int64(1), s.Requests.Active)
+	assert.DeepEqual(t, uint64(4), s.Requests.Total)
+	assert.True(t, s.Requests.SendBytes > 0)
+	assert.DeepEqual(t, 3, len(s.Routes))
+	assert.DeepEqual(t, "/fail", s.Routes[0].Route)
+	assert.DeepEqual(t, uint64(1), s.Routes[0].Errors)
+	assert.DeepEqual(t, "/users/:id", s.Routes[1].Route)
+	assert.DeepEqual(t, uint64(0), s.Routes[1].Errors)
+	assert.DeepEqual(t, uint64(2), s.Routes[1].Latency.Count)
+	assert.DeepEqual(t, uint64(2), s.Routes[1].Latency.Buckets[0].Count)
+	assert.DeepEqual(t, UnmatchedRoute, s.Routes[2].Route)
+
+	cli.CloseIdleConnections()
+	time.Sleep(100 * time.Millisecond)
+	snapshot := r.Snapshot()
+	assert.DeepEqual(t, int64(0), snapshot.Connections.Active)
+	assert.DeepEqual(t, int64(0), snapshot.Requests.Active)
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.ConnStart(context.Background())
+				ctx := app.NewContext(0)
+				r.Finish(r.Start(context.Background(), ctx), ctx)
+				r.ConnFinish(context.Background())
+				r.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	s := r.Snapshot()
+	assert.DeepEqual(t, int64(0), s.Connections.Active)
+	assert.DeepEqual(t, uint64(1000), s.Connections.Accepted)
+	assert.DeepEqual(t, uint64(1000), s.Requests.Total)
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// ErrHubClosed is returned by Subscribe after the Hub is closed.
+var ErrHubClosed = errors.New("sse: hub closed")
+
+// Hub publishes the events of topics to the subscribers, each of which is an event stream
+// responded to a client.
+//
+// The events are buffered for each subscriber and written by a goroutine of it, so a slow client
+// never blocks the publishers or the others, see WithBufferSize and WithDropPolicy.
+type Hub struct {
+	opts *options
+
+	mu     sync.RWMutex
+	topics map[string]map[*Subscriber]struct{}
+	closed bool
+}
+
+// NewHub returns a Hub with opts.
+func NewHub(opts ...Option) *Hub {
+	return &Hub{
+		opts:   newOptions(opts...),
+		topics: make(map[string]map[*Subscriber]struct{}),
+	}
+}
+
+// Subscribe responds an event stream to ctx, which receives the events published to topics
+// until the subscriber is closed or the client is disconnected. id identifies the subscriber
+// in the presence callbacks, e.g. the user of the client, which may be empty.
+//
+// The events are sent once the handler returns, so the handler returns after subscribing.
+func (h *Hub) Subscribe(ctx *app.RequestContext, id string, topics ...string) (*Subscriber, error) {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrHubClosed
+	}
+	s := &Subscriber{
+		hub:  h,
+		id:   id,
+		w:    NewWriter(ctx),
+		ch:   make(chan []byte, h.opts.bufferSize),
+		done: make(chan struct{}),
+	}
+	for _, topic := range topics {
+		subs := h.topics[topic]
+		if subs == nil {
+			subs = make(map[*Subscriber]struct{})
+			h.topics[topic] = subs
+		}
+		if _, ok := subs[s]; !ok {
+			subs[s] = struct{}{}
+			s.topics = append(s.topics, topic)
+		}
+	}
+	h.mu.Unlock()
+
+	if h.opts.onJoin != nil {
+		for _, topic := range s.topics {
+			h.opts.onJoin(topic, s)
+		}
+	}
+	go s.run(h.opts.heartbeatInterval)
+	return s, nil
+}
+
+// Publish sends e to the subscribers of topic, and returns the number of the subscribers
+// it's buffered for, i.e. not dropped.
+func (h *Hub) Publish(topic string, e *Event) (int, error) {
+	b, err := appendEvent(nil, e)
+	if err != nil {
+		return 0, err
+	}
+	subs := h.Subscribers(topic)
+	n := 0
+	for _, s := range subs {
+		if s.send(b, h.opts.dropPolicy) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Subscribers returns the subscribers of topic, e.g. to list the presence of it.
+func (h *Hub) Subscribers(topic string) []*Subscriber {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	subs := make([]*Subscriber, 0, len(h.topics[topic]))
+	for s := range h.topics[topic] {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// Count returns the number of the subscribers of topic.
+func (h *Hub) Count(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic])
+}
+
+// Close closes all the subscribers, and the later Subscribe fails with ErrHubClosed.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	subs := make(map[*Subscriber]struct{})
+	for _, m := range h.topics {
+		for s := range m {
+			subs[s] = struct{}{}
+		}
+	}
+	h.mu.Unlock()
+	for s := range subs {
+		s.Close()
+	}
+}
+
+func (h *Hub) remove(s *Subscriber) {
+	h.mu.Lock()
+	for _, topic := range s.topics {
+		if subs := h.topics[topic]; subs != nil {
+			delete(subs, s)
+			if len(subs) == 0 {
+				delete(h.topics, topic)
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	if h.opts.onLeave != nil {
+		for _, topic := range s.topics {
+			h.opts.onLeave(topic, s)
+		}
+	}
+}
+
+// Subscriber is an event stream subscribed to the topics of a Hub.
+type Subscriber struct {
+	hub    *Hub
+	id     string
+	topics []string
+	w      *Writer
+
+	ch        chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   int64
+}
+
+// ID returns the id passed to Subscribe.
+func (s *Subscriber) ID() string {
+	return s.id
+}
+
+// Topics returns the topics subscribed.
+func (s *Subscriber) Topics() []string {
+	return append([]string(nil), s.topics...)
+}
+
+// Dropped returns the number of the events dropped since the buffer is full.
+func (s *Subscriber) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Done returns a channel closed once the subscriber is closed.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the event stream and leaves the topics, the events buffered are dropped.
+func (s *Subscriber) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.w.Close() //nolint:errcheck
+		s.hub.remove(s)
+	})
+}
+
+// send buffers b by policy, and reports whether it's buffered.
+func (s *Subscriber) send(b []byte, policy DropPolicy) bool {
+	select {
+	case <-s.done:
+		return false
+	case s.ch <- b:
+		return true
+	default:
+	}
+
+	switch policy {
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- b:
+				return true
+			default:
+			}
+			select {
+			case <-s.ch:
+				atomic.AddInt64(&s.dropped, 1)
+			default:
+			}
+		}
+	case DropSubscriber:
+		atomic.AddInt64(&s.dropped, 1)
+		s.Close()
+		return false
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+		return false
+	}
+}
+
+// run writes the events buffered and the heartbeats until the subscriber is closed
+// or the writes fail, i.e. the client is disconnected.
+func (s *Subscriber) run(heartbeatInterval time.Duration) {
+	defer s.Close()
+
+	var heartbeat <-chan time.Time
+	if heartbeatInterval > 0 {
+		t := time.NewTicker(heartbeatInterval)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+	lastWrite := time.Now()
+	for {
+		var err error
+		select {
+		case <-s.done:
+			return
+		case b := <-s.ch:
+			err = s.w.write(b)
+		case <-heartbeat:
+			if time.Since(lastWrite) < heartbeatInterval {
+				continue
+			}
+			err = s.w.WriteComment("")
+		}
+		if err != nil {
+			return
+		}
+		lastWrite = time.Now()
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client/sse"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newTestSubscriber(h *Hub) *Subscriber {
+	return &Subscriber{hub: h, w: NewWriter(app.NewContext(0)), ch: make(chan []byte, 2), done: make(chan struct{})}
+}
+
+func TestDropPolicy(t *testing.T) {
+	h := NewHub()
+	events := [][]byte{[]byte("1"), []byte("2"), []byte("3")}
+
+	s := newTestSubscriber(h)
+	for i, b := range events {
+		assert.DeepEqual(t, i < 2, s.send(b, DropNewest))
+	}
+	assert.DeepEqual(t, int64(1), s.Dropped())
+	assert.DeepEqual(t, "1", string(<-s.ch))
+	assert.DeepEqual(t, "2", string(<-s.ch))
+
+	s = newTestSubscriber(h)
+	for _, b := range events {
+		assert.True(t, s.send(b, DropOldest))
+	}
+	assert.DeepEqual(t, int64(1), s.Dropped())
+	assert.DeepEqual(t, "2", string(<-s.ch))
+	assert.DeepEqual(t, "3", string(<-s.ch))
+
+	s = newTestSubscriber(h)
+	for i, b := range events {
+		assert.DeepEqual(t, i < 2, s.send(b, DropSubscriber))
+	}
+	assert.DeepEqual(t, int64(1), s.Dropped())
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("the subscriber is not closed")
+	}
+	assert.False(t, s.send(events[0], DropNewest))
+}
+
+func TestHub(t *testing.T) {
+	type presence struct {
+		join  bool
+		topic string
+		id    string
+	}
+	presences := make(chan presence, 8)
+	hub := NewHub(WithHeartbeatInterval(100*time.Millisecond),
+		WithJoinHandler(func(topic string, s *Subscriber) { presences <- presence{true, topic, s.ID()} }),
+		WithLeaveHandler(func(topic string, s *Subscriber) { presences <- presence{false, topic, s.ID()} }))
+
+	h := server.New(server.WithHostPorts("127.0.0.1:9255"), server.WithTransport(standard.NewTransporter))
+	h.GET("/events", func(c context.Context, ctx *app.RequestContext) {
+		if _, err := hub.Subscribe(ctx, ctx.Query("id"), "news", "news", "sports"); err != nil {
+			ctx.String(consts.StatusServiceUnavailable, err.Error())
+		}
+	})
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	c, err := sse.NewClient("http://127.0.0.1:9255/events?id=u1", sse.WithMaxReconnects(0))
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *sse.Event, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.SubscribeChan(ctx, received)
+	}()
+	assert.DeepEqual(t, presence{true, "news", "u1"}, <-presences)
+	assert.DeepEqual(t, presence{true, "sports", "u1"}, <-presences)
+	assert.DeepEqual(t, 1, hub.Count("news"))
+	assert.DeepEqual(t, []string{"news", "sports"}, hub.Subscribers("news")[0].Topics())
+
+	n, err := hub.Publish("news", &Event{ID: "1", Data: []byte("a\nb")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 1, n)
+	n, err = hub.Publish("sports", &Event{ID: "2", Event: "score", Data: []byte("1:0")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 1, n)
+	n, err = hub.Publish("weather", &Event{Data: []byte("sunny")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 0, n)
+	_, err = hub.Publish("news", &Event{ID: "\n"})
+	assert.DeepEqual(t, errInvalidField, err)
+
+	assert.DeepEqual(t, &sse.Event{ID: "1", Event: "message", Data: []byte("a\nb")}, <-received)
+	assert.DeepEqual(t, &sse.Event{ID: "2", Event: "score", Data: []byte("1:0")}, <-received)
+
+	// the subscriber leaves once the heartbeat fails after the client is disconnected
+	cancel()
+	assert.DeepEqual(t, context.Canceled, <-done)
+	for _, topic := range []string{"news", "sports"} {
+		select {
+		case p := <-presences:
+			assert.DeepEqual(t, presence{false, topic, "u1"}, p)
+		case <-time.After(2 * time.Second):
+			t.Fatal("the subscriber doesn't leave")
+		}
+	}
+	assert.DeepEqual(t, 0, hub.Count("news"))
+
+	s, err := hub.Subscribe(app.NewContext(0), "u2", "news")
+	assert.Nil(t, err)
+	<-presences
+	hub.Close()
+	<-s.Done()
+	assert.DeepEqual(t, presence{false, "news", "u2"}, <-presences)
+	_, err = hub.Subscribe(app.NewContext(0), "u3", "news")
+	assert.DeepEqual(t, ErrHubClosed, err)
+}
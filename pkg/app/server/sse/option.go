@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import "time"
+
+// DropPolicy decides what to do with an event published to a subscriber whose buffer is full,
+// i.e. a client which can't keep up with the events.
+type DropPolicy int
+
+const (
+	// DropNewest drops the event published.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest event buffered to make room for the event published.
+	DropOldest
+	// DropSubscriber closes the subscriber, the client may reconnect with Last-Event-ID.
+	DropSubscriber
+)
+
+type (
+	options struct {
+		bufferSize        int
+		dropPolicy        DropPolicy
+		heartbeatInterval time.Duration
+		onJoin            func(topic string, s *Subscriber)
+		onLeave           func(topic string, s *Subscriber)
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		bufferSize:        16,
+		heartbeatInterval: 15 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithBufferSize sets the number of the events buffered for each subscriber, which is 16 by default.
+func WithBufferSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.bufferSize = n
+		}
+	}
+}
+
+// WithDropPolicy sets the policy of the events published to the subscribers whose buffers
+// are full, which is DropNewest by default.
+func WithDropPolicy(p DropPolicy) Option {
+	return func(o *options) {
+		o.dropPolicy = p
+	}
+}
+
+// WithHeartbeatInterval sets the interval of the comments sent to the idle subscribers, which
+// keep the connections alive through the proxies and detect the clients disconnected.
+// It is 15s by default, and the heartbeat is disabled if it's not positive.
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.heartbeatInterval = d
+	}
+}
+
+// WithJoinHandler sets the function called after a subscriber joins a topic,
+// e.g. to publish the presence of it.
+func WithJoinHandler(f func(topic string, s *Subscriber)) Option {
+	return func(o *options) {
+		o.onJoin = f
+	}
+}
+
+// WithLeaveHandler sets the function called after a subscriber leaves a topic, which is
+// once it's closed or the client is disconnected.
+func WithLeaveHandler(f func(topic string, s *Subscriber)) Option {
+	return func(o *options) {
+		o.onLeave = f
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sse provides a Writer streaming server-sent events to a client and a Hub publishing
+// the events of topics to the subscribed clients,
+// see https://html.spec.whatwg.org/multipage/server-sent-events.html.
+//
+// The events are consumed by the clients of package pkg/app/client/sse, or by EventSource
+// of the browsers.
+package sse
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const contentType = "text/event-stream"
+
+var errInvalidField = errors.New("sse: id and event must not contain newlines, nor id NUL")
+
+// Event is an event sent to the clients.
+type Event struct {
+	// ID is sent as the id field if it's not empty, which is the Last-Event-ID of the client reconnecting.
+	ID string
+
+	// Event is sent as the event field if it's not empty, "message" is the type of the event by default.
+	Event string
+
+	// Data is the data of the event, the lines of which are sent as the data fields.
+	Data []byte
+
+	// Retry is sent as the retry field if it's positive, the delay of the client reconnecting.
+	Retry time.Duration
+}
+
+// appendEvent appends the fields of e to dst.
+func appendEvent(dst []byte, e *Event) ([]byte, error) {
+	if strings.ContainsAny(e.ID, "\r\n\x00") || strings.ContainsAny(e.Event, "\r\n") {
+		return dst, errInvalidField
+	}
+	if e.ID != "" {
+		dst = append(append(append(dst, "id: "...), e.ID...), '\n')
+	}
+	if e.Event != "" {
+		dst = append(append(append(dst, "event: "...), e.Event...), '\n')
+	}
+	if e.Retry > 0 {
+		dst = append(strconv.AppendInt(append(dst, "retry: "...), e.Retry.Milliseconds(), 10), '\n')
+	}
+	data := e.Data
+	for {
+		i := indexLineEnd(data)
+		if i < 0 {
+			dst = append(append(append(dst, "data: "...), data...), '\n')
+			break
+		}
+		dst = append(append(append(dst, "data: "...), data[:i]...), '\n')
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		data = data[i+1:]
+	}
+	return append(dst, '\n'), nil
+}
+
+// appendComment appends text as the comment lines to dst, which are ignored by the clients.
+func appendComment(dst []byte, text string) []byte {
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		dst = append(append(append(dst, ": "...), line...), '\n')
+	}
+	if text == "" {
+		dst = append(dst, ":\n"...)
+	}
+	return append(dst, '\n')
+}
+
+func indexLineEnd(b []byte) int {
+	for i, c := range b {
+		if c == '\r' || c == '\n' {
+			return i
+		}
+	}
+	return -1
+}
+
+// Writer writes the events to the response of a request.
+//
+// NOTE:
+//
+//	The response is written by the server after the handler returns, so the events are
+//	written by other goroutines, e.g. by a Hub. The writes block until the server sends
+//	them, and fail once the client is disconnected.
+type Writer struct {
+	mu sync.Mutex
+	pw *io.PipeWriter
+}
+
+// NewWriter sets the response of ctx to an event stream, which is written by the returned Writer
+// and ends once it is closed.
+func NewWriter(ctx *app.RequestContext) *Writer {
+	pr, pw := io.Pipe()
+	ctx.SetStatusCode(consts.StatusOK)
+	ctx.SetContentType(contentType)
+	ctx.Response.Header.Set(consts.HeaderCacheControl, "no-cache")
+	// the stream is closed by the server once it's written or fails to write
+	ctx.SetBodyStream(pr, -1)
+	return &Writer{pw: pw}
+}
+
+// WriteEvent writes e to the client.
+func (w *Writer) WriteEvent(e *Event) error {
+	b, err := appendEvent(nil, e)
+	if err != nil {
+		return err
+	}
+	return w.write(b)
+}
+
+// WriteComment writes text as the comments, which are ignored by the clients,
+// e.g. to keep the connection alive.
+func (w *Writer) WriteComment(text string) error {
+	return w.write(appendComment(nil, text))
+}
+
+func (w *Writer) write(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.pw.Write(b)
+	return err
+}
+
+// Close ends the event stream after the events written are sent, the writes blocked are failed.
+func (w *Writer) Close() error {
+	return w.pw.Close()
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sse
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestAppendEvent(t *testing.T) {
+	b, err := appendEvent(nil, &Event{ID: "1", Event: "update", Data: []byte("a\r\nb\rc\n"), Retry: 1500 * time.Millisecond})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "id: 1\nevent: update\nretry: 1500\ndata: a\ndata: b\ndata: c\ndata: \n\n", string(b))
+	b, err = appendEvent(nil, &Event{})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "data: \n\n", string(b))
+	_, err = appendEvent(nil, &Event{ID: "1\n"})
+	assert.DeepEqual(t, errInvalidField, err)
+	_, err = appendEvent(nil, &Event{ID: "1\x00"})
+	assert.DeepEqual(t, errInvalidField, err)
+	_, err = appendEvent(nil, &Event{Event: "a\rb"})
+	assert.DeepEqual(t, errInvalidField, err)
+
+	assert.DeepEqual(t, ":\n\n", string(appendComment(nil, "")))
+	assert.DeepEqual(t, ": a\n: b\n\n", string(appendComment(nil, "a\r\nb")))
+}
+
+func TestWriter(t *testing.T) {
+	ctx := app.NewContext(0)
+	w := NewWriter(ctx)
+	assert.DeepEqual(t, contentType, string(ctx.Response.Header.ContentType()))
+	assert.DeepEqual(t, "no-cache", ctx.Response.Header.Get(consts.HeaderCacheControl))
+	assert.True(t, ctx.Response.IsBodyStream())
+
+	go func() {
+		w.WriteEvent(&Event{Data: []byte("a")}) //nolint:errcheck
+		w.WriteComment("ping")                  //nolint:errcheck
+		w.Close()                               //nolint:errcheck
+	}()
+	b, err := ioutil.ReadAll(ctx.Response.BodyStream())
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "data: a\n\n: ping\n\n", string(b))
+
+	// the writes fail once the stream is closed by the server
+	assert.Nil(t, ctx.Response.CloseBodyStream())
+	assert.NotNil(t, w.WriteEvent(&Event{Data: []byte("b")}))
+}
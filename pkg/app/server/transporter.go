@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/iouring"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+)
+
+// The names of the built-in transporters, netpoll is not available on windows.
+// TransporterIOUring is experimental and falls back to the default transporter if io_uring is not supported,
+// see iouring.NewTransporter.
+const (
+	TransporterStandard = "standard"
+	TransporterNetpoll  = "netpoll"
+	TransporterIOUring  = "iouring"
+)
+
+var (
+	transportersLock sync.RWMutex
+	transporters     = map[string]func(options *config.Options) network.Transporter{
+		TransporterStandard: standard.NewTransporter,
+		TransporterIOUring:  iouring.NewTransporter,
+	}
+)
+
+// RegisterTransporter registers the function creating the transporter of name,
+// which can be chosen by WithTransporter, e.g.
+//
+//	func init() {
+//		server.RegisterTransporter("gnet", gnet.NewTransporter)
+//	}
+//
+//	h := server.New(server.WithTransporter("gnet"))
+//
+// See network.Transporter for the lifecycle the transporter must follow.
+//
+// NOTE:
+//
+//	RegisterTransporter panics if name is empty or registered already, or newer is nil.
+func RegisterTransporter(name string, newer func(options *config.Options) network.Transporter) {
+	if name == "" || newer == nil {
+		panic("server: RegisterTransporter name is empty or newer is nil")
+	}
+	transportersLock.Lock()
+	defer transportersLock.Unlock()
+	if _, ok := transporters[name]; ok {
+		panic(fmt.Sprintf("server: transporter %q is registered already", name))
+	}
+	transporters[name] = newer
+}
+
+// Transporters returns the names of the registered transporters in order.
+func Transporters() []string {
+	transportersLock.RLock()
+	names := make([]string, 0, len(transporters))
+	for name := range transporters {
+		names = append(names, name)
+	}
+	transportersLock.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+func lookupTransporter(name string) (func(options *config.Options) network.Transporter, bool) {
+	transportersLock.RLock()
+	defer transportersLock.RUnlock()
+	newer, ok := transporters[name]
+	return newer, ok
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+)
+
+// countingTransporter wraps the standard transporter and counts the connections and the shutdowns.
+type countingTransporter struct {
+	network.Transporter
+	conns     int32
+	shutdowns int32
+}
+
+func (t *countingTransporter) ListenAndServe(onData network.OnData) error {
+	return t.Transporter.ListenAndServe(func(ctx context.Context, conn interface{}) error {
+		atomic.AddInt32(&t.conns, 1)
+		return onData(ctx, conn)
+	})
+}
+
+func (t *countingTransporter) Shutdown(ctx context.Context) error {
+	atomic.AddInt32(&t.shutdowns, 1)
+	return t.Transporter.Shutdown(ctx)
+}
+
+func TestRegisterTransporter(t *testing.T) {
+	var transporter *countingTransporter
+	RegisterTransporter("counting", func(options *config.Options) network.Transporter {
+		transporter = &countingTransporter{Transporter: standard.NewTransporter(options)}
+		return transporter
+	})
+	assert.Panic(t, func() {
+		RegisterTransporter("counting", standard.NewTransporter)
+	})
+	assert.Panic(t, func() {
+		RegisterTransporter("", standard.NewTransporter)
+	})
+	assert.Panic(t, func() {
+		WithTransporter("unknown")
+	})
+	names := Transporters()
+	assert.DeepEqual(t, "counting", names[0])
+	assert.True(t, len(names) >= 2)
+
+	h := New(WithHostPorts("127.0.0.1:9234"), WithTransporter("counting"), WithExitWaitTime(0))
+	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(http.StatusOK, "pong")
+	})
+	go h.Run() //nolint:errcheck
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get("http://127.0.0.1:9234/ping")
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.DeepEqual(t, "pong", string(body))
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&transporter.conns))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, h.Shutdown(ctx))
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&transporter.shutdowns))
+}
+
+func TestIOUringTransporter(t *testing.T) {
+	h := New(WithHostPorts("127.0.0.1:9236"), WithTransporter(TransporterIOUring), WithExitWaitTime(0))
+	h.POST("/echo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Data(http.StatusOK, "text/plain", ctx.Request.Body())
+	})
+	go h.Run() //nolint:errcheck
+	time.Sleep(100 * time.Millisecond)
+
+	// the keep-alive connection serves the requests one by one
+	client := &http.Client{}
+	for _, body := range []string{"a", strings.Repeat("b", 64*1024), "c"} {
+		resp, err := client.Post("http://127.0.0.1:9236/echo", "text/plain", strings.NewReader(body))
+		assert.Nil(t, err)
+		got, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.DeepEqual(t, body, string(got))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, h.Shutdown(ctx))
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/stackless"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported compression levels of zstd.
+const (
+	CompressZstdSpeedFastest    = int(zstd.SpeedFastest)
+	CompressZstdSpeedDefault    = int(zstd.SpeedDefault)
+	CompressZstdSpeedBetter     = int(zstd.SpeedBetterCompression)
+	CompressZstdBestCompression = int(zstd.SpeedBestCompression)
+	CompressZstdDefaultLevel    = CompressZstdSpeedDefault
+)
+
+const compressZstdLevelsCount = CompressZstdBestCompression - CompressZstdSpeedFastest + 1
+
+var zstdDecoderPool sync.Pool
+
+var (
+	stacklessZstdWriterPoolMap = newZstdWriterPoolMap()
+	realZstdWriterPoolMap      = newZstdWriterPoolMap()
+)
+
+func newZstdWriterPoolMap() []*sync.Pool {
+	var m []*sync.Pool
+	for i := 0; i < compressZstdLevelsCount; i++ {
+		m = append(m, &sync.Pool{})
+	}
+	return m
+}
+
+// normalizes zstd compression level into [0..3], so it could be used as an index
+// in *PoolMap.
+func normalizeZstdCompressLevel(level int) int {
+	if level < CompressZstdSpeedFastest || level > CompressZstdBestCompression {
+		level = CompressZstdDefaultLevel
+	}
+	return level - CompressZstdSpeedFastest
+}
+
+// AcquireZstdReader returns a pooled zstd decoder reading from r.
+func AcquireZstdReader(r io.Reader) (*zstd.Decoder, error) {
+	v := zstdDecoderPool.Get()
+	if v == nil {
+		return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	}
+	zr := v.(*zstd.Decoder)
+	if err := zr.Reset(r); err != nil {
+		return nil, err
+	}
+	return zr, nil
+}
+
+// ReleaseZstdReader puts zr acquired via AcquireZstdReader back to the pool.
+func ReleaseZstdReader(zr *zstd.Decoder) {
+	zr.Reset(nil) //nolint:errcheck
+	zstdDecoderPool.Put(zr)
+}
+
+// AppendUnzstdBytes appends decompressed src to dst and returns the resulting dst.
+func AppendUnzstdBytes(dst, src []byte) ([]byte, error) {
+	w := &byteSliceWriter{dst}
+	_, err := WriteUnzstd(w, src)
+	return w.b, err
+}
+
+// WriteUnzstd writes decompressed p to w and returns the number of uncompressed
+// bytes written to w.
+func WriteUnzstd(w io.Writer, p []byte) (int, error) {
+	r := &byteSliceReader{p}
+	zr, err := AcquireZstdReader(r)
+	if err != nil {
+		return 0, err
+	}
+	zw := network.NewWriter(w)
+	n, err := utils.CopyZeroAlloc(zw, zr)
+	ReleaseZstdReader(zr)
+	nn := int(n)
+	if int64(nn) != n {
+		return 0, fmt.Errorf("too much data decompressed: %d", n)
+	}
+	return nn, err
+}
+
+// AppendZstdBytes appends zstd compressed src to dst and returns the resulting dst.
+func AppendZstdBytes(dst, src []byte) []byte {
+	return AppendZstdBytesLevel(dst, src, CompressZstdDefaultLevel)
+}
+
+// AppendZstdBytesLevel appends zstd compressed src to dst using the given
+// compression level and returns the resulting dst.
+//
+// Supported compression levels are:
+//
+//   - CompressZstdSpeedFastest
+//   - CompressZstdSpeedDefault
+//   - CompressZstdSpeedBetter
+//   - CompressZstdBestCompression
+func AppendZstdBytesLevel(dst, src []byte, level int) []byte {
+	w := &byteSliceWriter{dst}
+	WriteZstdLevel(w, src, level) //nolint:errcheck
+	return w.b
+}
+
+var stacklessWriteZstd = stackless.NewFunc(nonblockingWriteZstd)
+
+func nonblockingWriteZstd(ctxv interface{}) {
+	ctx := ctxv.(*compressCtx)
+	zw := acquireRealZstdWriter(ctx.w, ctx.level)
+
+	_, err := zw.Write(ctx.p)
+	if err != nil {
+		panic(fmt.Sprintf("BUG: zstd.Encoder.Write for len(p)=%d returned unexpected error: %s", len(ctx.p), err))
+	}
+
+	releaseRealZstdWriter(zw, ctx.level)
+}
+
+func releaseRealZstdWriter(zw *zstd.Encoder, level int) {
+	zw.Close()
+	nLevel := normalizeZstdCompressLevel(level)
+	p := realZstdWriterPoolMap[nLevel]
+	p.Put(zw)
+}
+
+func acquireRealZstdWriter(w io.Writer, level int) *zstd.Encoder {
+	nLevel := normalizeZstdCompressLevel(level)
+	p := realZstdWriterPoolMap[nLevel]
+	v := p.Get()
+	if v == nil {
+		zw, err := zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.EncoderLevel(nLevel+CompressZstdSpeedFastest)),
+			zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(fmt.Sprintf("BUG: unexpected error from zstd.NewWriter(%d): %s", level, err))
+		}
+		return zw
+	}
+	zw := v.(*zstd.Encoder)
+	zw.Reset(w)
+	return zw
+}
+
+// WriteZstdLevel writes zstd compressed p to w using the given compression level
+// and returns the number of compressed bytes written to w.
+//
+// Supported compression levels are:
+//
+//   - CompressZstdSpeedFastest
+//   - CompressZstdSpeedDefault
+//   - CompressZstdSpeedBetter
+//   - CompressZstdBestCompression
+func WriteZstdLevel(w io.Writer, p []byte, level int) (int, error) {
+	switch w.(type) {
+	case *byteSliceWriter,
+		*bytes.Buffer,
+		*bytebufferpool.ByteBuffer:
+		// These writers don't block, so we can just use stacklessWriteZstd
+		ctx := &compressCtx{
+			w:     w,
+			p:     p,
+			level: level,
+		}
+		stacklessWriteZstd(ctx)
+		return len(p), nil
+	default:
+		zw := AcquireStacklessZstdWriter(w, level)
+		n, err := zw.Write(p)
+		ReleaseStacklessZstdWriter(zw, level)
+		return n, err
+	}
+}
+
+func AcquireStacklessZstdWriter(w io.Writer, level int) stackless.Writer {
+	nLevel := normalizeZstdCompressLevel(level)
+	p := stacklessZstdWriterPoolMap[nLevel]
+	v := p.Get()
+	if v == nil {
+		return stackless.NewWriter(w, func(w io.Writer) stackless.Writer {
+			return acquireRealZstdWriter(w, level)
+		})
+	}
+	sw := v.(stackless.Writer)
+	sw.Reset(w)
+	return sw
+}
+
+func ReleaseStacklessZstdWriter(sw stackless.Writer, level int) {
+	sw.Close()
+	nLevel := normalizeZstdCompressLevel(level)
+	p := stacklessZstdWriterPoolMap[nLevel]
+	p.Put(sw)
+}
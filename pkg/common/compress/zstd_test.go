@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestCompressAppendZstdBytes(t *testing.T) {
+	src := []byte(strings.Repeat("hello hertz ", 100))
+	for _, level := range []int{CompressZstdSpeedFastest, CompressZstdSpeedDefault, CompressZstdSpeedBetter, CompressZstdBestCompression, -1, 100} {
+		compressed := AppendZstdBytesLevel([]byte("prefix"), src, level)
+		assert.DeepEqual(t, "prefix", string(compressed[:6]))
+		assert.True(t, len(compressed)-6 < len(src))
+
+		decompressed, err := AppendUnzstdBytes([]byte("prefix"), compressed[6:])
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "prefix"+string(src), string(decompressed))
+	}
+
+	_, err := AppendUnzstdBytes(nil, []byte("invalid"))
+	assert.True(t, err != nil)
+}
+
+func TestCompressWriteZstdLevel(t *testing.T) {
+	src := []byte(strings.Repeat("hello hertz ", 100))
+
+	// test the non-blocking writer case
+	var buf bytes.Buffer
+	n, err := WriteZstdLevel(&buf, src, CompressZstdDefaultLevel)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, len(src), n)
+
+	// test the default case with the stackless writer
+	var w defaultByteWriter
+	n, err = WriteZstdLevel(&w, src, CompressZstdDefaultLevel)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, len(src), n)
+	assert.DeepEqual(t, buf.Bytes(), w.b)
+
+	var dst bytes.Buffer
+	n, err = WriteUnzstd(&dst, w.b)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, len(src), n)
+	assert.DeepEqual(t, string(src), dst.String())
+}
@@ -20,6 +20,9 @@ import (
 	"crypto/tls"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/app/client/circuitbreak"
+	"github.com/cloudwego/hertz/pkg/app/client/dns"
+	"github.com/cloudwego/hertz/pkg/app/client/ratelimit"
 	"github.com/cloudwego/hertz/pkg/app/client/retry"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
@@ -32,6 +35,14 @@ type ConnPoolState struct {
 	TotalConnNum int
 	// Number of pending connections
 	WaitConnNum int
+	// Number of connections which are being used by requests.
+	InUseConnNum int
+	// Total number of requests which have waited for a free connection.
+	WaitCount uint64
+	// Total time spent by requests waiting for a free connection.
+	WaitDuration time.Duration
+	// Total number of failed attempts to establish a connection.
+	DialFailures uint64
 	// HostClient Addr
 	Addr string
 }
@@ -61,6 +72,19 @@ type ClientOptions struct {
 	TLSConfig           *tls.Config
 	ResponseBodyStream  bool
 
+	// HostTLSConfigs are the TLS configs keyed by host patterns,
+	// which take the place of TLSConfig for the matched hosts.
+	HostTLSConfigs map[string]*tls.Config
+
+	// UnixSockets are the paths of unix domain sockets keyed by host names,
+	// the connections to the hosts are dialed to the sockets instead.
+	// The empty host name stands for all hosts.
+	UnixSockets map[string]string
+
+	// AddrOverrides are the addresses dialed instead of the hosts in the request URIs,
+	// keyed by host names or addresses with ports.
+	AddrOverrides map[string][]string
+
 	// Client name. Used in User-Agent request header.
 	//
 	// Default client name is used if not set.
@@ -88,6 +112,16 @@ type ClientOptions struct {
 	// By default request write timeout is unlimited.
 	WriteTimeout time.Duration
 
+	// Timeout for the TLS handshake of new connections,
+	// which is done along with the first write if not set.
+	TLSHandshakeTimeout time.Duration
+
+	// Timeout for waiting for the response after the request is written,
+	// ReadTimeout takes effect for the rest of the response.
+	//
+	// By default it is limited by ReadTimeout only.
+	ResponseHeaderTimeout time.Duration
+
 	// Maximum response body size.
 	//
 	// The client returns ErrBodyTooLarge if this limit is greater than 0
@@ -96,6 +130,15 @@ type ClientOptions struct {
 	// By default response body size is unlimited.
 	MaxResponseBodySize int
 
+	// Send Accept-Encoding header and decode gzip, br and zstd response bodies
+	// transparently if it is set to true.
+	AutoDecompress bool
+
+	// Maximum size of the decoded response body.
+	//
+	// By default MaxResponseBodySize is used.
+	MaxDecompressedBodySize int
+
 	// Header names are passed as-is without normalization
 	// if this option is set.
 	//
@@ -124,6 +167,15 @@ type ClientOptions struct {
 	// all configurations related to retry
 	RetryConfig *retry.Config
 
+	// all configurations related to circuit breaker
+	CircuitBreakerConfig *circuitbreak.Config
+
+	// all configurations related to rate limiting
+	RateLimitConfig *ratelimit.Config
+
+	// Resolver looks up the addresses of hosts before dialing
+	DNSResolver dns.Resolver
+
 	HostClientStateObserve HostClientStateFunc
 
 	// StateObserve execution interval
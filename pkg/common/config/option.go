@@ -38,40 +38,81 @@ const (
 	defaultNetwork            = "tcp"
 	defaultBasePath           = "/"
 	defaultMaxRequestBodySize = 4 * 1024 * 1024
+	defaultMaxHeaderBytes     = 1024 * 1024
 	defaultWaitExitTimeout    = time.Second * 5
 	defaultReadBufferSize     = 4 * 1024
+	defaultWriteBufferSize    = 4 * 1024
 )
 
+// ShutdownTimeouts are the deadlines of the phases of graceful shutdown, relative to
+// the beginning of shutdown. They are all bounded by ExitWaitTimeout.
+type ShutdownTimeouts struct {
+	// Accept is how long new connections are still accepted after shutdown begins.
+	Accept time.Duration
+	// Idle is when the keep-alive connections waiting for the next request are closed.
+	// Zero means they are closed as soon as shutdown begins.
+	Idle time.Duration
+	// Request is when the connections with the requests still in flight are closed.
+	// Zero means they are waited until ExitWaitTimeout.
+	Request time.Duration
+}
+
 type Options struct {
-	KeepAliveTimeout             time.Duration
-	ReadTimeout                  time.Duration
-	WriteTimeout                 time.Duration
-	IdleTimeout                  time.Duration
-	RedirectTrailingSlash        bool
-	MaxRequestBodySize           int
-	MaxKeepBodySize              int
-	GetOnly                      bool
-	DisableKeepalive             bool
-	RedirectFixedPath            bool
-	HandleMethodNotAllowed       bool
-	UseRawPath                   bool
-	RemoveExtraSlash             bool
-	UnescapePathValues           bool
-	DisablePreParseMultipartForm bool
-	StreamRequestBody            bool
-	NoDefaultServerHeader        bool
-	DisablePrintRoute            bool
-	Network                      string
-	Addr                         string
-	BasePath                     string
-	ExitWaitTimeout              time.Duration
-	TLS                          *tls.Config
-	H2C                          bool
-	ReadBufferSize               int
-	ALPN                         bool
-	Tracers                      []interface{}
-	TraceLevel                   interface{}
-	ListenConfig                 *net.ListenConfig
+	KeepAliveTimeout              time.Duration
+	ReadTimeout                   time.Duration
+	WriteTimeout                  time.Duration
+	IdleTimeout                   time.Duration
+	RedirectTrailingSlash         bool
+	MaxRequestBodySize            int
+	MaxHeaderBytes                int
+	MaxHeaderCount                int
+	MaxURLLength                  int
+	MaxRequestsPerConn            int
+	MaxConnAge                    time.Duration
+	MaxFormFields                 int
+	MaxFormKeyLength              int
+	MaxFormValueLength            int
+	MaxKeepBodySize               int
+	GetOnly                       bool
+	DisableKeepalive              bool
+	RedirectFixedPath             bool
+	HandleMethodNotAllowed        bool
+	UseRawPath                    bool
+	RemoveExtraSlash              bool
+	UnescapePathValues            bool
+	DisablePreParseMultipartForm  bool
+	StreamRequestBody             bool
+	NoDefaultServerHeader         bool
+	DisablePrintRoute             bool
+	DisableHeaderNamesNormalizing bool
+	StrictCookieParsing           bool
+	StrictRequestParsing          bool
+	QuerySemicolonSeparator       bool
+	QueryDisablePlusAsSpace       bool
+	QueryDuplicateKeyPolicy       int
+	Network                       string
+	Addr                          string
+	BasePath                      string
+	ExitWaitTimeout               time.Duration
+	TLS                           *tls.Config
+	H2C                           bool
+	ReadBufferSize                int
+	WriteBufferSize               int
+	BufferPool                    network.BufferPool
+	ConnBandwidth                 network.Bandwidth
+	TotalBandwidth                network.Bandwidth
+	ALPN                          bool
+	Tracers                       []interface{}
+	TraceLevel                    interface{}
+	ListenConfig                  *net.ListenConfig
+
+	// ShutdownTimeouts are the deadlines of the phases of graceful shutdown.
+	// The connections are not closed by the engine during shutdown if it is nil.
+	ShutdownTimeouts *ShutdownTimeouts
+
+	// Listener is the listener served by the transporter instead of the one
+	// listening on Network and Addr.
+	Listener net.Listener
 
 	// TransporterNewer is the function to create a transporter.
 	TransporterNewer    func(opt *Options) network.Transporter
@@ -86,6 +127,11 @@ type Options struct {
 	OnAccept  func(conn net.Conn) context.Context
 	OnConnect func(ctx context.Context, conn network.Conn) context.Context
 
+	// OnClose is called once the connection is closed, or its serving is finished if it is
+	// hijacked in go net. ctx is the one passed to the handlers, which carries the
+	// network.ConnStats of the connection.
+	OnClose func(ctx context.Context, conn network.Conn)
+
 	// Registry is used for service registry.
 	Registry registry.Registry
 	// RegistryInfo is base info used for service registry.
@@ -188,6 +234,24 @@ func NewOptions(opts []Option) *Options {
 		// an error will be returned
 		MaxRequestBodySize: defaultMaxRequestBodySize,
 
+		// Define the max size of the request line and headers, the max number of headers
+		// and the max length of the request uri, 0 means no limit.
+		// 431 or 414 is returned if one of them is exceeded.
+		MaxHeaderBytes: defaultMaxHeaderBytes,
+		MaxHeaderCount: 0,
+		MaxURLLength:   0,
+
+		// Close the keep-alive connection after the number of requests or the duration,
+		// 0 means no limit.
+		MaxRequestsPerConn: 0,
+		MaxConnAge:         0,
+
+		// Define the max number of form fields, the max length of form keys and values,
+		// 0 means no limit. 413 or 400 is returned if one of them is exceeded.
+		MaxFormFields:      0,
+		MaxFormKeyLength:   0,
+		MaxFormValueLength: 0,
+
 		// max reserved body buffer size when reset Request & Request
 		// If the body size exceeds this value, then the buffer won't be put to
 		// sync.Pool to prevent OOM
@@ -212,6 +276,9 @@ func NewOptions(opts []Option) *Options {
 		// Set init read buffer size. Usually there is no need to set it.
 		ReadBufferSize: defaultReadBufferSize,
 
+		// Set the min size of the buffers allocated for writing.
+		WriteBufferSize: defaultWriteBufferSize,
+
 		// ALPN switch
 		ALPN: false,
 
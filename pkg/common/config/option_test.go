@@ -48,6 +48,8 @@ func TestDefaultOptions(t *testing.T) {
 	assert.DeepEqual(t, defaultWaitExitTimeout, options.ExitWaitTimeout)
 	assert.Nil(t, options.TLS)
 	assert.DeepEqual(t, defaultReadBufferSize, options.ReadBufferSize)
+	assert.DeepEqual(t, defaultWriteBufferSize, options.WriteBufferSize)
+	assert.Nil(t, options.BufferPool)
 	assert.False(t, options.ALPN)
 	assert.False(t, options.H2C)
 	assert.DeepEqual(t, []interface{}{}, options.Tracers)
@@ -24,9 +24,15 @@ type RequestOptions struct {
 	tags map[string]string
 	isSD bool
 
-	dialTimeout  time.Duration
-	readTimeout  time.Duration
-	writeTimeout time.Duration
+	dialTimeout           time.Duration
+	readTimeout           time.Duration
+	writeTimeout          time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+
+	rateLimitKey string
+
+	maxResponseBodySize int
 }
 
 // RequestOption is the only struct to set request-level options.
@@ -95,6 +101,48 @@ func WithWriteTimeout(t time.Duration) RequestOption {
 	}}
 }
 
+// WithTLSHandshakeTimeout sets the timeout of the TLS handshake of new connections.
+//
+// This is the request level configuration. It has a higher
+// priority than the client level configuration
+func WithTLSHandshakeTimeout(t time.Duration) RequestOption {
+	return RequestOption{F: func(o *RequestOptions) {
+		o.tlsHandshakeTimeout = t
+	}}
+}
+
+// WithResponseHeaderTimeout sets the timeout of waiting for the response
+// after the request is written.
+//
+// This is the request level configuration. It has a higher
+// priority than the client level configuration
+func WithResponseHeaderTimeout(t time.Duration) RequestOption {
+	return RequestOption{F: func(o *RequestOptions) {
+		o.responseHeaderTimeout = t
+	}}
+}
+
+// WithRateLimitKey sets the key whose rate of requests is limited,
+// which takes the place of the host of the request.
+func WithRateLimitKey(key string) RequestOption {
+	return RequestOption{F: func(o *RequestOptions) {
+		o.rateLimitKey = key
+	}}
+}
+
+// WithMaxResponseBodySize sets the max size of the response body,
+// reading the response is aborted with ErrBodyTooLarge once the body exceeds it.
+//
+// This is the request level configuration. It has a higher
+// priority than the client level configuration
+// Note: in the stream mode of the client, the body beyond it
+// is streamed instead, as the client level one does.
+func WithMaxResponseBodySize(n int) RequestOption {
+	return RequestOption{F: func(o *RequestOptions) {
+		o.maxResponseBodySize = n
+	}}
+}
+
 func (o *RequestOptions) Apply(opts []RequestOption) {
 	for _, op := range opts {
 		op.F(o)
@@ -125,6 +173,22 @@ func (o *RequestOptions) WriteTimeout() time.Duration {
 	return o.writeTimeout
 }
 
+func (o *RequestOptions) TLSHandshakeTimeout() time.Duration {
+	return o.tlsHandshakeTimeout
+}
+
+func (o *RequestOptions) ResponseHeaderTimeout() time.Duration {
+	return o.responseHeaderTimeout
+}
+
+func (o *RequestOptions) RateLimitKey() string {
+	return o.rateLimitKey
+}
+
+func (o *RequestOptions) MaxResponseBodySize() int {
+	return o.maxResponseBodySize
+}
+
 func (o *RequestOptions) CopyTo(dst *RequestOptions) {
 	if dst.tags == nil {
 		dst.tags = make(map[string]string)
@@ -138,6 +202,45 @@ func (o *RequestOptions) CopyTo(dst *RequestOptions) {
 	dst.readTimeout = o.readTimeout
 	dst.writeTimeout = o.writeTimeout
 	dst.dialTimeout = o.dialTimeout
+	dst.tlsHandshakeTimeout = o.tlsHandshakeTimeout
+	dst.responseHeaderTimeout = o.responseHeaderTimeout
+	dst.rateLimitKey = o.rateLimitKey
+	dst.maxResponseBodySize = o.maxResponseBodySize
+}
+
+// Inherit sets the options of o which are not set from parent,
+// the tags of o take precedence over the ones of parent.
+func (o *RequestOptions) Inherit(parent *RequestOptions) {
+	if o.tags == nil {
+		o.tags = make(map[string]string)
+	}
+	for k, v := range parent.tags {
+		if _, ok := o.tags[k]; !ok {
+			o.tags[k] = v
+		}
+	}
+	o.isSD = o.isSD || parent.isSD
+	if o.dialTimeout == 0 {
+		o.dialTimeout = parent.dialTimeout
+	}
+	if o.readTimeout == 0 {
+		o.readTimeout = parent.readTimeout
+	}
+	if o.writeTimeout == 0 {
+		o.writeTimeout = parent.writeTimeout
+	}
+	if o.tlsHandshakeTimeout == 0 {
+		o.tlsHandshakeTimeout = parent.tlsHandshakeTimeout
+	}
+	if o.responseHeaderTimeout == 0 {
+		o.responseHeaderTimeout = parent.responseHeaderTimeout
+	}
+	if o.rateLimitKey == "" {
+		o.rateLimitKey = parent.rateLimitKey
+	}
+	if o.maxResponseBodySize == 0 {
+		o.maxResponseBodySize = parent.maxResponseBodySize
+	}
 }
 
 // SetPreDefinedOpts Pre define some RequestOption here
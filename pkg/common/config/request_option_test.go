@@ -33,6 +33,10 @@ func TestRequestOptions(t *testing.T) {
 		WithDialTimeout(time.Second),
 		WithReadTimeout(time.Second),
 		WithWriteTimeout(time.Second),
+		WithTLSHandshakeTimeout(2 * time.Second),
+		WithResponseHeaderTimeout(3 * time.Second),
+		WithRateLimitKey("tenant"),
+		WithMaxResponseBodySize(1024),
 	})
 	assert.DeepEqual(t, "b", opt.Tag("a"))
 	assert.DeepEqual(t, "d", opt.Tag("c"))
@@ -40,6 +44,10 @@ func TestRequestOptions(t *testing.T) {
 	assert.DeepEqual(t, time.Second, opt.DialTimeout())
 	assert.DeepEqual(t, time.Second, opt.ReadTimeout())
 	assert.DeepEqual(t, time.Second, opt.WriteTimeout())
+	assert.DeepEqual(t, 2*time.Second, opt.TLSHandshakeTimeout())
+	assert.DeepEqual(t, 3*time.Second, opt.ResponseHeaderTimeout())
+	assert.DeepEqual(t, "tenant", opt.RateLimitKey())
+	assert.DeepEqual(t, 1024, opt.MaxResponseBodySize())
 	assert.True(t, opt.IsSD())
 }
 
@@ -75,3 +83,24 @@ func TestRequestOptions_CopyTo(t *testing.T) {
 	assert.DeepEqual(t, opt.Tags(), copyOpt.Tags())
 	assert.DeepEqual(t, opt.IsSD(), copyOpt.IsSD())
 }
+
+func TestRequestOptionsInherit(t *testing.T) {
+	parent := NewRequestOptions([]RequestOption{
+		WithTag("a", "parent"),
+		WithTag("b", "parent"),
+		WithReadTimeout(time.Second),
+		WithWriteTimeout(time.Second),
+		WithMaxResponseBodySize(1024),
+	})
+	opt := NewRequestOptions([]RequestOption{
+		WithTag("a", "child"),
+		WithReadTimeout(2 * time.Second),
+	})
+	opt.Inherit(parent)
+	assert.DeepEqual(t, map[string]string{"a": "child", "b": "parent"}, opt.Tags())
+	assert.DeepEqual(t, 2*time.Second, opt.ReadTimeout())
+	assert.DeepEqual(t, time.Second, opt.WriteTimeout())
+	assert.DeepEqual(t, time.Duration(0), opt.DialTimeout())
+	assert.DeepEqual(t, 1024, opt.MaxResponseBodySize())
+	assert.False(t, opt.IsSD())
+}
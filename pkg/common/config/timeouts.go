@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts holds the read and idle timeouts of a running server,
+// which can be changed concurrently while the server is serving requests.
+//
+// NOTE:
+//
+//	The new timeouts take effect on the coming requests of the connections,
+//	the pending reads keep the timeouts they were started with.
+type Timeouts struct {
+	read int64
+	idle int64
+}
+
+// NewTimeouts returns the Timeouts with the initial read and idle timeouts.
+func NewTimeouts(read, idle time.Duration) *Timeouts {
+	return &Timeouts{read: int64(read), idle: int64(idle)}
+}
+
+// Read returns the read timeout.
+func (t *Timeouts) Read() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.read))
+}
+
+// SetRead sets the read timeout, zero means no timeout.
+func (t *Timeouts) SetRead(d time.Duration) {
+	atomic.StoreInt64(&t.read, int64(d))
+}
+
+// Idle returns the idle timeout.
+func (t *Timeouts) Idle() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.idle))
+}
+
+// SetIdle sets the idle timeout of the keep-alive connections.
+//
+// NOTE:
+//
+//	Whether the idle connections are handed back to the network layer is decided when
+//	the server starts by the initial idle timeout, so setting it to zero or from zero has no effect.
+func (t *Timeouts) SetIdle(d time.Duration) {
+	atomic.StoreInt64(&t.idle, int64(d))
+}
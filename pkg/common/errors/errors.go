@@ -52,6 +52,10 @@ var (
 	ErrNeedMore           = errors.New("need more data")
 	ErrChunkedStream      = errors.New("chunked stream")
 	ErrBodyTooLarge       = errors.New("body size exceeds the given limit")
+	ErrHeaderTooLarge     = errors.New("header size exceeds the given limit")
+	ErrURLTooLong         = errors.New("request uri length exceeds the given limit")
+	ErrFormTooLarge       = errors.New("form fields or values exceed the given limit")
+	ErrFormKeyTooLong     = errors.New("form key length exceeds the given limit")
 	ErrHijacked           = errors.New("connection has been hijacked")
 	ErrIdleTimeout        = errors.New("idle timeout")
 	ErrTimeout            = errors.New("timeout")
@@ -61,6 +65,8 @@ var (
 	ErrNothingRead        = errors.New("nothing read")
 	ErrShortConnection    = errors.New("short connection")
 	ErrNoFreeConns        = errors.New("no free connections available to host")
+	ErrCircuitOpen        = errors.New("circuit breaker is open")
+	ErrRateLimited        = errors.New("rate limit exceeded")
 	ErrConnectionClosed   = errors.New("connection closed")
 	ErrNotSupportProtocol = errors.New("not support protocol")
 )
@@ -0,0 +1,264 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package errreport provides a single place to hook error reporting services
+// into hertz. The registered reporters are notified of the panics recovered by
+// the engine or the recovery middleware, of the responses with 5xx status codes
+// and of the internal errors of the framework, e.g.
+//
+//	errreport.Register(errreport.ReporterFunc(func(c context.Context, r *errreport.Report) {
+//		sentry.CaptureException(r.Err)
+//	}))
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// Kind is the kind of error being reported.
+type Kind int
+
+const (
+	// KindPanic is a panic recovered while handling a request.
+	KindPanic Kind = iota + 1
+	// KindServerError is a response with a 5xx status code.
+	KindServerError
+	// KindInternal is an error raised by the framework outside the handlers,
+	// e.g. a failure to read or write a connection.
+	KindInternal
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPanic:
+		return "panic"
+	case KindServerError:
+		return "server_error"
+	case KindInternal:
+		return "internal"
+	}
+	return "unknown"
+}
+
+// Redacted replaces the values of the sensitive headers in RequestSnapshot.
+const Redacted = "[REDACTED]"
+
+// Report describes an error to be reported.
+type Report struct {
+	Kind Kind
+	Time time.Time
+	Err  error
+	// Panic is the value recovered from the panic if Kind == KindPanic.
+	Panic interface{}
+	// Stack is the stack of the goroutine where the panic happened, it may be empty.
+	Stack []byte
+	// Request is nil if the error is not bound to a request.
+	Request *RequestSnapshot
+	// RemoteAddr is the address of the peer, set for internal errors as well.
+	RemoteAddr string
+}
+
+// RequestSnapshot is a copy of the request information taken when the error
+// is reported, it can be retained after the request is finished.
+type RequestSnapshot struct {
+	Method     string
+	URI        string
+	Route      string
+	Host       string
+	ClientIP   string
+	Header     map[string][]string
+	StatusCode int
+	// Errors are the errors attached to the RequestContext.
+	Errors []string
+}
+
+// Reporter receives the reported errors.
+//
+// NOTE:
+//
+//	Report is called synchronously on the goroutine serving the request,
+//	implementations should hand the report over to a background worker if sending it is slow.
+type Reporter interface {
+	Report(c context.Context, r *Report)
+}
+
+// ReporterFunc is an adapter to allow the use of ordinary functions as Reporter.
+type ReporterFunc func(c context.Context, r *Report)
+
+// Report implements Reporter.
+func (f ReporterFunc) Report(c context.Context, r *Report) {
+	f(c, r)
+}
+
+var (
+	// reportersLock serializes the updates of reporters,
+	// which holds a []Reporter replaced as a whole so that it's loaded without locking.
+	reportersLock sync.Mutex
+	reporters     atomic.Value
+
+	sensitiveHeaders = map[string]bool{
+		strings.ToLower(consts.HeaderAuthorization):      true,
+		strings.ToLower(consts.HeaderProxyAuthorization): true,
+		strings.ToLower(consts.HeaderCookie):             true,
+		strings.ToLower(consts.HeaderSetCookie):          true,
+	}
+)
+
+// Register adds r to the reporters notified of errors.
+func Register(r Reporter) {
+	if r == nil {
+		panic("errreport: Register reporter is nil")
+	}
+	reportersLock.Lock()
+	rs := loadReporters()
+	reporters.Store(append(rs[:len(rs):len(rs)], r))
+	reportersLock.Unlock()
+}
+
+// Reset removes all registered reporters.
+func Reset() {
+	reportersLock.Lock()
+	reporters.Store([]Reporter(nil))
+	reportersLock.Unlock()
+}
+
+// Enabled reports whether any reporter is registered.
+func Enabled() bool {
+	return len(loadReporters()) > 0
+}
+
+func loadReporters() []Reporter {
+	rs, _ := reporters.Load().([]Reporter)
+	return rs
+}
+
+// ReportPanic reports the value recovered from a panic while handling ctx.
+// If stack is empty, the stack of the calling goroutine is captured.
+func ReportPanic(c context.Context, ctx *app.RequestContext, recovered interface{}, stack []byte) {
+	if !Enabled() {
+		return
+	}
+	if len(stack) == 0 {
+		stack = debug.Stack()
+	}
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+	ctx.MarkErrorReported()
+	dispatch(c, &Report{
+		Kind:       KindPanic,
+		Time:       time.Now(),
+		Err:        err,
+		Panic:      recovered,
+		Stack:      stack,
+		Request:    Snapshot(ctx),
+		RemoteAddr: ctx.RemoteAddr().String(),
+	})
+}
+
+// ReportServerError reports the response of ctx if its status code is 5xx,
+// and it has not been reported as a panic.
+func ReportServerError(c context.Context, ctx *app.RequestContext) {
+	if !Enabled() {
+		return
+	}
+	status := ctx.Response.StatusCode()
+	if status < consts.StatusInternalServerError {
+		return
+	}
+	if !ctx.MarkErrorReported() {
+		return
+	}
+	var err error
+	if last := ctx.Errors.Last(); last != nil {
+		err = last
+	} else {
+		err = fmt.Errorf("%d %s", status, consts.StatusMessage(status))
+	}
+	dispatch(c, &Report{
+		Kind:       KindServerError,
+		Time:       time.Now(),
+		Err:        err,
+		Request:    Snapshot(ctx),
+		RemoteAddr: ctx.RemoteAddr().String(),
+	})
+}
+
+// ReportInternal reports an internal error of the framework which is not bound to a request.
+func ReportInternal(c context.Context, err error, remoteAddr string) {
+	if err == nil || !Enabled() {
+		return
+	}
+	dispatch(c, &Report{
+		Kind:       KindInternal,
+		Time:       time.Now(),
+		Err:        err,
+		RemoteAddr: remoteAddr,
+	})
+}
+
+// Snapshot copies the request information of ctx, the values of the
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie headers are redacted.
+func Snapshot(ctx *app.RequestContext) *RequestSnapshot {
+	s := &RequestSnapshot{
+		Method:     string(ctx.Method()),
+		URI:        ctx.URI().String(),
+		Route:      ctx.FullPath(),
+		Host:       string(ctx.Host()),
+		ClientIP:   ctx.ClientIP(),
+		Header:     make(map[string][]string),
+		StatusCode: ctx.Response.StatusCode(),
+	}
+	ctx.Request.Header.VisitAll(func(k, v []byte) {
+		key := string(k)
+		val := string(v)
+		if sensitiveHeaders[strings.ToLower(key)] {
+			val = Redacted
+		}
+		s.Header[key] = append(s.Header[key], val)
+	})
+	if len(ctx.Errors) > 0 {
+		s.Errors = ctx.Errors.Errors()
+	}
+	return s
+}
+
+func dispatch(c context.Context, r *Report) {
+	for _, reporter := range loadReporters() {
+		call(c, reporter, r)
+	}
+}
+
+// call isolates the panics of reporter from the caller.
+func call(c context.Context, reporter Reporter, r *Report) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			hlog.SystemLogger().CtxErrorf(c, "Error reporter panicked: %v", rcv)
+		}
+	}()
+	reporter.Report(c, r)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errreport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func collect(t *testing.T) *[]*Report {
+	var reports []*Report
+	Reset()
+	t.Cleanup(Reset)
+	Register(ReporterFunc(func(c context.Context, r *Report) {
+		reports = append(reports, r)
+	}))
+	return &reports
+}
+
+func newContext() *app.RequestContext {
+	ctx := app.NewContext(0)
+	ctx.Request.SetRequestURI("http://example.com/users/1?x=1")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("Authorization", "Bearer secret")
+	ctx.Request.Header.Set("X-Custom", "value")
+	ctx.SetFullPath("/users/:id")
+	return ctx
+}
+
+func TestDisabled(t *testing.T) {
+	Reset()
+	assert.False(t, Enabled())
+	ctx := newContext()
+	ctx.SetStatusCode(500)
+	// nothing happens without reporters
+	ReportServerError(context.Background(), ctx)
+	ReportPanic(context.Background(), ctx, "boom", nil)
+	assert.True(t, ctx.MarkErrorReported())
+}
+
+func TestReportPanic(t *testing.T) {
+	reports := collect(t)
+	ctx := newContext()
+	ReportPanic(context.Background(), ctx, "boom", nil)
+
+	assert.DeepEqual(t, 1, len(*reports))
+	r := (*reports)[0]
+	assert.DeepEqual(t, KindPanic, r.Kind)
+	assert.DeepEqual(t, "boom", r.Panic)
+	assert.DeepEqual(t, "panic: boom", r.Err.Error())
+	assert.True(t, len(r.Stack) > 0)
+	assert.DeepEqual(t, "POST", r.Request.Method)
+	assert.DeepEqual(t, "http://example.com/users/1?x=1", r.Request.URI)
+	assert.DeepEqual(t, "/users/:id", r.Request.Route)
+	assert.DeepEqual(t, []string{Redacted}, r.Request.Header["Authorization"])
+	assert.DeepEqual(t, []string{"value"}, r.Request.Header["X-Custom"])
+
+	// the panic turned into a 500 is not reported again
+	ctx.SetStatusCode(500)
+	ReportServerError(context.Background(), ctx)
+	assert.DeepEqual(t, 1, len(*reports))
+}
+
+func TestReportServerError(t *testing.T) {
+	reports := collect(t)
+	ctx := newContext()
+	ctx.SetStatusCode(404)
+	ReportServerError(context.Background(), ctx)
+	assert.DeepEqual(t, 0, len(*reports))
+
+	ctx.SetStatusCode(503)
+	ReportServerError(context.Background(), ctx)
+	assert.DeepEqual(t, 1, len(*reports))
+	assert.DeepEqual(t, KindServerError, (*reports)[0].Kind)
+	assert.DeepEqual(t, "503 Service Unavailable", (*reports)[0].Err.Error())
+	assert.DeepEqual(t, 503, (*reports)[0].Request.StatusCode)
+
+	ctx = newContext()
+	ctx.SetStatusCode(500)
+	ctx.Error(errors.New("db is down")) // nolint:errcheck
+	ReportServerError(context.Background(), ctx)
+	assert.DeepEqual(t, 2, len(*reports))
+	assert.DeepEqual(t, "db is down", (*reports)[1].Err.Error())
+	assert.DeepEqual(t, []string{"db is down"}, (*reports)[1].Request.Errors)
+}
+
+func TestReportInternal(t *testing.T) {
+	reports := collect(t)
+	// the panic of a reporter does not affect the others
+	Register(ReporterFunc(func(c context.Context, r *Report) {
+		panic("reporter")
+	}))
+	Register(ReporterFunc(func(c context.Context, r *Report) {
+		*reports = append(*reports, r)
+	}))
+	ReportInternal(context.Background(), nil, "")
+	assert.DeepEqual(t, 0, len(*reports))
+
+	ReportInternal(context.Background(), errors.New("read timeout"), "127.0.0.1:1234")
+	assert.DeepEqual(t, 2, len(*reports))
+	assert.DeepEqual(t, KindInternal, (*reports)[1].Kind)
+	assert.DeepEqual(t, "127.0.0.1:1234", (*reports)[1].RemoteAddr)
+	assert.Nil(t, (*reports)[1].Request)
+}
@@ -17,11 +17,18 @@
 package hlog
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Fatal calls the default logger's Fatal method and then os.Exit(1).
@@ -130,115 +137,187 @@ func CtxTracef(ctx context.Context, format string, v ...interface{}) {
 }
 
 type defaultLogger struct {
-	stdlog *log.Logger
-	level  Level
-	depth  int
+	stdlog    *log.Logger
+	level     int32
+	depth     int
+	formatter Formatter
+	fields    map[string]interface{}
 }
 
+// outputLock serializes the formatted logs written to the outputs,
+// which are shared by the loggers derived by With and WithFields.
+var outputLock sync.Mutex
+
 func (ll *defaultLogger) SetOutput(w io.Writer) {
 	ll.stdlog.SetOutput(w)
 }
 
 func (ll *defaultLogger) SetLevel(lv Level) {
-	ll.level = lv
+	atomic.StoreInt32(&ll.level, int32(lv))
+}
+
+func (ll *defaultLogger) getLevel() Level {
+	return Level(atomic.LoadInt32(&ll.level))
+}
+
+// SetFormatter sets the formatter of the logs, the plain text format is used if f==nil.
+func (ll *defaultLogger) SetFormatter(f Formatter) {
+	ll.formatter = f
 }
 
-func (ll *defaultLogger) logf(lv Level, format *string, v ...interface{}) {
-	if ll.level > lv {
+// WithFields returns a logger carrying fields in addition to the fields of ll,
+// which shares the output of ll and inherits the level and formatter of ll at the time of the call.
+func (ll *defaultLogger) WithFields(fields map[string]interface{}) FullLogger {
+	return &defaultLogger{
+		stdlog:    ll.stdlog,
+		level:     int32(ll.getLevel()),
+		depth:     ll.depth,
+		formatter: ll.formatter,
+		fields:    mergeFields(ll.fields, fields),
+	}
+}
+
+// With is like WithFields but takes the fields as alternating keys and values.
+func (ll *defaultLogger) With(kv ...interface{}) FullLogger {
+	return ll.WithFields(kvToFields(kv))
+}
+
+func (ll *defaultLogger) logf(ctx context.Context, lv Level, format *string, v ...interface{}) {
+	if ll.getLevel() > lv {
 		return
 	}
-	msg := lv.toString()
-	if format != nil {
-		msg += fmt.Sprintf(*format, v...)
+	fields := mergeFields(ll.fields, FieldsFromContext(ctx))
+	if ll.formatter != nil {
+		var msg string
+		if format != nil {
+			msg = fmt.Sprintf(*format, v...)
+		} else {
+			msg = fmt.Sprint(v...)
+		}
+		ll.output(lv, msg, fields)
 	} else {
-		msg += fmt.Sprint(v...)
+		msg := lv.toString()
+		if format != nil {
+			msg += fmt.Sprintf(*format, v...)
+		} else {
+			msg += fmt.Sprint(v...)
+		}
+		ll.stdlog.Output(ll.depth, appendTextFields(msg, fields))
 	}
-	ll.stdlog.Output(ll.depth, msg)
 	if lv == LevelFatal {
 		os.Exit(1)
 	}
 }
 
+var bufferPool = sync.Pool{New: func() interface{} {
+	return &bytes.Buffer{}
+}}
+
+// output writes the log formatted by the formatter to the output of stdlog.
+func (ll *defaultLogger) output(lv Level, msg string, fields map[string]interface{}) {
+	e := &Entry{Time: time.Now(), Level: lv, Message: msg, Fields: fields}
+	// output is called in the place of stdlog.Output, so the same depth is skipped
+	if _, file, line, ok := runtime.Caller(ll.depth); ok {
+		e.Caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		bufferPool.Put(buf)
+	}()
+	if err := ll.formatter.Format(buf, e); err != nil {
+		buf.Reset()
+		buf.WriteString(lv.toString() + msg + " (format error: " + err.Error() + ")")
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	outputLock.Lock()
+	ll.stdlog.Writer().Write(buf.Bytes())
+	outputLock.Unlock()
+}
+
 func (ll *defaultLogger) Fatal(v ...interface{}) {
-	ll.logf(LevelFatal, nil, v...)
+	ll.logf(context.Background(), LevelFatal, nil, v...)
 }
 
 func (ll *defaultLogger) Error(v ...interface{}) {
-	ll.logf(LevelError, nil, v...)
+	ll.logf(context.Background(), LevelError, nil, v...)
 }
 
 func (ll *defaultLogger) Warn(v ...interface{}) {
-	ll.logf(LevelWarn, nil, v...)
+	ll.logf(context.Background(), LevelWarn, nil, v...)
 }
 
 func (ll *defaultLogger) Notice(v ...interface{}) {
-	ll.logf(LevelNotice, nil, v...)
+	ll.logf(context.Background(), LevelNotice, nil, v...)
 }
 
 func (ll *defaultLogger) Info(v ...interface{}) {
-	ll.logf(LevelInfo, nil, v...)
+	ll.logf(context.Background(), LevelInfo, nil, v...)
 }
 
 func (ll *defaultLogger) Debug(v ...interface{}) {
-	ll.logf(LevelDebug, nil, v...)
+	ll.logf(context.Background(), LevelDebug, nil, v...)
 }
 
 func (ll *defaultLogger) Trace(v ...interface{}) {
-	ll.logf(LevelTrace, nil, v...)
+	ll.logf(context.Background(), LevelTrace, nil, v...)
 }
 
 func (ll *defaultLogger) Fatalf(format string, v ...interface{}) {
-	ll.logf(LevelFatal, &format, v...)
+	ll.logf(context.Background(), LevelFatal, &format, v...)
 }
 
 func (ll *defaultLogger) Errorf(format string, v ...interface{}) {
-	ll.logf(LevelError, &format, v...)
+	ll.logf(context.Background(), LevelError, &format, v...)
 }
 
 func (ll *defaultLogger) Warnf(format string, v ...interface{}) {
-	ll.logf(LevelWarn, &format, v...)
+	ll.logf(context.Background(), LevelWarn, &format, v...)
 }
 
 func (ll *defaultLogger) Noticef(format string, v ...interface{}) {
-	ll.logf(LevelNotice, &format, v...)
+	ll.logf(context.Background(), LevelNotice, &format, v...)
 }
 
 func (ll *defaultLogger) Infof(format string, v ...interface{}) {
-	ll.logf(LevelInfo, &format, v...)
+	ll.logf(context.Background(), LevelInfo, &format, v...)
 }
 
 func (ll *defaultLogger) Debugf(format string, v ...interface{}) {
-	ll.logf(LevelDebug, &format, v...)
+	ll.logf(context.Background(), LevelDebug, &format, v...)
 }
 
 func (ll *defaultLogger) Tracef(format string, v ...interface{}) {
-	ll.logf(LevelTrace, &format, v...)
+	ll.logf(context.Background(), LevelTrace, &format, v...)
 }
 
 func (ll *defaultLogger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelFatal, &format, v...)
+	ll.logf(ctx, LevelFatal, &format, v...)
 }
 
 func (ll *defaultLogger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelError, &format, v...)
+	ll.logf(ctx, LevelError, &format, v...)
 }
 
 func (ll *defaultLogger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelWarn, &format, v...)
+	ll.logf(ctx, LevelWarn, &format, v...)
 }
 
 func (ll *defaultLogger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelNotice, &format, v...)
+	ll.logf(ctx, LevelNotice, &format, v...)
 }
 
 func (ll *defaultLogger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelInfo, &format, v...)
+	ll.logf(ctx, LevelInfo, &format, v...)
 }
 
 func (ll *defaultLogger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelDebug, &format, v...)
+	ll.logf(ctx, LevelDebug, &format, v...)
 }
 
 func (ll *defaultLogger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelTrace, &format, v...)
+	ll.logf(ctx, LevelTrace, &format, v...)
 }
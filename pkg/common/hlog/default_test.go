@@ -114,34 +114,34 @@ func TestSetLevel(t *testing.T) {
 	}
 
 	setLogger.SetLevel(LevelTrace)
-	assert.DeepEqual(t, LevelTrace, setLogger.level)
-	assert.DeepEqual(t, LevelTrace.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelTrace, setLogger.getLevel())
+	assert.DeepEqual(t, LevelTrace.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelDebug)
-	assert.DeepEqual(t, LevelDebug, setLogger.level)
-	assert.DeepEqual(t, LevelDebug.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelDebug, setLogger.getLevel())
+	assert.DeepEqual(t, LevelDebug.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelInfo)
-	assert.DeepEqual(t, LevelInfo, setLogger.level)
-	assert.DeepEqual(t, LevelInfo.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelInfo, setLogger.getLevel())
+	assert.DeepEqual(t, LevelInfo.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelNotice)
-	assert.DeepEqual(t, LevelNotice, setLogger.level)
-	assert.DeepEqual(t, LevelNotice.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelNotice, setLogger.getLevel())
+	assert.DeepEqual(t, LevelNotice.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelWarn)
-	assert.DeepEqual(t, LevelWarn, setLogger.level)
-	assert.DeepEqual(t, LevelWarn.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelWarn, setLogger.getLevel())
+	assert.DeepEqual(t, LevelWarn.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelError)
-	assert.DeepEqual(t, LevelError, setLogger.level)
-	assert.DeepEqual(t, LevelError.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelError, setLogger.getLevel())
+	assert.DeepEqual(t, LevelError.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(LevelFatal)
-	assert.DeepEqual(t, LevelFatal, setLogger.level)
-	assert.DeepEqual(t, LevelFatal.toString(), setLogger.level.toString())
+	assert.DeepEqual(t, LevelFatal, setLogger.getLevel())
+	assert.DeepEqual(t, LevelFatal.toString(), setLogger.getLevel().toString())
 
 	setLogger.SetLevel(7)
-	assert.DeepEqual(t, 7, int(setLogger.level))
-	assert.DeepEqual(t, "[?7] ", setLogger.level.toString())
+	assert.DeepEqual(t, 7, int(setLogger.getLevel()))
+	assert.DeepEqual(t, "[?7] ", setLogger.getLevel().toString())
 }
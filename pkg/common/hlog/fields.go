@@ -0,0 +1,168 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hlog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// badKey is the key of the value without a key in the alternating keys and values.
+const badKey = "!BADKEY"
+
+type fieldsKey struct{}
+
+// ContextExtractor extracts the fields from the context, e.g. the request ID and the trace ID,
+// which are output by the Ctx methods of the built-in loggers.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+var extractors []ContextExtractor
+
+// RegisterContextExtractor registers the extractor of the fields added to the logs of the Ctx methods,
+// so that the logs can be correlated by request without adding the fields by hand, e.g.
+//
+//	hlog.RegisterContextExtractor(trace.LogFields)
+//	hlog.RegisterContextExtractor(requestid.LogFields)
+//
+// NOTE:
+//
+//	The fields added by ContextWithFields take precedence over the extracted ones,
+//	and the fields extracted by the extractor registered later take precedence over the earlier ones.
+//	Note that this method is not concurrent-safe and must be called before logging, e.g. in init.
+func RegisterContextExtractor(e ContextExtractor) {
+	extractors = append(extractors, e)
+}
+
+// WithFields returns a logger derived from the default logger carrying fields, e.g.
+//
+//	l := hlog.WithFields(map[string]interface{}{"user": "foo"})
+//	l.Infof("login from %s", ip)
+//
+// NOTE:
+//
+//	If the default logger does not implement FieldLogger, it is returned as is and fields are dropped.
+func WithFields(fields map[string]interface{}) FullLogger {
+	if fl, ok := logger.(FieldLogger); ok {
+		return fl.WithFields(fields)
+	}
+	return logger
+}
+
+// With is like WithFields but takes the fields as alternating keys and values, e.g.
+//
+//	hlog.With("user", "foo", "retry", 3).Warn("login failed")
+func With(kv ...interface{}) FullLogger {
+	if fl, ok := logger.(FieldLogger); ok {
+		return fl.With(kv...)
+	}
+	return logger
+}
+
+// ContextWithFields returns a copy of ctx carrying the fields as alternating keys and values
+// in addition to the ones carried by ctx, which are output by the Ctx methods of the built-in loggers, e.g.
+//
+//	ctx = hlog.ContextWithFields(ctx, "request_id", id)
+//	hlog.CtxInfof(ctx, "starting work")
+func ContextWithFields(ctx context.Context, kv ...interface{}) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, mergeFields(carriedFields(ctx), kvToFields(kv)))
+}
+
+// FieldsFromContext returns the fields carried by ctx and extracted by the registered extractors,
+// which must not be modified. It is meant for the adapters of FullLogger to output the same fields
+// as the built-in loggers.
+func FieldsFromContext(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	for _, e := range extractors {
+		fields = mergeFields(fields, e(ctx))
+	}
+	return mergeFields(fields, carriedFields(ctx))
+}
+
+func carriedFields(ctx context.Context) map[string]interface{} {
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	return fields
+}
+
+// mergeFields returns the union of a and b, the values of b take precedence.
+// a or b is returned as is if the other one is empty.
+func mergeFields(a, b map[string]interface{}) map[string]interface{} {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	ret := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range a {
+		ret[k] = v
+	}
+	for k, v := range b {
+		ret[k] = v
+	}
+	return ret
+}
+
+func kvToFields(kv []interface{}) map[string]interface{} {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields[badKey] = kv[i]
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields[key] = kv[i+1]
+	}
+	return fields
+}
+
+// appendTextFields appends the fields to the plain text msg as "key=value" in the order of keys.
+func appendTextFields(msg string, fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, k := range keys {
+		b.WriteByte(' ')
+		b.WriteString(k)
+		b.WriteByte('=')
+		v := fmt.Sprint(fields[k])
+		if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
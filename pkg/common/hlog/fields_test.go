@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hlog
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestWithFields(t *testing.T) {
+	initTestLogger()
+
+	var w byteSliceWriter
+	SetOutput(&w)
+
+	l := With("user", "foo", "retry", 3)
+	l.Info("login failed")
+	l.(FieldLogger).WithFields(nil).Info("no more fields")
+	l.(FieldLogger).WithFields(map[string]interface{}{"retry": 4, "reason": "bad password"}).Warnf("login %s", "failed")
+	WithFields(map[string]interface{}{"empty": ""}).(FieldLogger).With("lonely").Error("odd")
+	Info("no fields")
+
+	assert.DeepEqual(t, "[Info] login failed retry=3 user=foo\n"+
+		"[Info] no more fields retry=3 user=foo\n"+
+		"[Warn] login failed reason=\"bad password\" retry=4 user=foo\n"+
+		"[Error] odd !BADKEY=lonely empty=\"\"\n"+
+		"[Info] no fields\n", string(w.b))
+}
+
+func TestContextWithFields(t *testing.T) {
+	initTestLogger()
+	defer SetFormatter(nil)
+
+	var w byteSliceWriter
+	SetOutput(&w)
+
+	ctx := ContextWithFields(context.Background(), "request_id", "abc")
+	ctx = ContextWithFields(ctx, 1, true)
+	assert.DeepEqual(t, map[string]interface{}{"request_id": "abc", "1": true}, FieldsFromContext(ctx))
+	assert.Nil(t, FieldsFromContext(context.Background()))
+
+	With("user", "foo", "request_id", "ignored").(CtxLogger).CtxInfof(ctx, "starting %s", "work")
+	assert.DeepEqual(t, "[Info] starting work 1=true request_id=abc user=foo\n", string(w.b))
+
+	w.b = nil
+	SetFormatter(&JSONFormatter{})
+	CtxWarnf(ctx, "work may fail")
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(strings.TrimSpace(string(w.b))), &m))
+	assert.DeepEqual(t, "abc", m["request_id"])
+	assert.DeepEqual(t, true, m["1"])
+	assert.DeepEqual(t, "work may fail", m["msg"])
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	initTestLogger()
+	defer func() { extractors = nil }()
+
+	var w byteSliceWriter
+	SetOutput(&w)
+
+	type idKey struct{}
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if id, ok := ctx.Value(idKey{}).(string); ok {
+			return map[string]interface{}{"request_id": id, "source": "first"}
+		}
+		return nil
+	})
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if ctx.Value(idKey{}) != nil {
+			return map[string]interface{}{"source": "second"}
+		}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), idKey{}, "abc")
+	CtxInfof(ctx, "starting work")
+	CtxInfof(ContextWithFields(ctx, "source", "carried"), "work done")
+	Info("no context")
+
+	assert.DeepEqual(t, "[Info] starting work request_id=abc source=second\n"+
+		"[Info] work done request_id=abc source=carried\n"+
+		"[Info] no context\n", string(w.b))
+}
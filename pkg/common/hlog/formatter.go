@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Entry is a log record handed to the Formatter.
+type Entry struct {
+	Time  time.Time
+	Level Level
+	// Caller is the "file:line" of the logging call, empty if it can not be got.
+	Caller  string
+	Message string
+	// Fields holds the structured fields of the record.
+	Fields map[string]interface{}
+}
+
+// Formatter formats the log records of the default logger.
+type Formatter interface {
+	// Format appends the formatted e to buf, a trailing newline is added if missing.
+	Format(buf *bytes.Buffer, e *Entry) error
+}
+
+// The keys of the built-in attributes of JSONFormatter.
+const (
+	JSONKeyTime    = "time"
+	JSONKeyLevel   = "level"
+	JSONKeyCaller  = "caller"
+	JSONKeyMessage = "msg"
+)
+
+// JSONFormatter formats a log record as a single-line JSON object, e.g.
+//
+//	{"time":"2022-01-02T15:04:05.000000Z07:00","level":"info","caller":"main.go:12","msg":"starting work","user":"foo"}
+//
+// The structured fields follow the built-in attributes in the order of keys,
+// the field named as a built-in attribute is ignored.
+type JSONFormatter struct {
+	// TimeLayout is the layout of the time, RFC3339 with microseconds is used if empty.
+	TimeLayout string
+	// DisableCaller omits the caller.
+	DisableCaller bool
+}
+
+const defaultJSONTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// Format implements Formatter.
+func (f *JSONFormatter) Format(buf *bytes.Buffer, e *Entry) error {
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = defaultJSONTimeLayout
+	}
+	buf.WriteByte('{')
+	writeJSONField(buf, JSONKeyTime, e.Time.Format(layout), true)
+	writeJSONField(buf, JSONKeyLevel, e.Level.String(), false)
+	if !f.DisableCaller && e.Caller != "" {
+		writeJSONField(buf, JSONKeyCaller, e.Caller, false)
+	}
+	writeJSONField(buf, JSONKeyMessage, e.Message, false)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		switch k {
+		case JSONKeyTime, JSONKeyLevel, JSONKeyCaller, JSONKeyMessage:
+		default:
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeJSONField(buf, k, e.Fields[k], false)
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	writeJSONValue(buf, key)
+	buf.WriteByte(':')
+	writeJSONValue(buf, value)
+}
+
+// writeJSONValue writes v as JSON, the errors and the values which can not be
+// marshalled are written as strings.
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	if err, ok := v.(error); ok {
+		if _, ok = v.(json.Marshaler); !ok {
+			v = err.Error()
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	f := &JSONFormatter{}
+	var buf bytes.Buffer
+	e := &Entry{
+		Time:    time.Date(2022, 1, 2, 15, 4, 5, 6000, time.UTC),
+		Level:   LevelWarn,
+		Caller:  "main.go:12",
+		Message: `say "hi"`,
+		Fields: map[string]interface{}{
+			"user":  "foo",
+			"count": 2,
+			"err":   errors.New("failed"),
+			"msg":   "ignored",
+			"ch":    make(chan int),
+		},
+	}
+	assert.Nil(t, f.Format(&buf, e))
+	s := buf.String()
+	assert.True(t, strings.HasPrefix(s, `{"time":"2022-01-02T15:04:05.000006Z","level":"warn","caller":"main.go:12","msg":"say \"hi\"","ch":"0x`))
+	assert.True(t, strings.HasSuffix(s, `","count":2,"err":"failed","user":"foo"}`+"\n"))
+
+	buf.Reset()
+	f = &JSONFormatter{TimeLayout: time.RFC3339, DisableCaller: true}
+	e.Fields = nil
+	assert.Nil(t, f.Format(&buf, e))
+	assert.DeepEqual(t, `{"time":"2022-01-02T15:04:05Z","level":"warn","msg":"say \"hi\""}`+"\n", buf.String())
+}
+
+func TestSetFormatter(t *testing.T) {
+	initTestLogger()
+	initTestSysLogger()
+	defer SetFormatter(nil)
+
+	var w byteSliceWriter
+	SetOutput(&w)
+	SetFormatter(&JSONFormatter{})
+
+	Infof("starting %s", "work")
+	SystemLogger().Error("work failed")
+
+	lines := strings.Split(strings.TrimSuffix(string(w.b), "\n"), "\n")
+	assert.DeepEqual(t, 2, len(lines))
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &m))
+	assert.DeepEqual(t, "info", m["level"])
+	assert.DeepEqual(t, "starting work", m["msg"])
+	assert.True(t, strings.HasPrefix(m["caller"].(string), "formatter_test.go:"))
+	_, err := time.Parse(defaultJSONTimeLayout, m["time"].(string))
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &m))
+	assert.DeepEqual(t, "error", m["level"])
+	assert.DeepEqual(t, "HERTZ: work failed", m["msg"])
+
+	w.b = nil
+	SetFormatter(nil)
+	Info("starting work")
+	assert.DeepEqual(t, "[Info] starting work\n", string(w.b))
+}
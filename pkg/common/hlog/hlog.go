@@ -20,6 +20,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync/atomic"
 )
 
 const (
@@ -27,6 +28,9 @@ const (
 )
 
 var (
+	// level is the level set by SetLevel
+	level int32
+
 	// Provide default logger for users to use
 	logger FullLogger = &defaultLogger{
 		stdlog: log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile|log.Lmicroseconds),
@@ -51,10 +55,40 @@ func SetOutput(w io.Writer) {
 
 // SetLevel sets the level of logs below which logs will not be output.
 // The default logger and system logger level is LevelTrace.
-// Note that this method is not concurrent-safe.
+//
+// NOTE:
+//
+//	It is safe to call SetLevel while logging with the built-in loggers,
+//	the custom loggers set by SetLogger must implement SetLevel in a concurrent-safe way to do so.
 func SetLevel(lv Level) {
 	logger.SetLevel(lv)
 	sysLogger.SetLevel(lv)
+	atomic.StoreInt32(&level, int32(lv))
+}
+
+// GetLevel returns the level set by SetLevel, which is LevelTrace by default.
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&level))
+}
+
+// SetFormatter sets the formatter of default logger and system logger, e.g.
+//
+//	hlog.SetFormatter(&hlog.JSONFormatter{})
+//
+// NOTE:
+//
+//	If f==nil, the plain text format is used, which is the default.
+//	It takes effect only on the loggers which provide SetFormatter(Formatter), e.g. the built-in ones.
+//	Note that this method is not concurrent-safe.
+func SetFormatter(f Formatter) {
+	for _, l := range []FullLogger{logger, sysLogger} {
+		if sl, ok := l.(*systemLogger); ok {
+			l = sl.logger
+		}
+		if fl, ok := l.(interface{ SetFormatter(Formatter) }); ok {
+			fl.SetFormatter(f)
+		}
+	}
 }
 
 // DefaultLogger return the default logger for hertz.
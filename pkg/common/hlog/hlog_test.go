@@ -50,3 +50,23 @@ func TestSetLogger(t *testing.T) {
 	assert.DeepEqual(t, logger, setLog)
 	assert.DeepEqual(t, sysLogger, setSysLog)
 }
+
+func TestGetLevel(t *testing.T) {
+	defer SetLevel(LevelTrace)
+	assert.DeepEqual(t, LevelTrace, GetLevel())
+	SetLevel(LevelWarn)
+	assert.DeepEqual(t, LevelWarn, GetLevel())
+}
+
+func TestParseLevel(t *testing.T) {
+	for lv := LevelTrace; lv <= LevelFatal; lv++ {
+		parsed, err := ParseLevel(lv.String())
+		assert.Nil(t, err)
+		assert.DeepEqual(t, lv, parsed)
+	}
+	lv, err := ParseLevel("WARN")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, LevelWarn, lv)
+	_, err = ParseLevel("verbose")
+	assert.NotNil(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 )
 
 // FormatLogger is a logger interface that output logs with a format.
@@ -56,6 +57,13 @@ type CtxLogger interface {
 	CtxFatalf(ctx context.Context, format string, v ...interface{})
 }
 
+// FieldLogger is a logger interface that derives the loggers carrying structured fields,
+// which are output along with every log of the derived loggers.
+type FieldLogger interface {
+	WithFields(fields map[string]interface{}) FullLogger
+	With(kv ...interface{}) FullLogger
+}
+
 // Control provides methods to config a logger.
 type Control interface {
 	SetLevel(Level)
@@ -96,6 +104,34 @@ var strs = []string{
 	"[Fatal] ",
 }
 
+var names = []string{
+	"trace",
+	"debug",
+	"info",
+	"notice",
+	"warn",
+	"error",
+	"fatal",
+}
+
+// String returns the lower-cased name of lv, e.g. "info".
+func (lv Level) String() string {
+	if lv >= LevelTrace && lv <= LevelFatal {
+		return names[lv]
+	}
+	return fmt.Sprintf("?%d", lv)
+}
+
+// ParseLevel returns the level of the name returned by Level.String, case-insensitively.
+func ParseLevel(name string) (Level, error) {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return Level(i), nil
+		}
+	}
+	return LevelTrace, fmt.Errorf("unknown log level %q", name)
+}
+
 func (lv Level) toString() string {
 	if lv >= LevelTrace && lv <= LevelFatal {
 		return strs[lv]
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rotate
+
+import "time"
+
+const (
+	defaultMaxSize    = 100 << 20
+	defaultMaxBackups = 10
+)
+
+type (
+	options struct {
+		maxSize    int64
+		maxAge     time.Duration
+		maxBackups int
+		interval   time.Duration
+		compress   bool
+		localTime  bool
+	}
+
+	Option func(o *options)
+)
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		maxSize:    defaultMaxSize,
+		maxBackups: defaultMaxBackups,
+		localTime:  true,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithMaxSize sets the size in bytes the file is rotated at, which is 100MB by default.
+// The size limit is disabled if size<=0.
+func WithMaxSize(size int64) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// WithMaxAge sets the age the rotated files are removed after, which is judged by the time in their names.
+// The rotated files are kept regardless of the age by default.
+func WithMaxAge(d time.Duration) Option {
+	return func(o *options) {
+		o.maxAge = d
+	}
+}
+
+// WithMaxBackups sets the max number of the rotated files to keep, which is 10 by default.
+// All rotated files are kept if n<=0, unless they are removed by WithMaxAge.
+func WithMaxBackups(n int) Option {
+	return func(o *options) {
+		o.maxBackups = n
+	}
+}
+
+// WithRotateInterval makes the file rotated every d in addition to the size limit, e.g. 24*time.Hour.
+//
+// NOTE:
+//
+//	The intervals are aligned to the multiples of d since the zero time,
+//	e.g. the file is rotated at the UTC midnight if d is 24h.
+func WithRotateInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// WithCompress makes the rotated files compressed by gzip with the ".gz" suffix.
+func WithCompress(compress bool) Option {
+	return func(o *options) {
+		o.compress = compress
+	}
+}
+
+// WithLocalTime sets whether the local time is used in the names of the rotated files,
+// the UTC time is used if it is false. It is true by default.
+func WithLocalTime(local bool) Option {
+	return func(o *options) {
+		o.localTime = local
+	}
+}
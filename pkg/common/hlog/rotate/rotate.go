@@ -0,0 +1,285 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout is the layout of the time in the names of the rotated files,
+// which are named like "app-2006-01-02T15-04-05.000.log".
+const backupTimeLayout = "2006-01-02T15-04-05.000"
+
+const compressSuffix = ".gz"
+
+var errClosed = errors.New("rotate: writer is closed")
+
+// Writer is an io.Writer writing to a file which is rotated by the size and time, e.g.
+//
+//	w, err := rotate.NewWriter("/var/log/app.log", rotate.WithMaxSize(50<<20), rotate.WithCompress(true))
+//	...
+//	hlog.SetOutput(w)
+//
+// The file is renamed with the time of the rotation and a new file is created when it is rotated.
+// The rotated files beyond the limits are removed, and compressed if required, in the background.
+// It is safe for concurrent use.
+type Writer struct {
+	filename string
+	opts     *options
+	now      func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+// NewWriter creates a Writer appending to filename, the directory of which is created if missing.
+func NewWriter(filename string, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		filename: filename,
+		opts:     newOptions(opts...),
+		now:      time.Now,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	go w.millLoop()
+	return w, nil
+}
+
+// Write implements io.Writer, the file is rotated before writing p if p makes it exceed the max size
+// or the rotation interval has passed. p larger than the max size is written to a new file as a whole.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the file and waits for the pending removal and compression of the rotated files.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.file.Close()
+	w.mu.Unlock()
+
+	close(w.millCh)
+	<-w.millDone
+	return err
+}
+
+func (w *Writer) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.opts.maxSize > 0 && w.size+n > w.opts.maxSize {
+		return true
+	}
+	d := w.opts.interval
+	return d > 0 && !w.now().Truncate(d).Equal(w.openedAt.Truncate(d))
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size, w.openedAt = f, info.Size(), w.now()
+	if w.size > 0 {
+		w.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.filename, w.backupName(w.now())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (w *Writer) backupName(t time.Time) string {
+	if !w.opts.localTime {
+		t = t.UTC()
+	}
+	dir, prefix, ext := w.nameParts()
+	return filepath.Join(dir, prefix+t.Format(backupTimeLayout)+ext)
+}
+
+// nameParts splits the file name into the directory, the prefix of the rotated files and the extension.
+func (w *Writer) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (w *Writer) millLoop() {
+	defer close(w.millDone)
+	for range w.millCh {
+		w.mill()
+	}
+}
+
+type backup struct {
+	path       string
+	t          time.Time
+	compressed bool
+}
+
+// mill removes the rotated files beyond the limits and compresses the remaining ones if required.
+func (w *Writer) mill() {
+	backups := w.backups()
+	var remaining []backup
+	cutoff := w.now().Add(-w.opts.maxAge)
+	for i, b := range backups {
+		if (w.opts.maxBackups > 0 && i >= w.opts.maxBackups) || (w.opts.maxAge > 0 && b.t.Before(cutoff)) {
+			os.Remove(b.path)
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+	if !w.opts.compress {
+		return
+	}
+	for _, b := range remaining {
+		if !b.compressed {
+			compressFile(b.path)
+		}
+	}
+}
+
+// backups returns the rotated files from the newest to the oldest.
+func (w *Writer) backups() []backup {
+	dir, prefix, ext := w.nameParts()
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	loc := time.UTC
+	if w.opts.localTime {
+		loc = time.Local
+	}
+	var ret []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		b := backup{path: filepath.Join(dir, name)}
+		ts := strings.TrimPrefix(name, prefix)
+		if strings.HasSuffix(ts, ext+compressSuffix) {
+			ts, b.compressed = strings.TrimSuffix(ts, ext+compressSuffix), true
+		} else if strings.HasSuffix(ts, ext) {
+			ts = strings.TrimSuffix(ts, ext)
+		} else {
+			continue
+		}
+		if b.t, err = time.ParseInLocation(backupTimeLayout, ts, loc); err != nil {
+			continue
+		}
+		ret = append(ret, b)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].t.After(ret[j].t)
+	})
+	return ret
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(dst)
+	if _, err = io.Copy(gw, src); err == nil {
+		err = gw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
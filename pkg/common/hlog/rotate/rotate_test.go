@@ -0,0 +1,147 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rotate
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestWriter(t *testing.T, opts ...Option) (*Writer, *fakeClock, string) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)}
+	w, err := NewWriter(filepath.Join(dir, "logs", "app.log"), append([]Option{WithLocalTime(false)}, opts...)...)
+	assert.Nil(t, err)
+	w.now = clock.now
+	w.openedAt = clock.now()
+	return w, clock, filepath.Join(dir, "logs")
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return string(b)
+}
+
+func TestRotateBySize(t *testing.T) {
+	w, clock, dir := newTestWriter(t, WithMaxSize(10), WithMaxBackups(2))
+
+	for _, s := range []string{"12345", "67890", "abcde", "0123456789abc", "x"} {
+		_, err := w.Write([]byte(s))
+		assert.Nil(t, err)
+		clock.advance(time.Second)
+	}
+	assert.Nil(t, w.Close())
+	_, err := w.Write([]byte("closed"))
+	assert.NotNil(t, err)
+
+	// the oldest rotated file "1234567890" is removed by the max backups
+	assert.DeepEqual(t, []string{
+		"app-2022-01-02T15-04-08.000.log",
+		"app-2022-01-02T15-04-09.000.log",
+		"app.log",
+	}, listFiles(t, dir))
+	assert.DeepEqual(t, "abcde", readFile(t, filepath.Join(dir, "app-2022-01-02T15-04-08.000.log")))
+	assert.DeepEqual(t, "0123456789abc", readFile(t, filepath.Join(dir, "app-2022-01-02T15-04-09.000.log")))
+	assert.DeepEqual(t, "x", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestRotateByIntervalAndAge(t *testing.T) {
+	w, clock, dir := newTestWriter(t, WithRotateInterval(24*time.Hour), WithMaxAge(48*time.Hour), WithMaxBackups(0))
+
+	for i := 0; i < 4; i++ {
+		_, err := w.Write([]byte("day"))
+		assert.Nil(t, err)
+		clock.advance(24 * time.Hour)
+	}
+	_, err := w.Write([]byte("today"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	// the file rotated more than 48 hours ago is removed
+	assert.DeepEqual(t, []string{
+		"app-2022-01-04T15-04-05.000.log",
+		"app-2022-01-05T15-04-05.000.log",
+		"app-2022-01-06T15-04-05.000.log",
+		"app.log",
+	}, listFiles(t, dir))
+	assert.DeepEqual(t, "today", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestRotateCompress(t *testing.T) {
+	w, _, dir := newTestWriter(t, WithCompress(true))
+
+	_, err := w.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Rotate())
+	assert.Nil(t, w.Close())
+
+	assert.DeepEqual(t, []string{"app-2022-01-02T15-04-05.000.log.gz", "app.log"}, listFiles(t, dir))
+	f, err := os.Open(filepath.Join(dir, "app-2022-01-02T15-04-05.000.log.gz"))
+	assert.Nil(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	b, err := ioutil.ReadAll(gr)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello", string(b))
+}
+
+func TestReopen(t *testing.T) {
+	w, _, dir := newTestWriter(t)
+	_, err := w.Write([]byte("first"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	w, err = NewWriter(filepath.Join(dir, "app.log"))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(5), w.size)
+	_, err = w.Write([]byte(" second"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.DeepEqual(t, "first second", readFile(t, filepath.Join(dir, "app.log")))
+}
@@ -0,0 +1,255 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build go1.21
+// +build go1.21
+
+// Package slog provides the adapter of hlog.FullLogger backed by log/slog,
+// so that the logs of hertz are output by the same pipeline as the application, e.g.
+//
+//	hlog.SetLogger(slog.NewLogger(stdslog.Default().Handler()))
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdslog "log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// The slog levels of the hlog levels without counterparts in slog.
+const (
+	LevelTrace  = stdslog.LevelDebug - 4
+	LevelNotice = stdslog.LevelInfo + 2
+	LevelFatal  = stdslog.LevelError + 4
+)
+
+// callerSkip skips the frames of runtime.Callers, Logger.log, the method of Logger
+// and the global function of hlog.
+const callerSkip = 4
+
+var (
+	_ hlog.FullLogger  = (*Logger)(nil)
+	_ hlog.FieldLogger = (*Logger)(nil)
+)
+
+// Logger is the adapter of hlog.FullLogger backed by a slog handler.
+//
+// NOTE:
+//
+//	Fatal logs are output with LevelFatal before os.Exit(1).
+//	The fields carried by the context with hlog.ContextWithFields are added to the logs of Ctx methods.
+type Logger struct {
+	handler stdslog.Handler
+	level   *stdslog.LevelVar
+}
+
+// NewLogger creates a Logger outputting logs to handler,
+// a JSON handler writing to stderr is used if handler==nil.
+func NewLogger(handler stdslog.Handler) *Logger {
+	level := &stdslog.LevelVar{}
+	level.Set(LevelTrace)
+	if handler == nil {
+		handler = newJSONHandler(os.Stderr)
+	}
+	return &Logger{handler: handler, level: level}
+}
+
+func newJSONHandler(w io.Writer) stdslog.Handler {
+	return stdslog.NewJSONHandler(w, &stdslog.HandlerOptions{AddSource: true, Level: LevelTrace})
+}
+
+// Handler returns the slog handler of l.
+func (l *Logger) Handler() stdslog.Handler {
+	return l.handler
+}
+
+// SetLevel implements hlog.Control, the logs below lv are dropped before reaching the handler.
+// The level is shared by the loggers derived by With and WithFields.
+func (l *Logger) SetLevel(lv hlog.Level) {
+	l.level.Set(slogLevel(lv))
+}
+
+// SetOutput implements hlog.Control.
+//
+// NOTE:
+//
+//	The handler of l is replaced by a JSON handler writing to w,
+//	and the fields added by With and WithFields are dropped.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.handler = newJSONHandler(w)
+}
+
+// WithFields implements hlog.FieldLogger.
+func (l *Logger) WithFields(fields map[string]interface{}) hlog.FullLogger {
+	return &Logger{handler: l.handler.WithAttrs(fieldsToAttrs(fields)), level: l.level}
+}
+
+// With implements hlog.FieldLogger.
+func (l *Logger) With(kv ...interface{}) hlog.FullLogger {
+	r := stdslog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(kv...)
+	attrs := make([]stdslog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a stdslog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return &Logger{handler: l.handler.WithAttrs(attrs), level: l.level}
+}
+
+func (l *Logger) log(ctx context.Context, lv hlog.Level, format *string, v ...interface{}) {
+	level := slogLevel(lv)
+	if level >= l.level.Level() && l.handler.Enabled(ctx, level) {
+		var msg string
+		if format != nil {
+			msg = fmt.Sprintf(*format, v...)
+		} else {
+			msg = fmt.Sprint(v...)
+		}
+		var pcs [1]uintptr
+		runtime.Callers(callerSkip, pcs[:])
+		r := stdslog.NewRecord(time.Now(), level, msg, pcs[0])
+		r.AddAttrs(fieldsToAttrs(hlog.FieldsFromContext(ctx))...)
+		_ = l.handler.Handle(ctx, r)
+	}
+	if lv == hlog.LevelFatal {
+		os.Exit(1)
+	}
+}
+
+func slogLevel(lv hlog.Level) stdslog.Level {
+	switch lv {
+	case hlog.LevelTrace:
+		return LevelTrace
+	case hlog.LevelDebug:
+		return stdslog.LevelDebug
+	case hlog.LevelInfo:
+		return stdslog.LevelInfo
+	case hlog.LevelNotice:
+		return LevelNotice
+	case hlog.LevelWarn:
+		return stdslog.LevelWarn
+	case hlog.LevelError:
+		return stdslog.LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+func fieldsToAttrs(fields map[string]interface{}) []stdslog.Attr {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]stdslog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, stdslog.Any(k, fields[k]))
+	}
+	return attrs
+}
+
+func (l *Logger) Trace(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelTrace, nil, v...)
+}
+
+func (l *Logger) Debug(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelDebug, nil, v...)
+}
+
+func (l *Logger) Info(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelInfo, nil, v...)
+}
+
+func (l *Logger) Notice(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelNotice, nil, v...)
+}
+
+func (l *Logger) Warn(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelWarn, nil, v...)
+}
+
+func (l *Logger) Error(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelError, nil, v...)
+}
+
+func (l *Logger) Fatal(v ...interface{}) {
+	l.log(context.Background(), hlog.LevelFatal, nil, v...)
+}
+
+func (l *Logger) Tracef(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelTrace, &format, v...)
+}
+
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelDebug, &format, v...)
+}
+
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelInfo, &format, v...)
+}
+
+func (l *Logger) Noticef(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelNotice, &format, v...)
+}
+
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelWarn, &format, v...)
+}
+
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelError, &format, v...)
+}
+
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.log(context.Background(), hlog.LevelFatal, &format, v...)
+}
+
+func (l *Logger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelTrace, &format, v...)
+}
+
+func (l *Logger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelDebug, &format, v...)
+}
+
+func (l *Logger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelInfo, &format, v...)
+}
+
+func (l *Logger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelNotice, &format, v...)
+}
+
+func (l *Logger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelWarn, &format, v...)
+}
+
+func (l *Logger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelError, &format, v...)
+}
+
+func (l *Logger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, hlog.LevelFatal, &format, v...)
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdslog "log/slog"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var ret []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &m))
+		ret = append(ret, m)
+	}
+	buf.Reset()
+	return ret
+}
+
+func TestLogger(t *testing.T) {
+	defaultLogger := hlog.DefaultLogger()
+	defer hlog.SetLogger(defaultLogger)
+
+	var buf bytes.Buffer
+	l := NewLogger(nil)
+	l.SetOutput(&buf)
+	hlog.SetLogger(l)
+
+	hlog.Tracef("trace %s", "work")
+	hlog.Notice("something happens")
+	hlog.SystemLogger().Warnf("work may fail")
+	lines := decodeLines(t, &buf)
+	assert.DeepEqual(t, 3, len(lines))
+	assert.DeepEqual(t, "DEBUG-4", lines[0]["level"])
+	assert.DeepEqual(t, "trace work", lines[0]["msg"])
+	assert.True(t, strings.HasSuffix(lines[0]["source"].(map[string]interface{})["file"].(string), "slog_test.go"))
+	assert.DeepEqual(t, "INFO+2", lines[1]["level"])
+	assert.DeepEqual(t, "WARN", lines[2]["level"])
+	assert.DeepEqual(t, "HERTZ: work may fail", lines[2]["msg"])
+
+	hlog.SetLevel(hlog.LevelWarn)
+	hlog.Info("dropped")
+	assert.DeepEqual(t, 0, buf.Len())
+	hlog.SetLevel(hlog.LevelTrace)
+}
+
+func TestLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(stdslog.NewJSONHandler(&buf, &stdslog.HandlerOptions{Level: LevelTrace}))
+
+	derived := l.With("user", "foo", "retry", 3)
+	derived.Info("login failed")
+	derived.(hlog.FieldLogger).WithFields(map[string]interface{}{"reason": "bad password"}).Warnf("login %s", "failed")
+	ctx := hlog.ContextWithFields(context.Background(), "request_id", "abc")
+	l.CtxErrorf(ctx, "work failed")
+
+	lines := decodeLines(t, &buf)
+	assert.DeepEqual(t, 3, len(lines))
+	assert.DeepEqual(t, "foo", lines[0]["user"])
+	assert.DeepEqual(t, float64(3), lines[0]["retry"])
+	assert.DeepEqual(t, "bad password", lines[1]["reason"])
+	assert.DeepEqual(t, "foo", lines[1]["user"])
+	assert.DeepEqual(t, "abc", lines[2]["request_id"])
+	assert.DeepEqual(t, "ERROR", lines[2]["level"])
+
+	// the level is shared by the derived loggers
+	l.SetLevel(hlog.LevelError)
+	derived.Warn("dropped")
+	assert.DeepEqual(t, 0, buf.Len())
+}
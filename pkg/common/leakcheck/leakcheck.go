@@ -0,0 +1,296 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package leakcheck is a debug mode to find the leaks caused by forgotten Close or
+// Release calls. Once enabled, hertz tracks the body streams, the pooled requests and
+// responses and the client connections it hands out, and reports the ones held longer
+// than the thresholds together with the stacks where they were acquired, e.g.
+//
+//	leakcheck.Enable(leakcheck.Options{ConnThreshold: time.Minute})
+//	defer leakcheck.Disable()
+//
+// NOTE:
+//
+//	The stack is captured for every tracked object, so it should not be enabled in production.
+package leakcheck
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Kind is the kind of the tracked objects.
+type Kind int
+
+const (
+	// KindBodyStream is a request or response body stream, released by ReleaseBodyStream
+	// or by closing the response body stream of the client.
+	KindBodyStream Kind = iota + 1
+	// KindPooledObject is an object taken from a pool, e.g. by protocol.AcquireRequest.
+	KindPooledObject
+	// KindConn is a client connection taken from the connection pool to do a request.
+	KindConn
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBodyStream:
+		return "body_stream"
+	case KindPooledObject:
+		return "pooled_object"
+	case KindConn:
+		return "conn"
+	}
+	return "unknown"
+}
+
+const (
+	defaultThreshold     = time.Minute
+	defaultCheckInterval = 10 * time.Second
+	maxStackDepth        = 32
+)
+
+// Options configures the leak detection.
+type Options struct {
+	// The objects held longer than the thresholds are reported as leaked, 1 minute by default.
+	BodyStreamThreshold   time.Duration
+	PooledObjectThreshold time.Duration
+	ConnThreshold         time.Duration
+
+	// CheckInterval is the interval to check the tracked objects, 10 seconds by default.
+	CheckInterval time.Duration
+
+	// Report is called once for every leaked object, which is logged by default.
+	Report func(l *Leak)
+}
+
+// Leak describes an object held longer than the threshold of its kind.
+type Leak struct {
+	Kind Kind
+	// Type is the type of the object, e.g. *protocol.Request
+	Type       string
+	AcquiredAt time.Time
+	Held       time.Duration
+	// Stack is the stack where the object is acquired.
+	Stack string
+}
+
+func (l *Leak) String() string {
+	return fmt.Sprintf("%s %s held for %v, acquired at:\n%s", l.Kind, l.Type, l.Held, l.Stack)
+}
+
+type entry struct {
+	seq        uint64
+	kind       Kind
+	acquiredAt time.Time
+	pcs        []uintptr
+	reported   bool
+}
+
+var (
+	enabled int32
+
+	mu      sync.Mutex
+	opts    Options
+	tracked = make(map[interface{}]*entry)
+	seq     uint64
+	stop    chan struct{}
+)
+
+// Enable enables the leak detection, the objects acquired before are not tracked.
+// It replaces the options if it is already enabled.
+func Enable(o Options) {
+	if o.BodyStreamThreshold <= 0 {
+		o.BodyStreamThreshold = defaultThreshold
+	}
+	if o.PooledObjectThreshold <= 0 {
+		o.PooledObjectThreshold = defaultThreshold
+	}
+	if o.ConnThreshold <= 0 {
+		o.ConnThreshold = defaultThreshold
+	}
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = defaultCheckInterval
+	}
+	if o.Report == nil {
+		o.Report = func(l *Leak) {
+			hlog.SystemLogger().Warnf("Possible leak: %s", l)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+	opts = o
+	stop = make(chan struct{})
+	go checkLoop(o.CheckInterval, stop)
+	atomic.StoreInt32(&enabled, 1)
+}
+
+// Disable disables the leak detection and forgets all the tracked objects.
+func Disable() {
+	atomic.StoreInt32(&enabled, 0)
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+	tracked = make(map[interface{}]*entry)
+}
+
+// Enabled reports whether the leak detection is enabled.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// Acquire starts tracking obj, which must be a pointer, until it is released.
+func Acquire(kind Kind, obj interface{}) {
+	if !Enabled() {
+		return
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers, Acquire and the function tracking obj
+	n := runtime.Callers(3, pcs)
+	e := &entry{kind: kind, acquiredAt: time.Now(), pcs: pcs[:n]}
+
+	mu.Lock()
+	seq++
+	e.seq = seq
+	tracked[obj] = e
+	mu.Unlock()
+}
+
+// Release stops tracking obj, it's a no-op if obj is not tracked.
+func Release(obj interface{}) {
+	if !Enabled() {
+		return
+	}
+	mu.Lock()
+	delete(tracked, obj)
+	mu.Unlock()
+}
+
+// Check returns the tracked objects held longer than the thresholds, in the order of acquisition.
+func Check() []*Leak {
+	return collect(true, false)
+}
+
+// Dump writes all the tracked objects with their stacks to w, in the order of acquisition.
+func Dump(w io.Writer) error {
+	leaks := collect(false, false)
+	for _, l := range leaks {
+		if _, err := fmt.Fprintf(w, "%s\n", l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			mu.Lock()
+			report := opts.Report
+			mu.Unlock()
+			for _, l := range collect(true, true) {
+				report(l)
+			}
+		}
+	}
+}
+
+// collect returns the tracked objects, only the leaked ones if leakedOnly.
+// The leaked objects are marked as reported if markReported, and skipped since then.
+func collect(leakedOnly, markReported bool) []*Leak {
+	now := time.Now()
+	var (
+		leaks []*Leak
+		seqs  = make(map[*Leak]uint64)
+	)
+	mu.Lock()
+	for obj, e := range tracked {
+		held := now.Sub(e.acquiredAt)
+		if leakedOnly && held < threshold(e.kind) {
+			continue
+		}
+		if markReported {
+			if e.reported {
+				continue
+			}
+			e.reported = true
+		}
+		l := &Leak{
+			Kind:       e.kind,
+			Type:       reflect.TypeOf(obj).String(),
+			AcquiredAt: e.acquiredAt,
+			Held:       held,
+			Stack:      formatStack(e.pcs),
+		}
+		seqs[l] = e.seq
+		leaks = append(leaks, l)
+	}
+	mu.Unlock()
+
+	sort.Slice(leaks, func(i, j int) bool {
+		return seqs[leaks[i]] < seqs[leaks[j]]
+	})
+	return leaks
+}
+
+func threshold(kind Kind) time.Duration {
+	switch kind {
+	case KindBodyStream:
+		return opts.BodyStreamThreshold
+	case KindPooledObject:
+		return opts.PooledObjectThreshold
+	case KindConn:
+		return opts.ConnThreshold
+	}
+	return defaultThreshold
+}
+
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package leakcheck
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+type object struct {
+	_ int
+}
+
+func acquireObject() *object {
+	o := &object{}
+	Acquire(KindPooledObject, o)
+	return o
+}
+
+func TestDisabled(t *testing.T) {
+	Disable()
+	o := acquireObject()
+	assert.False(t, Enabled())
+	assert.DeepEqual(t, 0, len(Check()))
+	Release(o)
+}
+
+func TestCheck(t *testing.T) {
+	Enable(Options{PooledObjectThreshold: 50 * time.Millisecond, CheckInterval: time.Hour})
+	defer Disable()
+	assert.True(t, Enabled())
+
+	o := acquireObject()
+	c := &object{}
+	Acquire(KindConn, c)
+	assert.DeepEqual(t, 0, len(Check()))
+
+	time.Sleep(100 * time.Millisecond)
+	leaks := Check()
+	assert.DeepEqual(t, 1, len(leaks))
+	assert.DeepEqual(t, KindPooledObject, leaks[0].Kind)
+	assert.DeepEqual(t, "*leakcheck.object", leaks[0].Type)
+	assert.True(t, leaks[0].Held >= 100*time.Millisecond)
+	// the stack begins with the caller of the function acquiring the object
+	assert.True(t, strings.HasPrefix(leaks[0].Stack, "github.com/cloudwego/hertz/pkg/common/leakcheck.TestCheck\n"))
+
+	var b bytes.Buffer
+	assert.Nil(t, Dump(&b))
+	assert.True(t, strings.Contains(b.String(), "pooled_object *leakcheck.object held for"))
+	assert.True(t, strings.Contains(b.String(), "conn *leakcheck.object held for"))
+
+	Release(o)
+	Release(c)
+	assert.DeepEqual(t, 0, len(Check()))
+	b.Reset()
+	assert.Nil(t, Dump(&b))
+	assert.DeepEqual(t, "", b.String())
+}
+
+func TestReport(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		reports []*Leak
+	)
+	Enable(Options{
+		BodyStreamThreshold: 10 * time.Millisecond,
+		CheckInterval:       20 * time.Millisecond,
+		Report: func(l *Leak) {
+			lock.Lock()
+			reports = append(reports, l)
+			lock.Unlock()
+		},
+	})
+	defer Disable()
+
+	s := &object{}
+	Acquire(KindBodyStream, s)
+	time.Sleep(200 * time.Millisecond)
+
+	// the leaked object is reported only once
+	lock.Lock()
+	assert.DeepEqual(t, 1, len(reports))
+	assert.DeepEqual(t, KindBodyStream, reports[0].Kind)
+	lock.Unlock()
+	assert.DeepEqual(t, 1, len(Check()))
+
+	// the tracked objects are forgotten once disabled
+	Disable()
+	assert.DeepEqual(t, 0, len(Check()))
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtimeconfig
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// Handler returns the admin endpoint of the default Registry, see Registry.Handler.
+func Handler() app.HandlerFunc {
+	return defaultRegistry.Handler()
+}
+
+// Handler returns the admin endpoint of r, which responds to
+//
+//   - GET with the settings as a JSON array of {"name", "usage", "value"}.
+//   - PUT, POST or PATCH with the settings after changing them as a JSON object of names to values
+//     in the body, e.g. {"log.level": "debug"}, or the query arguments "name" and "value".
+//
+// NOTE:
+//
+//	The endpoint must be protected, e.g. by the basic_auth middleware, before being exposed.
+//	The changes are made in the order of names and stop at the first failure, which responds with 400.
+func (r *Registry) Handler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		ctx.Response.Header.Set("Cache-Control", "no-store")
+		switch string(ctx.Method()) {
+		case consts.MethodGet, consts.MethodHead:
+		case consts.MethodPut, consts.MethodPost, consts.MethodPatch:
+			changes := make(map[string]string)
+			if name := ctx.Query("name"); name != "" {
+				changes[name] = ctx.Query("value")
+			} else if err := json.Unmarshal(ctx.Request.Body(), &changes); err != nil {
+				ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+				return
+			}
+			if err := r.setAll(changes); err != nil {
+				ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			ctx.Response.Header.Set("Allow", "GET, HEAD, PUT, POST, PATCH")
+			ctx.SetStatusCode(consts.StatusMethodNotAllowed)
+			return
+		}
+		ctx.JSON(consts.StatusOK, r.Settings())
+	}
+}
+
+func (r *Registry) setAll(changes map[string]string) error {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := r.Set(name, changes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtimeconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	b := NewBool(false)
+	d := NewDuration(time.Second)
+	r.Register("a.enabled", "", b)
+	r.Register("b.ttl", "", d)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Any("/admin/config", r.Handler())
+
+	w := ut.PerformRequest(engine, consts.MethodGet, "/admin/config", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, "no-store", string(w.Header().Peek("Cache-Control")))
+	var settings []Setting
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.DeepEqual(t, []Setting{{Name: "a.enabled", Value: "false"}, {Name: "b.ttl", Value: "1s"}}, settings)
+
+	body := `{"a.enabled":"true","b.ttl":"2s"}`
+	w = ut.PerformRequest(engine, consts.MethodPut, "/admin/config", &ut.Body{Body: bytes.NewBufferString(body), Len: len(body)})
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.True(t, b.Load())
+	assert.DeepEqual(t, 2*time.Second, d.Load())
+
+	w = ut.PerformRequest(engine, consts.MethodPost, "/admin/config?name=a.enabled&value=false", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.False(t, b.Load())
+
+	w = ut.PerformRequest(engine, consts.MethodPost, "/admin/config?name=b.ttl&value=forever", nil)
+	assert.DeepEqual(t, consts.StatusBadRequest, w.Code)
+	assert.DeepEqual(t, 2*time.Second, d.Load())
+
+	body = `["a.enabled"]`
+	w = ut.PerformRequest(engine, consts.MethodPatch, "/admin/config", &ut.Body{Body: bytes.NewBufferString(body), Len: len(body)})
+	assert.DeepEqual(t, consts.StatusBadRequest, w.Code)
+
+	w = ut.PerformRequest(engine, consts.MethodDelete, "/admin/config", nil)
+	assert.DeepEqual(t, consts.StatusMethodNotAllowed, w.Code)
+}
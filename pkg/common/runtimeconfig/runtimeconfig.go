@@ -0,0 +1,182 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package runtimeconfig provides the settings which can be changed while the server is running,
+// e.g. the log level, the timeouts and the switches of middlewares, so that operators are able to
+// debug in production without restarts. The changes are made by Set or the admin Handler,
+// and are notified to the functions registered by Watch, e.g.
+//
+//	dumpBody := runtimeconfig.NewBool(false)
+//	runtimeconfig.Register("debug.dump_body", "dump the request bodies", dumpBody)
+//	runtimeconfig.RegisterTimeouts("server", h.Timeouts())
+//
+//	admin := h.Group("/admin", basic_auth.BasicAuth(accounts))
+//	admin.Any("/config", runtimeconfig.Handler())
+package runtimeconfig
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Var is a setting which can be changed at runtime.
+//
+// NOTE:
+//
+//	String and Set may be called concurrently with each other,
+//	and String must return the value in the format accepted by Set.
+type Var interface {
+	String() string
+	Set(value string) error
+}
+
+// Change is the notification of a setting being changed.
+type Change struct {
+	Name     string
+	OldValue string
+	NewValue string
+}
+
+// Setting describes a registered setting.
+type Setting struct {
+	Name  string `json:"name"`
+	Usage string `json:"usage"`
+	Value string `json:"value"`
+}
+
+type entry struct {
+	usage string
+	v     Var
+}
+
+// Registry holds the settings by names.
+type Registry struct {
+	mu       sync.RWMutex
+	setMu    sync.Mutex
+	vars     map[string]*entry
+	watchers []func(Change)
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{vars: make(map[string]*entry)}
+}
+
+// defaultRegistry is used by the package-level functions.
+var defaultRegistry = NewRegistry()
+
+// Default returns the Registry used by the package-level functions.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds v as the setting of name, it panics if name is registered already.
+func (r *Registry) Register(name, usage string, v Var) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.vars[name]; ok {
+		panic(fmt.Sprintf("runtimeconfig: setting %q is registered already", name))
+	}
+	r.vars[name] = &entry{usage: usage, v: v}
+}
+
+// Get returns the value of the setting of name.
+func (r *Registry) Get(name string) (string, bool) {
+	r.mu.RLock()
+	e, ok := r.vars[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return e.v.String(), true
+}
+
+// Set changes the setting of name to value and notifies the watchers if it succeeds.
+func (r *Registry) Set(name, value string) error {
+	r.mu.RLock()
+	e, ok := r.vars[name]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown setting %q", name)
+	}
+
+	// serialize the changes so that the notifications are in the same order as them
+	r.setMu.Lock()
+	defer r.setMu.Unlock()
+	old := e.v.String()
+	if err := e.v.Set(value); err != nil {
+		return fmt.Errorf("invalid value %q for setting %q: %v", value, name, err)
+	}
+	c := Change{Name: name, OldValue: old, NewValue: e.v.String()}
+	r.mu.RLock()
+	watchers := r.watchers
+	r.mu.RUnlock()
+	for _, w := range watchers {
+		w(c)
+	}
+	return nil
+}
+
+// Watch registers fn to be notified of the changes made by Set.
+//
+// NOTE:
+//
+//	fn is called synchronously by Set, and must not call Set.
+func (r *Registry) Watch(fn func(c Change)) {
+	r.mu.Lock()
+	r.watchers = append(r.watchers, fn)
+	r.mu.Unlock()
+}
+
+// Settings returns the registered settings sorted by names.
+func (r *Registry) Settings() []Setting {
+	r.mu.RLock()
+	settings := make([]Setting, 0, len(r.vars))
+	for name, e := range r.vars {
+		settings = append(settings, Setting{Name: name, Usage: e.usage, Value: e.v.String()})
+	}
+	r.mu.RUnlock()
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return settings
+}
+
+// Register adds v as the setting of name to the default Registry.
+func Register(name, usage string, v Var) {
+	defaultRegistry.Register(name, usage, v)
+}
+
+// Get returns the value of the setting of name in the default Registry.
+func Get(name string) (string, bool) {
+	return defaultRegistry.Get(name)
+}
+
+// Set changes the setting of name in the default Registry.
+func Set(name, value string) error {
+	return defaultRegistry.Set(name, value)
+}
+
+// Watch registers fn to be notified of the changes of the default Registry.
+func Watch(fn func(c Change)) {
+	defaultRegistry.Watch(fn)
+}
+
+// Settings returns the settings of the default Registry.
+func Settings() []Setting {
+	return defaultRegistry.Settings()
+}
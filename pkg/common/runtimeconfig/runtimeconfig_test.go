@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtimeconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	b := NewBool(false)
+	d := NewDuration(time.Second)
+	r.Register("feature.enabled", "enable the feature", b)
+	r.Register("cache.ttl", "ttl of the cache", d)
+	assert.Panic(t, func() {
+		r.Register("cache.ttl", "", NewBool(true))
+	})
+
+	var changes []Change
+	r.Watch(func(c Change) {
+		changes = append(changes, c)
+	})
+
+	assert.Nil(t, r.Set("feature.enabled", "true"))
+	assert.True(t, b.Load())
+	assert.Nil(t, r.Set("cache.ttl", "1m"))
+	assert.DeepEqual(t, time.Minute, d.Load())
+	assert.DeepEqual(t, []Change{
+		{Name: "feature.enabled", OldValue: "false", NewValue: "true"},
+		{Name: "cache.ttl", OldValue: "1s", NewValue: "1m0s"},
+	}, changes)
+
+	// the failed changes are not notified
+	assert.NotNil(t, r.Set("unknown", "1"))
+	assert.NotNil(t, r.Set("feature.enabled", "yes"))
+	assert.NotNil(t, r.Set("cache.ttl", "-1s"))
+	assert.DeepEqual(t, 2, len(changes))
+	assert.True(t, b.Load())
+
+	v, ok := r.Get("cache.ttl")
+	assert.True(t, ok)
+	assert.DeepEqual(t, "1m0s", v)
+	_, ok = r.Get("unknown")
+	assert.False(t, ok)
+
+	assert.DeepEqual(t, []Setting{
+		{Name: "cache.ttl", Usage: "ttl of the cache", Value: "1m0s"},
+		{Name: "feature.enabled", Usage: "enable the feature", Value: "true"},
+	}, r.Settings())
+}
+
+func TestLogLevel(t *testing.T) {
+	defer hlog.SetLevel(hlog.LevelTrace)
+	v, ok := Get(LogLevel)
+	assert.True(t, ok)
+	assert.DeepEqual(t, hlog.GetLevel().String(), v)
+
+	assert.Nil(t, Set(LogLevel, "WARN"))
+	assert.DeepEqual(t, hlog.LevelWarn, hlog.GetLevel())
+	v, _ = Get(LogLevel)
+	assert.DeepEqual(t, "warn", v)
+	assert.NotNil(t, Set(LogLevel, "verbose"))
+	assert.DeepEqual(t, hlog.LevelWarn, hlog.GetLevel())
+}
+
+func TestRegisterTimeouts(t *testing.T) {
+	r := NewRegistry()
+	timeouts := config.NewTimeouts(3*time.Second, time.Minute)
+	r.RegisterTimeouts("server", timeouts)
+
+	v, _ := r.Get("server.read_timeout")
+	assert.DeepEqual(t, "3s", v)
+	assert.Nil(t, r.Set("server.read_timeout", "10s"))
+	assert.DeepEqual(t, 10*time.Second, timeouts.Read())
+	assert.Nil(t, r.Set("server.read_timeout", "0s"))
+	assert.DeepEqual(t, time.Duration(0), timeouts.Read())
+
+	assert.Nil(t, r.Set("server.idle_timeout", "5s"))
+	assert.DeepEqual(t, 5*time.Second, timeouts.Idle())
+	assert.NotNil(t, r.Set("server.idle_timeout", "0"))
+	assert.DeepEqual(t, 5*time.Second, timeouts.Idle())
+}
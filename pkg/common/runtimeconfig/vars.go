@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtimeconfig
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// LogLevel is the name of the setting of the level of hlog, which is registered to the default Registry.
+const LogLevel = "log.level"
+
+func init() {
+	Register(LogLevel, "the level of hlog, one of trace, debug, info, notice, warn, error and fatal", logLevel{})
+}
+
+// Bool is a boolean setting, e.g. the switch of a middleware.
+type Bool struct {
+	v int32
+}
+
+// NewBool returns a Bool of the initial value.
+func NewBool(value bool) *Bool {
+	b := &Bool{}
+	if value {
+		b.v = 1
+	}
+	return b
+}
+
+// Load returns the value of b.
+func (b *Bool) Load() bool {
+	return atomic.LoadInt32(&b.v) == 1
+}
+
+// String implements Var.
+func (b *Bool) String() string {
+	return strconv.FormatBool(b.Load())
+}
+
+// Set implements Var, value is parsed by strconv.ParseBool.
+func (b *Bool) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	var i int32
+	if v {
+		i = 1
+	}
+	atomic.StoreInt32(&b.v, i)
+	return nil
+}
+
+// Duration is a time.Duration setting, which must not be negative.
+type Duration struct {
+	v     int64
+	apply func(d time.Duration)
+}
+
+// NewDuration returns a Duration of the initial value.
+func NewDuration(value time.Duration) *Duration {
+	return &Duration{v: int64(value)}
+}
+
+// Load returns the value of d.
+func (d *Duration) Load() time.Duration {
+	return time.Duration(atomic.LoadInt64(&d.v))
+}
+
+// String implements Var.
+func (d *Duration) String() string {
+	return d.Load().String()
+}
+
+// Set implements Var, value is parsed by time.ParseDuration.
+func (d *Duration) Set(value string) error {
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return errors.New("negative duration")
+	}
+	atomic.StoreInt64(&d.v, int64(v))
+	if d.apply != nil {
+		d.apply(v)
+	}
+	return nil
+}
+
+type logLevel struct{}
+
+func (logLevel) String() string {
+	return hlog.GetLevel().String()
+}
+
+func (logLevel) Set(value string) error {
+	lv, err := hlog.ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	hlog.SetLevel(lv)
+	return nil
+}
+
+// RegisterTimeouts registers the read and idle timeouts of t to the default Registry
+// as "<prefix>.read_timeout" and "<prefix>.idle_timeout", e.g.
+//
+//	runtimeconfig.RegisterTimeouts("server", h.Timeouts())
+//
+// NOTE:
+//
+//	Zero read timeout means no timeout. Zero idle timeout is not accepted,
+//	since the way of handling idle connections is decided when the server starts.
+func RegisterTimeouts(prefix string, t *config.Timeouts) {
+	defaultRegistry.RegisterTimeouts(prefix, t)
+}
+
+// RegisterTimeouts registers the read and idle timeouts of t, see the package-level RegisterTimeouts.
+func (r *Registry) RegisterTimeouts(prefix string, t *config.Timeouts) {
+	r.Register(prefix+".read_timeout", "the timeout of reading a request", &Duration{v: int64(t.Read()), apply: t.SetRead})
+	r.Register(prefix+".idle_timeout", "the timeout of waiting for the next request of a keep-alive connection",
+		&positiveDuration{Duration{v: int64(t.Idle()), apply: t.SetIdle}})
+}
+
+type positiveDuration struct {
+	Duration
+}
+
+func (d *positiveDuration) Set(value string) error {
+	if v, err := time.ParseDuration(value); err == nil && v == 0 {
+		return errors.New("zero duration")
+	}
+	return d.Duration.Set(value)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package clienttrace provides the hooks to trace the stages of the requests sent by the client.
+package clienttrace
+
+import (
+	"context"
+	"time"
+)
+
+// ClientTrace is a set of hooks which are called at the stages of a request
+// sent by the client, any of them may be nil.
+//
+// NOTE:
+//
+//	The hooks are called for every attempt if the request is retried.
+//	DNSStart and DNSDone are called only if the DNS resolver of the client is set,
+//	the hosts are resolved by the dialer otherwise.
+type ClientTrace struct {
+	// GetConn is called before a connection is taken from the pool or dialed.
+	GetConn func(hostPort string)
+
+	// GotConn is called after a connection is obtained,
+	// reused reports whether the connection has been used by the previous requests.
+	GotConn func(reused bool)
+
+	// DNSStart is called before the host is resolved.
+	DNSStart func(host string)
+
+	// DNSDone is called after the host is resolved.
+	DNSDone func(addrs []string, err error)
+
+	// ConnectStart is called before a new connection is dialed.
+	ConnectStart func(network, addr string)
+
+	// ConnectDone is called after a new connection is dialed.
+	ConnectDone func(network, addr string, err error)
+
+	// TLSHandshakeStart is called before the TLS handshake of a new connection.
+	TLSHandshakeStart func()
+
+	// TLSHandshakeDone is called after the TLS handshake of a new connection.
+	TLSHandshakeDone func(err error)
+
+	// WroteHeaders is called after the request headers are written to the buffer of the connection.
+	WroteHeaders func()
+
+	// WroteRequest is called after the whole request is written and flushed.
+	WroteRequest func(err error)
+
+	// GotFirstResponseByte is called when the first byte of the response is available.
+	GotFirstResponseByte func()
+}
+
+// Timings are the durations of the stages of a request,
+// the stages which are not passed through are 0.
+type Timings struct {
+	// GetConn is the duration of obtaining the connection, including DNS, Connect and TLSHandshake.
+	GetConn time.Duration
+	// DNS is the duration of resolving the host.
+	DNS time.Duration
+	// Connect is the duration of dialing the new connection.
+	Connect time.Duration
+	// TLSHandshake is the duration of the TLS handshake of the new connection.
+	TLSHandshake time.Duration
+	// WaitResponse is the duration from the request being written to the first byte of the response.
+	WaitResponse time.Duration
+	// Total is the duration of the request including all attempts.
+	Total time.Duration
+}
+
+type clientTraceKey struct{}
+
+// WithClientTrace returns a new context carrying trace, the requests sent with it
+// call the hooks of trace, and record their Timings in the responses.
+func WithClientTrace(ctx context.Context, trace *ClientTrace) context.Context {
+	return context.WithValue(ctx, clientTraceKey{}, trace)
+}
+
+// ContextClientTrace returns the ClientTrace carried by ctx, or nil if there is none.
+func ContextClientTrace(ctx context.Context) *ClientTrace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(clientTraceKey{}).(*ClientTrace)
+	return trace
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package slowlog provides the tracer logging the requests exceeding a latency threshold.
+package slowlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/tracer"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+)
+
+// Info describes a slow request.
+//
+// NOTE:
+//
+//	The durations of the phases are zero if the trace level is lower than stats.LevelDetailed.
+type Info struct {
+	Method     string
+	Path       string
+	Route      string
+	Host       string
+	RemoteAddr string
+	UserAgent  string
+	StatusCode int
+	RecvSize   int
+	SendSize   int
+	Err        error
+
+	Latency    time.Duration
+	ReadHeader time.Duration
+	ReadBody   time.Duration
+	Handle     time.Duration
+	// Write includes Flush.
+	Write time.Duration
+	Flush time.Duration
+}
+
+// Callback is called with the slow requests after they are logged.
+type Callback func(ctx context.Context, info *Info)
+
+type slowTracer struct {
+	threshold time.Duration
+	callback  Callback
+}
+
+// NewTracer returns the tracer logging the requests whose latency from reading the request
+// to writing the response exceeds threshold, callback is called after logging if it is not nil.
+// It is usually added by server.WithSlowRequestThreshold.
+func NewTracer(threshold time.Duration, callback Callback) tracer.Tracer {
+	return &slowTracer{threshold: threshold, callback: callback}
+}
+
+func (t *slowTracer) Start(ctx context.Context, _ *app.RequestContext) context.Context {
+	return ctx
+}
+
+func (t *slowTracer) Finish(ctx context.Context, c *app.RequestContext) {
+	ti := c.GetTraceInfo()
+	if ti == nil {
+		return
+	}
+	st := ti.Stats()
+	latency := duration(st, stats.HTTPStart, stats.HTTPFinish)
+	if latency <= t.threshold {
+		return
+	}
+
+	info := &Info{
+		Method:     string(c.Method()),
+		Path:       string(c.Path()),
+		Route:      c.FullPath(),
+		Host:       string(c.Host()),
+		UserAgent:  string(c.UserAgent()),
+		StatusCode: c.Response.StatusCode(),
+		RecvSize:   st.RecvSize(),
+		SendSize:   st.SendSize(),
+		Err:        st.Error(),
+		Latency:    latency,
+		ReadHeader: duration(st, stats.ReadHeaderStart, stats.ReadHeaderFinish),
+		ReadBody:   duration(st, stats.ReadBodyStart, stats.ReadBodyFinish),
+		Handle:     duration(st, stats.ServerHandleStart, stats.ServerHandleFinish),
+		Write:      duration(st, stats.WriteStart, stats.WriteFinish),
+		Flush:      duration(st, stats.FlushStart, stats.FlushFinish),
+	}
+	if addr := c.RemoteAddr(); addr != nil {
+		info.RemoteAddr = addr.String()
+	}
+	hlog.SystemLogger().CtxWarnf(ctx, "Slow request: method=%s path=%s route=%s status=%d latency=%s "+
+		"read_header=%s read_body=%s handle=%s write=%s flush=%s recv_size=%d send_size=%d remote_addr=%s host=%s user_agent=%q err=%v",
+		info.Method, info.Path, info.Route, info.StatusCode, info.Latency,
+		info.ReadHeader, info.ReadBody, info.Handle, info.Write, info.Flush, info.RecvSize, info.SendSize,
+		info.RemoteAddr, info.Host, info.UserAgent, info.Err)
+	if t.callback != nil {
+		t.callback(ctx, info)
+	}
+}
+
+func duration(st traceinfo.HTTPStats, start, finish stats.Event) time.Duration {
+	s, f := st.GetEvent(start), st.GetEvent(finish)
+	if s == nil || f == nil || s.IsNil() || f.IsNil() {
+		return 0
+	}
+	return f.Time().Sub(s.Time())
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package slowlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+)
+
+func TestTracer(t *testing.T) {
+	var got *Info
+	tracer := NewTracer(10*time.Millisecond, func(ctx context.Context, info *Info) {
+		got = info
+	})
+
+	c := app.NewContext(0)
+	tracer.Finish(context.Background(), c)
+	assert.Nil(t, got)
+
+	ti := traceinfo.NewTraceInfo()
+	ti.Stats().SetLevel(stats.LevelDetailed)
+	c.SetTraceInfo(ti)
+	c.Request.SetRequestURI("/users/1")
+	c.Request.Header.SetUserAgentBytes([]byte("test"))
+
+	ti.Stats().Record(stats.HTTPStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.HTTPFinish, stats.StatusInfo, "")
+	tracer.Finish(context.Background(), c)
+	assert.Nil(t, got)
+
+	ti.Stats().Reset()
+	ti.Stats().Record(stats.HTTPStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.ServerHandleStart, stats.StatusInfo, "")
+	time.Sleep(20 * time.Millisecond)
+	ti.Stats().Record(stats.ServerHandleFinish, stats.StatusInfo, "")
+	ti.Stats().Record(stats.HTTPFinish, stats.StatusError, "boom")
+	ti.Stats().SetError(errors.New("boom"))
+	tracer.Finish(context.Background(), c)
+	assert.NotNil(t, got)
+	assert.DeepEqual(t, "GET", got.Method)
+	assert.DeepEqual(t, "/users/1", got.Path)
+	assert.DeepEqual(t, "test", got.UserAgent)
+	assert.DeepEqual(t, "boom", got.Err.Error())
+	assert.True(t, got.Latency >= 20*time.Millisecond)
+	assert.True(t, got.Handle >= 20*time.Millisecond)
+	assert.DeepEqual(t, time.Duration(0), got.ReadBody)
+}
@@ -68,6 +68,8 @@ const (
 	readBodyFinish
 	writeStart
 	writeFinish
+	flushStart
+	flushFinish
 	predefinedEventNum
 )
 
@@ -84,6 +86,10 @@ var (
 	ReadBodyFinish     = newEvent(readBodyFinish, LevelDetailed)
 	WriteStart         = newEvent(writeStart, LevelDetailed)
 	WriteFinish        = newEvent(writeFinish, LevelDetailed)
+	// FlushStart and FlushFinish bound flushing the written response to the connection,
+	// which is included in the write stage.
+	FlushStart  = newEvent(flushStart, LevelDetailed)
+	FlushFinish = newEvent(flushFinish, LevelDetailed)
 )
 
 // errors
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+const (
+	// HeaderTraceparent is the header carrying the span context defined by W3C Trace Context.
+	HeaderTraceparent = "traceparent"
+	// HeaderTracestate is the header carrying the vendor-specific trace data defined by W3C Trace Context.
+	HeaderTracestate = "tracestate"
+
+	flagSampled = 0x01
+)
+
+var errInvalidTraceparent = errors.NewPublic("trace: invalid traceparent")
+
+// Carrier is the headers the span context is injected into and extracted from,
+// which is implemented by protocol.RequestHeader and protocol.ResponseHeader.
+type Carrier interface {
+	Peek(key string) []byte
+	Set(key, value string)
+}
+
+// Inject sets the traceparent and tracestate of sc into carrier, it does nothing if sc is invalid.
+func Inject(sc SpanContext, carrier Carrier) {
+	if !sc.IsValid() {
+		return
+	}
+	carrier.Set(HeaderTraceparent, FormatTraceparent(sc))
+	if sc.TraceState != "" {
+		carrier.Set(HeaderTracestate, sc.TraceState)
+	}
+}
+
+// Extract returns the span context of the traceparent and tracestate in carrier,
+// and reports whether a valid traceparent is found.
+func Extract(carrier Carrier) (SpanContext, bool) {
+	sc, err := ParseTraceparent(string(carrier.Peek(HeaderTraceparent)))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = strings.TrimSpace(string(carrier.Peek(HeaderTracestate)))
+	sc.Remote = true
+	return sc, true
+}
+
+// FormatTraceparent returns the traceparent of sc in version 00.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses s in the format of "version-traceid-parentid-flags".
+//
+// NOTE:
+//
+//	The fields appended by the versions later than 00 are ignored as required by W3C Trace Context.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	s = strings.TrimSpace(s)
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, errInvalidTraceparent
+	}
+	version, err := decodeHex(s[:2])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(s) != 55) || (len(s) > 55 && s[55] != '-') {
+		return sc, errInvalidTraceparent
+	}
+	traceID, err := decodeHex(s[3:35])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	spanID, err := decodeHex(s[36:52])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	flags, err := decodeHex(s[53:55])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	if !sc.IsValid() {
+		return sc, errInvalidTraceparent
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+	return sc, nil
+}
+
+// decodeHex decodes the lowercase hex s, the uppercase is invalid in traceparent.
+func decodeHex(s string) ([]byte, error) {
+	if strings.ToLower(s) != s {
+		return nil, errInvalidTraceparent
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.DeepEqual(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.DeepEqual(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", FormatTraceparent(sc))
+
+	// the fields of the later versions are ignored
+	sc, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-02-extra")
+	assert.Nil(t, err)
+	assert.False(t, sc.Sampled)
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err = ParseTraceparent(s)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	var h protocol.RequestHeader
+	_, ok := Extract(&h)
+	assert.False(t, ok)
+
+	Inject(SpanContext{}, &h)
+	assert.DeepEqual(t, 0, len(h.Peek(HeaderTraceparent)))
+
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true, TraceState: "vendor=value"}
+	Inject(sc, &h)
+	got, ok := Extract(&h)
+	assert.True(t, ok)
+	sc.Remote = true
+	assert.DeepEqual(t, sc, got)
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace provides the spans propagated by W3C Trace Context, following the data model of
+// OpenTelemetry, so that the spans can be exported to an OpenTelemetry SDK or collector by an Exporter.
+package trace
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID is the identifier of a trace.
+type TraceID [16]byte
+
+// IsValid reports whether t is not all zeros.
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID is the identifier of a span.
+type SpanID [8]byte
+
+// IsValid reports whether s is not all zeros.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext is the part of a span propagated across the process boundary.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+	// Remote reports whether the span context is extracted from the incoming request.
+	Remote bool
+}
+
+// IsValid reports whether both the TraceID and SpanID of sc are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind is the role of a span in a trace.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// StatusCode is the status of a span.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusError
+	StatusOK
+)
+
+// Attribute is a key-value pair describing a span or an event.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an int attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a bool attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Event is a timestamped annotation of a span.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// Span is an operation in a trace, it is exported when it is ended if sampled.
+//
+// NOTE:
+//
+//	The fields must not be modified by the exporter, and the methods can be called concurrently.
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	Context       SpanContext
+	Parent        SpanContext
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    []Attribute
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+// IsRecording reports whether the span is sampled and not ended, the attributes and events
+// are dropped if it is not recording.
+func (s *Span) IsRecording() bool {
+	if s == nil || !s.Context.Sampled {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+// SetName overrides the name of the span.
+func (s *Span) SetName(name string) {
+	s.update(func() { s.Name = name })
+}
+
+// SetAttributes adds the attributes into the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.update(func() { s.Attributes = append(s.Attributes, attrs...) })
+}
+
+// AddEvent adds the event into the span.
+func (s *Span) AddEvent(name string, attrs ...Attribute) {
+	s.update(func() { s.Events = append(s.Events, Event{Name: name, Time: time.Now(), Attributes: attrs}) })
+}
+
+// RecordError adds an exception event of err into the span, it does not change the status.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", String("exception.message", err.Error()))
+}
+
+// SetStatus sets the status of the span, the message is kept only for StatusError.
+// An OK status can not be overridden.
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	s.update(func() {
+		if s.Status == StatusOK {
+			return
+		}
+		s.Status = code
+		s.StatusMessage = ""
+		if code == StatusError {
+			s.StatusMessage = msg
+		}
+	})
+}
+
+// End ends the span and exports it if it is sampled, the later calls have no effect.
+func (s *Span) End() {
+	if s == nil || !s.Context.Sampled {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.tracer.exporter.ExportSpan(s)
+}
+
+func (s *Span) update(f func()) {
+	if s == nil || !s.Context.Sampled {
+		return
+	}
+	s.mu.Lock()
+	if !s.ended {
+		f()
+	}
+	s.mu.Unlock()
+}
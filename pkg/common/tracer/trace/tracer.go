@@ -0,0 +1,179 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// Exporter receives the ended spans which are sampled.
+//
+// NOTE:
+//
+//	ExportSpan is called synchronously by Span.End, so it should be fast, e.g. by batching the spans.
+type Exporter interface {
+	ExportSpan(span *Span)
+}
+
+// ExporterFunc is an adapter to use a function as an Exporter.
+type ExporterFunc func(span *Span)
+
+// ExportSpan implements Exporter.
+func (f ExporterFunc) ExportSpan(span *Span) {
+	f(span)
+}
+
+// Tracer starts the spans exported by the same exporter.
+type Tracer struct {
+	exporter    Exporter
+	sampleRatio float64
+}
+
+type (
+	options struct {
+		sampleRatio float64
+	}
+
+	Option func(o *options)
+)
+
+// WithSampleRatio sets the ratio of the root spans being sampled, which is 1 by default.
+// The spans with a parent follow the sampling decision of the parent.
+func WithSampleRatio(ratio float64) Option {
+	return func(o *options) {
+		o.sampleRatio = ratio
+	}
+}
+
+// NewTracer creates a tracer exporting the spans by exporter.
+func NewTracer(exporter Exporter, opts ...Option) *Tracer {
+	cfg := &options{sampleRatio: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &Tracer{exporter: exporter, sampleRatio: cfg.sampleRatio}
+}
+
+// Start starts a span as the child of the span in ctx, or of the remote span context in ctx,
+// and returns the context carrying the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	var parent SpanContext
+	if s := SpanFromContext(ctx); s != nil {
+		parent = s.Context
+	} else if sc, ok := ctx.Value(remoteSpanContextKey{}).(SpanContext); ok {
+		parent = sc
+	}
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.shouldSample(sc.TraceID)
+	}
+
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		Context:   sc,
+		Parent:    parent,
+		StartTime: time.Now(),
+		tracer:    t,
+	}
+	if sc.Sampled {
+		span.Attributes = attrs
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// shouldSample decides by the lower 8 bytes of id, which are random as required by W3C Trace Context.
+func (t *Tracer) shouldSample(id TraceID) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	if t.sampleRatio <= 0 {
+		return false
+	}
+	return binary.BigEndian.Uint64(id[8:])>>1 < uint64(t.sampleRatio*(1<<63))
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		rand.Read(id[:]) //nolint:errcheck
+	}
+	return
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		rand.Read(id[:]) //nolint:errcheck
+	}
+	return
+}
+
+type (
+	spanKey              struct{}
+	remoteSpanContextKey struct{}
+)
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or nil if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying sc extracted from the incoming request,
+// which becomes the parent of the spans started with the returned context.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// The keys of the fields returned by LogFields.
+const (
+	LogKeyTraceID = "trace_id"
+	LogKeySpanID  = "span_id"
+)
+
+// LogFields returns the trace ID and the span ID of the span in ctx, or the remote span context
+// if there is no span, which can be registered with hlog.RegisterContextExtractor to correlate the logs with the traces.
+// It returns nil if ctx carries neither of them.
+func LogFields(ctx context.Context) map[string]interface{} {
+	var sc SpanContext
+	if span := SpanFromContext(ctx); span != nil {
+		sc = span.Context
+	} else if remote, ok := ctx.Value(remoteSpanContextKey{}).(SpanContext); ok {
+		sc = remote
+	}
+	if !sc.IsValid() {
+		return nil
+	}
+	return map[string]interface{}{
+		LogKeyTraceID: sc.TraceID.String(),
+		LogKeySpanID:  sc.SpanID.String(),
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestTracer(t *testing.T) {
+	var exported []*Span
+	tracer := NewTracer(ExporterFunc(func(span *Span) {
+		exported = append(exported, span)
+	}))
+
+	ctx, root := tracer.Start(context.Background(), "root", SpanKindServer, String("a", "b"))
+	assert.DeepEqual(t, root, SpanFromContext(ctx))
+	assert.True(t, root.Context.IsValid())
+	assert.True(t, root.Context.Sampled)
+	assert.False(t, root.Parent.IsValid())
+
+	_, child := tracer.Start(ctx, "child", SpanKindClient)
+	assert.DeepEqual(t, root.Context.TraceID, child.Context.TraceID)
+	assert.DeepEqual(t, root.Context.SpanID, child.Parent.SpanID)
+	child.SetAttributes(Int("n", 1), Bool("ok", true))
+	child.RecordError(errors.New("boom"))
+	child.SetStatus(StatusError, "boom")
+	child.End()
+	child.End()
+	child.SetName("ignored")
+
+	root.SetStatus(StatusOK, "ignored")
+	root.SetStatus(StatusError, "ignored")
+	assert.True(t, root.IsRecording())
+	root.End()
+	assert.False(t, root.IsRecording())
+
+	assert.DeepEqual(t, []*Span{child, root}, exported)
+	assert.DeepEqual(t, "child", child.Name)
+	assert.DeepEqual(t, []Attribute{{"n", 1}, {"ok", true}}, child.Attributes)
+	assert.DeepEqual(t, "exception", child.Events[0].Name)
+	assert.DeepEqual(t, "boom", child.StatusMessage)
+	assert.DeepEqual(t, StatusOK, root.Status)
+	assert.DeepEqual(t, "", root.StatusMessage)
+	assert.DeepEqual(t, []Attribute{{"a", "b"}}, root.Attributes)
+}
+
+func TestTracerRemoteParent(t *testing.T) {
+	exported := 0
+	tracer := NewTracer(ExporterFunc(func(span *Span) { exported++ }))
+
+	remote := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), TraceState: "k=v"}
+	_, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "server", SpanKindServer)
+	assert.DeepEqual(t, remote.TraceID, span.Context.TraceID)
+	assert.DeepEqual(t, "k=v", span.Context.TraceState)
+	assert.True(t, span.Parent.Remote)
+
+	// the sampling decision of the parent is followed
+	assert.False(t, span.Context.Sampled)
+	assert.False(t, span.IsRecording())
+	span.SetAttributes(String("a", "b"))
+	span.End()
+	assert.DeepEqual(t, 0, exported)
+	assert.DeepEqual(t, 0, len(span.Attributes))
+}
+
+func TestSampleRatio(t *testing.T) {
+	tracer := NewTracer(ExporterFunc(func(span *Span) {}), WithSampleRatio(0))
+	_, span := tracer.Start(context.Background(), "root", SpanKindServer)
+	assert.False(t, span.Context.Sampled)
+
+	tracer = NewTracer(ExporterFunc(func(span *Span) {}), WithSampleRatio(0.5))
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if _, span = tracer.Start(context.Background(), "root", SpanKindServer); span.Context.Sampled {
+			sampled++
+		}
+	}
+	assert.True(t, sampled > 350 && sampled < 650)
+}
+
+func TestLogFields(t *testing.T) {
+	assert.Nil(t, LogFields(context.Background()))
+
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Nil(t, err)
+	ctx := ContextWithRemoteSpanContext(context.Background(), sc)
+	assert.DeepEqual(t, map[string]interface{}{
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":  "00f067aa0ba902b7",
+	}, LogFields(ctx))
+
+	ctx, span := NewTracer(nil).Start(ctx, "server", SpanKindServer)
+	fields := LogFields(ctx)
+	assert.DeepEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[LogKeyTraceID])
+	assert.DeepEqual(t, span.Context.SpanID.String(), fields[LogKeySpanID])
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package traceinfo
+
+import (
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+)
+
+// Stage is a stage of serving a request bounded by a start event and a finish event.
+type Stage struct {
+	Name   string
+	Start  stats.Event
+	Finish stats.Event
+}
+
+// Stages are the stages recorded by the server in order,
+// all of them except "http" are recorded only at stats.LevelDetailed.
+var Stages = []Stage{
+	{Name: "http", Start: stats.HTTPStart, Finish: stats.HTTPFinish},
+	{Name: "read_header", Start: stats.ReadHeaderStart, Finish: stats.ReadHeaderFinish},
+	{Name: "read_body", Start: stats.ReadBodyStart, Finish: stats.ReadBodyFinish},
+	{Name: "handle", Start: stats.ServerHandleStart, Finish: stats.ServerHandleFinish},
+	{Name: "write", Start: stats.WriteStart, Finish: stats.WriteFinish},
+	{Name: "flush", Start: stats.FlushStart, Finish: stats.FlushFinish},
+}
+
+// StageTiming is the timing of a stage of serving a request.
+type StageTiming struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+	// Err reports whether the stage finished with an error.
+	Err bool
+}
+
+// Duration returns the time spent in the stage.
+func (t StageTiming) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// StageTimings returns the timings of the Stages recorded in ti in order,
+// the stages whose start or finish event is not recorded are omitted, e.g.
+//
+//	for _, st := range traceinfo.StageTimings(c.GetTraceInfo()) {
+//		span.SetAttributes(trace.Int(st.Name+".us", int(st.Duration().Microseconds())))
+//	}
+func StageTimings(ti TraceInfo) []StageTiming {
+	if ti == nil {
+		return nil
+	}
+	s := ti.Stats()
+	ret := make([]StageTiming, 0, len(Stages))
+	for _, stage := range Stages {
+		start, finish := s.GetEvent(stage.Start), s.GetEvent(stage.Finish)
+		if start == nil || finish == nil || start.IsNil() || finish.IsNil() {
+			continue
+		}
+		ret = append(ret, StageTiming{
+			Name:  stage.Name,
+			Start: start.Time(),
+			End:   finish.Time(),
+			Err:   start.Status() == stats.StatusError || finish.Status() == stats.StatusError,
+		})
+	}
+	return ret
+}
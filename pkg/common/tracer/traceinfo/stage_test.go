@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package traceinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+)
+
+func TestStageTimings(t *testing.T) {
+	assert.Nil(t, StageTimings(nil))
+
+	ti := NewTraceInfo()
+	ti.Stats().SetLevel(stats.LevelDetailed)
+	assert.DeepEqual(t, 0, len(StageTimings(ti)))
+
+	ti.Stats().Record(stats.HTTPStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.ServerHandleStart, stats.StatusInfo, "")
+	time.Sleep(time.Millisecond)
+	ti.Stats().Record(stats.ServerHandleFinish, stats.StatusInfo, "")
+	ti.Stats().Record(stats.WriteStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.FlushStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.FlushFinish, stats.StatusError, "broken pipe")
+	ti.Stats().Record(stats.WriteFinish, stats.StatusError, "broken pipe")
+	ti.Stats().Record(stats.HTTPFinish, stats.StatusError, "broken pipe")
+
+	timings := StageTimings(ti)
+	var names []string
+	for _, st := range timings {
+		names = append(names, st.Name)
+	}
+	assert.DeepEqual(t, []string{"http", "handle", "write", "flush"}, names)
+	assert.True(t, timings[1].Duration() >= time.Millisecond)
+	assert.False(t, timings[1].Err)
+	assert.True(t, timings[3].Err)
+	assert.True(t, !timings[3].Start.Before(timings[2].Start))
+	assert.True(t, !timings[3].End.After(timings[2].End))
+
+	// the detailed stages are not recorded at the base level
+	ti = NewTraceInfo()
+	ti.Stats().SetLevel(stats.LevelBase)
+	ti.Stats().Record(stats.HTTPStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.ServerHandleStart, stats.StatusInfo, "")
+	ti.Stats().Record(stats.ServerHandleFinish, stats.StatusInfo, "")
+	ti.Stats().Record(stats.HTTPFinish, stats.StatusInfo, "")
+	timings = StageTimings(ti)
+	assert.DeepEqual(t, 1, len(timings))
+	assert.DeepEqual(t, "http", timings[0].Name)
+}
@@ -28,6 +28,13 @@ type Tracer interface {
 	Finish(ctx context.Context, c *app.RequestContext)
 }
 
+// ConnTracer is optionally implemented by Tracer to observe the connections served by the engine,
+// ConnStart and ConnFinish are called when the engine starts and finishes serving a connection.
+type ConnTracer interface {
+	ConnStart(ctx context.Context)
+	ConnFinish(ctx context.Context)
+}
+
 type Controller interface {
 	Append(col Tracer)
 	DoStart(ctx context.Context, c *app.RequestContext) context.Context
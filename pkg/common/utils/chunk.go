@@ -30,24 +30,46 @@ import (
 var errBrokenChunk = errors.NewPublic("cannot find crlf at the end of chunk")
 
 func ParseChunkSize(r network.Reader) (int, error) {
+	n, _, err := ParseChunkSizeWithExtensions(r)
+	return n, err
+}
+
+// ParseChunkSizeWithExtensions is the same as ParseChunkSize,
+// except that the raw chunk extensions starting with ';' are returned as well, e.g. ";name=value".
+func ParseChunkSizeWithExtensions(r network.Reader) (int, []byte, error) {
 	n, err := bytesconv.ReadHexInt(r)
 	if err != nil {
 		if err == io.EOF {
 			err = io.ErrUnexpectedEOF
 		}
-		return -1, err
+		return -1, nil, err
 	}
+	var extensions []byte
 	for {
 		c, err := r.ReadByte()
 		if err != nil {
-			return -1, errors.NewPublic(fmt.Sprintf("cannot read '\r' char at the end of chunk size: %s", err))
+			return -1, nil, errors.NewPublic(fmt.Sprintf("cannot read '\r' char at the end of chunk size: %s", err))
+		}
+		if extensions != nil {
+			if c == '\r' {
+				break
+			}
+			if c == '\n' {
+				return -1, nil, errors.NewPublic("unexpected char '\\n' in chunk extensions")
+			}
+			extensions = append(extensions, c)
+			continue
 		}
 		// Skip any trailing whitespace after chunk size.
-		if c == ' ' {
+		if c == ' ' || c == '\t' {
+			continue
+		}
+		if c == ';' {
+			extensions = append(extensions, c)
 			continue
 		}
 		if c != '\r' {
-			return -1, errors.NewPublic(
+			return -1, nil, errors.NewPublic(
 				fmt.Sprintf("unexpected char %q at the end of chunk size. Expected %q", c, '\r'),
 			)
 		}
@@ -55,14 +77,14 @@ func ParseChunkSize(r network.Reader) (int, error) {
 	}
 	c, err := r.ReadByte()
 	if err != nil {
-		return -1, errors.NewPublic(fmt.Sprintf("cannot read '\n' char at the end of chunk size: %s", err))
+		return -1, nil, errors.NewPublic(fmt.Sprintf("cannot read '\n' char at the end of chunk size: %s", err))
 	}
 	if c != '\n' {
-		return -1, errors.NewPublic(
+		return -1, nil, errors.NewPublic(
 			fmt.Sprintf("unexpected char %q at the end of chunk size. Expected %q", c, '\n'),
 		)
 	}
-	return n, nil
+	return n, extensions, nil
 }
 
 func SkipCRLF(reader network.Reader) error {
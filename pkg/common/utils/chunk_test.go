@@ -70,3 +70,15 @@ func TestChunkReadFalseCRLF(t *testing.T) {
 	err := SkipCRLF(zr)
 	assert.DeepEqual(t, errBrokenChunk, err)
 }
+
+func TestChunkParseChunkSizeWithExtensions(t *testing.T) {
+	zr := mock.NewZeroCopyReader("a ;foo=bar;baz=\"x y\"\r\n")
+	chunkSize, extensions, err := ParseChunkSizeWithExtensions(zr)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 10, chunkSize)
+	assert.DeepEqual(t, ";foo=bar;baz=\"x y\"", string(extensions))
+
+	zr = mock.NewZeroCopyReader("a;foo=bar\n\r\n")
+	_, _, err = ParseChunkSizeWithExtensions(zr)
+	assert.True(t, err != nil)
+}
@@ -61,6 +61,14 @@ type Writer interface {
 	Flush() error
 }
 
+// VectorWriter is implemented by the connections which can write several buffers
+// in a single syscall, e.g. writev.
+type VectorWriter interface {
+	// WriteBuffers writes bufs in order as if they were concatenated.
+	// NOTE: The elements of bufs may be modified.
+	WriteBuffers(bufs [][]byte) (n int64, err error)
+}
+
 type ReadWriter interface {
 	Reader
 	Writer
@@ -17,6 +17,7 @@
 package network
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"time"
@@ -34,3 +35,23 @@ type Dialer interface {
 	// AddTLS will transfer a common connection to a tls connection.
 	AddTLS(conn Conn, tlsConfig *tls.Config) (Conn, error)
 }
+
+// ContextDialer is a Dialer which dials with the context of the request, so that
+// the connections can be routed or tagged by the values carried by ctx.
+// The client prefers DialContext and AddTLSContext if the Dialer implements ContextDialer.
+type ContextDialer interface {
+	Dialer
+
+	// DialContext is used to dial the peer end before the deadline of ctx,
+	// which is derived from the dial timeout of the client.
+	DialContext(ctx context.Context, network, address string, tlsConfig *tls.Config) (conn Conn, err error)
+
+	// AddTLSContext will transfer a common connection to a tls connection with the handshake done.
+	AddTLSContext(ctx context.Context, conn Conn, tlsConfig *tls.Config) (Conn, error)
+}
+
+// DialContextFunc dials address on the named network before the deadline of ctx.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// TLSUpgradeFunc upgrades conn to a tls connection with tlsConfig and completes the handshake.
+type TLSUpgradeFunc func(ctx context.Context, conn net.Conn, tlsConfig *tls.Config) (net.Conn, error)
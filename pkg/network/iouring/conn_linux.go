@@ -0,0 +1,217 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build linux
+// +build linux
+
+package iouring
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// conn is a net.Conn reading and writing the socket by the ring.
+//
+// NOTE:
+//
+//	The deadlines only take effect on the reads and writes started after they are set.
+type conn struct {
+	r      *ring
+	fd     int
+	local  net.Addr
+	remote net.Addr
+
+	readDeadline  int64
+	writeDeadline int64
+
+	// the fd is closed after the pending operations are completed,
+	// so that they never refer to another socket which reuses the fd.
+	mu       sync.Mutex
+	inflight int
+	closed   bool
+}
+
+func newConn(r *ring, fd int, network string) *conn {
+	c := &conn{r: r, fd: fd}
+	if sa, err := syscall.Getsockname(fd); err == nil {
+		c.local = sockaddrToAddr(network, sa)
+	}
+	if sa, err := syscall.Getpeername(fd); err == nil {
+		c.remote = sockaddrToAddr(network, sa)
+	}
+	return c
+}
+
+func (c *conn) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	c.inflight++
+	return true
+}
+
+func (c *conn) release() {
+	c.mu.Lock()
+	c.inflight--
+	closeFd := c.closed && c.inflight == 0
+	c.mu.Unlock()
+	if closeFd {
+		syscall.Close(c.fd)
+	}
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	if !c.acquire() {
+		return 0, c.opError("read", net.ErrClosed)
+	}
+	defer c.release()
+	n, err := c.r.recv(c.fd, b, deadline(&c.readDeadline))
+	if err != nil {
+		return n, c.opError("read", err)
+	}
+	if n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	if !c.acquire() {
+		return 0, c.opError("write", net.ErrClosed)
+	}
+	defer c.release()
+	written := 0
+	for written < len(b) {
+		n, err := c.r.send(c.fd, b[written:], deadline(&c.writeDeadline))
+		written += n
+		if err != nil {
+			return written, c.opError("write", err)
+		}
+	}
+	return written, nil
+}
+
+// WriteBuffers implements network.VectorWriter, the buffers are sent by sendmsg.
+func (c *conn) WriteBuffers(bufs [][]byte) (int64, error) {
+	if !c.acquire() {
+		return 0, c.opError("write", net.ErrClosed)
+	}
+	defer c.release()
+	var written int64
+	for len(bufs) > 0 {
+		n, err := c.r.sendmsg(c.fd, bufs, deadline(&c.writeDeadline))
+		written += int64(n)
+		if err != nil {
+			return written, c.opError("write", err)
+		}
+		for n > 0 || (len(bufs) > 0 && len(bufs[0]) == 0) {
+			if n < len(bufs[0]) {
+				bufs[0] = bufs[0][n:]
+				break
+			}
+			n -= len(bufs[0])
+			bufs[0] = nil
+			bufs = bufs[1:]
+		}
+	}
+	return written, nil
+}
+
+// Close shuts the socket down, which wakes up the pending reads and writes.
+func (c *conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	closeFd := c.inflight == 0
+	c.mu.Unlock()
+	syscall.Shutdown(c.fd, syscall.SHUT_RDWR) //nolint:errcheck
+	if closeFd {
+		return syscall.Close(c.fd)
+	}
+	return nil
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)  //nolint:errcheck
+	c.SetWriteDeadline(t) //nolint:errcheck
+	return nil
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	atomic.StoreInt64(&c.readDeadline, unixNano(t))
+	return nil
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	atomic.StoreInt64(&c.writeDeadline, unixNano(t))
+	return nil
+}
+
+// opError wraps err as net.Conn does, so that the timeouts are reported by net.Error
+// and errors.Is(err, os.ErrDeadlineExceeded).
+func (c *conn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: networkOf(c.local), Source: c.local, Addr: c.remote, Err: err}
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func deadline(d *int64) time.Time {
+	if ns := atomic.LoadInt64(d); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+func networkOf(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.Network()
+}
+
+func sockaddrToAddr(network string, sa syscall.Sockaddr) net.Addr {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return &net.TCPAddr{IP: append(net.IP(nil), sa.Addr[:]...), Port: sa.Port}
+	case *syscall.SockaddrInet6:
+		return &net.TCPAddr{IP: append(net.IP(nil), sa.Addr[:]...), Port: sa.Port}
+	case *syscall.SockaddrUnix:
+		return &net.UnixAddr{Name: sa.Name, Net: network}
+	}
+	return nil
+}
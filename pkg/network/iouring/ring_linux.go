@@ -0,0 +1,521 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build linux
+// +build linux
+
+package iouring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// The system calls of io_uring, which share the same numbers on all architectures.
+const (
+	sysSetup    = 425
+	sysEnter    = 426
+	sysRegister = 427
+)
+
+const (
+	offSQRing = 0
+	offCQRing = 0x8000000
+	offSQEs   = 0x10000000
+
+	featSingleMmap = 1 << 0
+	featNoDrop     = 1 << 1
+
+	enterGetEvents = 1 << 0
+	registerProbe  = 8
+
+	sqeIOLink = 1 << 2
+
+	opNop         = 0
+	opSendmsg     = 9
+	opAccept      = 13
+	opAsyncCancel = 14
+	opLinkTimeout = 15
+	opSend        = 26
+	opRecv        = 27
+
+	probeOpSupported = 1
+
+	// maxIovecs is IOV_MAX, the max number of the buffers sent by a sendmsg.
+	maxIovecs = 1024
+
+	// ringEntries is the number of the submission queue entries,
+	// the completion queue is twice as large.
+	ringEntries = 4096
+)
+
+// requiredOps are the operations the transporter relies on.
+var requiredOps = []uint8{opNop, opSendmsg, opAccept, opAsyncCancel, opLinkTimeout, opSend, opRecv}
+
+var errRingClosed = errors.New("io_uring is closed")
+
+type sqRingOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	userAddr                                                        uint64
+}
+
+type cqRingOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	userAddr                                                        uint64
+}
+
+// params is struct io_uring_params.
+type params struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  sqRingOffsets
+	cqOff                                                                  cqRingOffsets
+}
+
+// sqe is struct io_uring_sqe.
+type sqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	opFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	pad         uint64
+}
+
+// cqe is struct io_uring_cqe.
+type cqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// timespec is struct __kernel_timespec.
+type timespec struct {
+	sec  int64
+	nsec int64
+}
+
+// msghdr is struct msghdr, whose iovlen is size_t on all the architectures.
+type msghdr struct {
+	name       uintptr
+	namelen    uint32
+	iov        *syscall.Iovec
+	iovlen     uintptr
+	control    uintptr
+	controllen uintptr
+	flags      int32
+}
+
+// probe is struct io_uring_probe with the room of 256 struct io_uring_probe_op.
+type probe struct {
+	lastOp uint8
+	opsLen uint8
+	resv   uint16
+	resv2  [3]uint32
+	ops    [256]struct {
+		op    uint8
+		resv  uint8
+		flags uint16
+		resv2 uint32
+	}
+}
+
+// op is an operation submitted to the ring, which keeps the memory referred by
+// its sqe alive until it is completed.
+type op struct {
+	res  int32
+	done chan struct{}
+
+	buf  []byte
+	bufs [][]byte
+	iov  []syscall.Iovec
+	msg  *msghdr
+	ts   *timespec
+}
+
+// ring is an io_uring instance shared by the operations of a transporter.
+// The operations are submitted by the goroutines performing them and completed
+// by the goroutine running loop, which wakes up the submitters.
+type ring struct {
+	fd int
+
+	sqMem, cqMem, sqeMem []byte
+
+	sqHead, sqTail *uint32
+	sqMask         uint32
+	sqSize         uint32
+	sqArray        []uint32
+	sqes           []sqe
+
+	cqHead, cqTail *uint32
+	cqMask         uint32
+	cqes           []cqe
+
+	mu      sync.Mutex
+	pending map[uint64]*op
+	nextID  uint64
+	closed  bool
+	done    chan struct{}
+}
+
+func setup(entries uint32, p *params) (int, error) {
+	fd, _, errno := syscall.Syscall(sysSetup, uintptr(entries), uintptr(unsafe.Pointer(p)), 0)
+	if errno != 0 {
+		return -1, os.NewSyscallError("io_uring_setup", errno)
+	}
+	return int(fd), nil
+}
+
+func enter(fd int, toSubmit, minComplete, flags uint32) (int, error) {
+	n, _, errno := syscall.Syscall6(sysEnter, uintptr(fd), uintptr(toSubmit), uintptr(minComplete), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return int(n), errno
+	}
+	return int(n), nil
+}
+
+// checkSupported reports why io_uring can not be used by the transporter, or nil if it can.
+func checkSupported() error {
+	var p params
+	fd, err := setup(8, &p)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	if p.features&featSingleMmap == 0 || p.features&featNoDrop == 0 {
+		return fmt.Errorf("io_uring features=%#x are not supported", p.features)
+	}
+	var pb probe
+	_, _, errno := syscall.Syscall6(sysRegister, uintptr(fd), registerProbe, uintptr(unsafe.Pointer(&pb)), uintptr(len(pb.ops)), 0, 0)
+	if errno != 0 {
+		return os.NewSyscallError("io_uring_register", errno)
+	}
+	for _, o := range requiredOps {
+		if o > pb.lastOp || pb.ops[o].flags&probeOpSupported == 0 {
+			return fmt.Errorf("io_uring operation=%d is not supported", o)
+		}
+	}
+	return nil
+}
+
+func newRing(entries uint32) (r *ring, err error) {
+	var p params
+	fd, err := setup(entries, &p)
+	if err != nil {
+		return nil, err
+	}
+	r = &ring{fd: fd, pending: make(map[uint64]*op), done: make(chan struct{})}
+	defer func() {
+		if err != nil {
+			r.unmap()
+			syscall.Close(fd)
+		}
+	}()
+
+	sqSize := int(p.sqOff.array + p.sqEntries*4)
+	cqSize := int(p.cqOff.cqes + p.cqEntries*uint32(unsafe.Sizeof(cqe{})))
+	// the rings are mapped at once with IORING_FEAT_SINGLE_MMAP
+	if cqSize > sqSize {
+		sqSize = cqSize
+	}
+	if r.sqMem, err = syscall.Mmap(fd, offSQRing, sqSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		return nil, os.NewSyscallError("mmap", err)
+	}
+	r.cqMem = r.sqMem
+	sqeSize := int(p.sqEntries) * int(unsafe.Sizeof(sqe{}))
+	if r.sqeMem, err = syscall.Mmap(fd, offSQEs, sqeSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		return nil, os.NewSyscallError("mmap", err)
+	}
+
+	r.sqHead = (*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.head]))
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.tail]))
+	r.sqMask = *(*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.ringMask]))
+	r.sqSize = p.sqEntries
+	r.sqArray = (*[1 << 20]uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.array]))[:p.sqEntries:p.sqEntries]
+	r.sqes = (*[1 << 20]sqe)(unsafe.Pointer(&r.sqeMem[0]))[:p.sqEntries:p.sqEntries]
+
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.tail]))
+	r.cqMask = *(*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.ringMask]))
+	r.cqes = (*[1 << 20]cqe)(unsafe.Pointer(&r.cqMem[p.cqOff.cqes]))[:p.cqEntries:p.cqEntries]
+
+	go r.loop()
+	return r, nil
+}
+
+func (r *ring) unmap() {
+	if r.sqeMem != nil {
+		syscall.Munmap(r.sqeMem) //nolint:errcheck
+	}
+	if r.sqMem != nil {
+		syscall.Munmap(r.sqMem) //nolint:errcheck
+	}
+}
+
+// submit queues the entries with their operations and submits them to the kernel,
+// the entries linked by IOSQE_IO_LINK must be submitted at once.
+func (r *ring) submit(ops []*op, entries []sqe) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errRingClosed
+	}
+	return r.submitLocked(ops, entries)
+}
+
+func (r *ring) submitLocked(ops []*op, entries []sqe) error {
+	tail := atomic.LoadUint32(r.sqTail)
+	for i := range entries {
+		// the queue is drained by every enter, so it is only full if the kernel is not able to
+		// take the entries, in which case the entries are not queued
+		if tail-atomic.LoadUint32(r.sqHead) >= r.sqSize {
+			return syscall.EBUSY
+		}
+		r.nextID++
+		entries[i].userData = r.nextID
+		r.pending[r.nextID] = ops[i]
+		idx := tail & r.sqMask
+		r.sqes[idx] = entries[i]
+		r.sqArray[idx] = idx
+		tail++
+	}
+	atomic.StoreUint32(r.sqTail, tail)
+
+	for toSubmit := uint32(len(entries)); toSubmit > 0; {
+		n, err := enter(r.fd, toSubmit, 0, 0)
+		switch err {
+		case nil:
+			toSubmit -= uint32(n)
+		case syscall.EINTR, syscall.EAGAIN, syscall.EBUSY:
+			// the completions are being reaped by loop
+			time.Sleep(time.Millisecond)
+		default:
+			return os.NewSyscallError("io_uring_enter", err)
+		}
+	}
+	return nil
+}
+
+// loop reaps the completions until the ring is closed and all operations are completed.
+func (r *ring) loop() {
+	defer func() {
+		// wakes up the operations which are not completed if loop exits abnormally
+		r.mu.Lock()
+		ids := make([]uint64, 0, len(r.pending))
+		for id := range r.pending {
+			ids = append(ids, id)
+		}
+		r.mu.Unlock()
+		for _, id := range ids {
+			r.complete(id, -int32(syscall.ECANCELED))
+		}
+		close(r.done)
+	}()
+	for {
+		head := atomic.LoadUint32(r.cqHead)
+		tail := atomic.LoadUint32(r.cqTail)
+		if head == tail {
+			r.mu.Lock()
+			exit := r.closed && len(r.pending) == 0
+			r.mu.Unlock()
+			if exit {
+				return
+			}
+			if _, err := enter(r.fd, 0, 1, enterGetEvents); err != nil && err != syscall.EINTR {
+				if err == syscall.EBADF {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+			continue
+		}
+		for ; head != tail; head++ {
+			c := r.cqes[head&r.cqMask]
+			r.complete(c.userData, c.res)
+		}
+		atomic.StoreUint32(r.cqHead, head)
+	}
+}
+
+func (r *ring) complete(id uint64, res int32) {
+	r.mu.Lock()
+	o := r.pending[id]
+	delete(r.pending, id)
+	r.mu.Unlock()
+	if o != nil && o.done != nil {
+		o.res = res
+		close(o.done)
+	}
+}
+
+// do performs the operation of e and returns its result, the operation is canceled
+// with -ECANCELED if it does not complete before the deadline.
+func (r *ring) do(e sqe, o *op, deadline time.Time) (int32, error) {
+	o.done = make(chan struct{})
+	var err error
+	if deadline.IsZero() {
+		err = r.submit([]*op{o}, []sqe{e})
+	} else {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		ts := &timespec{sec: int64(d / time.Second), nsec: int64(d % time.Second)}
+		e.flags |= sqeIOLink
+		timeout := sqe{opcode: opLinkTimeout, fd: -1, addr: uint64(uintptr(unsafe.Pointer(ts))), len: 1}
+		err = r.submit([]*op{o, {ts: ts}}, []sqe{e, timeout})
+	}
+	if err != nil {
+		return 0, err
+	}
+	<-o.done
+	if o.res == -int32(syscall.ECANCELED) && !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, os.ErrDeadlineExceeded
+	}
+	return o.res, nil
+}
+
+func (r *ring) accept(fd int) (int, error) {
+	res, err := r.do(sqe{opcode: opAccept, fd: int32(fd), opFlags: syscall.SOCK_CLOEXEC}, &op{}, time.Time{})
+	if err != nil {
+		return -1, err
+	}
+	if res < 0 {
+		return -1, os.NewSyscallError("accept", syscall.Errno(-res))
+	}
+	return int(res), nil
+}
+
+func (r *ring) recv(fd int, b []byte, deadline time.Time) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	res, err := r.do(sqe{
+		opcode: opRecv,
+		fd:     int32(fd),
+		addr:   uint64(uintptr(unsafe.Pointer(&b[0]))),
+		len:    uint32(len(b)),
+	}, &op{buf: b}, deadline)
+	if err != nil {
+		return 0, err
+	}
+	if res < 0 {
+		return 0, os.NewSyscallError("recv", syscall.Errno(-res))
+	}
+	return int(res), nil
+}
+
+func (r *ring) send(fd int, b []byte, deadline time.Time) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	res, err := r.do(sqe{
+		opcode:  opSend,
+		fd:      int32(fd),
+		addr:    uint64(uintptr(unsafe.Pointer(&b[0]))),
+		len:     uint32(len(b)),
+		opFlags: syscall.MSG_NOSIGNAL,
+	}, &op{buf: b}, deadline)
+	if err != nil {
+		return 0, err
+	}
+	if res < 0 {
+		return 0, os.NewSyscallError("send", syscall.Errno(-res))
+	}
+	return int(res), nil
+}
+
+// sendmsg sends bufs in a single operation, at most maxIovecs of them are sent.
+func (r *ring) sendmsg(fd int, bufs [][]byte, deadline time.Time) (int, error) {
+	if len(bufs) > maxIovecs {
+		bufs = bufs[:maxIovecs]
+	}
+	iov := make([]syscall.Iovec, 0, len(bufs))
+	for _, b := range bufs {
+		if len(b) > 0 {
+			v := syscall.Iovec{Base: &b[0]}
+			v.SetLen(len(b))
+			iov = append(iov, v)
+		}
+	}
+	if len(iov) == 0 {
+		return 0, nil
+	}
+	msg := &msghdr{iov: &iov[0], iovlen: uintptr(len(iov))}
+	res, err := r.do(sqe{
+		opcode:  opSendmsg,
+		fd:      int32(fd),
+		addr:    uint64(uintptr(unsafe.Pointer(msg))),
+		len:     1,
+		opFlags: syscall.MSG_NOSIGNAL,
+	}, &op{bufs: bufs, iov: iov, msg: msg}, deadline)
+	if err != nil {
+		return 0, err
+	}
+	if res < 0 {
+		return 0, os.NewSyscallError("sendmsg", syscall.Errno(-res))
+	}
+	return int(res), nil
+}
+
+// close cancels the pending operations, waits for them to complete and releases the ring.
+func (r *ring) close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	ids := make([]uint64, 0, len(r.pending))
+	for id := range r.pending {
+		ids = append(ids, id)
+	}
+	var err error
+	for _, id := range ids {
+		if err = r.submitLocked([]*op{{}}, []sqe{{opcode: opAsyncCancel, fd: -1, addr: id}}); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		// wakes up loop even if there is nothing to cancel
+		err = r.submitLocked([]*op{{}}, []sqe{{opcode: opNop, fd: -1}})
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		// loop exits with EBADF once the ring is closed
+		syscall.Close(r.fd)
+		<-r.done
+	} else {
+		<-r.done
+		err = syscall.Close(r.fd)
+	}
+	r.unmap()
+	return err
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package iouring provides an experimental transporter for Linux, which accepts the connections
+// and reads and writes them by io_uring instead of the blocking system calls, e.g.
+//
+//	h := server.New(server.WithTransport(iouring.NewTransporter))
+//
+// or by the name registered to the server:
+//
+//	h := server.New(server.WithTransporter(server.TransporterIOUring))
+//
+// NOTE:
+//
+//	io_uring requires Linux 5.6 or later, the kernels without the required operations, the platforms
+//	other than Linux and the environments in which io_uring is disabled (e.g. by seccomp) fall back
+//	to the default transporter, which is netpoll except on windows. See Probe for the reason.
+//	The connections are served in a goroutine each, as the standard transporter does,
+//	and share one ring per transporter.
+package iouring
+
+import (
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+var (
+	probeOnce sync.Once
+	probeErr  error
+)
+
+// Probe returns the reason why io_uring can not be used by the transporter, or nil if it can.
+func Probe() error {
+	probeOnce.Do(func() {
+		probeErr = checkSupported()
+	})
+	return probeErr
+}
+
+// NewTransporter returns the io_uring transporter, or the fallback transporter if io_uring is not supported.
+func NewTransporter(options *config.Options) network.Transporter {
+	if err := Probe(); err != nil {
+		hlog.SystemLogger().Warnf("io_uring is not available, fall back to the default transporter: error=%v", err)
+		return fallback(options)
+	}
+	return newTransporter(options)
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build linux
+// +build linux
+
+package iouring
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+)
+
+type transporter struct {
+	readBufferSize   int
+	writeBufferSize  int
+	bufferPool       network.BufferPool
+	shaper           *network.Shaper
+	network          string
+	addr             string
+	keepAliveTimeout time.Duration
+	tls              *tls.Config
+	listenConfig     *net.ListenConfig
+	OnAccept         func(conn net.Conn) context.Context
+	OnConnect        func(ctx context.Context, conn network.Conn) context.Context
+	OnClose          func(ctx context.Context, conn network.Conn)
+
+	lock   sync.Mutex
+	ln     net.Listener
+	lnFile *os.File
+	ring   *ring
+	closed bool
+}
+
+func newTransporter(options *config.Options) network.Transporter {
+	return &transporter{
+		readBufferSize:   options.ReadBufferSize,
+		writeBufferSize:  options.WriteBufferSize,
+		bufferPool:       options.BufferPool,
+		shaper:           network.NewShaper(options.ConnBandwidth, options.TotalBandwidth),
+		network:          options.Network,
+		addr:             options.Addr,
+		keepAliveTimeout: options.KeepAliveTimeout,
+		tls:              options.TLS,
+		listenConfig:     options.ListenConfig,
+		ln:               options.Listener,
+		OnAccept:         options.OnAccept,
+		OnConnect:        options.OnConnect,
+		OnClose:          options.OnClose,
+	}
+}
+
+func (t *transporter) listen() (lnFd int, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return -1, net.ErrClosed
+	}
+	if t.ln == nil {
+		network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
+		if t.listenConfig != nil {
+			t.ln, err = t.listenConfig.Listen(context.Background(), t.network, t.addr)
+		} else {
+			t.ln, err = net.Listen(t.network, t.addr)
+		}
+		if err != nil {
+			return -1, err
+		}
+	}
+	filer, ok := t.ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		t.ln.Close()
+		return -1, &net.OpError{Op: "listen", Net: t.network, Err: syscall.EPROTONOSUPPORT}
+	}
+	// the duplicated fd of the listener is accepted by the ring
+	if t.lnFile, err = filer.File(); err != nil {
+		t.ln.Close()
+		return -1, err
+	}
+	if t.ring, err = newRing(ringEntries); err != nil {
+		t.lnFile.Close()
+		t.ln.Close()
+		return -1, err
+	}
+	return int(t.lnFile.Fd()), nil
+}
+
+// ListenAndServe accepts the connections by the ring and serves each of them in a new goroutine.
+func (t *transporter) ListenAndServe(onData network.OnData) error {
+	lnFd, err := t.listen()
+	if err != nil {
+		return err
+	}
+	hlog.SystemLogger().Infof("HTTP server listening on address=%s by io_uring", t.ln.Addr().String())
+	for {
+		fd, err := t.ring.accept(lnFd)
+		if err != nil {
+			if t.isClosed() {
+				// reports the same error as the closed listener does
+				err = &net.OpError{Op: "accept", Net: t.network, Addr: t.ln.Addr(), Err: net.ErrClosed}
+			}
+			hlog.SystemLogger().Errorf("Error=%s", err.Error())
+			return err
+		}
+		t.setSockopts(fd)
+		c := newConn(t.ring, fd, t.network)
+		ctx := context.Background()
+		if t.OnAccept != nil {
+			ctx = t.OnAccept(c)
+		}
+		stats := &network.ConnStats{}
+		ctx = network.NewContextWithConnStats(ctx, stats)
+		rc := network.CountConn(c, stats)
+		if t.shaper != nil {
+			rc = t.shaper.Wrap(rc)
+		}
+		var nc network.Conn
+		if t.tls != nil {
+			nc = standard.NewConn(tls.Server(rc, t.tls), t.readBufferSize, t.writeBufferSize, t.bufferPool)
+		} else {
+			nc = standard.NewConn(rc, t.readBufferSize, t.writeBufferSize, t.bufferPool)
+		}
+		if t.OnConnect != nil {
+			ctx = t.OnConnect(ctx, nc)
+		}
+		go t.serveConn(ctx, nc, onData)
+	}
+}
+
+func (t *transporter) serveConn(ctx context.Context, c network.Conn, onData network.OnData) {
+	onData(ctx, c) //nolint:errcheck
+	if t.OnClose != nil {
+		t.OnClose(ctx, c)
+	}
+}
+
+func (t *transporter) isClosed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.closed
+}
+
+func (t *transporter) setSockopts(fd int) {
+	if t.network == "unix" {
+		return
+	}
+	syscall.SetsockoptInt(fd, syscall.IPPROTO_TCP, syscall.TCP_NODELAY, 1) //nolint:errcheck
+	if t.keepAliveTimeout > 0 {
+		secs := int((t.keepAliveTimeout + time.Second - 1) / time.Second)
+		syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 1)      //nolint:errcheck
+		syscall.SetsockoptInt(fd, syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE, secs)  //nolint:errcheck
+		syscall.SetsockoptInt(fd, syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, secs) //nolint:errcheck
+	}
+}
+
+func (t *transporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	return t.Shutdown(ctx)
+}
+
+// Shutdown stops accepting the connections, and releases the ring when ctx is done,
+// after which the connections which are not closed fail to read and write.
+func (t *transporter) Shutdown(ctx context.Context) error {
+	defer func() {
+		network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
+	}()
+	t.lock.Lock()
+	if t.closed {
+		t.lock.Unlock()
+		return nil
+	}
+	t.closed = true
+	if t.lnFile != nil {
+		// wakes up the pending accept
+		syscall.Shutdown(int(t.lnFile.Fd()), syscall.SHUT_RDWR) //nolint:errcheck
+		t.ln.Close()
+	}
+	t.lock.Unlock()
+
+	<-ctx.Done()
+	if t.ring != nil {
+		// the pending accept is canceled if it is not woken up by the shutdown
+		t.ring.close() //nolint:errcheck
+		t.lnFile.Close()
+	}
+	return nil
+}
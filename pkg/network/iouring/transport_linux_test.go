@@ -0,0 +1,181 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build linux
+// +build linux
+
+package iouring
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+func skipIfUnsupported(t *testing.T) {
+	if err := Probe(); err != nil {
+		t.Skipf("io_uring is not supported: %v", err)
+	}
+}
+
+// echo serves the lines of conn until it fails.
+func echo(ctx context.Context, conn interface{}) error {
+	c := conn.(network.Conn)
+	defer c.Close()
+	for {
+		line, err := bufio.NewReader(c).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if _, err = c.WriteBinary([]byte(strings.ToUpper(line))); err != nil {
+			return err
+		}
+		if err = c.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	skipIfUnsupported(t)
+	r, err := newRing(8)
+	assert.Nil(t, err)
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	assert.Nil(t, err)
+	c := newConn(r, fds[0], "unix")
+	peer := newConn(r, fds[1], "unix")
+
+	n, err := c.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 5, n)
+	buf := make([]byte, 16)
+	n, err = peer.Read(buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello", string(buf[:n]))
+
+	// the buffers are sent by a single sendmsg
+	written, err := c.WriteBuffers([][]byte{[]byte("foo"), nil, []byte("bar")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(6), written)
+	n, err = peer.Read(buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "foobar", string(buf[:n]))
+
+	// the read is canceled by the deadline
+	peer.SetReadDeadline(time.Now().Add(50 * time.Millisecond)) //nolint:errcheck
+	start := time.Now()
+	_, err = peer.Read(buf)
+	assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	peer.SetReadDeadline(time.Time{}) //nolint:errcheck
+
+	// the pending read is woken up by the close of the peer
+	done := make(chan error, 1)
+	go func() {
+		_, err := peer.Read(buf)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, c.Close())
+	select {
+	case err = <-done:
+		assert.DeepEqual(t, "EOF", err.Error())
+	case <-time.After(time.Second):
+		t.Fatal("read is not woken up")
+	}
+	_, err = c.Read(buf)
+	assert.True(t, errors.Is(err, net.ErrClosed))
+
+	// the pending read is canceled by the close of the ring
+	go func() {
+		_, err := peer.Read(buf)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, r.close())
+	select {
+	case err = <-done:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("read is not canceled")
+	}
+	peer.Close()
+	_, err = r.recv(fds[1], buf, time.Time{})
+	assert.DeepEqual(t, errRingClosed, err)
+}
+
+func TestTransporter(t *testing.T) {
+	skipIfUnsupported(t)
+	opt := config.NewOptions(nil)
+	opt.Addr = "127.0.0.1:9235"
+	var accepted int32
+	opt.OnAccept = func(conn net.Conn) context.Context {
+		atomic.AddInt32(&accepted, 1)
+		assert.DeepEqual(t, "127.0.0.1:9235", conn.LocalAddr().String())
+		return context.Background()
+	}
+	trans := NewTransporter(opt)
+	_, ok := trans.(*transporter)
+	assert.True(t, ok)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- trans.ListenAndServe(echo)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	conns := make([]net.Conn, 8)
+	for i := range conns {
+		c, err := net.Dial("tcp", "127.0.0.1:9235")
+		assert.Nil(t, err)
+		conns[i] = c
+	}
+	for i, c := range conns {
+		msg := strings.Repeat("x", i*1000) + "hello\n"
+		_, err := c.Write([]byte(msg))
+		assert.Nil(t, err)
+		line, err := bufio.NewReader(c).ReadString('\n')
+		assert.Nil(t, err)
+		assert.DeepEqual(t, strings.ToUpper(msg), line)
+		c.Close()
+	}
+	assert.DeepEqual(t, int32(len(conns)), atomic.LoadInt32(&accepted))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, trans.Shutdown(ctx))
+	select {
+	case err := <-served:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ListenAndServe does not return")
+	}
+	_, err := net.Dial("tcp", "127.0.0.1:9235")
+	assert.NotNil(t, err)
+}
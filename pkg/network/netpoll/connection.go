@@ -29,6 +29,7 @@ import (
 
 type Conn struct {
 	network.Conn
+	stats *network.ConnStats
 }
 
 func (c *Conn) ToHertzError(err error) error {
@@ -46,12 +47,17 @@ func (c *Conn) Peek(n int) (b []byte, err error) {
 
 func (c *Conn) Read(p []byte) (int, error) {
 	n, err := c.Conn.Read(p)
+	c.stats.AddRead(int64(n))
 	err = normalizeErr(err)
 	return n, err
 }
 
 func (c *Conn) Skip(n int) error {
-	return c.Conn.Skip(n)
+	err := c.Conn.Skip(n)
+	if err == nil {
+		c.stats.AddRead(int64(n))
+	}
+	return err
 }
 
 func (c *Conn) Release() error {
@@ -64,22 +70,36 @@ func (c *Conn) Len() int {
 
 func (c *Conn) ReadByte() (b byte, err error) {
 	b, err = c.Conn.ReadByte()
+	if err == nil {
+		c.stats.AddRead(1)
+	}
 	err = normalizeErr(err)
 	return
 }
 
 func (c *Conn) ReadBinary(n int) (b []byte, err error) {
 	b, err = c.Conn.ReadBinary(n)
+	c.stats.AddRead(int64(len(b)))
 	err = normalizeErr(err)
 	return
 }
 
 func (c *Conn) Malloc(n int) (buf []byte, err error) {
-	return c.Conn.Malloc(n)
+	buf, err = c.Conn.Malloc(n)
+	c.stats.AddWritten(int64(len(buf)))
+	return
 }
 
 func (c *Conn) WriteBinary(b []byte) (n int, err error) {
-	return c.Conn.WriteBinary(b)
+	n, err = c.Conn.WriteBinary(b)
+	c.stats.AddWritten(int64(n))
+	return
+}
+
+func (c *Conn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	c.stats.AddWritten(int64(n))
+	return
 }
 
 func (c *Conn) Flush() error {
@@ -87,6 +107,10 @@ func (c *Conn) Flush() error {
 }
 
 func (c *Conn) HandleSpecificError(err error, rip string) (needIgnore bool) {
+	return handleSpecificError(err, rip)
+}
+
+func handleSpecificError(err error, rip string) (needIgnore bool) {
 	if errors.Is(err, netpoll.ErrConnClosed) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
 		// ignore flushing error when connection is closed or reset
 		if strings.Contains(err.Error(), "when flush") {
@@ -109,3 +133,8 @@ func normalizeErr(err error) error {
 func newConn(c netpoll.Connection) network.Conn {
 	return &Conn{Conn: c.(network.Conn)}
 }
+
+// newCountedConn returns the Conn counting the bytes consumed or written by hertz into stats.
+func newCountedConn(c netpoll.Connection, stats *network.ConnStats) network.Conn {
+	return &Conn{Conn: c.(network.Conn), stats: stats}
+}
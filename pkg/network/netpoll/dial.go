@@ -27,19 +27,21 @@ import (
 
 var errNotSupportTLS = errors.NewPublic("not support tls")
 
+const defaultTLSBufferSize = 4096
+
 type dialer struct {
 	netpoll.Dialer
 }
 
 func (d dialer) DialConnection(n, address string, timeout time.Duration, tlsConfig *tls.Config) (conn network.Conn, err error) {
-	if tlsConfig != nil {
-		// https
-		return nil, errNotSupportTLS
-	}
 	c, err := d.Dialer.DialConnection(n, address, timeout)
 	if err != nil {
 		return nil, err
 	}
+	if tlsConfig != nil {
+		// https
+		return newTLSConn(c, tlsConfig, true, defaultTLSBufferSize, nil), nil
+	}
 	conn = newConn(c)
 	return
 }
@@ -50,7 +52,19 @@ func (d dialer) DialTimeout(network, address string, timeout time.Duration, tlsC
 }
 
 func (d dialer) AddTLS(conn network.Conn, tlsConfig *tls.Config) (network.Conn, error) {
-	return nil, errNotSupportTLS
+	c, ok := conn.(*Conn)
+	if !ok {
+		return nil, errNotSupportTLS
+	}
+	raw, ok := c.Conn.(netpoll.Connection)
+	if !ok {
+		return nil, errNotSupportTLS
+	}
+	tlsConn := newTLSConn(raw, tlsConfig, true, defaultTLSBufferSize, nil)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
 }
 
 func NewDialer() network.Dialer {
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package netpoll
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/netpoll"
+)
+
+// errWouldBlock is returned by the probing reads if there is no data in the buffer of netpoll.
+// It is a temporary error so that it does not break the tls connection: crypto/tls keeps the
+// bytes of a partial record read before, and only records the errors which are not net.Error
+// or not Temporary as the permanent error of the connection.
+var errWouldBlock error = wouldBlockError{}
+
+type wouldBlockError struct{}
+
+func (wouldBlockError) Error() string   { return "no data buffered" }
+func (wouldBlockError) Timeout() bool   { return true }
+func (wouldBlockError) Temporary() bool { return true }
+
+// rawConn is the net.Conn under the tls connection, which reads and writes the
+// buffers of netpoll, so the tls records are handled without leaving the event loop.
+// The deadlines are converted to the timeouts of netpoll.
+type rawConn struct {
+	netpoll.Connection
+	probing int32
+}
+
+func (c *rawConn) Read(b []byte) (int, error) {
+	if atomic.LoadInt32(&c.probing) == 1 && c.Reader().Len() == 0 {
+		return 0, errWouldBlock
+	}
+	n, err := c.Connection.Read(b)
+	if errors.Is(err, netpoll.ErrReadTimeout) {
+		// the timeout is temporary for the tls connection
+		err = os.ErrDeadlineExceeded
+	}
+	return n, normalizeErr(err)
+}
+
+func (c *rawConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+func (c *rawConn) SetReadDeadline(t time.Time) error {
+	return c.SetReadTimeout(timeoutOf(t))
+}
+
+func (c *rawConn) SetWriteDeadline(t time.Time) error {
+	return c.SetWriteTimeout(timeoutOf(t))
+}
+
+// timeoutOf converts the deadline t to the timeout, 0 means no timeout.
+func timeoutOf(t time.Time) time.Duration {
+	if t.IsZero() {
+		return 0
+	}
+	if d := time.Until(t); d > 0 {
+		return d
+	}
+	return time.Nanosecond
+}
+
+// TLSConn is the tls connection served by netpoll.
+type TLSConn struct {
+	network.Conn
+	raw *rawConn
+}
+
+func newTLSConn(c netpoll.Connection, config *tls.Config, isClient bool, readBufferSize int, stats *network.ConnStats) *TLSConn {
+	raw := &rawConn{Connection: c}
+	var tc *tls.Conn
+	if isClient {
+		tc = tls.Client(network.CountConn(raw, stats), config)
+	} else {
+		tc = tls.Server(network.CountConn(raw, stats), config)
+	}
+	return &TLSConn{
+		Conn: standard.NewConn(tc, readBufferSize, 0, nil),
+		raw:  raw,
+	}
+}
+
+func (c *TLSConn) Handshake() error {
+	return c.Conn.(network.ConnTLSer).Handshake()
+}
+
+func (c *TLSConn) ConnectionState() tls.ConnectionState {
+	return c.Conn.(network.ConnTLSer).ConnectionState()
+}
+
+func (c *TLSConn) ToHertzError(err error) error {
+	if errors.Is(err, netpoll.ErrConnClosed) {
+		return errs.ErrConnectionClosed
+	}
+	return c.Conn.(network.ErrorNormalization).ToHertzError(err)
+}
+
+func (c *TLSConn) HandleSpecificError(err error, rip string) (needIgnore bool) {
+	return handleSpecificError(err, rip)
+}
+
+// buffered reports whether there is some data decrypted or buffered by tls,
+// which never triggers netpoll again.
+//
+// NOTE:
+//
+//	The data buffered inside tls.Conn, e.g. the next record read along with the current one,
+//	is not exposed, so it's probed by reading through tls.Conn instead of checking the buffer
+//	of netpoll. If a record is partially buffered, the probe consumes its bytes and fails with
+//	errWouldBlock, which relies on tls.Conn retaining them until the rest arrives.
+func (c *TLSConn) buffered() bool {
+	if c.Len() > 0 {
+		return true
+	}
+	atomic.StoreInt32(&c.raw.probing, 1)
+	defer atomic.StoreInt32(&c.raw.probing, 0)
+	_, err := c.Peek(1)
+	return err == nil
+}
+
+type tlsConnKey struct{}
+
+// serveTLS serves the tls connection prepared for c, until the data buffered by tls is handled.
+func serveTLS(ctx context.Context, onReq network.OnData) error {
+	c, _ := ctx.Value(tlsConnKey{}).(*TLSConn)
+	if c == nil {
+		return errs.NewPrivate("tls connection is not prepared")
+	}
+	for {
+		if err := onReq(ctx, c); err != nil || !c.raw.IsActive() || !c.buffered() {
+			return err
+		}
+	}
+}
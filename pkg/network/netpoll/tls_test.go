@@ -0,0 +1,176 @@
+// Copyright 2022 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build !windows
+// +build !windows
+
+package netpoll
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"math/big"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+// serveLine answers one line and returns to netpoll as the server with zero IdleTimeout does.
+func serveLine(ctx context.Context, conn interface{}) error {
+	c := conn.(network.Conn)
+	var line []byte
+	for {
+		b, err := c.ReadByte()
+		if err != nil {
+			return err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	c.WriteBinary([]byte(strings.ToUpper(string(line)))) //nolint:errcheck
+	return c.Flush()
+}
+
+func TestTLSTransporter(t *testing.T) {
+	var connected int32
+	opt := config.NewOptions(nil)
+	opt.Addr = "127.0.0.1:9237"
+	opt.TLS = newTestTLSConfig(t)
+	opt.OnConnect = func(ctx context.Context, conn network.Conn) context.Context {
+		if _, ok := conn.(network.ConnTLSer); ok {
+			atomic.AddInt32(&connected, 1)
+		}
+		return ctx
+	}
+	trans := NewTransporter(opt)
+	go trans.ListenAndServe(serveLine) //nolint:errcheck
+	defer trans.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	c, err := tls.Dial("tcp", opt.Addr, &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	defer c.Close()
+	// the second line is buffered by tls, which never triggers netpoll again
+	_, err = c.Write([]byte("foo\nbar\n"))
+	assert.Nil(t, err)
+	c.SetReadDeadline(time.Now().Add(time.Second)) //nolint:errcheck
+	r := bufio.NewReader(c)
+	line, err := r.ReadString('\n')
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "FOO\n", line)
+	line, err = r.ReadString('\n')
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "BAR\n", line)
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&connected))
+
+	// the tls connection dialed by netpoll
+	conn, err := NewDialer().DialConnection("tcp", opt.Addr, time.Second, &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, ok := conn.(network.ConnTLSer)
+	assert.True(t, ok)
+	conn.WriteBinary([]byte("baz\n")) //nolint:errcheck
+	assert.Nil(t, conn.Flush())
+	assert.Nil(t, conn.SetReadTimeout(time.Second))
+	b, err := conn.Peek(4)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "BAZ\n", string(b))
+}
+
+// holdConn holds the written data if hold is set, so that the tls records can be sent in pieces.
+type holdConn struct {
+	net.Conn
+	hold bool
+	held []byte
+}
+
+func (c *holdConn) Write(b []byte) (int, error) {
+	if c.hold {
+		c.held = append(c.held, b...)
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+func TestTLSProbePartialRecord(t *testing.T) {
+	opt := config.NewOptions(nil)
+	opt.Addr = "127.0.0.1:9240"
+	opt.TLS = newTestTLSConfig(t)
+	trans := NewTransporter(opt)
+	go trans.ListenAndServe(serveLine) //nolint:errcheck
+	defer trans.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	raw, err := net.Dial("tcp", opt.Addr)
+	assert.Nil(t, err)
+	hc := &holdConn{Conn: raw}
+	c := tls.Client(hc, &tls.Config{InsecureSkipVerify: true})
+	defer c.Close()
+	assert.Nil(t, c.Handshake())
+
+	// the records of both lines are held, and sent with the header of the second
+	// record split, which is read by the probe after the first line is answered
+	hc.hold = true
+	_, err = c.Write([]byte("foo\n"))
+	assert.Nil(t, err)
+	_, err = c.Write([]byte("bar\n"))
+	assert.Nil(t, err)
+	hc.hold = false
+	first := 5 + int(binary.BigEndian.Uint16(hc.held[3:5]))
+	_, err = raw.Write(hc.held[:first+3])
+	assert.Nil(t, err)
+
+	c.SetReadDeadline(time.Now().Add(time.Second)) //nolint:errcheck
+	r := bufio.NewReader(c)
+	line, err := r.ReadString('\n')
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "FOO\n", line)
+
+	// the rest of the record triggers netpoll, and tls goes on with the bytes read by the probe
+	time.Sleep(100 * time.Millisecond)
+	_, err = raw.Write(hc.held[first+3:])
+	assert.Nil(t, err)
+	line, err = r.ReadString('\n')
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "BAR\n", line)
+}
@@ -20,6 +20,7 @@ package netpoll
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"sync"
 	"time"
@@ -40,34 +41,45 @@ type transporter struct {
 	listener         net.Listener
 	eventLoop        netpoll.EventLoop
 	listenConfig     *net.ListenConfig
+	tls              *tls.Config
+	readBufferSize   int
 	OnAccept         func(conn net.Conn) context.Context
 	OnConnect        func(ctx context.Context, conn network.Conn) context.Context
+	OnClose          func(ctx context.Context, conn network.Conn)
 }
 
 // For transporter switch
 func NewTransporter(options *config.Options) network.Transporter {
+	if network.NewShaper(options.ConnBandwidth, options.TotalBandwidth) != nil {
+		hlog.SystemLogger().Warnf("Bandwidth limits are not supported by netpoll transporter, ignored")
+	}
 	return &transporter{
 		network:          options.Network,
 		addr:             options.Addr,
 		keepAliveTimeout: options.KeepAliveTimeout,
 		readTimeout:      options.ReadTimeout,
 		writeTimeout:     options.WriteTimeout,
-		listener:         nil,
+		listener:         options.Listener,
 		eventLoop:        nil,
 		listenConfig:     options.ListenConfig,
+		tls:              options.TLS,
+		readBufferSize:   options.ReadBufferSize,
 		OnAccept:         options.OnAccept,
 		OnConnect:        options.OnConnect,
+		OnClose:          options.OnClose,
 	}
 }
 
 // ListenAndServe binds listen address and keep serving, until an error occurs
 // or the transport shutdowns
 func (t *transporter) ListenAndServe(onReq network.OnData) (err error) {
-	network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
-	if t.listenConfig != nil {
-		t.listener, err = t.listenConfig.Listen(context.Background(), t.network, t.addr)
-	} else {
-		t.listener, err = net.Listen(t.network, t.addr)
+	if t.listener == nil {
+		network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
+		if t.listenConfig != nil {
+			t.listener, err = t.listenConfig.Listen(context.Background(), t.network, t.addr)
+		} else {
+			t.listener, err = net.Listen(t.network, t.addr)
+		}
 	}
 
 	if err != nil {
@@ -82,23 +94,48 @@ func (t *transporter) ListenAndServe(onReq network.OnData) (err error) {
 			if t.writeTimeout > 0 {
 				conn.SetWriteTimeout(t.writeTimeout)
 			}
+			ctx := context.Background()
 			if t.OnAccept != nil {
-				return t.OnAccept(newConn(conn))
+				ctx = t.OnAccept(newConn(conn))
+			}
+			stats := &network.ConnStats{}
+			ctx = network.NewContextWithConnStats(ctx, stats)
+			if t.tls != nil {
+				// The tls connection is kept in ctx, which is passed to OnConnect and OnRequest.
+				ctx = context.WithValue(ctx, tlsConnKey{}, newTLSConn(conn, t.tls, false, t.readBufferSize, stats))
 			}
-			return context.Background()
+			return ctx
 		}),
 	}
 
-	if t.OnConnect != nil {
+	if t.OnConnect != nil || t.OnClose != nil {
 		opts = append(opts, netpoll.WithOnConnect(func(ctx context.Context, conn netpoll.Connection) context.Context {
-			return t.OnConnect(ctx, newConn(conn))
+			var c network.Conn
+			if tc, ok := ctx.Value(tlsConnKey{}).(*TLSConn); ok {
+				c = tc
+			} else {
+				c = newCountedConn(conn, network.ConnStatsFromContext(ctx))
+			}
+			if t.OnConnect != nil {
+				ctx = t.OnConnect(ctx, c)
+			}
+			if t.OnClose != nil {
+				conn.AddCloseCallback(func(netpoll.Connection) error { //nolint:errcheck
+					t.OnClose(ctx, c)
+					return nil
+				})
+			}
+			return ctx
 		}))
 	}
 
 	// Create EventLoop
 	t.Lock()
 	t.eventLoop, err = netpoll.NewEventLoop(func(ctx context.Context, connection netpoll.Connection) error {
-		return onReq(ctx, newConn(connection))
+		if t.tls != nil {
+			return serveTLS(ctx, onReq)
+		}
+		return onReq(ctx, newCountedConn(connection, network.ConnStatsFromContext(ctx)))
 	}, opts...)
 	t.Unlock()
 	if err != nil {
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import "math/bits"
+
+const minBufferClassShift = 6 // 64B
+
+// BufferPool allocates and recycles the buffers of the connections.
+type BufferPool interface {
+	// Malloc returns a buffer whose len is size and cap is at least capacity.
+	Malloc(size, capacity int) []byte
+	// Free recycles buf, which must not be used after being freed.
+	Free(buf []byte)
+}
+
+type sizeClassPool struct {
+	minShift int
+	classes  []chan []byte
+}
+
+// NewBufferPool returns a BufferPool which rounds the capacity of the buffers up to
+// the size classes, the powers of two from minSize to maxSize, and retains at most
+// maxRetained free buffers per class.
+//
+// NOTE:
+//
+//	The buffers larger than maxSize are allocated directly and left to the garbage collector.
+//	A small maxRetained bounds the memory held by the pool when the connections are mostly idle,
+//	while a large maxSize avoids allocations for large bodies.
+func NewBufferPool(minSize, maxSize, maxRetained int) BufferPool {
+	minShift := minBufferClassShift
+	if minSize > 1<<minShift {
+		minShift = bits.Len(uint(minSize - 1))
+	}
+	maxShift := minShift
+	if maxSize > 1<<maxShift {
+		maxShift = bits.Len(uint(maxSize - 1))
+	}
+	if maxRetained < 0 {
+		maxRetained = 0
+	}
+	p := &sizeClassPool{
+		minShift: minShift,
+		classes:  make([]chan []byte, maxShift-minShift+1),
+	}
+	for i := range p.classes {
+		p.classes[i] = make(chan []byte, maxRetained)
+	}
+	return p
+}
+
+// class returns the index of the smallest class which can hold capacity bytes.
+func (p *sizeClassPool) class(capacity int) int {
+	if capacity <= 1<<p.minShift {
+		return 0
+	}
+	return bits.Len(uint(capacity-1)) - p.minShift
+}
+
+func (p *sizeClassPool) Malloc(size, capacity int) []byte {
+	if capacity < size {
+		capacity = size
+	}
+	if capacity == 0 {
+		return nil
+	}
+	idx := p.class(capacity)
+	if idx >= len(p.classes) {
+		return make([]byte, size, capacity)
+	}
+	select {
+	case buf := <-p.classes[idx]:
+		return buf[:size]
+	default:
+		return make([]byte, size, 1<<(p.minShift+idx))
+	}
+}
+
+func (p *sizeClassPool) Free(buf []byte) {
+	c := cap(buf)
+	if c == 0 {
+		return
+	}
+	idx := p.class(c)
+	if idx >= len(p.classes) || 1<<(p.minShift+idx) != c {
+		return
+	}
+	select {
+	case p.classes[idx] <- buf[:0]:
+	default:
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestBufferPool(t *testing.T) {
+	p := NewBufferPool(1000, 5000, 1)
+
+	buf := p.Malloc(10, 100)
+	assert.DeepEqual(t, 10, len(buf))
+	assert.DeepEqual(t, 1024, cap(buf))
+	p.Free(buf)
+	reused := p.Malloc(0, 1024)
+	assert.DeepEqual(t, 0, len(reused))
+	assert.DeepEqual(t, &buf[:1][0], &reused[:1][0])
+
+	// only one free buffer is retained per class
+	p.Free(reused)
+	p.Free(make([]byte, 1024))
+	assert.DeepEqual(t, 1, len(p.(*sizeClassPool).classes[0]))
+
+	buf = p.Malloc(3000, 3000)
+	assert.DeepEqual(t, 4096, cap(buf))
+	buf = p.Malloc(5000, 5000)
+	assert.DeepEqual(t, 8192, cap(buf))
+
+	// out of the classes
+	buf = p.Malloc(9000, 9000)
+	assert.DeepEqual(t, 9000, cap(buf))
+	p.Free(buf)
+	p.Free(make([]byte, 1000))
+	assert.DeepEqual(t, 0, len(p.(*sizeClassPool).classes[2]))
+	assert.Nil(t, p.Malloc(0, 0))
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rudp
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+)
+
+const defaultBufferSize = 4096
+
+// DialFunc dials the reliable session to addr within timeout.
+type DialFunc func(addr string, timeout time.Duration) (net.Conn, error)
+
+type dialer struct {
+	dial DialFunc
+}
+
+// NewDialer returns the network.Dialer dialing the sessions by dial, which can be used
+// by the client, see client.WithDialer.
+func NewDialer(dial DialFunc) network.Dialer {
+	return &dialer{dial: dial}
+}
+
+func (d *dialer) DialConnection(_, address string, timeout time.Duration, tlsConfig *tls.Config) (network.Conn, error) {
+	c, err := d.dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		c = tls.Client(c, tlsConfig)
+	}
+	return standard.NewConn(c, defaultBufferSize, defaultBufferSize, nil), nil
+}
+
+func (d *dialer) DialTimeout(_, address string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	c, err := d.dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		c = tls.Client(c, tlsConfig)
+	}
+	return c, nil
+}
+
+func (d *dialer) AddTLS(conn network.Conn, tlsConfig *tls.Config) (network.Conn, error) {
+	c := tls.Client(conn, tlsConfig)
+	if err := c.Handshake(); err != nil {
+		return nil, err
+	}
+	return standard.NewConn(c, defaultBufferSize, defaultBufferSize, nil), nil
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package rudp provides the transporter and the dialer running HTTP/1 over the sessions of
+// a reliable UDP protocol, e.g. KCP, so that the latency-sensitive deployments on lossy networks
+// can keep the existing HTTP/1 codec. The protocol is plugged in by the functions listening and
+// dialing its sessions, e.g. with github.com/xtaci/kcp-go:
+//
+//	listen := func(addr string) (net.Listener, error) {
+//		return kcp.ListenWithOptions(addr, nil, 10, 3)
+//	}
+//	h := server.New(server.WithHostPorts(":8888"), server.WithTransport(rudp.NewTransporter(listen)))
+//
+//	dial := func(addr string, timeout time.Duration) (net.Conn, error) {
+//		return kcp.DialWithOptions(addr, nil, 10, 3)
+//	}
+//	c, _ := client.NewClient(client.WithDialer(rudp.NewDialer(dial)))
+//
+// NOTE:
+//
+//	The sessions must be reliable and ordered streams, as the ones of KCP are.
+//	The sessions are served in a goroutine each, as the standard transporter does.
+//	The network of the server and the client is ignored, the address is passed to the functions as is.
+package rudp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+)
+
+// ListenFunc listens on addr and returns the listener accepting the reliable sessions.
+type ListenFunc func(addr string) (net.Listener, error)
+
+type transporter struct {
+	listen          ListenFunc
+	addr            string
+	readBufferSize  int
+	writeBufferSize int
+	bufferPool      network.BufferPool
+	shaper          *network.Shaper
+	tls             *tls.Config
+	OnAccept        func(conn net.Conn) context.Context
+	OnConnect       func(ctx context.Context, conn network.Conn) context.Context
+	OnClose         func(ctx context.Context, conn network.Conn)
+
+	lock sync.Mutex
+	ln   net.Listener
+}
+
+// NewTransporter returns the function creating the transporter, which serves the sessions
+// accepted by the listener of listen, or the one set by server.WithListener if any.
+func NewTransporter(listen ListenFunc) func(options *config.Options) network.Transporter {
+	return func(options *config.Options) network.Transporter {
+		return &transporter{
+			listen:          listen,
+			addr:            options.Addr,
+			readBufferSize:  options.ReadBufferSize,
+			writeBufferSize: options.WriteBufferSize,
+			bufferPool:      options.BufferPool,
+			shaper:          network.NewShaper(options.ConnBandwidth, options.TotalBandwidth),
+			tls:             options.TLS,
+			ln:              options.Listener,
+			OnAccept:        options.OnAccept,
+			OnConnect:       options.OnConnect,
+			OnClose:         options.OnClose,
+		}
+	}
+}
+
+func (t *transporter) ListenAndServe(onData network.OnData) (err error) {
+	t.lock.Lock()
+	if t.ln == nil {
+		t.ln, err = t.listen(t.addr)
+	}
+	t.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	hlog.SystemLogger().Infof("HTTP server listening on address=%s by reliable UDP", t.ln.Addr().String())
+	for {
+		conn, err := t.ln.Accept()
+		if err != nil {
+			hlog.SystemLogger().Errorf("Error=%s", err.Error())
+			return err
+		}
+		ctx := context.Background()
+		if t.OnAccept != nil {
+			ctx = t.OnAccept(conn)
+		}
+		stats := &network.ConnStats{}
+		ctx = network.NewContextWithConnStats(ctx, stats)
+		conn = network.CountConn(conn, stats)
+		if t.shaper != nil {
+			conn = t.shaper.Wrap(conn)
+		}
+		if t.tls != nil {
+			conn = tls.Server(conn, t.tls)
+		}
+		c := standard.NewConn(conn, t.readBufferSize, t.writeBufferSize, t.bufferPool)
+		if t.OnConnect != nil {
+			ctx = t.OnConnect(ctx, c)
+		}
+		go t.serveConn(ctx, c, onData)
+	}
+}
+
+func (t *transporter) serveConn(ctx context.Context, c network.Conn, onData network.OnData) {
+	onData(ctx, c) //nolint:errcheck
+	if t.OnClose != nil {
+		t.OnClose(ctx, c)
+	}
+}
+
+func (t *transporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	return t.Shutdown(ctx)
+}
+
+// Shutdown closes the listener, and waits until ctx is done for the sessions being served.
+func (t *transporter) Shutdown(ctx context.Context) error {
+	t.lock.Lock()
+	if t.ln != nil {
+		_ = t.ln.Close()
+	}
+	t.lock.Unlock()
+	<-ctx.Done()
+	return nil
+}
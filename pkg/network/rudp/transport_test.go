@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rudp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// The sessions are tcp connections in the test, which are reliable and ordered as the ones of KCP.
+func TestTransporterAndDialer(t *testing.T) {
+	var dialed int32
+	listen := func(addr string) (net.Listener, error) {
+		return net.Listen("tcp", addr)
+	}
+	dial := func(addr string, timeout time.Duration) (net.Conn, error) {
+		atomic.AddInt32(&dialed, 1)
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+
+	h := server.New(server.WithHostPorts("127.0.0.1:9238"), server.WithTransport(NewTransporter(listen)), server.WithExitWaitTime(0))
+	h.POST("/echo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Data(http.StatusOK, "text/plain", ctx.Request.Body())
+	})
+	go h.Run() //nolint:errcheck
+	time.Sleep(100 * time.Millisecond)
+	assert.DeepEqual(t, "rudp", h.GetTransporterName())
+
+	c, err := client.NewClient(client.WithDialer(NewDialer(dial)))
+	assert.Nil(t, err)
+	// the keep-alive session serves the requests one by one
+	for _, body := range []string{"a", strings.Repeat("b", 64*1024), "c"} {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		req.SetMethod(http.MethodPost)
+		req.SetRequestURI("http://127.0.0.1:9238/echo")
+		req.SetBodyString(body)
+		assert.Nil(t, c.Do(context.Background(), req, resp))
+		assert.DeepEqual(t, http.StatusOK, resp.StatusCode())
+		assert.DeepEqual(t, body, string(resp.Body()))
+		protocol.ReleaseRequest(req)
+		protocol.ReleaseResponse(resp)
+	}
+	assert.DeepEqual(t, int32(1), atomic.LoadInt32(&dialed))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, h.Shutdown(ctx))
+}
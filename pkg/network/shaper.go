@@ -0,0 +1,202 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const minShaperChunk = 1024
+
+// Bandwidth is the rate of the reads and the writes in bytes per second,
+// 0 means unlimited.
+type Bandwidth struct {
+	Read  int
+	Write int
+}
+
+// Shaper limits the bandwidth of the connections accepted by a transporter,
+// both the bandwidth of each connection and the total of all connections.
+//
+// NOTE:
+//
+//	The reads and writes are delayed to follow the rates, which may exceed their deadlines.
+//	The bytes are counted on the wire, i.e. including the TLS overhead.
+type Shaper struct {
+	perConn     Bandwidth
+	read, write *limiter
+}
+
+// NewShaper returns the Shaper limiting each connection by perConn and all the
+// connections by total, nil is returned if there is no limit at all.
+func NewShaper(perConn, total Bandwidth) *Shaper {
+	if perConn == (Bandwidth{}) && total == (Bandwidth{}) {
+		return nil
+	}
+	return &Shaper{
+		perConn: perConn,
+		read:    newLimiter(total.Read),
+		write:   newLimiter(total.Write),
+	}
+}
+
+// Wrap returns c whose reads and writes are limited by the Shaper.
+func (s *Shaper) Wrap(c net.Conn) net.Conn {
+	sc := &shapedConn{Conn: c}
+	sc.read, sc.readChunk = limitersOf(newLimiter(s.perConn.Read), s.read)
+	sc.write, sc.writeChunk = limitersOf(newLimiter(s.perConn.Write), s.write)
+	return sc
+}
+
+// limitersOf returns the non-nil limiters of ls, and the size of the chunk
+// passing them without waiting.
+func limitersOf(ls ...*limiter) ([]*limiter, int) {
+	var ret []*limiter
+	chunk := 0
+	for _, l := range ls {
+		if l == nil {
+			continue
+		}
+		ret = append(ret, l)
+		if chunk == 0 || l.burst < chunk {
+			chunk = l.burst
+		}
+	}
+	return ret, chunk
+}
+
+type shapedConn struct {
+	net.Conn
+	read, write           []*limiter
+	readChunk, writeChunk int
+}
+
+func wait(ls []*limiter, n int) {
+	var d time.Duration
+	for _, l := range ls {
+		if w := l.reserve(n); w > d {
+			d = w
+		}
+	}
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+func (c *shapedConn) Read(b []byte) (int, error) {
+	if len(c.read) == 0 {
+		return c.Conn.Read(b)
+	}
+	if len(b) > c.readChunk {
+		b = b[:c.readChunk]
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		wait(c.read, n)
+	}
+	return n, err
+}
+
+func (c *shapedConn) Write(b []byte) (n int, err error) {
+	if len(c.write) == 0 {
+		return c.Conn.Write(b)
+	}
+	for n < len(b) {
+		size := len(b) - n
+		if size > c.writeChunk {
+			size = c.writeChunk
+		}
+		wait(c.write, size)
+		m, err := c.Conn.Write(b[n : n+size])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// WriteBuffers keeps the vectored writes of the underlying connection
+// if the buffers fit in a chunk.
+func (c *shapedConn) WriteBuffers(bufs [][]byte) (n int64, err error) {
+	size := 0
+	for _, b := range bufs {
+		size += len(b)
+	}
+	if len(c.write) == 0 || size <= c.writeChunk {
+		if len(c.write) > 0 {
+			wait(c.write, size)
+		}
+		return WriteBuffers(c.Conn, bufs)
+	}
+	for _, b := range bufs {
+		m, err := c.Write(b)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// limiter is a token bucket, whose tokens may be borrowed from the future.
+// The borrower waits until the tokens are refilled.
+type limiter struct {
+	rate  float64 // tokens per nanosecond
+	burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns the limiter of rate bytes per second, whose burst is
+// 1/10 second of the rate, nil is returned if rate<=0.
+func newLimiter(rate int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := rate / 10
+	if burst < minShaperChunk {
+		burst = minShaperChunk
+	}
+	return &limiter{
+		rate:   float64(rate) / float64(time.Second),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n tokens and returns the duration to wait before using them.
+func (l *limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate)
+}
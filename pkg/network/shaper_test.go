@@ -0,0 +1,100 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	assert.Nil(t, newLimiter(0))
+
+	l := newLimiter(100 * 1024)
+	assert.DeepEqual(t, 10*1024, l.burst)
+	// the burst passes without waiting
+	assert.DeepEqual(t, time.Duration(0), l.reserve(10*1024))
+	// the borrowed tokens are refilled at the rate
+	d := l.reserve(10 * 1024)
+	assert.True(t, d > 90*time.Millisecond && d <= 100*time.Millisecond)
+
+	l = newLimiter(100)
+	assert.DeepEqual(t, minShaperChunk, l.burst)
+}
+
+func TestShaper(t *testing.T) {
+	assert.Nil(t, NewShaper(Bandwidth{}, Bandwidth{}))
+
+	s := NewShaper(Bandwidth{Write: 100 * 1024}, Bandwidth{Read: 50 * 1024})
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	sc := s.Wrap(c1).(*shapedConn)
+	assert.DeepEqual(t, 1, len(sc.read))
+	assert.DeepEqual(t, 5*1024, sc.readChunk)
+	assert.DeepEqual(t, 1, len(sc.write))
+	assert.DeepEqual(t, 10*1024, sc.writeChunk)
+
+	// 10KB are sent at once, and the other 20KB take 200ms
+	go io.Copy(io.Discard, c2) //nolint:errcheck
+	start := time.Now()
+	n, err := sc.Write(make([]byte, 30*1024))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 30*1024, n)
+	assert.True(t, time.Since(start) >= 190*time.Millisecond)
+
+	// the reads are limited by the total bandwidth shared by the connections
+	go c2.Write(make([]byte, 10*1024)) //nolint:errcheck
+	buf := make([]byte, 10*1024)
+	n, err = sc.Read(buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 5*1024, n)
+	start = time.Now()
+	n, err = s.Wrap(c1).Read(buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 5*1024, n)
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+}
+
+func TestShapedWriteBuffers(t *testing.T) {
+	vw := &mockVectorConn{}
+	sc := NewShaper(Bandwidth{Write: 100 * 1024}, Bandwidth{}).Wrap(vw).(*shapedConn)
+	n, err := sc.WriteBuffers([][]byte{[]byte("foo"), []byte("bar")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(6), n)
+	assert.DeepEqual(t, 1, vw.calls)
+
+	// larger than a chunk
+	n, err = sc.WriteBuffers([][]byte{make([]byte, 8*1024), make([]byte, 8*1024)})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(16*1024), n)
+	assert.DeepEqual(t, 1, vw.calls)
+	assert.DeepEqual(t, 6+16*1024, vw.Len())
+}
+
+type mockVectorConn struct {
+	net.Conn
+	mockVectorWriter
+}
+
+func (m *mockVectorConn) Write(b []byte) (int, error) {
+	return m.mockVectorWriter.Write(b)
+}
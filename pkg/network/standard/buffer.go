@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/bytedance/gopkg/lang/mcache"
+	"github.com/cloudwego/hertz/pkg/network"
 )
 
 var bufferPool = sync.Pool{}
@@ -36,6 +37,7 @@ type linkBufferNode struct {
 	malloc   int             // write-offset
 	next     *linkBufferNode // the next node of the linked buffer
 	readOnly bool            // whether this node is a read only node
+	pool     network.BufferPool
 }
 
 type linkBuffer struct {
@@ -57,10 +59,11 @@ func (l *linkBuffer) release() {
 	}
 }
 
-// newBufferNode creates a new node with buffer size
-func newBufferNode(size int) *linkBufferNode {
+// newBufferNode creates a new node with buffer size from pool
+func newBufferNode(pool network.BufferPool, size int) *linkBufferNode {
 	buf := bufferPool.Get().(*linkBufferNode)
-	buf.buf = malloc(size, size)
+	buf.buf = malloc(pool, size, size)
+	buf.pool = pool
 	return buf
 }
 
@@ -90,27 +93,35 @@ func (b *linkBufferNode) Cap() int {
 // Release will recycle the buffer of node
 func (b *linkBufferNode) Release() {
 	if !b.readOnly {
-		free(b.buf)
+		free(b.pool, b.buf)
 	}
 	b.readOnly = false
 	b.buf = nil
+	b.pool = nil
 	b.next = nil
 	b.malloc, b.off = 0, 0
 	bufferPool.Put(b)
 }
 
-// malloc limits the cap of the buffer from mcache.
-func malloc(size, capacity int) []byte {
+// malloc limits the cap of the buffer from pool, mcache is used if pool is nil.
+func malloc(pool network.BufferPool, size, capacity int) []byte {
 	if capacity > mallocMax {
 		return make([]byte, size, capacity)
 	}
+	if pool != nil {
+		return pool.Malloc(size, capacity)
+	}
 	return mcache.Malloc(size, capacity)
 }
 
-// free limits the cap of the buffer from mcache.
-func free(buf []byte) {
+// free limits the cap of the buffer returned to pool, mcache is used if pool is nil.
+func free(pool network.BufferPool, buf []byte) {
 	if cap(buf) > mallocMax {
 		return
 	}
+	if pool != nil {
+		pool.Free(buf)
+		return
+	}
 	mcache.Free(buf)
 }
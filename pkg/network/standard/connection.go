@@ -44,6 +44,9 @@ type Conn struct {
 	outputBuffer *linkBuffer
 	caches       [][]byte // buf allocated by Next when cross-package, which should be freed when release
 	maxSize      int      // history max malloc size
+	writeSize    int      // min malloc size of outputBuffer
+	vec          [][]byte // buffers of outputBuffer to be flushed
+	pool         network.BufferPool
 }
 
 func (c *Conn) ToHertzError(err error) error {
@@ -211,7 +214,7 @@ func (c *Conn) SetWriteDeadline(t time.Time) error {
 
 func (c *Conn) releaseCaches() {
 	for i := range c.caches {
-		free(c.caches[i])
+		free(c.pool, c.caches[i])
 		c.caches[i] = nil
 	}
 	c.caches = c.caches[:0]
@@ -286,7 +289,7 @@ func (c *Conn) Release() error {
 func (c *Conn) handleTail() {
 	if cap(c.inputBuffer.write.buf) > mallocMax {
 		node := c.inputBuffer.write
-		c.inputBuffer.write.next = newBufferNode(c.maxSize)
+		c.inputBuffer.write.next = newBufferNode(c.pool, c.maxSize)
 		c.inputBuffer.write = c.inputBuffer.write.next
 		node.Release()
 		return
@@ -310,7 +313,7 @@ func (c *Conn) Peek(i int) (p []byte, err error) {
 
 	// not enough data in a signal node
 	if block1k < i && i <= mallocMax {
-		p = malloc(i, i)
+		p = malloc(c.pool, i, i)
 		c.caches = append(c.caches, p)
 	} else {
 		p = make([]byte, i)
@@ -365,7 +368,7 @@ func (c *Conn) fill(i int) (err error) {
 		if i < c.maxSize {
 			malloc = c.maxSize
 		}
-		c.inputBuffer.write.next = newBufferNode(malloc)
+		c.inputBuffer.write.next = newBufferNode(c.pool, malloc)
 		c.inputBuffer.write = c.inputBuffer.write.next
 		// Set readOnly flag to false so that current node can be recycled.
 		// In inputBuffer, whether readOnly value is, the node need to be recycled.
@@ -456,10 +459,10 @@ func (c *Conn) Malloc(n int) (buf []byte, err error) {
 	}
 
 	mallocSize := n
-	if n < defaultMallocSize {
-		mallocSize = defaultMallocSize
+	if n < c.writeSize {
+		mallocSize = c.writeSize
 	}
-	node := newBufferNode(mallocSize)
+	node := newBufferNode(c.pool, mallocSize)
 	node.malloc = n
 	c.outputBuffer.len = cap(node.buf) - n
 	c.outputBuffer.write.next = node
@@ -480,7 +483,7 @@ func (c *Conn) WriteBinary(b []byte) (n int, err error) {
 		return copy(buf, b), nil
 	}
 	// Build a new node with buffer b.
-	node := newBufferNode(0)
+	node := newBufferNode(c.pool, 0)
 	node.malloc = len(b)
 	node.readOnly = true
 	node.buf = b
@@ -491,6 +494,9 @@ func (c *Conn) WriteBinary(b []byte) (n int, err error) {
 }
 
 // Flush will send data to the peer end.
+//
+// NOTE: The buffered nodes are sent in a single syscall if the underlying
+// connection supports vectored writes, see network.WriteBuffers.
 func (c *Conn) Flush() (err error) {
 	// No data to flush
 	if c.outputBuffer.head == c.outputBuffer.write && c.outputBuffer.head.Len() == 0 {
@@ -504,25 +510,35 @@ func (c *Conn) Flush() (err error) {
 		node.Release()
 	}
 
-	for {
-		n, err := c.c.Write(c.outputBuffer.head.buf[c.outputBuffer.head.off:c.outputBuffer.head.malloc])
-		if err != nil {
-			return err
+	for node := c.outputBuffer.head; ; node = node.next {
+		if node.Len() > 0 {
+			c.vec = append(c.vec, node.buf[node.off:node.malloc])
 		}
-		c.outputBuffer.head.off += n
-		if c.outputBuffer.head == c.outputBuffer.write {
-			// If the capacity of buffer is less than 8k, then just reset the node
-			if c.outputBuffer.head.recyclable() {
-				c.outputBuffer.head.Reset()
-				c.outputBuffer.len = cap(c.outputBuffer.head.buf)
-			}
+		if node == c.outputBuffer.write {
 			break
 		}
-		// Flush next node
+	}
+	_, err = network.WriteBuffers(c.c, c.vec)
+	for i := range c.vec {
+		c.vec[i] = nil
+	}
+	c.vec = c.vec[:0]
+	if err != nil {
+		return err
+	}
+
+	// Release the flushed nodes except the tail one
+	for c.outputBuffer.head != c.outputBuffer.write {
 		node := c.outputBuffer.head
 		c.outputBuffer.head = c.outputBuffer.head.next
 		node.Release()
 	}
+	c.outputBuffer.head.off = c.outputBuffer.head.malloc
+	// If the capacity of buffer is less than 8k, then just reset the node
+	if c.outputBuffer.head.recyclable() {
+		c.outputBuffer.head.Reset()
+		c.outputBuffer.len = cap(c.outputBuffer.head.buf)
+	}
 	return nil
 }
 
@@ -534,13 +550,44 @@ func (c *TLSConn) ConnectionState() tls.ConnectionState {
 	return c.c.(network.ConnTLSer).ConnectionState()
 }
 
+// NewConn returns the buffered network.Conn of c, so that the transporters built on
+// net.Conn can reuse the buffers of the standard transporter.
+// The returned Conn implements network.ConnTLSer if c is a *tls.Conn.
+//
+// NOTE:
+//
+//	readSize is the initial size of the read buffer, which grows with the requests.
+//	writeSize is the min size of the write buffer allocated for the responses.
+//	The buffers are allocated from pool, and mcache is used if pool is nil.
+func NewConn(c net.Conn, readSize, writeSize int, pool network.BufferPool) network.Conn {
+	conn := newBufferedConn(c, readSize, writeSize, pool)
+	if _, ok := c.(*tls.Conn); ok {
+		return &TLSConn{*conn}
+	}
+	return conn
+}
+
 func newConn(c net.Conn, size int) network.Conn {
+	return newBufferedConn(c, size, defaultMallocSize, nil)
+}
+
+func newTLSConn(c net.Conn, size int) network.Conn {
+	return &TLSConn{*newBufferedConn(c, size, defaultMallocSize, nil)}
+}
+
+func newBufferedConn(c net.Conn, readSize, writeSize int, pool network.BufferPool) *Conn {
 	maxSize := defaultMallocSize
-	if size > maxSize {
-		maxSize = size
+	if readSize > 0 {
+		maxSize = readSize
+	}
+	if maxSize < block1k {
+		maxSize = block1k
 	}
-	inputNode := newBufferNode(maxSize)
-	outputNode := newBufferNode(0)
+	if writeSize <= 0 {
+		writeSize = defaultMallocSize
+	}
+	inputNode := newBufferNode(pool, maxSize)
+	outputNode := newBufferNode(pool, 0)
 	return &Conn{
 		c: c,
 		inputBuffer: &linkBuffer{
@@ -552,30 +599,8 @@ func newConn(c net.Conn, size int) network.Conn {
 			head:  outputNode,
 			write: outputNode,
 		},
-		maxSize: maxSize,
-	}
-}
-
-func newTLSConn(c net.Conn, size int) network.Conn {
-	maxSize := defaultMallocSize
-	if size > maxSize {
-		maxSize = size
-	}
-	node := newBufferNode(maxSize)
-	outputNode := newBufferNode(0)
-	return &TLSConn{
-		Conn{
-			c: c,
-			inputBuffer: &linkBuffer{
-				head:  node,
-				read:  node,
-				write: node,
-			},
-			outputBuffer: &linkBuffer{
-				head:  outputNode,
-				write: outputNode,
-			},
-			maxSize: maxSize,
-		},
+		maxSize:   maxSize,
+		writeSize: writeSize,
+		pool:      pool,
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network"
 )
 
 func TestRead(t *testing.T) {
@@ -335,3 +336,85 @@ func (m *mockAddr) Network() string {
 func (m *mockAddr) String() string {
 	return m.address
 }
+
+func TestNewConn(t *testing.T) {
+	c := &mockConn{}
+	_, ok := NewConn(c, 4096, 4096, nil).(*Conn)
+	assert.True(t, ok)
+
+	_, ok = NewConn(tls.Server(c, &tls.Config{}), 4096, 4096, nil).(*TLSConn)
+	assert.True(t, ok)
+}
+
+type countingPool struct {
+	network.BufferPool
+	mallocs, frees int
+}
+
+func (p *countingPool) Malloc(size, capacity int) []byte {
+	p.mallocs++
+	return p.BufferPool.Malloc(size, capacity)
+}
+
+func (p *countingPool) Free(buf []byte) {
+	p.frees++
+	p.BufferPool.Free(buf)
+}
+
+func TestConnBufferPool(t *testing.T) {
+	c := &mockConn{}
+	pool := &countingPool{BufferPool: network.NewBufferPool(1024, 64*1024, 8)}
+	conn := NewConn(c, 1024, 16*1024, pool).(*Conn)
+	assert.DeepEqual(t, 1024, cap(conn.inputBuffer.head.buf))
+
+	buf, err := conn.Malloc(10)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 16*1024, cap(buf))
+	copy(buf, "0123456789")
+	assert.Nil(t, conn.Flush())
+	assert.DeepEqual(t, "0123456789", c.buffer.String())
+
+	p, err := conn.Peek(2048)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 2048, len(p))
+	err = conn.Skip(2048)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Release())
+	assert.True(t, pool.mallocs > 2)
+	assert.True(t, pool.frees > 0)
+}
+
+type mockVectorConn struct {
+	mockConn
+	calls int
+}
+
+func (m *mockVectorConn) WriteBuffers(bufs [][]byte) (n int64, err error) {
+	m.calls++
+	for _, b := range bufs {
+		m.buffer.Write(b)
+		n += int64(len(b))
+	}
+	return
+}
+
+func TestFlushVectored(t *testing.T) {
+	c := &mockVectorConn{}
+	conn := newConn(c, 4096)
+	buf, _ := conn.Malloc(5)
+	copy(buf, "chunk")
+	body := bytes.Repeat([]byte("a"), 8192)
+	conn.WriteBinary(body)           //nolint:errcheck
+	conn.WriteBinary([]byte("\r\n")) //nolint:errcheck
+	assert.Nil(t, conn.Flush())
+	assert.DeepEqual(t, 1, c.calls)
+	assert.DeepEqual(t, "chunk"+string(body)+"\r\n", c.buffer.String())
+
+	connection := conn.(*Conn)
+	assert.True(t, connection.outputBuffer.head == connection.outputBuffer.write)
+	assert.DeepEqual(t, 0, len(connection.vec))
+
+	// nothing to flush
+	assert.Nil(t, conn.Flush())
+	assert.DeepEqual(t, 1, c.calls)
+}
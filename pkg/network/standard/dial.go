@@ -17,6 +17,7 @@
 package standard
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"time"
@@ -55,3 +56,97 @@ func (d *dialer) AddTLS(conn network.Conn, tlsConfig *tls.Config) (network.Conn,
 func NewDialer() network.Dialer {
 	return &dialer{}
 }
+
+type contextDialer struct {
+	dial    network.DialContextFunc
+	upgrade network.TLSUpgradeFunc
+}
+
+// NewContextDialer returns a network.ContextDialer which dials by dial and upgrades
+// the connections to tls by upgrade, e.g. for happy-eyeballs or connection tagging.
+//
+// NOTE:
+//
+//	If dial==nil, net.Dialer is used.
+//	If upgrade==nil, the tls handshake is done by crypto/tls lazily on the first read or write
+//	of the dialed connections, and eagerly for the connections upgraded by AddTLS.
+func NewContextDialer(dial network.DialContextFunc, upgrade network.TLSUpgradeFunc) network.ContextDialer {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return &contextDialer{dial: dial, upgrade: upgrade}
+}
+
+func (d *contextDialer) DialContext(ctx context.Context, n, address string, tlsConfig *tls.Config) (network.Conn, error) {
+	c, err := d.dial(ctx, n, address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return newConn(c, defaultMallocSize), nil
+	}
+	if d.upgrade == nil {
+		return newTLSConn(tls.Client(c, tlsConfig), defaultMallocSize), nil
+	}
+	tc, err := d.upgrade(ctx, c, tlsConfig)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return wrapConn(tc), nil
+}
+
+func (d *contextDialer) AddTLSContext(ctx context.Context, conn network.Conn, tlsConfig *tls.Config) (network.Conn, error) {
+	upgrade := d.upgrade
+	if upgrade == nil {
+		upgrade = handshake
+	}
+	tc, err := upgrade(ctx, conn, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return wrapConn(tc), nil
+}
+
+func (d *contextDialer) DialConnection(n, address string, timeout time.Duration, tlsConfig *tls.Config) (network.Conn, error) {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+	return d.DialContext(ctx, n, address, tlsConfig)
+}
+
+func (d *contextDialer) DialTimeout(n, address string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+	return d.dial(ctx, n, address)
+}
+
+func (d *contextDialer) AddTLS(conn network.Conn, tlsConfig *tls.Config) (network.Conn, error) {
+	return d.AddTLSContext(context.Background(), conn, tlsConfig)
+}
+
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// handshake is the default TLSUpgradeFunc, which bounds the handshake by the deadline of ctx.
+func handshake(ctx context.Context, conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
+	tc := tls.Client(conn, tlsConfig)
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+func wrapConn(c net.Conn) network.Conn {
+	if tc, ok := c.(*tls.Conn); ok {
+		return newTLSConn(tc, defaultMallocSize)
+	}
+	return newConn(c, defaultMallocSize)
+}
@@ -37,6 +37,9 @@ type transport struct {
 	//
 	// Default buffer size is used if not set.
 	readBufferSize   int
+	writeBufferSize  int
+	bufferPool       network.BufferPool
+	shaper           *network.Shaper
 	network          string
 	addr             string
 	keepAliveTimeout time.Duration
@@ -48,15 +51,18 @@ type transport struct {
 	lock             sync.Mutex
 	OnAccept         func(conn net.Conn) context.Context
 	OnConnect        func(ctx context.Context, conn network.Conn) context.Context
+	OnClose          func(ctx context.Context, conn network.Conn)
 }
 
 func (t *transport) serve() (err error) {
-	network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
 	t.lock.Lock()
-	if t.listenConfig != nil {
-		t.ln, err = t.listenConfig.Listen(context.Background(), t.network, t.addr)
-	} else {
-		t.ln, err = net.Listen(t.network, t.addr)
+	if t.ln == nil {
+		network.UnlinkUdsFile(t.network, t.addr) //nolint:errcheck
+		if t.listenConfig != nil {
+			t.ln, err = t.listenConfig.Listen(context.Background(), t.network, t.addr)
+		} else {
+			t.ln, err = net.Listen(t.network, t.addr)
+		}
 	}
 	t.lock.Unlock()
 	if err != nil {
@@ -75,17 +81,30 @@ func (t *transport) serve() (err error) {
 		if t.OnAccept != nil {
 			ctx = t.OnAccept(conn)
 		}
+		stats := &network.ConnStats{}
+		ctx = network.NewContextWithConnStats(ctx, stats)
+		conn = network.CountConn(conn, stats)
 
+		if t.shaper != nil {
+			conn = t.shaper.Wrap(conn)
+		}
 		if t.tls != nil {
-			c = newTLSConn(tls.Server(conn, t.tls), t.readBufferSize)
+			c = &TLSConn{*newBufferedConn(tls.Server(conn, t.tls), t.readBufferSize, t.writeBufferSize, t.bufferPool)}
 		} else {
-			c = newConn(conn, t.readBufferSize)
+			c = newBufferedConn(conn, t.readBufferSize, t.writeBufferSize, t.bufferPool)
 		}
 
 		if t.OnConnect != nil {
 			ctx = t.OnConnect(ctx, c)
 		}
-		go t.handler(ctx, c)
+		go t.serveConn(ctx, c)
+	}
+}
+
+func (t *transport) serveConn(ctx context.Context, c network.Conn) {
+	t.handler(ctx, c) //nolint:errcheck
+	if t.OnClose != nil {
+		t.OnClose(ctx, c)
 	}
 }
 
@@ -117,13 +136,18 @@ func (t *transport) Shutdown(ctx context.Context) error {
 func NewTransporter(options *config.Options) network.Transporter {
 	return &transport{
 		readBufferSize:   options.ReadBufferSize,
+		writeBufferSize:  options.WriteBufferSize,
+		bufferPool:       options.BufferPool,
+		shaper:           network.NewShaper(options.ConnBandwidth, options.TotalBandwidth),
 		network:          options.Network,
 		addr:             options.Addr,
 		keepAliveTimeout: options.KeepAliveTimeout,
 		readTimeout:      options.ReadTimeout,
 		tls:              options.TLS,
 		listenConfig:     options.ListenConfig,
+		ln:               options.Listener,
 		OnAccept:         options.OnAccept,
 		OnConnect:        options.OnConnect,
+		OnClose:          options.OnClose,
 	}
 }
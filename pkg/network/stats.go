@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+)
+
+// ConnStats counts the bytes read from and written to a connection accepted by a transporter,
+// including the request lines, headers and the bodies of all the requests served on it,
+// e.g. for billing, quotas or access logs.
+//
+// The ConnStats of a connection can be got by ConnStatsFromContext from the context passed to
+// the handlers, OnConnect and OnClose.
+//
+// NOTE:
+//
+//	The bytes are counted on the wire by the go net, io_uring and reliable UDP transporters,
+//	i.e. including the TLS overhead, and the bytes consumed or written by hertz are counted by
+//	netpoll transporter unless it serves tls.
+type ConnStats struct {
+	read    int64
+	written int64
+}
+
+// BytesRead returns the number of bytes read from the connection so far.
+func (s *ConnStats) BytesRead() int64 {
+	return atomic.LoadInt64(&s.read)
+}
+
+// BytesWritten returns the number of bytes written to the connection so far.
+func (s *ConnStats) BytesWritten() int64 {
+	return atomic.LoadInt64(&s.written)
+}
+
+// AddRead adds n bytes to the bytes read, it does nothing if s==nil.
+func (s *ConnStats) AddRead(n int64) {
+	if s != nil && n > 0 {
+		atomic.AddInt64(&s.read, n)
+	}
+}
+
+// AddWritten adds n bytes to the bytes written, it does nothing if s==nil.
+func (s *ConnStats) AddWritten(n int64) {
+	if s != nil && n > 0 {
+		atomic.AddInt64(&s.written, n)
+	}
+}
+
+type connStatsKey struct{}
+
+// NewContextWithConnStats returns a copy of ctx carrying stats.
+func NewContextWithConnStats(ctx context.Context, stats *ConnStats) context.Context {
+	return context.WithValue(ctx, connStatsKey{}, stats)
+}
+
+// ConnStatsFromContext returns the ConnStats carried by ctx, or nil if there is none.
+func ConnStatsFromContext(ctx context.Context) *ConnStats {
+	s, _ := ctx.Value(connStatsKey{}).(*ConnStats)
+	return s
+}
+
+// CountConn returns c counting the bytes read from and written to it into stats,
+// c is returned as is if stats==nil.
+func CountConn(c net.Conn, stats *ConnStats) net.Conn {
+	if stats == nil {
+		return c
+	}
+	return &countedConn{Conn: c, stats: stats}
+}
+
+type countedConn struct {
+	net.Conn
+	stats *ConnStats
+}
+
+func (c *countedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.stats.AddRead(int64(n))
+	return n, err
+}
+
+func (c *countedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.stats.AddWritten(int64(n))
+	return n, err
+}
+
+// WriteBuffers keeps the vectored writes of the underlying connection.
+func (c *countedConn) WriteBuffers(bufs [][]byte) (int64, error) {
+	n, err := WriteBuffers(c.Conn, bufs)
+	c.stats.AddWritten(n)
+	return n, err
+}
+
+// ReadFrom keeps the ReadFrom of the underlying connection, e.g. sendfile of TCP connections.
+func (c *countedConn) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	var err error
+	if rf, ok := c.Conn.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(c.Conn, r)
+	}
+	c.stats.AddWritten(n)
+	return n, err
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestCountConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	assert.DeepEqual(t, c1, CountConn(c1, nil))
+
+	stats := &ConnStats{}
+	c := CountConn(c1, stats)
+	received := make(chan int)
+	go func() {
+		c2.Write([]byte("hello")) //nolint:errcheck
+		b, _ := ioutil.ReadAll(c2)
+		received <- len(b)
+	}()
+
+	buf := make([]byte, 8)
+	n, err := c.Read(buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 5, n)
+	assert.DeepEqual(t, int64(5), stats.BytesRead())
+
+	_, err = c.Write([]byte("abc"))
+	assert.Nil(t, err)
+	_, err = WriteBuffers(c, [][]byte{[]byte("de"), []byte("f")})
+	assert.Nil(t, err)
+	_, err = c.(io.ReaderFrom).ReadFrom(strings.NewReader("ghij"))
+	assert.Nil(t, err)
+	c.Close()
+	assert.DeepEqual(t, 10, <-received)
+	assert.DeepEqual(t, int64(10), stats.BytesWritten())
+}
+
+func TestConnStatsContext(t *testing.T) {
+	assert.Nil(t, ConnStatsFromContext(context.Background()))
+	stats := &ConnStats{}
+	ctx := NewContextWithConnStats(context.Background(), stats)
+	assert.DeepEqual(t, stats, ConnStatsFromContext(ctx))
+
+	// the nil stats are ignored
+	var nilStats *ConnStats
+	nilStats.AddRead(1)
+	nilStats.AddWritten(1)
+}
@@ -20,6 +20,20 @@ import (
 	"context"
 )
 
+// Transporter is the network layer of the server, which accepts the connections
+// and hands them over to the protocol layer by OnData.
+// The custom transporters are plugged into the server by server.WithTransport,
+// or registered by server.RegisterTransporter and chosen by server.WithTransporter.
+//
+// The lifecycle of a transporter is:
+//
+//  1. It is created from the options of the server when the server is created,
+//     it should not listen before ListenAndServe is called.
+//  2. ListenAndServe is called once by Spin or Run, which listens on the address of the options,
+//     serves the connections and blocks until the listener is closed or fails.
+//  3. Shutdown is called once on graceful shutdown, which stops accepting new connections and
+//     returns when ctx is done, the server waits for the in-flight requests in the meantime.
+//     Close is called if the server is closed without waiting.
 type Transporter interface {
 	// Close the transporter immediately
 	Close() error
@@ -31,5 +45,13 @@ type Transporter interface {
 	ListenAndServe(onData OnData) error
 }
 
-// Callback when data is ready on the connection
+// OnData is the callback when data is ready on the connection.
+//
+// NOTE:
+//
+//	conn must implement Conn, or StreamConn for the stream-based protocols, otherwise it is ignored.
+//	onData serves the requests of conn until it returns, and it closes conn if it returns an error.
+//	The transporters of the blocking mode (e.g. the standard one) call onData in a new goroutine
+//	for each connection. The transporters of the event-driven mode (e.g. netpoll) call it each time
+//	conn becomes readable, in which case the IdleTimeout of the options must not be zero.
 type OnData func(ctx context.Context, conn interface{}) error
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+// Tunnel copies the data between a and b in both directions, e.g. after a connection is
+// upgraded by a proxy, which is the same as a Tunneler with no timeouts, see Tunneler.Tunnel.
+func Tunnel(a, b Conn) error {
+	return (&Tunneler{}).Tunnel(a, b, nil)
+}
+
+// TunnelWithIdleTimeout is the same as Tunnel, except that the tunnel is closed once no data
+// is read from either a or b for idleTimeout, and errs.ErrIdleTimeout is returned.
+// There is no idle timeout if idleTimeout <= 0.
+func TunnelWithIdleTimeout(a, b Conn, idleTimeout time.Duration) error {
+	return (&Tunneler{IdleTimeout: idleTimeout}).Tunnel(a, b, nil)
+}
+
+// Tunneler tunnels the connections with the timeouts of it, which can be shared by the tunnels.
+type Tunneler struct {
+	// IdleTimeout closes the tunnel once no data is read from either connection for it,
+	// and errs.ErrIdleTimeout is returned. Zero means no idle timeout.
+	IdleTimeout time.Duration
+
+	// MaxDuration closes the tunnel once it has been open for it, and errs.ErrTimeout
+	// is returned. Zero means no limit.
+	MaxDuration time.Duration
+
+	// WriteTimeout is the write timeout of both connections while tunneling, zero means no timeout.
+	WriteTimeout time.Duration
+}
+
+// Tunnel copies the data between a and b in both directions. Once either direction ends or the
+// tunnel times out, the underlying connections of both are closed to stop the other one, and
+// Tunnel returns after both directions are done. The error of the direction ending first is
+// returned, which is nil if it ends with io.EOF.
+//
+// The read timeouts of a and b are cleared since the tunnel is closed by either side or by
+// IdleTimeout, and their write timeouts are set to WriteTimeout.
+//
+// The bytes copied are counted into stats as they are read, i.e. the bytes read from a are
+// counted as read and the bytes read from b as written. stats may be nil.
+//
+// NOTE:
+//
+//	The buffers of a and b are not released, which is done by calling Close after Tunnel
+//	returns, e.g. by the server for a hijacked connection.
+func (t *Tunneler) Tunnel(a, b Conn, stats *ConnStats) error {
+	for _, c := range []Conn{a, b} {
+		if err := c.SetReadTimeout(0); err != nil {
+			return err
+		}
+		if err := c.SetWriteTimeout(t.WriteTimeout); err != nil {
+			return err
+		}
+	}
+
+	var last int64
+	atomic.StoreInt64(&last, time.Now().UnixNano())
+	errc := make(chan error, 2)
+	go copyConn(b, &tunnelReader{r: a, last: &last, count: stats.AddRead}, errc)
+	go copyConn(a, &tunnelReader{r: b, last: &last, count: stats.AddWritten}, errc)
+
+	pending, err := t.wait(errc, &last)
+	closeUnderlying(a)
+	closeUnderlying(b)
+	for ; pending > 0; pending-- {
+		<-errc
+	}
+	if errors.Is(err, net.ErrClosed) {
+		err = nil
+	}
+	return err
+}
+
+// wait waits for the first direction to end or the tunnel to time out,
+// and returns the number of the directions still running.
+func (t *Tunneler) wait(errc <-chan error, last *int64) (int, error) {
+	var idle, expired <-chan time.Time
+	var idleTimer *time.Timer
+	if t.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(t.IdleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+	if t.MaxDuration > 0 {
+		maxTimer := time.NewTimer(t.MaxDuration)
+		defer maxTimer.Stop()
+		expired = maxTimer.C
+	}
+	for {
+		select {
+		case err := <-errc:
+			return 1, err
+		case <-expired:
+			return 2, errs.ErrTimeout
+		case <-idle:
+			d := time.Since(time.Unix(0, atomic.LoadInt64(last)))
+			if d >= t.IdleTimeout {
+				return 2, errs.ErrIdleTimeout
+			}
+			idleTimer.Reset(t.IdleTimeout - d)
+		}
+	}
+}
+
+func copyConn(dst Conn, src io.Reader, errc chan<- error) {
+	_, err := io.Copy(dst, src)
+	errc <- err
+}
+
+// tunnelReader records the time of the last read and counts the bytes read.
+type tunnelReader struct {
+	r     io.Reader
+	last  *int64
+	count func(n int64)
+}
+
+func (r *tunnelReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		atomic.StoreInt64(r.last, time.Now().UnixNano())
+		r.count(int64(n))
+	}
+	return n, err
+}
+
+// closeUnderlying closes the connection without releasing its buffers,
+// which may still be used by the goroutine copying from or to it.
+func closeUnderlying(c Conn) {
+	if cc, ok := c.(interface{ CloseNoResetBuffer() error }); ok {
+		cc.CloseNoResetBuffer() //nolint:errcheck
+		return
+	}
+	c.Close() //nolint:errcheck
+}
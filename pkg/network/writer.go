@@ -18,6 +18,7 @@ package network
 
 import (
 	"io"
+	"net"
 	"sync"
 
 	"github.com/bytedance/gopkg/lang/mcache"
@@ -40,6 +41,7 @@ func init() {
 
 type networkWriter struct {
 	caches []*node
+	bufs   [][]byte
 	w      io.Writer
 }
 
@@ -89,11 +91,17 @@ func (w *networkWriter) WriteBinary(b []byte) (length int, err error) {
 
 func (w *networkWriter) Flush() (err error) {
 	for _, c := range w.caches {
-		_, err = w.w.Write(c.data)
-		if err != nil {
-			break
+		if len(c.data) > 0 {
+			w.bufs = append(w.bufs, c.data)
 		}
 	}
+	if len(w.bufs) > 0 {
+		_, err = WriteBuffers(w.w, w.bufs)
+	}
+	for i := range w.bufs {
+		w.bufs[i] = nil
+	}
+	w.bufs = w.bufs[:0]
 	w.release()
 	return
 }
@@ -103,3 +111,22 @@ func NewWriter(w io.Writer) Writer {
 		w: w,
 	}
 }
+
+// WriteBuffers writes bufs to w in a single syscall if possible, which is the case
+// if w implements VectorWriter or w is a *net.TCPConn or *net.UnixConn.
+// Otherwise, the buffers are written one by one.
+//
+// NOTE:
+//
+//	The elements of bufs may be modified.
+func WriteBuffers(w io.Writer, bufs [][]byte) (int64, error) {
+	if vw, ok := w.(VectorWriter); ok {
+		return vw.WriteBuffers(bufs)
+	}
+	if len(bufs) == 1 {
+		n, err := w.Write(bufs[0])
+		return int64(n), err
+	}
+	nb := net.Buffers(bufs)
+	return nb.WriteTo(w)
+}
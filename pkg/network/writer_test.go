@@ -17,6 +17,7 @@
 package network
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
@@ -94,3 +95,42 @@ func (m *mockIOWriter) Write(p []byte) (n int, err error) {
 	m.WriteNum += len(p)
 	return len(p), nil
 }
+
+type mockVectorWriter struct {
+	bytes.Buffer
+	calls int
+}
+
+func (m *mockVectorWriter) WriteBuffers(bufs [][]byte) (n int64, err error) {
+	m.calls++
+	for _, b := range bufs {
+		m.Write(b)
+		n += int64(len(b))
+	}
+	return
+}
+
+func TestWriteBuffers(t *testing.T) {
+	vw := &mockVectorWriter{}
+	n, err := WriteBuffers(vw, [][]byte{[]byte("foo"), []byte("bar")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(6), n)
+	assert.DeepEqual(t, 1, vw.calls)
+	assert.DeepEqual(t, "foobar", vw.String())
+
+	// written one by one
+	iw := &mockIOWriter{}
+	n, err = WriteBuffers(iw, [][]byte{[]byte("foo"), []byte("bar")})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int64(6), n)
+	assert.DeepEqual(t, 6, iw.WriteNum)
+
+	// the caches are flushed by a single call
+	w := NewWriter(vw)
+	w.Malloc(size1K)                    //nolint:errcheck
+	w.WriteBinary(make([]byte, size4K)) //nolint:errcheck
+	w.WriteBinary(make([]byte, size1K)) //nolint:errcheck
+	assert.Nil(t, w.Flush())
+	assert.DeepEqual(t, 2, vw.calls)
+	assert.DeepEqual(t, 6+size1K*2+size4K, vw.Len())
+}
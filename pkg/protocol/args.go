@@ -44,6 +44,7 @@ package protocol
 import (
 	"bytes"
 	"io"
+	"strings"
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/internal/nocopy"
@@ -58,6 +59,35 @@ var nilByteSlice = []byte{}
 
 type argsScanner struct {
 	b []byte
+
+	semicolon bool
+	noPlus    bool
+}
+
+// DuplicateKeyPolicy decides which values are kept if a key appears more than once in query args.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysKeepAll keeps all the values, Peek returns the first one and PeekAll returns all of them.
+	DuplicateKeysKeepAll DuplicateKeyPolicy = iota
+	// DuplicateKeysKeepFirst keeps the first value only, as Java servlet containers do.
+	DuplicateKeysKeepFirst
+	// DuplicateKeysKeepLast keeps the last value only, as PHP does.
+	DuplicateKeysKeepLast
+)
+
+// QueryDialect describes how query args are emitted by different ecosystems.
+// The zero value is the default dialect, i.e. '&' separated args, '+' decoded as space
+// and all the values of duplicate keys kept.
+type QueryDialect struct {
+	// SemicolonSeparator treats ';' as a separator of args besides '&'.
+	SemicolonSeparator bool
+
+	// DisablePlusAsSpace keeps '+' as it is instead of decoding it as space.
+	DisablePlusAsSpace bool
+
+	// DuplicateKeys decides which values are kept for the duplicate keys.
+	DuplicateKeys DuplicateKeyPolicy
 }
 
 type Args struct {
@@ -106,16 +136,19 @@ func (s *argsScanner) next(kv *argsKV) bool {
 		case '=':
 			if isKey {
 				isKey = false
-				kv.key = decodeArgAppend(kv.key[:0], s.b[:i])
+				kv.key = s.decode(kv.key[:0], s.b[:i])
 				k = i + 1
 			}
-		case '&':
+		case '&', ';':
+			if c == ';' && !s.semicolon {
+				break
+			}
 			if isKey {
-				kv.key = decodeArgAppend(kv.key[:0], s.b[:i])
+				kv.key = s.decode(kv.key[:0], s.b[:i])
 				kv.value = kv.value[:0]
 				kv.noValue = argsNoValue
 			} else {
-				kv.value = decodeArgAppend(kv.value[:0], s.b[k:i])
+				kv.value = s.decode(kv.value[:0], s.b[k:i])
 			}
 			s.b = s.b[i+1:]
 			return true
@@ -123,16 +156,23 @@ func (s *argsScanner) next(kv *argsKV) bool {
 	}
 
 	if isKey {
-		kv.key = decodeArgAppend(kv.key[:0], s.b)
+		kv.key = s.decode(kv.key[:0], s.b)
 		kv.value = kv.value[:0]
 		kv.noValue = argsNoValue
 	} else {
-		kv.value = decodeArgAppend(kv.value[:0], s.b[k:])
+		kv.value = s.decode(kv.value[:0], s.b[k:])
 	}
 	s.b = s.b[len(s.b):]
 	return true
 }
 
+func (s *argsScanner) decode(dst, src []byte) []byte {
+	if s.noPlus {
+		return decodeArgAppendNoPlus(dst, src)
+	}
+	return decodeArgAppend(dst, src)
+}
+
 func decodeArgAppend(dst, src []byte) []byte {
 	if bytes.IndexByte(src, '%') < 0 && bytes.IndexByte(src, '+') < 0 {
 		// fast path: src doesn't contain encoded chars
@@ -224,6 +264,19 @@ func setArg(h []argsKV, key, value string, noValue bool) []argsKV {
 	return appendArg(h, key, value, noValue)
 }
 
+func peekArgBytesFold(h []argsKV, k []byte) []byte {
+	for i, n := 0, len(h); i < n; i++ {
+		kv := &h[i]
+		if bytes.EqualFold(kv.key, k) {
+			if kv.value != nil {
+				return kv.value
+			}
+			return nilByteSlice
+		}
+	}
+	return nil
+}
+
 func peekArgBytes(h []argsKV, k []byte) []byte {
 	for i, n := 0, len(h); i < n; i++ {
 		kv := &h[i]
@@ -368,6 +421,34 @@ func (a *Args) ParseBytes(b []byte) {
 	}
 }
 
+// ParseBytesWithDialect parses the given b containing query args in dialect d.
+//
+// It is the same as ParseBytes if d is nil.
+func (a *Args) ParseBytesWithDialect(b []byte, d *QueryDialect) {
+	if d == nil {
+		a.ParseBytes(b)
+		return
+	}
+	a.Reset()
+
+	s := argsScanner{b: b, semicolon: d.SemicolonSeparator, noPlus: d.DisablePlusAsSpace}
+	var kv argsKV
+	for s.next(&kv) {
+		if len(kv.key) == 0 && len(kv.value) == 0 {
+			continue
+		}
+		switch d.DuplicateKeys {
+		case DuplicateKeysKeepFirst:
+			if peekArgBytes(a.args, kv.key) != nil {
+				continue
+			}
+		case DuplicateKeysKeepLast:
+			a.args = delAllArgsBytes(a.args, kv.key)
+		}
+		a.args = appendArgBytes(a.args, kv.key, kv.value, kv.noValue)
+	}
+}
+
 // Peek returns query arg value for the given key.
 //
 // Returned value is valid until the next Args call.
@@ -379,6 +460,47 @@ func (a *Args) PeekExists(key string) (string, bool) {
 	return peekArgStrExists(a.args, key)
 }
 
+// PeekAll returns all the values of the given key, including the values
+// in array syntax, e.g. both "key=a&key=b" and "key[]=a&key[]=b" produce [a b].
+//
+// Returned values are valid until the next Args call.
+func (a *Args) PeekAll(key string) [][]byte {
+	var values [][]byte
+	for i, n := 0, len(a.args); i < n; i++ {
+		kv := &a.args[i]
+		if k := kv.key; string(k) == key ||
+			(len(k) == len(key)+2 && string(k[:len(key)]) == key && string(k[len(key):]) == "[]") {
+			values = append(values, kv.value)
+		}
+	}
+	return values
+}
+
+// PeekMap returns the values of the given key in map syntax,
+// e.g. "key[a]=1&key[b]=2" produces {a:1 b:2}.
+// The first value is kept if a map key appears more than once.
+func (a *Args) PeekMap(key string) map[string]string {
+	var m map[string]string
+	for i, n := 0, len(a.args); i < n; i++ {
+		kv := &a.args[i]
+		k := kv.key
+		if len(k) < len(key)+3 || string(k[:len(key)]) != key || k[len(key)] != '[' || k[len(k)-1] != ']' {
+			continue
+		}
+		mk := string(k[len(key)+1 : len(k)-1])
+		if strings.ContainsAny(mk, "[]") {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		if _, ok := m[mk]; !ok {
+			m[mk] = string(kv.value)
+		}
+	}
+	return m
+}
+
 func visitArgs(args []argsKV, f func(k, v []byte)) {
 	for i, n := 0, len(args); i < n; i++ {
 		kv := &args[i]
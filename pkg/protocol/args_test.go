@@ -90,6 +90,20 @@ func TestArgsPeekExists(t *testing.T) {
 	assert.True(t, b4)
 }
 
+func TestArgsPeekAll(t *testing.T) {
+	var a Args
+	a.ParseBytes([]byte("ids=1&ids[]=2&ids=3&idss=4&ids[x]=5"))
+	assert.DeepEqual(t, [][]byte{[]byte("1"), []byte("2"), []byte("3")}, a.PeekAll("ids"))
+	assert.DeepEqual(t, 0, len(a.PeekAll("none")))
+}
+
+func TestArgsPeekMap(t *testing.T) {
+	var a Args
+	a.ParseBytes([]byte("ids[a]=1&ids[b]=2&ids[a]=3&ids=4&ids[]=5&ids[c][d]=6&idss[e]=7"))
+	assert.DeepEqual(t, map[string]string{"a": "1", "b": "2"}, a.PeekMap("ids"))
+	assert.Nil(t, a.PeekMap("none"))
+}
+
 func TestSetArg(t *testing.T) {
 	a := Args{args: setArg(nil, "q1", "foo", true)}
 	a.Add("", "")
@@ -119,6 +133,29 @@ func TestArgsParseBytes(t *testing.T) {
 	assert.DeepEqual(t, &ta2, &a2)
 }
 
+func TestArgsParseBytesWithDialect(t *testing.T) {
+	var a Args
+	a.ParseBytesWithDialect([]byte("a=1;b=2&a=x+y"), nil)
+	assert.DeepEqual(t, "1;b=2", string(a.Peek("a")))
+	assert.DeepEqual(t, 2, len(a.PeekAll("a")))
+
+	a.ParseBytesWithDialect([]byte("a=1;b=2&a=x+y"), &QueryDialect{SemicolonSeparator: true})
+	assert.DeepEqual(t, "1", string(a.Peek("a")))
+	assert.DeepEqual(t, "2", string(a.Peek("b")))
+	assert.DeepEqual(t, "x y", string(a.PeekAll("a")[1]))
+
+	a.ParseBytesWithDialect([]byte("a=x+y%2B"), &QueryDialect{DisablePlusAsSpace: true})
+	assert.DeepEqual(t, "x+y+", string(a.Peek("a")))
+
+	a.ParseBytesWithDialect([]byte("a=1&b=2&a=3"), &QueryDialect{DuplicateKeys: DuplicateKeysKeepFirst})
+	assert.DeepEqual(t, [][]byte{[]byte("1")}, a.PeekAll("a"))
+	assert.DeepEqual(t, "a=1&b=2", a.String())
+
+	a.ParseBytesWithDialect([]byte("a=1&b=2&a=3"), &QueryDialect{DuplicateKeys: DuplicateKeysKeepLast})
+	assert.DeepEqual(t, [][]byte{[]byte("3")}, a.PeekAll("a"))
+	assert.DeepEqual(t, "b=2&a=3", a.String())
+}
+
 func TestArgsVisitAll(t *testing.T) {
 	var a Args
 	var s []string
@@ -118,6 +118,9 @@ type DynamicConfig struct {
 	Addr     string
 	ProxyURI *protocol.URI
 	IsTLS    bool
+	// ServerName is the origin host verified by TLS when Addr is an alternative service of it,
+	// e.g. for the HTTP/3 host clients. It is empty if the host of Addr is verified.
+	ServerName string
 }
 
 // RetryIfFunc signature of retry if function
@@ -218,7 +221,12 @@ func doRequestFollowRedirectsBuffer(ctx context.Context, req *protocol.Request,
 	oldBody := bodyBuf.B
 	bodyBuf.B = dst
 
-	statusCode, _, err = DoRequestFollowRedirects(ctx, req, resp, url, defaultMaxRedirectsCount, c)
+	maxRedirectsCount := defaultMaxRedirectsCount
+	policy := getRedirectPolicy(c)
+	if policy != nil && policy.MaxRedirects > 0 {
+		maxRedirectsCount = policy.MaxRedirects
+	}
+	statusCode, _, err = doRequestFollowRedirects(ctx, req, resp, url, maxRedirectsCount, policy, c)
 
 	// In HTTP2 scenario, client use stream mode to create a request and its body is in body stream.
 	// In HTTP1, only client recv body exceed max body size and client is in stream mode can trig it.
@@ -230,34 +238,7 @@ func doRequestFollowRedirectsBuffer(ctx context.Context, req *protocol.Request,
 }
 
 func DoRequestFollowRedirects(ctx context.Context, req *protocol.Request, resp *protocol.Response, url string, maxRedirectsCount int, c Doer) (statusCode int, body []byte, err error) {
-	redirectsCount := 0
-
-	for {
-		req.SetRequestURI(url)
-		req.ParseURI()
-
-		if err = c.Do(ctx, req, resp); err != nil {
-			break
-		}
-		statusCode = resp.Header.StatusCode()
-		if !StatusCodeIsRedirect(statusCode) {
-			break
-		}
-
-		redirectsCount++
-		if redirectsCount > maxRedirectsCount {
-			err = errTooManyRedirects
-			break
-		}
-		location := resp.Header.PeekLocation()
-		if len(location) == 0 {
-			err = errMissingLocation
-			break
-		}
-		url = getRedirectURL(url, location)
-	}
-
-	return statusCode, body, err
+	return doRequestFollowRedirects(ctx, req, resp, url, maxRedirectsCount, getRedirectPolicy(c), c)
 }
 
 // StatusCodeIsRedirect returns true if the status code indicates a redirect.
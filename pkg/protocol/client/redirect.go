@@ -0,0 +1,165 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cloudwego/hertz/internal/bytestr"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ErrUseLastResponse can be returned by RedirectPolicy.CheckRedirect to stop following
+// redirects, then the last response is returned without error.
+var ErrUseLastResponse = errors.NewPublic("use last response")
+
+// DefaultRedirectStripHeaders are the headers removed from the request when it is
+// redirected to another origin, if RedirectPolicy.StripHeaders is nil.
+var DefaultRedirectStripHeaders = []string{
+	consts.HeaderAuthorization,
+	consts.HeaderProxyAuthorization,
+	consts.HeaderCookie,
+	consts.HeaderWWWAuthenticate,
+}
+
+// RedirectPolicy decides how the redirects are followed by Get, Post, DoRedirects and the like.
+//
+// NOTE:
+//
+//	Without RedirectPolicy, the request is resent to the new location as is,
+//	which keeps the method, the body and all headers.
+type RedirectPolicy struct {
+	// MaxRedirects is the max number of redirects followed by Get, Post and the like,
+	// if MaxRedirects<=0, 16 is used. DoRedirects keeps using its maxRedirectsCount.
+	MaxRedirects int
+
+	// CheckRedirect is called before following the redirect to location, with the
+	// response of the last request and the number of the redirects followed so far.
+	// If it returns an error, the error is returned without following the redirect,
+	// except for ErrUseLastResponse.
+	CheckRedirect func(resp *protocol.Response, location string, redirects int) error
+
+	// StripHeaders are removed from the request when the redirect crosses origins,
+	// i.e. the scheme or host changes.
+	// If StripHeaders is nil, DefaultRedirectStripHeaders is used.
+	StripHeaders []string
+
+	// KeepMethod keeps the method and body of the request on 301, 302 and 303.
+	// By default, the method is switched to GET and the body is dropped on 303
+	// unless the method is HEAD, and so it is for POST on 301 and 302 as browsers do.
+	// The method and body are always kept on 307 and 308.
+	KeepMethod bool
+}
+
+// redirectPolicyGetter is implemented by the Doer which has RedirectPolicy set.
+type redirectPolicyGetter interface {
+	GetRedirectPolicy() *RedirectPolicy
+}
+
+func getRedirectPolicy(c Doer) *RedirectPolicy {
+	if g, ok := c.(redirectPolicyGetter); ok {
+		return g.GetRedirectPolicy()
+	}
+	return nil
+}
+
+// prepare applies the policy to req, which has been sent to from with the status code,
+// before it is sent to the redirected url to.
+func (p *RedirectPolicy) prepare(req *protocol.Request, statusCode int, from, to string) {
+	if !p.KeepMethod && switchToGet(&req.Header, statusCode) {
+		req.Header.SetMethod(consts.MethodGet)
+		req.ResetBody()
+		req.Header.DelBytes(bytestr.StrContentType)
+		req.Header.DelBytes(bytestr.StrContentLength)
+	}
+
+	if !sameOrigin(from, to) {
+		headers := p.StripHeaders
+		if headers == nil {
+			headers = DefaultRedirectStripHeaders
+		}
+		for _, h := range headers {
+			req.Header.DelBytes([]byte(h))
+		}
+	}
+}
+
+func switchToGet(h *protocol.RequestHeader, statusCode int) bool {
+	switch statusCode {
+	case consts.StatusSeeOther:
+		return !h.IsHead() && !h.IsGet()
+	case consts.StatusMovedPermanently, consts.StatusFound:
+		return h.IsPost()
+	}
+	return false
+}
+
+func sameOrigin(a, b string) bool {
+	ua, ub := protocol.AcquireURI(), protocol.AcquireURI()
+	ua.Update(a)
+	ub.Update(b)
+	same := bytes.Equal(ua.Scheme(), ub.Scheme()) && bytes.EqualFold(ua.Host(), ub.Host())
+	protocol.ReleaseURI(ua)
+	protocol.ReleaseURI(ub)
+	return same
+}
+
+func doRequestFollowRedirects(ctx context.Context, req *protocol.Request, resp *protocol.Response, url string, maxRedirectsCount int, policy *RedirectPolicy, c Doer) (statusCode int, body []byte, err error) {
+	redirectsCount := 0
+
+	for {
+		req.SetRequestURI(url)
+		req.ParseURI()
+
+		if err = c.Do(ctx, req, resp); err != nil {
+			break
+		}
+		statusCode = resp.Header.StatusCode()
+		if !StatusCodeIsRedirect(statusCode) {
+			break
+		}
+
+		redirectsCount++
+		if redirectsCount > maxRedirectsCount {
+			err = errTooManyRedirects
+			break
+		}
+		location := resp.Header.PeekLocation()
+		if len(location) == 0 {
+			err = errMissingLocation
+			break
+		}
+		next := getRedirectURL(url, location)
+		if policy != nil {
+			if policy.CheckRedirect != nil {
+				if err = policy.CheckRedirect(resp, next, redirectsCount-1); err != nil {
+					if err == ErrUseLastResponse {
+						err = nil
+					}
+					break
+				}
+			}
+			policy.prepare(req, statusCode, url, next)
+		}
+		url = next
+	}
+
+	return statusCode, body, err
+}
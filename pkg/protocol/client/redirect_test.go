@@ -0,0 +1,204 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type sentRequest struct {
+	method string
+	uri    string
+	body   string
+	auth   string
+}
+
+type redirectDoer struct {
+	policy    *RedirectPolicy
+	locations map[string]string
+	status    int
+	sent      []sentRequest
+}
+
+func (d *redirectDoer) Do(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+	uri := req.URI().String()
+	d.sent = append(d.sent, sentRequest{
+		method: string(req.Header.Method()),
+		uri:    uri,
+		body:   string(req.Body()),
+		auth:   string(req.Header.Peek(consts.HeaderAuthorization)),
+	})
+	resp.Reset()
+	if location, ok := d.locations[uri]; ok {
+		resp.SetStatusCode(d.status)
+		resp.Header.Set(consts.HeaderLocation, location)
+		return nil
+	}
+	resp.SetStatusCode(consts.StatusOK)
+	return nil
+}
+
+func (d *redirectDoer) GetRedirectPolicy() *RedirectPolicy {
+	return d.policy
+}
+
+func newRedirectRequest(method string) *protocol.Request {
+	req := protocol.AcquireRequest()
+	req.Header.SetMethod(method)
+	req.Header.Set(consts.HeaderAuthorization, "secret")
+	if method == consts.MethodPost {
+		req.SetBodyString("a=b")
+	}
+	return req
+}
+
+func TestRedirectWithoutPolicy(t *testing.T) {
+	d := &redirectDoer{
+		locations: map[string]string{"http://a.com/foo": "http://b.com/bar"},
+		status:    consts.StatusFound,
+	}
+	req := newRedirectRequest(consts.MethodPost)
+	resp := protocol.AcquireResponse()
+	statusCode, _, err := DoRequestFollowRedirects(context.Background(), req, resp, "http://a.com/foo", 16, d)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, statusCode)
+	assert.DeepEqual(t, sentRequest{method: consts.MethodPost, uri: "http://b.com/bar", body: "a=b", auth: "secret"}, d.sent[1])
+}
+
+func TestRedirectPolicyMethod(t *testing.T) {
+	for _, tc := range []struct {
+		status     int
+		method     string
+		keepMethod bool
+		expected   string
+	}{
+		{consts.StatusMovedPermanently, consts.MethodPost, false, consts.MethodGet},
+		{consts.StatusFound, consts.MethodPost, false, consts.MethodGet},
+		{consts.StatusFound, consts.MethodPut, false, consts.MethodPut},
+		{consts.StatusSeeOther, consts.MethodPut, false, consts.MethodGet},
+		{consts.StatusSeeOther, consts.MethodHead, false, consts.MethodHead},
+		{consts.StatusTemporaryRedirect, consts.MethodPost, false, consts.MethodPost},
+		{consts.StatusPermanentRedirect, consts.MethodPost, false, consts.MethodPost},
+		{consts.StatusFound, consts.MethodPost, true, consts.MethodPost},
+		{consts.StatusSeeOther, consts.MethodPost, true, consts.MethodPost},
+	} {
+		d := &redirectDoer{
+			policy:    &RedirectPolicy{KeepMethod: tc.keepMethod},
+			locations: map[string]string{"http://a.com/foo": "/bar"},
+			status:    tc.status,
+		}
+		req := newRedirectRequest(tc.method)
+		_, _, err := DoRequestFollowRedirects(context.Background(), req, protocol.AcquireResponse(), "http://a.com/foo", 16, d)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, 2, len(d.sent))
+		assert.DeepEqual(t, tc.expected, d.sent[1].method)
+		assert.DeepEqual(t, "http://a.com/bar", d.sent[1].uri)
+		// the headers are kept for the same origin
+		assert.DeepEqual(t, "secret", d.sent[1].auth)
+		if tc.method == consts.MethodPost {
+			assert.DeepEqual(t, tc.method == tc.expected, d.sent[1].body == "a=b")
+		}
+	}
+}
+
+func TestRedirectPolicyStripHeaders(t *testing.T) {
+	d := &redirectDoer{
+		policy: &RedirectPolicy{},
+		locations: map[string]string{
+			"http://a.com/foo":  "https://a.com/foo",
+			"https://a.com/foo": "https://b.com/foo",
+		},
+		status: consts.StatusTemporaryRedirect,
+	}
+	req := newRedirectRequest(consts.MethodGet)
+	req.Header.Set("X-Token", "t")
+	_, _, err := DoRequestFollowRedirects(context.Background(), req, protocol.AcquireResponse(), "http://a.com/foo", 16, d)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 3, len(d.sent))
+	assert.DeepEqual(t, "", d.sent[1].auth)
+	assert.DeepEqual(t, "t", string(req.Header.Peek("X-Token")))
+
+	d.sent = nil
+	d.policy.StripHeaders = []string{"X-Token"}
+	req = newRedirectRequest(consts.MethodGet)
+	req.Header.Set("X-Token", "t")
+	_, _, err = DoRequestFollowRedirects(context.Background(), req, protocol.AcquireResponse(), "http://a.com/foo", 16, d)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "secret", d.sent[2].auth)
+	assert.DeepEqual(t, "", string(req.Header.Peek("X-Token")))
+}
+
+func TestRedirectPolicyCheckRedirect(t *testing.T) {
+	d := &redirectDoer{
+		locations: map[string]string{
+			"http://a.com/1": "/2",
+			"http://a.com/2": "/3",
+			"http://a.com/3": "/4",
+		},
+		status: consts.StatusFound,
+	}
+	var locations []string
+	errStop := errors.New("stop")
+	d.policy = &RedirectPolicy{
+		CheckRedirect: func(resp *protocol.Response, location string, redirects int) error {
+			locations = append(locations, location)
+			if location == "http://a.com/3" {
+				return errStop
+			}
+			return nil
+		},
+	}
+	_, _, err := DoRequestFollowRedirects(context.Background(), newRedirectRequest(consts.MethodGet), protocol.AcquireResponse(), "http://a.com/1", 16, d)
+	assert.DeepEqual(t, errStop, err)
+	assert.DeepEqual(t, []string{"http://a.com/2", "http://a.com/3"}, locations)
+
+	d.policy.CheckRedirect = func(resp *protocol.Response, location string, redirects int) error {
+		if redirects == 1 {
+			return ErrUseLastResponse
+		}
+		return nil
+	}
+	d.sent = nil
+	statusCode, _, err := DoRequestFollowRedirects(context.Background(), newRedirectRequest(consts.MethodGet), protocol.AcquireResponse(), "http://a.com/1", 16, d)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusFound, statusCode)
+	assert.DeepEqual(t, 2, len(d.sent))
+}
+
+func TestRedirectPolicyMaxRedirects(t *testing.T) {
+	d := &redirectDoer{
+		policy: &RedirectPolicy{MaxRedirects: 1},
+		locations: map[string]string{
+			"http://a.com/1": "/2",
+			"http://a.com/2": "/3",
+		},
+		status: consts.StatusFound,
+	}
+	_, _, err := GetURL(context.Background(), nil, "http://a.com/1", d)
+	assert.DeepEqual(t, errTooManyRedirects, err)
+
+	d.policy.MaxRedirects = 2
+	statusCode, _, err := GetURL(context.Background(), nil, "http://a.com/1", d)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, consts.StatusOK, statusCode)
+}
@@ -25,6 +25,40 @@ const (
 	// Redirects
 	HeaderLocation = "Location"
 
+	// Caching
+	HeaderAge          = "Age"
+	HeaderCacheControl = "Cache-Control"
+	HeaderETag         = "ETag"
+	HeaderExpires      = "Expires"
+	HeaderIfNoneMatch  = "If-None-Match"
+	HeaderPragma       = "Pragma"
+	HeaderVary         = "Vary"
+
+	// CORS
+	HeaderAccessControlAllowCredentials      = "Access-Control-Allow-Credentials"
+	HeaderAccessControlAllowHeaders          = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowMethods          = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowOrigin           = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowPrivateNetwork   = "Access-Control-Allow-Private-Network"
+	HeaderAccessControlExposeHeaders         = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge                = "Access-Control-Max-Age"
+	HeaderAccessControlRequestHeaders        = "Access-Control-Request-Headers"
+	HeaderAccessControlRequestMethod         = "Access-Control-Request-Method"
+	HeaderAccessControlRequestPrivateNetwork = "Access-Control-Request-Private-Network"
+	HeaderOrigin                             = "Origin"
+
+	// Security
+	HeaderContentSecurityPolicy           = "Content-Security-Policy"
+	HeaderContentSecurityPolicyReportOnly = "Content-Security-Policy-Report-Only"
+	HeaderCrossOriginEmbedderPolicy       = "Cross-Origin-Embedder-Policy"
+	HeaderCrossOriginOpenerPolicy         = "Cross-Origin-Opener-Policy"
+	HeaderCrossOriginResourcePolicy       = "Cross-Origin-Resource-Policy"
+	HeaderStrictTransportSecurity         = "Strict-Transport-Security"
+	HeaderXContentTypeOptions             = "X-Content-Type-Options"
+	HeaderXForwardedFor                   = "X-Forwarded-For"
+	HeaderXForwardedProto                 = "X-Forwarded-Proto"
+	HeaderXFrameOptions                   = "X-Frame-Options"
+
 	// Transfer coding
 	HeaderTE               = "TE"
 	HeaderTrailer          = "Trailer"
@@ -42,6 +76,17 @@ const (
 	HeaderConnection      = "Connection"
 	HeaderKeepAlive       = "Keep-Alive"
 	HeaderProxyConnection = "Proxy-Connection"
+	HeaderUpgrade         = "Upgrade"
+
+	// WebSocket
+	HeaderSecWebSocketAccept     = "Sec-WebSocket-Accept"
+	HeaderSecWebSocketExtensions = "Sec-WebSocket-Extensions"
+	HeaderSecWebSocketKey        = "Sec-WebSocket-Key"
+	HeaderSecWebSocketProtocol   = "Sec-WebSocket-Protocol"
+	HeaderSecWebSocketVersion    = "Sec-WebSocket-Version"
+
+	// Server-sent events
+	HeaderLastEventID = "Last-Event-ID"
 
 	// Authentication
 	HeaderAuthorization      = "Authorization"
@@ -57,6 +102,7 @@ const (
 
 	// Response context
 	HeaderAllow       = "Allow"
+	HeaderRetryAfter  = "Retry-After"
 	HeaderServer      = "Server"
 	HeaderServerLower = "server"
 
@@ -72,6 +118,7 @@ const (
 	HeaderContentLanguage = "Content-Language"
 	HeaderContentLength   = "Content-Length"
 	HeaderContentLocation = "Content-Location"
+	HeaderContentMD5      = "Content-MD5"
 	HeaderContentType     = "Content-Type"
 
 	// Content negotiation
@@ -43,6 +43,7 @@ package protocol
 
 import (
 	"bytes"
+	"strings"
 	"sync"
 	"time"
 
@@ -264,12 +265,19 @@ type cookieScanner struct {
 	b []byte
 }
 
-func parseRequestCookies(cookies []argsKV, src []byte) []argsKV {
+// maxStrictCookieSize is the max size of a cookie-pair accepted in strict mode,
+// which is the minimum size user agents should support according to RFC 6265, section 6.1.
+const maxStrictCookieSize = 4096
+
+func parseRequestCookies(cookies []argsKV, src []byte, strict bool) []argsKV {
 	var s cookieScanner
 	s.b = src
 	var kv *argsKV
 	cookies, kv = allocArg(cookies)
 	for s.next(kv) {
+		if strict && !isValidCookiePair(kv) {
+			continue
+		}
 		if len(kv.key) > 0 || len(kv.value) > 0 {
 			cookies, kv = allocArg(cookies)
 		}
@@ -277,6 +285,38 @@ func parseRequestCookies(cookies []argsKV, src []byte) []argsKV {
 	return releaseArg(cookies)
 }
 
+// isValidCookiePair reports whether kv is a cookie-pair defined in RFC 6265, section 4.1.1.
+func isValidCookiePair(kv *argsKV) bool {
+	if len(kv.key) == 0 || len(kv.key)+len(kv.value)+1 > maxStrictCookieSize {
+		return false
+	}
+	for _, c := range kv.key {
+		if !isTokenByte(c) {
+			return false
+		}
+	}
+	for _, c := range kv.value {
+		if !isCookieOctet(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCookieOctet reports whether c is a cookie-octet defined in RFC 6265, section 4.1.1,
+// which excludes CTLs, whitespace, DQUOTE, comma, semicolon and backslash.
+func isCookieOctet(c byte) bool {
+	return c > ' ' && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\'
+}
+
+// isTokenByte reports whether c is a tchar defined in RFC 7230, section 3.2.6.
+func isTokenByte(c byte) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
 func (s *cookieScanner) next(kv *argsKV) bool {
 	b := s.b
 	if len(b) == 0 {
@@ -92,7 +92,25 @@ func TestParseRequestCookies(t *testing.T) {
 }
 
 func testParseRequestCookies(t *testing.T, s, expectedS string) {
-	cookies := parseRequestCookies(nil, []byte(s))
+	testParseRequestCookiesWithMode(t, s, expectedS, false)
+}
+
+func TestParseRequestCookiesStrict(t *testing.T) {
+	t.Parallel()
+
+	testParseRequestCookiesWithMode(t, "=", "", true)
+	testParseRequestCookiesWithMode(t, "foo", "", true)
+	testParseRequestCookiesWithMode(t, "=foo", "", true)
+	testParseRequestCookiesWithMode(t, "bar=", "bar=", true)
+	testParseRequestCookiesWithMode(t, "xxx=aa;bb=c; =d; ;;e=g", "xxx=aa; bb=c; e=g", true)
+	testParseRequestCookiesWithMode(t, "a;b;c; d=1;d=2", "d=1; d=2", true)
+	testParseRequestCookiesWithMode(t, "a b=1; c=d e; f=\"g\"; h=i\\j; k(l)=m", "f=g", true)
+	testParseRequestCookiesWithMode(t, "a="+strings.Repeat("b", 4094)+"; c=d", "a="+strings.Repeat("b", 4094)+"; c=d", true)
+	testParseRequestCookiesWithMode(t, "a="+strings.Repeat("b", 4095)+"; c=d", "c=d", true)
+}
+
+func testParseRequestCookiesWithMode(t *testing.T, s, expectedS string, strict bool) {
+	cookies := parseRequestCookies(nil, []byte(s), strict)
 	ss := string(appendRequestCookieBytes(nil, cookies))
 	if ss != expectedS {
 		t.Fatalf("Unexpected cookies after parsing: %q. Expecting %q. String to parse %q", ss, expectedS, s)
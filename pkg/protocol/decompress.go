@@ -0,0 +1,147 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/compress"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// decoder creates the reader decoding r and the function releasing the reader.
+type decoder func(r io.Reader) (io.Reader, func(), error)
+
+var decoders = map[string]decoder{
+	"gzip": func(r io.Reader) (io.Reader, func(), error) {
+		zr, err := compress.AcquireGzipReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() { compress.ReleaseGzipReader(zr) }, nil
+	},
+	"br": func(r io.Reader) (io.Reader, func(), error) {
+		zr, err := compress.AcquireBrotliReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() { compress.ReleaseBrotliReader(zr) }, nil
+	},
+	"zstd": func(r io.Reader) (io.Reader, func(), error) {
+		zr, err := compress.AcquireZstdReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() { compress.ReleaseZstdReader(zr) }, nil
+	},
+}
+
+// SupportedContentEncodings is the value of Accept-Encoding header
+// listing the encodings which can be decoded by DecompressBody.
+const SupportedContentEncodings = "gzip, br, zstd"
+
+// DecompressBody decodes the body according to the Content-Encoding header if it is
+// gzip, br or zstd. The Content-Encoding header is removed after that, and is
+// available via OriginalContentEncoding.
+//
+// ErrBodyTooLarge is returned if maxBodySize > 0 and the decoded body is larger than it.
+//
+// NOTE:
+//
+//	The body stream is decoded while it is read, so the error of decoding
+//	and ErrBodyTooLarge are returned by reading the stream.
+func (resp *Response) DecompressBody(maxBodySize int) error {
+	encoding := strings.ToLower(string(bytes.TrimSpace(resp.Header.ContentEncoding())))
+	newReader, ok := decoders[encoding]
+	if !ok {
+		return nil
+	}
+
+	if resp.bodyStream != nil {
+		var err error
+		resp.WrapBodyStream(func(bodyStream io.Reader) io.Reader {
+			r, release, e := newReader(bodyStream)
+			if e != nil {
+				err = e
+				return bodyStream
+			}
+			return &decodedBodyStream{r: r, release: release, maxBodySize: maxBodySize}
+		}, -1)
+		if err != nil {
+			return err
+		}
+	} else {
+		r, release, err := newReader(bytes.NewReader(resp.BodyBytes()))
+		if err != nil {
+			return err
+		}
+		var bb bytebufferpool.ByteBuffer
+		if maxBodySize > 0 {
+			r = io.LimitReader(r, int64(maxBodySize)+1)
+		}
+		_, err = bb.ReadFrom(r)
+		release()
+		if err != nil {
+			return err
+		}
+		if maxBodySize > 0 && bb.Len() > maxBodySize {
+			return errs.ErrBodyTooLarge
+		}
+		resp.SetBody(bb.B)
+	}
+
+	resp.originalContentEncoding = append(resp.originalContentEncoding[:0], resp.Header.ContentEncoding()...)
+	resp.Header.Del(consts.HeaderContentEncoding)
+	return nil
+}
+
+// OriginalContentEncoding returns the Content-Encoding header value
+// of the response before the body is decoded by DecompressBody.
+func (resp *Response) OriginalContentEncoding() []byte {
+	return resp.originalContentEncoding
+}
+
+type decodedBodyStream struct {
+	r           io.Reader
+	release     func()
+	maxBodySize int
+	n           int
+}
+
+func (s *decodedBodyStream) Read(p []byte) (int, error) {
+	if s.r == nil {
+		return 0, io.EOF
+	}
+	n, err := s.r.Read(p)
+	s.n += n
+	if s.maxBodySize > 0 && s.n > s.maxBodySize {
+		return n, errs.ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (s *decodedBodyStream) Close() error {
+	if s.r != nil {
+		s.release()
+		s.r = nil
+	}
+	return nil
+}
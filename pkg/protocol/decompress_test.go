@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/compress"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestResponseDecompressBody(t *testing.T) {
+	body := []byte("hello hertz")
+	for encoding, encoded := range map[string][]byte{
+		"gzip": compress.AppendGzipBytes(nil, body),
+		"br":   compress.AppendBrotliBytes(nil, body),
+		"zstd": compress.AppendZstdBytes(nil, body),
+	} {
+		var resp Response
+		resp.Header.SetContentEncoding(encoding)
+		resp.SetBody(encoded)
+		assert.Nil(t, resp.DecompressBody(0))
+		assert.DeepEqual(t, body, resp.Body())
+		assert.DeepEqual(t, encoding, string(resp.OriginalContentEncoding()))
+		assert.DeepEqual(t, 0, len(resp.Header.ContentEncoding()))
+
+		resp.Reset()
+		resp.Header.SetContentEncoding(encoding)
+		resp.SetBody(encoded)
+		assert.DeepEqual(t, errs.ErrBodyTooLarge, resp.DecompressBody(5))
+		assert.DeepEqual(t, encoding, string(resp.Header.ContentEncoding()))
+	}
+
+	// unknown encoding is kept as is
+	var resp Response
+	resp.Header.SetContentEncoding("deflate")
+	resp.SetBody(body)
+	assert.Nil(t, resp.DecompressBody(0))
+	assert.DeepEqual(t, body, resp.Body())
+	assert.DeepEqual(t, "deflate", string(resp.Header.ContentEncoding()))
+	assert.DeepEqual(t, 0, len(resp.OriginalContentEncoding()))
+}
+
+func TestResponseDecompressBodyStream(t *testing.T) {
+	body := []byte("hello hertz")
+	var resp Response
+	resp.Header.SetContentEncoding("gzip")
+	resp.SetBodyStream(bytes.NewReader(compress.AppendGzipBytes(nil, body)), -1)
+	assert.Nil(t, resp.DecompressBody(0))
+	assert.True(t, resp.IsBodyStream())
+	assert.DeepEqual(t, "gzip", string(resp.OriginalContentEncoding()))
+	b, err := ioutil.ReadAll(resp.BodyStream())
+	assert.Nil(t, err)
+	assert.DeepEqual(t, body, b)
+	assert.Nil(t, resp.CloseBodyStream())
+
+	resp.Reset()
+	resp.Header.SetContentEncoding("gzip")
+	resp.SetBodyStream(bytes.NewReader(compress.AppendGzipBytes(nil, body)), -1)
+	assert.Nil(t, resp.DecompressBody(5))
+	_, err = ioutil.ReadAll(resp.BodyStream())
+	assert.DeepEqual(t, errs.ErrBodyTooLarge, err)
+}
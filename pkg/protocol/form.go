@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bytes"
+	"mime/multipart"
+
+	"github.com/cloudwego/hertz/internal/bytestr"
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+var (
+	errTooManyFormFields = errs.New(errs.ErrFormTooLarge, errs.ErrorTypePublic, "too many form fields")
+	errFormValueTooLong  = errs.New(errs.ErrFormTooLarge, errs.ErrorTypePublic, "form value too long")
+	errFormKeyTooLong    = errs.New(errs.ErrFormKeyTooLong, errs.ErrorTypePublic, "form key too long")
+)
+
+// FormLimits limits the resources used by the urlencoded and multipart forms of requests,
+// which defends against the payloads of hash flooding and memory amplification.
+// 0 means no limitation.
+type FormLimits struct {
+	// MaxFields is the max number of fields, including the files of multipart forms.
+	MaxFields int
+
+	// MaxKeyLength is the max length of the field keys.
+	MaxKeyLength int
+
+	// MaxValueLength is the max length of the field values, the files of multipart forms are not limited by it.
+	MaxValueLength int
+}
+
+func (l *FormLimits) checkField(key, value int) error {
+	if l.MaxKeyLength > 0 && key > l.MaxKeyLength {
+		return errFormKeyTooLong
+	}
+	if l.MaxValueLength > 0 && value > l.MaxValueLength {
+		return errFormValueTooLong
+	}
+	return nil
+}
+
+func (l *FormLimits) checkArgs(a *Args) error {
+	if l.MaxFields > 0 && a.Len() > l.MaxFields {
+		return errTooManyFormFields
+	}
+	for i := range a.args {
+		kv := &a.args[i]
+		if err := l.checkField(len(kv.key), len(kv.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *FormLimits) checkMultipartForm(f *multipart.Form) error {
+	fields := 0
+	for k, vv := range f.Value {
+		fields += len(vv)
+		for _, v := range vv {
+			if err := l.checkField(len(k), len(v)); err != nil {
+				return err
+			}
+		}
+	}
+	for k, fhs := range f.File {
+		fields += len(fhs)
+		if err := l.checkField(len(k), 0); err != nil {
+			return err
+		}
+	}
+	if l.MaxFields > 0 && fields > l.MaxFields {
+		return errTooManyFormFields
+	}
+	return nil
+}
+
+// SetFormLimits sets the limits of the urlencoded and multipart forms of the request,
+// nil means no limitation.
+//
+// The multipart form exceeding the limits fails to be parsed.
+// NOTE:
+//
+//	It only takes effect if the form has not been parsed yet.
+func (req *Request) SetFormLimits(l *FormLimits) {
+	req.formLimits = l
+}
+
+// CheckFormLimits returns an error if the form of the request exceeds the limits set by SetFormLimits.
+//
+// The urlencoded form is parsed for the check unless the body is streamed,
+// and the multipart form is only checked if it has been parsed, e.g. by the server.
+// The error wraps errors.ErrFormTooLarge or errors.ErrFormKeyTooLong.
+func (req *Request) CheckFormLimits() error {
+	if req.formLimits == nil {
+		return nil
+	}
+	if req.multipartForm != nil {
+		return req.formLimits.checkMultipartForm(req.multipartForm)
+	}
+	if req.IsBodyStream() || !bytes.HasPrefix(req.Header.ContentType(), bytestr.StrPostArgsContentType) {
+		return nil
+	}
+	return req.formLimits.checkArgs(req.PostArgs())
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestRequestCheckFormLimitsURLEncoded(t *testing.T) {
+	newRequest := func(body string) *Request {
+		req := NewRequest("POST", "/foo", nil)
+		req.Header.SetContentTypeBytes([]byte("application/x-www-form-urlencoded"))
+		req.SetBodyString(body)
+		return req
+	}
+
+	req := newRequest("a=1&b=2&c=3")
+	assert.Nil(t, req.CheckFormLimits())
+	req.SetFormLimits(&FormLimits{MaxFields: 3, MaxKeyLength: 1, MaxValueLength: 1})
+	assert.Nil(t, req.CheckFormLimits())
+
+	req = newRequest("a=1&b=2&c=3&d=4")
+	req.SetFormLimits(&FormLimits{MaxFields: 3})
+	assert.True(t, errors.Is(req.CheckFormLimits(), errs.ErrFormTooLarge))
+
+	req = newRequest("a=1&bb=2")
+	req.SetFormLimits(&FormLimits{MaxKeyLength: 1})
+	assert.True(t, errors.Is(req.CheckFormLimits(), errs.ErrFormKeyTooLong))
+
+	req = newRequest("a=1&b=22")
+	req.SetFormLimits(&FormLimits{MaxValueLength: 1})
+	assert.True(t, errors.Is(req.CheckFormLimits(), errs.ErrFormTooLarge))
+
+	req.Reset()
+	req.Header.SetContentTypeBytes([]byte("application/x-www-form-urlencoded"))
+	req.SetBodyString("a=1&b=22")
+	assert.Nil(t, req.CheckFormLimits())
+}
+
+func TestRequestCheckFormLimitsMultipart(t *testing.T) {
+	s := `--foo
+Content-Disposition: form-data; name="f1"
+
+value1
+--foo
+Content-Disposition: form-data; name="f2"; filename="TODO"
+Content-Type: application/octet-stream
+
+file content
+--foo--
+`
+	s = strings.Replace(s, "\n", "\r\n", -1)
+	newRequest := func(l *FormLimits) *Request {
+		req := NewRequest("POST", "/upload", nil)
+		req.Header.SetContentTypeBytes([]byte("multipart/form-data; boundary=foo"))
+		req.SetBodyString(s)
+		req.SetFormLimits(l)
+		return req
+	}
+
+	req := newRequest(&FormLimits{MaxFields: 2, MaxKeyLength: 2, MaxValueLength: 6})
+	f, err := req.MultipartForm()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "value1", f.Value["f1"][0])
+	assert.Nil(t, req.CheckFormLimits())
+	req.RemoveMultipartFormFiles()
+
+	for _, l := range []*FormLimits{{MaxFields: 1}, {MaxValueLength: 5}} {
+		_, err = newRequest(l).MultipartForm()
+		assert.True(t, errors.Is(err, errs.ErrFormTooLarge))
+	}
+	_, err = newRequest(&FormLimits{MaxKeyLength: 1}).MultipartForm()
+	assert.True(t, errors.Is(err, errs.ErrFormKeyTooLong))
+}
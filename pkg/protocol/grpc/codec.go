@@ -0,0 +1,152 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ContentType is the content type of the gRPC calls encoded with protobuf.
+	ContentType = "application/grpc"
+
+	headerSize = 5
+)
+
+// Codec encodes and decodes the messages of the calls.
+type Codec interface {
+	// Name is the subtype of the content type, e.g. proto for application/grpc+proto.
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Name() string { return "proto" }
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// readMessage reads a length-prefixed message, io.EOF is returned if there is no more message.
+// The compressed messages are decompressed with gzip if compressed is true.
+func readMessage(r io.Reader, maxSize int, compressed bool) ([]byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, Errorf(Internal, "truncated message header")
+		}
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if maxSize > 0 && int64(size) > int64(maxSize) {
+		return nil, Errorf(ResourceExhausted, "received message larger than max (%d vs. %d)", size, maxSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, Errorf(Internal, "truncated message: %v", err)
+	}
+
+	switch header[0] {
+	case 0:
+		return msg, nil
+	case 1:
+		if !compressed {
+			return nil, Errorf(Internal, "compressed flag set with identity or empty encoding")
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(msg))
+		if err != nil {
+			return nil, Errorf(Internal, "failed to decompress the message: %v", err)
+		}
+		var lr io.Reader = zr
+		if maxSize > 0 {
+			lr = io.LimitReader(zr, int64(maxSize)+1)
+		}
+		msg, err = ioutil.ReadAll(lr)
+		if err != nil {
+			return nil, Errorf(Internal, "failed to decompress the message: %v", err)
+		}
+		if maxSize > 0 && len(msg) > maxSize {
+			return nil, Errorf(ResourceExhausted, "received message after decompression larger than max (%d)", maxSize)
+		}
+		return msg, nil
+	}
+	return nil, Errorf(Internal, "invalid compressed flag %d", header[0])
+}
+
+// appendMessage appends the length-prefixed uncompressed message to dst.
+func appendMessage(dst, msg []byte) []byte {
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+	dst = append(dst, header[:]...)
+	return append(dst, msg...)
+}
+
+// parseTimeout parses the value of grpc-timeout, e.g. 100m.
+func parseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("malformed grpc-timeout: %q", s)
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("malformed grpc-timeout unit: %q", s)
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("malformed grpc-timeout value: %q", s)
+	}
+	if v > int64(math.MaxInt64/unit) {
+		return time.Duration(math.MaxInt64), nil
+	}
+	return time.Duration(v) * unit, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestReadMessage(t *testing.T) {
+	var buf []byte
+	buf = appendMessage(buf, []byte("hello"))
+	buf = appendMessage(buf, nil)
+	r := bytes.NewReader(buf)
+
+	msg, err := readMessage(r, 1024, false)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello", string(msg))
+	msg, err = readMessage(r, 1024, false)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 0, len(msg))
+	_, err = readMessage(r, 1024, false)
+	assert.DeepEqual(t, io.EOF, err)
+
+	_, err = readMessage(bytes.NewReader(buf[:3]), 1024, false)
+	assert.DeepEqual(t, Internal, ErrorCode(err))
+	_, err = readMessage(bytes.NewReader(buf[:7]), 1024, false)
+	assert.DeepEqual(t, Internal, ErrorCode(err))
+	_, err = readMessage(bytes.NewReader(buf), 4, false)
+	assert.DeepEqual(t, ResourceExhausted, ErrorCode(err))
+}
+
+func TestReadCompressedMessage(t *testing.T) {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write(bytes.Repeat([]byte("hertz"), 100)) //nolint:errcheck
+	zw.Close()                                   //nolint:errcheck
+	buf := appendMessage(nil, b.Bytes())
+	buf[0] = 1
+
+	_, err := readMessage(bytes.NewReader(buf), 1024, false)
+	assert.DeepEqual(t, Internal, ErrorCode(err))
+	msg, err := readMessage(bytes.NewReader(buf), 1024, true)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, string(bytes.Repeat([]byte("hertz"), 100)), string(msg))
+	_, err = readMessage(bytes.NewReader(buf), 100, true)
+	assert.DeepEqual(t, ResourceExhausted, ErrorCode(err))
+}
+
+func TestParseTimeout(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"1H":        time.Hour,
+		"2M":        2 * time.Minute,
+		"3S":        3 * time.Second,
+		"100m":      100 * time.Millisecond,
+		"5u":        5 * time.Microsecond,
+		"7n":        7,
+		"9999H":     9999 * time.Hour,
+		"99999999H": time.Duration(math.MaxInt64),
+	} {
+		d, err := parseTimeout(s)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, want, d)
+	}
+	for _, s := range []string{"", "1", "H", "1x", "-1S", "123456789S"} {
+		_, err := parseTimeout(s)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	contextType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	decoderType   = reflect.TypeOf((func(interface{}) error)(nil))
+)
+
+// RegisterGRPCService registers the service described by desc, which is a *ServiceDesc of
+// google.golang.org/grpc, e.g. &pb.Greeter_ServiceDesc generated by protoc-gen-go-grpc, so that
+// the generated code can be used as is. It panics if desc is not such a descriptor, or if it has
+// streaming methods, whose handlers take the stream types of google.golang.org/grpc.
+func (s *Server) RegisterGRPCService(desc, impl interface{}) {
+	d, err := convertServiceDesc(desc)
+	if err != nil {
+		panic(err.Error())
+	}
+	s.RegisterService(d, impl)
+}
+
+// convertServiceDesc converts the service descriptor of google.golang.org/grpc by reflection.
+func convertServiceDesc(desc interface{}) (*ServiceDesc, error) {
+	v := reflect.ValueOf(desc)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("grpc: service descriptor must be a pointer to struct, got %T", desc)
+	}
+	v = v.Elem()
+	name, ok := fieldOf(v, "ServiceName", reflect.String)
+	if !ok {
+		return nil, fmt.Errorf("grpc: %T has no ServiceName", desc)
+	}
+	d := &ServiceDesc{ServiceName: name.String()}
+	if ht, ok := fieldOf(v, "HandlerType", reflect.Interface); ok {
+		d.HandlerType = ht.Interface()
+	}
+	if md, ok := fieldOf(v, "Metadata", reflect.Interface); ok {
+		d.Metadata = md.Interface()
+	}
+	if streams, ok := fieldOf(v, "Streams", reflect.Slice); ok && streams.Len() > 0 {
+		return nil, fmt.Errorf("grpc: streaming methods of %s must be registered with ServiceDesc", d.ServiceName)
+	}
+	methods, ok := fieldOf(v, "Methods", reflect.Slice)
+	if !ok {
+		return nil, fmt.Errorf("grpc: %T has no Methods", desc)
+	}
+	for i := 0; i < methods.Len(); i++ {
+		m := methods.Index(i)
+		if m.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("grpc: method descriptor of %s must be a struct", d.ServiceName)
+		}
+		name, ok := fieldOf(m, "MethodName", reflect.String)
+		if !ok {
+			return nil, fmt.Errorf("grpc: method descriptor of %s has no MethodName", d.ServiceName)
+		}
+		h, ok := fieldOf(m, "Handler", reflect.Func)
+		if !ok || !isMethodHandler(h.Type()) {
+			return nil, fmt.Errorf("grpc: method %s of %s has no valid Handler", name.String(), d.ServiceName)
+		}
+		d.Methods = append(d.Methods, MethodDesc{MethodName: name.String(), Handler: convertMethodHandler(h)})
+	}
+	return d, nil
+}
+
+func fieldOf(v reflect.Value, name string, kind reflect.Kind) (reflect.Value, bool) {
+	f := v.FieldByName(name)
+	return f, f.IsValid() && f.Kind() == kind
+}
+
+// isMethodHandler reports whether t is the type of the unary method handlers, i.e.
+//
+//	func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor UnaryServerInterceptor) (interface{}, error)
+//
+// where the interceptor is
+//
+//	func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error)
+func isMethodHandler(t reflect.Type) bool {
+	if t.NumIn() != 4 || t.In(0) != interfaceType || t.In(1) != contextType || t.In(2) != decoderType || !isResult(t) {
+		return false
+	}
+	it := t.In(3)
+	if it.Kind() != reflect.Func || it.NumIn() != 4 || it.In(0) != contextType || it.In(1) != interfaceType || !isResult(it) {
+		return false
+	}
+	info, handler := it.In(2), it.In(3)
+	if info.Kind() != reflect.Ptr || info.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	return handler.Kind() == reflect.Func && handler.NumIn() == 2 &&
+		handler.In(0) == contextType && handler.In(1) == interfaceType && isResult(handler)
+}
+
+func isResult(t reflect.Type) bool {
+	return t.NumOut() == 2 && t.Out(0) == interfaceType && t.Out(1) == errorType
+}
+
+// convertMethodHandler returns the handler calling h, whose interceptor calls the interceptor of the server.
+func convertMethodHandler(h reflect.Value) MethodHandler {
+	it := h.Type().In(3)
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor UnaryServerInterceptor) (interface{}, error) {
+		in := reflect.Zero(it)
+		if interceptor != nil {
+			in = reflect.MakeFunc(it, func(args []reflect.Value) []reflect.Value {
+				info := &UnaryServerInfo{}
+				if v, ok := fieldOf(args[2].Elem(), "Server", reflect.Interface); ok {
+					info.Server = v.Interface()
+				}
+				if v, ok := fieldOf(args[2].Elem(), "FullMethod", reflect.String); ok {
+					info.FullMethod = v.String()
+				}
+				next := args[3]
+				reply, err := interceptor(args[0].Interface().(context.Context), args[1].Interface(), info,
+					func(ctx context.Context, req interface{}) (interface{}, error) {
+						return results(next.Call([]reflect.Value{valueOf(&ctx), valueOf(&req)}))
+					})
+				return []reflect.Value{valueOf(&reply), valueOf(&err)}
+			})
+		}
+		return results(h.Call([]reflect.Value{valueOf(&srv), valueOf(&ctx), reflect.ValueOf(dec), in}))
+	}
+}
+
+// valueOf returns the value of the interface pointed by p, which keeps the interface type if it's nil.
+func valueOf(p interface{}) reflect.Value {
+	return reflect.ValueOf(p).Elem()
+}
+
+func results(out []reflect.Value) (interface{}, error) {
+	err, _ := out[1].Interface().(error)
+	return out[0].Interface(), err
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// the types below have the same shape as the ones of google.golang.org/grpc.
+type (
+	grpcUnaryServerInfo struct {
+		Server     interface{}
+		FullMethod string
+	}
+	grpcUnaryHandler           func(ctx context.Context, req interface{}) (interface{}, error)
+	grpcUnaryServerInterceptor func(ctx context.Context, req interface{}, info *grpcUnaryServerInfo, handler grpcUnaryHandler) (interface{}, error)
+	grpcMethodHandler          func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpcUnaryServerInterceptor) (interface{}, error)
+	grpcMethodDesc             struct {
+		MethodName string
+		Handler    grpcMethodHandler
+	}
+	grpcStreamDesc struct {
+		StreamName string
+		Handler    func(srv interface{}, stream interface{}) error
+	}
+	grpcServiceDesc struct {
+		ServiceName string
+		HandlerType interface{}
+		Methods     []grpcMethodDesc
+		Streams     []grpcStreamDesc
+		Metadata    interface{}
+	}
+)
+
+var grpcEchoServiceDesc = grpcServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpcMethodDesc{{
+		MethodName: "Say",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpcUnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return srv.(echoServer).Say(ctx, in)
+			}
+			info := &grpcUnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Say"}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(echoServer).Say(ctx, req.(*wrapperspb.StringValue))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}},
+	Metadata: "echo.proto",
+}
+
+func TestRegisterGRPCService(t *testing.T) {
+	var intercepted []string
+	interceptor := func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+		intercepted = append(intercepted, info.FullMethod)
+		return handler(ctx, req)
+	}
+	invoke := func(s *Server, value string) (interface{}, error) {
+		return s.Invoke(context.Background(), app.NewContext(0), "/test.Echo/Say", func(v interface{}) error {
+			v.(*wrapperspb.StringValue).Value = value
+			return nil
+		})
+	}
+
+	for _, s := range []*Server{NewServer(), NewServer(WithUnaryInterceptor(interceptor))} {
+		s.RegisterGRPCService(&grpcEchoServiceDesc, echo{})
+		assert.DeepEqual(t, "echo.proto", s.Services()[0].Metadata)
+		reply, err := invoke(s, "world")
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "hello world", reply.(*wrapperspb.StringValue).Value)
+		_, err = invoke(s, "missing")
+		assert.DeepEqual(t, NotFound, ErrorCode(err))
+		_, err = invoke(s, "panic")
+		assert.DeepEqual(t, Internal, ErrorCode(err))
+	}
+	assert.DeepEqual(t, []string{"/test.Echo/Say", "/test.Echo/Say", "/test.Echo/Say"}, intercepted)
+
+	withStreams := grpcEchoServiceDesc
+	withStreams.Streams = []grpcStreamDesc{{StreamName: "Repeat"}}
+	for _, desc := range []interface{}{
+		grpcEchoServiceDesc,
+		&withStreams,
+		&struct{ ServiceName string }{"test.Echo"},
+		&struct {
+			ServiceName string
+			Methods     []struct{ MethodName string }
+		}{"test.Echo", []struct{ MethodName string }{{"Say"}}},
+	} {
+		assert.Panic(t, func() {
+			NewServer().RegisterGRPCService(desc, echo{})
+		})
+	}
+	assert.Panic(t, func() {
+		NewServer().RegisterGRPCService(&grpcEchoServiceDesc, struct{}{})
+	})
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errUnknownField = errors.New("unknown field")
+
+// lookupField returns the field of md named name, which is either the proto name or the JSON name.
+func lookupField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// resolveField returns the message containing the field at fieldPath of m, and the field.
+// The messages on the path are created if create is true, otherwise nil is returned if any is not set.
+func resolveField(m protoreflect.Message, fieldPath string, create bool) (protoreflect.Message, protoreflect.FieldDescriptor, error) {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		fd := lookupField(m.Descriptor(), name)
+		if fd == nil {
+			return nil, nil, fmt.Errorf("%w %q in %s", errUnknownField, fieldPath, m.Descriptor().FullName())
+		}
+		if i == len(names)-1 {
+			return m, fd, nil
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, nil, fmt.Errorf("field %q of %s is not a singular message", name, m.Descriptor().FullName())
+		}
+		if !create && !m.Has(fd) {
+			return nil, nil, nil
+		}
+		m = m.Mutable(fd).Message()
+	}
+	return nil, nil, nil
+}
+
+// setField sets the field at fieldPath of m to the values, which are appended to the repeated field.
+func setField(m protoreflect.Message, fieldPath string, values []string) error {
+	m, fd, err := resolveField(m, fieldPath, true)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("map field %q is not supported", fieldPath)
+	case fd.IsList():
+		list := m.Mutable(fd).List()
+		for _, s := range values {
+			v, err := parseValue(fd, s, list.NewElement)
+			if err != nil {
+				return fmt.Errorf("invalid value of %q: %v", fieldPath, err)
+			}
+			list.Append(v)
+		}
+	case len(values) > 0:
+		v, err := parseValue(fd, values[len(values)-1], func() protoreflect.Value { return m.NewField(fd) })
+		if err != nil {
+			return fmt.Errorf("invalid value of %q: %v", fieldPath, err)
+		}
+		m.Set(fd, v)
+	}
+	return nil
+}
+
+// parseValue parses s into the value of fd, newMessage returns the new message value if fd is a message.
+// The messages are parsed by their JSON mapping, e.g. google.protobuf.Timestamp is parsed from RFC 3339.
+func parseValue(fd protoreflect.FieldDescriptor, s string, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown value of enum %s", fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		v := newMessage()
+		m := v.Message().Interface()
+		// the JSON values of the wrappers are not always strings, e.g. google.protobuf.BoolValue
+		if err := protojson.Unmarshal([]byte(s), m); err != nil {
+			if err = protojson.Unmarshal([]byte(strconv.Quote(s)), m); err != nil {
+				return protoreflect.Value{}, err
+			}
+		}
+		return v, nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+}
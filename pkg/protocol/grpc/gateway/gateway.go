@@ -0,0 +1,389 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package gateway transcodes the HTTP/JSON requests to the unary calls of the services registered
+// on a grpc.Server, by the google.api.http options of the methods, e.g.
+//
+//	service Messaging {
+//	  rpc GetMessage(GetMessageRequest) returns (Message) {
+//	    option (google.api.http) = {
+//	      get: "/v1/{name=messages/*}"
+//	    };
+//	  }
+//	}
+//
+//	s := grpc.NewServer()
+//	s.RegisterService(&pb.Messaging_ServiceDesc, &messaging{})
+//	s.Register(h)
+//	gateway.New(s).Register(h)
+//
+// so that GET /v1/messages/123?revision=2 calls GetMessage with {name: "messages/123", revision: 2},
+// and the reply is responded in JSON. The calls go through the interceptors of the server.
+//
+// The rules are the same as the ones of google.api.HttpRule, see
+// https://github.com/googleapis/googleapis/blob/master/google/api/http.proto.
+//
+// NOTE:
+//
+//	The streaming methods are not transcoded.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/grpc"
+	"github.com/cloudwego/hertz/pkg/route"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const contentTypeJSON = "application/json"
+
+// Gateway serves the HTTP/JSON requests of the services registered on a grpc.Server.
+type Gateway struct {
+	server *grpc.Server
+	opts   *options
+}
+
+// binding is a rule of a method.
+type binding struct {
+	fullMethod string
+	rule       *HTTPRule
+	template   *template
+}
+
+// New returns a gateway of the services registered on s.
+func New(s *grpc.Server, opts ...Option) *Gateway {
+	return &Gateway{
+		server: s,
+		opts:   newOptions(opts...),
+	}
+}
+
+// Register adds the routes of the rules of the unary methods to r, the middlewares of r are applied
+// to the requests. It must be called after the services are registered on the server.
+//
+// It panics if a rule is invalid, e.g. the path template can't be parsed or a field of the rule
+// is not in the messages of the method.
+func (g *Gateway) Register(r route.IRoutes) {
+	type routeKey struct{ method, path string }
+	var keys []routeKey
+	routes := make(map[routeKey][]*binding)
+
+	for _, desc := range g.server.Services() {
+		var sd protoreflect.ServiceDescriptor
+		if d, err := g.opts.resolver.FindDescriptorByName(protoreflect.FullName(desc.ServiceName)); err == nil {
+			sd, _ = d.(protoreflect.ServiceDescriptor)
+		}
+		methods := make([]string, 0, len(desc.Methods)+len(desc.Streams))
+		for _, md := range desc.Methods {
+			methods = append(methods, md.MethodName)
+		}
+		for _, sd := range desc.Streams {
+			methods = append(methods, sd.StreamName)
+		}
+
+		for i, name := range methods {
+			fullMethod := "/" + desc.ServiceName + "/" + name
+			var md protoreflect.MethodDescriptor
+			if sd != nil {
+				md = sd.Methods().ByName(protoreflect.Name(name))
+			}
+			rules, ok := g.opts.rules[fullMethod]
+			if !ok && md != nil {
+				var err error
+				if rules, err = methodRules(md); err != nil {
+					panic(fmt.Sprintf("gateway: failed to read the rules of %s: %v", fullMethod, err))
+				}
+			}
+			if len(rules) == 0 {
+				continue
+			}
+			if i >= len(desc.Methods) {
+				hlog.SystemLogger().Warnf("gateway: streaming method %s is not transcoded", fullMethod)
+				continue
+			}
+
+			for _, rule := range rules {
+				b, err := newBinding(fullMethod, rule, md)
+				if err != nil {
+					panic(err.Error())
+				}
+				key := routeKey{method: rule.Method, path: b.template.routePath()}
+				if _, ok := routes[key]; !ok {
+					keys = append(keys, key)
+				}
+				routes[key] = append(routes[key], b)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		r.Handle(key.method, key.path, g.handler(routes[key]))
+	}
+}
+
+func newBinding(fullMethod string, rule *HTTPRule, md protoreflect.MethodDescriptor) (*binding, error) {
+	if rule.Method == "" || rule.Pattern == "" {
+		return nil, fmt.Errorf("gateway: the rule of %s has no pattern", fullMethod)
+	}
+	t, err := parseTemplate(rule.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	if md != nil {
+		// the fields are checked if the descriptor is found
+		in, out := md.Input(), md.Output()
+		for _, v := range t.variables {
+			if err = checkField(in, v.fieldPath); err != nil {
+				break
+			}
+		}
+		if err == nil && rule.Body != "" && rule.Body != "*" {
+			err = checkField(in, rule.Body)
+		}
+		if err == nil && rule.ResponseBody != "" {
+			err = checkField(out, rule.ResponseBody)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gateway: invalid rule %s %s of %s: %v", rule.Method, rule.Pattern, fullMethod, err)
+		}
+	}
+	return &binding{fullMethod: fullMethod, rule: rule, template: t}, nil
+}
+
+func checkField(md protoreflect.MessageDescriptor, fieldPath string) error {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		fd := lookupField(md, name)
+		if fd == nil {
+			return fmt.Errorf("%w %q in %s", errUnknownField, fieldPath, md.FullName())
+		}
+		if i < len(names)-1 {
+			if md = fd.Message(); md == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q of %s is not a singular message", name, fd.ContainingMessage().FullName())
+			}
+		}
+	}
+	return nil
+}
+
+// handler serves the bindings of the same route, the first one matching the path is called.
+func (g *Gateway) handler(bindings []*binding) app.HandlerFunc {
+	t := bindings[0].template
+	return func(c context.Context, ctx *app.RequestContext) {
+		values := make([]string, len(t.segments))
+		for _, b := range bindings {
+			for i, seg := range t.segments {
+				if t.isParam(i) {
+					values[i] = ctx.Param(paramName(i))
+				} else {
+					values[i] = seg.literal
+				}
+			}
+			if vars, ok := b.template.match(values); ok {
+				g.serve(c, ctx, b, vars)
+				return
+			}
+		}
+		g.opts.errorHandler(c, ctx, grpc.Errorf(grpc.NotFound, "Not Found"))
+	}
+}
+
+func (g *Gateway) serve(c context.Context, ctx *app.RequestContext, b *binding, vars []string) {
+	dec := func(v interface{}) error {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return grpc.Errorf(grpc.Internal, "gateway: request message is %T, want proto.Message", v)
+		}
+		if err := g.decode(ctx, b, vars, m.ProtoReflect()); err != nil {
+			return grpc.Errorf(grpc.InvalidArgument, "%v", err)
+		}
+		return nil
+	}
+	reply, err := g.server.Invoke(c, ctx, b.fullMethod, dec)
+	if err != nil {
+		g.opts.errorHandler(c, ctx, err)
+		return
+	}
+	m, ok := reply.(proto.Message)
+	if !ok {
+		g.opts.errorHandler(c, ctx, grpc.Errorf(grpc.Internal, "gateway: response message is %T, want proto.Message", reply))
+		return
+	}
+	data, err := g.encode(m.ProtoReflect(), b.rule.ResponseBody)
+	if err != nil {
+		g.opts.errorHandler(c, ctx, grpc.Errorf(grpc.Internal, "gateway: failed to marshal the response: %v", err))
+		return
+	}
+	ctx.Data(consts.StatusOK, contentTypeJSON, data)
+}
+
+// decode sets the fields of m by the body, the query and the path in order, the latter ones take precedence.
+func (g *Gateway) decode(ctx *app.RequestContext, b *binding, vars []string, m protoreflect.Message) error {
+	body := ctx.Request.Body()
+	switch b.rule.Body {
+	case "":
+	case "*":
+		if len(body) > 0 {
+			if err := g.opts.unmarshalOptions.Unmarshal(body, m.Interface()); err != nil {
+				return err
+			}
+		}
+	default:
+		if len(body) > 0 {
+			parent, fd, err := resolveField(m, b.rule.Body, true)
+			if err != nil {
+				return err
+			}
+			// unmarshal the body as the field of a new message, so that fd can be of any kind
+			tmp := parent.New()
+			wrapped := append(append([]byte(`{"`+string(fd.Name())+`":`), body...), '}')
+			if err = g.opts.unmarshalOptions.Unmarshal(wrapped, tmp.Interface()); err != nil {
+				return err
+			}
+			if tmp.Has(fd) {
+				parent.Set(fd, tmp.Get(fd))
+			}
+		}
+	}
+
+	if b.rule.Body != "*" {
+		var err error
+		ctx.QueryArgs().VisitAll(func(key, value []byte) {
+			if err != nil || b.bound(string(key)) {
+				return
+			}
+			if e := setField(m, string(key), []string{string(value)}); e != nil && !errors.Is(e, errUnknownField) {
+				err = e
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, v := range b.template.variables {
+		if err := setField(m, v.fieldPath, vars[i:i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bound reports whether the field at fieldPath is bound by the path or the body,
+// which are not bound by the query.
+func (b *binding) bound(fieldPath string) bool {
+	covers := func(p string) bool {
+		return fieldPath == p || strings.HasPrefix(fieldPath, p+".")
+	}
+	if b.rule.Body != "" && covers(b.rule.Body) {
+		return true
+	}
+	for _, v := range b.template.variables {
+		if covers(v.fieldPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// encode marshals m, or the field of m at responseBody if it's not empty.
+func (g *Gateway) encode(m protoreflect.Message, responseBody string) ([]byte, error) {
+	if responseBody == "" {
+		return g.opts.marshalOptions.Marshal(m.Interface())
+	}
+	parent, fd, err := resolveField(m, responseBody, false)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return []byte("null"), nil
+	}
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		return g.opts.marshalOptions.Marshal(parent.Get(fd).Message().Interface())
+	}
+	// marshal the field as the only field of a new message, so that fd can be of any kind
+	tmp := parent.New()
+	if parent.Has(fd) {
+		tmp.Set(fd, parent.Get(fd))
+	}
+	opts := g.opts.marshalOptions
+	opts.EmitUnpopulated = true
+	data, err := opts.Marshal(tmp.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	name := fd.JSONName()
+	if opts.UseProtoNames {
+		name = string(fd.Name())
+	}
+	return fields[name], nil
+}
+
+// DefaultErrorHandler responds err in JSON, e.g. {"code":5,"message":"Not Found"}, whose status code
+// is mapped from the code of err by HTTPStatusFromCode.
+func DefaultErrorHandler(c context.Context, ctx *app.RequestContext, err error) {
+	code, message := grpc.ErrorCode(err), err.Error()
+	var e *grpc.Error
+	if errors.As(err, &e) {
+		message = e.Message
+	}
+	ctx.AbortWithStatusJSON(HTTPStatusFromCode(code), utils.H{"code": int(code), "message": message})
+}
+
+// HTTPStatusFromCode returns the HTTP status code mapped from the gRPC status code, see
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto.
+func HTTPStatusFromCode(code grpc.Code) int {
+	switch code {
+	case grpc.OK:
+		return consts.StatusOK
+	case grpc.Canceled:
+		// Client Closed Request
+		return 499
+	case grpc.InvalidArgument, grpc.FailedPrecondition, grpc.OutOfRange:
+		return consts.StatusBadRequest
+	case grpc.DeadlineExceeded:
+		return consts.StatusGatewayTimeout
+	case grpc.NotFound:
+		return consts.StatusNotFound
+	case grpc.AlreadyExists, grpc.Aborted:
+		return consts.StatusConflict
+	case grpc.PermissionDenied:
+		return consts.StatusForbidden
+	case grpc.Unauthenticated:
+		return consts.StatusUnauthorized
+	case grpc.ResourceExhausted:
+		return consts.StatusTooManyRequests
+	case grpc.Unimplemented:
+		return consts.StatusNotImplemented
+	case grpc.Unavailable:
+		return consts.StatusServiceUnavailable
+	}
+	return consts.StatusInternalServerError
+}
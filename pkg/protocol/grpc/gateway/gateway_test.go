@@ -0,0 +1,360 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/grpc"
+	"github.com/cloudwego/hertz/pkg/route"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// encodeRule encodes rule as google.api.HttpRule.
+func encodeRule(rule *HTTPRule) []byte {
+	var b []byte
+	appendString := func(num protowire.Number, s string) {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	switch rule.Method {
+	case "GET":
+		appendString(2, rule.Pattern)
+	case "PUT":
+		appendString(3, rule.Pattern)
+	case "POST":
+		appendString(4, rule.Pattern)
+	case "DELETE":
+		appendString(5, rule.Pattern)
+	case "PATCH":
+		appendString(6, rule.Pattern)
+	default:
+		var custom []byte
+		custom = protowire.AppendTag(custom, 1, protowire.BytesType)
+		custom = protowire.AppendString(custom, rule.Method)
+		custom = protowire.AppendTag(custom, 2, protowire.BytesType)
+		custom = protowire.AppendString(custom, rule.Pattern)
+		b = protowire.AppendTag(b, 8, protowire.BytesType)
+		b = protowire.AppendBytes(b, custom)
+	}
+	if rule.Body != "" {
+		appendString(7, rule.Body)
+	}
+	for _, binding := range rule.AdditionalBindings {
+		b = protowire.AppendTag(b, 11, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeRule(binding))
+	}
+	if rule.ResponseBody != "" {
+		appendString(12, rule.ResponseBody)
+	}
+	return b
+}
+
+func methodOptions(rule *HTTPRule) *descriptorpb.MethodOptions {
+	opts := &descriptorpb.MethodOptions{Deprecated: proto.Bool(false)}
+	var b []byte
+	b = protowire.AppendTag(b, httpExtensionNumber, protowire.BytesType)
+	b = protowire.AppendBytes(b, encodeRule(rule))
+	opts.ProtoReflect().SetUnknown(b)
+	return opts
+}
+
+func field(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(strings.Replace(name, "_i", "I", 1)),
+		Number:   proto.Int32(num),
+		Type:     typ.Enum(),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if typeName != "" {
+		fd.TypeName = proto.String(typeName)
+	}
+	if repeated {
+		fd.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	}
+	return fd
+}
+
+var (
+	getRule = &HTTPRule{
+		Method: "GET", Pattern: "/v1/{name=messages/*}",
+		AdditionalBindings: []*HTTPRule{{Method: "GET", Pattern: "/v1/users/{sub.user_id}/messages/{name}"}},
+	}
+	updateRule = &HTTPRule{Method: "PATCH", Pattern: "/v1/{name=messages/*}", Body: "message"}
+	createRule = &HTTPRule{Method: "POST", Pattern: "/v1/messages", Body: "*", ResponseBody: "tags"}
+	cancelRule = &HTTPRule{Method: "POST", Pattern: "/v1/{name=messages/*}:cancel"}
+	watchRule  = &HTTPRule{Method: "GET", Pattern: "/v1/watch"}
+)
+
+func newFiles(t *testing.T) *protoregistry.Files {
+	const (
+		str    = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		i32    = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		bl     = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		msg    = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		enm    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+		sub    = ".gatewaytest.Sub"
+		kind   = ".gatewaytest.Kind"
+		stream = true
+	)
+	method := func(name, in, out string, rule *HTTPRule, streaming bool) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(name),
+			InputType:       proto.String(".gatewaytest." + in),
+			OutputType:      proto.String(".gatewaytest." + out),
+			Options:         methodOptions(rule),
+			ServerStreaming: proto.Bool(streaming),
+		}
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("gatewaytest/messaging.proto"),
+		Package: proto.String("gatewaytest"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Kind"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("KIND_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("KIND_TEXT"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Sub"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("user_id", 1, str, "", false),
+				field("flag", 2, bl, "", false),
+			}},
+			{Name: proto.String("Message"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, str, "", false),
+				field("text", 2, str, "", false),
+				field("revision", 3, i32, "", false),
+				field("tags", 4, str, "", true),
+				field("sub", 5, msg, sub, false),
+				field("kind", 6, enm, kind, false),
+			}},
+			{Name: proto.String("UpdateRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, str, "", false),
+				field("message", 2, msg, ".gatewaytest.Message", false),
+			}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Messaging"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("GetMessage", "Message", "Message", getRule, false),
+				method("UpdateMessage", "UpdateRequest", "Message", updateRule, false),
+				method("CreateMessage", "Message", "Message", createRule, false),
+				method("CancelMessage", "Message", "Message", cancelRule, false),
+				method("Watch", "Message", "Message", watchRule, stream),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	assert.Nil(t, err)
+	files := &protoregistry.Files{}
+	assert.Nil(t, files.RegisterFile(fd))
+	return files
+}
+
+// unaryHandler returns the handler calling f with the request message of md.
+func unaryHandler(md protoreflect.MethodDescriptor, f func(c context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error)) grpc.MethodHandler {
+	return func(srv interface{}, c context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		in := dynamicpb.NewMessage(md.Input())
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		handler := func(c context.Context, req interface{}) (interface{}, error) {
+			return f(c, req.(*dynamicpb.Message))
+		}
+		if interceptor == nil {
+			return handler(c, in)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gatewaytest.Messaging/" + string(md.Name())}
+		return interceptor(c, in, info, handler)
+	}
+}
+
+func newGatewayEngine(t *testing.T, opts ...Option) (*route.Engine, *[]string) {
+	files := newFiles(t)
+	d, err := files.FindDescriptorByName("gatewaytest.Messaging")
+	assert.Nil(t, err)
+	sd := d.(protoreflect.ServiceDescriptor)
+	methods := sd.Methods()
+
+	echo := func(c context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+		if name := req.Get(req.Descriptor().Fields().ByName("name")).String(); strings.HasSuffix(name, "missing") {
+			return nil, grpc.Errorf(grpc.NotFound, "%s not found", name)
+		}
+		return req, nil
+	}
+	update := func(c context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+		fields := req.Descriptor().Fields()
+		m := req.Get(fields.ByName("message")).Message().Interface().(*dynamicpb.Message)
+		m.Set(m.Descriptor().Fields().ByName("name"), req.Get(fields.ByName("name")))
+		return m, nil
+	}
+
+	var (
+		mu            sync.Mutex
+		methodsCalled []string
+	)
+	s := grpc.NewServer(grpc.WithUnaryInterceptor(func(c context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		mu.Lock()
+		methodsCalled = append(methodsCalled, info.FullMethod)
+		mu.Unlock()
+		return handler(c, req)
+	}))
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "gatewaytest.Messaging",
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetMessage", Handler: unaryHandler(methods.ByName("GetMessage"), echo)},
+			{MethodName: "UpdateMessage", Handler: unaryHandler(methods.ByName("UpdateMessage"), update)},
+			{MethodName: "CreateMessage", Handler: unaryHandler(methods.ByName("CreateMessage"), echo)},
+			{MethodName: "CancelMessage", Handler: unaryHandler(methods.ByName("CancelMessage"), echo)},
+		},
+		Streams: []grpc.StreamDesc{
+			{StreamName: "Watch", ServerStreams: true, Handler: func(srv interface{}, stream grpc.ServerStream) error { return nil }},
+		},
+	}, nil)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	s.Register(engine)
+	New(s, append([]Option{WithResolver(files)}, opts...)...).Register(engine)
+	return engine, &methodsCalled
+}
+
+func TestGateway(t *testing.T) {
+	engine, called := newGatewayEngine(t)
+
+	// path and query
+	w := ut.PerformRequest(engine, consts.MethodGet, "/v1/messages/m1?revision=2&tags=a&tags=b&sub.flag=true&kind=KIND_TEXT&name=ignored&unknown=1", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, contentTypeJSON, string(w.Header().ContentType()))
+	assert.DeepEqual(t, `{"name":"messages/m1","revision":2,"tags":["a","b"],"sub":{"flag":true},"kind":"KIND_TEXT"}`, strings.Replace(w.Body.String(), " ", "", -1))
+
+	// additional binding with nested field
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v1/users/u1/messages/m2", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, `{"name":"m2","sub":{"userId":"u1"}}`, strings.Replace(w.Body.String(), " ", "", -1))
+
+	// body field
+	w = ut.PerformRequest(engine, consts.MethodPatch, "/v1/messages/m3", &ut.Body{Body: strings.NewReader(`{"text":"hi","name":"other"}`), Len: 28})
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, `{"name":"messages/m3","text":"hi"}`, strings.Replace(w.Body.String(), " ", "", -1))
+
+	// whole body and response body
+	w = ut.PerformRequest(engine, consts.MethodPost, "/v1/messages?tags=ignored", &ut.Body{Body: strings.NewReader(`{"tags":["x","y"]}`), Len: 18})
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, `["x","y"]`, strings.Replace(w.Body.String(), " ", "", -1))
+
+	// verb
+	w = ut.PerformRequest(engine, consts.MethodPost, "/v1/messages/m4:cancel", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, `{"name":"messages/m4"}`, strings.Replace(w.Body.String(), " ", "", -1))
+	w = ut.PerformRequest(engine, consts.MethodPost, "/v1/messages/m4:other", nil)
+	assert.DeepEqual(t, consts.StatusNotFound, w.Code)
+	assert.DeepEqual(t, `{"code":5,"message":"Not Found"}`, w.Body.String())
+
+	// errors, the invalid requests fail before the interceptors
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v1/messages/missing", nil)
+	assert.DeepEqual(t, consts.StatusNotFound, w.Code)
+	assert.DeepEqual(t, `{"code":5,"message":"messages/missing not found"}`, w.Body.String())
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v1/messages/m1?revision=x", nil)
+	assert.DeepEqual(t, consts.StatusBadRequest, w.Code)
+	w = ut.PerformRequest(engine, consts.MethodPatch, "/v1/messages/m3", &ut.Body{Body: strings.NewReader(`{`), Len: 1})
+	assert.DeepEqual(t, consts.StatusBadRequest, w.Code)
+
+	// streaming methods are not transcoded
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v1/watch", nil)
+	assert.DeepEqual(t, consts.StatusNotFound, w.Code)
+
+	assert.DeepEqual(t, []string{
+		"/gatewaytest.Messaging/GetMessage", "/gatewaytest.Messaging/GetMessage",
+		"/gatewaytest.Messaging/UpdateMessage", "/gatewaytest.Messaging/CreateMessage",
+		"/gatewaytest.Messaging/CancelMessage", "/gatewaytest.Messaging/GetMessage",
+	}, *called)
+}
+
+func TestGatewayOptions(t *testing.T) {
+	var handled error
+	engine, _ := newGatewayEngine(t,
+		WithHTTPRule("/gatewaytest.Messaging/GetMessage", &HTTPRule{Method: "GET", Pattern: "/v2/{name}", ResponseBody: "sub"}),
+		WithErrorHandler(func(c context.Context, ctx *app.RequestContext, err error) {
+			handled = err
+			ctx.AbortWithStatus(consts.StatusTeapot)
+		}))
+
+	w := ut.PerformRequest(engine, consts.MethodGet, "/v2/m1?sub.user_id=u1", nil)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, `{"userId":"u1"}`, strings.Replace(w.Body.String(), " ", "", -1))
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v1/messages/m1", nil)
+	assert.DeepEqual(t, consts.StatusNotFound, w.Code)
+
+	w = ut.PerformRequest(engine, consts.MethodGet, "/v2/missing", nil)
+	assert.DeepEqual(t, consts.StatusTeapot, w.Code)
+	assert.DeepEqual(t, grpc.NotFound, grpc.ErrorCode(handled))
+}
+
+func TestGatewayInvalidRule(t *testing.T) {
+	for _, rule := range []*HTTPRule{
+		{Method: "GET", Pattern: "/v1/{unknown}"},
+		{Method: "GET", Pattern: "/v1/{name}", ResponseBody: "unknown"},
+		{Method: "POST", Pattern: "/v1/{name}", Body: "tags.x"},
+		{Method: "GET", Pattern: "v1"},
+	} {
+		assert.Panic(t, func() {
+			newGatewayEngine(t, WithHTTPRule("/gatewaytest.Messaging/GetMessage", rule))
+		})
+	}
+}
+
+func TestMethodRules(t *testing.T) {
+	files := newFiles(t)
+	d, err := files.FindDescriptorByName("gatewaytest.Messaging.GetMessage")
+	assert.Nil(t, err)
+	rules, err := methodRules(d.(protoreflect.MethodDescriptor))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []*HTTPRule{getRule, getRule.AdditionalBindings[0]}, rules)
+
+	custom := &HTTPRule{Method: "OPTIONS", Pattern: "/v1/messages", Body: "*", ResponseBody: "name"}
+	rule, err := parseRule(encodeRule(custom), true)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, custom, rule)
+
+	_, err = parseRule(encodeRule(&HTTPRule{Method: "GET", Pattern: "/", AdditionalBindings: []*HTTPRule{getRule}}), true)
+	assert.DeepEqual(t, errInvalidRule, err)
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	assert.DeepEqual(t, consts.StatusOK, HTTPStatusFromCode(grpc.OK))
+	assert.DeepEqual(t, 499, HTTPStatusFromCode(grpc.Canceled))
+	assert.DeepEqual(t, consts.StatusConflict, HTTPStatusFromCode(grpc.Aborted))
+	assert.DeepEqual(t, consts.StatusInternalServerError, HTTPStatusFromCode(grpc.DataLoss))
+	assert.DeepEqual(t, consts.StatusInternalServerError, HTTPStatusFromCode(grpc.Code(100)))
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+type (
+	options struct {
+		resolver         Resolver
+		rules            map[string][]*HTTPRule
+		marshalOptions   protojson.MarshalOptions
+		unmarshalOptions protojson.UnmarshalOptions
+		errorHandler     func(c context.Context, ctx *app.RequestContext, err error)
+	}
+
+	Option func(o *options)
+)
+
+// Resolver finds the descriptors of the services, e.g. *protoregistry.Files.
+type Resolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		resolver:     protoregistry.GlobalFiles,
+		rules:        make(map[string][]*HTTPRule),
+		errorHandler: DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithResolver sets the resolver of the service descriptors, which is protoregistry.GlobalFiles by default,
+// where the descriptors of the generated packages are registered.
+func WithResolver(r Resolver) Option {
+	return func(o *options) {
+		o.resolver = r
+	}
+}
+
+// WithHTTPRule sets the rules of the method fullMethod, e.g. /helloworld.Greeter/SayHello,
+// which replace the google.api.http option of the method.
+func WithHTTPRule(fullMethod string, rules ...*HTTPRule) Option {
+	return func(o *options) {
+		o.rules[fullMethod] = rules
+	}
+}
+
+// WithMarshalOptions sets the options marshaling the response messages into JSON.
+func WithMarshalOptions(opts protojson.MarshalOptions) Option {
+	return func(o *options) {
+		o.marshalOptions = opts
+	}
+}
+
+// WithUnmarshalOptions sets the options unmarshaling the request bodies into the messages.
+func WithUnmarshalOptions(opts protojson.UnmarshalOptions) Option {
+	return func(o *options) {
+		o.unmarshalOptions = opts
+	}
+}
+
+// WithErrorHandler sets the handler of the errors of the calls, including the ones transcoding
+// the requests, which are InvalidArgument. DefaultErrorHandler is used by default.
+func WithErrorHandler(f func(c context.Context, ctx *app.RequestContext, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = f
+	}
+}
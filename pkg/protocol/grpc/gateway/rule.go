@@ -0,0 +1,179 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// httpExtensionNumber is the field number of the google.api.http extension of google.protobuf.MethodOptions.
+const httpExtensionNumber = 72295728
+
+var errInvalidRule = errors.New("gateway: invalid google.api.http option")
+
+// HTTPRule maps a method to the HTTP requests, which is the same as google.api.HttpRule,
+// see https://github.com/googleapis/googleapis/blob/master/google/api/http.proto.
+type HTTPRule struct {
+	// Method is the HTTP method, e.g. GET or the kind of the custom pattern
+	Method string
+	// Pattern is the path template, e.g. /v1/{name=messages/*}
+	Pattern string
+	// Body is the field of the request message mapped to the request body, "*" for the whole message
+	// and empty for no body. The fields not bound by the path or the body are bound by the query.
+	Body string
+	// ResponseBody is the field of the response message mapped to the response body, empty for the whole message
+	ResponseBody string
+	// AdditionalBindings are the other rules of the method, which can't have additional bindings
+	AdditionalBindings []*HTTPRule
+}
+
+// methodRules returns the rules of the google.api.http option of md, nil if there is no option.
+// The option is read from the encoded MethodOptions, so that the generated package of the
+// annotations is not required.
+func methodRules(md protoreflect.MethodDescriptor) ([]*HTTPRule, error) {
+	opts := md.Options()
+	if opts == nil {
+		return nil, nil
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	var rule *HTTPRule
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if num == httpExtensionNumber && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			// the last one wins
+			if rule, err = parseRule(v, true); err != nil {
+				return nil, err
+			}
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	return flattenRule(rule), nil
+}
+
+// parseRule parses the encoded google.api.HttpRule.
+func parseRule(b []byte, top bool) (rule *HTTPRule, err error) {
+	rule = &HTTPRule{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch num {
+		case 2:
+			rule.Method, rule.Pattern = "GET", string(v)
+		case 3:
+			rule.Method, rule.Pattern = "PUT", string(v)
+		case 4:
+			rule.Method, rule.Pattern = "POST", string(v)
+		case 5:
+			rule.Method, rule.Pattern = "DELETE", string(v)
+		case 6:
+			rule.Method, rule.Pattern = "PATCH", string(v)
+		case 7:
+			rule.Body = string(v)
+		case 8:
+			if rule.Method, rule.Pattern, err = parseCustomPattern(v); err != nil {
+				return nil, err
+			}
+		case 11:
+			if !top {
+				return nil, errInvalidRule
+			}
+			var binding *HTTPRule
+			binding, err = parseRule(v, false)
+			if err != nil {
+				return nil, err
+			}
+			rule.AdditionalBindings = append(rule.AdditionalBindings, binding)
+		case 12:
+			rule.ResponseBody = string(v)
+		}
+	}
+	return rule, nil
+}
+
+// parseCustomPattern parses the encoded google.api.CustomHttpPattern.
+func parseCustomPattern(b []byte) (kind, path string, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return "", "", protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return "", "", protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch num {
+		case 1:
+			kind = string(v)
+		case 2:
+			path = string(v)
+		}
+	}
+	return kind, path, nil
+}
+
+// flattenRule returns rule and its additional bindings.
+func flattenRule(rule *HTTPRule) []*HTTPRule {
+	return append([]*HTTPRule{rule}, rule.AdditionalBindings...)
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type segmentKind uint8
+
+const (
+	literalSegment segmentKind = iota
+	// "*" matches a single segment
+	wildcardSegment
+	// "**" matches the rest of the path
+	deepWildcardSegment
+)
+
+type segment struct {
+	kind    segmentKind
+	literal string
+}
+
+// variable binds the segments [start, end) to the field of the request message
+type variable struct {
+	fieldPath  string
+	start, end int
+}
+
+// template is a compiled path template of an HTTP rule, the syntax of which is
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+//
+// see https://github.com/googleapis/googleapis/blob/master/google/api/http.proto.
+type template struct {
+	segments  []segment
+	variables []variable
+	verb      string
+}
+
+func parseTemplate(s string) (*template, error) {
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("gateway: path template %q must begin with '/'", s)
+	}
+	p := &templateParser{s: s, i: 1}
+	t, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("gateway: invalid path template %q: %v", s, err)
+	}
+	return t, nil
+}
+
+type templateParser struct {
+	s string
+	i int
+	t template
+}
+
+func (p *templateParser) parse() (*template, error) {
+	if err := p.segments(false); err != nil {
+		return nil, err
+	}
+	if p.i < len(p.s) && p.s[p.i] == ':' {
+		p.t.verb = p.s[p.i+1:]
+		if p.t.verb == "" || strings.ContainsAny(p.t.verb, "/{}") {
+			return nil, fmt.Errorf("invalid verb %q", p.t.verb)
+		}
+		p.i = len(p.s)
+	}
+	if p.i != len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.s[p.i], p.i)
+	}
+	for i, seg := range p.t.segments {
+		if seg.kind == deepWildcardSegment && i != len(p.t.segments)-1 {
+			return nil, fmt.Errorf("'**' must be the last segment")
+		}
+	}
+	return &p.t, nil
+}
+
+// segments parses the segments until the end, the verb, or '}' if inVariable.
+func (p *templateParser) segments(inVariable bool) error {
+	for {
+		if err := p.segment(inVariable); err != nil {
+			return err
+		}
+		if p.i == len(p.s) || p.s[p.i] != '/' {
+			return nil
+		}
+		p.i++
+	}
+}
+
+func (p *templateParser) segment(inVariable bool) error {
+	if p.i < len(p.s) && p.s[p.i] == '{' {
+		if inVariable {
+			return fmt.Errorf("nested variable at %d", p.i)
+		}
+		return p.variable()
+	}
+	end := p.i
+	for end < len(p.s) && p.s[end] != '/' && p.s[end] != '{' && p.s[end] != '}' &&
+		// the verb follows the last segment
+		!(p.s[end] == ':' && !inVariable) {
+		end++
+	}
+	lit := p.s[p.i:end]
+	p.i = end
+	switch lit {
+	case "":
+		return fmt.Errorf("empty segment at %d", end)
+	case "*":
+		p.t.segments = append(p.t.segments, segment{kind: wildcardSegment})
+	case "**":
+		p.t.segments = append(p.t.segments, segment{kind: deepWildcardSegment})
+	default:
+		p.t.segments = append(p.t.segments, segment{kind: literalSegment, literal: lit})
+	}
+	return nil
+}
+
+func (p *templateParser) variable() error {
+	p.i++
+	end := p.i
+	for end < len(p.s) && p.s[end] != '=' && p.s[end] != '}' {
+		end++
+	}
+	if end == len(p.s) {
+		return fmt.Errorf("unclosed variable")
+	}
+	v := variable{fieldPath: p.s[p.i:end], start: len(p.t.segments)}
+	if !validFieldPath(v.fieldPath) {
+		return fmt.Errorf("invalid field path %q", v.fieldPath)
+	}
+	p.i = end
+	if p.s[p.i] == '=' {
+		p.i++
+		if err := p.segments(true); err != nil {
+			return err
+		}
+		if p.i == len(p.s) || p.s[p.i] != '}' {
+			return fmt.Errorf("unclosed variable")
+		}
+	} else {
+		p.t.segments = append(p.t.segments, segment{kind: wildcardSegment})
+	}
+	p.i++
+	v.end = len(p.t.segments)
+	p.t.variables = append(p.t.variables, v)
+	return nil
+}
+
+func validFieldPath(s string) bool {
+	for _, ident := range strings.Split(s, ".") {
+		if ident == "" {
+			return false
+		}
+		for i := 0; i < len(ident); i++ {
+			c := ident[i]
+			if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// routePath returns the path of the hertz route matching the template, whose parameters are
+// named p0, p1... in order, so that the templates of the same shape share the route.
+//
+// NOTE:
+//
+//	The segment with the verb is matched by a parameter, since hertz takes ':' as the beginning of a parameter.
+func (t *template) routePath() string {
+	var b strings.Builder
+	for i, seg := range t.segments {
+		b.WriteByte('/')
+		switch {
+		case seg.kind == deepWildcardSegment:
+			b.WriteString("*" + paramName(i))
+		case t.isParam(i):
+			b.WriteString(":" + paramName(i))
+		default:
+			b.WriteString(seg.literal)
+		}
+	}
+	return b.String()
+}
+
+// isParam reports whether the segment i is matched by a parameter of the route.
+func (t *template) isParam(i int) bool {
+	seg := t.segments[i]
+	return seg.kind != literalSegment || t.verb != "" && i == len(t.segments)-1 || strings.ContainsAny(seg.literal, ":*")
+}
+
+func paramName(i int) string {
+	return "p" + strconv.Itoa(i)
+}
+
+// match matches the values of the segments, which are the parameters of the route or the literals,
+// and returns the values of the variables in order.
+func (t *template) match(values []string) ([]string, bool) {
+	if len(values) != len(t.segments) {
+		return nil, false
+	}
+	if t.verb != "" {
+		last := values[len(values)-1]
+		if !strings.HasSuffix(last, ":"+t.verb) {
+			return nil, false
+		}
+		values[len(values)-1] = last[:len(last)-len(t.verb)-1]
+	}
+	for i, seg := range t.segments {
+		switch seg.kind {
+		case literalSegment:
+			if values[i] != seg.literal {
+				return nil, false
+			}
+		case wildcardSegment:
+			if values[i] == "" {
+				return nil, false
+			}
+		}
+	}
+	vars := make([]string, len(t.variables))
+	for i, v := range t.variables {
+		vars[i] = strings.Join(values[v.start:v.end], "/")
+	}
+	return vars, true
+}
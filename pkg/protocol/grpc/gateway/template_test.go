@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestParseTemplate(t *testing.T) {
+	for _, c := range []struct {
+		pattern, route string
+		values         []string
+		vars           []string
+	}{
+		{"/v1/messages", "/v1/messages", []string{"v1", "messages"}, []string{}},
+		{"/v1/{name}", "/v1/:p1", []string{"v1", "m1"}, []string{"m1"}},
+		{"/v1/{name=messages/*}", "/v1/messages/:p2", []string{"v1", "messages", "m1"}, []string{"messages/m1"}},
+		{"/v1/{parent=shelves/*}/books/{book.id}", "/v1/shelves/:p2/books/:p4", []string{"v1", "shelves", "s1", "books", "b1"}, []string{"shelves/s1", "b1"}},
+		{"/v1/{name=files/**}", "/v1/files/*p2", []string{"v1", "files", "a/b/c"}, []string{"files/a/b/c"}},
+		{"/v1/{name=messages/*}:cancel", "/v1/messages/:p2", []string{"v1", "messages", "m1:cancel"}, []string{"messages/m1"}},
+		{"/v1/messages:batchGet", "/v1/:p1", []string{"v1", "messages:batchGet"}, []string{}},
+		{"/v1/*/items", "/v1/:p1/items", []string{"v1", "x", "items"}, []string{}},
+	} {
+		tmpl, err := parseTemplate(c.pattern)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, c.route, tmpl.routePath())
+		vars, ok := tmpl.match(c.values)
+		assert.True(t, ok)
+		assert.DeepEqual(t, c.vars, vars)
+	}
+
+	for _, pattern := range []string{
+		"v1/messages", "/v1//messages", "/v1/{name", "/v1/{name=messages/{id}}", "/v1/{na-me}",
+		"/v1/**/messages", "/v1/messages:", "/v1/messages}",
+	} {
+		_, err := parseTemplate(pattern)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestTemplateMatch(t *testing.T) {
+	tmpl, err := parseTemplate("/v1/{name=messages/*}:cancel")
+	assert.Nil(t, err)
+	_, ok := tmpl.match([]string{"v1", "messages", "m1"})
+	assert.False(t, ok)
+	_, ok = tmpl.match([]string{"v1", "messages", ":cancel"})
+	assert.False(t, ok)
+
+	tmpl, err = parseTemplate("/v1/messages:batchGet")
+	assert.Nil(t, err)
+	_, ok = tmpl.match([]string{"v1", "other:batchGet"})
+	assert.False(t, ok)
+}
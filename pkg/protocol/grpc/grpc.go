@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package grpc serves unary gRPC services on the routes of a hertz server over the HTTP/2
+// protocol server registered for suite.HTTP2, so that they share the port, the middlewares
+// and the tracers with the HTTP routes, e.g.
+//
+//	h := server.New(server.WithH2C(true))
+//	h.AddProtocol(suite.HTTP2, factory.NewServerFactory()) // e.g. hertz-contrib/http2
+//	s := grpc.NewServer(grpc.WithUnaryInterceptor(logging))
+//	s.RegisterGRPCService(&pb.Greeter_ServiceDesc, &greeter{})
+//	s.Register(h)
+//	h.GET("/ping", ping)
+//
+// The descriptors of unary services generated by protoc-gen-go-grpc are registered by
+// RegisterGRPCService as is. The streaming methods can only be served by ServiceDesc,
+// whose handlers are written against ServerStream of this package.
+//
+// NOTE:
+//
+//	This package does not implement HTTP/2, the standard gRPC clients are served only if
+//	a protocol server is registered for suite.HTTP2, selected by ALPN with TLS or by the
+//	client preface with H2C. Over HTTP/1.1 the calls are served with chunked bodies and
+//	trailers, which the standard gRPC clients do not interoperate with.
+package grpc
+
+import (
+	"context"
+)
+
+// MethodHandler handles a unary call, dec decodes the request message into its argument.
+type MethodHandler func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor UnaryServerInterceptor) (interface{}, error)
+
+// MethodDesc describes a unary method of a service.
+type MethodDesc struct {
+	MethodName string
+	Handler    MethodHandler
+}
+
+// StreamHandler handles a streaming call.
+type StreamHandler func(srv interface{}, stream ServerStream) error
+
+// StreamDesc describes a streaming method of a service.
+type StreamDesc struct {
+	StreamName    string
+	Handler       StreamHandler
+	ServerStreams bool
+	ClientStreams bool
+}
+
+// ServiceDesc describes a service.
+type ServiceDesc struct {
+	// ServiceName is the full name of the service, e.g. helloworld.Greeter
+	ServiceName string
+	// HandlerType is the pointer to the interface implemented by the service, which is checked on registration.
+	HandlerType interface{}
+	Methods     []MethodDesc
+	Streams     []StreamDesc
+	Metadata    interface{}
+}
+
+// ServerStream is the server side of a streaming call.
+type ServerStream interface {
+	// Context returns the context of the call, which is canceled once the call is finished.
+	Context() context.Context
+	// SendMsg sends a message to the client.
+	SendMsg(m interface{}) error
+	// RecvMsg receives the next message from the client, io.EOF is returned at the end of the stream.
+	RecvMsg(m interface{}) error
+}
+
+// UnaryServerInfo describes a unary call for the interceptors.
+type UnaryServerInfo struct {
+	Server interface{}
+	// FullMethod is the full name of the method, e.g. /helloworld.Greeter/SayHello
+	FullMethod string
+}
+
+// UnaryHandler calls the method of the service.
+type UnaryHandler func(ctx context.Context, req interface{}) (interface{}, error)
+
+// UnaryServerInterceptor intercepts a unary call, it should call handler to continue the call.
+type UnaryServerInterceptor func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error)
+
+// StreamServerInfo describes a streaming call for the interceptors.
+type StreamServerInfo struct {
+	FullMethod     string
+	IsClientStream bool
+	IsServerStream bool
+}
+
+// StreamServerInterceptor intercepts a streaming call, it should call handler to continue the call.
+type StreamServerInterceptor func(srv interface{}, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error
+
+// chainUnaryInterceptors returns the interceptor calling interceptors in order.
+func chainUnaryInterceptors(interceptors []UnaryServerInterceptor) UnaryServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	return func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+		return interceptors[0](ctx, req, info, chainUnaryHandler(interceptors, 0, info, handler))
+	}
+}
+
+func chainUnaryHandler(interceptors []UnaryServerInterceptor, cur int, info *UnaryServerInfo, final UnaryHandler) UnaryHandler {
+	if cur == len(interceptors)-1 {
+		return final
+	}
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return interceptors[cur+1](ctx, req, info, chainUnaryHandler(interceptors, cur+1, info, final))
+	}
+}
+
+// chainStreamInterceptors returns the interceptor calling interceptors in order.
+func chainStreamInterceptors(interceptors []StreamServerInterceptor) StreamServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	return func(srv interface{}, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+		return interceptors[0](srv, ss, info, chainStreamHandler(interceptors, 0, info, handler))
+	}
+}
+
+func chainStreamHandler(interceptors []StreamServerInterceptor, cur int, info *StreamServerInfo, final StreamHandler) StreamHandler {
+	if cur == len(interceptors)-1 {
+		return final
+	}
+	return func(srv interface{}, ss ServerStream) error {
+		return interceptors[cur+1](srv, ss, info, chainStreamHandler(interceptors, cur+1, info, final))
+	}
+}
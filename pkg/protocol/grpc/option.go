@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const defaultMaxRecvMsgSize = 4 * 1024 * 1024
+
+type (
+	options struct {
+		codec              Codec
+		maxRecvMsgSize     int
+		unaryInterceptors  []UnaryServerInterceptor
+		streamInterceptors []StreamServerInterceptor
+		recoveryHandler    func(c context.Context, p interface{}, stack []byte) error
+	}
+
+	Option func(o *options)
+)
+
+func defaultRecoveryHandler(c context.Context, p interface{}, stack []byte) error {
+	hlog.SystemLogger().CtxErrorf(c, "[gRPC Recovery] err=%v\nstack=%s", p, stack)
+	return Errorf(Internal, "panic: %v", p)
+}
+
+func newOptions(opts ...Option) *options {
+	cfg := &options{
+		codec:           protoCodec{},
+		maxRecvMsgSize:  defaultMaxRecvMsgSize,
+		recoveryHandler: defaultRecoveryHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithCodec sets the codec of the messages, which is protobuf by default.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		if codec == nil {
+			panic(fmt.Errorf("grpc: codec is nil"))
+		}
+		o.codec = codec
+	}
+}
+
+// WithMaxRecvMsgSize sets the maximum size of the messages received, 4MB by default.
+// The larger messages are rejected with ResourceExhausted, zero means no limit.
+func WithMaxRecvMsgSize(size int) Option {
+	return func(o *options) {
+		o.maxRecvMsgSize = size
+	}
+}
+
+// WithUnaryInterceptor adds the interceptors of the unary calls, which are called in order.
+func WithUnaryInterceptor(interceptors ...UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptor adds the interceptors of the streaming calls, which are called in order.
+func WithStreamInterceptor(interceptors ...StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithRecoveryHandler sets the handler of the panics recovered from the methods and the interceptors,
+// the returned error is sent as the status of the call.
+// By default the panic is logged and the call fails with Internal.
+//
+// NOTE:
+//
+//	The panics are reported to errreport in any case, as the ones recovered by the engine.
+func WithRecoveryHandler(f func(c context.Context, p interface{}, stack []byte) error) Option {
+	return func(o *options) {
+		o.recoveryHandler = f
+	}
+}
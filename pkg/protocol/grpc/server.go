@@ -0,0 +1,403 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/errreport"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const (
+	headerGrpcStatus         = "Grpc-Status"
+	headerGrpcMessage        = "Grpc-Message"
+	headerGrpcTimeout        = "Grpc-Timeout"
+	headerGrpcEncoding       = "Grpc-Encoding"
+	headerGrpcAcceptEncoding = "Grpc-Accept-Encoding"
+
+	// the encodings of the request messages which can be decompressed
+	acceptEncoding = "identity, gzip"
+)
+
+var errStreamClosed = errors.New("grpc: stream closed")
+
+type requestContextKey struct{}
+
+// RequestContext returns the RequestContext of the call, whose request headers are the metadata
+// sent by the client. It returns nil if ctx is not the context of a call.
+func RequestContext(ctx context.Context) *app.RequestContext {
+	c, _ := ctx.Value(requestContextKey{}).(*app.RequestContext)
+	return c
+}
+
+// Server serves the registered services on the routes of a hertz server.
+type Server struct {
+	opts       *options
+	unaryInt   UnaryServerInterceptor
+	streamInt  StreamServerInterceptor
+	mu         sync.Mutex
+	services   map[string]*serviceInfo
+	registered bool
+}
+
+type serviceInfo struct {
+	desc *ServiceDesc
+	impl interface{}
+}
+
+// NewServer returns a server without services.
+func NewServer(opts ...Option) *Server {
+	o := newOptions(opts...)
+	return &Server{
+		opts:      o,
+		unaryInt:  chainUnaryInterceptors(o.unaryInterceptors),
+		streamInt: chainStreamInterceptors(o.streamInterceptors),
+		services:  make(map[string]*serviceInfo),
+	}
+}
+
+// RegisterService registers the service implemented by impl, which must be called before Register.
+// It panics if impl does not implement desc.HandlerType or the service is registered already.
+func (s *Server) RegisterService(desc *ServiceDesc, impl interface{}) {
+	if impl != nil && desc.HandlerType != nil {
+		ht := reflect.TypeOf(desc.HandlerType).Elem()
+		if st := reflect.TypeOf(impl); !st.Implements(ht) {
+			panic(fmt.Sprintf("grpc: RegisterService found the handler of type %v that does not satisfy %v", st, ht))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.registered {
+		panic("grpc: RegisterService is called after Register")
+	}
+	if _, ok := s.services[desc.ServiceName]; ok {
+		panic(fmt.Sprintf("grpc: RegisterService found duplicate service registration for %q", desc.ServiceName))
+	}
+	s.services[desc.ServiceName] = &serviceInfo{desc: desc, impl: impl}
+}
+
+// Register adds the routes of the methods to r, which is POST /{ServiceName}/{MethodName}.
+// The middlewares of r are applied to the calls.
+func (s *Server) Register(r route.IRoutes) {
+	s.mu.Lock()
+	s.registered = true
+	services := make([]*serviceInfo, 0, len(s.services))
+	for _, srv := range s.services {
+		services = append(services, srv)
+	}
+	s.mu.Unlock()
+
+	for _, srv := range services {
+		for i := range srv.desc.Methods {
+			md := &srv.desc.Methods[i]
+			fullMethod := "/" + srv.desc.ServiceName + "/" + md.MethodName
+			r.POST(fullMethod, s.handler(srv, fullMethod, md, nil))
+		}
+		for i := range srv.desc.Streams {
+			sd := &srv.desc.Streams[i]
+			fullMethod := "/" + srv.desc.ServiceName + "/" + sd.StreamName
+			r.POST(fullMethod, s.handler(srv, fullMethod, nil, sd))
+		}
+	}
+}
+
+// Services returns the descriptors of the registered services, sorted by ServiceName.
+func (s *Server) Services() []*ServiceDesc {
+	s.mu.Lock()
+	descs := make([]*ServiceDesc, 0, len(s.services))
+	for _, srv := range s.services {
+		descs = append(descs, srv.desc)
+	}
+	s.mu.Unlock()
+	sort.Slice(descs, func(i, j int) bool { return descs[i].ServiceName < descs[j].ServiceName })
+	return descs
+}
+
+// Invoke calls the unary method fullMethod, e.g. /helloworld.Greeter/SayHello, in process with the
+// interceptors and the recovery of the server, dec decodes the request message into its argument.
+// It's used to serve the calls of the other forms, e.g. the HTTP/JSON requests transcoded by the gateway.
+//
+// NOTE:
+//
+//	RequestContext returns ctx during the call, whose request headers are the metadata.
+func (s *Server) Invoke(c context.Context, ctx *app.RequestContext, fullMethod string, dec func(interface{}) error) (reply interface{}, err error) {
+	srv, md := s.lookupMethod(fullMethod)
+	if md == nil {
+		return nil, Errorf(Unimplemented, "unknown method %s", fullMethod)
+	}
+	c = context.WithValue(c, requestContextKey{}, ctx)
+	defer s.recover(c, ctx, &err)
+	return md.Handler(srv.impl, c, dec, s.unaryInt)
+}
+
+func (s *Server) lookupMethod(fullMethod string) (*serviceInfo, *MethodDesc) {
+	i := strings.LastIndexByte(fullMethod, '/')
+	if i <= 0 || fullMethod[0] != '/' {
+		return nil, nil
+	}
+	s.mu.Lock()
+	srv := s.services[fullMethod[1:i]]
+	s.mu.Unlock()
+	if srv == nil {
+		return nil, nil
+	}
+	for j := range srv.desc.Methods {
+		if srv.desc.Methods[j].MethodName == fullMethod[i+1:] {
+			return srv, &srv.desc.Methods[j]
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) handler(srv *serviceInfo, fullMethod string, md *MethodDesc, sd *StreamDesc) app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		// see https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md#requests
+		contentType := string(ctx.Request.Header.ContentType())
+		if !strings.HasPrefix(contentType, ContentType) ||
+			(len(contentType) > len(ContentType) && contentType[len(ContentType)] != '+' && contentType[len(ContentType)] != ';') {
+			ctx.AbortWithStatus(consts.StatusUnsupportedMediaType)
+			return
+		}
+
+		ctx.SetContentType(ContentType)
+		ctx.Response.Header.Set(headerGrpcAcceptEncoding, acceptEncoding)
+		ctx.Response.Header.Trailer().SetTrailers([]byte(headerGrpcStatus + ", " + headerGrpcMessage)) //nolint:errcheck
+
+		var compressed bool
+		switch encoding := string(ctx.Request.Header.Peek(headerGrpcEncoding)); encoding {
+		case "", "identity":
+		case "gzip":
+			compressed = true
+		default:
+			s.finishUnary(ctx, nil, Errorf(Unimplemented, "grpc: Decompressor is not installed for grpc-encoding %q", encoding))
+			return
+		}
+
+		cc, cancel, err := callContext(c, ctx)
+		if err != nil {
+			s.finishUnary(ctx, nil, err)
+			return
+		}
+		body := requestBody(ctx)
+		if md != nil {
+			defer cancel()
+			reply, err := s.callUnary(cc, ctx, srv, fullMethod, md, body, compressed)
+			s.finishUnary(ctx, reply, err)
+			return
+		}
+		s.serveStream(cc, cancel, ctx, srv, fullMethod, sd, body, compressed)
+	}
+}
+
+func (s *Server) callUnary(c context.Context, ctx *app.RequestContext, srv *serviceInfo, fullMethod string, md *MethodDesc, body io.Reader, compressed bool) (reply []byte, err error) {
+	defer s.recover(c, ctx, &err)
+
+	received := false
+	dec := func(v interface{}) error {
+		if received {
+			return Errorf(Internal, "grpc: the request message is decoded twice")
+		}
+		data, err := readMessage(body, s.opts.maxRecvMsgSize, compressed)
+		if err == io.EOF {
+			return Errorf(Internal, "grpc: the request message is missing")
+		}
+		if err != nil {
+			return err
+		}
+		received = true
+		if err = s.opts.codec.Unmarshal(data, v); err != nil {
+			return Errorf(Internal, "grpc: failed to unmarshal the request: %v", err)
+		}
+		return nil
+	}
+	resp, err := md.Handler(srv.impl, c, dec, s.unaryInt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.opts.codec.Marshal(resp)
+	if err != nil {
+		return nil, Errorf(Internal, "grpc: failed to marshal the response: %v", err)
+	}
+	return appendMessage(nil, data), nil
+}
+
+// finishUnary writes the response of a unary call, which is chunked to carry the status in trailers.
+func (s *Server) finishUnary(ctx *app.RequestContext, reply []byte, err error) {
+	setStatus(ctx.Response.Header.Trailer(), err)
+	ctx.SetBodyStream(bytes.NewReader(reply), -1)
+}
+
+func (s *Server) serveStream(c context.Context, cancel context.CancelFunc, ctx *app.RequestContext, srv *serviceInfo, fullMethod string, sd *StreamDesc, body io.Reader, compressed bool) {
+	pr, pw := io.Pipe()
+	sb := &streamBody{
+		pr:      pr,
+		trailer: ctx.Response.Header.Trailer(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	ss := &serverStream{
+		ctx:        c,
+		body:       body,
+		w:          pw,
+		codec:      s.opts.codec,
+		maxSize:    s.opts.maxRecvMsgSize,
+		compressed: compressed,
+	}
+	info := &StreamServerInfo{
+		FullMethod:     fullMethod,
+		IsClientStream: sd.ClientStreams,
+		IsServerStream: sd.ServerStreams,
+	}
+
+	// the messages are written while the response is being sent, which begins after the handler returns
+	ctx.SetBodyStream(sb, -1)
+	go func() {
+		defer close(sb.done)
+		var err error
+		func() {
+			defer s.recover(c, ctx, &err)
+			if s.streamInt != nil {
+				err = s.streamInt(srv.impl, ss, info, sd.Handler)
+			} else {
+				err = sd.Handler(srv.impl, ss)
+			}
+		}()
+		sb.err = err
+		pw.Close() //nolint:errcheck
+	}()
+}
+
+func (s *Server) recover(c context.Context, ctx *app.RequestContext, err *error) {
+	if p := recover(); p != nil {
+		stack := debug.Stack()
+		errreport.ReportPanic(c, ctx, p, stack)
+		*err = s.opts.recoveryHandler(c, p, stack)
+	}
+}
+
+// callContext returns the context of the call with the deadline of grpc-timeout.
+func callContext(c context.Context, ctx *app.RequestContext) (context.Context, context.CancelFunc, error) {
+	c = context.WithValue(c, requestContextKey{}, ctx)
+	if v := ctx.Request.Header.Peek(headerGrpcTimeout); len(v) > 0 {
+		timeout, err := parseTimeout(string(v))
+		if err != nil {
+			return nil, nil, Errorf(Internal, "grpc: %v", err)
+		}
+		c, cancel := context.WithTimeout(c, timeout)
+		return c, cancel, nil
+	}
+	c, cancel := context.WithCancel(c)
+	return c, cancel, nil
+}
+
+func requestBody(ctx *app.RequestContext) io.Reader {
+	if ctx.Request.IsBodyStream() {
+		return ctx.RequestBodyStream()
+	}
+	return bytes.NewReader(ctx.Request.Body())
+}
+
+func setStatus(t *protocol.Trailer, err error) {
+	if err == nil {
+		t.Set(headerGrpcStatus, "0") //nolint:errcheck
+		return
+	}
+	e := toError(err)
+	t.Set(headerGrpcStatus, strconv.Itoa(int(e.Code))) //nolint:errcheck
+	if e.Message != "" {
+		t.Set(headerGrpcMessage, encodeGrpcMessage(e.Message)) //nolint:errcheck
+	}
+}
+
+// streamBody is the response body of a streaming call, the status is set to the trailer once
+// all the messages are read. It's closed by the server after the response is sent or failed,
+// which waits for the handler of the call to return, so that the RequestContext is not reused
+// while the handler is running.
+type streamBody struct {
+	pr      *io.PipeReader
+	trailer *protocol.Trailer
+	// err is set by the handler before the pipe is closed
+	err    error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (b *streamBody) Read(p []byte) (n int, err error) {
+	n, err = b.pr.Read(p)
+	if err == io.EOF {
+		setStatus(b.trailer, b.err)
+	}
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	b.cancel()
+	b.pr.CloseWithError(errStreamClosed) //nolint:errcheck
+	<-b.done
+	return nil
+}
+
+type serverStream struct {
+	ctx        context.Context
+	body       io.Reader
+	w          io.Writer
+	codec      Codec
+	maxSize    int
+	compressed bool
+	buf        []byte
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *serverStream) SendMsg(m interface{}) error {
+	data, err := ss.codec.Marshal(m)
+	if err != nil {
+		return Errorf(Internal, "grpc: failed to marshal the response: %v", err)
+	}
+	ss.buf = appendMessage(ss.buf[:0], data)
+	if _, err = ss.w.Write(ss.buf); err != nil {
+		return Errorf(Unavailable, "grpc: failed to send the response: %v", err)
+	}
+	return nil
+}
+
+func (ss *serverStream) RecvMsg(m interface{}) error {
+	data, err := readMessage(ss.body, ss.maxSize, ss.compressed)
+	if err != nil {
+		return err
+	}
+	if err = ss.codec.Unmarshal(data, m); err != nil {
+		return Errorf(Internal, "grpc: failed to unmarshal the request: %v", err)
+	}
+	return nil
+}
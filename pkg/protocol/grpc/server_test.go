@@ -0,0 +1,308 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type echoServer interface {
+	Say(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+	Repeat(req *wrapperspb.StringValue, stream ServerStream) error
+	Join(stream ServerStream) error
+}
+
+type echo struct{}
+
+func (echo) Say(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch req.Value {
+	case "panic":
+		panic("say panic")
+	case "missing":
+		return nil, Errorf(NotFound, "%s: 100%%", req.Value)
+	case "metadata":
+		return wrapperspb.String(RequestContext(ctx).Request.Header.Get("X-Name")), nil
+	}
+	return wrapperspb.String("hello " + req.Value), nil
+}
+
+func (echo) Repeat(req *wrapperspb.StringValue, stream ServerStream) error {
+	if req.Value == "panic" {
+		panic("repeat panic")
+	}
+	for i := 0; i < 3; i++ {
+		if err := stream.SendMsg(req); err != nil {
+			return err
+		}
+	}
+	return Errorf(Aborted, "repeated")
+}
+
+func (echo) Join(stream ServerStream) error {
+	var values []string
+	for {
+		req := &wrapperspb.StringValue{}
+		err := stream.RecvMsg(req)
+		if err == io.EOF {
+			return stream.SendMsg(wrapperspb.String(strings.Join(values, ",")))
+		}
+		if err != nil {
+			return err
+		}
+		values = append(values, req.Value)
+	}
+}
+
+var echoServiceDesc = ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []MethodDesc{{
+		MethodName: "Say",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return srv.(echoServer).Say(ctx, in)
+			}
+			info := &UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Say"}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(echoServer).Say(ctx, req.(*wrapperspb.StringValue))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}},
+	Streams: []StreamDesc{{
+		StreamName: "Repeat",
+		Handler: func(srv interface{}, stream ServerStream) error {
+			in := new(wrapperspb.StringValue)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			return srv.(echoServer).Repeat(in, stream)
+		},
+		ServerStreams: true,
+	}, {
+		StreamName: "Join",
+		Handler: func(srv interface{}, stream ServerStream) error {
+			return srv.(echoServer).Join(stream)
+		},
+		ClientStreams: true,
+	}},
+}
+
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	unary := func(name string) UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	assert.Nil(t, chainUnaryInterceptors(nil))
+	chain := chainUnaryInterceptors([]UnaryServerInterceptor{unary("a"), unary("b"), unary("c")})
+	resp, err := chain(context.Background(), "req", &UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "req", resp)
+	assert.DeepEqual(t, []string{"a", "b", "c", "handler"}, calls)
+
+	calls = nil
+	stream := func(name string) StreamServerInterceptor {
+		return func(srv interface{}, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+			calls = append(calls, name)
+			return handler(srv, ss)
+		}
+	}
+	assert.Nil(t, chainStreamInterceptors(nil))
+	err = chainStreamInterceptors([]StreamServerInterceptor{stream("a"), stream("b")})(nil, nil, &StreamServerInfo{}, func(srv interface{}, ss ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []string{"a", "b", "handler"}, calls)
+}
+
+func TestRegisterService(t *testing.T) {
+	s := NewServer()
+	s.RegisterService(&echoServiceDesc, echo{})
+	assert.Panic(t, func() {
+		s.RegisterService(&echoServiceDesc, echo{})
+	})
+	assert.Panic(t, func() {
+		NewServer().RegisterService(&echoServiceDesc, struct{}{})
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	var intercepted []string
+	s := NewServer(WithUnaryInterceptor(func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+		intercepted = append(intercepted, info.FullMethod)
+		return handler(ctx, req)
+	}))
+	s.RegisterService(&echoServiceDesc, echo{})
+	assert.DeepEqual(t, []*ServiceDesc{&echoServiceDesc}, s.Services())
+
+	ctx := app.NewContext(0)
+	ctx.Request.Header.Set("X-Name", "hertz")
+	invoke := func(fullMethod, value string) (interface{}, error) {
+		return s.Invoke(context.Background(), ctx, fullMethod, func(v interface{}) error {
+			v.(*wrapperspb.StringValue).Value = value
+			return nil
+		})
+	}
+	reply, err := invoke("/test.Echo/Say", "metadata")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hertz", reply.(*wrapperspb.StringValue).Value)
+	_, err = invoke("/test.Echo/Say", "panic")
+	assert.DeepEqual(t, Internal, ErrorCode(err))
+	assert.DeepEqual(t, []string{"/test.Echo/Say", "/test.Echo/Say"}, intercepted)
+
+	for _, fullMethod := range []string{"/test.Echo/Repeat", "/test.Echo/Unknown", "/test.Other/Say", "test.Echo/Say", "/"} {
+		_, err = invoke(fullMethod, "")
+		assert.DeepEqual(t, Unimplemented, ErrorCode(err))
+	}
+}
+
+func TestServe(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		intercepted []string
+	)
+	s := NewServer(
+		WithUnaryInterceptor(func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+			mu.Lock()
+			intercepted = append(intercepted, info.FullMethod)
+			mu.Unlock()
+			return handler(ctx, req)
+		}),
+		WithStreamInterceptor(func(srv interface{}, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+			mu.Lock()
+			intercepted = append(intercepted, info.FullMethod)
+			mu.Unlock()
+			return handler(srv, ss)
+		}),
+	)
+	s.RegisterService(&echoServiceDesc, echo{})
+	h := server.New(server.WithHostPorts("127.0.0.1:9244"), server.WithTransport(standard.NewTransporter))
+	s.Register(h)
+	go h.Spin()
+	time.Sleep(200 * time.Millisecond)
+
+	c, err := client.NewClient()
+	assert.Nil(t, err)
+	call := func(method string, values ...string) (*protocol.Response, []string) {
+		req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+		defer protocol.ReleaseRequest(req)
+		req.SetMethod(consts.MethodPost)
+		req.SetRequestURI("http://127.0.0.1:9244" + method)
+		req.Header.SetContentTypeBytes([]byte(ContentType + "+proto"))
+		req.Header.Set("X-Name", "hertz")
+		var body []byte
+		for _, v := range values {
+			data, err := protoCodec{}.Marshal(wrapperspb.String(v))
+			assert.Nil(t, err)
+			body = appendMessage(body, data)
+		}
+		req.SetBody(body)
+		assert.Nil(t, c.Do(context.Background(), req, resp))
+
+		var replies []string
+		r := strings.NewReader(string(resp.Body()))
+		for {
+			data, err := readMessage(r, 1024, false)
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			reply := &wrapperspb.StringValue{}
+			assert.Nil(t, protoCodec{}.Unmarshal(data, reply))
+			replies = append(replies, reply.Value)
+		}
+		return resp, replies
+	}
+
+	resp, replies := call("/test.Echo/Say", "world")
+	assert.DeepEqual(t, consts.StatusOK, resp.StatusCode())
+	assert.DeepEqual(t, ContentType, string(resp.Header.ContentType()))
+	assert.DeepEqual(t, "0", resp.Header.Trailer().Get(headerGrpcStatus))
+	assert.DeepEqual(t, []string{"hello world"}, replies)
+
+	resp, replies = call("/test.Echo/Say", "metadata")
+	assert.DeepEqual(t, []string{"hertz"}, replies)
+
+	resp, replies = call("/test.Echo/Say", "missing")
+	assert.DeepEqual(t, "5", resp.Header.Trailer().Get(headerGrpcStatus))
+	assert.DeepEqual(t, "missing: 100%25", resp.Header.Trailer().Get(headerGrpcMessage))
+	assert.DeepEqual(t, 0, len(replies))
+
+	resp, _ = call("/test.Echo/Say")
+	assert.DeepEqual(t, "13", resp.Header.Trailer().Get(headerGrpcStatus))
+
+	resp, _ = call("/test.Echo/Say", "panic")
+	assert.DeepEqual(t, "13", resp.Header.Trailer().Get(headerGrpcStatus))
+	assert.DeepEqual(t, "panic: say panic", resp.Header.Trailer().Get(headerGrpcMessage))
+
+	resp, replies = call("/test.Echo/Repeat", "hertz")
+	assert.DeepEqual(t, "10", resp.Header.Trailer().Get(headerGrpcStatus))
+	assert.DeepEqual(t, "repeated", resp.Header.Trailer().Get(headerGrpcMessage))
+	assert.DeepEqual(t, []string{"hertz", "hertz", "hertz"}, replies)
+
+	resp, _ = call("/test.Echo/Repeat", "panic")
+	assert.DeepEqual(t, "13", resp.Header.Trailer().Get(headerGrpcStatus))
+
+	resp, replies = call("/test.Echo/Join", "a", "b", "c")
+	assert.DeepEqual(t, "0", resp.Header.Trailer().Get(headerGrpcStatus))
+	assert.DeepEqual(t, []string{"a,b,c"}, replies)
+
+	// the call without request message fails before the interceptors
+	mu.Lock()
+	assert.DeepEqual(t, []string{
+		"/test.Echo/Say", "/test.Echo/Say", "/test.Echo/Say", "/test.Echo/Say",
+		"/test.Echo/Repeat", "/test.Echo/Repeat", "/test.Echo/Join",
+	}, intercepted)
+	mu.Unlock()
+
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	req.SetMethod(consts.MethodPost)
+	req.SetRequestURI("http://127.0.0.1:9244/test.Echo/Say")
+	req.Header.SetContentTypeBytes([]byte("application/json"))
+	assert.Nil(t, c.Do(context.Background(), req, resp))
+	assert.DeepEqual(t, consts.StatusUnsupportedMediaType, resp.StatusCode())
+
+	req.Header.SetContentTypeBytes([]byte(ContentType))
+	req.Header.Set(headerGrpcEncoding, "snappy")
+	assert.Nil(t, c.Do(context.Background(), req, resp))
+	assert.DeepEqual(t, "12", resp.Header.Trailer().Get(headerGrpcStatus))
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestErrorf(t *testing.T) {
+	assert.Nil(t, Errorf(OK, "ok"))
+	err := Errorf(NotFound, "user %d not found", 1)
+	assert.DeepEqual(t, "rpc error: code = NotFound desc = user 1 not found", err.Error())
+	assert.DeepEqual(t, NotFound, ErrorCode(err))
+	assert.DeepEqual(t, NotFound, ErrorCode(fmt.Errorf("wrapped: %w", err)))
+	assert.DeepEqual(t, OK, ErrorCode(nil))
+	assert.DeepEqual(t, Unknown, ErrorCode(errors.New("unknown")))
+	assert.DeepEqual(t, "Code(100)", Code(100).String())
+}
+
+func TestToError(t *testing.T) {
+	assert.DeepEqual(t, Canceled, toError(context.Canceled).Code)
+	assert.DeepEqual(t, DeadlineExceeded, toError(context.DeadlineExceeded).Code)
+	e := toError(errors.New("failed"))
+	assert.DeepEqual(t, Unknown, e.Code)
+	assert.DeepEqual(t, "failed", e.Message)
+}
+
+func TestEncodeGrpcMessage(t *testing.T) {
+	assert.DeepEqual(t, "hello world", encodeGrpcMessage("hello world"))
+	assert.DeepEqual(t, "100%25", encodeGrpcMessage("100%"))
+	assert.DeepEqual(t, "a%0Ab", encodeGrpcMessage("a\nb"))
+	assert.DeepEqual(t, "%E4%BD%A0", encodeGrpcMessage("你"))
+}
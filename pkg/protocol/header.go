@@ -74,6 +74,8 @@ type RequestHeader struct {
 	// These two fields have been moved close to other bool fields
 	// for reducing RequestHeader object size.
 	cookiesCollected bool
+	strictCookie     bool
+	strictParsing    bool
 
 	contentLength      int
 	contentLengthBytes []byte
@@ -131,6 +133,10 @@ type ResponseHeader struct {
 
 	cookies []argsKV
 
+	// stores an immutable copy of headers as they were received from the
+	// wire.
+	rawHeaders []byte
+
 	headerLength int
 }
 
@@ -235,6 +241,7 @@ func (h *ResponseHeader) CopyTo(dst *ResponseHeader) {
 	dst.server = append(dst.server[:0], h.server...)
 	dst.h = copyArgs(dst.h, h.h)
 	dst.cookies = copyArgs(dst.cookies, h.cookies)
+	dst.rawHeaders = append(dst.rawHeaders[:0], h.rawHeaders...)
 	h.Trailer().CopyTo(dst.Trailer())
 }
 
@@ -363,8 +370,83 @@ func (h *RequestHeader) RawHeaders() []byte {
 	return h.rawHeaders
 }
 
+// VisitAllInOrder calls f for each header in the order they were received.
+//
+// Unlike VisitAll, the headers which are not received from the wire are not visited,
+// and the repeated headers are visited as many times as they were received.
+//
+// f must not retain references to key and/or value after returning.
+// Copy key and/or value contents before returning if you need retaining them.
+func (h *RequestHeader) VisitAllInOrder(f func(key, value []byte)) {
+	visitRawHeaders(&h.bufKV, h.rawHeaders, h.disableNormalizing, f)
+}
+
+func (h *ResponseHeader) SetRawHeaders(r []byte) {
+	h.rawHeaders = r
+}
+
+// RawHeaders returns raw header key/value bytes.
+//
+// This copy is set aside during parsing, so empty slice is returned for all
+// cases where parsing did not happen. Similarly, status line is not stored
+// during parsing and can not be returned.
+func (h *ResponseHeader) RawHeaders() []byte {
+	return h.rawHeaders
+}
+
+// VisitAllInOrder calls f for each header in the order they were received.
+//
+// Unlike VisitAll, the headers which are not received from the wire are not visited,
+// and the repeated headers are visited as many times as they were received.
+//
+// f must not retain references to key and/or value after returning.
+// Copy key and/or value contents before returning if you need retaining them.
+func (h *ResponseHeader) VisitAllInOrder(f func(key, value []byte)) {
+	visitRawHeaders(&h.bufKV, h.rawHeaders, h.disableNormalizing, f)
+}
+
+// visitRawHeaders calls f for each "key: value" line of raw, the multi-line values
+// are joined with spaces, and the key is normalized unless disableNormalizing is set.
+func visitRawHeaders(kv *argsKV, raw []byte, disableNormalizing bool, f func(key, value []byte)) {
+	pending := false
+	for len(raw) > 0 {
+		var line []byte
+		if n := bytes.IndexByte(raw, '\n'); n >= 0 {
+			line, raw = raw[:n], raw[n+1:]
+		} else {
+			line, raw = raw, nil
+		}
+		line = bytes.TrimRight(line, "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			if pending {
+				kv.value = append(kv.value, ' ')
+				kv.value = append(kv.value, bytes.TrimSpace(line)...)
+			}
+			continue
+		}
+		if pending {
+			f(kv.key, kv.value)
+			pending = false
+		}
+		n := bytes.IndexByte(line, ':')
+		if n <= 0 {
+			continue
+		}
+		kv.key = append(kv.key[:0], line[:n]...)
+		utils.NormalizeHeaderKey(kv.key, disableNormalizing)
+		kv.value = append(kv.value[:0], bytes.TrimSpace(line[n+1:])...)
+		pending = true
+	}
+	if pending {
+		f(kv.key, kv.value)
+	}
+}
+
 // AppendBytes appends request header representation to dst and returns
 // the extended dst.
+//
+// The headers other than the special ones, e.g. Content-Type and Content-Length,
+// are written in the order they were first set or added.
 func (h *RequestHeader) AppendBytes(dst []byte) []byte {
 	dst = append(dst, h.Method()...)
 	dst = append(dst, ' ')
@@ -499,7 +581,9 @@ func (h *ResponseHeader) ResetSkipNormalize() {
 
 	h.h = h.h[:0]
 	h.cookies = h.cookies[:0]
+	h.rawHeaders = h.rawHeaders[:0]
 	h.Trailer().ResetSkipNormalize()
+	h.Trailer().resetChunkExtensions()
 	h.mulHeader = h.mulHeader[:0]
 }
 
@@ -666,7 +750,20 @@ func (h *ResponseHeader) DelClientCookieBytes(key []byte) {
 // Do not store references to returned value. Make copies instead.
 func (h *ResponseHeader) Peek(key string) []byte {
 	k := getHeaderKeyBytes(&h.bufKV, key, h.disableNormalizing)
-	return h.peek(k)
+	if v := h.peek(k); v != nil || !h.disableNormalizing {
+		return v
+	}
+	return h.peekFold(k)
+}
+
+// peekFold looks up the header case-insensitively, since the header names
+// are kept in their original casing if normalizing is disabled.
+func (h *ResponseHeader) peekFold(key []byte) []byte {
+	utils.NormalizeHeaderKey(key, false)
+	if v := h.peek(key); v != nil {
+		return v
+	}
+	return peekArgBytesFold(h.h, key)
 }
 
 func (h *ResponseHeader) IsDisableNormalizing() bool {
@@ -849,6 +946,9 @@ func (h *ResponseHeader) SetArgBytes(key, value []byte, noValue bool) {
 
 // AppendBytes appends response header representation to dst and returns
 // the extended dst.
+//
+// The headers other than the special ones, e.g. Content-Type and Content-Length,
+// are written in the order they were first set or added.
 func (h *ResponseHeader) AppendBytes(dst []byte) []byte {
 	statusCode := h.StatusCode()
 	if statusCode < 0 {
@@ -1012,6 +1112,8 @@ func (h *RequestHeader) Len() int {
 // Reset clears request header.
 func (h *RequestHeader) Reset() {
 	h.disableNormalizing = false
+	h.strictCookie = false
+	h.strictParsing = false
 	h.Trailer().disableNormalizing = false
 	h.ResetSkipNormalize()
 }
@@ -1090,6 +1192,7 @@ func (h *RequestHeader) CopyTo(dst *RequestHeader) {
 	dst.h = copyArgs(dst.h, h.h)
 	dst.cookies = copyArgs(dst.cookies, h.cookies)
 	dst.cookiesCollected = h.cookiesCollected
+	dst.strictCookie = h.strictCookie
 	dst.rawHeaders = append(dst.rawHeaders[:0], h.rawHeaders...)
 }
 
@@ -1099,7 +1202,20 @@ func (h *RequestHeader) CopyTo(dst *RequestHeader) {
 // Do not store references to returned value. Make copies instead.
 func (h *RequestHeader) Peek(key string) []byte {
 	k := getHeaderKeyBytes(&h.bufKV, key, h.disableNormalizing)
-	return h.peek(k)
+	if v := h.peek(k); v != nil || !h.disableNormalizing {
+		return v
+	}
+	return h.peekFold(k)
+}
+
+// peekFold looks up the header case-insensitively, since the header names
+// are kept in their original casing if normalizing is disabled.
+func (h *RequestHeader) peekFold(key []byte) []byte {
+	utils.NormalizeHeaderKey(key, false)
+	if v := h.peek(key); v != nil {
+		return v
+	}
+	return peekArgBytesFold(h.h, key)
 }
 
 // SetMultipartFormBoundary sets the following Content-Type:
@@ -1280,6 +1396,49 @@ func (h *RequestHeader) Cookie(key string) []byte {
 	return peekArgStr(h.cookies, key)
 }
 
+// CookieValues returns all the values of the request cookies with the given key
+// in the order they were received.
+//
+// Browsers may send cookies with the same name but different paths or domains,
+// in which case Cookie returns only the first one.
+func (h *RequestHeader) CookieValues(key string) [][]byte {
+	h.collectCookies()
+	return peekAllArgBytesToDst(nil, h.cookies, bytesconv.S2b(key))
+}
+
+// SetStrictCookieParsing sets whether the request cookies are parsed according to RFC 6265 strictly.
+//
+// The cookies are parsed leniently by default, which keeps every cookie-pair as is.
+// If strict is true, the cookie-pairs without '=', with invalid names or values,
+// or larger than 4096 bytes are ignored.
+//
+// It must be called before the cookies are accessed.
+func (h *RequestHeader) SetStrictCookieParsing(strict bool) {
+	h.strictCookie = strict
+}
+
+// IsStrictCookieParsing returns whether the request cookies are parsed according to RFC 6265 strictly.
+func (h *RequestHeader) IsStrictCookieParsing() bool {
+	return h.strictCookie
+}
+
+// SetStrictParsing sets whether the request header is parsed in the strict mode,
+// which is intended for the servers exposed to the clients directly.
+//
+// If strict is true, the request with both Content-Length and Transfer-Encoding,
+// conflicting Content-Length, obs-fold header lines, or lines not terminated by CRLF
+// is rejected instead of being parsed leniently.
+//
+// It must be called before the header is read.
+func (h *RequestHeader) SetStrictParsing(strict bool) {
+	h.strictParsing = strict
+}
+
+// IsStrictParsing returns whether the request header is parsed in the strict mode.
+func (h *RequestHeader) IsStrictParsing() bool {
+	return h.strictParsing
+}
+
 // Cookies returns all the request cookies.
 //
 // It's a good idea to call protocol.ReleaseCookie to reduce GC load after the cookie used.
@@ -1335,7 +1494,7 @@ func (h *RequestHeader) collectCookies() {
 	for i, n := 0, len(h.h); i < n; i++ {
 		kv := &h.h[i]
 		if bytes.Equal(kv.key, bytestr.StrCookie) {
-			h.cookies = parseRequestCookies(h.cookies, kv.value)
+			h.cookies = parseRequestCookies(h.cookies, kv.value, h.strictCookie)
 			tmp := *kv
 			copy(h.h[i:], h.h[i+1:])
 			n--
@@ -1416,6 +1575,7 @@ func (h *RequestHeader) ResetSkipNormalize() {
 	h.rawHeaders = h.rawHeaders[:0]
 	h.mulHeader = h.mulHeader[:0]
 	h.Trailer().ResetSkipNormalize()
+	h.Trailer().resetChunkExtensions()
 }
 
 func peekRawHeader(buf, key []byte) []byte {
@@ -1760,7 +1920,7 @@ func (h *RequestHeader) setSpecialHeader(key, value []byte) bool {
 			return true
 		} else if utils.CaseInsensitiveCompare(bytestr.StrCookie, key) {
 			h.collectCookies()
-			h.cookies = parseRequestCookies(h.cookies, value)
+			h.cookies = parseRequestCookies(h.cookies, value, h.strictCookie)
 			return true
 		}
 	case 't':
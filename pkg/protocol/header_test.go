@@ -656,3 +656,64 @@ func expectResponseHeaderAll(t *testing.T, h *ResponseHeader, key string, expect
 	}
 	assert.DeepEqual(t, h.PeekAll(key), expectedValue)
 }
+
+func TestHeaderDisableNormalizingPeek(t *testing.T) {
+	var reqHeader RequestHeader
+	reqHeader.DisableNormalizing()
+	reqHeader.Set("host", "foobar.com")
+	reqHeader.Set("content-type", "foo/bar")
+	reqHeader.Set("x-CUSTOM-header", "baz")
+	assert.DeepEqual(t, "baz", string(reqHeader.Peek("x-CUSTOM-header")))
+	assert.DeepEqual(t, "baz", string(reqHeader.Peek("X-Custom-Header")))
+	assert.DeepEqual(t, "foo/bar", string(reqHeader.Peek("content-type")))
+	assert.DeepEqual(t, "foobar.com", string(reqHeader.Peek("HOST")))
+	assert.True(t, strings.Contains(reqHeader.String(), "x-CUSTOM-header: baz\r\n"))
+
+	var respHeader ResponseHeader
+	respHeader.DisableNormalizing()
+	respHeader.Set("x-lower-case", "foo")
+	respHeader.Set("content-type", "foo/bar")
+	assert.DeepEqual(t, "foo", string(respHeader.Peek("X-Lower-Case")))
+	assert.DeepEqual(t, "foo/bar", string(respHeader.Peek("Content-Type")))
+	assert.DeepEqual(t, "", string(respHeader.Peek("x-missing")))
+	assert.True(t, strings.Contains(string(respHeader.Header()), "x-lower-case: foo\r\n"))
+}
+
+func TestHeaderAppendBytesOrder(t *testing.T) {
+	var reqHeader RequestHeader
+	reqHeader.Set("X-C", "1")
+	reqHeader.Add("X-A", "2")
+	reqHeader.Set("X-B", "3")
+	reqHeader.Set("X-C", "4")
+	s := string(reqHeader.Header())
+	assert.True(t, strings.Index(s, "X-C: 4") < strings.Index(s, "X-A: 2"))
+	assert.True(t, strings.Index(s, "X-A: 2") < strings.Index(s, "X-B: 3"))
+
+	var respHeader ResponseHeader
+	respHeader.Set("X-C", "1")
+	respHeader.Add("X-A", "2")
+	respHeader.Add("X-B", "3")
+	respHeader.Add("X-A", "4")
+	s = string(respHeader.Header())
+	assert.True(t, strings.Index(s, "X-C: 1") < strings.Index(s, "X-A: 2"))
+	assert.True(t, strings.Index(s, "X-A: 2") < strings.Index(s, "X-B: 3"))
+	assert.True(t, strings.Index(s, "X-B: 3") < strings.Index(s, "X-A: 4"))
+}
+
+func TestRequestHeaderStrictCookieParsing(t *testing.T) {
+	var h RequestHeader
+	h.Set("Cookie", "a=1; b; c d=2; a=3")
+	assert.DeepEqual(t, [][]byte{[]byte("1"), []byte("3")}, h.CookieValues("a"))
+	assert.DeepEqual(t, "2", string(h.Cookie("c d")))
+
+	h.Reset()
+	h.SetStrictCookieParsing(true)
+	h.Set("Cookie", "a=1; b; c d=2; a=3")
+	assert.True(t, h.IsStrictCookieParsing())
+	assert.DeepEqual(t, [][]byte{[]byte("1"), []byte("3")}, h.CookieValues("a"))
+	assert.DeepEqual(t, 0, len(h.Cookie("c d")))
+	assert.DeepEqual(t, "a=1; a=3", string(h.FullCookie()))
+
+	h.Reset()
+	assert.False(t, h.IsStrictCookieParsing())
+}
@@ -56,11 +56,15 @@ import (
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/internal/bytestr"
 	"github.com/cloudwego/hertz/internal/nocopy"
+	"github.com/cloudwego/hertz/pkg/app/client/circuitbreak"
+	"github.com/cloudwego/hertz/pkg/app/client/dns"
 	"github.com/cloudwego/hertz/pkg/app/client/retry"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/leakcheck"
 	"github.com/cloudwego/hertz/pkg/common/timer"
+	"github.com/cloudwego/hertz/pkg/common/tracer/clienttrace"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/network/dialer"
 	"github.com/cloudwego/hertz/pkg/protocol"
@@ -87,6 +91,12 @@ var errConnectionClosed = errs.NewPublic("the server closed connection before re
 type HostClient struct {
 	noCopy nocopy.NoCopy //lint:ignore U1000 until noCopy is used
 
+	// The statistics of conn pool, which are accessed atomically.
+	// Keep them at the top of the struct for 64-bit alignment on 32-bit platforms.
+	waitCount    uint64
+	waitDuration int64
+	dialFailures uint64
+
 	*ClientOptions
 
 	// Comma-separated list of upstream HTTP server host addresses,
@@ -114,11 +124,16 @@ type HostClient struct {
 	addrs     []string
 	addrIdx   uint32
 
+	overrideIdx uint32
+
 	tlsConfigMap     map[string]*tls.Config
 	tlsConfigMapLock sync.Mutex
 
 	pendingRequests int32
 
+	breakerOnce sync.Once
+	breaker     *circuitbreak.Breaker
+
 	connsCleanerRun bool
 
 	closed chan struct{}
@@ -187,6 +202,10 @@ func (c *HostClient) ConnPoolState() config.ConnPoolState {
 	cps := config.ConnPoolState{
 		PoolConnNum:  len(c.conns),
 		TotalConnNum: c.connsCount,
+		InUseConnNum: c.connsCount - len(c.conns),
+		WaitCount:    atomic.LoadUint64(&c.waitCount),
+		WaitDuration: time.Duration(atomic.LoadInt64(&c.waitDuration)),
+		DialFailures: atomic.LoadUint64(&c.dialFailures),
 		Addr:         c.Addr,
 	}
 
@@ -378,15 +397,39 @@ func (c *HostClient) Do(ctx context.Context, req *protocol.Request, resp *protoc
 
 	atomic.AddInt32(&c.pendingRequests, 1)
 
+	breaker := c.circuitBreaker()
+	t := newRequestTracer(clienttrace.ContextClientTrace(ctx))
+
 	for {
-		canIdempotentRetry, err = c.do(req, resp)
-		if err == nil {
+		if breaker != nil {
+			generation, allowErr := breaker.Allow()
+			if allowErr != nil {
+				err = allowErr
+				break
+			}
+			canIdempotentRetry, err = c.do(ctx, req, resp, t)
+			statusCode := 0
+			if err == nil && resp != nil {
+				statusCode = resp.StatusCode()
+			}
+			breaker.Done(generation, statusCode, err)
+		} else {
+			canIdempotentRetry, err = c.do(ctx, req, resp, t)
+		}
+		// the response is retried like an error if its status code is configured
+		retryStatus := err == nil && resp != nil && retryCfg != nil && retryCfg.IsRetryStatus(resp.StatusCode())
+		if err == nil && !retryStatus {
 			break
 		}
 
-		if isDefaultRetryFunc {
-			// canIdempotentRetry only makes sense if the user hasn't provided a custom retry function.
-			if !canIdempotentRetry {
+		if err != nil {
+			if isDefaultRetryFunc {
+				// canIdempotentRetry only makes sense if the user hasn't provided a custom retry function.
+				if !canIdempotentRetry {
+					break
+				}
+			}
+			if retryCfg != nil && !retryCfg.IsRetryError(err) {
 				break
 			}
 		}
@@ -402,17 +445,39 @@ func (c *HostClient) Do(ctx context.Context, req *protocol.Request, resp *protoc
 		}
 
 		wait := retry.Delay(attempts, err, retryCfg)
+		if retryStatus && retryCfg.RespectRetryAfter {
+			if retryAfter, ok := retry.RetryAfter(resp.Header.Peek(consts.HeaderRetryAfter), time.Now()); ok && retryAfter > wait {
+				wait = retryAfter
+				if retryCfg.MaxDelay > 0 && wait > retryCfg.MaxDelay {
+					wait = retryCfg.MaxDelay
+				}
+			}
+		}
 		// Retry after wait time
 		time.Sleep(wait)
 	}
 	atomic.AddInt32(&c.pendingRequests, -1)
 
+	if t != nil && resp != nil {
+		resp.SetTimings(t.result())
+	}
 	if err == io.EOF {
 		err = errConnectionClosed
 	}
 	return err
 }
 
+// circuitBreaker returns the circuit breaker of the host, or nil if it is disabled.
+func (c *HostClient) circuitBreaker() *circuitbreak.Breaker {
+	if c.ClientOptions.CircuitBreakerConfig == nil {
+		return nil
+	}
+	c.breakerOnce.Do(func() {
+		c.breaker = circuitbreak.NewBreaker(c.Addr, c.ClientOptions.CircuitBreakerConfig)
+	})
+	return c.breaker
+}
+
 // PendingRequests returns the current number of requests the client
 // is executing.
 //
@@ -422,14 +487,14 @@ func (c *HostClient) PendingRequests() int {
 	return int(atomic.LoadInt32(&c.pendingRequests))
 }
 
-func (c *HostClient) do(req *protocol.Request, resp *protocol.Response) (bool, error) {
+func (c *HostClient) do(ctx context.Context, req *protocol.Request, resp *protocol.Response, t *requestTracer) (bool, error) {
 	nilResp := false
 	if resp == nil {
 		nilResp = true
 		resp = protocol.AcquireResponse()
 	}
 
-	canIdempotentRetry, err := c.doNonNilReqResp(req, resp)
+	canIdempotentRetry, err := c.doNonNilReqResp(ctx, req, resp, t)
 
 	if nilResp {
 		protocol.ReleaseResponse(resp)
@@ -439,16 +504,22 @@ func (c *HostClient) do(req *protocol.Request, resp *protocol.Response) (bool, e
 }
 
 type requestConfig struct {
-	dialTimeout  time.Duration
-	readTimeout  time.Duration
-	writeTimeout time.Duration
+	dialTimeout           time.Duration
+	readTimeout           time.Duration
+	writeTimeout          time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	maxResponseBodySize   int
 }
 
 func (c *HostClient) preHandleConfig(o *config.RequestOptions) requestConfig {
 	rc := requestConfig{
-		dialTimeout:  c.DialTimeout,
-		readTimeout:  c.ReadTimeout,
-		writeTimeout: c.WriteTimeout,
+		dialTimeout:           c.DialTimeout,
+		readTimeout:           c.ReadTimeout,
+		writeTimeout:          c.WriteTimeout,
+		tlsHandshakeTimeout:   c.TLSHandshakeTimeout,
+		responseHeaderTimeout: c.ResponseHeaderTimeout,
+		maxResponseBodySize:   c.MaxResponseBodySize,
 	}
 	if o.ReadTimeout() > 0 {
 		rc.readTimeout = o.ReadTimeout()
@@ -462,10 +533,38 @@ func (c *HostClient) preHandleConfig(o *config.RequestOptions) requestConfig {
 		rc.dialTimeout = o.DialTimeout()
 	}
 
+	if o.TLSHandshakeTimeout() > 0 {
+		rc.tlsHandshakeTimeout = o.TLSHandshakeTimeout()
+	}
+
+	if o.ResponseHeaderTimeout() > 0 {
+		rc.responseHeaderTimeout = o.ResponseHeaderTimeout()
+	}
+
+	if o.MaxResponseBodySize() > 0 {
+		rc.maxResponseBodySize = o.MaxResponseBodySize()
+	}
+
+	if retryCfg := c.ClientOptions.RetryConfig; retryCfg != nil && retryCfg.AttemptTimeout > 0 {
+		rc.dialTimeout = minTimeout(rc.dialTimeout, retryCfg.AttemptTimeout)
+		rc.readTimeout = minTimeout(rc.readTimeout, retryCfg.AttemptTimeout)
+		rc.writeTimeout = minTimeout(rc.writeTimeout, retryCfg.AttemptTimeout)
+		rc.tlsHandshakeTimeout = minTimeout(rc.tlsHandshakeTimeout, retryCfg.AttemptTimeout)
+		rc.responseHeaderTimeout = minTimeout(rc.responseHeaderTimeout, retryCfg.AttemptTimeout)
+	}
+
 	return rc
 }
 
-func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Response) (bool, error) {
+// minTimeout returns the smaller one of the timeouts, in which the non-positive one means no timeout.
+func minTimeout(a, b time.Duration) time.Duration {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func (c *HostClient) doNonNilReqResp(ctx context.Context, req *protocol.Request, resp *protocol.Response, t *requestTracer) (bool, error) {
 	if req == nil {
 		panic("BUG: req cannot be nil")
 	}
@@ -487,11 +586,14 @@ func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Respo
 	if c.DisablePathNormalizing {
 		req.URI().DisablePathNormalizing = true
 	}
-	cc, err := c.acquireConn(rc.dialTimeout)
+	t.getConn(c.Addr)
+	cc, err := c.acquireConn(ctx, rc.dialTimeout, rc.tlsHandshakeTimeout, t)
 	// if getting connection error, fast fail
 	if err != nil {
 		return false, err
 	}
+	leakcheck.Acquire(leakcheck.KindConn, cc)
+	t.gotConn(!cc.lastUseTime.IsZero())
 	conn := cc.c
 
 	usingProxy := false
@@ -521,7 +623,7 @@ func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Respo
 	if len(userAgentOld) == 0 {
 		req.Header.SetUserAgentBytes(c.getClientName())
 	}
-	zw := c.acquireWriter(conn)
+	zw := t.writer(c.acquireWriter(conn))
 
 	if !usingProxy {
 		err = reqI.Write(req, zw)
@@ -535,6 +637,7 @@ func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Respo
 	if err == nil {
 		err = zw.Flush()
 	}
+	t.wroteRequest(err)
 	// error happened when writing request, close the connection, and try another connection if retry is enabled
 	if err != nil {
 		defer c.closeConn(cc)
@@ -559,14 +662,40 @@ func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Respo
 
 		zr := c.acquireReader(conn)
 		defer zr.Release()
-		if respI.ReadHeaderAndLimitBody(resp, zr, c.MaxResponseBodySize) == nil {
+		if respI.ReadHeaderAndLimitBody(resp, zr, rc.maxResponseBodySize) == nil {
 			return false, nil
 		}
 
 		return true, err
 	}
 
-	if rc.readTimeout > 0 {
+	if rc.responseHeaderTimeout > 0 {
+		// wait for the first byte of the response within its own timeout,
+		// then the read timeout takes effect for the rest.
+		if err = conn.SetReadTimeout(rc.responseHeaderTimeout); err == nil {
+			_, err = conn.Peek(1)
+		}
+	} else if t != nil {
+		// wait for the first byte of the response to be traced
+		if rc.readTimeout > 0 {
+			err = conn.SetReadTimeout(rc.readTimeout)
+		}
+		if err == nil {
+			_, err = conn.Peek(1)
+		}
+	}
+	if err != nil {
+		if errNorm, ok := conn.(network.ErrorNormalization); ok {
+			err = errNorm.ToHertzError(err)
+		}
+		c.closeConn(cc)
+		return true, err
+	}
+	if rc.responseHeaderTimeout > 0 || t != nil {
+		t.gotFirstResponseByte()
+	}
+
+	if rc.readTimeout > 0 || rc.responseHeaderTimeout > 0 {
 		// Set Deadline every time, since golang has fixed the performance issue
 		// See https://github.com/golang/go/issues/15133#issuecomment-271571395 for details
 		if err = conn.SetReadTimeout(rc.readTimeout); err != nil {
@@ -585,9 +714,9 @@ func (c *HostClient) doNonNilReqResp(req *protocol.Request, resp *protocol.Respo
 	zr := c.acquireReader(conn)
 
 	if !c.ResponseBodyStream {
-		err = respI.ReadHeaderAndLimitBody(resp, zr, c.MaxResponseBodySize)
+		err = respI.ReadHeaderAndLimitBody(resp, zr, rc.maxResponseBodySize)
 	} else {
-		err = respI.ReadBodyStream(resp, zr, c.MaxResponseBodySize, func() error {
+		err = respI.ReadBodyStream(resp, zr, rc.maxResponseBodySize, func() error {
 			c.releaseConn(cc)
 			return nil
 		})
@@ -628,7 +757,7 @@ func (c *HostClient) SetMaxConns(newMaxConns int) {
 	c.connsLock.Unlock()
 }
 
-func (c *HostClient) acquireConn(dialTimeout time.Duration) (cc *clientConn, err error) {
+func (c *HostClient) acquireConn(ctx context.Context, dialTimeout, tlsHandshakeTimeout time.Duration, t *requestTracer) (cc *clientConn, err error) {
 	createConn := false
 	startCleaner := false
 
@@ -666,6 +795,11 @@ func (c *HostClient) acquireConn(dialTimeout time.Duration) (cc *clientConn, err
 
 		timeout := c.MaxConnWaitTimeout
 
+		atomic.AddUint64(&c.waitCount, 1)
+		defer func(start time.Time) {
+			atomic.AddInt64(&c.waitDuration, int64(time.Since(start)))
+		}(time.Now())
+
 		// wait for a free connection
 		tc := timer.AcquireTimer(timeout)
 		defer timer.ReleaseTimer(tc)
@@ -698,7 +832,7 @@ func (c *HostClient) acquireConn(dialTimeout time.Duration) (cc *clientConn, err
 		go c.connsCleaner()
 	}
 
-	conn, err := c.dialHostHard(dialTimeout)
+	conn, err := c.dialHostHard(ctx, dialTimeout, tlsHandshakeTimeout, t)
 	if err != nil {
 		c.decConnsCount()
 		return nil, err
@@ -719,7 +853,7 @@ func (c *HostClient) queueForIdle(w *wantConn) {
 }
 
 func (c *HostClient) dialConnFor(w *wantConn) {
-	conn, err := c.dialHostHard(c.DialTimeout)
+	conn, err := c.dialHostHard(context.Background(), c.DialTimeout, c.TLSHandshakeTimeout, nil)
 	if err != nil {
 		w.tryDeliver(nil, err)
 		c.decConnsCount()
@@ -816,6 +950,7 @@ func (c *HostClient) connsCleaner() {
 }
 
 func (c *HostClient) closeConn(cc *clientConn) {
+	leakcheck.Release(cc)
 	c.decConnsCount()
 	cc.c.Close()
 	releaseClientConn(cc)
@@ -867,6 +1002,7 @@ func releaseClientConn(cc *clientConn) {
 var clientConnPool sync.Pool
 
 func (c *HostClient) releaseConn(cc *clientConn) {
+	leakcheck.Release(cc)
 	cc.lastUseTime = time.Now()
 	if c.MaxConnWaitTimeout <= 0 {
 		c.connsLock.Lock()
@@ -948,7 +1084,7 @@ func (c *HostClient) nextAddr() string {
 	return addr
 }
 
-func (c *HostClient) dialHostHard(dialTimeout time.Duration) (conn network.Conn, err error) {
+func (c *HostClient) dialHostHard(ctx context.Context, dialTimeout, tlsHandshakeTimeout time.Duration, t *requestTracer) (conn network.Conn, err error) {
 	// attempt to dial all the available hosts before giving up.
 
 	c.addrsLock.Lock()
@@ -964,18 +1100,55 @@ func (c *HostClient) dialHostHard(dialTimeout time.Duration) (conn network.Conn,
 	for n > 0 {
 		addr := c.nextAddr()
 		tlsConfig := c.cachedTLSConfig(addr)
-		conn, err = dialAddr(addr, c.Dialer, c.DialDualStack, tlsConfig, dialTimeout, c.ProxyURI, c.IsTLS)
-		if err == nil {
-			return conn, nil
+		if path := c.unixSocket(addr); path != "" {
+			conn, err = dialAddr(ctx, "unix", path, c.Dialer, c.DialDualStack, tlsConfig, dialTimeout, tlsHandshakeTimeout, nil, c.IsTLS, t)
+			if err == nil {
+				return conn, nil
+			}
+		} else {
+			for _, target := range c.dialTargets(addr) {
+				if target, err = c.resolveAddr(target, dialTimeout, t); err == nil {
+					conn, err = dialAddr(ctx, "tcp", target, c.Dialer, c.DialDualStack, tlsConfig, dialTimeout, tlsHandshakeTimeout, c.ProxyURI, c.IsTLS, t)
+					if err == nil {
+						return conn, nil
+					}
+				}
+				if time.Since(deadline) >= 0 {
+					break
+				}
+			}
 		}
 		if time.Since(deadline) >= 0 {
 			break
 		}
 		n--
 	}
+	atomic.AddUint64(&c.dialFailures, 1)
 	return nil, err
 }
 
+// resolveAddr resolves the host of addr with c.Resolver, which is skipped if the proxy is used.
+// The TLS config is taken from the unresolved addr, so the server name is kept.
+func (c *HostClient) resolveAddr(addr string, timeout time.Duration, t *requestTracer) (string, error) {
+	if c.Resolver == nil || c.ProxyURI != nil {
+		return addr, nil
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := t.dnsStart(addr)
+	resolved, err := dns.ResolveAddr(ctx, c.Resolver, addr)
+	if err != nil {
+		t.dnsDone(start, nil, err)
+		return "", err
+	}
+	t.dnsDone(start, []string{resolved}, nil)
+	return resolved, nil
+}
+
 func (c *HostClient) cachedTLSConfig(addr string) *tls.Config {
 	var cfgAddr string
 	if c.ProxyURI != nil && bytes.Equal(c.ProxyURI.Scheme(), bytestr.StrHTTPS) {
@@ -996,7 +1169,7 @@ func (c *HostClient) cachedTLSConfig(addr string) *tls.Config {
 	}
 	cfg := c.tlsConfigMap[cfgAddr]
 	if cfg == nil {
-		cfg = newClientTLSConfig(c.TLSConfig, cfgAddr)
+		cfg = newClientTLSConfig(c.tlsConfig(cfgAddr), cfgAddr)
 		c.tlsConfigMap[cfgAddr] = cfg
 	}
 	c.tlsConfigMapLock.Unlock()
@@ -1004,7 +1177,48 @@ func (c *HostClient) cachedTLSConfig(addr string) *tls.Config {
 	return cfg
 }
 
-func dialAddr(addr string, dial network.Dialer, dialDualStack bool, tlsConfig *tls.Config, timeout time.Duration, proxyURI *protocol.URI, isTLS bool) (network.Conn, error) {
+// unixSocket returns the path of the unix domain socket for the host of addr,
+// the proxy is not used for the unix domain socket.
+func (c *HostClient) unixSocket(addr string) string {
+	if c.UnixSocket == nil {
+		return ""
+	}
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	return c.UnixSocket(host)
+}
+
+// dialTargets returns the addresses to dial for addr, the overridden addresses
+// are returned in a round-robin manner.
+func (c *HostClient) dialTargets(addr string) []string {
+	if c.AddrOverride == nil {
+		return []string{addr}
+	}
+	addrs := c.AddrOverride(addr)
+	if len(addrs) == 0 {
+		return []string{addr}
+	}
+	idx := int((atomic.AddUint32(&c.overrideIdx, 1) - 1) % uint32(len(addrs)))
+	return append(append(make([]string, 0, len(addrs)), addrs[idx:]...), addrs[:idx]...)
+}
+
+// tlsConfig returns the TLS config for the host of addr.
+func (c *HostClient) tlsConfig(addr string) *tls.Config {
+	if c.HostTLSConfig != nil {
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		if cfg := c.HostTLSConfig(host); cfg != nil {
+			return cfg
+		}
+	}
+	return c.TLSConfig
+}
+
+func dialAddr(ctx context.Context, dialNetwork, addr string, dial network.Dialer, dialDualStack bool, tlsConfig *tls.Config, timeout, tlsHandshakeTimeout time.Duration, proxyURI *protocol.URI, isTLS bool, t *requestTracer) (network.Conn, error) {
 	var conn network.Conn
 	var err error
 	if dial == nil {
@@ -1012,14 +1226,36 @@ func dialAddr(addr string, dial network.Dialer, dialDualStack bool, tlsConfig *t
 		dial = dialer.DefaultDialer()
 	}
 	dialFunc := dial.DialConnection
+	addTLS := dial.AddTLS
+	if cd, ok := dial.(network.ContextDialer); ok {
+		dialFunc = func(n, addr string, timeout time.Duration, tlsConfig *tls.Config) (network.Conn, error) {
+			dialCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				dialCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			return cd.DialContext(dialCtx, n, addr, tlsConfig)
+		}
+		addTLS = func(conn network.Conn, tlsConfig *tls.Config) (network.Conn, error) {
+			return cd.AddTLSContext(ctx, conn, tlsConfig)
+		}
+	}
 
 	// addr has already been added port, no need to do it here
+	// the handshake is done eagerly to be traced as well
+	handshakeEagerly := tlsConfig != nil && (tlsHandshakeTimeout > 0 || t != nil)
+	connectStart := t.connectStart(dialNetwork, addr)
 	if proxyURI != nil {
 		// use tcp connection first, proxy will AddTLS to it
 		conn, err = dialFunc("tcp", string(proxyURI.Host()), timeout, nil)
+	} else if handshakeEagerly {
+		// use tcp connection first, so that the handshake is done within its own timeout
+		conn, err = dialFunc(dialNetwork, addr, timeout, nil)
 	} else {
-		conn, err = dialFunc("tcp", addr, timeout, tlsConfig)
+		conn, err = dialFunc(dialNetwork, addr, timeout, tlsConfig)
 	}
+	t.connectDone(connectStart, dialNetwork, addr, err)
 
 	if err != nil {
 		return nil, err
@@ -1028,8 +1264,31 @@ func dialAddr(addr string, dial network.Dialer, dialDualStack bool, tlsConfig *t
 		panic("BUG: dial.DialConnection returned (nil, nil)")
 	}
 
+	if handshakeEagerly && tlsHandshakeTimeout > 0 {
+		// the deadline covers the CONNECT of proxy as well
+		if err = conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
 	if proxyURI != nil {
 		conn, err = proxy.SetupProxy(conn, addr, proxyURI, tlsConfig, isTLS, dial)
+	} else if handshakeEagerly {
+		tlsStart := t.tlsHandshakeStart()
+		tlsConn, tlsErr := addTLS(conn, tlsConfig)
+		t.tlsHandshakeDone(tlsStart, tlsErr)
+		if tlsErr != nil {
+			conn.Close()
+		}
+		conn, err = tlsConn, tlsErr
+	}
+
+	if err == nil && handshakeEagerly && tlsHandshakeTimeout > 0 {
+		if err = conn.SetDeadline(time.Time{}); err != nil {
+			conn.Close()
+			conn = nil
+		}
 	}
 
 	// conn must be nil when got error, so doesn't need to close it
@@ -1190,6 +1449,18 @@ type ClientOptions struct {
 	// Optional TLS config.
 	TLSConfig *tls.Config
 
+	// HostTLSConfig returns the TLS config for the host name,
+	// TLSConfig is used if it is nil or returns nil.
+	HostTLSConfig func(host string) *tls.Config
+
+	// UnixSocket returns the path of the unix domain socket dialed for the host name,
+	// or "" to dial the host over TCP.
+	UnixSocket func(host string) string
+
+	// AddrOverride returns the addresses dialed instead of addr,
+	// or nil to dial addr itself.
+	AddrOverride func(addr string) []string
+
 	// Maximum number of connections which may be established to all hosts
 	// listed in Addr.
 	//
@@ -1220,6 +1491,16 @@ type ClientOptions struct {
 	// By default request write timeout is unlimited.
 	WriteTimeout time.Duration
 
+	// Timeout for the TLS handshake of new connections,
+	// which is done along with the first write if not set.
+	TLSHandshakeTimeout time.Duration
+
+	// Timeout for waiting for the response after the request is written,
+	// ReadTimeout takes effect for the rest of the response.
+	//
+	// By default it is limited by ReadTimeout only.
+	ResponseHeaderTimeout time.Duration
+
 	// Maximum response body size.
 	//
 	// The client returns errBodyTooLarge if this limit is greater than 0
@@ -1264,6 +1545,13 @@ type ClientOptions struct {
 	// All configurations related to retry
 	RetryConfig *retry.Config
 
+	// All configurations related to circuit breaker, which is disabled if nil
+	CircuitBreakerConfig *circuitbreak.Config
+
+	// Resolver looks up the addresses of the host before dialing.
+	// The host is resolved by the dialer if nil.
+	Resolver dns.Resolver
+
 	RetryIfFunc client.RetryIfFunc
 
 	// Observe hostclient state
@@ -55,12 +55,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/app/client/circuitbreak"
+	"github.com/cloudwego/hertz/pkg/app/client/dns"
+	"github.com/cloudwego/hertz/pkg/app/client/retry"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
 	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/network/standard"
 	"github.com/cloudwego/hertz/pkg/protocol"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
@@ -266,6 +270,271 @@ func TestReadTimeoutPriority(t *testing.T) {
 	}
 }
 
+func TestAttemptTimeout(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			DialTimeout: time.Second,
+			ReadTimeout: time.Second * 3,
+			RetryConfig: &retry.Config{MaxAttemptTimes: 2, AttemptTimeout: 2 * time.Second},
+		},
+	}
+
+	rc := c.preHandleConfig(config.NewRequestOptions(nil))
+	assert.DeepEqual(t, time.Second, rc.dialTimeout)
+	assert.DeepEqual(t, 2*time.Second, rc.readTimeout)
+	assert.DeepEqual(t, 2*time.Second, rc.writeTimeout)
+
+	rc = c.preHandleConfig(config.NewRequestOptions([]config.RequestOption{config.WithReadTimeout(time.Millisecond)}))
+	assert.DeepEqual(t, time.Millisecond, rc.readTimeout)
+}
+
+func TestFineGrainedTimeoutPriority(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			TLSHandshakeTimeout:   time.Second,
+			ResponseHeaderTimeout: time.Second,
+			RetryConfig:           &retry.Config{MaxAttemptTimes: 1, AttemptTimeout: 3 * time.Second},
+		},
+	}
+
+	rc := c.preHandleConfig(config.NewRequestOptions(nil))
+	assert.DeepEqual(t, time.Second, rc.tlsHandshakeTimeout)
+	assert.DeepEqual(t, time.Second, rc.responseHeaderTimeout)
+
+	rc = c.preHandleConfig(config.NewRequestOptions([]config.RequestOption{
+		config.WithTLSHandshakeTimeout(2 * time.Second),
+		config.WithResponseHeaderTimeout(5 * time.Second),
+	}))
+	assert.DeepEqual(t, 2*time.Second, rc.tlsHandshakeTimeout)
+	assert.DeepEqual(t, 3*time.Second, rc.responseHeaderTimeout)
+}
+
+func TestMaxResponseBodySizePriority(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				return mock.NewConn("HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\n0123456789"), nil
+			}),
+			MaxResponseBodySize: 100,
+		},
+		Addr: "foobar",
+	}
+
+	req := protocol.AcquireRequest()
+	req.SetRequestURI("http://foobar/baz")
+	req.SetConnectionClose()
+	resp := protocol.AcquireResponse()
+	err := c.Do(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "0123456789", string(resp.Body()))
+
+	req.SetOptions(config.WithMaxResponseBodySize(5))
+	err = c.Do(context.Background(), req, resp)
+	assert.True(t, errors.Is(err, errs.ErrBodyTooLarge))
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				return mock.SlowReadDialer(addr)
+			}),
+			ReadTimeout:           time.Second * 3,
+			ResponseHeaderTimeout: time.Second * 3,
+		},
+		Addr: "foobar",
+	}
+
+	req := protocol.AcquireRequest()
+	req.SetRequestURI("http://foobar/baz")
+	req.SetOptions(config.WithResponseHeaderTimeout(100 * time.Millisecond))
+	resp := protocol.AcquireResponse()
+
+	ch := make(chan error, 1)
+	go func() {
+		ch <- c.Do(context.Background(), req, resp)
+	}()
+	select {
+	case <-time.After(time.Second * 2):
+		t.Fatalf("should use responseHeaderTimeout in request options")
+	case err := <-ch:
+		assert.DeepEqual(t, errs.ErrReadTimeout, err)
+	}
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// never responds to the handshake
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = dialAddr(context.Background(), "tcp", ln.Addr().String(), standard.NewDialer(), false, &tls.Config{InsecureSkipVerify: true},
+		time.Second, 100*time.Millisecond, nil, true, nil)
+	assert.True(t, err != nil)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestHostClientHostTLSConfig(t *testing.T) {
+	defaultConfig := &tls.Config{}
+	hostConfig := &tls.Config{InsecureSkipVerify: true}
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			TLSConfig: defaultConfig,
+			HostTLSConfig: func(host string) *tls.Config {
+				if host == "foo.com" {
+					return hostConfig
+				}
+				return nil
+			},
+		},
+		IsTLS: true,
+	}
+
+	cfg := c.cachedTLSConfig("foo.com:443")
+	assert.True(t, cfg.InsecureSkipVerify)
+	assert.DeepEqual(t, "foo.com", cfg.ServerName)
+
+	cfg = c.cachedTLSConfig("bar.com:443")
+	assert.False(t, cfg.InsecureSkipVerify)
+	assert.DeepEqual(t, "bar.com", cfg.ServerName)
+}
+
+func TestHostClientConnPoolStats(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				return mock.NewConn(""), nil
+			}),
+			MaxConns:           1,
+			MaxConnWaitTimeout: 50 * time.Millisecond,
+		},
+		Addr: "foobar",
+	}
+
+	cc, err := c.acquireConn(context.Background(), time.Second, 0, nil)
+	assert.Nil(t, err)
+	_, err = c.acquireConn(context.Background(), time.Second, 0, nil)
+	assert.DeepEqual(t, errs.ErrNoFreeConns, err)
+
+	state := c.ConnPoolState()
+	assert.DeepEqual(t, 1, state.TotalConnNum)
+	assert.DeepEqual(t, 1, state.InUseConnNum)
+	assert.DeepEqual(t, 0, state.PoolConnNum)
+	assert.DeepEqual(t, uint64(1), state.WaitCount)
+	assert.True(t, state.WaitDuration >= 50*time.Millisecond)
+	assert.DeepEqual(t, uint64(0), state.DialFailures)
+
+	c.releaseConn(cc)
+	state = c.ConnPoolState()
+	assert.DeepEqual(t, 0, state.InUseConnNum)
+	assert.DeepEqual(t, 1, state.PoolConnNum)
+
+	c = &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				return nil, errs.ErrDialTimeout
+			}),
+		},
+		Addr: "foobar",
+	}
+	_, err = c.acquireConn(context.Background(), time.Second, 0, nil)
+	assert.True(t, err != nil)
+	state = c.ConnPoolState()
+	assert.DeepEqual(t, uint64(1), state.DialFailures)
+	assert.DeepEqual(t, 0, state.TotalConnNum)
+}
+
+func TestHostClientAddrOverride(t *testing.T) {
+	var dialed []string
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				dialed = append(dialed, addr)
+				return nil, errs.ErrDialTimeout
+			}),
+			AddrOverride: func(addr string) []string {
+				if addr == "foobar:80" {
+					return []string{"127.0.0.1:8080", "127.0.0.2:8080"}
+				}
+				return nil
+			},
+		},
+		Addr: "foobar:80",
+	}
+
+	_, err := c.dialHostHard(context.Background(), time.Second, 0, nil)
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, []string{"127.0.0.1:8080", "127.0.0.2:8080"}, dialed)
+
+	// the next dial starts from the next address
+	dialed = nil
+	_, err = c.dialHostHard(context.Background(), time.Second, 0, nil)
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, []string{"127.0.0.2:8080", "127.0.0.1:8080"}, dialed)
+
+	c.Addr, c.addrs = "baz:80", nil
+	dialed = nil
+	_, err = c.dialHostHard(context.Background(), time.Second, 0, nil)
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, []string{"baz:80"}, dialed)
+}
+
+func TestHostClientCircuitBreaker(t *testing.T) {
+	var dials int32
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				atomic.AddInt32(&dials, 1)
+				return nil, errs.ErrDialTimeout
+			}),
+			CircuitBreakerConfig: &circuitbreak.Config{ConsecutiveFailures: 2, OpenTimeout: time.Second},
+		},
+		Addr: "foobar",
+	}
+
+	req := protocol.AcquireRequest()
+	req.SetRequestURI("http://foobar/baz")
+	resp := protocol.AcquireResponse()
+	for i := 0; i < 2; i++ {
+		err := c.Do(context.Background(), req, resp)
+		assert.DeepEqual(t, errs.ErrDialTimeout, err)
+	}
+	err := c.Do(context.Background(), req, resp)
+	assert.DeepEqual(t, errs.ErrCircuitOpen, err)
+	assert.DeepEqual(t, int32(2), atomic.LoadInt32(&dials))
+	assert.DeepEqual(t, circuitbreak.StateOpen, c.circuitBreaker().State())
+}
+
+func TestHostClientResolver(t *testing.T) {
+	var dialed string
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				dialed = addr
+				return nil, errs.ErrDialTimeout
+			}),
+			Resolver: dns.NewCachingResolver(dns.WithStaticHosts(map[string][]string{"foobar": {"10.0.0.1"}})),
+		},
+		Addr: "foobar:80",
+	}
+
+	req := protocol.AcquireRequest()
+	req.SetRequestURI("http://foobar/baz")
+	err := c.Do(context.Background(), req, nil)
+	assert.DeepEqual(t, errs.ErrDialTimeout, err)
+	assert.DeepEqual(t, "10.0.0.1:80", dialed)
+}
+
 func TestDoNonNilReqResp(t *testing.T) {
 	c := &HostClient{
 		ClientOptions: &ClientOptions{
@@ -280,7 +549,7 @@ func TestDoNonNilReqResp(t *testing.T) {
 	req := protocol.AcquireRequest()
 	resp := protocol.AcquireResponse()
 	req.SetHost("foobar")
-	retry, err := c.doNonNilReqResp(req, resp)
+	retry, err := c.doNonNilReqResp(context.Background(), req, resp, nil)
 	assert.False(t, retry)
 	assert.Nil(t, err)
 	assert.DeepEqual(t, resp.StatusCode(), 400)
@@ -301,7 +570,7 @@ func TestDoNonNilReqResp1(t *testing.T) {
 	req := protocol.AcquireRequest()
 	resp := protocol.AcquireResponse()
 	req.SetHost("foobar")
-	retry, err := c.doNonNilReqResp(req, resp)
+	retry, err := c.doNonNilReqResp(context.Background(), req, resp, nil)
 	assert.True(t, retry)
 	assert.NotNil(t, err)
 }
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ext
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// parseChunkExtensions adds the extensions of the chunk parsed from raw, e.g. `;a=b;c="d"`, to t.
+func parseChunkExtensions(t *protocol.Trailer, chunk int, raw []byte) {
+	if t == nil {
+		return
+	}
+	for len(raw) > 0 {
+		// skip the leading ';'
+		raw = raw[1:]
+		i := 0
+		for i < len(raw) && raw[i] != '=' && raw[i] != ';' {
+			i++
+		}
+		name := strings.TrimSpace(string(raw[:i]))
+		raw = raw[i:]
+
+		var value string
+		if len(raw) > 0 && raw[0] == '=' {
+			raw = bytes.TrimLeft(raw[1:], " \t")
+			quoted := len(raw) > 0 && raw[0] == '"'
+			if quoted {
+				var b []byte
+				j := 1
+				for ; j < len(raw) && raw[j] != '"'; j++ {
+					if raw[j] == '\\' && j+1 < len(raw) {
+						j++
+					}
+					b = append(b, raw[j])
+				}
+				value = string(b)
+				if j < len(raw) {
+					j++
+				}
+				raw = raw[j:]
+			}
+			k := bytes.IndexByte(raw, ';')
+			if k < 0 {
+				k = len(raw)
+			}
+			if !quoted {
+				value = strings.TrimSpace(string(raw[:k]))
+			}
+			raw = raw[k:]
+		}
+		if name != "" {
+			t.AddChunkExtension(chunk, name, value)
+		}
+	}
+}
+
+// appendChunkExtensions appends the extensions of the chunk added to t, quoting the values if necessary.
+func appendChunkExtensions(dst []byte, t *protocol.Trailer, chunk int, last bool) []byte {
+	if t == nil {
+		return dst
+	}
+	for _, e := range t.ChunkExtensions() {
+		if e.Chunk != chunk && !(last && e.Chunk == protocol.LastChunk) {
+			continue
+		}
+		dst = append(dst, ';')
+		dst = append(dst, e.Name...)
+		if e.Value == "" {
+			continue
+		}
+		dst = append(dst, '=')
+		if isToken(e.Value) {
+			dst = append(dst, e.Value...)
+			continue
+		}
+		dst = append(dst, '"')
+		for i := 0; i < len(e.Value); i++ {
+			if c := e.Value[i]; c == '"' || c == '\\' {
+				dst = append(dst, '\\')
+			}
+			dst = append(dst, e.Value[i])
+		}
+		dst = append(dst, '"')
+	}
+	return dst
+}
+
+// isToken reports whether s is a token defined in RFC 9110, section 5.6.2.
+func isToken(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			continue
+		}
+		if strings.IndexByte("!#$%&'*+-.^_`|~", c) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ext
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestParseChunkExtensions(t *testing.T) {
+	var trailer protocol.Trailer
+	parseChunkExtensions(&trailer, 1, []byte(`;a=b ; c = "d \"e\"";f`))
+	assert.DeepEqual(t, []protocol.ChunkExtension{
+		{Chunk: 1, Name: "a", Value: "b"},
+		{Chunk: 1, Name: "c", Value: `d "e"`},
+		{Chunk: 1, Name: "f"},
+	}, trailer.ChunkExtensions())
+
+	// nil trailer is ignored
+	parseChunkExtensions(nil, 0, []byte(";a=b"))
+}
+
+func TestAppendChunkExtensions(t *testing.T) {
+	var trailer protocol.Trailer
+	trailer.AddChunkExtension(0, "a", "b")
+	trailer.AddChunkExtension(1, "c", "")
+	trailer.AddChunkExtension(protocol.LastChunk, "d", `x "y"`)
+
+	assert.DeepEqual(t, ";a=b", string(appendChunkExtensions(nil, &trailer, 0, false)))
+	assert.DeepEqual(t, ";c", string(appendChunkExtensions(nil, &trailer, 1, false)))
+	assert.DeepEqual(t, `;d="x \"y\""`, string(appendChunkExtensions(nil, &trailer, 2, true)))
+	assert.DeepEqual(t, "", string(appendChunkExtensions(nil, nil, 0, true)))
+
+	var parsed protocol.Trailer
+	parseChunkExtensions(&parsed, protocol.LastChunk, appendChunkExtensions(nil, &trailer, 2, true))
+	assert.DeepEqual(t, []protocol.ChunkExtension{{Chunk: protocol.LastChunk, Name: "d", Value: `x "y"`}}, parsed.ChunkExtensions())
+}
@@ -59,6 +59,9 @@ import (
 
 const maxContentLengthInStream = 8 * 1024
 
+// maxPipeSize is the max size of the pieces piped from a NoCopyReader at a time.
+const maxPipeSize = 64 * 1024
+
 var errBrokenChunk = errs.NewPublic("cannot find crlf at the end of chunk").SetMeta("when read body chunk")
 
 func MustPeekBuffered(r network.Reader) []byte {
@@ -106,26 +109,59 @@ func ReadRawHeaders(dst, buf []byte) ([]byte, int, error) {
 }
 
 func WriteBodyChunked(w network.Writer, r io.Reader) error {
+	return WriteBodyChunkedWithExtensions(w, r, nil)
+}
+
+// WriteBodyChunkedWithExtensions is the same as WriteBodyChunked,
+// except that the chunk extensions added to t are written along with the chunks.
+func WriteBodyChunkedWithExtensions(w network.Writer, r io.Reader, t *protocol.Trailer) error {
+	return WriteBodyChunkedWithConfig(w, r, t, nil)
+}
+
+// WriteBodyChunkedWithConfig is the same as WriteBodyChunkedWithExtensions,
+// except that the size and flushing of chunks follow config.
+//
+// NOTE:
+//
+//	If config.BufferChunks is true, the caller should flush w after writing the trailer.
+func WriteBodyChunkedWithConfig(w network.Writer, r io.Reader, t *protocol.Trailer, config *protocol.ChunkedStreamConfig) error {
 	vbuf := utils.CopyBufPool.Get()
 	buf := vbuf.([]byte)
+	flush := true
+	if config != nil {
+		if config.ChunkSize > 0 && config.ChunkSize != len(buf) {
+			buf = make([]byte, config.ChunkSize)
+		}
+		flush = !config.BufferChunks
+	}
+
+	// the chunks piped from a NoCopyReader must be flushed before they are released
+	if nr, ok := r.(NoCopyReader); ok && flush {
+		err := pipeBodyChunked(w, nr, t, len(buf))
+		utils.CopyBufPool.Put(vbuf)
+		return err
+	}
 
 	var err error
 	var n int
-	for {
+	var extensions []byte
+	for chunk := 0; ; chunk++ {
 		n, err = r.Read(buf)
 		if n == 0 {
 			if err == nil {
 				panic("BUG: io.Reader returned 0, nil")
 			}
 			if err == io.EOF {
-				if err = writeChunk(w, buf[:0]); err != nil {
+				extensions = appendChunkExtensions(extensions[:0], t, chunk, true)
+				if err = writeChunk(w, buf[:0], extensions, flush); err != nil {
 					break
 				}
 				err = nil
 			}
 			break
 		}
-		if err = writeChunk(w, buf[:n]); err != nil {
+		extensions = appendChunkExtensions(extensions[:0], t, chunk, false)
+		if err = writeChunk(w, buf[:n], extensions, flush); err != nil {
 			break
 		}
 	}
@@ -141,18 +177,79 @@ func WriteBodyFixedSize(w network.Writer, r io.Reader, size int64) error {
 		}
 	}
 
-	if size > 0 {
-		r = io.LimitReader(r, size)
+	var n int64
+	var err error
+	if nr, ok := r.(NoCopyReader); ok && size > 0 {
+		n, err = pipeBodyFixedSize(w, nr, size)
+	} else {
+		if size > 0 {
+			r = io.LimitReader(r, size)
+		}
+		n, err = utils.CopyZeroAlloc(w, r)
 	}
 
-	n, err := utils.CopyZeroAlloc(w, r)
-
 	if n != size && err == nil {
 		err = fmt.Errorf("copied %d bytes from body stream instead of %d bytes", n, size)
 	}
 	return err
 }
 
+// pipeBodyFixedSize writes size bytes of r to w by the buffers of r,
+// each piece is flushed before its buffer is released.
+func pipeBodyFixedSize(w network.Writer, r NoCopyReader, size int64) (written int64, err error) {
+	for written < size {
+		n := size - written
+		if n > maxPipeSize {
+			n = maxPipeSize
+		}
+		var b []byte
+		b, err = r.NextNoCopy(int(n))
+		if len(b) > 0 {
+			if _, werr := w.WriteBinary(b); werr != nil {
+				return written, werr
+			}
+			if werr := w.Flush(); werr != nil {
+				return written, werr
+			}
+			written += int64(len(b))
+			r.Release() //nolint:errcheck
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return
+		}
+	}
+	return
+}
+
+// pipeBodyChunked is the same as pipeBodyFixedSize, except that the body is written
+// in chunks of at most chunkSize bytes until r returns io.EOF.
+func pipeBodyChunked(w network.Writer, r NoCopyReader, t *protocol.Trailer, chunkSize int) error {
+	var extensions []byte
+	chunk := 0
+	for {
+		b, err := r.NextNoCopy(chunkSize)
+		if len(b) > 0 {
+			extensions = appendChunkExtensions(extensions[:0], t, chunk, false)
+			if werr := writeChunk(w, b, extensions, true); werr != nil {
+				return werr
+			}
+			r.Release() //nolint:errcheck
+			chunk++
+		}
+		if err == io.EOF {
+			extensions = appendChunkExtensions(extensions[:0], t, chunk, true)
+			return writeChunk(w, nil, extensions, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func appendBodyFixedSize(r network.Reader, dst []byte, n int) ([]byte, error) {
 	if n == 0 {
 		return dst, nil
@@ -224,6 +321,12 @@ func readBodyIdentity(r network.Reader, maxBodySize int, dst []byte) ([]byte, er
 }
 
 func ReadBody(r network.Reader, contentLength, maxBodySize int, dst []byte) ([]byte, error) {
+	return ReadBodyWithChunkExtensions(r, contentLength, maxBodySize, dst, nil)
+}
+
+// ReadBodyWithChunkExtensions is the same as ReadBody,
+// except that the extensions of the chunks are added to t if the body is chunked.
+func ReadBodyWithChunkExtensions(r network.Reader, contentLength, maxBodySize int, dst []byte, t *protocol.Trailer) ([]byte, error) {
 	dst = dst[:0]
 	if contentLength >= 0 {
 		if maxBodySize > 0 && contentLength > maxBodySize {
@@ -232,7 +335,7 @@ func ReadBody(r network.Reader, contentLength, maxBodySize int, dst []byte) ([]b
 		return appendBodyFixedSize(r, dst, contentLength)
 	}
 	if contentLength == -1 {
-		return readBodyChunked(r, maxBodySize, dst)
+		return readBodyChunked(r, maxBodySize, dst, t)
 	}
 	return readBodyIdentity(r, maxBodySize, dst)
 }
@@ -245,17 +348,20 @@ func LimitedReaderSize(r io.Reader) int64 {
 	return lr.N
 }
 
-func readBodyChunked(r network.Reader, maxBodySize int, dst []byte) ([]byte, error) {
+func readBodyChunked(r network.Reader, maxBodySize int, dst []byte, t *protocol.Trailer) ([]byte, error) {
 	if len(dst) > 0 {
 		panic("BUG: expected zero-length buffer")
 	}
 
 	strCRLFLen := len(bytestr.StrCRLF)
-	for {
-		chunkSize, err := utils.ParseChunkSize(r)
+	for chunk := 0; ; chunk++ {
+		chunkSize, extensions, err := utils.ParseChunkSizeWithExtensions(r)
 		if err != nil {
 			return dst, err
 		}
+		if len(extensions) > 0 {
+			parseChunkExtensions(t, chunk, extensions)
+		}
 		// If it is the end of chunk, Read CRLF after reading trailer
 		if chunkSize == 0 {
 			return dst, nil
@@ -287,11 +393,14 @@ func round2(n int) int {
 	return 1 << x
 }
 
-func writeChunk(w network.Writer, b []byte) (err error) {
+func writeChunk(w network.Writer, b, extensions []byte, flush bool) (err error) {
 	n := len(b)
 	if err = bytesconv.WriteHexInt(w, n); err != nil {
 		return err
 	}
+	if len(extensions) > 0 {
+		w.WriteBinary(extensions) //nolint:errcheck
+	}
 
 	w.WriteBinary(bytestr.StrCRLF) //nolint:errcheck
 	if _, err = w.WriteBinary(b); err != nil {
@@ -303,7 +412,9 @@ func writeChunk(w network.Writer, b []byte) (err error) {
 		w.WriteBinary(bytestr.StrCRLF) //nolint:errcheck
 	}
 
-	err = w.Flush()
+	if flush {
+		err = w.Flush()
+	}
 	return
 }
 
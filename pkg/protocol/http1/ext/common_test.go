@@ -21,9 +21,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/netpoll"
 )
 
 func Test_stripSpace(t *testing.T) {
@@ -95,3 +98,93 @@ func TestReadTrailer1(t *testing.T) {
 		}
 	}
 }
+
+type flushCountWriter struct {
+	network.Writer
+	flushes int
+}
+
+func (w *flushCountWriter) Flush() error {
+	w.flushes++
+	return w.Writer.Flush()
+}
+
+func TestWriteBodyChunkedWithConfig(t *testing.T) {
+	body := strings.Repeat("a", 10)
+
+	// every chunk is flushed by default
+	var b bytes.Buffer
+	w := &flushCountWriter{Writer: netpoll.NewWriter(&b)}
+	err := WriteBodyChunkedWithConfig(w, strings.NewReader(body), nil, &protocol.ChunkedStreamConfig{ChunkSize: 4})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "4\r\naaaa\r\n4\r\naaaa\r\n2\r\naa\r\n0\r\n", b.String())
+	assert.DeepEqual(t, 4, w.flushes)
+
+	b.Reset()
+	w = &flushCountWriter{Writer: netpoll.NewWriter(&b)}
+	err = WriteBodyChunkedWithConfig(w, strings.NewReader(body), nil, &protocol.ChunkedStreamConfig{ChunkSize: 4, BufferChunks: true})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 0, w.flushes)
+	assert.Nil(t, w.Flush())
+	assert.DeepEqual(t, "4\r\naaaa\r\n4\r\naaaa\r\n2\r\naa\r\n0\r\n", b.String())
+
+	// the pooled buffer is used without config
+	b.Reset()
+	w = &flushCountWriter{Writer: netpoll.NewWriter(&b)}
+	err = WriteBodyChunkedWithConfig(w, strings.NewReader(body), nil, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "a\r\naaaaaaaaaa\r\n0\r\n", b.String())
+	assert.DeepEqual(t, 2, w.flushes)
+}
+
+type releaseCountReader struct {
+	network.Reader
+	releases int
+}
+
+func (r *releaseCountReader) Release() error {
+	r.releases++
+	return r.Reader.Release()
+}
+
+func TestWriteBodyNoCopy(t *testing.T) {
+	// the prefetched bytes are followed by the ones on the wire
+	body := strings.Repeat("a", 10) + strings.Repeat("b", 20)
+	prefetched := bytebufferpool.Get()
+	prefetched.B = append(prefetched.B, body[:10]...)
+	zr := &releaseCountReader{Reader: mock.NewZeroCopyReader(body[10:])}
+	bs := AcquireBodyStream(prefetched, zr, nil, len(body))
+	_, ok := bs.(NoCopyReader)
+	assert.True(t, ok)
+	var b bytes.Buffer
+	w := &flushCountWriter{Writer: netpoll.NewWriter(&b)}
+	err := WriteBodyFixedSize(w, bs, int64(len(body)))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, body, b.String())
+	assert.DeepEqual(t, 2, w.flushes)
+	assert.DeepEqual(t, 2, zr.releases)
+	assert.Nil(t, ReleaseBodyStream(bs))
+
+	// the chunks are piped one by one
+	zr = &releaseCountReader{Reader: mock.NewZeroCopyReader("4\r\naaaa\r\n2\r\nbb\r\n0\r\nHertz: test\r\n\r\n")}
+	trailer := &protocol.Trailer{}
+	trailer.SetTrailers([]byte("Hertz")) //nolint:errcheck
+	bs = AcquireBodyStream(bytebufferpool.Get(), zr, trailer, -1)
+	b.Reset()
+	w = &flushCountWriter{Writer: netpoll.NewWriter(&b)}
+	err = WriteBodyChunkedWithConfig(w, bs, nil, nil)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "4\r\naaaa\r\n2\r\nbb\r\n0\r\n", b.String())
+	assert.DeepEqual(t, 3, w.flushes)
+	assert.DeepEqual(t, 2, zr.releases)
+	assert.DeepEqual(t, "test", string(trailer.Peek("Hertz")))
+	assert.Nil(t, ReleaseBodyStream(bs))
+
+	// the truncated body is reported
+	bs = AcquireBodyStream(bytebufferpool.Get(), mock.NewZeroCopyReader("aaa"), nil, 5)
+	b.Reset()
+	err = WriteBodyFixedSize(netpoll.NewWriter(&b), bs, 5)
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "aaa", b.String())
+	ReleaseBodyStream(bs) //nolint:errcheck
+}
@@ -48,6 +48,7 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/leakcheck"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/protocol"
@@ -66,13 +67,36 @@ var (
 // Deprecated: Use github.com/cloudwego/hertz/pkg/protocol.NoBody instead.
 var NoBody = protocol.NoBody
 
+// NoCopyReader is implemented by the body streams read from a connection, which hand out
+// the body in the buffers of the connection instead of copying it. WriteBodyFixedSize and
+// WriteBodyChunkedWithConfig pipe such a stream to the writer without intermediate copies,
+// so that a proxy can pass the streamed request or response body through, e.g.
+//
+//	upstreamReq.SetBodyStream(ctx.RequestBodyStream(), ctx.Request.Header.ContentLength())
+//
+// NOTE:
+//
+//	The bytes returned by NextNoCopy are only valid until Release is called.
+type NoCopyReader interface {
+	io.Reader
+
+	// NextNoCopy returns and consumes the next bytes of the body, at most n bytes.
+	// It returns io.EOF along with the last bytes, or after them.
+	NextNoCopy(n int) ([]byte, error)
+
+	// Release releases the buffers of the bytes returned by NextNoCopy.
+	Release() error
+}
+
 type bodyStream struct {
+	prefetched      []byte
 	prefetchedBytes *bytes.Reader
 	reader          network.Reader
 	trailer         *protocol.Trailer
 	offset          int
 	contentLength   int
 	chunkLeft       int
+	chunkIndex      int
 }
 
 func ReadBodyWithStreaming(zr network.Reader, contentLength, maxBodySize int, dst []byte) (b []byte, err error) {
@@ -110,10 +134,12 @@ func ReadBodyWithStreaming(zr network.Reader, contentLength, maxBodySize int, ds
 
 func AcquireBodyStream(b *bytebufferpool.ByteBuffer, r network.Reader, t *protocol.Trailer, contentLength int) io.Reader {
 	rs := bodyStreamPool.Get().(*bodyStream)
+	rs.prefetched = b.B
 	rs.prefetchedBytes = bytes.NewReader(b.B)
 	rs.reader = r
 	rs.contentLength = contentLength
 	rs.trailer = t
+	leakcheck.Acquire(leakcheck.KindBodyStream, rs)
 
 	return rs
 }
@@ -126,10 +152,14 @@ func (rs *bodyStream) Read(p []byte) (int, error) {
 	}()
 	if rs.contentLength == -1 {
 		if rs.chunkLeft == 0 {
-			chunkSize, err := utils.ParseChunkSize(rs.reader)
+			chunkSize, extensions, err := utils.ParseChunkSizeWithExtensions(rs.reader)
 			if err != nil {
 				return 0, err
 			}
+			if len(extensions) > 0 {
+				parseChunkExtensions(rs.trailer, rs.chunkIndex, extensions)
+			}
+			rs.chunkIndex++
 			if chunkSize == 0 {
 				err = ReadTrailer(rs.trailer, rs.reader)
 				if err == nil {
@@ -188,12 +218,13 @@ func (rs *bodyStream) Read(p []byte) (int, error) {
 	m := len(p) - n
 	remain := rs.contentLength - rs.offset
 
-	if m > remain {
+	// the body of unknown length (-2) is read until the connection is closed
+	if rs.contentLength >= 0 && m > remain {
 		m = remain
 	}
 
 	if conn, ok := rs.reader.(io.Reader); ok {
-		m, err = conn.Read(p[n:])
+		m, err = conn.Read(p[n : n+m])
 	} else {
 		var tmp []byte
 		tmp, err = rs.reader.Peek(m)
@@ -220,6 +251,105 @@ func (rs *bodyStream) Read(p []byte) (int, error) {
 	return n, err
 }
 
+func (rs *bodyStream) NextNoCopy(n int) ([]byte, error) {
+	if rs.contentLength == -1 {
+		if rs.chunkLeft == 0 {
+			chunkSize, extensions, err := utils.ParseChunkSizeWithExtensions(rs.reader)
+			if err != nil {
+				return nil, err
+			}
+			if len(extensions) > 0 {
+				parseChunkExtensions(rs.trailer, rs.chunkIndex, extensions)
+			}
+			rs.chunkIndex++
+			if chunkSize == 0 {
+				err = ReadTrailer(rs.trailer, rs.reader)
+				if err == nil {
+					err = io.EOF
+				}
+				return nil, err
+			}
+			rs.chunkLeft = chunkSize
+		}
+		if n > rs.chunkLeft {
+			n = rs.chunkLeft
+		}
+		b, err := rs.next(n)
+		rs.chunkLeft -= len(b)
+		if err == nil && rs.chunkLeft == 0 {
+			err = utils.SkipCRLF(rs.reader)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return b, err
+	}
+	if rs.offset == rs.contentLength {
+		return nil, io.EOF
+	}
+
+	var b []byte
+	var err error
+	if left := rs.prefetchedBytes.Len(); left > 0 {
+		// the pre-read buffer is handed out as is
+		if n > left {
+			n = left
+		}
+		pos := len(rs.prefetched) - left
+		b = rs.prefetched[pos : pos+n]
+		rs.prefetchedBytes.Seek(int64(n), io.SeekCurrent) //nolint:errcheck
+	} else {
+		// the body of unknown length (-2) is read until the connection is closed
+		if remain := rs.contentLength - rs.offset; rs.contentLength >= 0 && n > remain {
+			n = remain
+		}
+		b, err = rs.next(n)
+	}
+	rs.offset += len(b)
+
+	if err != nil {
+		// the data on stream may be incomplete
+		if err == io.EOF {
+			if rs.offset != rs.contentLength && rs.contentLength != -2 {
+				err = io.ErrUnexpectedEOF
+			}
+			// ensure that skipRest works fine
+			rs.offset = rs.contentLength
+		}
+		return b, err
+	}
+	if rs.offset == rs.contentLength {
+		err = io.EOF
+	}
+	return b, err
+}
+
+// next returns and skips at most n bytes of the reader. It only waits for the first byte,
+// and the bytes returned are the buffered ones, which are likely held by a single buffer node.
+func (rs *bodyStream) next(n int) ([]byte, error) {
+	if rs.reader.Len() == 0 {
+		if _, err := rs.reader.Peek(1); err != nil {
+			return nil, err
+		}
+	}
+	if l := rs.reader.Len(); n > l {
+		n = l
+	}
+	b, err := rs.reader.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+	rs.reader.Skip(n) //nolint:errcheck
+	return b, nil
+}
+
+func (rs *bodyStream) Release() error {
+	if rs.reader == nil {
+		return nil
+	}
+	return rs.reader.Release()
+}
+
 func (rs *bodyStream) skipRest() error {
 	// The body length doesn't exceed the maxContentLengthInStream or
 	// the bodyStream has been skip rest
@@ -265,9 +395,12 @@ func (rs *bodyStream) skipRest() error {
 // NOTE: Be careful to use this method unless you know what it's for.
 func ReleaseBodyStream(requestReader io.Reader) (err error) {
 	if rs, ok := requestReader.(*bodyStream); ok {
+		leakcheck.Release(rs)
 		err = rs.skipRest()
+		rs.prefetched = nil
 		rs.prefetchedBytes = nil
 		rs.offset = 0
+		rs.chunkIndex = 0
 		rs.reader = nil
 		rs.trailer = nil
 		bodyStreamPool.Put(rs)
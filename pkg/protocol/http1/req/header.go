@@ -48,6 +48,7 @@ import (
 	"io"
 
 	"github.com/cloudwego/hertz/internal/bytestr"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
@@ -56,7 +57,16 @@ import (
 	"github.com/cloudwego/hertz/pkg/protocol/http1/ext"
 )
 
-var errEOFReadHeader = errs.NewPublic("error when reading request headers: EOF")
+var (
+	errEOFReadHeader  = errs.NewPublic("error when reading request headers: EOF")
+	errHeaderTooLarge = errs.New(errs.ErrHeaderTooLarge, errs.ErrorTypePublic, nil)
+
+	errBareLF                            = errs.NewPublic("line is not terminated by CRLF")
+	errBareCR                            = errs.NewPublic("bare CR in line")
+	errObsFold                           = errs.NewPublic("obsolete line folding is not allowed")
+	errConflictingContentLength          = errs.NewPublic("conflicting Content-Length")
+	errContentLengthWithTransferEncoding = errs.NewPublic("both Content-Length and Transfer-Encoding are present")
+)
 
 // Write writes request header to w.
 func WriteHeader(h *protocol.RequestHeader, w network.Writer) error {
@@ -66,9 +76,28 @@ func WriteHeader(h *protocol.RequestHeader, w network.Writer) error {
 }
 
 func ReadHeader(h *protocol.RequestHeader, r network.Reader) error {
+	return ReadHeaderWithLimit(h, r, 0)
+}
+
+// ReadHeaderWithLimit reads request header from r like ReadHeader,
+// and returns ErrHeaderTooLarge if the size of request line and headers exceeds maxHeaderBytes.
+//
+// maxHeaderBytes <= 0 means no limitation.
+func ReadHeaderWithLimit(h *protocol.RequestHeader, r network.Reader, maxHeaderBytes int) error {
+	return ReadHeaderWithHook(h, r, maxHeaderBytes, nil)
+}
+
+// ReadHeaderWithHook reads request header from r like ReadHeaderWithLimit,
+// and calls hook with the raw request line and headers once they are parsed successfully.
+//
+// NOTE:
+//
+//	raw refers to the buffer of r, which is reused after hook returns,
+//	so it must be copied if it is used after that.
+func ReadHeaderWithHook(h *protocol.RequestHeader, r network.Reader, maxHeaderBytes int, hook func(raw []byte)) error {
 	n := 1
 	for {
-		err := tryRead(h, r, n)
+		err := tryRead(h, r, n, maxHeaderBytes, hook)
 		if err == nil {
 			return nil
 		}
@@ -76,6 +105,10 @@ func ReadHeader(h *protocol.RequestHeader, r network.Reader) error {
 			h.ResetSkipNormalize()
 			return err
 		}
+		if maxHeaderBytes > 0 && n > maxHeaderBytes {
+			h.ResetSkipNormalize()
+			return errHeaderTooLarge
+		}
 
 		// No more data available on the wire, try block peek
 		if n == r.Len() {
@@ -86,7 +119,7 @@ func ReadHeader(h *protocol.RequestHeader, r network.Reader) error {
 	}
 }
 
-func tryRead(h *protocol.RequestHeader, r network.Reader, n int) error {
+func tryRead(h *protocol.RequestHeader, r network.Reader, n, maxHeaderBytes int, hook func(raw []byte)) error {
 	h.ResetSkipNormalize()
 	b, err := r.Peek(n)
 	if len(b) == 0 {
@@ -103,31 +136,113 @@ func tryRead(h *protocol.RequestHeader, r network.Reader, n int) error {
 		return errEOFReadHeader
 	}
 	b = ext.MustPeekBuffered(r)
-	headersLen, errParse := parse(h, b)
+	requestLineLen, headersLen, errParse := parseRequestLineAndHeaders(h, b)
 	if errParse != nil {
 		return ext.HeaderError("request", err, errParse, b)
 	}
+	headersLen += requestLineLen
+	if maxHeaderBytes > 0 && headersLen > maxHeaderBytes {
+		return errHeaderTooLarge
+	}
+	if hook != nil {
+		// the header keys and multi-line values are normalized in place while parsing,
+		// so the raw headers are taken from the copy kept by h.
+		rawHeaders := h.RawHeaders()
+		if len(rawHeaders) == 0 {
+			rawHeaders = b[requestLineLen:headersLen]
+		}
+		raw := bytebufferpool.Get()
+		raw.B = append(raw.B, b[:requestLineLen]...)
+		raw.B = append(raw.B, rawHeaders...)
+		hook(raw.B)
+		bytebufferpool.Put(raw)
+	}
 	ext.MustDiscard(r, headersLen)
 	return nil
 }
 
 func parse(h *protocol.RequestHeader, buf []byte) (int, error) {
-	m, err := parseFirstLine(h, buf)
+	m, n, err := parseRequestLineAndHeaders(h, buf)
 	if err != nil {
 		return 0, err
 	}
+	return m + n, nil
+}
+
+// parseRequestLineAndHeaders returns the length of the request line and headers respectively.
+func parseRequestLineAndHeaders(h *protocol.RequestHeader, buf []byte) (int, int, error) {
+	m, err := parseFirstLine(h, buf)
+	if err != nil {
+		return 0, 0, err
+	}
 
-	rawHeaders, _, err := ext.ReadRawHeaders(h.RawHeaders()[:0], buf[m:])
+	rawHeaders, rawLen, err := ext.ReadRawHeaders(h.RawHeaders()[:0], buf[m:])
 	h.SetRawHeaders(rawHeaders)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
+	}
+	// check before parsing headers since the multi-line values are normalized in place
+	if h.IsStrictParsing() {
+		if err = checkStrict(buf[:m+rawLen]); err != nil {
+			return 0, 0, err
+		}
 	}
 	var n int
 	n, err = parseHeaders(h, buf[m:])
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	return m + n, nil
+	return m, n, nil
+}
+
+// checkStrict checks the raw request line and headers in b against the anomalies
+// which may be interpreted differently by the proxies and lead to request smuggling.
+func checkStrict(b []byte) error {
+	requestLine := true
+	var contentLength []byte
+	hasContentLength, hasTransferEncoding := false, false
+	for len(b) > 0 {
+		n := bytes.IndexByte(b, '\n')
+		if n <= 0 || b[n-1] != '\r' {
+			return errBareLF
+		}
+		line := b[:n-1]
+		b = b[n+1:]
+		if bytes.IndexByte(line, '\r') >= 0 {
+			return errBareCR
+		}
+		if len(line) == 0 {
+			if requestLine {
+				// empty lines before the request line are ignored
+				continue
+			}
+			break
+		}
+		if requestLine {
+			requestLine = false
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return errObsFold
+		}
+		i := bytes.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		key, value := line[:i], bytes.Trim(line[i+1:], " \t")
+		if utils.CaseInsensitiveCompare(key, bytestr.StrContentLength) {
+			if hasContentLength && !bytes.Equal(contentLength, value) {
+				return errConflictingContentLength
+			}
+			hasContentLength, contentLength = true, value
+		} else if utils.CaseInsensitiveCompare(key, bytestr.StrTransferEncoding) {
+			hasTransferEncoding = true
+		}
+	}
+	if hasContentLength && hasTransferEncoding {
+		return errContentLengthWithTransferEncoding
+	}
+	return nil
 }
 
 func parseFirstLine(h *protocol.RequestHeader, buf []byte) (int, error) {
@@ -44,11 +44,13 @@ package req
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"testing"
 
+	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
 	"github.com/cloudwego/hertz/pkg/protocol"
@@ -412,3 +414,111 @@ func TestRequestHeader_PeekIfExists(t *testing.T) {
 	assert.DeepEqual(t, []byte{}, rh.Peek("exists"))
 	assert.DeepEqual(t, []byte(nil), rh.Peek("non-exists"))
 }
+
+func TestRequestHeaderVisitAllInOrder(t *testing.T) {
+	s := "GET / HTTP/1.1\r\n" +
+		"X-B: 1\r\n" +
+		"host: foobar.com\r\n" +
+		"Content-Type: foo/bar;\r\n another/newline\r\n" +
+		"x-a: 2\r\n" +
+		"X-B: 3\r\n" +
+		"\r\n"
+	var h protocol.RequestHeader
+	_, err := parse(&h, []byte(s))
+	assert.Nil(t, err)
+
+	var got []string
+	h.VisitAllInOrder(func(key, value []byte) {
+		got = append(got, string(key)+"="+string(value))
+	})
+	assert.DeepEqual(t, []string{"X-B=1", "Host=foobar.com", "Content-Type=foo/bar; another/newline", "X-A=2", "X-B=3"}, got)
+
+	h.Reset()
+	h.DisableNormalizing()
+	_, err = parse(&h, []byte(s))
+	assert.Nil(t, err)
+	got = got[:0]
+	h.VisitAllInOrder(func(key, value []byte) {
+		got = append(got, string(key))
+	})
+	assert.DeepEqual(t, []string{"X-B", "host", "Content-Type", "x-a", "X-B"}, got)
+}
+
+func TestRequestHeaderReadWithLimit(t *testing.T) {
+	s := "GET /foo/bar HTTP/1.1\r\nHost: foobar.com\r\nX-Foo: " + strings.Repeat("a", 100) + "\r\n\r\n"
+
+	var h protocol.RequestHeader
+	err := ReadHeaderWithLimit(&h, mock.NewZeroCopyReader(s), len(s))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, strings.Repeat("a", 100), string(h.Peek("X-Foo")))
+
+	h.Reset()
+	err = ReadHeaderWithLimit(&h, mock.NewZeroCopyReader(s), len(s)-1)
+	assert.True(t, errors.Is(err, errs.ErrHeaderTooLarge))
+
+	// the header is incomplete within the limitation
+	h.Reset()
+	err = ReadHeaderWithLimit(&h, mock.NewZeroCopyReader(s[:len(s)-2]+strings.Repeat("b", 100)), 64)
+	assert.True(t, errors.Is(err, errs.ErrHeaderTooLarge))
+}
+
+func TestRequestHeaderReadWithHook(t *testing.T) {
+	s := "GET /foo HTTP/1.1\r\nHost: foobar.com\r\nx-foo:  a\r\n b\r\n\r\nbody"
+
+	var h protocol.RequestHeader
+	var raw string
+	err := ReadHeaderWithHook(&h, mock.NewZeroCopyReader(s), 0, func(b []byte) {
+		raw = string(b)
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "GET /foo HTTP/1.1\r\nHost: foobar.com\r\nx-foo:  a\r\n b\r\n\r\n", raw)
+	assert.DeepEqual(t, "a b", string(h.Peek("X-Foo")))
+
+	// no headers
+	err = ReadHeaderWithHook(&h, mock.NewZeroCopyReader("GET /foo HTTP/1.1\r\n\r\n"), 0, func(b []byte) {
+		raw = string(b)
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "GET /foo HTTP/1.1\r\n\r\n", raw)
+
+	// the hook is not called for the invalid header
+	raw = ""
+	h.Reset()
+	err = ReadHeaderWithHook(&h, mock.NewZeroCopyReader("GET /foo HTTP/1.1\r\nX Foo: a\r\n\r\n"), 0, func(b []byte) {
+		raw = string(b)
+	})
+	assert.True(t, err != nil)
+	assert.DeepEqual(t, "", raw)
+}
+
+func TestRequestHeaderReadStrict(t *testing.T) {
+	read := func(s string, strict bool) (*protocol.RequestHeader, error) {
+		var h protocol.RequestHeader
+		h.SetStrictParsing(strict)
+		return &h, ReadHeader(&h, mock.NewZeroCopyReader(s))
+	}
+
+	h, err := read("\r\nGET /foo HTTP/1.1\r\nHost: foobar.com\r\nContent-Length: 5\r\nContent-Length: 5\r\n\r\n", true)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, 5, h.ContentLength())
+	assert.True(t, h.IsStrictParsing())
+
+	for _, s := range []string{
+		"POST /foo HTTP/1.1\r\nHost: foobar.com\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n",
+		"POST /foo HTTP/1.1\r\nHost: foobar.com\r\nTransfer-Encoding: chunked\r\nContent-Length: 5\r\n\r\n",
+		"POST /foo HTTP/1.1\r\nHost: foobar.com\r\nContent-Length: 5\r\nContent-Length: 6\r\n\r\n",
+		"GET /foo HTTP/1.1\r\nHost: foobar.com\r\nX-Foo: a\r\n b\r\n\r\n",
+		"GET /foo HTTP/1.1\nHost: foobar.com\r\n\r\n",
+		"GET /foo HTTP/1.1\r\nHost: foobar.com\n\r\n",
+		"GET /foo HTTP/1.1\r\nHost: foobar.com\r\n\n",
+		"GET /foo HTTP/1.1\r\nX-Foo: a\rb\r\n\r\n",
+	} {
+		h, err = read(s, true)
+		assert.True(t, err != nil)
+		assert.True(t, h.IsStrictParsing())
+
+		// parsed leniently by default
+		_, err = read(s, false)
+		assert.Nil(t, err)
+	}
+}
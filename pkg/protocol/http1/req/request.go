@@ -353,7 +353,7 @@ func ContinueReadBody(req *protocol.Request, r network.Reader, maxBodySize int,
 
 	bodyBuf := req.BodyBuffer()
 	bodyBuf.Reset()
-	bodyBuf.B, err = ext.ReadBody(r, contentLength, maxBodySize, bodyBuf.B)
+	bodyBuf.B, err = ext.ReadBodyWithChunkExtensions(r, contentLength, maxBodySize, bodyBuf.B, req.Header.Trailer())
 	if err != nil {
 		req.Reset()
 		return err
@@ -426,7 +426,7 @@ func writeBodyStream(req *protocol.Request, w network.Writer) error {
 		req.Header.SetContentLength(-1)
 		err = WriteHeader(&req.Header, w)
 		if err == nil {
-			err = ext.WriteBodyChunked(w, req.BodyStream())
+			err = ext.WriteBodyChunkedWithConfig(w, req.BodyStream(), req.Header.Trailer(), req.ChunkedStreamConfig())
 		}
 		if err == nil {
 			err = ext.WriteTrailer(req.Header.Trailer(), w)
@@ -766,6 +766,67 @@ func TestRequestReadChunked(t *testing.T) {
 	verifyTrailer(t, zr, map[string]string{"Trail": "test"})
 }
 
+func TestRequestReadChunkExtensions(t *testing.T) {
+	t.Parallel()
+
+	var req protocol.Request
+	s := "POST /foo HTTP/1.1\r\nHost: google.com\r\nTransfer-Encoding: chunked\r\n\r\n5;a=b\r\nhello\r\n0;last=\"x y\"\r\n\r\n"
+	zr := mock.NewZeroCopyReader(s)
+	if err := Read(&req, zr); err != nil {
+		t.Fatalf("Unexpected error when reading chunked request: %s", err)
+	}
+	assert.DeepEqual(t, "hello", string(req.Body()))
+	assert.DeepEqual(t, []protocol.ChunkExtension{
+		{Chunk: 0, Name: "a", Value: "b"},
+		{Chunk: 1, Name: "last", Value: "x y"},
+	}, req.Header.Trailer().ChunkExtensions())
+}
+
+func TestRequestWriteChunkExtensions(t *testing.T) {
+	t.Parallel()
+
+	var req protocol.Request
+	req.Header.SetHost("foobar.com")
+	req.Header.SetMethod(consts.MethodPost)
+	req.SetBodyStream(bytes.NewBufferString("hello"), -1)
+	req.Header.Trailer().AddChunkExtension(0, "a", "b")
+	req.Header.Trailer().AddChunkExtension(protocol.LastChunk, "last", "x y")
+
+	var w bytes.Buffer
+	zw := netpoll.NewWriter(&w)
+	if err := Write(&req, zw); err != nil {
+		t.Fatalf("unexpected error when writing request: %s", err)
+	}
+	if err := zw.Flush(); err != nil {
+		t.Fatalf("unexpected error when flushing request: %s", err)
+	}
+	assert.True(t, strings.Contains(w.String(), "\r\n\r\n5;a=b\r\nhello\r\n0;last=\"x y\"\r\n"))
+}
+
+func TestRequestWriteChunkedStreamConfig(t *testing.T) {
+	t.Parallel()
+
+	var req protocol.Request
+	req.Header.SetHost("foobar.com")
+	req.Header.SetMethod(consts.MethodPost)
+	req.SetBodyStream(bytes.NewBufferString("hello world"), -1)
+	req.SetChunkedStreamConfig(&protocol.ChunkedStreamConfig{ChunkSize: 6, BufferChunks: true})
+
+	var w bytes.Buffer
+	zw := netpoll.NewWriter(&w)
+	if err := Write(&req, zw); err != nil {
+		t.Fatalf("unexpected error when writing request: %s", err)
+	}
+	assert.DeepEqual(t, 0, w.Len())
+	if err := zw.Flush(); err != nil {
+		t.Fatalf("unexpected error when flushing request: %s", err)
+	}
+	assert.True(t, strings.HasSuffix(w.String(), "\r\n\r\n6\r\nhello \r\n5\r\nworld\r\n0\r\n\r\n"))
+
+	req.Reset()
+	assert.Nil(t, req.ChunkedStreamConfig())
+}
+
 func verifyTrailer(t *testing.T, r network.Reader, exceptedTrailers map[string]string) {
 	trailer := protocol.Trailer{}
 	keys := make([]string, 0, len(exceptedTrailers))
@@ -87,8 +87,15 @@ func ReadHeader(h *protocol.ResponseHeader, r network.Reader) error {
 
 // Write writes response header to w.
 func WriteHeader(h *protocol.ResponseHeader, w network.Writer) error {
+	return writeHeader(h, w, nil)
+}
+
+func writeHeader(h *protocol.ResponseHeader, w network.Writer, hook func(raw []byte)) error {
 	header := h.Header()
 	h.SetHeaderLength(len(header))
+	if hook != nil {
+		hook(header)
+	}
 	_, err := w.WriteBinary(header)
 	if err != nil {
 		return err
@@ -217,6 +224,11 @@ func parse(h *protocol.ResponseHeader, buf []byte) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	rawHeaders, _, err := ext.ReadRawHeaders(h.RawHeaders()[:0], buf[m:])
+	h.SetRawHeaders(rawHeaders)
+	if err != nil {
+		return 0, err
+	}
 	n, err := parseHeaders(h, buf[m:])
 	if err != nil {
 		return 0, err
@@ -43,6 +43,7 @@ package resp
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/protocol"
@@ -179,3 +180,36 @@ func equalCookie(c1, c2 *protocol.Cookie) bool {
 	}
 	return true
 }
+
+func TestResponseHeaderVisitAllInOrder(t *testing.T) {
+	s := "HTTP/1.1 200 OK\r\n" +
+		"X-B: 1\r\n" +
+		"Content-Type: foo/bar\r\n" +
+		"Set-Cookie: a=b\r\n" +
+		"X-A: 2\r\n" +
+		"Content-Length: 0\r\n" +
+		"\r\n"
+	var h protocol.ResponseHeader
+	_, err := parse(&h, []byte(s))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var got []string
+	h.VisitAllInOrder(func(key, value []byte) {
+		got = append(got, string(key)+"="+string(value))
+	})
+	expected := []string{"X-B=1", "Content-Type=foo/bar", "Set-Cookie=a=b", "X-A=2", "Content-Length=0"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected headers %q. Expected %q", got, expected)
+	}
+
+	var dst protocol.ResponseHeader
+	h.CopyTo(&dst)
+	if !bytes.Equal(dst.RawHeaders(), h.RawHeaders()) {
+		t.Fatalf("unexpected raw headers %q. Expected %q", dst.RawHeaders(), h.RawHeaders())
+	}
+	h.Reset()
+	if len(h.RawHeaders()) != 0 {
+		t.Fatalf("unexpected raw headers %q after reset", h.RawHeaders())
+	}
+}
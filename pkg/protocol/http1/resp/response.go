@@ -111,7 +111,7 @@ func ReadHeaderAndLimitBody(resp *protocol.Response, r network.Reader, maxBodySi
 	if !resp.MustSkipBody() {
 		bodyBuf := resp.BodyBuffer()
 		bodyBuf.Reset()
-		bodyBuf.B, err = ext.ReadBody(r, resp.Header.ContentLength(), maxBodySize, bodyBuf.B)
+		bodyBuf.B, err = ext.ReadBodyWithChunkExtensions(r, resp.Header.ContentLength(), maxBodySize, bodyBuf.B, resp.Header.Trailer())
 		if err != nil {
 			return err
 		}
@@ -150,6 +150,16 @@ func (c *clientRespStream) Read(p []byte) (n int, err error) {
 	return c.r.Read(p)
 }
 
+// NextNoCopy implements ext.NoCopyReader, so that the streamed response can be piped
+// to another connection, see ext.NoCopyReader for details.
+func (c *clientRespStream) NextNoCopy(n int) ([]byte, error) {
+	return c.r.(ext.NoCopyReader).NextNoCopy(n)
+}
+
+func (c *clientRespStream) Release() error {
+	return c.r.(ext.NoCopyReader).Release()
+}
+
 func (c *clientRespStream) reset() {
 	c.closeCallback = nil
 	c.r = nil
@@ -226,10 +236,21 @@ func Read(resp *protocol.Response, r network.Reader) error {
 //
 // See also WriteTo.
 func Write(resp *protocol.Response, w network.Writer) error {
+	return WriteWithHeaderHook(resp, w, nil)
+}
+
+// WriteWithHeaderHook writes response to w like Write,
+// and calls hook with the serialized status line and headers before they are written.
+//
+// NOTE:
+//
+//	raw refers to the buffer of the response header, which is reused after hook returns,
+//	so it must be copied if it is used after that.
+func WriteWithHeaderHook(resp *protocol.Response, w network.Writer, hook func(raw []byte)) error {
 	sendBody := !resp.MustSkipBody()
 
 	if resp.IsBodyStream() {
-		return writeBodyStream(resp, w, sendBody)
+		return writeBodyStream(resp, w, sendBody, hook)
 	}
 
 	body := resp.BodyBytes()
@@ -239,6 +260,9 @@ func Write(resp *protocol.Response, w network.Writer) error {
 	}
 
 	header := resp.Header.Header()
+	if hook != nil {
+		hook(header)
+	}
 	_, err := w.WriteBinary(header)
 	if err != nil {
 		return err
@@ -251,7 +275,7 @@ func Write(resp *protocol.Response, w network.Writer) error {
 	return err
 }
 
-func writeBodyStream(resp *protocol.Response, w network.Writer, sendBody bool) (err error) {
+func writeBodyStream(resp *protocol.Response, w network.Writer, sendBody bool, hook func(raw []byte)) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = &ErrBodyStreamWritePanic{
@@ -273,32 +297,55 @@ func writeBodyStream(resp *protocol.Response, w network.Writer, sendBody bool) (
 			}
 		}
 	}
+	// count the written bytes only if someone cares, since the counter hides
+	// the underlying stream from the optimizations of copying
+	bodyStream := resp.BodyStream()
+	var counter *countingReader
+	if resp.HasBodyStreamDoneCallbacks() {
+		counter = &countingReader{r: bodyStream}
+		bodyStream = counter
+	}
 	if contentLength >= 0 {
-		if err = WriteHeader(&resp.Header, w); err == nil && sendBody {
+		if err = writeHeader(&resp.Header, w, hook); err == nil && sendBody {
 			if resp.ImmediateHeaderFlush {
 				err = w.Flush()
 			}
 			if err == nil {
-				err = ext.WriteBodyFixedSize(w, resp.BodyStream(), int64(contentLength))
+				err = ext.WriteBodyFixedSize(w, bodyStream, int64(contentLength))
 			}
 		}
 	} else {
 		resp.Header.SetContentLength(-1)
-		if err = WriteHeader(&resp.Header, w); err == nil && sendBody {
+		if err = writeHeader(&resp.Header, w, hook); err == nil && sendBody {
 			if resp.ImmediateHeaderFlush {
 				err = w.Flush()
 			}
 			if err == nil {
-				err = ext.WriteBodyChunked(w, resp.BodyStream())
+				err = ext.WriteBodyChunkedWithExtensions(w, bodyStream, resp.Header.Trailer())
 			}
 			if err == nil {
 				err = ext.WriteTrailer(resp.Header.Trailer(), w)
 			}
 		}
 	}
-	err1 := resp.CloseBodyStream()
+	var written int64
+	if counter != nil {
+		written = counter.n
+	}
+	err1 := resp.CloseBodyStreamWithResult(written, err)
 	if err == nil {
 		err = err1
 	}
 	return err
 }
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
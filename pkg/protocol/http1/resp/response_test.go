@@ -787,3 +787,63 @@ func TestResponseReadBodyStreamBadTrailer(t *testing.T) {
 	testResponseReadBodyStreamBadTrailer(t, resp, "HTTP/1.1 300 OK\r\nTransfer-Encoding: chunked\r\nContent-Type: bar\r\n\r\n5\r\n56789\r\n0\r\ncontent-type: bar\r\n\r\n")
 	testResponseReadBodyStreamBadTrailer(t, resp, "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nTransfer-Encoding: chunked\r\n\r\n4\r\nqwer\r\n2\r\nty\r\n0\r\nproxy-connection: bar2\r\n\r\n")
 }
+
+func TestResponseBodyStreamDone(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{11, -1} {
+		var resp protocol.Response
+		var written int64
+		var doneErr error
+		calls := 0
+		resp.OnBodyStreamDone(func(n int64, err error) {
+			calls++
+			written, doneErr = n, err
+		})
+		resp.SetBodyStream(strings.NewReader("hello world"), size)
+
+		var w bytes.Buffer
+		zw := netpoll.NewWriter(&w)
+		assert.Nil(t, Write(&resp, zw))
+		assert.Nil(t, zw.Flush())
+		assert.DeepEqual(t, 1, calls)
+		assert.DeepEqual(t, int64(11), written)
+		assert.Nil(t, doneErr)
+		assert.False(t, resp.HasBodyStreamDoneCallbacks())
+	}
+
+	var resp protocol.Response
+	var doneErr error
+	resp.OnBodyStreamDone(func(n int64, err error) {
+		doneErr = err
+	})
+	resp.SetBodyStream(&ErroneousBodyStream{errOnRead: true}, 100)
+	var w bytes.Buffer
+	zw := netpoll.NewWriter(&w)
+	assert.NotNil(t, Write(&resp, zw))
+	assert.NotNil(t, doneErr)
+}
+
+func TestResponseWriteWithHeaderHook(t *testing.T) {
+	var resp protocol.Response
+	resp.SetBodyString("hello")
+	resp.Header.Set("X-Foo", "a")
+
+	var raw string
+	hook := func(b []byte) {
+		raw = string(b)
+	}
+	w := bytes.NewBuffer(nil)
+	zw := netpoll.NewWriter(w)
+	assert.Nil(t, WriteWithHeaderHook(&resp, zw, hook))
+	assert.Nil(t, zw.Flush())
+	assert.DeepEqual(t, w.String(), raw+"hello")
+
+	// the header of streaming response
+	resp.SetBodyStream(strings.NewReader("hello"), -1)
+	w.Reset()
+	assert.Nil(t, WriteWithHeaderHook(&resp, zw, hook))
+	assert.Nil(t, zw.Flush())
+	assert.True(t, strings.HasPrefix(w.String(), raw))
+	assert.True(t, strings.Contains(raw, "Transfer-Encoding: chunked\r\n"))
+}
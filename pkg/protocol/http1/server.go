@@ -29,6 +29,7 @@ import (
 	internalStats "github.com/cloudwego/hertz/internal/stats"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server/render"
+	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
@@ -51,23 +52,42 @@ var (
 	errIdleTimeout     = errs.New(errs.ErrIdleTimeout, errs.ErrorTypePublic, nil)
 	errShortConnection = errs.New(errs.ErrShortConnection, errs.ErrorTypePublic, "server is going to close the connection")
 	errUnexpectedEOF   = errs.NewPublic(io.ErrUnexpectedEOF.Error() + " when reading request")
+	errURLTooLong      = errs.New(errs.ErrURLTooLong, errs.ErrorTypePublic, nil)
+	errTooManyHeaders  = errs.New(errs.ErrHeaderTooLarge, errs.ErrorTypePublic, "too many headers")
 )
 
 type Option struct {
-	StreamRequestBody            bool
-	GetOnly                      bool
-	DisablePreParseMultipartForm bool
-	DisableKeepalive             bool
-	NoDefaultServerHeader        bool
-	MaxRequestBodySize           int
-	IdleTimeout                  time.Duration
-	ReadTimeout                  time.Duration
-	ServerName                   []byte
-	TLS                          *tls.Config
-	HTMLRender                   render.HTMLRender
-	EnableTrace                  bool
-	ContinueHandler              func(header *protocol.RequestHeader) bool
-	HijackConnHandle             func(c network.Conn, h app.HijackHandler)
+	StreamRequestBody             bool
+	GetOnly                       bool
+	DisablePreParseMultipartForm  bool
+	DisableKeepalive              bool
+	NoDefaultServerHeader         bool
+	DisableHeaderNamesNormalizing bool
+	StrictCookieParsing           bool
+	StrictRequestParsing          bool
+	QueryDialect                  *protocol.QueryDialect
+	FormLimits                    *protocol.FormLimits
+	MaxRequestBodySize            int
+	MaxHeaderBytes                int
+	MaxHeaderCount                int
+	MaxURLLength                  int
+	MaxRequestsPerConn            int
+	MaxConnAge                    time.Duration
+	IdleTimeout                   time.Duration
+	ReadTimeout                   time.Duration
+	// Timeouts takes the place of IdleTimeout and ReadTimeout if it is not nil,
+	// so that they can be changed while the server is running.
+	Timeouts              *config.Timeouts
+	ServerName            []byte
+	TLS                   *tls.Config
+	HTMLRender            render.HTMLRender
+	EnableTrace           bool
+	ContinueHandler       func(header *protocol.RequestHeader) bool
+	ParseErrorHandler     func(ctx *app.RequestContext, err error)
+	ContinueRejectHandler func(ctx *app.RequestContext)
+	RawRequestHeaderHook  func(ctx *app.RequestContext, raw []byte)
+	RawResponseHeaderHook func(ctx *app.RequestContext, raw []byte)
+	HijackConnHandle      func(c network.Conn, h app.HijackHandler)
 }
 
 type Server struct {
@@ -99,6 +119,8 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 
 		traceCtl        = s.Core.GetTracer()
 		eventsToTrigger *eventStack
+		// traceStarted reports whether the tracers are started for a request which is not finished yet
+		traceStarted bool
 
 		// Use a new variable to hold the standard context to avoid modify the initial
 		// context.
@@ -122,7 +144,9 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 				s.eventStackPool.Put(eventsToTrigger)
 			}
 
-			traceCtl.DoFinish(cc, ctx, err)
+			if traceStarted {
+				traceCtl.DoFinish(cc, ctx, err)
+			}
 		}
 
 		// Hijack may release and close the connection already
@@ -138,12 +162,20 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 	ctx.SetConn(conn)
 	ctx.Request.SetIsTLS(s.TLS != nil)
 	ctx.SetEnableTrace(s.EnableTrace)
+	if s.Timeouts != nil {
+		// the connection may be set up with the read timeout before it is changed
+		conn.SetReadTimeout(s.readTimeout()) //nolint:errcheck
+	}
 
 	if !s.NoDefaultServerHeader {
 		serverName = s.ServerName
 	}
 
 	connRequestNum := uint64(0)
+	var connStart time.Time
+	if s.MaxConnAge > 0 {
+		connStart = time.Now()
+	}
 
 	for {
 		connRequestNum++
@@ -155,9 +187,17 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 		// If this is a keep-alive connection we want to try and read the first bytes
 		// within the idle time.
 		if connRequestNum > 1 {
-			ctx.GetConn().SetReadTimeout(s.IdleTimeout) //nolint:errcheck
+			ctx.GetConn().SetReadTimeout(s.idleTimeout()) //nolint:errcheck
 
+			tracker, _ := s.Core.(suite.ConnTracker)
+			if tracker != nil && !tracker.ConnIdle(conn) {
+				err = errIdleTimeout
+				return
+			}
 			_, err = zr.Peek(4)
+			if tracker != nil {
+				tracker.ConnActive(conn)
+			}
 			// This is not the first request, and we haven't read a single byte
 			// of a new request yet. This means it's just a keep-alive connection
 			// closing down either because the remote closed it or because
@@ -169,18 +209,35 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 			}
 
 			// Reset the real read timeout for the coming request
-			ctx.GetConn().SetReadTimeout(s.ReadTimeout) //nolint:errcheck
+			ctx.GetConn().SetReadTimeout(s.readTimeout()) //nolint:errcheck
 		}
 
 		if s.EnableTrace {
 			cc = traceCtl.DoStart(c, ctx)
+			traceStarted = true
 			internalStats.Record(ctx.GetTraceInfo(), stats.ReadHeaderStart, err)
 			eventsToTrigger.push(func(ti traceinfo.TraceInfo, err error) {
 				internalStats.Record(ti, stats.ReadHeaderFinish, err)
 			})
 		}
+		if s.DisableHeaderNamesNormalizing {
+			ctx.Request.Header.DisableNormalizing()
+			ctx.Response.Header.DisableNormalizing()
+		}
+		if s.StrictCookieParsing {
+			ctx.Request.Header.SetStrictCookieParsing(true)
+		}
+		if s.StrictRequestParsing {
+			ctx.Request.Header.SetStrictParsing(true)
+		}
+		if s.QueryDialect != nil {
+			ctx.Request.SetQueryDialect(s.QueryDialect)
+		}
+		if s.FormLimits != nil {
+			ctx.Request.SetFormLimits(s.FormLimits)
+		}
 		// Read Headers
-		if err = req.ReadHeader(&ctx.Request.Header, zr); err == nil {
+		if err = s.readHeader(ctx, zr); err == nil {
 			if s.EnableTrace {
 				// read header finished
 				if last := eventsToTrigger.pop(); last != nil {
@@ -219,45 +276,61 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 			if err == io.EOF {
 				return errUnexpectedEOF
 			}
-			writeErrorResponse(zw, ctx, serverName, err)
+			s.writeErrorResponse(zw, ctx, serverName, err)
 			return
 		}
 
 		// 'Expect: 100-continue' request handling.
 		// See https://www.w3.org/Protocols/rfc2616/rfc2616-sec8.html#sec8.2.3 for details.
 		if ctx.Request.MayContinue() {
-			// Allow the ability to deny reading the incoming request body
-			if s.ContinueHandler != nil {
+			rejectStatusCode := 0
+			if !s.StreamRequestBody && s.MaxRequestBodySize > 0 && ctx.Request.Header.ContentLength() > s.MaxRequestBodySize {
+				// Reject the body which is too large before the client transmits it,
+				// the streamed body is not limited by the max request body size.
+				continueReadingRequest = false
+				rejectStatusCode = consts.StatusRequestEntityTooLarge
+			} else if s.ContinueHandler != nil {
+				// Allow the ability to deny reading the incoming request body
 				if continueReadingRequest = s.ContinueHandler(&ctx.Request.Header); !continueReadingRequest {
-					ctx.SetStatusCode(consts.StatusExpectationFailed)
+					rejectStatusCode = consts.StatusExpectationFailed
 				}
 			}
 
-			if continueReadingRequest {
-				zw = ctx.GetWriter()
-				// Send 'HTTP/1.1 100 Continue' response.
-				_, err = zw.WriteBinary(bytestr.StrResponseContinue)
-				if err != nil {
-					return
-				}
-				err = zw.Flush()
-				if err != nil {
-					return
-				}
+			if !continueReadingRequest {
+				s.rejectContinue(zw, ctx, serverName, rejectStatusCode)
+				return errShortConnection
+			}
 
-				// Read body.
-				if zr == nil {
-					zr = ctx.GetReader()
-				}
-				if s.StreamRequestBody {
-					err = req.ContinueReadBodyStream(&ctx.Request, zr, s.MaxRequestBodySize, !s.DisablePreParseMultipartForm)
-				} else {
-					err = req.ContinueReadBody(&ctx.Request, zr, s.MaxRequestBodySize, !s.DisablePreParseMultipartForm)
-				}
-				if err != nil {
-					writeErrorResponse(zw, ctx, serverName, err)
-					return
-				}
+			zw = ctx.GetWriter()
+			// Send 'HTTP/1.1 100 Continue' response.
+			_, err = zw.WriteBinary(bytestr.StrResponseContinue)
+			if err != nil {
+				return
+			}
+			err = zw.Flush()
+			if err != nil {
+				return
+			}
+
+			// Read body.
+			if zr == nil {
+				zr = ctx.GetReader()
+			}
+			if s.StreamRequestBody {
+				err = req.ContinueReadBodyStream(&ctx.Request, zr, s.MaxRequestBodySize, !s.DisablePreParseMultipartForm)
+			} else {
+				err = req.ContinueReadBody(&ctx.Request, zr, s.MaxRequestBodySize, !s.DisablePreParseMultipartForm)
+			}
+			if err != nil {
+				s.writeErrorResponse(zw, ctx, serverName, err)
+				return
+			}
+		}
+
+		if s.FormLimits != nil {
+			if err = ctx.Request.CheckFormLimits(); err != nil {
+				s.writeErrorResponse(zw, ctx, serverName, err)
+				return
 			}
 		}
 
@@ -297,6 +370,14 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 		hijackHandler = ctx.GetHijackHandler()
 		ctx.SetHijackHandler(nil)
 
+		// Close the connection proactively for rebalancing if it has been reused enough.
+		if s.MaxRequestsPerConn > 0 && connRequestNum >= uint64(s.MaxRequestsPerConn) {
+			connectionClose = true
+		}
+		if s.MaxConnAge > 0 && time.Since(connStart) >= s.MaxConnAge {
+			connectionClose = true
+		}
+
 		connectionClose = connectionClose || ctx.Response.ConnectionClose()
 		if connectionClose {
 			ctx.Response.Header.SetCanonical(bytestr.StrConnection, bytestr.StrClose)
@@ -313,12 +394,20 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 				internalStats.Record(ti, stats.WriteFinish, err)
 			})
 		}
-		if err = writeResponse(ctx, zw); err != nil {
+		streamWritten := int64(-1)
+		if s.EnableTrace && ctx.Response.IsBodyStream() {
+			ctx.Response.OnBodyStreamDone(func(written int64, _ error) {
+				streamWritten = written
+			})
+		}
+		if err = s.writeResponse(ctx, zw); err != nil {
 			return
 		}
 
 		if s.EnableTrace {
-			if ctx.Response.Header.ContentLength() > 0 {
+			if streamWritten >= 0 {
+				ctx.GetTraceInfo().Stats().SetSendSize(ctx.Response.Header.GetHeaderLength() + int(streamWritten))
+			} else if ctx.Response.Header.ContentLength() > 0 {
 				ctx.GetTraceInfo().Stats().SetSendSize(ctx.Response.Header.GetHeaderLength() + ctx.Response.Header.ContentLength())
 			} else {
 				ctx.GetTraceInfo().Stats().SetSendSize(0)
@@ -330,14 +419,22 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 			zr.Release() //nolint:errcheck
 			zr = nil
 		}
+		if s.EnableTrace {
+			internalStats.Record(ctx.GetTraceInfo(), stats.FlushStart, err)
+			eventsToTrigger.push(func(ti traceinfo.TraceInfo, err error) {
+				internalStats.Record(ti, stats.FlushFinish, err)
+			})
+		}
 		// Flush the response.
 		if err = zw.Flush(); err != nil {
 			return
 		}
 		if s.EnableTrace {
-			// write finished
-			if last := eventsToTrigger.pop(); last != nil {
-				last(ctx.GetTraceInfo(), err)
+			// flush and write finished
+			for i := 0; i < 2; i++ {
+				if last := eventsToTrigger.pop(); last != nil {
+					last(ctx.GetTraceInfo(), err)
+				}
 			}
 		}
 
@@ -370,6 +467,7 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 		// general case
 		if s.EnableTrace {
 			traceCtl.DoFinish(cc, ctx, err)
+			traceStarted = false
 		}
 
 		if connectionClose {
@@ -385,6 +483,25 @@ func (s Server) Serve(c context.Context, conn network.Conn) (err error) {
 	}
 }
 
+func (s Server) readTimeout() time.Duration {
+	if s.Timeouts != nil {
+		return s.Timeouts.Read()
+	}
+	return s.ReadTimeout
+}
+
+// idleTimeout returns the idle timeout of the keep-alive connections,
+// the one of Timeouts is only used if the server is not started with the zero or negative IdleTimeout,
+// which have special meanings.
+func (s Server) idleTimeout() time.Duration {
+	if s.Timeouts != nil && s.IdleTimeout > 0 {
+		if d := s.Timeouts.Idle(); d > 0 {
+			return d
+		}
+	}
+	return s.IdleTimeout
+}
+
 func NewServer() *Server {
 	return &Server{
 		eventStackPool: &sync.Pool{
@@ -395,10 +512,38 @@ func NewServer() *Server {
 	}
 }
 
-func writeErrorResponse(zw network.Writer, ctx *app.RequestContext, serverName []byte, err error) network.Writer {
-	errorHandler := defaultErrorHandler
+// readHeader reads the request header and checks it against the limitations.
+func (s Server) readHeader(ctx *app.RequestContext, zr network.Reader) error {
+	var hook func(raw []byte)
+	if s.RawRequestHeaderHook != nil {
+		hook = func(raw []byte) {
+			s.RawRequestHeaderHook(ctx, raw)
+		}
+	}
+	h := &ctx.Request.Header
+	if err := req.ReadHeaderWithHook(h, zr, s.MaxHeaderBytes, hook); err != nil {
+		return err
+	}
+	if s.MaxURLLength > 0 && len(h.RequestURI()) > s.MaxURLLength {
+		return errURLTooLong
+	}
+	if s.MaxHeaderCount > 0 {
+		count := 0
+		h.VisitAllInOrder(func(_, _ []byte) {
+			count++
+		})
+		if count > s.MaxHeaderCount {
+			return errTooManyHeaders
+		}
+	}
+	return nil
+}
 
-	errorHandler(ctx, err)
+func (s Server) writeErrorResponse(zw network.Writer, ctx *app.RequestContext, serverName []byte, err error) network.Writer {
+	defaultErrorHandler(ctx, err)
+	if s.ParseErrorHandler != nil {
+		s.ParseErrorHandler(ctx, err)
+	}
 
 	if serverName != nil {
 		ctx.Response.Header.SetServerBytes(serverName)
@@ -407,25 +552,49 @@ func writeErrorResponse(zw network.Writer, ctx *app.RequestContext, serverName [
 	if zw == nil {
 		zw = ctx.GetWriter()
 	}
-	writeResponse(ctx, zw) //nolint:errcheck
-	zw.Flush()             //nolint:errcheck
+	s.writeResponse(ctx, zw) //nolint:errcheck
+	zw.Flush()               //nolint:errcheck
 	return zw
 }
 
-func writeResponse(ctx *app.RequestContext, w network.Writer) error {
-	err := resp.Write(&ctx.Response, w)
-	if err != nil {
-		return err
+// rejectContinue writes the response of statusCode rejecting the 'Expect: 100-continue' request.
+// The connection is closed since the client may transmit the body anyway.
+func (s Server) rejectContinue(zw network.Writer, ctx *app.RequestContext, serverName []byte, statusCode int) {
+	ctx.AbortWithMsg(consts.StatusMessage(statusCode), statusCode)
+	if s.ContinueRejectHandler != nil {
+		s.ContinueRejectHandler(ctx)
+	}
+
+	if serverName != nil {
+		ctx.Response.Header.SetServerBytes(serverName)
+	}
+	ctx.SetConnectionClose()
+	if zw == nil {
+		zw = ctx.GetWriter()
 	}
+	s.writeResponse(ctx, zw) //nolint:errcheck
+	zw.Flush()               //nolint:errcheck
+}
 
-	return err
+func (s Server) writeResponse(ctx *app.RequestContext, w network.Writer) error {
+	var hook func(raw []byte)
+	if s.RawResponseHeaderHook != nil {
+		hook = func(raw []byte) {
+			s.RawResponseHeaderHook(ctx, raw)
+		}
+	}
+	return resp.WriteWithHeaderHook(&ctx.Response, w, hook)
 }
 
 func defaultErrorHandler(ctx *app.RequestContext, err error) {
 	if netErr, ok := err.(*net.OpError); ok && netErr.Timeout() {
 		ctx.AbortWithMsg("Request timeout", consts.StatusRequestTimeout)
-	} else if errors.Is(err, errs.ErrBodyTooLarge) {
+	} else if errors.Is(err, errs.ErrBodyTooLarge) || errors.Is(err, errs.ErrFormTooLarge) {
 		ctx.AbortWithMsg("Request Entity Too Large", consts.StatusRequestEntityTooLarge)
+	} else if errors.Is(err, errs.ErrHeaderTooLarge) {
+		ctx.AbortWithMsg("Request Header Fields Too Large", consts.StatusRequestHeaderFieldsTooLarge)
+	} else if errors.Is(err, errs.ErrURLTooLong) {
+		ctx.AbortWithMsg("Request URI Too Long", consts.StatusRequestURITooLong)
 	} else {
 		ctx.AbortWithMsg("Error when parsing request", consts.StatusBadRequest)
 	}
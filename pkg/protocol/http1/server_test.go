@@ -19,11 +19,17 @@ package http1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	inStats "github.com/cloudwego/hertz/internal/stats"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
@@ -31,6 +37,9 @@ import (
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
 	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
 )
 
 var pool = &sync.Pool{New: func() interface{} {
@@ -63,9 +72,58 @@ func TestTraceEventCompleted(t *testing.T) {
 	assert.False(t, traceInfo.Stats().GetEvent(stats.ServerHandleFinish).IsNil())
 	assert.False(t, traceInfo.Stats().GetEvent(stats.WriteStart).IsNil())
 	assert.False(t, traceInfo.Stats().GetEvent(stats.WriteFinish).IsNil())
+	assert.False(t, traceInfo.Stats().GetEvent(stats.FlushStart).IsNil())
+	assert.False(t, traceInfo.Stats().GetEvent(stats.FlushFinish).IsNil())
 	assert.False(t, traceInfo.Stats().GetEvent(stats.HTTPFinish).IsNil())
 }
 
+type countTracer struct {
+	starts, finishes int
+}
+
+func (c *countTracer) Start(ctx context.Context, _ *app.RequestContext) context.Context {
+	c.starts++
+	return ctx
+}
+
+func (c *countTracer) Finish(_ context.Context, _ *app.RequestContext) {
+	c.finishes++
+}
+
+func TestTraceFinishedOncePerRequest(t *testing.T) {
+	for _, tc := range []struct {
+		raw         string
+		idleTimeout time.Duration
+		requests    int
+	}{
+		{"GET /aaa HTTP/1.1\nHost: foobar.com\n\n", 0, 1},
+		{"GET /aaa HTTP/1.1\nHost: foobar.com\nConnection: close\n\n", 10 * time.Millisecond, 1},
+		{"GET /aaa HTTP/1.1\nHost: foobar.com\n\nGET /bbb HTTP/1.1\nHost: foobar.com\n\n", 10 * time.Millisecond, 2},
+	} {
+		server := &Server{}
+		server.eventStackPool = pool
+		server.EnableTrace = true
+		server.IdleTimeout = tc.idleTimeout
+		ct := &countTracer{}
+		controller := &inStats.Controller{}
+		controller.Append(ct)
+		server.Core = &mockCore{
+			ctxPool: &sync.Pool{New: func() interface{} {
+				ti := traceinfo.NewTraceInfo()
+				ti.Stats().SetLevel(2)
+				ctx := &app.RequestContext{}
+				ctx.SetTraceInfo(ti)
+				return ctx
+			}},
+			controller: controller,
+			running:    true,
+		}
+		server.Serve(context.TODO(), mock.NewConn(tc.raw)) //nolint:errcheck
+		assert.DeepEqual(t, tc.requests, ct.starts)
+		assert.DeepEqual(t, tc.requests, ct.finishes)
+	}
+}
+
 func TestTraceEventReadHeaderError(t *testing.T) {
 	server := &Server{}
 	server.eventStackPool = pool
@@ -160,6 +218,335 @@ func TestTraceEventWriteError(t *testing.T) {
 	assert.False(t, traceInfo.Stats().GetEvent(stats.HTTPFinish).IsNil())
 }
 
+func TestServerDisableHeaderNamesNormalizing(t *testing.T) {
+	server := &Server{}
+	server.eventStackPool = pool
+	server.DisableHeaderNamesNormalizing = true
+	var requestHeader string
+	server.Core = &mockCore{
+		ctxPool: &sync.Pool{New: func() interface{} {
+			return &app.RequestContext{}
+		}},
+		controller: &inStats.Controller{},
+		handler: func(c context.Context, ctx *app.RequestContext) {
+			requestHeader = string(ctx.Request.Header.Header())
+			ctx.Response.Header.Set("x-lower-case", "foo")
+			ctx.Response.Header.Set("X-UPPER-CASE", "bar")
+		},
+	}
+	conn := mock.NewConn("GET /aaa HTTP/1.1\r\nHost: foobar.com\r\nx-custom-HEADER: baz\r\n\r\n")
+	err := server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.True(t, strings.Contains(requestHeader, "x-custom-HEADER: baz\r\n"))
+
+	var response protocol.Response
+	response.Header.DisableNormalizing()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.True(t, strings.Contains(string(response.Header.Header()), "x-lower-case: foo\r\n"))
+	assert.True(t, strings.Contains(string(response.Header.Header()), "X-UPPER-CASE: bar\r\n"))
+}
+
+func TestServerQueryDialect(t *testing.T) {
+	server := &Server{}
+	server.eventStackPool = pool
+	server.QueryDialect = &protocol.QueryDialect{SemicolonSeparator: true, DuplicateKeys: protocol.DuplicateKeysKeepLast}
+	var a, b string
+	server.Core = &mockCore{
+		ctxPool: &sync.Pool{New: func() interface{} {
+			return &app.RequestContext{}
+		}},
+		controller: &inStats.Controller{},
+		handler: func(c context.Context, ctx *app.RequestContext) {
+			a = string(ctx.QueryArgs().Peek("a"))
+			b = string(ctx.QueryArgs().Peek("b"))
+		},
+	}
+	conn := mock.NewConn("GET /aaa?a=1;b=2;a=3 HTTP/1.1\r\nHost: foobar.com\r\n\r\n")
+	err := server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.DeepEqual(t, "3", a)
+	assert.DeepEqual(t, "2", b)
+}
+
+func TestServerBodyReaderDrain(t *testing.T) {
+	server := &Server{}
+	server.eventStackPool = pool
+	server.StreamRequestBody = true
+	var head string
+	var readErr error
+	server.Core = &mockCore{
+		ctxPool: &sync.Pool{New: func() interface{} {
+			return &app.RequestContext{}
+		}},
+		controller: &inStats.Controller{},
+		handler: func(c context.Context, ctx *app.RequestContext) {
+			r := ctx.RequestBodyReader()
+			b := make([]byte, 4)
+			n, _ := io.ReadFull(r, b)
+			head = string(b[:n])
+			assert.Nil(t, r.Close())
+			_, readErr = r.Read(b)
+		},
+	}
+	body := strings.Repeat("a", 4) + strings.Repeat("b", 2048)
+	conn := mock.NewConn("POST /upload HTTP/1.1\r\nHost: foobar.com\r\nContent-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" +
+		body + "GET /next HTTP/1.1\r\nHost: foobar.com\r\n\r\n")
+	err := server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.DeepEqual(t, "aaaa", head)
+	assert.NotNil(t, readErr)
+	next, _ := conn.Peek(9)
+	assert.DeepEqual(t, "GET /next", string(next))
+}
+
+func TestServerExpectContinue(t *testing.T) {
+	newServer := func() (*Server, *bool) {
+		handled := false
+		server := &Server{}
+		server.eventStackPool = pool
+		server.Core = &mockCore{
+			ctxPool: &sync.Pool{New: func() interface{} {
+				return &app.RequestContext{}
+			}},
+			controller: &inStats.Controller{},
+			handler: func(c context.Context, ctx *app.RequestContext) {
+				handled = true
+				ctx.SetBodyString(string(ctx.Request.Body()))
+			},
+		}
+		return server, &handled
+	}
+	request := "POST /upload HTTP/1.1\r\nHost: foobar.com\r\nExpect: 100-continue\r\nX-Token: %s\r\nContent-Length: 5\r\n\r\nhello"
+
+	server, handled := newServer()
+	conn := mock.NewConn(fmt.Sprintf(request, "ok"))
+	err := server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.True(t, *handled)
+	continueLine, _ := conn.WriterRecorder().Peek(len("HTTP/1.1 100 Continue"))
+	assert.DeepEqual(t, "HTTP/1.1 100 Continue", string(continueLine))
+	var response protocol.Response
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, "hello", string(response.Body()))
+
+	server, handled = newServer()
+	server.ContinueHandler = func(header *protocol.RequestHeader) bool {
+		return string(header.Peek("X-Token")) == "ok"
+	}
+	server.ContinueRejectHandler = func(ctx *app.RequestContext) {
+		assert.DeepEqual(t, consts.StatusExpectationFailed, ctx.Response.StatusCode())
+		ctx.Response.SetBodyString("invalid token")
+	}
+	conn = mock.NewConn(fmt.Sprintf(request, "bad"))
+	err = server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.False(t, *handled)
+	response.Reset()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, consts.StatusExpectationFailed, response.StatusCode())
+	assert.DeepEqual(t, "invalid token", string(response.Body()))
+	assert.True(t, response.ConnectionClose())
+
+	server, handled = newServer()
+	server.MaxRequestBodySize = 4
+	conn = mock.NewConn(fmt.Sprintf(request, "ok"))
+	err = server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.False(t, *handled)
+	response.Reset()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, consts.StatusRequestEntityTooLarge, response.StatusCode())
+
+	// the streamed body is not limited by the max request body size
+	server, handled = newServer()
+	server.MaxRequestBodySize = 4
+	server.StreamRequestBody = true
+	conn = mock.NewConn(fmt.Sprintf(request, "ok"))
+	err = server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.True(t, *handled)
+	continueLine, _ = conn.WriterRecorder().Peek(len("HTTP/1.1 100 Continue"))
+	assert.DeepEqual(t, "HTTP/1.1 100 Continue", string(continueLine))
+	response.Reset()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, consts.StatusOK, response.StatusCode())
+	assert.DeepEqual(t, "hello", string(response.Body()))
+}
+
+func TestServerConnReusePolicy(t *testing.T) {
+	newServer := func() (*Server, *[]string) {
+		var paths []string
+		server := &Server{}
+		server.eventStackPool = pool
+		server.IdleTimeout = time.Second
+		server.Core = &mockCore{
+			ctxPool: &sync.Pool{New: func() interface{} {
+				return &app.RequestContext{}
+			}},
+			controller: &inStats.Controller{},
+			handler: func(c context.Context, ctx *app.RequestContext) {
+				paths = append(paths, string(ctx.Path()))
+			},
+			running: true,
+		}
+		return server, &paths
+	}
+	request := "GET /a HTTP/1.1\r\nHost: foobar.com\r\n\r\nGET /b HTTP/1.1\r\nHost: foobar.com\r\n\r\nGET /c HTTP/1.1\r\nHost: foobar.com\r\n\r\n"
+
+	server, paths := newServer()
+	server.MaxRequestsPerConn = 2
+	conn := mock.NewConn(request)
+	err := server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.DeepEqual(t, []string{"/a", "/b"}, *paths)
+	var response protocol.Response
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.False(t, response.ConnectionClose())
+	response.Reset()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.True(t, response.ConnectionClose())
+
+	server, paths = newServer()
+	server.MaxConnAge = time.Nanosecond
+	conn = mock.NewConn(request)
+	err = server.Serve(context.TODO(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.DeepEqual(t, []string{"/a"}, *paths)
+	response.Reset()
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.True(t, response.ConnectionClose())
+}
+
+func TestServerFormLimits(t *testing.T) {
+	serve := func(body, contentType string) *protocol.Response {
+		server := &Server{}
+		server.eventStackPool = pool
+		server.FormLimits = &protocol.FormLimits{MaxFields: 2, MaxKeyLength: 4}
+		server.Core = &mockCore{
+			ctxPool: &sync.Pool{New: func() interface{} {
+				return &app.RequestContext{}
+			}},
+			controller: &inStats.Controller{},
+		}
+		conn := mock.NewConn("POST /form HTTP/1.1\r\nHost: foobar.com\r\nContent-Type: " + contentType +
+			"\r\nContent-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body)
+		assert.NotNil(t, server.Serve(context.TODO(), conn))
+		var response protocol.Response
+		assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+		return &response
+	}
+	urlencoded := "application/x-www-form-urlencoded"
+
+	assert.DeepEqual(t, consts.StatusOK, serve("a=1&b=2", urlencoded).StatusCode())
+	assert.DeepEqual(t, consts.StatusRequestEntityTooLarge, serve("a=1&b=2&c=3", urlencoded).StatusCode())
+	assert.DeepEqual(t, consts.StatusBadRequest, serve("a=1&bbbbb=2", urlencoded).StatusCode())
+
+	multipart := "--foo\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n" +
+		"--foo\r\nContent-Disposition: form-data; name=\"b\"\r\n\r\n2\r\n" +
+		"--foo\r\nContent-Disposition: form-data; name=\"c\"\r\n\r\n3\r\n--foo--\r\n"
+	assert.DeepEqual(t, consts.StatusRequestEntityTooLarge, serve(multipart, "multipart/form-data; boundary=foo").StatusCode())
+}
+
+func TestServerStrictRequestParsing(t *testing.T) {
+	server := &Server{}
+	server.eventStackPool = pool
+	server.StrictRequestParsing = true
+	server.Core = &mockCore{
+		ctxPool: &sync.Pool{New: func() interface{} {
+			return &app.RequestContext{}
+		}},
+		controller: &inStats.Controller{},
+	}
+	conn := mock.NewConn("POST /foo HTTP/1.1\r\nHost: foobar.com\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n")
+	assert.NotNil(t, server.Serve(context.TODO(), conn))
+	var response protocol.Response
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, consts.StatusBadRequest, response.StatusCode())
+	assert.True(t, response.ConnectionClose())
+}
+
+func TestServerRawHeaderHooks(t *testing.T) {
+	var rawRequest, rawResponse []byte
+	server := &Server{}
+	server.eventStackPool = pool
+	server.RawRequestHeaderHook = func(ctx *app.RequestContext, raw []byte) {
+		rawRequest = append(rawRequest[:0], raw...)
+	}
+	server.RawResponseHeaderHook = func(ctx *app.RequestContext, raw []byte) {
+		rawResponse = append(rawResponse[:0], raw...)
+	}
+	server.Core = &mockCore{
+		ctxPool: &sync.Pool{New: func() interface{} {
+			return &app.RequestContext{}
+		}},
+		controller: &inStats.Controller{},
+		handler: func(c context.Context, ctx *app.RequestContext) {
+			ctx.Response.Header.Set("X-Bar", "b")
+		},
+	}
+	request := "GET /foo HTTP/1.1\r\nHost: foobar.com\r\nx-foo:  a\r\n\r\n"
+	conn := mock.NewConn(request)
+	assert.NotNil(t, server.Serve(context.TODO(), conn))
+	assert.DeepEqual(t, request, string(rawRequest))
+	assert.True(t, strings.HasPrefix(string(rawResponse), "HTTP/1.1 200 OK\r\n"))
+	assert.True(t, strings.Contains(string(rawResponse), "X-Bar: b\r\n"))
+
+	var response protocol.Response
+	assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+	assert.DeepEqual(t, "b", string(response.Header.Peek("X-Bar")))
+}
+
+func TestServerHeaderLimits(t *testing.T) {
+	newServer := func() *Server {
+		server := &Server{}
+		server.eventStackPool = pool
+		server.Core = &mockCore{
+			ctxPool: &sync.Pool{New: func() interface{} {
+				return &app.RequestContext{}
+			}},
+			controller: &inStats.Controller{},
+		}
+		return server
+	}
+	serve := func(server *Server, request string) *protocol.Response {
+		conn := mock.NewConn(request)
+		assert.NotNil(t, server.Serve(context.TODO(), conn))
+		var response protocol.Response
+		assert.Nil(t, resp.Read(&response, conn.WriterRecorder()))
+		return &response
+	}
+	request := "GET /" + strings.Repeat("a", 64) + " HTTP/1.1\r\nHost: foobar.com\r\nX-A: 1\r\nX-B: 2\r\n\r\n"
+
+	server := newServer()
+	server.MaxHeaderBytes = 64
+	response := serve(server, request)
+	assert.DeepEqual(t, consts.StatusRequestHeaderFieldsTooLarge, response.StatusCode())
+	assert.True(t, response.ConnectionClose())
+
+	server = newServer()
+	server.MaxHeaderCount = 2
+	response = serve(server, request)
+	assert.DeepEqual(t, consts.StatusRequestHeaderFieldsTooLarge, response.StatusCode())
+
+	server = newServer()
+	server.MaxURLLength = 64
+	server.ParseErrorHandler = func(ctx *app.RequestContext, err error) {
+		assert.True(t, errors.Is(err, errs.ErrURLTooLong))
+		assert.DeepEqual(t, consts.StatusRequestURITooLong, ctx.Response.StatusCode())
+		ctx.Response.SetBodyString("custom body")
+	}
+	response = serve(server, request)
+	assert.DeepEqual(t, consts.StatusRequestURITooLong, response.StatusCode())
+	assert.DeepEqual(t, "custom body", string(response.Body()))
+
+	server = newServer()
+	server.MaxHeaderBytes = len(request)
+	server.MaxHeaderCount = 3
+	server.MaxURLLength = 65
+	response = serve(server, request)
+	assert.DeepEqual(t, consts.StatusOK, response.StatusCode())
+}
+
 func TestEventStack(t *testing.T) {
 	// Create a stack.
 	s := &eventStack{}
@@ -192,17 +579,23 @@ func TestEventStack(t *testing.T) {
 type mockCore struct {
 	ctxPool    *sync.Pool
 	controller tracer.Controller
+	handler    app.HandlerFunc
+	running    bool
 }
 
 func (m *mockCore) IsRunning() bool {
-	return false
+	return m.running
 }
 
 func (m *mockCore) GetCtxPool() *sync.Pool {
 	return m.ctxPool
 }
 
-func (m *mockCore) ServeHTTP(c context.Context, ctx *app.RequestContext) {}
+func (m *mockCore) ServeHTTP(c context.Context, ctx *app.RequestContext) {
+	if m.handler != nil {
+		m.handler(c, ctx)
+	}
+}
 
 func (m *mockCore) GetTracer() tracer.Controller {
 	return m.controller
@@ -221,3 +614,21 @@ type mockErrorWriter struct {
 func (errorWriter *mockErrorWriter) Flush() error {
 	return errors.New("error")
 }
+
+func TestServerTimeouts(t *testing.T) {
+	s := &Server{}
+	s.ReadTimeout = time.Second
+	s.IdleTimeout = time.Minute
+	assert.DeepEqual(t, time.Second, s.readTimeout())
+	assert.DeepEqual(t, time.Minute, s.idleTimeout())
+
+	s.Timeouts = config.NewTimeouts(time.Second, time.Minute)
+	s.Timeouts.SetRead(2 * time.Second)
+	s.Timeouts.SetIdle(2 * time.Minute)
+	assert.DeepEqual(t, 2*time.Second, s.readTimeout())
+	assert.DeepEqual(t, 2*time.Minute, s.idleTimeout())
+
+	// the special idle timeouts can not be changed
+	s.IdleTimeout = -1
+	assert.DeepEqual(t, time.Duration(-1), s.idleTimeout())
+}
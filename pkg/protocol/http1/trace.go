@@ -0,0 +1,180 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http1
+
+import (
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/tracer/clienttrace"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// requestTracer calls the hooks of the trace and records the timings of a request,
+// all of its methods are no-ops on the nil tracer.
+type requestTracer struct {
+	trace   *clienttrace.ClientTrace
+	timings clienttrace.Timings
+
+	start       time.Time
+	stageStart  time.Time
+	wroteHeader bool
+}
+
+func newRequestTracer(trace *clienttrace.ClientTrace) *requestTracer {
+	if trace == nil {
+		return nil
+	}
+	return &requestTracer{trace: trace, start: time.Now()}
+}
+
+func (t *requestTracer) getConn(hostPort string) {
+	if t == nil {
+		return
+	}
+	t.stageStart = time.Now()
+	if t.trace.GetConn != nil {
+		t.trace.GetConn(hostPort)
+	}
+}
+
+func (t *requestTracer) gotConn(reused bool) {
+	if t == nil {
+		return
+	}
+	t.timings.GetConn = time.Since(t.stageStart)
+	if t.trace.GotConn != nil {
+		t.trace.GotConn(reused)
+	}
+}
+
+func (t *requestTracer) dnsStart(host string) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	if t.trace.DNSStart != nil {
+		t.trace.DNSStart(host)
+	}
+	return time.Now()
+}
+
+func (t *requestTracer) dnsDone(start time.Time, addrs []string, err error) {
+	if t == nil {
+		return
+	}
+	t.timings.DNS = time.Since(start)
+	if t.trace.DNSDone != nil {
+		t.trace.DNSDone(addrs, err)
+	}
+}
+
+func (t *requestTracer) connectStart(network, addr string) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	if t.trace.ConnectStart != nil {
+		t.trace.ConnectStart(network, addr)
+	}
+	return time.Now()
+}
+
+func (t *requestTracer) connectDone(start time.Time, network, addr string, err error) {
+	if t == nil {
+		return
+	}
+	t.timings.Connect = time.Since(start)
+	if t.trace.ConnectDone != nil {
+		t.trace.ConnectDone(network, addr, err)
+	}
+}
+
+func (t *requestTracer) tlsHandshakeStart() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	if t.trace.TLSHandshakeStart != nil {
+		t.trace.TLSHandshakeStart()
+	}
+	return time.Now()
+}
+
+func (t *requestTracer) tlsHandshakeDone(start time.Time, err error) {
+	if t == nil {
+		return
+	}
+	t.timings.TLSHandshake = time.Since(start)
+	if t.trace.TLSHandshakeDone != nil {
+		t.trace.TLSHandshakeDone(err)
+	}
+}
+
+func (t *requestTracer) wroteHeaders() {
+	if t == nil || t.wroteHeader {
+		return
+	}
+	t.wroteHeader = true
+	if t.trace.WroteHeaders != nil {
+		t.trace.WroteHeaders()
+	}
+}
+
+func (t *requestTracer) wroteRequest(err error) {
+	if t == nil {
+		return
+	}
+	t.stageStart = time.Now()
+	if t.trace.WroteRequest != nil {
+		t.trace.WroteRequest(err)
+	}
+}
+
+func (t *requestTracer) gotFirstResponseByte() {
+	if t == nil {
+		return
+	}
+	t.timings.WaitResponse = time.Since(t.stageStart)
+	if t.trace.GotFirstResponseByte != nil {
+		t.trace.GotFirstResponseByte()
+	}
+}
+
+// writer wraps w to report the headers being written by the first write,
+// w is returned as is on the nil tracer.
+func (t *requestTracer) writer(w network.Writer) network.Writer {
+	if t == nil {
+		return w
+	}
+	t.wroteHeader = false
+	return &tracedWriter{Writer: w, t: t}
+}
+
+func (t *requestTracer) result() clienttrace.Timings {
+	t.timings.Total = time.Since(t.start)
+	return t.timings
+}
+
+type tracedWriter struct {
+	network.Writer
+	t *requestTracer
+}
+
+func (w *tracedWriter) WriteBinary(b []byte) (int, error) {
+	n, err := w.Writer.WriteBinary(b)
+	if err == nil {
+		w.t.wroteHeaders()
+	}
+	return n, err
+}
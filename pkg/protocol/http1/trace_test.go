@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/common/tracer/clienttrace"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestHostClientTrace(t *testing.T) {
+	c := &HostClient{
+		ClientOptions: &ClientOptions{
+			Dialer: newSlowConnDialer(func(network, addr string) (network.Conn, error) {
+				return mock.NewConn("HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok"), nil
+			}),
+		},
+		Addr: "foobar:80",
+	}
+
+	var events []string
+	trace := &clienttrace.ClientTrace{
+		GetConn: func(hostPort string) { events = append(events, "GetConn "+hostPort) },
+		GotConn: func(reused bool) {
+			if reused {
+				events = append(events, "GotConn reused")
+				return
+			}
+			events = append(events, "GotConn")
+		},
+		ConnectStart: func(network, addr string) { events = append(events, "ConnectStart "+network+" "+addr) },
+		ConnectDone: func(network, addr string, err error) {
+			assert.Nil(t, err)
+			events = append(events, "ConnectDone")
+		},
+		WroteHeaders: func() { events = append(events, "WroteHeaders") },
+		WroteRequest: func(err error) {
+			assert.Nil(t, err)
+			events = append(events, "WroteRequest")
+		},
+		GotFirstResponseByte: func() { events = append(events, "GotFirstResponseByte") },
+	}
+
+	req := protocol.AcquireRequest()
+	resp := protocol.AcquireResponse()
+	req.SetRequestURI("http://foobar/baz")
+	req.SetConnectionClose()
+	err := c.Do(clienttrace.WithClientTrace(context.Background(), trace), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "ok", string(resp.Body()))
+	assert.DeepEqual(t, []string{
+		"GetConn foobar:80", "ConnectStart tcp foobar:80", "ConnectDone", "GotConn",
+		"WroteHeaders", "WroteRequest", "GotFirstResponseByte",
+	}, events)
+
+	timings := resp.Timings()
+	assert.True(t, timings.Total > 0)
+	assert.True(t, timings.Total >= timings.GetConn+timings.WaitResponse)
+	assert.True(t, timings.GetConn >= timings.Connect)
+
+	// the timings are not recorded without the trace
+	err = c.Do(context.Background(), req, resp)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, clienttrace.Timings{}, resp.Timings())
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/common/errors"
+)
+
+// Bootstring parameters for punycode, see RFC 3492, section 5.
+const (
+	punycodeBase        int32 = 36
+	punycodeTMin        int32 = 1
+	punycodeTMax        int32 = 26
+	punycodeSkew        int32 = 38
+	punycodeDamp        int32 = 700
+	punycodeInitialBias int32 = 72
+	punycodeInitialN    int32 = 128
+
+	acePrefix      = "xn--"
+	maxLabelLength = 63
+)
+
+var (
+	errInvalidPunycode  = errors.NewPublic("invalid punycode")
+	errPunycodeOverflow = errors.NewPublic("punycode overflow")
+	errLabelTooLong     = errors.NewPublic("domain label is too long")
+)
+
+// hostToASCII converts the internationalized labels of host into punycode labels
+// prefixed with "xn--", and lowercases the others. The port and IPv6 literal are kept as is.
+//
+// NOTE:
+//
+//	Only lowercasing is applied to the labels before encoding,
+//	the full mapping of UTS #46 is not supported.
+func hostToASCII(host string) (string, error) {
+	return convertHost(host, func(label string) (string, error) {
+		if isASCII(label) {
+			return strings.ToLower(label), nil
+		}
+		encoded, err := punycodeEncode(strings.ToLower(label))
+		if err != nil {
+			return "", err
+		}
+		label = acePrefix + encoded
+		if len(label) > maxLabelLength {
+			return "", errLabelTooLong
+		}
+		return label, nil
+	})
+}
+
+// hostToUnicode converts the punycode labels of host prefixed with "xn--" into unicode.
+// The port and IPv6 literal are kept as is.
+func hostToUnicode(host string) (string, error) {
+	return convertHost(host, func(label string) (string, error) {
+		if len(label) < len(acePrefix) || !strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			return label, nil
+		}
+		return punycodeDecode(label[len(acePrefix):])
+	})
+}
+
+func convertHost(host string, convert func(label string) (string, error)) (string, error) {
+	if strings.HasPrefix(host, "[") {
+		return host, nil
+	}
+	port := ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		host, port = host[:i], host[i:]
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		converted, err := convert(label)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = converted
+	}
+	return strings.Join(labels, ".") + port, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punycodeEncode encodes s according to RFC 3492, section 6.3.
+func punycodeEncode(s string) (string, error) {
+	runes := []rune(s)
+	output := make([]byte, 0, len(s)+8)
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+	b := int32(len(output))
+	h := b
+	if b > 0 {
+		output = append(output, '-')
+	}
+
+	n, delta, bias := punycodeInitialN, int32(0), punycodeInitialBias
+	for h < int32(len(runes)) {
+		m := int32(math.MaxInt32)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if m-n > (math.MaxInt32-delta)/(h+1) {
+			return "", errPunycodeOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, r := range runes {
+			if r < n {
+				delta++
+				if delta < 0 {
+					return "", errPunycodeOverflow
+				}
+				continue
+			}
+			if r > n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output = append(output, punycodeEncodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			output = append(output, punycodeEncodeDigit(q))
+			bias = punycodeAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return string(output), nil
+}
+
+// punycodeDecode decodes s according to RFC 3492, section 6.2.
+func punycodeDecode(s string) (string, error) {
+	var output []rune
+	pos := 0
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		for j := 0; j < i; j++ {
+			if s[j] >= utf8.RuneSelf {
+				return "", errInvalidPunycode
+			}
+			output = append(output, rune(s[j]))
+		}
+		pos = i + 1
+	}
+
+	n, i, bias := punycodeInitialN, int32(0), punycodeInitialBias
+	for pos < len(s) {
+		oldI, w := i, int32(1)
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos == len(s) {
+				return "", errInvalidPunycode
+			}
+			digit, ok := punycodeDecodeDigit(s[pos])
+			pos++
+			if !ok {
+				return "", errInvalidPunycode
+			}
+			if digit > (math.MaxInt32-i)/w {
+				return "", errPunycodeOverflow
+			}
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > math.MaxInt32/(punycodeBase-t) {
+				return "", errPunycodeOverflow
+			}
+			w *= punycodeBase - t
+		}
+		x := int32(len(output) + 1)
+		bias = punycodeAdapt(i-oldI, x, oldI == 0)
+		if i/x > math.MaxInt32-n {
+			return "", errPunycodeOverflow
+		}
+		n += i / x
+		i %= x
+		if n > utf8.MaxRune || !utf8.ValidRune(n) {
+			return "", errInvalidPunycode
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+	return string(output), nil
+}
+
+func punycodeThreshold(k, bias int32) int32 {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	default:
+		return k - bias
+	}
+}
+
+func punycodeAdapt(delta, numPoints int32, firstTime bool) int32 {
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := int32(0)
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeEncodeDigit(d int32) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punycodeDecodeDigit(c byte) (int32, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return int32(c-'0') + 26, true
+	case 'A' <= c && c <= 'Z':
+		return int32(c - 'A'), true
+	case 'a' <= c && c <= 'z':
+		return int32(c - 'a'), true
+	}
+	return 0, false
+}
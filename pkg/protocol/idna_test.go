@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestPunycode(t *testing.T) {
+	for decoded, encoded := range map[string]string{
+		"bücher":  "bcher-kva",
+		"münchen": "mnchen-3ya",
+		"中国":      "fiqs8s",
+		"例え":      "r8jz45g",
+		"テスト":     "zckzah",
+		"abc":     "abc-",
+		// RFC 3492, section 7.1 (L)
+		"3年b組金八先生": "3b-ww4c5e180e575a65lsy2b",
+	} {
+		s, err := punycodeEncode(decoded)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, encoded, s)
+		s, err = punycodeDecode(encoded)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, decoded, s)
+	}
+
+	for _, s := range []string{"a-b!", "99999999999", "ü-abc"} {
+		_, err := punycodeDecode(s)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestHostIDNA(t *testing.T) {
+	for unicode, ascii := range map[string]string{
+		"bücher.example":      "xn--bcher-kva.example",
+		"münchen.de:8080":     "xn--mnchen-3ya.de:8080",
+		"例え.テスト":              "xn--r8jz45g.xn--zckzah",
+		"example.com":         "example.com",
+		"[::1]:8080":          "[::1]:8080",
+		"sub.xn--fiqs8s.test": "sub.xn--fiqs8s.test",
+	} {
+		s, err := hostToASCII(unicode)
+		assert.Nil(t, err)
+		assert.DeepEqual(t, ascii, s)
+	}
+
+	s, err := hostToASCII("BÜCHER.Example")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "xn--bcher-kva.example", s)
+
+	s, err = hostToUnicode("XN--bcher-kva.example:80")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "bücher.example:80", s)
+
+	_, err = hostToUnicode("xn--a-b!.example")
+	assert.NotNil(t, err)
+}
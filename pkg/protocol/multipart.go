@@ -49,7 +49,9 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	"github.com/cloudwego/hertz/pkg/common/utils"
@@ -74,6 +76,60 @@ func ReadMultipartForm(r io.Reader, boundary string, size, maxInMemoryFileSize i
 	return f, nil
 }
 
+// MultipartFormConfig is the policy of parsing multipart/form-data bodies.
+type MultipartFormConfig struct {
+	// MaxMemory is the max bytes of the file parts stored in memory,
+	// the rest are stored in temporary files.
+	// If MaxMemory<=0, consts.DefaultMaxInMemoryFileSize is used.
+	MaxMemory int64
+
+	// TempDir is the directory of temporary files.
+	// If TempDir is empty, os.TempDir() is used.
+	// NOTE:
+	//
+	//	TempDir takes effect since go1.20, before which mime/multipart always uses os.TempDir().
+	TempDir string
+
+	// FilePerm is the permission of temporary files.
+	// If FilePerm==0, the default 0600 is kept.
+	FilePerm os.FileMode
+}
+
+func readMultipartFormWithConfig(r io.Reader, boundary string, config *MultipartFormConfig) (*multipart.Form, error) {
+	maxMemory := config.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = consts.DefaultMaxInMemoryFileSize
+	}
+	mr := multipart.NewReader(r, boundary)
+	if config.TempDir != "" {
+		setUnexportedString(reflect.ValueOf(mr).Elem().FieldByName("tempDir"), config.TempDir)
+	}
+	f, err := mr.ReadForm(maxMemory)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read multipart/form-data body: %s", err)
+	}
+	if config.FilePerm != 0 {
+		for _, fhs := range f.File {
+			for _, fh := range fhs {
+				name := reflect.ValueOf(fh).Elem().FieldByName("tmpfile")
+				if name.IsValid() && name.Kind() == reflect.String && name.String() != "" {
+					if err = os.Chmod(name.String(), config.FilePerm); err != nil {
+						f.RemoveAll() //nolint:errcheck
+						return nil, fmt.Errorf("cannot change mode of multipart/form-data file: %s", err)
+					}
+				}
+			}
+		}
+	}
+	return f, nil
+}
+
+func setUnexportedString(v reflect.Value, s string) {
+	if v.IsValid() && v.Kind() == reflect.String && v.CanAddr() {
+		reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem().SetString(s)
+	}
+}
+
 // WriteMultipartForm writes the given multipart form f with the given
 // boundary to w.
 func WriteMultipartForm(w io.Writer, f *multipart.Form, boundary string) error {
@@ -186,6 +242,12 @@ func ParseMultipartForm(r io.Reader, request *Request, size, maxInMemoryFileSize
 	if err != nil {
 		return err
 	}
+	if request.formLimits != nil {
+		if err = request.formLimits.checkMultipartForm(m); err != nil {
+			m.RemoveAll() //nolint:errcheck
+			return err
+		}
+	}
 
 	request.multipartForm = m
 	return nil
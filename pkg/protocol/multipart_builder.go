@@ -0,0 +1,210 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MultipartBuilder builds the multipart/form-data body of the request part by part,
+// the parts are streamed while the request is sent instead of being assembled in memory,
+// and the files are not opened until their parts are sent. e.g.
+//
+//	req.Multipart().
+//		AddField("name", "hertz").
+//		AddFile("avatar", "/path/to/avatar.png").
+//		AddReader("data", "data.json", r)
+//
+// NOTE:
+//
+//	The body stream of the request is set by Multipart, the other body is reset.
+//	The Content-Length is set if the sizes of all parts are known, otherwise
+//	the body is sent in chunked transfer encoding.
+//	The errors of AddFile are returned when the request is sent.
+type MultipartBuilder struct {
+	req    *Request
+	stream *multipartBodyStream
+}
+
+type multipartPart struct {
+	header []byte
+	open   func() (io.Reader, error)
+	// size is -1 if unknown.
+	size int64
+}
+
+// Multipart returns the builder of the multipart/form-data body of the request.
+func (req *Request) Multipart() *MultipartBuilder {
+	if s, ok := req.bodyStream.(*multipartBodyStream); ok {
+		return &MultipartBuilder{req: req, stream: s}
+	}
+	var buf bytes.Buffer
+	s := &multipartBodyStream{buf: &buf, w: multipart.NewWriter(&buf)}
+	req.SetBodyStream(s, len(s.trailer()))
+	req.Header.SetContentTypeBytes([]byte(s.w.FormDataContentType()))
+	return &MultipartBuilder{req: req, stream: s}
+}
+
+// AddField adds the form field of name and value.
+func (b *MultipartBuilder) AddField(name, value string) *MultipartBuilder {
+	return b.add(CreateMultipartHeader(name, "", ""), func() (io.Reader, error) {
+		return strings.NewReader(value), nil
+	}, int64(len(value)))
+}
+
+// AddFile adds the file of path as the form file of name.
+func (b *MultipartBuilder) AddFile(name, path string) *MultipartBuilder {
+	info, err := os.Stat(path)
+	if err != nil {
+		b.stream.err = err
+		return b
+	}
+	fileName := filepath.Base(path)
+	return b.add(CreateMultipartHeader(name, fileName, fileContentType(fileName)), func() (io.Reader, error) {
+		return os.Open(path)
+	}, info.Size())
+}
+
+// AddReader adds the content of r as the form file of name and fileName.
+// r is closed after it is sent if it implements io.Closer.
+func (b *MultipartBuilder) AddReader(name, fileName string, r io.Reader) *MultipartBuilder {
+	size := int64(-1)
+	if l, ok := r.(interface{ Len() int }); ok {
+		size = int64(l.Len())
+	}
+	return b.add(CreateMultipartHeader(name, fileName, fileContentType(fileName)), func() (io.Reader, error) {
+		return r, nil
+	}, size)
+}
+
+func (b *MultipartBuilder) add(header textproto.MIMEHeader, open func() (io.Reader, error), size int64) *MultipartBuilder {
+	s := b.stream
+	if _, err := s.w.CreatePart(header); err != nil {
+		s.err = err
+		return b
+	}
+	s.parts = append(s.parts, multipartPart{
+		header: append([]byte(nil), s.buf.Bytes()...),
+		open:   open,
+		size:   size,
+	})
+	s.buf.Reset()
+	b.req.Header.SetContentLength(s.size())
+	return b
+}
+
+func fileContentType(fileName string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(fileName)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// multipartBodyStream reads the header and the content of each part in turn,
+// and the closing boundary at last.
+type multipartBodyStream struct {
+	buf   *bytes.Buffer
+	w     *multipart.Writer
+	parts []multipartPart
+	err   error
+
+	// pos is the index of the reader in the sequence of part headers and contents.
+	pos int
+	r   io.Reader
+}
+
+// trailer returns the closing boundary of the body.
+func (s *multipartBodyStream) trailer() []byte {
+	trailer := "--" + s.w.Boundary() + "--\r\n"
+	if len(s.parts) > 0 {
+		trailer = "\r\n" + trailer
+	}
+	return []byte(trailer)
+}
+
+// size returns the size of the body, or -1 if it is unknown.
+func (s *multipartBodyStream) size() int {
+	size := int64(len(s.trailer()))
+	for _, p := range s.parts {
+		if p.size < 0 {
+			return -1
+		}
+		size += int64(len(p.header)) + p.size
+	}
+	return int(size)
+}
+
+func (s *multipartBodyStream) Read(p []byte) (int, error) {
+	for s.err == nil {
+		if s.r == nil {
+			s.r, s.err = s.next()
+			continue
+		}
+		n, err := s.r.Read(p)
+		if err == io.EOF {
+			s.closeReader() //nolint:errcheck
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+	return 0, s.err
+}
+
+// next returns the reader following the current one.
+func (s *multipartBodyStream) next() (io.Reader, error) {
+	i, isContent := s.pos/2, s.pos%2 == 1
+	s.pos++
+	switch {
+	case i < len(s.parts) && !isContent:
+		return bytes.NewReader(s.parts[i].header), nil
+	case i < len(s.parts):
+		r, err := s.parts[i].open()
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	case i == len(s.parts) && !isContent:
+		return bytes.NewReader(s.trailer()), nil
+	}
+	return nil, io.EOF
+}
+
+func (s *multipartBodyStream) closeReader() error {
+	var err error
+	if c, ok := s.r.(io.Closer); ok {
+		err = c.Close()
+	}
+	s.r = nil
+	return err
+}
+
+func (s *multipartBodyStream) Close() error {
+	if s.r == nil {
+		return nil
+	}
+	return s.closeReader()
+}
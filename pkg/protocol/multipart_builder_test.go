@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocol
+
+import (
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestRequestMultipartBuilder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multipart")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.html")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("file content"), 0o644))
+
+	req := NewRequest("POST", "http://example.com/upload", nil)
+	req.Multipart().AddField("name", "hertz").AddFile("file", path)
+	req.Multipart().AddReader("data", "data.json", strings.NewReader(`{"a":1}`))
+	assert.True(t, req.IsBodyStream())
+
+	body, err := ioutil.ReadAll(req.BodyStream())
+	assert.Nil(t, err)
+	assert.DeepEqual(t, len(body), req.Header.ContentLength())
+
+	boundary := string(req.Header.MultipartFormBoundary())
+	form, err := multipart.NewReader(strings.NewReader(string(body)), boundary).ReadForm(1024)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []string{"hertz"}, form.Value["name"])
+
+	fh := form.File["file"][0]
+	assert.DeepEqual(t, "a.html", fh.Filename)
+	assert.DeepEqual(t, "text/html; charset=utf-8", fh.Header.Get("Content-Type"))
+	f, _ := fh.Open()
+	b, _ := ioutil.ReadAll(f)
+	assert.DeepEqual(t, "file content", string(b))
+
+	fh = form.File["data"][0]
+	assert.DeepEqual(t, "data.json", fh.Filename)
+	f, _ = fh.Open()
+	b, _ = ioutil.ReadAll(f)
+	assert.DeepEqual(t, `{"a":1}`, string(b))
+}
+
+func TestRequestMultipartBuilderUnknownSize(t *testing.T) {
+	req := NewRequest("POST", "http://example.com/upload", nil)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streamed")) //nolint:errcheck
+		pw.Close()
+	}()
+	req.Multipart().AddReader("data", "data.bin", pr)
+	assert.DeepEqual(t, -1, req.Header.ContentLength())
+
+	body, err := ioutil.ReadAll(req.BodyStream())
+	assert.Nil(t, err)
+	boundary := string(req.Header.MultipartFormBoundary())
+	form, err := multipart.NewReader(strings.NewReader(string(body)), boundary).ReadForm(1024)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "application/octet-stream", form.File["data"][0].Header.Get("Content-Type"))
+
+	req = NewRequest("POST", "http://example.com/upload", nil)
+	req.Multipart().AddField("name", "hertz").AddFile("file", "not-exist.txt")
+	_, err = ioutil.ReadAll(req.BodyStream())
+	assert.True(t, os.IsNotExist(err))
+}
@@ -235,3 +235,42 @@ Content-Type: application/json
 	_, err = MarshalMultipartForm(form, " ")
 	assert.NotNil(t, err)
 }
+
+func TestMultipartFormConfig(t *testing.T) {
+	t.Parallel()
+	s := strings.Replace(`--foo
+Content-Disposition: form-data; name="key"
+
+value
+--foo
+Content-Disposition: form-data; name="file"; filename="test.txt"
+Content-Type: text/plain
+
+`+strings.Repeat("a", 1024)+`
+--foo--
+`, "\n", "\r\n", -1)
+	dir := t.TempDir()
+	req := NewRequest("POST", "/upload", strings.NewReader(s))
+	req.Header.SetContentTypeBytes([]byte("multipart/form-data; boundary=foo"))
+	req.SetMultipartFormConfig(&MultipartFormConfig{MaxMemory: 1, TempDir: dir, FilePerm: 0o640})
+
+	form, err := req.MultipartForm()
+	assert.Nil(t, err)
+	defer form.RemoveAll()
+	assert.DeepEqual(t, []string{"value"}, form.Value["key"])
+
+	fh := form.File["file"][0]
+	f, err := fh.Open()
+	assert.Nil(t, err)
+	defer f.Close()
+	osFile, ok := f.(*os.File)
+	assert.True(t, ok)
+	stat, err := osFile.Stat()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, os.FileMode(0o640), stat.Mode().Perm())
+	assert.True(t, strings.HasPrefix(osFile.Name(), dir))
+
+	// the config is cleared by Reset
+	req.Reset()
+	assert.Nil(t, req.multipartFormConfig)
+}
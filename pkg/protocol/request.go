@@ -47,6 +47,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/url"
 	"strings"
@@ -59,14 +60,16 @@ import (
 	"github.com/cloudwego/hertz/pkg/common/compress"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/leakcheck"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
 
 var (
-	errMissingFile     = errors.NewPublic("http: no such file")
-	errNoMultipartForm = errors.NewPublic("request has no multipart/form-data Content-Type")
+	errMissingFile      = errors.NewPublic("http: no such file")
+	errNoMultipartForm  = errors.NewPublic("request has no multipart/form-data Content-Type")
+	errBodyReaderClosed = errors.NewPublic("read on closed body reader")
 
 	responseBodyPool bytebufferpool.Pool
 	requestBodyPool  bytebufferpool.Pool
@@ -101,6 +104,10 @@ type Request struct {
 
 	multipartForm         *multipart.Form
 	multipartFormBoundary string
+	multipartFormConfig   *MultipartFormConfig
+	formLimits            *FormLimits
+
+	chunkedStreamConfig *ChunkedStreamConfig
 
 	// Group bool members in order to reduce Request object size.
 	parsedURI      bool
@@ -205,6 +212,9 @@ func (req *Request) Reset() {
 	req.CloseBodyStream()
 
 	req.options = nil
+	req.multipartFormConfig = nil
+	req.formLimits = nil
+	req.chunkedStreamConfig = nil
 }
 
 func (req *Request) IsURIParsed() bool {
@@ -246,7 +256,11 @@ func (req *Request) MultipartForm() (*multipart.Form, error) {
 		} else if len(ce) > 0 {
 			return nil, fmt.Errorf("unsupported Content-Encoding: %q", ce)
 		}
-		f, err = ReadMultipartForm(bytes.NewReader(body), req.multipartFormBoundary, len(body), len(body))
+		if req.multipartFormConfig != nil {
+			f, err = readMultipartFormWithConfig(bytes.NewReader(body), req.multipartFormBoundary, req.multipartFormConfig)
+		} else {
+			f, err = ReadMultipartForm(bytes.NewReader(body), req.multipartFormBoundary, len(body), len(body))
+		}
 	} else {
 		bodyStream := req.bodyStream
 		if req.Header.contentLength > 0 {
@@ -261,14 +275,23 @@ func (req *Request) MultipartForm() (*multipart.Form, error) {
 			return nil, fmt.Errorf("unsupported Content-Encoding: %q", ce)
 		}
 
-		mr := multipart.NewReader(bodyStream, req.multipartFormBoundary)
-
-		f, err = mr.ReadForm(8 * 1024)
+		if req.multipartFormConfig != nil {
+			f, err = readMultipartFormWithConfig(bodyStream, req.multipartFormBoundary, req.multipartFormConfig)
+		} else {
+			mr := multipart.NewReader(bodyStream, req.multipartFormBoundary)
+			f, err = mr.ReadForm(8 * 1024)
+		}
 	}
 
 	if err != nil {
 		return nil, err
 	}
+	if req.formLimits != nil {
+		if err = req.formLimits.checkMultipartForm(f); err != nil {
+			f.RemoveAll() //nolint:errcheck
+			return nil, err
+		}
+	}
 	req.multipartForm = f
 	return f, nil
 }
@@ -425,6 +448,22 @@ func (req *Request) SetMultipartFormBoundary(b string) {
 	req.multipartFormBoundary = b
 }
 
+// SetMultipartFormConfig sets the policy of parsing the multipart form of the request,
+// it is usually called by a middleware to customize the policy for a route or a group.
+// NOTE:
+//
+//	It only takes effect if the multipart form has not been parsed yet, so the
+//	pre-parsing of server should be disabled by server.WithDisablePreParseMultipartForm.
+func (req *Request) SetMultipartFormConfig(config *MultipartFormConfig) {
+	req.multipartFormConfig = config
+}
+
+// SetQueryDialect sets the dialect used to parse the query args of the request,
+// nil means the default dialect.
+func (req *Request) SetQueryDialect(d *QueryDialect) {
+	req.uri.SetQueryDialect(d)
+}
+
 func (req *Request) MultipartFormBoundary() string {
 	return req.multipartFormBoundary
 }
@@ -545,6 +584,17 @@ func (req *Request) BasicAuth() (username, password string, ok bool) {
 	return parseBasicAuth(auth)
 }
 
+// ProxyBasicAuth is the same as BasicAuth, except that the credentials are parsed from
+// the Proxy-Authorization header, which is sent by the clients of a forward proxy.
+func (req *Request) ProxyBasicAuth() (username, password string, ok bool) {
+	auth := req.Header.Peek(consts.HeaderProxyAuthorization)
+	if auth == nil {
+		return
+	}
+
+	return parseBasicAuth(auth)
+}
+
 var prefix = []byte{'B', 'a', 's', 'i', 'c', ' '}
 
 // parseBasicAuth can parse an HTTP Basic Authorization string encrypted by base64.
@@ -628,6 +678,9 @@ func (req *Request) IsBodyStream() bool {
 	return req.bodyStream != nil && req.bodyStream != NoBody
 }
 
+// BodyStream returns the underlying body stream of the request, or NoBody if there is none.
+//
+// See also BodyReader, which is preferred by handlers.
 func (req *Request) BodyStream() io.Reader {
 	if req.bodyStream == nil {
 		req.bodyStream = NoBody
@@ -635,6 +688,51 @@ func (req *Request) BodyStream() io.Reader {
 	return req.bodyStream
 }
 
+// BodyReader returns an io.ReadCloser of the request body.
+//
+// If the body is streamed, e.g. the server is created with WithStreamBody(true),
+// the reader reads directly from the connection without buffering the whole body,
+// so that proxies and upload handlers can process large bodies with constant memory.
+// Otherwise the reader reads from the buffered body.
+//
+// NOTE:
+//
+//	Close discards the unread part of a streamed body. The server discards it as well
+//	after the handler returns, Close is useful to do it earlier, e.g. before a long response.
+//	It does not release the stream, which is done by the server.
+//	Read returns an error after Close. The reader must not be used after the request is released.
+func (req *Request) BodyReader() io.ReadCloser {
+	if req.IsBodyStream() {
+		return &requestBodyReader{r: req.bodyStream, drain: true}
+	}
+	return &requestBodyReader{r: bytes.NewReader(req.Body())}
+}
+
+type requestBodyReader struct {
+	r      io.Reader
+	drain  bool
+	closed bool
+}
+
+func (br *requestBodyReader) Read(p []byte) (int, error) {
+	if br.closed {
+		return 0, errBodyReaderClosed
+	}
+	return br.r.Read(p)
+}
+
+func (br *requestBodyReader) Close() error {
+	if br.closed {
+		return nil
+	}
+	br.closed = true
+	if !br.drain {
+		return nil
+	}
+	_, err := io.Copy(ioutil.Discard, br.r)
+	return err
+}
+
 // SetBodyStream sets request body stream and, optionally body size.
 //
 // If bodySize is >= 0, then the bodyStream must provide exactly bodySize bytes
@@ -647,6 +745,9 @@ func (req *Request) BodyStream() io.Reader {
 //
 // Note that GET and HEAD requests cannot have body.
 //
+// If bodySize < 0, the body is written in chunked transfer encoding without being
+// loaded into memory, see also SetChunkedStreamConfig.
+//
 // See also SetBodyStreamWriter.
 func (req *Request) SetBodyStream(bodyStream io.Reader, bodySize int) {
 	req.ResetBody()
@@ -654,6 +755,31 @@ func (req *Request) SetBodyStream(bodyStream io.Reader, bodySize int) {
 	req.Header.SetContentLength(bodySize)
 }
 
+// ChunkedStreamConfig is the policy of writing the body stream of unknown size
+// in chunked transfer encoding.
+type ChunkedStreamConfig struct {
+	// ChunkSize is the max bytes of each chunk, a chunk is written for every Read
+	// of the body stream which returns data.
+	// If ChunkSize<=0, 4KB is used.
+	ChunkSize int
+
+	// BufferChunks keeps the chunks in the write buffer of the connection until
+	// it is full, instead of flushing every chunk as soon as it is written.
+	// It saves syscalls for bulk uploads, but delays the chunks of slow streams.
+	BufferChunks bool
+}
+
+// SetChunkedStreamConfig sets the policy of writing the body stream of the request
+// in chunked transfer encoding, nil means every chunk of at most 4KB is flushed.
+func (req *Request) SetChunkedStreamConfig(config *ChunkedStreamConfig) {
+	req.chunkedStreamConfig = config
+}
+
+// ChunkedStreamConfig returns the policy set by SetChunkedStreamConfig.
+func (req *Request) ChunkedStreamConfig() *ChunkedStreamConfig {
+	return req.chunkedStreamConfig
+}
+
 func (req *Request) ConstructBodyStream(body *bytebufferpool.ByteBuffer, bodyStream io.Reader) {
 	req.body = body
 	req.bodyStream = bodyStream
@@ -822,8 +948,9 @@ func (req *Request) SetConnectionClose() {
 func AcquireRequest() *Request {
 	v := requestPool.Get()
 	if v == nil {
-		return &Request{}
+		v = &Request{}
 	}
+	leakcheck.Acquire(leakcheck.KindPooledObject, v)
 	return v.(*Request)
 }
 
@@ -832,6 +959,7 @@ func AcquireRequest() *Request {
 // It is forbidden accessing req and/or its members after returning
 // it to request pool.
 func ReleaseRequest(req *Request) {
+	leakcheck.Release(req)
 	req.Reset()
 	requestPool.Put(req)
 }
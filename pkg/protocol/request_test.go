@@ -51,10 +51,12 @@ import (
 	"mime/multipart"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	"github.com/cloudwego/hertz/pkg/common/compress"
 	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/leakcheck"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 )
 
@@ -672,6 +674,22 @@ func TestRequestBasicAuth(t *testing.T) {
 	}
 }
 
+func TestRequestProxyBasicAuth(t *testing.T) {
+	for _, tt := range BasicAuthTests {
+		req := NewRequest("CONNECT", "http://www.google.com:443", nil)
+		req.SetHeader("Proxy-Authorization", tt.header)
+		username, password, ok := req.ProxyBasicAuth()
+		if ok != tt.ok || username != tt.username || password != tt.password {
+			t.Fatalf("ProxyBasicAuth() = %+v, want %+v", getBasicAuthTest{username, password, ok},
+				getBasicAuthTest{tt.username, tt.password, tt.ok})
+		}
+	}
+	req := NewRequest("CONNECT", "http://www.google.com:443", nil)
+	req.SetBasicAuth("Aladdin", "open sesame")
+	_, _, ok := req.ProxyBasicAuth()
+	assert.False(t, ok)
+}
+
 // Issue: NewRequest should create a Request that doesn't use input parameters as its struct,
 // otherwise it will cause panic when we pass a const string as method to NewRequest and call req.SetMethod()
 func TestNewRequestWithConstParam(t *testing.T) {
@@ -709,6 +727,30 @@ func TestRequestGetBodyAfterGetBodyStream(t *testing.T) {
 	assert.DeepEqual(t, req.Body(), []byte("abc"))
 }
 
+func TestRequestBodyReader(t *testing.T) {
+	req := AcquireRequest()
+	defer ReleaseRequest(req)
+	req.SetBodyString("abc")
+	r := req.BodyReader()
+	b, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "abc", string(b))
+	assert.Nil(t, r.Close())
+	_, err = r.Read(b)
+	assert.DeepEqual(t, errBodyReaderClosed, err)
+
+	req.SetBodyStream(strings.NewReader("hello world"), -1)
+	r = req.BodyReader()
+	b = make([]byte, 5)
+	n, err := r.Read(b)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "hello", string(b[:n]))
+	assert.Nil(t, r.Close())
+	// the rest of the stream is drained by Close
+	n, _ = req.BodyStream().Read(b)
+	assert.DeepEqual(t, 0, n)
+}
+
 func TestRequestSetOptionsNotOverwrite(t *testing.T) {
 	req := AcquireRequest()
 	req.SetOptions(config.WithSD(true))
@@ -764,3 +806,20 @@ func TestReqSafeCopy(t *testing.T) {
 		assert.DeepEqual(t, []byte{byte(i)}, reqs[i].Body())
 	}
 }
+
+func TestAcquireRequestLeakCheck(t *testing.T) {
+	leakcheck.Enable(leakcheck.Options{PooledObjectThreshold: time.Nanosecond, CheckInterval: time.Hour})
+	defer leakcheck.Disable()
+
+	req := AcquireRequest()
+	resp := AcquireResponse()
+	time.Sleep(time.Millisecond)
+	leaks := leakcheck.Check()
+	assert.DeepEqual(t, 2, len(leaks))
+	assert.DeepEqual(t, "*protocol.Request", leaks[0].Type)
+	assert.DeepEqual(t, "*protocol.Response", leaks[1].Type)
+
+	ReleaseRequest(req)
+	ReleaseResponse(resp)
+	assert.DeepEqual(t, 0, len(leakcheck.Check()))
+}
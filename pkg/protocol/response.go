@@ -49,6 +49,8 @@ import (
 	"github.com/cloudwego/hertz/internal/nocopy"
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
 	"github.com/cloudwego/hertz/pkg/common/compress"
+	"github.com/cloudwego/hertz/pkg/common/leakcheck"
+	"github.com/cloudwego/hertz/pkg/common/tracer/clienttrace"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
 )
@@ -86,6 +88,15 @@ type Response struct {
 	// Use it for writing HEAD responses.
 	SkipBody bool
 
+	// bodyStreamDone are called once the body stream is done.
+	bodyStreamDone []func(written int64, err error)
+
+	// Content-Encoding header value before the body is decoded by DecompressBody.
+	originalContentEncoding []byte
+
+	// Timings of the stages of the request traced by clienttrace.
+	timings clienttrace.Timings
+
 	// Remote TCPAddr from concurrently net.Conn
 	raddr net.Addr
 	// Local TCPAddr from concurrently net.Conn
@@ -167,6 +178,8 @@ func (resp *Response) CopyToSkipBody(dst *Response) {
 	dst.Reset()
 	resp.Header.CopyTo(&dst.Header)
 	dst.SkipBody = resp.SkipBody
+	dst.originalContentEncoding = append(dst.originalContentEncoding[:0], resp.originalContentEncoding...)
+	dst.timings = resp.timings
 	dst.raddr = resp.raddr
 	dst.laddr = resp.laddr
 }
@@ -200,6 +213,45 @@ func (resp *Response) SetBodyStreamNoReset(bodyStream io.Reader, bodySize int) {
 	resp.Header.SetContentLength(bodySize)
 }
 
+// WrapBodyStream replaces the body stream with the one returned by wrap, so that
+// the bytes of the stream can be captured or transformed while they are written.
+// It is usually called by a middleware after the handlers set the body stream.
+//
+// bodySize is the size of the new stream, see SetBodyStream for details.
+//
+// NOTE:
+//
+//	It does nothing if the body is not a stream.
+//	The original stream is closed after the new one if they implement io.Closer.
+func (resp *Response) WrapBodyStream(wrap func(bodyStream io.Reader) io.Reader, bodySize int) {
+	if resp.bodyStream == nil {
+		return
+	}
+	resp.bodyStream = &wrappedBodyStream{
+		Reader: wrap(resp.bodyStream),
+		origin: resp.bodyStream,
+	}
+	resp.Header.SetContentLength(bodySize)
+}
+
+type wrappedBodyStream struct {
+	io.Reader
+	origin io.Reader
+}
+
+func (s *wrappedBodyStream) Close() error {
+	var err error
+	if c, ok := s.Reader.(io.Closer); ok {
+		err = c.Close()
+	}
+	if c, ok := s.origin.(io.Closer); ok && s.origin != s.Reader {
+		if e := c.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
 // BodyE returns response body.
 func (resp *Response) BodyE() ([]byte, error) {
 	if resp.bodyStream != nil {
@@ -261,6 +313,9 @@ func SwapResponseBody(a, b *Response) {
 func (resp *Response) Reset() {
 	resp.Header.Reset()
 	resp.resetSkipHeader()
+	resp.bodyStreamDone = nil
+	resp.originalContentEncoding = resp.originalContentEncoding[:0]
+	resp.timings = clienttrace.Timings{}
 	resp.SkipBody = false
 	resp.raddr = nil
 	resp.laddr = nil
@@ -330,6 +385,13 @@ func (resp *Response) ConnectionClose() bool {
 }
 
 func (resp *Response) CloseBodyStream() error {
+	return resp.CloseBodyStreamWithResult(0, nil)
+}
+
+// CloseBodyStreamWithResult closes the body stream like CloseBodyStream, and calls the callbacks
+// registered by OnBodyStreamDone with the result of writing the stream.
+// It is used by the protocol implementations after writing the stream.
+func (resp *Response) CloseBodyStreamWithResult(written int64, writeErr error) error {
 	if resp.bodyStream == nil {
 		return nil
 	}
@@ -338,9 +400,37 @@ func (resp *Response) CloseBodyStream() error {
 		err = bsc.Close()
 	}
 	resp.bodyStream = nil
+	if len(resp.bodyStreamDone) > 0 {
+		callbacks := resp.bodyStreamDone
+		resp.bodyStreamDone = nil
+		if writeErr == nil {
+			writeErr = err
+		}
+		for _, fn := range callbacks {
+			fn(written, writeErr)
+		}
+	}
 	return err
 }
 
+// OnBodyStreamDone registers fn, which is called once the body stream is done, i.e. it has been
+// written to the connection, or it is closed without being written, e.g. replaced by SetBody.
+// written is the number of body bytes written from the stream, and err is the error of writing
+// or closing the stream if any.
+// It is usually used by handlers to clean up resources and by access-log middleware to learn
+// how many bytes are sent.
+// NOTE:
+//
+//	fn is called at most once, and it is dropped if the response is reset before the body stream is set.
+func (resp *Response) OnBodyStreamDone(fn func(written int64, err error)) {
+	resp.bodyStreamDone = append(resp.bodyStreamDone, fn)
+}
+
+// HasBodyStreamDoneCallbacks returns true if any callback is registered by OnBodyStreamDone.
+func (resp *Response) HasBodyStreamDoneCallbacks() bool {
+	return len(resp.bodyStreamDone) > 0
+}
+
 func (resp *Response) BodyBuffer() *bytebufferpool.ByteBuffer {
 	if resp.body == nil {
 		resp.body = responseBodyPool.Get()
@@ -370,6 +460,17 @@ func (resp *Response) LocalAddr() net.Addr {
 	return resp.laddr
 }
 
+// Timings returns the timings of the request, which are recorded only if
+// the request is sent with the context carrying a clienttrace.ClientTrace.
+func (resp *Response) Timings() clienttrace.Timings {
+	return resp.timings
+}
+
+// SetTimings sets the timings of the request.
+func (resp *Response) SetTimings(timings clienttrace.Timings) {
+	resp.timings = timings
+}
+
 func (resp *Response) ParseNetAddr(conn network.Conn) {
 	resp.raddr = conn.RemoteAddr()
 	resp.laddr = conn.LocalAddr()
@@ -383,8 +484,9 @@ func (resp *Response) ParseNetAddr(conn network.Conn) {
 func AcquireResponse() *Response {
 	v := responsePool.Get()
 	if v == nil {
-		return &Response{}
+		v = &Response{}
 	}
+	leakcheck.Acquire(leakcheck.KindPooledObject, v)
 	return v.(*Response)
 }
 
@@ -393,6 +495,7 @@ func AcquireResponse() *Response {
 // It is forbidden accessing resp and/or its members after returning
 // it to response pool.
 func ReleaseResponse(resp *Response) {
+	leakcheck.Release(resp)
 	resp.Reset()
 	responsePool.Put(resp)
 }
@@ -44,8 +44,10 @@ package protocol
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
@@ -272,3 +274,62 @@ func TestRespSafeCopy(t *testing.T) {
 		assert.DeepEqual(t, []byte{byte(i)}, resps[i].Body())
 	}
 }
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestResponseWrapBodyStream(t *testing.T) {
+	t.Parallel()
+
+	var resp Response
+	// nothing to wrap
+	resp.SetBodyString("foo")
+	resp.WrapBodyStream(func(r io.Reader) io.Reader { return r }, -1)
+	assert.False(t, resp.IsBodyStream())
+	assert.DeepEqual(t, "foo", string(resp.Body()))
+
+	origin := &closeRecorder{Reader: strings.NewReader("hello world")}
+	resp.SetBodyStream(origin, 11)
+	var captured bytes.Buffer
+	resp.WrapBodyStream(func(r io.Reader) io.Reader {
+		return io.TeeReader(r, &captured)
+	}, -1)
+	assert.DeepEqual(t, -1, resp.Header.ContentLength())
+
+	var w bytes.Buffer
+	assert.Nil(t, resp.BodyWriteTo(&w))
+	assert.DeepEqual(t, "hello world", w.String())
+	assert.DeepEqual(t, "hello world", captured.String())
+	assert.True(t, origin.closed)
+}
+
+func TestResponseOnBodyStreamDone(t *testing.T) {
+	t.Parallel()
+
+	var resp Response
+	calls := 0
+	resp.OnBodyStreamDone(func(written int64, err error) {
+		calls++
+		assert.DeepEqual(t, int64(0), written)
+		assert.Nil(t, err)
+	})
+	assert.True(t, resp.HasBodyStreamDoneCallbacks())
+	origin := &closeRecorder{Reader: strings.NewReader("foo")}
+	resp.SetBodyStream(origin, -1)
+	// the stream is replaced without being written
+	resp.SetBodyString("bar")
+	assert.True(t, origin.closed)
+	assert.DeepEqual(t, 1, calls)
+	assert.False(t, resp.HasBodyStreamDoneCallbacks())
+
+	resp.OnBodyStreamDone(func(written int64, err error) {})
+	resp.Reset()
+	assert.False(t, resp.HasBodyStreamDoneCallbacks())
+}
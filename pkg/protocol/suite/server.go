@@ -17,13 +17,16 @@
 package suite
 
 import (
+	"bytes"
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/errors"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/common/tracer"
+	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/protocol"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
@@ -52,6 +55,38 @@ type Core interface {
 	GetTracer() tracer.Controller
 }
 
+// ConnTracker is optionally implemented by Core to track the connections
+// served by the protocol servers, e.g. to close the idle ones during shutdown.
+type ConnTracker interface {
+	// ConnIdle is called before waiting for the next request on the keep-alive conn.
+	// The conn should be closed if false is returned.
+	ConnIdle(conn network.Conn) bool
+	// ConnActive is called once the next request on the conn begins to arrive.
+	ConnActive(conn network.Conn)
+}
+
+// Sniffer is optionally implemented by the protocol.Server of a protocol added to the suite,
+// to serve the cleartext connections recognized by their leading bytes, e.g. the preface of the protocol.
+// The connections not recognized by any Sniffer are served by the HTTP1 server.
+//
+// NOTE:
+//
+//	The protocols negotiated by ALPN don't need to implement it, they are advertised
+//	in the TLS config once ALPN is enabled.
+type Sniffer interface {
+	// Sniff reports whether the connection beginning with b speaks the protocol.
+	// If more is true, Sniff is called again once more bytes arrive.
+	Sniff(b []byte) (match, more bool)
+}
+
+// SniffPrefix is the Sniff of the protocols beginning with prefix.
+func SniffPrefix(b, prefix []byte) (match, more bool) {
+	if len(b) >= len(prefix) {
+		return bytes.HasPrefix(b, prefix), false
+	}
+	return false, bytes.HasPrefix(prefix, b)
+}
+
 type ServerFactory interface {
 	New(core Core) (server protocol.Server, err error)
 }
@@ -85,6 +120,19 @@ func (c *coreWrapper) ServeHTTP(ctx context.Context, reqCtx *app.RequestContext)
 	c.Core.ServeHTTP(ctx, reqCtx)
 }
 
+func (c *coreWrapper) ConnIdle(conn network.Conn) bool {
+	if t, ok := c.Core.(ConnTracker); ok {
+		return t.ConnIdle(conn)
+	}
+	return true
+}
+
+func (c *coreWrapper) ConnActive(conn network.Conn) {
+	if t, ok := c.Core.(ConnTracker); ok {
+		t.ConnActive(conn)
+	}
+}
+
 // SetAltHeader will set response header "Alt-Svc" for the target protocol, altHeader will be the value of the header.
 // Protocols other than the target protocol will carry the altHeader in the request header.
 func (c *Config) SetAltHeader(target, altHeader string) {
@@ -117,6 +165,16 @@ func (c *Config) Get(name string) ServerFactory {
 	return c.configMap[name]
 }
 
+// Protocols returns the sorted names of the protocols added by ServerFactory.
+func (c *Config) Protocols() []string {
+	protocols := make([]string, 0, len(c.configMap))
+	for proto := range c.configMap {
+		protocols = append(protocols, proto)
+	}
+	sort.Strings(protocols)
+	return protocols
+}
+
 func (c *Config) Delete(protocol string) {
 	delete(c.configMap, protocol)
 }
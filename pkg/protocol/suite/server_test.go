@@ -0,0 +1,56 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package suite
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+func TestSniffPrefix(t *testing.T) {
+	prefix := []byte("PRI * HTTP/2.0")
+	for _, c := range []struct {
+		b           string
+		match, more bool
+	}{
+		{"PRI", false, true},
+		{"PRI * HTTP/2.0\r\n", true, false},
+		{"PRI * HTTP/2.0", true, false},
+		{"POST", false, false},
+		{"GET / HTTP/1.1\r\n", false, false},
+	} {
+		match, more := SniffPrefix([]byte(c.b), prefix)
+		assert.DeepEqual(t, c.match, match)
+		assert.DeepEqual(t, c.more, more)
+	}
+}
+
+func TestProtocols(t *testing.T) {
+	c := New()
+	assert.DeepEqual(t, []string{}, c.Protocols())
+	c.Add(HTTP2, mockServerFactory{})
+	c.Add(HTTP1, mockServerFactory{})
+	assert.DeepEqual(t, []string{HTTP2, HTTP1}, c.Protocols())
+}
+
+type mockServerFactory struct{}
+
+func (mockServerFactory) New(core Core) (protocol.Server, error) {
+	return nil, nil
+}
@@ -25,10 +25,24 @@ import (
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
 
+// LastChunk addresses the last chunk of size 0 when a chunk extension is added.
+const LastChunk = -1
+
+// ChunkExtension is an extension of a chunk in the chunked transfer coding,
+// see RFC 9112, section 7.1.1.
+type ChunkExtension struct {
+	// Chunk is the index of the chunk starting from 0, the last chunk of size 0 is counted as well.
+	Chunk int
+	Name  string
+	// Value is unquoted if it is a quoted-string on the wire.
+	Value string
+}
+
 type Trailer struct {
 	h                  []argsKV
 	bufKV              argsKV
 	disableNormalizing bool
+	chunkExtensions    []ChunkExtension
 }
 
 // Get returns trailer value for the given key.
@@ -128,6 +142,11 @@ func (t *Trailer) ResetSkipNormalize() {
 func (t *Trailer) Reset() {
 	t.disableNormalizing = false
 	t.ResetSkipNormalize()
+	t.resetChunkExtensions()
+}
+
+func (t *Trailer) resetChunkExtensions() {
+	t.chunkExtensions = t.chunkExtensions[:0]
 }
 
 func (t *Trailer) DisableNormalizing() {
@@ -144,6 +163,22 @@ func (t *Trailer) CopyTo(dst *Trailer) {
 
 	dst.disableNormalizing = t.disableNormalizing
 	dst.h = copyArgs(dst.h, t.h)
+	dst.chunkExtensions = append(dst.chunkExtensions, t.chunkExtensions...)
+}
+
+// ChunkExtensions returns the extensions of the chunks in order,
+// which are read from the chunked body, or added to be written by AddChunkExtension.
+func (t *Trailer) ChunkExtensions() []ChunkExtension {
+	return t.chunkExtensions
+}
+
+// AddChunkExtension adds the extension of name and value to the chunk of the given index,
+// it is written along with the chunk if the body is written in chunked transfer coding.
+//
+// LastChunk can be used to address the last chunk of size 0, since the number of chunks
+// is usually unknown before the body is written. If value is empty, only the name is written.
+func (t *Trailer) AddChunkExtension(chunk int, name, value string) {
+	t.chunkExtensions = append(t.chunkExtensions, ChunkExtension{Chunk: chunk, Name: name, Value: value})
 }
 
 func (t *Trailer) SetTrailers(trailers []byte) (err error) {
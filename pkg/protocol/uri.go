@@ -43,14 +43,20 @@ package protocol
 
 import (
 	"bytes"
+	"net"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/internal/bytestr"
 	"github.com/cloudwego/hertz/internal/nocopy"
+	"github.com/cloudwego/hertz/pkg/common/errors"
 )
 
+// strPercent25 is the percent-encoded delimiter of IPv6 zone id, see RFC 6874.
+var strPercent25 = []byte("%25")
+
 // AcquireURI returns an empty URI instance from the pool.
 //
 // Release the URI with ReleaseURI after the URI is no longer needed.
@@ -89,6 +95,8 @@ type URI struct {
 
 	DisablePathNormalizing bool
 
+	queryDialect *QueryDialect
+
 	fullURI    []byte
 	requestURI []byte
 
@@ -125,11 +133,20 @@ func (u *URI) CopyTo(dst *URI) {
 	u.queryArgs.CopyTo(&dst.queryArgs)
 	dst.parsedQueryArgs = u.parsedQueryArgs
 	dst.DisablePathNormalizing = u.DisablePathNormalizing
+	dst.queryDialect = u.queryDialect
 
 	// fullURI and requestURI shouldn't be copied, since they are created
 	// from scratch on each FullURI() and RequestURI() call.
 }
 
+// SetQueryDialect sets the dialect used to parse query args, nil means the default dialect.
+//
+// The dialect remains in effect for the following Parse until Reset is called.
+func (u *URI) SetQueryDialect(d *QueryDialect) {
+	u.queryDialect = d
+	u.parsedQueryArgs = false
+}
+
 // QueryArgs returns query args.
 func (u *URI) QueryArgs() *Args {
 	u.parseQueryArgs()
@@ -140,7 +157,7 @@ func (u *URI) parseQueryArgs() {
 	if u.parsedQueryArgs {
 		return
 	}
-	u.queryArgs.ParseBytes(u.queryString)
+	u.queryArgs.ParseBytesWithDialect(u.queryString, u.queryDialect)
 	u.parsedQueryArgs = true
 }
 
@@ -288,6 +305,7 @@ func (u *URI) Reset() {
 	u.queryArgs.Reset()
 	u.parsedQueryArgs = false
 	u.DisablePathNormalizing = false
+	u.queryDialect = nil
 
 	// There is no need in u.fullURI = u.fullURI[:0], since full uri
 	// is calculated on each call to FullURI().
@@ -306,13 +324,93 @@ func (u *URI) Host() []byte {
 // SetHost sets host for the uri.
 func (u *URI) SetHost(host string) {
 	u.host = append(u.host[:0], host...)
-	bytesconv.LowercaseBytes(u.host)
+	lowercaseHost(u.host)
 }
 
 // SetHostBytes sets host for the uri.
 func (u *URI) SetHostBytes(host []byte) {
 	u.host = append(u.host[:0], host...)
-	bytesconv.LowercaseBytes(u.host)
+	lowercaseHost(u.host)
+}
+
+// Hostname returns host part without port and the square brackets of IPv6 literal,
+// i.e. fe80::1%eth0 of http://[fe80::1%25eth0]:8080/foo .
+//
+// The percent-encoded delimiter of IPv6 zone id is decoded, so the returned value can be used for dialing.
+func (u *URI) Hostname() []byte {
+	host, _ := splitHostPort(u.host)
+	if n := bytes.Index(host, strPercent25); n >= 0 {
+		return append(append(host[:n:n], '%'), host[n+len(strPercent25):]...)
+	}
+	return host
+}
+
+// Port returns port part of the host, i.e. 8080 of http://[::1]:8080/foo .
+//
+// Empty slice is returned if the host doesn't contain port.
+func (u *URI) Port() []byte {
+	_, port := splitHostPort(u.host)
+	return port
+}
+
+func splitHostPort(host []byte) ([]byte, []byte) {
+	if len(host) > 0 && host[0] == '[' {
+		end := bytes.IndexByte(host, ']')
+		if end < 0 {
+			return host[1:], nil
+		}
+		if len(host) > end+1 && host[end+1] == ':' {
+			return host[1:end], host[end+2:]
+		}
+		return host[1:end], nil
+	}
+	if n := bytes.LastIndexByte(host, ':'); n >= 0 {
+		return host[:n], host[n+1:]
+	}
+	return host, nil
+}
+
+// ToASCII converts the internationalized host of the uri into ASCII,
+// e.g. bücher.example -> xn--bcher-kva.example, which is the form used on the wire.
+func (u *URI) ToASCII() error {
+	if isASCII(bytesconv.B2s(u.host)) {
+		return nil
+	}
+	host, err := hostToASCII(string(u.host))
+	if err != nil {
+		return err
+	}
+	u.host = append(u.host[:0], host...)
+	return nil
+}
+
+// ToUnicode converts the punycode labels of the uri host into unicode,
+// e.g. xn--bcher-kva.example -> bücher.example, which is the form displayed to users.
+func (u *URI) ToUnicode() error {
+	host, err := hostToUnicode(string(u.host))
+	if err != nil {
+		return err
+	}
+	u.host = append(u.host[:0], host...)
+	return nil
+}
+
+// IsHostEqual reports whether host refers to the same host as the uri,
+// regardless of the case, the punycode or unicode form and the trailing dot of the hosts.
+func (u *URI) IsHostEqual(host string) bool {
+	return normalizeHost(bytesconv.B2s(u.host)) == normalizeHost(host)
+}
+
+func normalizeHost(host string) string {
+	if ascii, err := hostToASCII(host); err == nil {
+		host = ascii
+	} else {
+		host = strings.ToLower(host)
+	}
+	if port := strings.LastIndexByte(host, ':'); port > 0 && !strings.HasSuffix(host, "]") {
+		return strings.TrimSuffix(host[:port], ".") + host[port:]
+	}
+	return strings.TrimSuffix(host, ".")
 }
 
 // LastPathSegment returns the last part of uri path after '/'.
@@ -374,7 +472,9 @@ func (u *URI) Parse(host, uri []byte) {
 }
 
 func (u *URI) parse(host, uri []byte, isTLS bool) {
+	queryDialect := u.queryDialect
 	u.Reset()
+	u.queryDialect = queryDialect
 
 	if stringContainsCTLByte(uri) {
 		return
@@ -392,7 +492,8 @@ func (u *URI) parse(host, uri []byte, isTLS bool) {
 		u.scheme = append(u.scheme[:0], bytestr.StrHTTPS...)
 	}
 
-	if n := bytes.Index(host, bytestr.StrAt); n >= 0 {
+	// userinfo may contain '@' which is not escaped, so the last one is the delimiter
+	if n := bytes.LastIndex(host, bytestr.StrAt); n >= 0 {
 		auth := host[:n]
 		host = host[n+1:]
 
@@ -406,7 +507,7 @@ func (u *URI) parse(host, uri []byte, isTLS bool) {
 	}
 
 	u.host = append(u.host, host...)
-	bytesconv.LowercaseBytes(u.host)
+	lowercaseHost(u.host)
 
 	b := uri
 	queryIndex := bytes.IndexByte(b, '?')
@@ -469,17 +570,31 @@ func splitHostURI(host, uri []byte) ([]byte, []byte, []byte) {
 	n += len(bytestr.StrSlashSlash)
 	uri = uri[n:]
 	n = bytes.IndexByte(uri, '/')
+	// A hack for bogus urls like foobar.com?a=b or foobar.com#a without
+	// slash after host.
+	if m := bytes.IndexAny(uri, "?#"); m >= 0 && (n < 0 || m < n) {
+		return scheme, uri[:m], uri[m:]
+	}
 	if n < 0 {
-		// A hack for bogus urls like foobar.com?a=b without
-		// slash after host.
-		if n = bytes.IndexByte(uri, '?'); n >= 0 {
-			return scheme, uri[:n], uri[n:]
-		}
 		return scheme, uri, bytestr.StrSlash
 	}
 	return scheme, uri[:n], uri[n:]
 }
 
+// lowercaseHost lowercases host except the zone id of IPv6 literal, which is case-sensitive.
+func lowercaseHost(host []byte) {
+	if len(host) > 0 && host[0] == '[' {
+		if n := bytes.IndexByte(host, '%'); n >= 0 {
+			bytesconv.LowercaseBytes(host[:n])
+			if end := bytes.IndexByte(host[n:], ']'); end >= 0 {
+				bytesconv.LowercaseBytes(host[n+end:])
+			}
+			return
+		}
+	}
+	bytesconv.LowercaseBytes(host)
+}
+
 func normalizePath(dst, src []byte) []byte {
 	dst = dst[:0]
 	dst = addLeadingSlash(dst, src)
@@ -669,6 +784,11 @@ func (u *URI) FullURI() []byte {
 	return u.fullURI
 }
 
+// ParseURI parses the fully qualified uriStr, i.e. with scheme and host, into URI.
+// http is assumed if scheme is omitted.
+//
+// The uriStr is parsed leniently, e.g. the invalid host is kept as it is.
+// Use ParseURIWithOptions to validate it.
 func ParseURI(uriStr string) *URI {
 	uri := &URI{}
 	uri.Parse(nil, []byte(uriStr))
@@ -676,6 +796,105 @@ func ParseURI(uriStr string) *URI {
 	return uri
 }
 
+// URIOptions customizes how ParseURIWithOptions normalizes the uri.
+type URIOptions struct {
+	// DisablePathNormalizing keeps the path as it is in RequestURI and FullURI.
+	DisablePathNormalizing bool
+
+	// HostToASCII converts the internationalized host into punycode, see URI.ToASCII.
+	HostToASCII bool
+
+	// StripDefaultPort removes the port of the host if it is the default one of the scheme,
+	// e.g. http://example.com:80/ -> http://example.com/ .
+	StripDefaultPort bool
+
+	// StripTrailingDot removes the trailing dot of the host,
+	// e.g. http://example.com./ -> http://example.com/ .
+	StripTrailingDot bool
+}
+
+// ParseURIWithOptions parses the fully qualified uriStr like ParseURI,
+// and normalizes it according to opts. An error is returned if the uri is invalid.
+//
+// The uriStr is in the form:
+//
+//	[scheme:]//[userinfo@]host[:port][/path][?query][#fragment]
+//
+// where host may be an IPv6 literal enclosed in square brackets with an optional zone id,
+// e.g. http://user:pass@[fe80::1%25eth0]:8080/foo?bar=baz#qux .
+// The userinfo is available via URI.Username and URI.Password,
+// and the fragment via URI.Hash.
+func ParseURIWithOptions(uriStr string, opts URIOptions) (*URI, error) {
+	if stringContainsCTLByte(bytesconv.S2b(uriStr)) {
+		return nil, errors.NewPublicf("invalid control character in uri %q", uriStr)
+	}
+	uri := ParseURI(uriStr)
+	if err := validateHost(uri.host); err != nil {
+		return nil, err
+	}
+	if opts.HostToASCII {
+		if err := uri.ToASCII(); err != nil {
+			return nil, err
+		}
+	}
+	host, port := splitHostPort(uri.host)
+	if opts.StripDefaultPort && len(port) > 0 && string(port) == defaultPort(uri.Scheme()) {
+		uri.host = uri.host[:len(uri.host)-len(port)-1]
+	}
+	if opts.StripTrailingDot && len(host) > 1 && host[len(host)-1] == '.' && uri.host[0] != '[' {
+		uri.host = append(uri.host[:len(host)-1], uri.host[len(host):]...)
+	}
+	uri.DisablePathNormalizing = opts.DisablePathNormalizing
+	return uri, nil
+}
+
+func defaultPort(scheme []byte) string {
+	switch string(scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// validateHost checks the IPv6 literal and port of host.
+func validateHost(host []byte) error {
+	hostname, port := splitHostPort(host)
+	if len(host) > 0 && host[0] == '[' {
+		end := bytes.IndexByte(host, ']')
+		if end < 0 || (end+1 < len(host) && host[end+1] != ':') {
+			return errors.NewPublicf("invalid IPv6 literal in host %q", host)
+		}
+		addr, zone := hostname, []byte(nil)
+		if n := bytes.IndexByte(hostname, '%'); n >= 0 {
+			addr, zone = hostname[:n], hostname[n+1:]
+			if bytes.HasPrefix(zone, strPercent25[1:]) {
+				zone = zone[2:]
+			}
+			if len(zone) == 0 {
+				return errors.NewPublicf("invalid IPv6 zone id in host %q", host)
+			}
+		}
+		if ip := net.ParseIP(bytesconv.B2s(addr)); ip == nil || bytes.IndexByte(addr, ':') < 0 {
+			return errors.NewPublicf("invalid IPv6 literal in host %q", host)
+		}
+	} else if bytes.ContainsAny(hostname, "[]") {
+		return errors.NewPublicf("invalid host %q", host)
+	}
+	if len(port) > 0 {
+		if len(port) > 5 {
+			return errors.NewPublicf("invalid port in host %q", host)
+		}
+		for _, c := range port {
+			if c < '0' || c > '9' {
+				return errors.NewPublicf("invalid port in host %q", host)
+			}
+		}
+	}
+	return nil
+}
+
 type Proxy func(*Request) (*URI, error)
 
 func ProxyURI(fixedURI *URI) Proxy {
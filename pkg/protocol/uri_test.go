@@ -126,6 +126,28 @@ func TestURI_QueryString(t *testing.T) {
 	assert.DeepEqual(t, expectQueryString2, queryString2)
 }
 
+func TestURIQueryDialect(t *testing.T) {
+	u := AcquireURI()
+	defer ReleaseURI(u)
+
+	u.SetQueryDialect(&QueryDialect{SemicolonSeparator: true})
+	u.Parse(nil, []byte("http://foobar.com/foo?a=1;b=2"))
+	assert.DeepEqual(t, "2", string(u.QueryArgs().Peek("b")))
+
+	u.SetQueryDialect(nil)
+	assert.DeepEqual(t, "1;b=2", string(u.QueryArgs().Peek("a")))
+
+	u.SetQueryDialect(&QueryDialect{SemicolonSeparator: true})
+	var dst URI
+	u.CopyTo(&dst)
+	dst.Parse(nil, []byte("http://foobar.com/foo?c=3;d=4"))
+	assert.DeepEqual(t, "4", string(dst.QueryArgs().Peek("d")))
+
+	u.Reset()
+	u.Parse(nil, []byte("http://foobar.com/foo?a=1;b=2"))
+	assert.DeepEqual(t, "1;b=2", string(u.QueryArgs().Peek("a")))
+}
+
 func TestURI_Path(t *testing.T) {
 	u := AcquireURI()
 	defer ReleaseURI(u)
@@ -468,3 +490,72 @@ func TestParseURI(t *testing.T) {
 	uri := string(ParseURI(expectURI).FullURI())
 	assert.DeepEqual(t, expectURI, uri)
 }
+
+func TestParseURIWithOptions(t *testing.T) {
+	uri, err := ParseURIWithOptions("http://user:p@ss@[FE80::1%25Eth0]:8080/foo?bar=baz#qux", URIOptions{})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "user", string(uri.Username()))
+	assert.DeepEqual(t, "p@ss", string(uri.Password()))
+	assert.DeepEqual(t, "[fe80::1%25Eth0]:8080", string(uri.Host()))
+	assert.DeepEqual(t, "fe80::1%Eth0", string(uri.Hostname()))
+	assert.DeepEqual(t, "8080", string(uri.Port()))
+	assert.DeepEqual(t, "/foo", string(uri.Path()))
+	assert.DeepEqual(t, "bar=baz", string(uri.QueryString()))
+	assert.DeepEqual(t, "qux", string(uri.Hash()))
+
+	uri, err = ParseURIWithOptions("http://[::1]/", URIOptions{})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "::1", string(uri.Hostname()))
+	assert.DeepEqual(t, "", string(uri.Port()))
+
+	uri, err = ParseURIWithOptions("https://example.com#frag", URIOptions{})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "example.com", string(uri.Host()))
+	assert.DeepEqual(t, "frag", string(uri.Hash()))
+
+	uri, err = ParseURIWithOptions("https://Bücher.Example.:443/a//b/../c", URIOptions{
+		DisablePathNormalizing: true,
+		HostToASCII:            true,
+		StripDefaultPort:       true,
+		StripTrailingDot:       true,
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "https://xn--bcher-kva.example/a//b/../c", uri.String())
+
+	uri, err = ParseURIWithOptions("http://example.com.:443/", URIOptions{StripDefaultPort: true, StripTrailingDot: true})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "example.com:443", string(uri.Host()))
+
+	for _, s := range []string{
+		"http://[::1/",
+		"http://[::1]x/",
+		"http://[example.com]/",
+		"http://[::1%25]/",
+		"http://example.com:80a/",
+		"http://exam[ple.com/",
+		"http://example.com/\x00",
+	} {
+		_, err = ParseURIWithOptions(s, URIOptions{})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestURIIDNA(t *testing.T) {
+	var u URI
+	u.Parse([]byte("bücher.example:8080"), []byte("/foo?a=b"))
+	assert.Nil(t, u.ToASCII())
+	assert.DeepEqual(t, "xn--bcher-kva.example:8080", string(u.Host()))
+	assert.DeepEqual(t, "http://xn--bcher-kva.example:8080/foo?a=b", u.String())
+	assert.True(t, u.IsHostEqual("BÜCHER.example.:8080"))
+	assert.True(t, u.IsHostEqual("xn--bcher-kva.example:8080"))
+	assert.False(t, u.IsHostEqual("bucher.example:8080"))
+	assert.False(t, u.IsHostEqual("bücher.example"))
+
+	assert.Nil(t, u.ToUnicode())
+	assert.DeepEqual(t, "bücher.example:8080", string(u.Host()))
+	assert.True(t, u.IsHostEqual("xn--bcher-kva.example:8080"))
+
+	u.SetHost("xn--a-b!.example")
+	assert.NotNil(t, u.ToUnicode())
+	assert.DeepEqual(t, "xn--a-b!.example", string(u.Host()))
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocket
+
+import (
+	"compress/flate"
+	"crypto/rand"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+)
+
+const (
+	minCompressionLevel     = flate.HuffmanOnly
+	maxCompressionLevel     = flate.BestCompression
+	defaultCompressionLevel = flate.BestSpeed
+
+	// deflateTail is the tail removed from the compressed messages, see RFC 7692, section 7.2.1.
+	deflateTail = "\x00\x00\xff\xff"
+	// finalBlock is appended to the compressed messages, so that the flate reader returns io.EOF.
+	finalBlock = "\x01\x00\x00\xff\xff"
+)
+
+var (
+	flateWriterPools [maxCompressionLevel - minCompressionLevel + 1]sync.Pool
+	flateReaderPool  = sync.Pool{New: func() interface{} {
+		return flate.NewReader(nil)
+	}}
+)
+
+func isValidCompressionLevel(level int) bool {
+	return minCompressionLevel <= level && level <= maxCompressionLevel
+}
+
+// compressData compresses data into a buffer, which should be released by releaseCompressBuffer.
+// The context is not taken over by the following messages.
+func compressData(level int, data []byte) (*bytebufferpool.ByteBuffer, error) {
+	buf := bytebufferpool.Get()
+	p := &flateWriterPools[level-minCompressionLevel]
+	fw, _ := p.Get().(*flate.Writer)
+	if fw == nil {
+		fw, _ = flate.NewWriter(buf, level)
+	} else {
+		fw.Reset(buf)
+	}
+	defer p.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		bytebufferpool.Put(buf)
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		bytebufferpool.Put(buf)
+		return nil, err
+	}
+	buf.B = buf.B[:len(buf.B)-len(deflateTail)]
+	return buf, nil
+}
+
+func releaseCompressBuffer(buf *bytebufferpool.ByteBuffer) {
+	bytebufferpool.Put(buf)
+}
+
+// decompressReader decompresses a message, the flate reader is recycled once it's read to the end.
+type decompressReader struct {
+	fr io.ReadCloser
+}
+
+func newDecompressReader(r io.Reader) io.Reader {
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	fr.(flate.Resetter).Reset(io.MultiReader(r, strings.NewReader(deflateTail+finalBlock)), nil) //nolint:errcheck
+	return &decompressReader{fr: fr}
+}
+
+func (r *decompressReader) Read(p []byte) (n int, err error) {
+	if r.fr == nil {
+		return 0, io.EOF
+	}
+	n, err = r.fr.Read(p)
+	if err == io.EOF {
+		r.fr.Close() //nolint:errcheck
+		flateReaderPool.Put(r.fr)
+		r.fr = nil
+	}
+	return n, err
+}
+
+// newMaskKey returns a random key to mask the frames sent by the client.
+func newMaskKey() [4]byte {
+	var key [4]byte
+	rand.Read(key[:]) //nolint:errcheck
+	return key
+}
@@ -0,0 +1,588 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// Conn is a WebSocket connection.
+//
+// NOTE:
+//
+//	At most one goroutine may call the read methods and at most one goroutine may call
+//	WriteMessage concurrently. WriteControl, WriteClose and Close can be called concurrently
+//	with all the other methods.
+type Conn struct {
+	conn        network.Conn
+	isServer    bool
+	subprotocol string
+
+	// write fields
+	writeMu          sync.Mutex
+	closeSent        bool
+	compress         bool
+	writeCompress    bool
+	compressionLevel int
+
+	// read fields
+	readErr       error
+	readLimit     int64
+	readLength    int64
+	readRemaining int64
+	readFinal     bool
+	// readCompressed reports whether the current message is compressed
+	readCompressed bool
+	readMaskKey    [4]byte
+	readMaskPos    int
+	reader         *messageReader
+
+	handlePing  func(appData string) error
+	handlePong  func(appData string) error
+	handleClose func(code int, text string) error
+}
+
+func newConn(conn network.Conn, isServer, compress bool) *Conn {
+	c := &Conn{
+		conn:             conn,
+		isServer:         isServer,
+		compress:         compress,
+		writeCompress:    compress,
+		compressionLevel: defaultCompressionLevel,
+		readFinal:        true,
+	}
+	c.SetPingHandler(nil)
+	c.SetPongHandler(nil)
+	c.SetCloseHandler(nil)
+	return c
+}
+
+// Subprotocol returns the subprotocol negotiated in the opening handshake.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// NetConn returns the underlying connection, which should not be read or written directly.
+func (c *Conn) NetConn() network.Conn {
+	return c.conn
+}
+
+// LocalAddr returns the local network address.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadTimeout sets the timeout of every read of the underlying connection, zero means no timeout.
+// The connection is broken once a read times out.
+func (c *Conn) SetReadTimeout(t time.Duration) error {
+	return c.conn.SetReadTimeout(t)
+}
+
+// SetWriteTimeout sets the timeout of every write of the underlying connection, zero means no timeout.
+// The connection is broken once a write times out.
+func (c *Conn) SetWriteTimeout(t time.Duration) error {
+	return c.conn.SetWriteTimeout(t)
+}
+
+// SetReadLimit sets the maximum size of the messages read from the peer, zero means no limit.
+// The size of a compressed message is counted after decompression.
+// ErrReadLimit is returned and the close message with CloseMessageTooBig is sent once the limit is exceeded.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// EnableWriteCompression enables or disables the compression of the following messages,
+// it does nothing if permessage-deflate is not negotiated.
+func (c *Conn) EnableWriteCompression(enable bool) {
+	c.writeCompress = enable
+}
+
+// SetCompressionLevel sets the flate compression level of the following messages,
+// see compress/flate for the levels.
+func (c *Conn) SetCompressionLevel(level int) error {
+	if !isValidCompressionLevel(level) {
+		return errors.New("websocket: invalid compression level")
+	}
+	c.compressionLevel = level
+	return nil
+}
+
+// SetPingHandler sets the handler of the ping messages received from the peer.
+// The default handler replies a pong message with the same application data.
+//
+// The handler is called in the read methods, and the error returned by it is returned by them.
+func (c *Conn) SetPingHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(appData string) error {
+			err := c.WriteControl(PongMessage, []byte(appData))
+			if err == ErrCloseSent {
+				return nil
+			}
+			return err
+		}
+	}
+	c.handlePing = h
+}
+
+// SetPongHandler sets the handler of the pong messages received from the peer.
+// The default handler does nothing.
+//
+// The handler is called in the read methods, and the error returned by it is returned by them.
+func (c *Conn) SetPongHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(string) error { return nil }
+	}
+	c.handlePong = h
+}
+
+// SetCloseHandler sets the handler of the close message received from the peer.
+// The default handler replies a close message with the same code, which completes
+// the closing handshake.
+//
+// The handler is called in the read methods, which return a *CloseError after it.
+func (c *Conn) SetCloseHandler(h func(code int, text string) error) {
+	if h == nil {
+		h = func(code int, text string) error {
+			err := c.WriteControl(CloseMessage, FormatCloseMessage(code, ""))
+			if err == ErrCloseSent {
+				return nil
+			}
+			return err
+		}
+	}
+	c.handleClose = h
+}
+
+// Close closes the underlying connection without sending the close message.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// WriteClose starts the closing handshake by sending the close message with code and text.
+// The read methods return a *CloseError once the close message of the peer is received,
+// after that the connection should be closed.
+func (c *Conn) WriteClose(code int, text string) error {
+	if len(text) > maxControlFramePayloadSize-2 {
+		text = text[:maxControlFramePayloadSize-2]
+	}
+	return c.WriteControl(CloseMessage, FormatCloseMessage(code, text))
+}
+
+// WriteControl writes a control message, data must not be longer than 125 bytes.
+// ErrCloseSent is returned once the close message is sent.
+func (c *Conn) WriteControl(messageType int, data []byte) error {
+	if !isControl(messageType) {
+		return errBadWriteOpCode
+	}
+	if len(data) > maxControlFramePayloadSize {
+		return errInvalidControlFrame
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	if messageType == CloseMessage {
+		c.closeSent = true
+	}
+	return c.writeFrame(messageType, false, data)
+}
+
+// WriteMessage writes a data message in a single frame, it's compressed if the compression
+// is negotiated and enabled. ErrCloseSent is returned once the close message is sent.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if isControl(messageType) {
+		return c.WriteControl(messageType, data)
+	}
+	if !isData(messageType) {
+		return errBadWriteOpCode
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	if c.compress && c.writeCompress {
+		buf, err := compressData(c.compressionLevel, data)
+		if err != nil {
+			return err
+		}
+		defer releaseCompressBuffer(buf)
+		return c.writeFrame(messageType, true, buf.B)
+	}
+	return c.writeFrame(messageType, false, data)
+}
+
+// writeFrame writes a final frame and flushes it, c.writeMu must be held.
+func (c *Conn) writeFrame(frameType int, compressed bool, payload []byte) error {
+	b0 := byte(frameType) | finalBit
+	if compressed {
+		b0 |= rsv1Bit
+	}
+	length := len(payload)
+	headerSize := 2
+	switch {
+	case length > 65535:
+		headerSize += 8
+	case length > 125:
+		headerSize += 2
+	}
+	if !c.isServer {
+		headerSize += 4
+	}
+
+	header, err := c.conn.Malloc(headerSize)
+	if err != nil {
+		return err
+	}
+	header[0] = b0
+	switch {
+	case length > 65535:
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	case length > 125:
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = byte(length)
+	}
+
+	if c.isServer {
+		if length > 0 {
+			if _, err = c.conn.WriteBinary(payload); err != nil {
+				return err
+			}
+		}
+		return c.conn.Flush()
+	}
+
+	// the frames sent by the client must be masked
+	header[1] |= maskBit
+	key := newMaskKey()
+	copy(header[headerSize-4:], key[:])
+	if length > 0 {
+		buf, err := c.conn.Malloc(length)
+		if err != nil {
+			return err
+		}
+		copy(buf, payload)
+		maskBytes(key, 0, buf)
+	}
+	return c.conn.Flush()
+}
+
+// ReadMessage reads the next data message, a *CloseError is returned once the close message
+// of the peer is received. The text messages must be valid UTF-8.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	messageType, r, err := c.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	p, err = ioutil.ReadAll(r)
+	if err == nil && messageType == TextMessage && !utf8.Valid(p) {
+		err = c.fail(CloseInvalidFramePayloadData, errInvalidUTF8)
+	}
+	return messageType, p, err
+}
+
+// NextReader returns the next data message, the unread part of the previous message is discarded.
+// The control messages are handled while reading, see SetPingHandler, SetPongHandler and SetCloseHandler.
+//
+// Once an error is returned, the connection is broken and the following calls return the same error.
+func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
+	if c.reader != nil {
+		c.reader.c = nil
+		c.reader = nil
+	}
+	for c.readErr == nil {
+		var compressed bool
+		messageType, compressed, c.readErr = c.advanceFrame()
+		if c.readErr != nil {
+			break
+		}
+		if isData(messageType) {
+			c.reader = &messageReader{c: c}
+			r = c.reader
+			if compressed {
+				r = newDecompressReader(r)
+			}
+			return messageType, &limitReader{c: c, r: r}, nil
+		}
+	}
+	return 0, nil, c.readErr
+}
+
+// advanceFrame discards the rest of the current frame and reads the header of the next frame,
+// the control frames are read and handled as well.
+func (c *Conn) advanceFrame() (frameType int, compressed bool, err error) {
+	if c.readRemaining > 0 {
+		if err = c.discard(c.readRemaining); err != nil {
+			return 0, false, err
+		}
+		c.readRemaining = 0
+	}
+
+	p, err := c.conn.Peek(2)
+	if err != nil {
+		return 0, false, c.readError(err)
+	}
+	final := p[0]&finalBit != 0
+	rsv1 := p[0]&rsv1Bit != 0
+	frameType = int(p[0] & 0xf)
+	masked := p[1]&maskBit != 0
+	length := int64(p[1] & 0x7f)
+	if p[0]&(rsv2Bit|rsv3Bit) != 0 {
+		return 0, false, c.fail(CloseProtocolError, errors.New("websocket: unexpected reserved bits"))
+	}
+	if err = c.conn.Skip(2); err != nil {
+		return 0, false, c.readError(err)
+	}
+
+	switch frameType {
+	case CloseMessage, PingMessage, PongMessage:
+		if length > maxControlFramePayloadSize || !final || rsv1 {
+			return 0, false, c.fail(CloseProtocolError, errInvalidControlFrame)
+		}
+	case TextMessage, BinaryMessage:
+		if !c.readFinal {
+			return 0, false, c.fail(CloseProtocolError, errors.New("websocket: data frame before the final frame of the previous message"))
+		}
+		if rsv1 && !c.compress {
+			return 0, false, c.fail(CloseProtocolError, errors.New("websocket: unexpected compressed frame"))
+		}
+		c.readFinal = final
+		c.readCompressed = rsv1
+		c.readLength = 0
+	case continuationFrame:
+		if c.readFinal {
+			return 0, false, c.fail(CloseProtocolError, errors.New("websocket: continuation frame without message"))
+		}
+		if rsv1 {
+			return 0, false, c.fail(CloseProtocolError, errors.New("websocket: compressed continuation frame"))
+		}
+		c.readFinal = final
+	default:
+		return 0, false, c.fail(CloseProtocolError, errors.New("websocket: unknown opcode "+strconv.Itoa(frameType)))
+	}
+	if masked != c.isServer {
+		return 0, false, c.fail(CloseProtocolError, errors.New("websocket: bad frame masking"))
+	}
+
+	switch length {
+	case 126:
+		if p, err = c.conn.Peek(2); err != nil {
+			return 0, false, c.readError(err)
+		}
+		length = int64(binary.BigEndian.Uint16(p))
+		err = c.conn.Skip(2)
+	case 127:
+		if p, err = c.conn.Peek(8); err != nil {
+			return 0, false, c.readError(err)
+		}
+		length = int64(binary.BigEndian.Uint64(p))
+		if length < 0 {
+			return 0, false, c.fail(CloseProtocolError, errors.New("websocket: invalid frame length"))
+		}
+		err = c.conn.Skip(8)
+	}
+	if err != nil {
+		return 0, false, c.readError(err)
+	}
+	if masked {
+		if p, err = c.conn.Peek(4); err != nil {
+			return 0, false, c.readError(err)
+		}
+		copy(c.readMaskKey[:], p)
+		if err = c.conn.Skip(4); err != nil {
+			return 0, false, c.readError(err)
+		}
+	}
+	c.readMaskPos = 0
+	c.readRemaining = length
+	c.conn.Release() //nolint:errcheck
+
+	if frameType == continuationFrame || isData(frameType) {
+		// the size of the uncompressed messages can be checked ahead
+		if c.readLimit > 0 && !c.readCompressed && c.readLength+length > c.readLimit {
+			return 0, false, c.tooLarge()
+		}
+		return frameType, rsv1, nil
+	}
+
+	payload, err := c.conn.ReadBinary(int(length))
+	if err != nil {
+		return 0, false, c.readError(err)
+	}
+	c.conn.Release() //nolint:errcheck
+	c.readRemaining = 0
+	if masked {
+		maskBytes(c.readMaskKey, 0, payload)
+	}
+
+	switch frameType {
+	case PingMessage:
+		err = c.handlePing(string(payload))
+	case PongMessage:
+		err = c.handlePong(string(payload))
+	case CloseMessage:
+		closeErr, perr := parseCloseMessage(payload)
+		if perr != nil {
+			code := CloseProtocolError
+			if perr == errInvalidUTF8 {
+				code = CloseInvalidFramePayloadData
+			}
+			return 0, false, c.fail(code, perr)
+		}
+		if err = c.handleClose(closeErr.Code, closeErr.Text); err == nil {
+			err = closeErr
+		}
+	}
+	return frameType, false, err
+}
+
+// discard discards the next n bytes of the payload.
+func (c *Conn) discard(n int64) error {
+	for n > 0 {
+		if _, err := c.conn.Peek(1); err != nil {
+			return c.readError(err)
+		}
+		k := int64(c.conn.Len())
+		if k > n {
+			k = n
+		}
+		if err := c.conn.Skip(int(k)); err != nil {
+			return c.readError(err)
+		}
+		c.conn.Release() //nolint:errcheck
+		n -= k
+	}
+	return nil
+}
+
+// readPayload reads the payload of the current frame into p, it waits only for the first byte.
+func (c *Conn) readPayload(p []byte) (int, error) {
+	if _, err := c.conn.Peek(1); err != nil {
+		return 0, c.readError(err)
+	}
+	n := c.conn.Len()
+	if n > len(p) {
+		n = len(p)
+	}
+	if int64(n) > c.readRemaining {
+		n = int(c.readRemaining)
+	}
+	b, err := c.conn.Peek(n)
+	if err != nil {
+		return 0, c.readError(err)
+	}
+	copy(p, b)
+	if err = c.conn.Skip(n); err != nil {
+		return 0, c.readError(err)
+	}
+	c.conn.Release() //nolint:errcheck
+	if c.isServer {
+		c.readMaskPos = maskBytes(c.readMaskKey, c.readMaskPos, p[:n])
+	}
+	c.readRemaining -= int64(n)
+	return n, nil
+}
+
+// readError converts the EOF of the underlying connection to CloseAbnormalClosure.
+func (c *Conn) readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &CloseError{Code: CloseAbnormalClosure, Text: io.ErrUnexpectedEOF.Error()}
+	}
+	return err
+}
+
+// fail sends the close message with code and breaks the connection with err.
+func (c *Conn) fail(code int, err error) error {
+	c.WriteClose(code, err.Error()) //nolint:errcheck
+	c.readErr = err
+	return err
+}
+
+func (c *Conn) tooLarge() error {
+	return c.fail(CloseMessageTooBig, ErrReadLimit)
+}
+
+// messageReader reads the payload of a data message across its frames.
+type messageReader struct {
+	c *Conn
+}
+
+func (r *messageReader) Read(p []byte) (int, error) {
+	c := r.c
+	if c == nil {
+		return 0, io.EOF
+	}
+	for c.readErr == nil {
+		if c.readRemaining > 0 {
+			if len(p) == 0 {
+				return 0, nil
+			}
+			n, err := c.readPayload(p)
+			if err != nil {
+				c.readErr = err
+			}
+			return n, err
+		}
+		if c.readFinal {
+			r.c = nil
+			c.reader = nil
+			return 0, io.EOF
+		}
+		if _, _, c.readErr = c.advanceFrame(); c.readErr != nil {
+			break
+		}
+	}
+	return 0, c.readErr
+}
+
+// limitReader counts the bytes of the message against the read limit.
+type limitReader struct {
+	c *Conn
+	r io.Reader
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	c := r.c
+	c.readLength += int64(n)
+	if c.readLimit > 0 && c.readLength > c.readLimit {
+		if c.readErr == nil {
+			c.tooLarge() //nolint:errcheck
+		}
+		return 0, ErrReadLimit
+	}
+	return n, err
+}
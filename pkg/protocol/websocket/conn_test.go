@@ -0,0 +1,248 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocket
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// written returns the bytes written to conn.
+func written(conn *mock.Conn) []byte {
+	zr := conn.WriterRecorder()
+	var b []byte
+	for {
+		if _, err := zr.Peek(1); err != nil {
+			return b
+		}
+		p, _ := zr.ReadBinary(zr.Len())
+		b = append(b, p...)
+	}
+}
+
+// clientFrames returns the frames written by a client with fn.
+func clientFrames(compress bool, fn func(c *Conn)) string {
+	conn := mock.NewConn("")
+	fn(newConn(conn, false, compress))
+	return string(written(conn))
+}
+
+// serverConn returns a server reading frames, the reads time out once they are consumed.
+func serverConn(frames string, compress bool) (*Conn, *mock.Conn) {
+	conn := mock.NewConn(frames)
+	conn.SetReadTimeout(10 * time.Millisecond) //nolint:errcheck
+	return newConn(conn, true, compress), conn
+}
+
+// readFrame reads a frame written by the server.
+func readFrame(t *testing.T, r network.Reader) (frameType int, payload []byte) {
+	h, err := r.ReadBinary(2)
+	assert.Nil(t, err)
+	assert.True(t, h[0]&finalBit != 0)
+	assert.True(t, h[1]&maskBit == 0)
+	n := int(h[1])
+	switch n {
+	case 126:
+		b, _ := r.ReadBinary(2)
+		n = int(binary.BigEndian.Uint16(b))
+	case 127:
+		b, _ := r.ReadBinary(8)
+		n = int(binary.BigEndian.Uint64(b))
+	}
+	payload, err = r.ReadBinary(n)
+	assert.Nil(t, err)
+	return int(h[0] & 0xf), payload
+}
+
+func TestConnReadWriteMessage(t *testing.T) {
+	large := strings.Repeat("a", 70000)
+	for _, compress := range []bool{false, true} {
+		frames := clientFrames(compress, func(c *Conn) {
+			assert.Nil(t, c.WriteMessage(TextMessage, []byte("hello")))
+			assert.Nil(t, c.WriteMessage(BinaryMessage, []byte{}))
+			assert.Nil(t, c.WriteMessage(BinaryMessage, []byte(large)))
+		})
+		c, conn := serverConn(frames, compress)
+
+		mt, p, err := c.ReadMessage()
+		assert.Nil(t, err)
+		assert.DeepEqual(t, TextMessage, mt)
+		assert.DeepEqual(t, "hello", string(p))
+		mt, p, err = c.ReadMessage()
+		assert.Nil(t, err)
+		assert.DeepEqual(t, BinaryMessage, mt)
+		assert.DeepEqual(t, 0, len(p))
+		mt, p, err = c.ReadMessage()
+		assert.Nil(t, err)
+		assert.DeepEqual(t, BinaryMessage, mt)
+		assert.DeepEqual(t, large, string(p))
+
+		assert.Nil(t, c.WriteMessage(TextMessage, []byte(large)))
+		frameType, payload := readFrame(t, conn.WriterRecorder())
+		assert.DeepEqual(t, TextMessage, frameType)
+		if compress {
+			assert.True(t, len(payload) < len(large))
+			r := newDecompressReader(bytes.NewReader(payload))
+			b := make([]byte, len(large)+1)
+			n, _ := r.Read(b)
+			for m := n; m > 0; n += m {
+				m, _ = r.Read(b[n:])
+			}
+			assert.DeepEqual(t, large, string(b[:n]))
+		} else {
+			assert.DeepEqual(t, large, string(payload))
+		}
+	}
+}
+
+func TestConnFragmentedMessage(t *testing.T) {
+	// "hello" in two fragments with a ping in between, see RFC 6455, section 5.7
+	frames := "\x01\x83\x00\x00\x00\x00hel" + "\x89\x82\x00\x00\x00\x00hi" + "\x80\x82\x01\x02\x03\x04" + string([]byte{'l' ^ 1, 'o' ^ 2})
+	c, conn := serverConn(frames, false)
+	mt, p, err := c.ReadMessage()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, TextMessage, mt)
+	assert.DeepEqual(t, "hello", string(p))
+
+	// the ping is replied by the default handler
+	frameType, payload := readFrame(t, conn.WriterRecorder())
+	assert.DeepEqual(t, PongMessage, frameType)
+	assert.DeepEqual(t, "hi", string(payload))
+}
+
+func TestConnControlHandlers(t *testing.T) {
+	frames := clientFrames(false, func(c *Conn) {
+		assert.Nil(t, c.WriteControl(PingMessage, []byte("ping")))
+		assert.Nil(t, c.WriteControl(PongMessage, []byte("pong")))
+		assert.Nil(t, c.WriteMessage(TextMessage, []byte("data")))
+	})
+	c, _ := serverConn(frames, false)
+	var handled []string
+	c.SetPingHandler(func(appData string) error {
+		handled = append(handled, "ping "+appData)
+		return nil
+	})
+	c.SetPongHandler(func(appData string) error {
+		handled = append(handled, "pong "+appData)
+		return nil
+	})
+	_, p, err := c.ReadMessage()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "data", string(p))
+	assert.DeepEqual(t, []string{"ping ping", "pong pong"}, handled)
+
+	assert.DeepEqual(t, errInvalidControlFrame, c.WriteControl(PingMessage, make([]byte, 126)))
+	assert.DeepEqual(t, errBadWriteOpCode, c.WriteControl(TextMessage, nil))
+}
+
+func TestConnCloseHandshake(t *testing.T) {
+	frames := clientFrames(false, func(c *Conn) {
+		assert.Nil(t, c.WriteMessage(TextMessage, []byte("bye")))
+		assert.Nil(t, c.WriteClose(CloseGoingAway, "going away"))
+		assert.DeepEqual(t, ErrCloseSent, c.WriteMessage(TextMessage, []byte("bye")))
+	})
+	c, conn := serverConn(frames, false)
+	_, p, err := c.ReadMessage()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "bye", string(p))
+
+	_, _, err = c.ReadMessage()
+	assert.True(t, IsCloseError(err, CloseGoingAway))
+	assert.DeepEqual(t, "websocket: close 1001: going away", err.Error())
+	// the error is sticky
+	_, _, err2 := c.NextReader()
+	assert.DeepEqual(t, err, err2)
+
+	// the close message is echoed by the default handler
+	frameType, payload := readFrame(t, conn.WriterRecorder())
+	assert.DeepEqual(t, CloseMessage, frameType)
+	assert.DeepEqual(t, FormatCloseMessage(CloseGoingAway, ""), payload)
+	assert.DeepEqual(t, ErrCloseSent, c.WriteMessage(TextMessage, []byte("bye")))
+}
+
+func TestConnReadLimit(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		frames := clientFrames(compress, func(c *Conn) {
+			assert.Nil(t, c.WriteMessage(TextMessage, []byte("short")))
+			assert.Nil(t, c.WriteMessage(TextMessage, bytes.Repeat([]byte{'a'}, 1024)))
+		})
+		c, conn := serverConn(frames, compress)
+		c.SetReadLimit(512)
+		_, p, err := c.ReadMessage()
+		assert.Nil(t, err)
+		assert.DeepEqual(t, "short", string(p))
+		_, _, err = c.ReadMessage()
+		assert.DeepEqual(t, ErrReadLimit, err)
+
+		frameType, payload := readFrame(t, conn.WriterRecorder())
+		assert.DeepEqual(t, CloseMessage, frameType)
+		assert.DeepEqual(t, CloseMessageTooBig, int(binary.BigEndian.Uint16(payload)))
+	}
+}
+
+func TestConnProtocolErrors(t *testing.T) {
+	for _, tc := range []struct {
+		frames string
+		code   int
+	}{
+		// unmasked frame from the client
+		{"\x81\x02hi", CloseProtocolError},
+		// fragmented control frame
+		{"\x09\x80\x00\x00\x00\x00", CloseProtocolError},
+		// continuation frame without message
+		{"\x80\x80\x00\x00\x00\x00", CloseProtocolError},
+		// reserved bits
+		{"\xa1\x80\x00\x00\x00\x00", CloseProtocolError},
+		// compressed frame without negotiation
+		{"\xc1\x80\x00\x00\x00\x00", CloseProtocolError},
+		// unknown opcode
+		{"\x83\x80\x00\x00\x00\x00", CloseProtocolError},
+		// invalid close code
+		{"\x88\x82\x00\x00\x00\x00\x03\xed", CloseProtocolError},
+		// invalid UTF-8 text
+		{"\x81\x81\x00\x00\x00\x00\xff", CloseInvalidFramePayloadData},
+	} {
+		c, conn := serverConn(tc.frames, false)
+		_, _, err := c.ReadMessage()
+		assert.NotNil(t, err)
+		frameType, payload := readFrame(t, conn.WriterRecorder())
+		assert.DeepEqual(t, CloseMessage, frameType)
+		assert.DeepEqual(t, tc.code, int(binary.BigEndian.Uint16(payload)))
+	}
+}
+
+func TestConnAbnormalClosure(t *testing.T) {
+	c := newConn(&eofConn{mock.NewConn("")}, true, false)
+	_, _, err := c.ReadMessage()
+	assert.True(t, IsCloseError(err, CloseAbnormalClosure))
+}
+
+type eofConn struct {
+	*mock.Conn
+}
+
+func (c *eofConn) Peek(n int) ([]byte, error) {
+	return nil, io.EOF
+}
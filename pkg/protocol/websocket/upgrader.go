@@ -0,0 +1,240 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocket
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	version           = "13"
+	acceptKeyGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	permessageDeflate = "permessage-deflate"
+)
+
+// Handler handles the upgraded connection, which is closed once it returns.
+type Handler func(conn *Conn)
+
+// HandshakeError describes an error of the opening handshake.
+type HandshakeError struct {
+	message string
+}
+
+func (e HandshakeError) Error() string { return e.message }
+
+// Upgrader upgrades the HTTP/1.1 connections to the WebSocket protocol.
+type Upgrader struct {
+	// Subprotocols are the protocols supported by the server in the order of preference.
+	// The first one requested by the client is selected.
+	Subprotocols []string
+
+	// CheckOrigin reports whether the Origin of the request is acceptable.
+	// If it is nil, the requests without Origin or with the same host as Host are accepted.
+	CheckOrigin func(ctx *app.RequestContext) bool
+
+	// EnableCompression negotiates the permessage-deflate extension if it's offered by the client.
+	EnableCompression bool
+
+	// ReadLimit is the maximum size of the messages read from the peer, zero means no limit.
+	// It can be changed by Conn.SetReadLimit.
+	ReadLimit int64
+
+	// Error writes the response if the handshake fails.
+	// If it is nil, the response is written with the status code and its text.
+	Error func(ctx *app.RequestContext, status int, reason error)
+}
+
+// IsWebSocketUpgrade reports whether the request is a WebSocket opening handshake.
+func IsWebSocketUpgrade(ctx *app.RequestContext) bool {
+	return tokenListContains(ctx.Request.Header.PeekAll(consts.HeaderConnection), "upgrade") &&
+		tokenListContains(ctx.Request.Header.PeekAll(consts.HeaderUpgrade), "websocket")
+}
+
+// Upgrade validates the opening handshake and prepares the response of it.
+// Once the response is written, the connection is hijacked and served by handler
+// in the goroutine of the request.
+//
+// If the handshake fails, the error response is written and a HandshakeError is returned.
+//
+// NOTE:
+//
+//	The response must not be modified after Upgrade returns without error,
+//	the hijacked connection is not tracked by the server, see app.RequestContext.Hijack.
+func (u *Upgrader) Upgrade(ctx *app.RequestContext, handler Handler) error {
+	if !ctx.IsGet() {
+		return u.fail(ctx, consts.StatusMethodNotAllowed, "websocket: the request method is not GET")
+	}
+	if !tokenListContains(ctx.Request.Header.PeekAll(consts.HeaderConnection), "upgrade") {
+		return u.fail(ctx, consts.StatusBadRequest, "websocket: 'upgrade' token not found in 'Connection' header")
+	}
+	if !tokenListContains(ctx.Request.Header.PeekAll(consts.HeaderUpgrade), "websocket") {
+		return u.fail(ctx, consts.StatusBadRequest, "websocket: 'websocket' token not found in 'Upgrade' header")
+	}
+	if string(ctx.Request.Header.Peek(consts.HeaderSecWebSocketVersion)) != version {
+		ctx.Response.Header.Set(consts.HeaderSecWebSocketVersion, version)
+		return u.fail(ctx, consts.StatusUpgradeRequired, "websocket: unsupported version: 13 not found in 'Sec-Websocket-Version' header")
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = checkSameOrigin
+	}
+	if !checkOrigin(ctx) {
+		return u.fail(ctx, consts.StatusForbidden, "websocket: request origin not allowed by Upgrader.CheckOrigin")
+	}
+	key := ctx.Request.Header.Peek(consts.HeaderSecWebSocketKey)
+	if !IsValidChallengeKey(key) {
+		return u.fail(ctx, consts.StatusBadRequest, "websocket: not a websocket handshake: 'Sec-WebSocket-Key' header must be Base64 encoded value of 16-byte in length")
+	}
+
+	subprotocol := u.selectSubprotocol(ctx)
+	compress := u.EnableCompression && offersDeflate(ctx.Request.Header.PeekAll(consts.HeaderSecWebSocketExtensions))
+
+	ctx.SetStatusCode(consts.StatusSwitchingProtocols)
+	ctx.Response.Header.Set(consts.HeaderUpgrade, "websocket")
+	ctx.Response.Header.Set(consts.HeaderConnection, "Upgrade")
+	ctx.Response.Header.Set(consts.HeaderSecWebSocketAccept, ComputeAcceptKey(key))
+	if subprotocol != "" {
+		ctx.Response.Header.Set(consts.HeaderSecWebSocketProtocol, subprotocol)
+	}
+	if compress {
+		ctx.Response.Header.Set(consts.HeaderSecWebSocketExtensions, permessageDeflate+"; server_no_context_takeover; client_no_context_takeover")
+	}
+
+	readLimit := u.ReadLimit
+	ctx.Hijack(func(c network.Conn) {
+		conn := newConn(c, true, compress)
+		conn.subprotocol = subprotocol
+		conn.SetReadLimit(readLimit)
+		handler(conn)
+	})
+	return nil
+}
+
+func (u *Upgrader) fail(ctx *app.RequestContext, status int, reason string) error {
+	err := HandshakeError{message: reason}
+	if u.Error != nil {
+		u.Error(ctx, status, err)
+	} else {
+		ctx.AbortWithMsg(consts.StatusMessage(status), status)
+	}
+	return err
+}
+
+func (u *Upgrader) selectSubprotocol(ctx *app.RequestContext) string {
+	if len(u.Subprotocols) == 0 {
+		return ""
+	}
+	requested := parseTokenList(ctx.Request.Header.PeekAll(consts.HeaderSecWebSocketProtocol))
+	for _, s := range u.Subprotocols {
+		for _, r := range requested {
+			if s == r {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// checkSameOrigin accepts the requests without Origin or with the same host as Host.
+func checkSameOrigin(ctx *app.RequestContext) bool {
+	origin := ctx.Request.Header.Peek(consts.HeaderOrigin)
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(string(origin))
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, string(ctx.Request.Host()))
+}
+
+// IsValidChallengeKey reports whether key is a valid Sec-WebSocket-Key, which is the
+// base64-encoded value of 16 bytes.
+func IsValidChallengeKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(key))
+	return err == nil && len(decoded) == 16
+}
+
+// ComputeAcceptKey returns the Sec-WebSocket-Accept of the challenge key, e.g. to verify
+// the response of the handshake forwarded by a proxy.
+func ComputeAcceptKey(key []byte) string {
+	h := sha1.New()
+	h.Write(key)                   //nolint:errcheck
+	h.Write([]byte(acceptKeyGUID)) //nolint:errcheck
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// offersDeflate reports whether permessage-deflate is offered with the parameters which
+// can be accepted. The window of the server can not be limited by server_max_window_bits.
+func offersDeflate(values [][]byte) bool {
+	for _, v := range values {
+		for _, ext := range strings.Split(string(v), ",") {
+			params := strings.Split(ext, ";")
+			if strings.TrimSpace(params[0]) != permessageDeflate {
+				continue
+			}
+			ok := true
+			for _, p := range params[1:] {
+				name := strings.TrimSpace(p)
+				if i := strings.IndexByte(name, '='); i >= 0 {
+					name = strings.TrimSpace(name[:i])
+				}
+				switch name {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				default:
+					ok = false
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseTokenList(values [][]byte) []string {
+	var tokens []string
+	for _, v := range values {
+		for _, t := range bytes.Split(v, []byte{','}) {
+			if t = bytes.TrimSpace(t); len(t) > 0 {
+				tokens = append(tokens, string(t))
+			}
+		}
+	}
+	return tokens
+}
+
+func tokenListContains(values [][]byte, token string) bool {
+	for _, t := range parseTokenList(values) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newHandshakeContext() *app.RequestContext {
+	ctx := app.NewContext(0)
+	ctx.Request.SetMethod(consts.MethodGet)
+	ctx.Request.SetRequestURI("http://example.com/ws")
+	ctx.Request.Header.Set(consts.HeaderConnection, "keep-alive, Upgrade")
+	ctx.Request.Header.Set(consts.HeaderUpgrade, "websocket")
+	ctx.Request.Header.Set(consts.HeaderSecWebSocketVersion, "13")
+	ctx.Request.Header.Set(consts.HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ==")
+	return ctx
+}
+
+func TestUpgrade(t *testing.T) {
+	ctx := newHandshakeContext()
+	ctx.Request.Header.Set(consts.HeaderSecWebSocketProtocol, "chat, superchat")
+	ctx.Request.Header.Set(consts.HeaderSecWebSocketExtensions, "permessage-deflate; client_max_window_bits")
+	assert.True(t, IsWebSocketUpgrade(ctx))
+
+	u := Upgrader{Subprotocols: []string{"superchat", "chat"}, EnableCompression: true}
+	assert.Nil(t, u.Upgrade(ctx, func(conn *Conn) {}))
+	assert.True(t, ctx.Hijacked())
+	assert.DeepEqual(t, consts.StatusSwitchingProtocols, ctx.Response.StatusCode())
+	assert.DeepEqual(t, "websocket", string(ctx.Response.Header.Peek(consts.HeaderUpgrade)))
+	assert.DeepEqual(t, "Upgrade", string(ctx.Response.Header.Peek(consts.HeaderConnection)))
+	// the example of RFC 6455, section 1.3
+	assert.DeepEqual(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", string(ctx.Response.Header.Peek(consts.HeaderSecWebSocketAccept)))
+	assert.DeepEqual(t, "superchat", string(ctx.Response.Header.Peek(consts.HeaderSecWebSocketProtocol)))
+	assert.DeepEqual(t, "permessage-deflate; server_no_context_takeover; client_no_context_takeover",
+		string(ctx.Response.Header.Peek(consts.HeaderSecWebSocketExtensions)))
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		modify func(ctx *app.RequestContext)
+		status int
+	}{
+		{func(ctx *app.RequestContext) { ctx.Request.SetMethod(consts.MethodPost) }, consts.StatusMethodNotAllowed},
+		{func(ctx *app.RequestContext) { ctx.Request.Header.Set(consts.HeaderConnection, "keep-alive") }, consts.StatusBadRequest},
+		{func(ctx *app.RequestContext) { ctx.Request.Header.Set(consts.HeaderUpgrade, "h2c") }, consts.StatusBadRequest},
+		{func(ctx *app.RequestContext) { ctx.Request.Header.Set(consts.HeaderSecWebSocketVersion, "8") }, consts.StatusUpgradeRequired},
+		{func(ctx *app.RequestContext) { ctx.Request.Header.Set(consts.HeaderSecWebSocketKey, "short") }, consts.StatusBadRequest},
+		{func(ctx *app.RequestContext) { ctx.Request.Header.Set(consts.HeaderOrigin, "http://evil.com") }, consts.StatusForbidden},
+	} {
+		ctx := newHandshakeContext()
+		tc.modify(ctx)
+		u := Upgrader{}
+		err := u.Upgrade(ctx, func(conn *Conn) {})
+		_, ok := err.(HandshakeError)
+		assert.True(t, ok)
+		assert.False(t, ctx.Hijacked())
+		assert.DeepEqual(t, tc.status, ctx.Response.StatusCode())
+	}
+
+	// the same origin is accepted by default
+	ctx := newHandshakeContext()
+	ctx.Request.Header.Set(consts.HeaderOrigin, "https://example.com")
+	u := Upgrader{}
+	assert.Nil(t, u.Upgrade(ctx, func(conn *Conn) {}))
+	// compression is not negotiated unless enabled
+	assert.DeepEqual(t, 0, len(ctx.Response.Header.Peek(consts.HeaderSecWebSocketExtensions)))
+}
+
+func TestOffersDeflate(t *testing.T) {
+	assert.True(t, offersDeflate([][]byte{[]byte("permessage-deflate")}))
+	assert.True(t, offersDeflate([][]byte{[]byte("foo, permessage-deflate; client_no_context_takeover; client_max_window_bits=10")}))
+	assert.False(t, offersDeflate([][]byte{[]byte("permessage-deflate; server_max_window_bits=10")}))
+	assert.False(t, offersDeflate([][]byte{[]byte("x-webkit-deflate-frame")}))
+	assert.False(t, offersDeflate(nil))
+}
+
+func TestUpgradeServe(t *testing.T) {
+	for i, opt := range []config.Option{
+		server.WithTransporter(server.TransporterNetpoll),
+		server.WithTransport(standard.NewTransporter),
+	} {
+		addr := []string{"127.0.0.1:9242", "127.0.0.1:9243"}[i]
+		h := server.New(server.WithHostPorts(addr), opt)
+		u := Upgrader{EnableCompression: true}
+		h.GET("/echo", func(c context.Context, ctx *app.RequestContext) {
+			u.Upgrade(ctx, func(conn *Conn) { //nolint:errcheck
+				for {
+					mt, p, err := conn.ReadMessage()
+					if err != nil {
+						return
+					}
+					if err = conn.WriteMessage(mt, p); err != nil {
+						return
+					}
+				}
+			})
+		})
+		go h.Spin()
+		time.Sleep(200 * time.Millisecond)
+
+		conn, err := standard.NewDialer().DialConnection("tcp", addr, time.Second, nil)
+		assert.Nil(t, err)
+		_, err = conn.Write([]byte("GET /echo HTTP/1.1\r\nHost: " + addr + "\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+			"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Extensions: permessage-deflate\r\n\r\n"))
+		assert.Nil(t, err)
+		var header []byte
+		for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+			b, err := conn.ReadByte()
+			assert.Nil(t, err)
+			header = append(header, b)
+		}
+		assert.True(t, strings.HasPrefix(string(header), "HTTP/1.1 101 Switching Protocols\r\n"))
+		assert.True(t, strings.Contains(strings.ToLower(string(header)), "sec-websocket-accept: s3pplmbitxaq9kygzzhzrbk+xoo=\r\n"))
+
+		c := newConn(conn, false, true)
+		for _, msg := range []string{"hello", strings.Repeat("hertz", 10000)} {
+			assert.Nil(t, c.WriteMessage(TextMessage, []byte(msg)))
+			mt, p, err := c.ReadMessage()
+			assert.Nil(t, err)
+			assert.DeepEqual(t, TextMessage, mt)
+			assert.DeepEqual(t, msg, string(p))
+		}
+
+		// the closing handshake is completed by the server
+		assert.Nil(t, c.WriteClose(CloseNormalClosure, ""))
+		_, _, err = c.ReadMessage()
+		assert.True(t, IsCloseError(err, CloseNormalClosure))
+		c.Close()
+		h.Close()
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package websocket implements the WebSocket protocol defined in RFC 6455 on top of
+// the hertz network abstraction, so that it works with netpoll as well as the standard
+// transport, e.g.
+//
+//	upgrader := websocket.Upgrader{EnableCompression: true}
+//	h.GET("/echo", func(c context.Context, ctx *app.RequestContext) {
+//		err := upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
+//			for {
+//				mt, msg, err := conn.ReadMessage()
+//				if err != nil {
+//					return
+//				}
+//				if err = conn.WriteMessage(mt, msg); err != nil {
+//					return
+//				}
+//			}
+//		})
+//		if err != nil {
+//			hlog.CtxErrorf(c, "upgrade error=%v", err)
+//		}
+//	})
+//
+// The compression extension permessage-deflate defined in RFC 7692 is supported
+// without context takeover.
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The message types defined in RFC 6455, section 11.8.
+const (
+	// TextMessage denotes a text data message, the payload is UTF-8 encoded text.
+	TextMessage = 1
+	// BinaryMessage denotes a binary data message.
+	BinaryMessage = 2
+	// CloseMessage denotes a close control message, the payload is a status code
+	// and an optional reason, see FormatCloseMessage.
+	CloseMessage = 8
+	// PingMessage denotes a ping control message.
+	PingMessage = 9
+	// PongMessage denotes a pong control message.
+	PongMessage = 10
+)
+
+// The close codes defined in RFC 6455, section 11.7.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+	CloseServiceRestart          = 1012
+	CloseTryAgainLater           = 1013
+	CloseTLSHandshake            = 1015
+)
+
+const (
+	continuationFrame = 0
+
+	finalBit = 1 << 7
+	rsv1Bit  = 1 << 6
+	rsv2Bit  = 1 << 5
+	rsv3Bit  = 1 << 4
+	maskBit  = 1 << 7
+
+	maxFrameHeaderSize         = 2 + 8 + 4
+	maxControlFramePayloadSize = 125
+)
+
+var (
+	// ErrCloseSent is returned by the write methods once the close message is sent.
+	ErrCloseSent = errors.New("websocket: close sent")
+	// ErrReadLimit is returned when a message is larger than the read limit.
+	ErrReadLimit = errors.New("websocket: read limit exceeded")
+
+	errBadWriteOpCode      = errors.New("websocket: bad write message type")
+	errInvalidControlFrame = errors.New("websocket: invalid control frame")
+	errInvalidCloseCode    = errors.New("websocket: invalid close code")
+	errInvalidUTF8         = errors.New("websocket: invalid UTF-8 text")
+)
+
+// CloseError is returned by the read methods once the close message is received.
+type CloseError struct {
+	// Code is CloseNoStatusReceived if the close message carries no status code.
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	s := "websocket: close " + strconv.Itoa(e.Code)
+	if e.Text != "" {
+		s += ": " + e.Text
+	}
+	return s
+}
+
+// IsCloseError reports whether err is a *CloseError with one of the codes.
+func IsCloseError(err error, codes ...int) bool {
+	var e *CloseError
+	if !errors.As(err, &e) {
+		return false
+	}
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatCloseMessage formats the payload of a close message, which is empty
+// for CloseNoStatusReceived.
+func FormatCloseMessage(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return []byte{}
+	}
+	buf := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(buf, uint16(code))
+	copy(buf[2:], text)
+	return buf
+}
+
+// parseCloseMessage parses the payload of a close message received from the peer.
+func parseCloseMessage(payload []byte) (*CloseError, error) {
+	if len(payload) == 0 {
+		return &CloseError{Code: CloseNoStatusReceived}, nil
+	}
+	if len(payload) == 1 {
+		return nil, errInvalidControlFrame
+	}
+	e := &CloseError{
+		Code: int(binary.BigEndian.Uint16(payload)),
+		Text: string(payload[2:]),
+	}
+	if !isValidReceivedCloseCode(e.Code) {
+		return nil, errInvalidCloseCode
+	}
+	if !utf8.ValidString(e.Text) {
+		return nil, errInvalidUTF8
+	}
+	return e, nil
+}
+
+// isValidReceivedCloseCode reports whether code can be sent in a close message, see RFC 6455, section 7.4.
+func isValidReceivedCloseCode(code int) bool {
+	switch code {
+	case 1004, CloseNoStatusReceived, CloseAbnormalClosure, CloseTLSHandshake:
+		return false
+	}
+	return (code >= 1000 && code <= 1014) || (code >= 3000 && code <= 4999)
+}
+
+func isControl(messageType int) bool {
+	return messageType == CloseMessage || messageType == PingMessage || messageType == PongMessage
+}
+
+func isData(messageType int) bool {
+	return messageType == TextMessage || messageType == BinaryMessage
+}
+
+// maskBytes masks b with key from the position pos of the payload and returns the next position.
+func maskBytes(key [4]byte, pos int, b []byte) int {
+	for i := range b {
+		b[i] ^= key[pos&3]
+		pos++
+	}
+	return pos & 3
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package route
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// connDrainer tracks the connections served by the engine, so that they can be
+// closed by the deadlines of config.ShutdownTimeouts.
+type connDrainer struct {
+	mu sync.Mutex
+	// conns maps the connections being served to whether they are idle
+	conns map[network.Conn]bool
+
+	idleClosed bool
+	allClosed  bool
+}
+
+func newConnDrainer() *connDrainer {
+	return &connDrainer{conns: make(map[network.Conn]bool)}
+}
+
+// add reports false if the connection should be closed without being served.
+func (d *connDrainer) add(conn network.Conn) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.allClosed {
+		return false
+	}
+	d.conns[conn] = false
+	return true
+}
+
+func (d *connDrainer) remove(conn network.Conn) {
+	d.mu.Lock()
+	delete(d.conns, conn)
+	d.mu.Unlock()
+}
+
+// setIdle reports false if the connection becomes idle after the idle connections are closed.
+func (d *connDrainer) setIdle(conn network.Conn, idle bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if idle && (d.idleClosed || d.allClosed) {
+		return false
+	}
+	if _, ok := d.conns[conn]; ok {
+		d.conns[conn] = idle
+	}
+	return true
+}
+
+func (d *connDrainer) closeConns(all bool) {
+	var conns []network.Conn
+	d.mu.Lock()
+	d.idleClosed = true
+	d.allClosed = d.allClosed || all
+	for conn, idle := range d.conns {
+		if idle || all {
+			conns = append(conns, conn)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, conn := range conns {
+		// the buffers are still used by the serving goroutine, only close the underlying connection
+		if c, ok := conn.(interface{ CloseNoResetBuffer() error }); ok {
+			c.CloseNoResetBuffer() //nolint:errcheck
+			continue
+		}
+		conn.Close() //nolint:errcheck
+	}
+}
+
+// drain closes the idle connections after idle, and all the connections after request
+// if it is positive.
+func (d *connDrainer) drain(idle, request time.Duration) {
+	time.AfterFunc(idle, func() { d.closeConns(false) })
+	if request > 0 {
+		time.AfterFunc(request, func() { d.closeConns(true) })
+	}
+}
@@ -53,6 +53,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/cloudwego/hertz/internal/bytesconv"
 	"github.com/cloudwego/hertz/internal/bytestr"
@@ -62,6 +63,7 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/server/render"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/errreport"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/common/tracer"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
@@ -120,6 +122,9 @@ type Engine struct {
 	// Options for route and protocol server
 	options *config.Options
 
+	// timeouts are the read and idle timeouts which can be changed at runtime
+	timeouts *config.Timeouts
+
 	// route
 	RouterGroup
 	trees MethodTrees
@@ -153,11 +158,14 @@ type Engine struct {
 	// trace
 	tracerCtl   tracer.Controller
 	enableTrace bool
+	connTracers []tracer.ConnTracer
 
 	// protocol layer management
 	protocolSuite         *suite.Config
 	protocolServers       map[string]protocol.Server
 	protocolStreamServers map[string]protocol.StreamServer
+	// the servers recognizing the cleartext connections, see suite.Sniffer
+	protocolSniffers []protocolSniffer
 
 	// RequestContext pool
 	ctxPool sync.Pool
@@ -177,9 +185,40 @@ type Engine struct {
 	// to read a potentially large request body based on the headers
 	//
 	// The default is to automatically read request bodies of Expect 100 Continue requests
-	// like they are normal requests
+	// like they are normal requests, except the ones whose Content-Length exceeds
+	// the max request body size, which are rejected with 413 Request Entity Too Large
+	// unless the request body is streamed.
+	// The rejected requests are answered with 417 Expectation Failed without calling the handlers,
+	// and the connection is closed.
 	ContinueHandler func(header *protocol.RequestHeader) bool
 
+	// ContinueRejectHandler is called when an Expect 100 Continue request is rejected,
+	// either by ContinueHandler or for the large body which is not streamed.
+	//
+	// The response has been prepared with 417 Expectation Failed or 413 Request Entity Too Large
+	// before the call, and can be customized by the handler, e.g. to explain the rejection.
+	ContinueRejectHandler func(ctx *app.RequestContext)
+
+	// ParseErrorHandler is called when the request can not be parsed,
+	// e.g. the request header exceeds the limitations.
+	//
+	// The response has been prepared with the default status code and body before the call,
+	// e.g. 431 Request Header Fields Too Large, and can be customized by the handler.
+	// The connection is always closed after the response is written.
+	ParseErrorHandler func(ctx *app.RequestContext, err error)
+
+	// RawRequestHeaderHook is called with the raw request line and headers
+	// as they are received, once they are parsed successfully.
+	//
+	// RawResponseHeaderHook is called with the status line and headers of the response
+	// as they are serialized, before being written to the connection.
+	//
+	// They are intended for wire-level debugging, signature verification or archiving.
+	// raw is reused after the hook returns, so it must be copied if it is used after that.
+	// Only HTTP/1.1 is supported.
+	RawRequestHeaderHook  func(ctx *app.RequestContext, raw []byte)
+	RawResponseHeaderHook func(ctx *app.RequestContext, raw []byte)
+
 	// Indicates the engine status (Init/Running/Shutdown/Closed).
 	status uint32
 
@@ -189,6 +228,9 @@ type Engine struct {
 	// Hook functions get triggered simultaneously when engine shutdown
 	OnShutdown []CtxCallback
 
+	// tracks the connections to be closed during shutdown, see config.ShutdownTimeouts
+	drainer *connDrainer
+
 	// Custom Functions
 	clientIPFunc  app.ClientIP
 	formValueFunc app.FormValueFunc
@@ -315,6 +357,20 @@ func (engine *Engine) Shutdown(ctx context.Context) (err error) {
 		}
 	}
 
+	if engine.drainer != nil {
+		st := engine.options.ShutdownTimeouts
+		engine.drainer.drain(st.Idle, st.Request)
+		// keep accepting until the deadline
+		if st.Accept > 0 {
+			t := time.NewTimer(st.Accept)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+			}
+		}
+	}
+
 	// call transport shutdown
 	if err := engine.transport.Shutdown(ctx); err != ctx.Err() {
 		return err
@@ -371,7 +427,21 @@ func (engine *Engine) Init() error {
 	engine.protocolServers = serverMap
 	engine.protocolStreamServers = streamServerMap
 
+	protocols := engine.protocolSuite.Protocols()
+	engine.protocolSniffers = engine.protocolSniffers[:0]
+	for _, proto := range protocols {
+		if sniffer, ok := serverMap[proto].(suite.Sniffer); ok && proto != suite.HTTP1 {
+			engine.protocolSniffers = append(engine.protocolSniffers, protocolSniffer{Sniffer: sniffer, server: serverMap[proto]})
+		}
+	}
+
 	if engine.alpnEnable() {
+		// advertise the added protocols, which are preferred to HTTP1
+		for _, proto := range protocols {
+			if proto != suite.HTTP1 && !containsProto(engine.options.TLS.NextProtos, proto) {
+				engine.options.TLS.NextProtos = append(engine.options.TLS.NextProtos, proto)
+			}
+		}
 		engine.options.TLS.NextProtos = append(engine.options.TLS.NextProtos, suite.HTTP1)
 	}
 
@@ -381,6 +451,15 @@ func (engine *Engine) Init() error {
 	return nil
 }
 
+func containsProto(protos []string, proto string) bool {
+	for _, p := range protos {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
+
 func (engine *Engine) alpnEnable() bool {
 	return engine.options.TLS != nil && engine.options.ALPN
 }
@@ -402,11 +481,21 @@ func (c *hijackConn) Close() error {
 	return conn.Close()
 }
 
+// CloseNoResetBuffer closes the underlying connection without releasing its buffers even if
+// the hijacked connections are not kept, e.g. to stop a tunnel reading from it. The buffers are
+// released once the HijackHandler returns.
+func (c *hijackConn) CloseNoResetBuffer() error {
+	if cc, ok := c.Conn.(interface{ CloseNoResetBuffer() error }); ok {
+		return cc.CloseNoResetBuffer()
+	}
+	return c.Conn.Close()
+}
+
 func (engine *Engine) getNextProto(conn network.Conn) (proto string, err error) {
 	if tlsConn, ok := conn.(network.ConnTLSer); ok {
-		if engine.options.ReadTimeout > 0 {
-			if err := conn.SetReadTimeout(engine.options.ReadTimeout); err != nil {
-				hlog.SystemLogger().Errorf("BUG: error in SetReadDeadline=%s: error=%s", engine.options.ReadTimeout, err)
+		if readTimeout := engine.timeouts.Read(); readTimeout > 0 {
+			if err := conn.SetReadTimeout(readTimeout); err != nil {
+				hlog.SystemLogger().Errorf("BUG: error in SetReadDeadline=%s: error=%s", readTimeout, err)
 			}
 		}
 		err = tlsConn.Handshake()
@@ -418,6 +507,13 @@ func (engine *Engine) getNextProto(conn network.Conn) (proto string, err error)
 }
 
 func (engine *Engine) onData(c context.Context, conn interface{}) (err error) {
+	if len(engine.connTracers) > 0 {
+		for _, t := range engine.connTracers {
+			t.ConnStart(c)
+		}
+		defer engine.finishConn(c)
+	}
+
 	switch conn := conn.(type) {
 	case network.Conn:
 		err = engine.Serve(c, conn)
@@ -427,6 +523,12 @@ func (engine *Engine) onData(c context.Context, conn interface{}) (err error) {
 	return
 }
 
+func (engine *Engine) finishConn(c context.Context) {
+	for i := len(engine.connTracers) - 1; i >= 0; i-- {
+		engine.connTracers[i].ConnFinish(c)
+	}
+}
+
 func errProcess(conn io.Closer, err error) {
 	if err == nil {
 		return
@@ -460,6 +562,7 @@ func errProcess(conn io.Closer, err error) {
 	}
 	// other errors
 	hlog.SystemLogger().Errorf("Error=%s, remoteAddr=%s", err.Error(), rip)
+	errreport.ReportInternal(context.Background(), err, rip)
 }
 
 func getRemoteAddrFromCloser(conn io.Closer) string {
@@ -498,6 +601,13 @@ func (engine *Engine) Serve(c context.Context, conn network.Conn) (err error) {
 		errProcess(conn, err)
 	}()
 
+	if engine.drainer != nil {
+		if !engine.drainer.add(conn) {
+			return conn.Close()
+		}
+		defer engine.drainer.remove(conn)
+	}
+
 	// H2C path
 	if engine.options.H2C {
 		// protocol sniffer
@@ -528,12 +638,61 @@ func (engine *Engine) Serve(c context.Context, conn network.Conn) (err error) {
 		}
 	}
 
+	// sniffing path
+	if len(engine.protocolSniffers) > 0 && engine.options.TLS == nil {
+		if server := engine.sniff(conn); server != nil {
+			return server.Serve(c, conn)
+		}
+	}
+
 	// HTTP1 path
 	err = engine.protocolServers[suite.HTTP1].Serve(c, conn)
 
 	return
 }
 
+type protocolSniffer struct {
+	suite.Sniffer
+	server protocol.Server
+}
+
+// sniff returns the server recognizing the leading bytes of conn, or nil if there is none.
+func (engine *Engine) sniff(conn network.Conn) protocol.Server {
+	if readTimeout := engine.timeouts.Read(); readTimeout > 0 {
+		if err := conn.SetReadTimeout(readTimeout); err != nil {
+			hlog.SystemLogger().Errorf("BUG: error in SetReadDeadline=%s: error=%s", readTimeout, err)
+		}
+	}
+	candidates := engine.protocolSniffers
+	n := 1
+	for {
+		// peek all the buffered bytes, or wait for one more byte
+		if l := conn.Len(); l > n {
+			n = l
+		}
+		b, err := conn.Peek(n)
+		if err != nil {
+			// let the HTTP1 server handle the error
+			return nil
+		}
+		var remaining []protocolSniffer
+		for _, s := range candidates {
+			match, more := s.Sniff(b)
+			if match {
+				return s.server
+			}
+			if more {
+				remaining = append(remaining, s)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		candidates = remaining
+		n = len(b) + 1
+	}
+}
+
 func (engine *Engine) ServeStream(ctx context.Context, conn network.StreamConn) error {
 	// ALPN path
 	if engine.options.ALPN && engine.options.TLS != nil {
@@ -551,6 +710,28 @@ func (engine *Engine) ServeStream(ctx context.Context, conn network.StreamConn)
 	return errs.ErrNotSupportProtocol
 }
 
+// ConnIdle implements suite.ConnTracker, it reports false once the idle connections
+// are closed during shutdown.
+func (engine *Engine) ConnIdle(conn network.Conn) bool {
+	if engine.drainer == nil {
+		return true
+	}
+	return engine.drainer.setIdle(conn, true)
+}
+
+// ConnActive implements suite.ConnTracker.
+func (engine *Engine) ConnActive(conn network.Conn) {
+	if engine.drainer != nil {
+		engine.drainer.setIdle(conn, false)
+	}
+}
+
+// Timeouts returns the read and idle timeouts of the engine, which can be changed
+// while the engine is running, e.g. by the runtimeconfig package.
+func (engine *Engine) Timeouts() *config.Timeouts {
+	return engine.timeouts
+}
+
 func NewEngine(opt *config.Options) *Engine {
 	engine := &Engine{
 		trees: make(MethodTrees, 0, 9),
@@ -565,10 +746,14 @@ func NewEngine(opt *config.Options) *Engine {
 		protocolStreamServers: make(map[string]protocol.StreamServer),
 		enableTrace:           true,
 		options:               opt,
+		timeouts:              config.NewTimeouts(opt.ReadTimeout, opt.IdleTimeout),
 	}
 	if opt.TransporterNewer != nil {
 		engine.transport = opt.TransporterNewer(opt)
 	}
+	if opt.ShutdownTimeouts != nil {
+		engine.drainer = newConnDrainer()
+	}
 	engine.RouterGroup.engine = engine
 
 	traceLevel := initTrace(engine)
@@ -592,8 +777,11 @@ func NewEngine(opt *config.Options) *Engine {
 
 func initTrace(engine *Engine) stats.Level {
 	for _, ti := range engine.options.Tracers {
-		if tracer, ok := ti.(tracer.Tracer); ok {
-			engine.tracerCtl.Append(tracer)
+		if t, ok := ti.(tracer.Tracer); ok {
+			engine.tracerCtl.Append(t)
+		}
+		if ct, ok := ti.(tracer.ConnTracer); ok {
+			engine.connTracers = append(engine.connTracers, ct)
 		}
 	}
 
@@ -659,12 +847,17 @@ func printNode(node *node, level int) {
 
 func (engine *Engine) recv(ctx *app.RequestContext) {
 	if rcv := recover(); rcv != nil {
+		errreport.ReportPanic(context.Background(), ctx, rcv, nil)
 		engine.PanicHandler(context.Background(), ctx)
 	}
 }
 
 // ServeHTTP makes the router implement the Handler interface.
 func (engine *Engine) ServeHTTP(c context.Context, ctx *app.RequestContext) {
+	if errreport.Enabled() {
+		// registered before recv, so that it runs after the PanicHandler has written the response
+		defer errreport.ReportServerError(c, ctx)
+	}
 	if engine.PanicHandler != nil {
 		defer engine.recv(ctx)
 	}
@@ -948,6 +1141,10 @@ func (engine *Engine) Routes() (routes RoutesInfo) {
 	return routes
 }
 
+// AddProtocol adds the factory of the server serving protocol, which is either a suite.ServerFactory or a suite.StreamServerFactory.
+//
+// The connections are dispatched to the server once protocol is negotiated by ALPN, or recognized
+// by the server implementing suite.Sniffer on cleartext.
 func (engine *Engine) AddProtocol(protocol string, factory interface{}) {
 	engine.protocolSuite.Add(protocol, factory)
 }
@@ -987,27 +1184,68 @@ func iterate(method string, routes RoutesInfo, root *node) RoutesInfo {
 	return routes
 }
 
+// newQueryDialectFromOptions returns nil if the default dialect is used.
+func newQueryDialectFromOptions(opt *config.Options) *protocol.QueryDialect {
+	d := &protocol.QueryDialect{
+		SemicolonSeparator: opt.QuerySemicolonSeparator,
+		DisablePlusAsSpace: opt.QueryDisablePlusAsSpace,
+		DuplicateKeys:      protocol.DuplicateKeyPolicy(opt.QueryDuplicateKeyPolicy),
+	}
+	if *d == (protocol.QueryDialect{}) {
+		return nil
+	}
+	return d
+}
+
+// newFormLimitsFromOptions returns nil if the forms are not limited.
+func newFormLimitsFromOptions(opt *config.Options) *protocol.FormLimits {
+	l := &protocol.FormLimits{
+		MaxFields:      opt.MaxFormFields,
+		MaxKeyLength:   opt.MaxFormKeyLength,
+		MaxValueLength: opt.MaxFormValueLength,
+	}
+	if *l == (protocol.FormLimits{}) {
+		return nil
+	}
+	return l
+}
+
 // for built-in http1 impl only.
 func newHttp1OptionFromEngine(engine *Engine) *http1.Option {
 	opt := &http1.Option{
-		StreamRequestBody:            engine.options.StreamRequestBody,
-		GetOnly:                      engine.options.GetOnly,
-		DisablePreParseMultipartForm: engine.options.DisablePreParseMultipartForm,
-		DisableKeepalive:             engine.options.DisableKeepalive,
-		NoDefaultServerHeader:        engine.options.NoDefaultServerHeader,
-		MaxRequestBodySize:           engine.options.MaxRequestBodySize,
-		IdleTimeout:                  engine.options.IdleTimeout,
-		ReadTimeout:                  engine.options.ReadTimeout,
-		ServerName:                   engine.GetServerName(),
-		ContinueHandler:              engine.ContinueHandler,
-		TLS:                          engine.options.TLS,
-		HTMLRender:                   engine.htmlRender,
-		EnableTrace:                  engine.IsTraceEnable(),
-		HijackConnHandle:             engine.HijackConnHandle,
-	}
-	// Idle timeout of standard network must not be zero. Set it to -1 seconds if it is zero.
+		StreamRequestBody:             engine.options.StreamRequestBody,
+		GetOnly:                       engine.options.GetOnly,
+		DisablePreParseMultipartForm:  engine.options.DisablePreParseMultipartForm,
+		DisableKeepalive:              engine.options.DisableKeepalive,
+		NoDefaultServerHeader:         engine.options.NoDefaultServerHeader,
+		DisableHeaderNamesNormalizing: engine.options.DisableHeaderNamesNormalizing,
+		StrictCookieParsing:           engine.options.StrictCookieParsing,
+		StrictRequestParsing:          engine.options.StrictRequestParsing,
+		QueryDialect:                  newQueryDialectFromOptions(engine.options),
+		FormLimits:                    newFormLimitsFromOptions(engine.options),
+		MaxRequestBodySize:            engine.options.MaxRequestBodySize,
+		MaxHeaderBytes:                engine.options.MaxHeaderBytes,
+		MaxHeaderCount:                engine.options.MaxHeaderCount,
+		MaxURLLength:                  engine.options.MaxURLLength,
+		MaxRequestsPerConn:            engine.options.MaxRequestsPerConn,
+		MaxConnAge:                    engine.options.MaxConnAge,
+		IdleTimeout:                   engine.options.IdleTimeout,
+		ReadTimeout:                   engine.options.ReadTimeout,
+		Timeouts:                      engine.timeouts,
+		ServerName:                    engine.GetServerName(),
+		ContinueHandler:               engine.ContinueHandler,
+		ParseErrorHandler:             engine.ParseErrorHandler,
+		ContinueRejectHandler:         engine.ContinueRejectHandler,
+		RawRequestHeaderHook:          engine.RawRequestHeaderHook,
+		RawResponseHeaderHook:         engine.RawResponseHeaderHook,
+		TLS:                           engine.options.TLS,
+		HTMLRender:                    engine.htmlRender,
+		EnableTrace:                   engine.IsTraceEnable(),
+		HijackConnHandle:              engine.HijackConnHandle,
+	}
+	// Idle timeout of the blocking network libraries (standard, iouring and rudp) must not be zero. Set it to -1 seconds if it is zero.
 	// Due to the different triggering ways of the network library, see the actual use of this value for the detailed reasons.
-	if opt.IdleTimeout == 0 && engine.GetTransporterName() == "standard" {
+	if name := engine.GetTransporterName(); opt.IdleTimeout == 0 && (name == "standard" || name == "iouring" || name == "rudp") {
 		opt.IdleTimeout = -1
 	}
 	return opt
@@ -56,11 +56,14 @@ import (
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/config"
 	errs "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/errreport"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/test/mock"
 	"github.com/cloudwego/hertz/pkg/network"
 	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
 )
 
 func TestNew_Engine(t *testing.T) {
@@ -393,6 +396,60 @@ func TestGetNextProto(t *testing.T) {
 	}
 }
 
+type mockProtocolServer struct {
+	preface string
+	served  []string
+}
+
+func (s *mockProtocolServer) Serve(c context.Context, conn network.Conn) error {
+	b, _ := conn.Peek(conn.Len())
+	s.served = append(s.served, string(b))
+	return nil
+}
+
+func (s *mockProtocolServer) Sniff(b []byte) (match, more bool) {
+	return suite.SniffPrefix(b, []byte(s.preface))
+}
+
+func (s *mockProtocolServer) New(core suite.Core) (protocol.Server, error) {
+	return s, nil
+}
+
+func TestSniffProtocol(t *testing.T) {
+	engine := NewEngine(config.NewOptions(nil))
+	foo, bar := &mockProtocolServer{preface: "FOO\r\n"}, &mockProtocolServer{preface: "FOOBAR\r\n"}
+	engine.AddProtocol("foo", foo)
+	engine.AddProtocol("bar", bar)
+	atomic.StoreUint32(&engine.status, statusRunning)
+	engine.Init()
+	engine.GET("/foo", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(consts.StatusOK, "ok")
+	})
+
+	assert.Nil(t, engine.Serve(context.Background(), mock.NewConn("FOO\r\nfoo")))
+	assert.Nil(t, engine.Serve(context.Background(), mock.NewConn("FOOBAR\r\nbar")))
+	assert.DeepEqual(t, []string{"FOO\r\nfoo"}, foo.served)
+	assert.DeepEqual(t, []string{"FOOBAR\r\nbar"}, bar.served)
+
+	// fallback to HTTP1
+	conn := mock.NewConn("GET /foo HTTP/1.1\r\nHost: google.com\r\nConnection: close\r\n\r\n")
+	err := engine.Serve(context.Background(), conn)
+	assert.True(t, errors.Is(err, errs.ErrShortConnection))
+	assert.DeepEqual(t, 1, len(foo.served))
+	assert.DeepEqual(t, 1, len(bar.served))
+}
+
+func TestALPNNextProtos(t *testing.T) {
+	engine := NewEngine(config.NewOptions([]config.Option{{F: func(o *config.Options) {
+		o.TLS = &tls.Config{NextProtos: []string{"bar"}}
+		o.ALPN = true
+	}}}))
+	engine.AddProtocol("foo", &mockProtocolServer{})
+	engine.AddProtocol("bar", &mockProtocolServer{})
+	engine.Init()
+	assert.DeepEqual(t, []string{"bar", "foo", suite.HTTP1}, engine.options.TLS.NextProtos)
+}
+
 func formatAsDate(t time.Time) string {
 	year, month, day := t.Date()
 	return fmt.Sprintf("%d/%02d/%02d", year, month, day)
@@ -615,3 +672,31 @@ func (f *fakeTransporter) ListenAndServe(onData network.OnData) error {
 	// TODO implement me
 	panic("implement me")
 }
+
+func TestErrorReport(t *testing.T) {
+	var kinds []errreport.Kind
+	errreport.Register(errreport.ReporterFunc(func(c context.Context, r *errreport.Report) {
+		kinds = append(kinds, r.Kind)
+	}))
+	defer errreport.Reset()
+
+	e := NewEngine(config.NewOptions(nil))
+	e.PanicHandler = func(c context.Context, ctx *app.RequestContext) {
+		ctx.AbortWithStatus(consts.StatusInternalServerError)
+	}
+	e.GET("/panic", func(c context.Context, ctx *app.RequestContext) {
+		panic("boom")
+	})
+	e.GET("/unavailable", func(c context.Context, ctx *app.RequestContext) {
+		ctx.AbortWithStatus(consts.StatusServiceUnavailable)
+	})
+	e.GET("/ok", func(c context.Context, ctx *app.RequestContext) {})
+
+	w := performRequest(e, "GET", "/panic")
+	assert.DeepEqual(t, consts.StatusInternalServerError, w.Code)
+	assert.DeepEqual(t, []errreport.Kind{errreport.KindPanic}, kinds)
+
+	performRequest(e, "GET", "/unavailable")
+	performRequest(e, "GET", "/ok")
+	assert.DeepEqual(t, []errreport.Kind{errreport.KindPanic, errreport.KindServerError}, kinds)
+}